@@ -1,16 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
+	"time"
 
 	"github.com/0-jagadeesh-0/chorvo/config"
 	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
 	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
 	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/routes"
 	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/jobs"
+	"github.com/0-jagadeesh-0/chorvo/internal/mail"
+	"github.com/0-jagadeesh-0/chorvo/internal/realtime"
 	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"github.com/0-jagadeesh-0/chorvo/internal/storage"
 	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
@@ -43,17 +49,234 @@ func main() {
 	}
 	defer sqlDB.Close()
 
+	// Apply schema migrations
+	if err := config.MigrateDB(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
+	}
+
+	// Connect to attachment storage
+	storageConfig, err := config.LoadStorageConfig()
+	if err != nil {
+		log.Fatalf("Failed to load storage config: %v", err)
+	}
+	store, scanner, err := config.ConnectStorage(storageConfig)
+	if err != nil {
+		log.Fatalf("Failed to set up storage: %v", err)
+	}
+
+	// Set up email
+	mailConfig, err := config.LoadMailConfig()
+	if err != nil {
+		log.Fatalf("Failed to load mail config: %v", err)
+	}
+	mailer, err := config.ConnectMailer(mailConfig)
+	if err != nil {
+		log.Fatalf("Failed to set up mail: %v", err)
+	}
+	mailTemplates, err := mail.LoadTemplates()
+	if err != nil {
+		log.Fatalf("Failed to load email templates: %v", err)
+	}
+
+	// Pass changes announced by any server on to this server's live clients
+	hub := realtime.NewHub()
+	if err := config.ListenRealtime(context.Background(), hub); err != nil {
+		log.Fatalf("Failed to listen for realtime events: %v", err)
+	}
+
+	// Load invoicing settings
+	billingConfig, err := config.LoadBillingConfig()
+	if err != nil {
+		log.Fatalf("Failed to load billing config: %v", err)
+	}
+
+	// Connect to the payment provider
+	paymentConfig, err := config.LoadPaymentConfig()
+	if err != nil {
+		log.Fatalf("Failed to load payment config: %v", err)
+	}
+	paymentProvider, err := config.ConnectPayments(paymentConfig)
+	if err != nil {
+		log.Fatalf("Failed to set up payments: %v", err)
+	}
+
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db)
+	orgRepo := repositories.NewOrganizationRepository(db)
+	teamRepo := repositories.NewTeamRepository(db)
+	projectRepo := repositories.NewProjectRepository(db)
+	taskRepo := repositories.NewTaskRepository(db)
+	sprintRepo := repositories.NewSprintRepository(db)
+	activityRepo := repositories.NewActivityRepository(db)
+	labelRepo := repositories.NewLabelRepository(db)
+	fieldRepo := repositories.NewCustomFieldRepository(db)
+	filterRepo := repositories.NewSavedFilterRepository(db)
+	searchRepo := repositories.NewSearchRepository(db)
+	commentRepo := repositories.NewCommentRepository(db)
+	attachmentRepo := repositories.NewAttachmentRepository(db)
+	timeEntryRepo := repositories.NewTimeEntryRepository(db)
+	timesheetRepo := repositories.NewTimesheetRepository(db)
+	budgetRepo := repositories.NewBudgetRepository(db)
+	expenseRepo := repositories.NewExpenseRepository(db)
+	planRepo := repositories.NewPlanRepository(db)
+	subscriptionRepo := repositories.NewSubscriptionRepository(db)
+	addOnRepo := repositories.NewAddOnRepository(db)
+	featureRepo := repositories.NewFeatureRepository(db)
+	couponRepo := repositories.NewCouponRepository(db)
+	invoiceRepo := repositories.NewInvoiceRepository(db)
+	paymentRepo := repositories.NewPaymentRepository(db)
+	creditNoteRepo := repositories.NewCreditNoteRepository(db)
+	notificationRepo := repositories.NewNotificationRepository(db)
+	outboxRepo := repositories.NewOutboxRepository(db)
+	digestRepo := repositories.NewDigestRepository(db)
+	presenceRepo := repositories.NewPresenceRepository(db)
 
 	// Initialize services
-	authService := services.NewAuthService(userRepo)
+	mailService := services.NewMailService(outboxRepo, mailTemplates, mailer, services.MailSettings{
+		FrontendURL: mailConfig.FrontendURL,
+		PublicURL:   mailConfig.PublicURL,
+		SigningKey:  []byte(mailConfig.SigningKey),
+	})
+	authService := services.NewAuthService(userRepo, mailService)
+	sprintService := services.NewSprintService(sprintRepo, taskRepo, teamRepo, projectRepo, orgRepo)
+	activityService := services.NewActivityService(activityRepo, taskRepo, projectRepo, orgRepo)
+	notificationService := services.NewNotificationService(notificationRepo, userRepo, mailService)
+	digestService := services.NewDigestService(digestRepo, userRepo, mailService)
+	realtimeService := services.NewRealtimeService(hub, projectRepo, orgRepo)
+	presenceService := services.NewPresenceService(presenceRepo, taskRepo, hub, projectRepo, orgRepo)
+	entitlementService := services.NewEntitlementService(featureRepo, subscriptionRepo, userRepo, projectRepo, orgRepo)
+	labelService := services.NewLabelService(labelRepo, taskRepo, projectRepo, orgRepo)
+	fieldService := services.NewCustomFieldService(fieldRepo, taskRepo, entitlementService, projectRepo, orgRepo)
+	taskService := services.NewTaskService(taskRepo, labelRepo, fieldRepo, projectRepo, orgRepo)
+	filterService := services.NewSavedFilterService(filterRepo, taskService, entitlementService, projectRepo, orgRepo)
+	searchService := services.NewSearchService(searchRepo, userRepo, projectRepo, orgRepo)
+	attachmentService := services.NewAttachmentService(attachmentRepo, taskRepo, commentRepo, expenseRepo, projectRepo, orgRepo, store, scanner, services.AttachmentLimits{
+		MaxFileSize:    storageConfig.MaxFileSize,
+		UploadExpiry:   storageConfig.UploadExpiry,
+		DownloadExpiry: storageConfig.DownloadExpiry,
+	})
+	budgetService := services.NewBudgetService(budgetRepo, entitlementService, projectRepo, orgRepo)
+	expenseService := services.NewExpenseService(expenseRepo, taskRepo, budgetService, entitlementService, projectRepo, orgRepo)
+	timeService := services.NewTimeService(timeEntryRepo, timesheetRepo, taskRepo, budgetService, entitlementService, projectRepo, orgRepo)
+	planService := services.NewPlanService(planRepo, userRepo)
+	subscriptionService := services.NewSubscriptionService(subscriptionRepo, planRepo, projectRepo, orgRepo)
+	addOnService := services.NewAddOnService(addOnRepo, subscriptionRepo, userRepo, projectRepo, orgRepo)
+	invoiceSettings := services.InvoiceSettings{
+		NumberPerOrganization: billingConfig.NumberPerOrganization,
+		NumberFormat:          billingConfig.NumberFormat,
+		CreditNoteFormat:      billingConfig.CreditNoteFormat,
+		DueDays:               billingConfig.DueDays,
+		Seller:                billingConfig.Seller,
+		SellerCountry:         billingConfig.SellerCountry,
+		Tax:                   billingConfig.Tax,
+	}
+	invoiceService := services.NewInvoiceService(invoiceRepo, addOnRepo, couponRepo, projectRepo, orgRepo, invoiceSettings)
+	creditNoteService := services.NewCreditNoteService(creditNoteRepo, invoiceRepo, projectRepo, orgRepo, invoiceSettings)
+	taxService := services.NewTaxService(projectRepo, orgRepo, billingConfig.Tax, billingConfig.SellerCountry)
+	couponService := services.NewCouponService(couponRepo, invoiceRepo, userRepo, projectRepo, orgRepo)
+	paymentService := services.NewPaymentService(paymentRepo, invoiceRepo, subscriptionRepo, userRepo, creditNoteService, projectRepo, orgRepo, paymentProvider)
+	dunningService := services.NewDunningService(invoiceRepo, subscriptionRepo, orgRepo, paymentService, mailService, billingConfig.Dunning)
+	paymentEventService := services.NewPaymentEventService(paymentRepo, invoiceRepo, subscriptionRepo, userRepo, paymentProvider)
 
 	// Initialize handlers
 	authHandler := handlers.NewAuthHandler(authService)
+	sprintHandler := handlers.NewSprintHandler(sprintService)
+	activityHandler := handlers.NewActivityHandler(activityService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	digestHandler := handlers.NewDigestHandler(digestService)
+	realtimeHandler := handlers.NewRealtimeHandler(realtimeService)
+	presenceHandler := handlers.NewPresenceHandler(presenceService, realtimeService)
+	labelHandler := handlers.NewLabelHandler(labelService)
+	fieldHandler := handlers.NewCustomFieldHandler(fieldService)
+	taskHandler := handlers.NewTaskHandler(taskService)
+	filterHandler := handlers.NewSavedFilterHandler(filterService)
+	searchHandler := handlers.NewSearchHandler(searchService)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, storageConfig.MaxFileSize)
+	timeHandler := handlers.NewTimeHandler(timeService)
+	budgetHandler := handlers.NewBudgetHandler(budgetService)
+	expenseHandler := handlers.NewExpenseHandler(expenseService)
+	planHandler := handlers.NewPlanHandler(planService)
+	subscriptionHandler := handlers.NewSubscriptionHandler(subscriptionService)
+	addOnHandler := handlers.NewAddOnHandler(addOnService)
+	entitlementHandler := handlers.NewEntitlementHandler(entitlementService)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceService)
+	couponHandler := handlers.NewCouponHandler(couponService)
+	taxHandler := handlers.NewTaxHandler(taxService)
+	paymentHandler := handlers.NewPaymentHandler(paymentService)
+	creditNoteHandler := handlers.NewCreditNoteHandler(creditNoteService)
+	paymentEventHandler := handlers.NewPaymentEventHandler(paymentEventService)
+
+	// Start background jobs
+	go jobs.Every(context.Background(), db, "subscription-renewals", time.Minute, func(ctx context.Context) error {
+		_, err := subscriptionService.ProcessRenewals(ctx)
+		return err
+	})
+	go jobs.Daily(context.Background(), db, "usage-reconciliation", 3*time.Hour, func(ctx context.Context) error {
+		drifted, err := subscriptionService.ReconcileUsage(ctx)
+		if drifted > 0 {
+			log.Printf("Corrected the usage counts of %d subscriptions", drifted)
+		}
+		return err
+	})
+	go jobs.Every(context.Background(), db, "billing-run", time.Minute, func(ctx context.Context) error {
+		_, err := invoiceService.RunBilling(ctx)
+		return err
+	})
+	go jobs.Every(context.Background(), db, "payment-collection", time.Minute, func(ctx context.Context) error {
+		_, err := paymentService.CollectPayments(ctx)
+		return err
+	})
+	go jobs.Every(context.Background(), db, "credit-notes", time.Minute, func(ctx context.Context) error {
+		_, err := creditNoteService.IssueMissing(ctx)
+		return err
+	})
+	go jobs.Every(context.Background(), db, "credit-note-voids", time.Minute, func(ctx context.Context) error {
+		_, err := creditNoteService.VoidReversed(ctx)
+		return err
+	})
+	go jobs.Every(context.Background(), db, "dunning", time.Minute, func(ctx context.Context) error {
+		_, err := dunningService.RunDunning(ctx)
+		return err
+	})
+	go jobs.Every(context.Background(), db, "payment-events", time.Minute, func(ctx context.Context) error {
+		_, err := paymentEventService.RetryFailed(ctx)
+		return err
+	})
+	go jobs.Every(context.Background(), db, "feature-overrides", time.Minute, func(ctx context.Context) error {
+		return entitlementService.SyncExpiredOverrides(ctx)
+	})
+	go jobs.Every(context.Background(), db, "due-soon-notifications", 15*time.Minute, func(ctx context.Context) error {
+		_, err := notificationService.NotifyDueSoon(ctx)
+		return err
+	})
+	go jobs.Every(context.Background(), db, "notification-emails", 30*time.Second, func(ctx context.Context) error {
+		_, err := notificationService.QueueEmails(ctx)
+		return err
+	})
+	go jobs.Every(context.Background(), db, "digests", 5*time.Minute, func(ctx context.Context) error {
+		_, err := digestService.SendDue(ctx)
+		return err
+	})
+	go jobs.Every(context.Background(), db, "presence-cleanup", 30*time.Second, func(ctx context.Context) error {
+		_, err := presenceService.PruneExpired(ctx)
+		return err
+	})
+	go jobs.Every(context.Background(), db, "mail-outbox", 10*time.Second, func(ctx context.Context) error {
+		_, err := mailService.DeliverPending(ctx)
+		return err
+	})
+	go jobs.Daily(context.Background(), db, "mail-outbox-cleanup", 4*time.Hour, func(ctx context.Context) error {
+		_, err := mailService.PruneSent(ctx)
+		return err
+	})
 
 	// Public routes
 	routes.SetupAuthRoutes(router, authHandler)
+	routes.SetupPaymentWebhookRoutes(router, paymentEventHandler)
+	routes.SetupDigestUnsubscribeRoutes(router, digestHandler)
+	if local, ok := store.(*storage.LocalStorage); ok {
+		routes.SetupFileRoutes(router, handlers.NewFileHandler(local))
+	}
 
 	// Protected routes
 	protected := router.Group("/api/v1")
@@ -63,8 +286,39 @@ func main() {
 		// TODO: Add team routes
 		// TODO: Add project routes
 		// TODO: Add task routes
+		routes.SetupTaskRoutes(protected, taskHandler)
+		routes.SetupSavedFilterRoutes(protected, filterHandler, entitlementService)
+		routes.SetupSearchRoutes(protected, searchHandler)
+		routes.SetupAttachmentRoutes(protected, attachmentHandler)
+		routes.SetupTimeRoutes(protected, timeHandler, entitlementService)
+		routes.SetupBudgetRoutes(protected, budgetHandler, entitlementService)
+		routes.SetupExpenseRoutes(protected, expenseHandler)
+		routes.SetupPlanRoutes(protected, planHandler)
+		routes.SetupSubscriptionRoutes(protected, subscriptionHandler)
+		routes.SetupAddOnRoutes(protected, addOnHandler)
+		routes.SetupEntitlementRoutes(protected, entitlementHandler)
+		routes.SetupInvoiceRoutes(protected, invoiceHandler)
+		routes.SetupCouponRoutes(protected, couponHandler)
+		routes.SetupTaxRoutes(protected, taxHandler)
+		routes.SetupPaymentRoutes(protected, paymentHandler)
+		routes.SetupCreditNoteRoutes(protected, creditNoteHandler)
+		routes.SetupPaymentEventRoutes(protected, paymentEventHandler)
+		routes.SetupLabelRoutes(protected, labelHandler)
+		routes.SetupCustomFieldRoutes(protected, fieldHandler)
+		routes.SetupSprintRoutes(protected, sprintHandler)
+		routes.SetupActivityRoutes(protected, activityHandler)
+		routes.SetupNotificationRoutes(protected, notificationHandler)
+		routes.SetupDigestRoutes(protected, digestHandler)
+		routes.SetupPresenceRoutes(protected, presenceHandler)
 	}
 
+	// Live event stream and presence socket, which also take the token as a
+	// query parameter
+	stream := router.Group("/api/v1")
+	stream.Use(middleware.StreamAuthMiddleware())
+	routes.SetupRealtimeRoutes(stream, realtimeHandler)
+	routes.SetupPresenceSocketRoutes(stream, presenceHandler)
+
 	// Get port from environment variable or use default
 	port := os.Getenv("PORT")
 	if port == "" {
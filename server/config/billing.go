@@ -0,0 +1,121 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/billing"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/tax"
+)
+
+const defaultInvoiceDueDays = 14
+
+// BillingConfig holds the invoicing settings
+type BillingConfig struct {
+	NumberPerOrganization bool // number each organization's invoices separately
+	NumberFormat          billing.NumberFormat
+	CreditNoteFormat      billing.NumberFormat // credit notes are numbered like invoices, in a sequence of their own
+	DueDays               int
+	Seller                billing.Party // printed on every invoice
+	SellerCountry         string        // where the seller is established, for tax
+	Tax                   *tax.Rules
+	Dunning               billing.DunningPolicy
+}
+
+// LoadBillingConfig reads the invoicing settings from the environment
+func LoadBillingConfig() (*BillingConfig, error) {
+	config := &BillingConfig{
+		NumberFormat:     billing.NumberFormat(getEnv("INVOICE_NUMBER_FORMAT", string(billing.DefaultNumberFormat))),
+		CreditNoteFormat: billing.NumberFormat(getEnv("CREDIT_NOTE_NUMBER_FORMAT", string(billing.DefaultCreditNoteFormat))),
+		DueDays:          defaultInvoiceDueDays,
+		Seller: billing.Party{
+			Name:    getEnv("INVOICE_SELLER_NAME", "Chorvo"),
+			Email:   os.Getenv("INVOICE_SELLER_EMAIL"),
+			Address: os.Getenv("INVOICE_SELLER_ADDRESS"),
+			TaxID:   os.Getenv("INVOICE_SELLER_TAX_ID"),
+		},
+		SellerCountry: tax.NormalizeCountry(os.Getenv("INVOICE_SELLER_COUNTRY")),
+		Dunning:       billing.DefaultDunningPolicy,
+	}
+
+	switch numbering := getEnv("INVOICE_NUMBERING", "global"); numbering {
+	case "global":
+	case "organization":
+		config.NumberPerOrganization = true
+	default:
+		return nil, fmt.Errorf("invalid INVOICE_NUMBERING %q: use global or organization", numbering)
+	}
+	if err := config.NumberFormat.Validate(config.NumberPerOrganization); err != nil {
+		return nil, fmt.Errorf("INVOICE_NUMBER_FORMAT: %w", err)
+	}
+	if err := config.CreditNoteFormat.Validate(config.NumberPerOrganization); err != nil {
+		return nil, fmt.Errorf("CREDIT_NOTE_NUMBER_FORMAT: %w", err)
+	}
+
+	if raw := os.Getenv("INVOICE_DUE_DAYS"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid INVOICE_DUE_DAYS %q", raw)
+		}
+		config.DueDays = n
+	}
+
+	if config.SellerCountry != "" {
+		if err := tax.ValidateCountry(config.SellerCountry); err != nil {
+			return nil, fmt.Errorf("INVOICE_SELLER_COUNTRY: %w", err)
+		}
+	}
+	// Tax rates change, so they can be read from a file of the same format as
+	// the embedded one rather than waiting for a release
+	config.Tax = tax.DefaultRules()
+	if path := os.Getenv("TAX_RULES_FILE"); path != "" {
+		rules, err := tax.LoadRulesFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("TAX_RULES_FILE: %w", err)
+		}
+		config.Tax = rules
+	}
+
+	var err error
+	if config.Dunning.RetryDays, err = parseDays("DUNNING_RETRY_DAYS", config.Dunning.RetryDays); err != nil {
+		return nil, err
+	}
+	if config.Dunning.ReminderDays, err = parseDays("DUNNING_REMINDER_DAYS", config.Dunning.ReminderDays); err != nil {
+		return nil, err
+	}
+	if raw := os.Getenv("DUNNING_GRACE_DAYS"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid DUNNING_GRACE_DAYS %q", raw)
+		}
+		config.Dunning.GraceDays = n
+	}
+	if err := config.Dunning.Validate(); err != nil {
+		return nil, fmt.Errorf("dunning: %w", err)
+	}
+
+	return config, nil
+}
+
+// parseDays reads a comma-separated list of day counts, such as "1,3,7". An
+// empty value disables the schedule.
+func parseDays(name string, fallback []int) ([]int, error) {
+	raw, ok := os.LookupEnv(name)
+	if !ok {
+		return fallback, nil
+	}
+	var days []int
+	for _, field := range strings.Split(raw, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %q", name, raw)
+		}
+		days = append(days, n)
+	}
+	return days, nil
+}
@@ -35,22 +35,25 @@ func LoadDBConfig() (*DBConfig, error) {
 	return config, nil
 }
 
+// DSN returns the connection string for the database
+func (c *DBConfig) DSN() string {
+	return fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		c.Host,
+		c.Port,
+		c.User,
+		c.Password,
+		c.DBName,
+		c.SSLMode,
+	)
+}
+
 func ConnectDB() (*gorm.DB, error) {
 	config, err := LoadDBConfig()
 	if err != nil {
 		return nil, err
 	}
 
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-		config.Host,
-		config.Port,
-		config.User,
-		config.Password,
-		config.DBName,
-		config.SSLMode,
-	)
-
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	db, err := gorm.Open(postgres.Open(config.DSN()), &gorm.Config{})
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %v", err)
 	}
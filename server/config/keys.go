@@ -0,0 +1,34 @@
+package config
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"os"
+
+	"golang.org/x/crypto/hkdf"
+)
+
+// signingKey returns the key in the named environment variable. Without one,
+// a key for the purpose is derived from JWT_SECRET with HKDF, so that the
+// secret signing sessions never signs anything else itself and a key leaked
+// from one use cannot forge another.
+func signingKey(name, purpose string) (string, error) {
+	if key := os.Getenv(name); key != "" {
+		return key, nil
+	}
+	secret := os.Getenv("JWT_SECRET")
+	if secret == "" {
+		return "", fmt.Errorf("%s or JWT_SECRET is required", name)
+	}
+	return deriveKey(secret, purpose)
+}
+
+// deriveKey derives a 32-byte key for the purpose from the secret
+func deriveKey(secret, purpose string) (string, error) {
+	key := make([]byte, 32)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, []byte(secret), nil, []byte("chorvo "+purpose)), key); err != nil {
+		return "", err
+	}
+	return string(key), nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSigningKey(t *testing.T) {
+	t.Setenv("JWT_SECRET", "jwt-secret")
+	t.Setenv("STORAGE_SIGNING_KEY", "")
+
+	derived, err := signingKey("STORAGE_SIGNING_KEY", "storage URL signing")
+	if err != nil {
+		t.Fatalf("signingKey: %v", err)
+	}
+	if len(derived) != 32 || strings.Contains(derived, "jwt-secret") {
+		t.Errorf("derived key %x is not a separate 32-byte key", derived)
+	}
+	if again, _ := signingKey("STORAGE_SIGNING_KEY", "storage URL signing"); again != derived {
+		t.Error("derived key changes between calls")
+	}
+	if other, _ := signingKey("STORAGE_SIGNING_KEY", "unsubscribe link signing"); other == derived {
+		t.Error("different purposes derive the same key")
+	}
+
+	t.Setenv("JWT_SECRET", "another-secret")
+	if rotated, _ := signingKey("STORAGE_SIGNING_KEY", "storage URL signing"); rotated == derived {
+		t.Error("derived key does not depend on JWT_SECRET")
+	}
+
+	t.Setenv("STORAGE_SIGNING_KEY", "dedicated-key")
+	if key, err := signingKey("STORAGE_SIGNING_KEY", "storage URL signing"); key != "dedicated-key" || err != nil {
+		t.Errorf("signingKey with a dedicated key = %q, %v", key, err)
+	}
+
+	t.Setenv("STORAGE_SIGNING_KEY", "")
+	t.Setenv("JWT_SECRET", "")
+	if _, err := signingKey("STORAGE_SIGNING_KEY", "storage URL signing"); err == nil || !strings.Contains(err.Error(), "STORAGE_SIGNING_KEY") {
+		t.Errorf("signingKey without any secret error = %v", err)
+	}
+}
+
+func TestLoadStorageConfigDoesNotReuseJWTSecret(t *testing.T) {
+	t.Setenv("JWT_SECRET", "jwt-secret")
+	t.Setenv("STORAGE_SIGNING_KEY", "")
+	t.Setenv("STORAGE_BACKEND", "local")
+	t.Setenv("ATTACHMENT_MAX_SIZE_MB", "")
+
+	config, err := LoadStorageConfig()
+	if err != nil {
+		t.Fatalf("LoadStorageConfig: %v", err)
+	}
+	if config.SigningKey == "" || config.SigningKey == "jwt-secret" {
+		t.Errorf("storage signing key = %q, want a key derived from JWT_SECRET", config.SigningKey)
+	}
+
+	t.Setenv("JWT_SECRET", "")
+	if _, err := LoadStorageConfig(); err == nil {
+		t.Error("LoadStorageConfig without a signing key succeeded")
+	}
+}
+
+func TestLoadMailConfigDoesNotReuseJWTSecret(t *testing.T) {
+	t.Setenv("JWT_SECRET", "jwt-secret")
+	t.Setenv("MAIL_SIGNING_KEY", "")
+	t.Setenv("MAIL_BACKEND", "log")
+	t.Setenv("ENV", "")
+
+	config, err := LoadMailConfig()
+	if err != nil {
+		t.Fatalf("LoadMailConfig: %v", err)
+	}
+	if config.SigningKey == "" || config.SigningKey == "jwt-secret" {
+		t.Errorf("mail signing key = %q, want a key derived from JWT_SECRET", config.SigningKey)
+	}
+	storage, _ := deriveKey("jwt-secret", "storage URL signing")
+	if config.SigningKey == storage {
+		t.Error("mail and storage derive the same key")
+	}
+
+	t.Setenv("JWT_SECRET", "")
+	if _, err := LoadMailConfig(); err == nil {
+		t.Error("LoadMailConfig without a signing key succeeded")
+	}
+}
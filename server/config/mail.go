@@ -0,0 +1,62 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/mail"
+)
+
+// MailConfig holds the email settings
+type MailConfig struct {
+	Backend     string // smtp, file or log
+	SMTP        mail.SMTPConfig
+	FileDir     string // where the file backend writes messages
+	FrontendURL string // base URL of the web app, for links in emails
+	PublicURL   string // base URL of this server, for unsubscribe links
+	SigningKey  string // signs unsubscribe links
+}
+
+// LoadMailConfig reads the email settings from the environment. Mail is
+// sent over SMTP when a server is configured and logged otherwise.
+func LoadMailConfig() (*MailConfig, error) {
+	defaultBackend := "log"
+	if os.Getenv("SMTP_HOST") != "" {
+		defaultBackend = "smtp"
+	}
+	config := &MailConfig{
+		Backend: getEnv("MAIL_BACKEND", defaultBackend),
+		SMTP: mail.SMTPConfig{
+			Host:     os.Getenv("SMTP_HOST"),
+			Port:     getEnv("SMTP_PORT", "587"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     getEnv("SMTP_FROM", "Chorvo <no-reply@localhost>"),
+		},
+		FileDir:     getEnv("MAIL_FILE_DIR", "./mail"),
+		FrontendURL: os.Getenv("FRONTEND_URL"),
+		PublicURL:   getEnv("PUBLIC_URL", "http://localhost:"+getEnv("PORT", "8080")),
+	}
+	key, err := signingKey("MAIL_SIGNING_KEY", "unsubscribe link signing")
+	if err != nil {
+		return nil, fmt.Errorf("signing unsubscribe links: %w", err)
+	}
+	config.SigningKey = key
+	if config.Backend != "smtp" && os.Getenv("ENV") == "production" {
+		return nil, fmt.Errorf("MAIL_BACKEND must be smtp in production")
+	}
+	return config, nil
+}
+
+// ConnectMailer creates the configured mail backend
+func ConnectMailer(config *MailConfig) (mail.Mailer, error) {
+	switch config.Backend {
+	case "smtp":
+		return mail.NewSMTPMailer(config.SMTP)
+	case "file":
+		return mail.NewFileMailer(config.FileDir, config.SMTP.From)
+	case "log":
+		return mail.LogMailer{}, nil
+	}
+	return nil, fmt.Errorf("unknown mail backend %q", config.Backend)
+}
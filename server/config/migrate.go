@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// joinTables lists the custom join models used by many2many relationships
+var joinTables = []struct {
+	model interface{}
+	field string
+	join  interface{}
+}{
+	{&models.Organization{}, "Users", &models.OrganizationUser{}},
+	{&models.User{}, "Organizations", &models.OrganizationUser{}},
+	{&models.Project{}, "Members", &models.ProjectMember{}},
+	{&models.Team{}, "Members", &models.TeamMember{}},
+	{&models.User{}, "Teams", &models.TeamMember{}},
+	{&models.Team{}, "Projects", &models.TeamProject{}},
+	{&models.Project{}, "Teams", &models.TeamProject{}},
+	{&models.Task{}, "Labels", &models.TaskLabel{}},
+}
+
+// MigrateDB creates or updates the schema for all domain models
+func MigrateDB(db *gorm.DB) error {
+	for _, jt := range joinTables {
+		if err := db.SetupJoinTable(jt.model, jt.field, jt.join); err != nil {
+			return fmt.Errorf("failed to set up join table for %s: %v", jt.field, err)
+		}
+	}
+
+	if err := migrateMoney(db); err != nil {
+		return err
+	}
+
+	err := db.AutoMigrate(
+		&models.User{},
+		&models.Organization{},
+		&models.OrganizationUser{},
+		&models.Team{},
+		&models.TeamMember{},
+		&models.Project{},
+		&models.ProjectMember{},
+		&models.TeamProject{},
+		&models.Task{},
+		&models.TaskStatusChange{},
+		&models.Label{},
+		&models.TaskLabel{},
+		&models.CustomFieldDefinition{},
+		&models.CustomFieldValue{},
+		&models.SavedFilter{},
+		&models.FilterSubscription{},
+		&models.Comment{},
+		&models.Attachment{},
+		&models.TimeEntry{},
+		&models.Timesheet{},
+		&models.BudgetLine{},
+		&models.HourlyRate{},
+		&models.BudgetAlert{},
+		&models.Expense{},
+		&models.ActivityEvent{},
+		&models.Sprint{},
+		&models.SprintTask{},
+		&models.Plan{},
+		&models.PlanFeature{},
+		&models.FeatureOverride{},
+		&models.Subscription{},
+		&models.Invoice{},
+		&models.InvoiceItem{},
+		&models.InvoiceTax{},
+		&models.InvoiceSequence{},
+		&models.AddOn{},
+		&models.OrganizationAddOn{},
+		&models.Coupon{},
+		&models.CouponRedemption{},
+		&models.PaymentProfile{},
+		&models.PaymentEvent{},
+		&models.PaymentTransaction{},
+		&models.CreditNote{},
+		&models.Notification{},
+		&models.NotificationPreference{},
+		&models.OutboxEmail{},
+		&models.TaskWatcher{},
+		&models.DigestSetting{},
+		&models.Presence{},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to migrate database: %v", err)
+	}
+
+	if err := migrateNotificationPreferences(db); err != nil {
+		return err
+	}
+
+	if err := migrateSearch(db); err != nil {
+		return err
+	}
+
+	return nil
+}
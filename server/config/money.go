@@ -0,0 +1,104 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"gorm.io/gorm"
+)
+
+// moneyColumn describes a column that used to hold an amount as a float and
+// now holds it as <column>_minor and <column>_currency. currency is an SQL
+// expression giving the currency of each row.
+type moneyColumn struct {
+	table    string
+	column   string
+	currency string
+}
+
+var moneyColumns = []moneyColumn{
+	{"invoices", "amount", "invoices.currency"},
+	{"invoice_items", "unit_price", "(SELECT invoices.currency FROM invoices WHERE invoices.id = invoice_items.invoice_id)"},
+	{"invoice_items", "amount", "(SELECT invoices.currency FROM invoices WHERE invoices.id = invoice_items.invoice_id)"},
+	{"payment_transactions", "amount", "payment_transactions.currency"},
+	{"payment_transactions", "provider_fee", "payment_transactions.currency"},
+	{"plans", "price", "'USD'"},
+	{"projects", "budget", "'USD'"},
+	{"budget_lines", "amount", "'USD'"},
+	{"hourly_rates", "rate", "'USD'"},
+	{"expenses", "amount", "'USD'"},
+	{"budget_alerts", "budget", "'USD'"},
+	{"budget_alerts", "spent", "'USD'"},
+}
+
+// legacyCurrencyColumns held the currency of a whole row before each amount carried its own
+var legacyCurrencyColumns = []struct{ table, column string }{
+	{"invoices", "currency"},
+	{"payment_transactions", "currency"},
+}
+
+// migrateMoney converts float amount columns to minor units and a currency.
+// It runs before AutoMigrate so the new columns are filled from the old ones,
+// and does nothing for columns that are already converted.
+func migrateMoney(db *gorm.DB) error {
+	scale := minorUnitScale()
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, mc := range moneyColumns {
+			if !tx.Migrator().HasTable(mc.table) || !tx.Migrator().HasColumn(mc.table, mc.column) {
+				continue
+			}
+
+			currency := fmt.Sprintf("upper(COALESCE(NULLIF(%s, ''), '%s'))", mc.currency, money.DefaultCurrency)
+			statements := []string{
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s_minor bigint NOT NULL DEFAULT 0", mc.table, mc.column),
+				fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s_currency varchar(3)", mc.table, mc.column),
+				fmt.Sprintf("UPDATE %s SET %s_currency = %s", mc.table, mc.column, currency),
+				fmt.Sprintf(
+					"UPDATE %s SET %s_minor = ROUND(COALESCE(%s, 0)::numeric * %s)",
+					mc.table, mc.column, mc.column, strings.ReplaceAll(scale, "@currency", mc.column+"_currency"),
+				),
+				fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s", mc.table, mc.column),
+			}
+			for _, stmt := range statements {
+				if err := tx.Exec(stmt).Error; err != nil {
+					return fmt.Errorf("failed to convert %s.%s to minor units: %v", mc.table, mc.column, err)
+				}
+			}
+		}
+
+		for _, lc := range legacyCurrencyColumns {
+			if !tx.Migrator().HasTable(lc.table) || !tx.Migrator().HasColumn(lc.table, lc.column) {
+				continue
+			}
+			if err := tx.Migrator().DropColumn(lc.table, lc.column); err != nil {
+				return fmt.Errorf("failed to drop %s.%s: %v", lc.table, lc.column, err)
+			}
+		}
+		return nil
+	})
+}
+
+// minorUnitScale builds an SQL expression giving the number of minor units in
+// one major unit of the currency in @currency
+func minorUnitScale() string {
+	exponents := money.Exponents()
+	currencies := make([]string, 0, len(exponents))
+	for currency := range exponents {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	var b strings.Builder
+	b.WriteString("CASE @currency")
+	for _, currency := range currencies {
+		scale := 1
+		for i := 0; i < exponents[currency]; i++ {
+			scale *= 10
+		}
+		fmt.Fprintf(&b, " WHEN '%s' THEN %d", currency, scale)
+	}
+	b.WriteString(" ELSE 100 END")
+	return b.String()
+}
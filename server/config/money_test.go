@@ -0,0 +1,159 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestMinorUnitScale(t *testing.T) {
+	scale := minorUnitScale()
+
+	for _, want := range []string{"CASE @currency ", " WHEN 'JPY' THEN 1 ", " WHEN 'BHD' THEN 1000 ", " WHEN 'CLF' THEN 10000 ", " ELSE 100 END"} {
+		if !strings.Contains(scale, want) {
+			t.Errorf("minorUnitScale() = %s, missing %q", scale, want)
+		}
+	}
+	if strings.Contains(scale, "'USD'") || strings.Contains(scale, "'EUR'") {
+		t.Errorf("minorUnitScale() lists currencies with the default exponent: %s", scale)
+	}
+	if minorUnitScale() != scale {
+		t.Error("minorUnitScale() is not deterministic")
+	}
+}
+
+// TestMigrateMoney converts float columns in a scratch schema of the
+// database in TEST_DATABASE_DSN, such as
+// "host=localhost user=postgres password=postgres dbname=chorvo_test sslmode=disable"
+func TestMigrateMoney(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	random := make([]byte, 6)
+	rand.Read(random)
+	schema := "money_test_" + hex.EncodeToString(random)
+
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+
+	db, err := gorm.Open(postgres.Open(dsn+" search_path="+schema), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connecting to schema: %v", err)
+	}
+
+	setup := []string{
+		"CREATE TABLE invoices (id bigint PRIMARY KEY, amount double precision, currency varchar(3))",
+		"CREATE TABLE invoice_items (id bigint PRIMARY KEY, invoice_id bigint, unit_price double precision, amount double precision)",
+		"CREATE TABLE expenses (id bigint PRIMARY KEY, amount double precision)",
+	}
+	for _, stmt := range setup {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	// Amounts that floats cannot represent exactly must still land on the
+	// nearest minor unit instead of being truncated. The sum is computed at
+	// run time, as constant arithmetic would give exactly 0.3.
+	tenth, fifth := 0.1, 0.2
+	sum := tenth + fifth
+	if sum == 0.3 {
+		t.Fatal("0.1 + 0.2 is exact; the test would prove nothing")
+	}
+
+	invoices := []struct {
+		id       int
+		amount   interface{}
+		currency string
+		want     int64
+		wantCur  string
+	}{
+		{1, sum, "usd", 30, "USD"},
+		{2, 19.99, "USD", 1999, "USD"},
+		{3, -19.99, "EUR", -1999, "EUR"},
+		{4, 1.005, "USD", 101, "USD"},
+		{5, 1234.0, "JPY", 1234, "JPY"},
+		{6, 1234.5, "JPY", 1235, "JPY"},
+		{7, 1.2345, "BHD", 1235, "BHD"},
+		{8, 99.995, "", 10000, "USD"},
+		{9, nil, "USD", 0, "USD"},
+		{10, 12345678.91, "USD", 1234567891, "USD"},
+	}
+	for _, inv := range invoices {
+		err := db.Exec("INSERT INTO invoices (id, amount, currency) VALUES (?, ?, ?)", inv.id, inv.amount, inv.currency).Error
+		if err != nil {
+			t.Fatalf("inserting invoice %d: %v", inv.id, err)
+		}
+	}
+	if err := db.Exec("INSERT INTO invoice_items (id, invoice_id, unit_price, amount) VALUES (1, 5, 617.0, 1234.0), (2, 2, 6.663333, 19.99)").Error; err != nil {
+		t.Fatalf("inserting items: %v", err)
+	}
+	if err := db.Exec("INSERT INTO expenses (id, amount) VALUES (1, ?)", sum).Error; err != nil {
+		t.Fatalf("inserting expense: %v", err)
+	}
+
+	if err := migrateMoney(db); err != nil {
+		t.Fatalf("migrateMoney: %v", err)
+	}
+
+	for _, inv := range invoices {
+		var row struct {
+			AmountMinor    int64
+			AmountCurrency string
+		}
+		if err := db.Raw("SELECT amount_minor, amount_currency FROM invoices WHERE id = ?", inv.id).Scan(&row).Error; err != nil {
+			t.Fatalf("reading invoice %d: %v", inv.id, err)
+		}
+		if row.AmountMinor != inv.want || row.AmountCurrency != inv.wantCur {
+			t.Errorf("invoice %d amount %v %q = %d %s, want %d %s", inv.id, inv.amount, inv.currency, row.AmountMinor, row.AmountCurrency, inv.want, inv.wantCur)
+		}
+	}
+
+	var items []struct {
+		UnitPriceMinor    int64
+		UnitPriceCurrency string
+		AmountMinor       int64
+	}
+	if err := db.Raw("SELECT unit_price_minor, unit_price_currency, amount_minor FROM invoice_items ORDER BY id").Scan(&items).Error; err != nil {
+		t.Fatalf("reading items: %v", err)
+	}
+	if len(items) != 2 || items[0].UnitPriceMinor != 617 || items[0].UnitPriceCurrency != "JPY" || items[0].AmountMinor != 1234 ||
+		items[1].UnitPriceMinor != 666 || items[1].UnitPriceCurrency != "USD" || items[1].AmountMinor != 1999 {
+		t.Errorf("items = %+v", items)
+	}
+
+	var expense int64
+	if err := db.Raw("SELECT amount_minor FROM expenses WHERE id = 1").Scan(&expense).Error; err != nil || expense != 30 {
+		t.Errorf("expense amount_minor = %d, %v, want 30", expense, err)
+	}
+
+	for _, column := range []struct{ table, column string }{{"invoices", "amount"}, {"invoices", "currency"}, {"expenses", "amount"}} {
+		if db.Migrator().HasColumn(column.table, column.column) {
+			t.Errorf("%s.%s was not dropped", column.table, column.column)
+		}
+	}
+
+	// Running the migration again leaves converted columns alone
+	if err := migrateMoney(db); err != nil {
+		t.Fatalf("second migrateMoney: %v", err)
+	}
+	var again int64
+	db.Raw("SELECT amount_minor FROM invoices WHERE id = 2").Scan(&again)
+	if again != 1999 {
+		t.Errorf("amount_minor after second run = %d, want 1999", again)
+	}
+}
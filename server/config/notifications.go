@@ -0,0 +1,52 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// legacyNotificationColumns turned a whole channel off for a user before
+// notifications could be chosen per event and channel
+var legacyNotificationColumns = []struct {
+	column  string
+	channel models.NotificationChannel
+}{
+	{"email_notifications", models.NotificationChannelEmail},
+	{"push_notifications", models.NotificationChannelPush},
+}
+
+// migrateNotificationPreferences turns the channels users switched off into
+// a preference for every type of notification on that channel, then drops
+// the old columns. It runs after AutoMigrate has created the preferences
+// table, and does nothing once the columns are gone.
+func migrateNotificationPreferences(db *gorm.DB) error {
+	types := make([]string, len(models.NotificationTypes))
+	for i, info := range models.NotificationTypes {
+		types[i] = fmt.Sprintf("('%s')", info.Type)
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		for _, lc := range legacyNotificationColumns {
+			if !tx.Migrator().HasColumn(&models.User{}, lc.column) {
+				continue
+			}
+			err := tx.Exec(fmt.Sprintf(
+				`INSERT INTO notification_preferences (user_id, type, channel, enabled, updated_at)
+SELECT users.id, t.type, ?, false, now() FROM users CROSS JOIN (VALUES %s) AS t(type)
+WHERE users.%s = false
+ON CONFLICT DO NOTHING`,
+				strings.Join(types, ", "), lc.column,
+			), lc.channel).Error
+			if err != nil {
+				return fmt.Errorf("failed to migrate users.%s: %v", lc.column, err)
+			}
+			if err := tx.Migrator().DropColumn(&models.User{}, lc.column); err != nil {
+				return fmt.Errorf("failed to drop users.%s: %v", lc.column, err)
+			}
+		}
+		return nil
+	})
+}
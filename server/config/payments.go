@@ -0,0 +1,47 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/payments"
+)
+
+// PaymentConfig holds the payment provider settings
+type PaymentConfig struct {
+	Provider      string // fake or stripe
+	WebhookSecret string // verifies the provider's webhooks
+	Stripe        payments.StripeConfig
+}
+
+// LoadPaymentConfig reads the payment provider settings from the environment
+func LoadPaymentConfig() (*PaymentConfig, error) {
+	config := &PaymentConfig{
+		Provider:      getEnv("PAYMENT_PROVIDER", "fake"),
+		WebhookSecret: os.Getenv("PAYMENT_WEBHOOK_SECRET"),
+		Stripe: payments.StripeConfig{
+			APIURL:    os.Getenv("STRIPE_API_URL"),
+			SecretKey: os.Getenv("STRIPE_SECRET_KEY"),
+		},
+	}
+	config.Stripe.WebhookSecret = config.WebhookSecret
+	if config.Provider == "fake" && os.Getenv("ENV") == "production" {
+		return nil, fmt.Errorf("PAYMENT_PROVIDER must be set in production")
+	}
+	return config, nil
+}
+
+// ConnectPayments creates the configured payment provider
+func ConnectPayments(config *PaymentConfig) (payments.PaymentProvider, error) {
+	switch config.Provider {
+	case "fake":
+		return payments.NewFakeProvider(config.WebhookSecret), nil
+	case "stripe":
+		provider, err := payments.NewStripeProvider(config.Stripe)
+		if err != nil {
+			return nil, err
+		}
+		return provider, nil
+	}
+	return nil, fmt.Errorf("unknown payment provider %q", config.Provider)
+}
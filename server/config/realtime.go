@@ -0,0 +1,18 @@
+package config
+
+import (
+	"context"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/realtime"
+)
+
+// ListenRealtime hands the changes announced through the database to the
+// hub until ctx is done
+func ListenRealtime(ctx context.Context, hub *realtime.Hub) error {
+	config, err := LoadDBConfig()
+	if err != nil {
+		return err
+	}
+	go realtime.Listen(ctx, config.DSN(), hub)
+	return nil
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// searchDocument describes a table whose rows are full-text searchable.
+// Columns are weighted from A (most relevant) to D.
+type searchDocument struct {
+	table   string
+	columns []searchColumn
+}
+
+type searchColumn struct {
+	name   string
+	weight string
+}
+
+var searchDocuments = []searchDocument{
+	{table: "tasks", columns: []searchColumn{{"title", "A"}, {"description", "B"}}},
+	{table: "projects", columns: []searchColumn{{"name", "A"}, {"description", "B"}}},
+	{table: "comments", columns: []searchColumn{{"content", "B"}}},
+}
+
+// migrateSearch adds a search_vector column with a GIN index to every
+// searchable table, kept up to date by a trigger. Text is stemmed with the
+// row's search_language and also indexed unstemmed at weight D, so searches
+// in another language still find exact words.
+func migrateSearch(db *gorm.DB) error {
+	for _, doc := range searchDocuments {
+		var stemmed, columns []string
+		var plain []string
+		for _, column := range doc.columns {
+			stemmed = append(stemmed, fmt.Sprintf(
+				"setweight(to_tsvector(NEW.search_language::regconfig, coalesce(NEW.%s, '')), '%s')",
+				column.name, column.weight,
+			))
+			columns = append(columns, column.name)
+			plain = append(plain, fmt.Sprintf("coalesce(NEW.%s, '')", column.name))
+		}
+		vector := strings.Join(stemmed, " || ") +
+			fmt.Sprintf(" || setweight(to_tsvector('simple', %s), 'D')", strings.Join(plain, " || ' ' || "))
+
+		statements := []string{
+			fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS search_vector tsvector", doc.table),
+			fmt.Sprintf("CREATE INDEX IF NOT EXISTS idx_%s_search_vector ON %s USING GIN (search_vector)", doc.table, doc.table),
+			fmt.Sprintf(`CREATE OR REPLACE FUNCTION %s_search_vector_update() RETURNS trigger AS $$
+BEGIN
+	NEW.search_vector := %s;
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql`, doc.table, vector),
+			fmt.Sprintf("DROP TRIGGER IF EXISTS %s_search_vector ON %s", doc.table, doc.table),
+			fmt.Sprintf(
+				"CREATE TRIGGER %s_search_vector BEFORE INSERT OR UPDATE OF %s, search_language ON %s FOR EACH ROW EXECUTE FUNCTION %s_search_vector_update()",
+				doc.table, strings.Join(columns, ", "), doc.table, doc.table,
+			),
+			// Index rows written before search existed
+			fmt.Sprintf("UPDATE %s SET search_language = search_language WHERE search_vector IS NULL", doc.table),
+		}
+
+		for _, statement := range statements {
+			if err := db.Exec(statement).Error; err != nil {
+				return fmt.Errorf("failed to set up search for %s: %v", doc.table, err)
+			}
+		}
+	}
+	return nil
+}
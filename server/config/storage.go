@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/storage"
+)
+
+const defaultMaxUploadMB = 25
+
+// StorageConfig holds the attachment storage settings
+type StorageConfig struct {
+	Backend        string // local or s3
+	LocalPath      string
+	PublicURL      string // base URL of the /files routes for the local backend
+	SigningKey     string
+	S3             storage.S3Config
+	ClamdAddr      string
+	MaxFileSize    int64 // in bytes
+	UploadExpiry   time.Duration
+	DownloadExpiry time.Duration
+}
+
+// LoadStorageConfig reads the storage settings from the environment
+func LoadStorageConfig() (*StorageConfig, error) {
+	config := &StorageConfig{
+		Backend:   getEnv("STORAGE_BACKEND", "local"),
+		LocalPath: getEnv("STORAGE_LOCAL_PATH", "./uploads"),
+		PublicURL: getEnv("STORAGE_PUBLIC_URL", "http://localhost:"+getEnv("PORT", "8080")+"/files"),
+		S3: storage.S3Config{
+			Endpoint:        os.Getenv("S3_ENDPOINT"),
+			Region:          os.Getenv("S3_REGION"),
+			Bucket:          os.Getenv("S3_BUCKET"),
+			AccessKeyID:     os.Getenv("S3_ACCESS_KEY_ID"),
+			SecretAccessKey: os.Getenv("S3_SECRET_ACCESS_KEY"),
+			PathStyle:       os.Getenv("S3_PATH_STYLE") == "true",
+		},
+		ClamdAddr:      os.Getenv("CLAMD_ADDR"),
+		UploadExpiry:   15 * time.Minute,
+		DownloadExpiry: 5 * time.Minute,
+	}
+	if config.Backend == "local" {
+		key, err := signingKey("STORAGE_SIGNING_KEY", "storage URL signing")
+		if err != nil {
+			return nil, fmt.Errorf("signing local storage URLs: %w", err)
+		}
+		config.SigningKey = key
+	}
+
+	maxMB := defaultMaxUploadMB
+	if raw := os.Getenv("ATTACHMENT_MAX_SIZE_MB"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid ATTACHMENT_MAX_SIZE_MB %q", raw)
+		}
+		maxMB = n
+	}
+	config.MaxFileSize = int64(maxMB) << 20
+
+	return config, nil
+}
+
+// ConnectStorage creates the configured storage backend and virus scanner
+func ConnectStorage(config *StorageConfig) (storage.Storage, storage.Scanner, error) {
+	var scanner storage.Scanner = storage.NoopScanner{}
+	if config.ClamdAddr != "" {
+		scanner = storage.ClamdScanner{Addr: config.ClamdAddr}
+	}
+
+	switch config.Backend {
+	case "local":
+		if config.SigningKey == "" {
+			return nil, nil, fmt.Errorf("a signing key is required for local storage")
+		}
+		store, err := storage.NewLocalStorage(config.LocalPath, config.PublicURL, []byte(config.SigningKey))
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, scanner, nil
+	case "s3":
+		store, err := storage.NewS3Storage(config.S3)
+		if err != nil {
+			return nil, nil, err
+		}
+		return store, scanner, nil
+	}
+	return nil, nil, fmt.Errorf("unknown storage backend %q", config.Backend)
+}
+
+func getEnv(name, fallback string) string {
+	if value := os.Getenv(name); value != "" {
+		return value
+	}
+	return fallback
+}
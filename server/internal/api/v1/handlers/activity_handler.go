@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/gin-gonic/gin"
+)
+
+// ActivityHandler serves activity feeds
+type ActivityHandler struct {
+	activityService *services.ActivityService
+}
+
+// NewActivityHandler creates a new instance of ActivityHandler
+func NewActivityHandler(activityService *services.ActivityService) *ActivityHandler {
+	return &ActivityHandler{
+		activityService: activityService,
+	}
+}
+
+// GetTaskActivity returns the activity feed of a task
+func (h *ActivityHandler) GetTaskActivity(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	limit, ok := parseLimitQuery(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.activityService.TaskFeed(c.Request.Context(), middleware.GetUserID(c), taskID, c.Query("cursor"), limit)
+	if err != nil {
+		respondActivityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetProjectActivity returns the activity feed of a project
+func (h *ActivityHandler) GetProjectActivity(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	limit, ok := parseLimitQuery(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.activityService.ProjectFeed(c.Request.Context(), middleware.GetUserID(c), projectID, c.Query("cursor"), limit)
+	if err != nil {
+		respondActivityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetOrganizationActivity returns the activity feed of an organization
+func (h *ActivityHandler) GetOrganizationActivity(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	limit, ok := parseLimitQuery(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.activityService.OrganizationFeed(c.Request.Context(), middleware.GetUserID(c), orgID, c.Query("cursor"), limit)
+	if err != nil {
+		respondActivityError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+func respondActivityError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, services.ErrTaskNotFound), errors.Is(err, services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidCursor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load activity"})
+	}
+}
@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// AddOnHandler handles add-on catalog and purchase requests
+type AddOnHandler struct {
+	addOnService *services.AddOnService
+}
+
+// NewAddOnHandler creates a new instance of AddOnHandler
+func NewAddOnHandler(addOnService *services.AddOnService) *AddOnHandler {
+	return &AddOnHandler{
+		addOnService: addOnService,
+	}
+}
+
+type AddOnRequest struct {
+	Name        string      `json:"name" binding:"required"`
+	Description string      `json:"description"`
+	Price       json.Number `json:"price" binding:"required"`
+	Currency    string      `json:"currency" binding:"required"`
+}
+
+func (r AddOnRequest) toInput() services.AddOnInput {
+	return services.AddOnInput{
+		Name:        r.Name,
+		Description: r.Description,
+		Price:       r.Price.String(),
+		Currency:    r.Currency,
+	}
+}
+
+type AddOnQuantityRequest struct {
+	Quantity *int `json:"quantity" binding:"required"`
+}
+
+// ListAddOns lists the add-ons on offer; platform admins can add ?archived=true
+func (h *AddOnHandler) ListAddOns(c *gin.Context) {
+	addOns, err := h.addOnService.ListAddOns(c.Request.Context(), middleware.GetUserID(c), c.Query("archived") == "true")
+	if err != nil {
+		respondAddOnError(c, err, "Failed to list add-ons")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"add_ons": addOns})
+}
+
+// CreateAddOn adds an add-on to the catalog
+func (h *AddOnHandler) CreateAddOn(c *gin.Context) {
+	var req AddOnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	addOn, err := h.addOnService.CreateAddOn(c.Request.Context(), middleware.GetUserID(c), req.toInput())
+	if err != nil {
+		respondAddOnError(c, err, "Failed to create add-on")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"add_on": addOn})
+}
+
+// UpdateAddOn changes an add-on
+func (h *AddOnHandler) UpdateAddOn(c *gin.Context) {
+	addOnID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req AddOnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	addOn, err := h.addOnService.UpdateAddOn(c.Request.Context(), middleware.GetUserID(c), addOnID, req.toInput())
+	if err != nil {
+		respondAddOnError(c, err, "Failed to update add-on")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"add_on": addOn})
+}
+
+// ArchiveAddOn withdraws an add-on from the catalog
+func (h *AddOnHandler) ArchiveAddOn(c *gin.Context) {
+	addOnID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	addOn, err := h.addOnService.ArchiveAddOn(c.Request.Context(), middleware.GetUserID(c), addOnID)
+	if err != nil {
+		respondAddOnError(c, err, "Failed to archive add-on")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"add_on": addOn})
+}
+
+// ListOrganizationAddOns lists the add-ons the organization buys
+func (h *AddOnHandler) ListOrganizationAddOns(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	addOns, err := h.addOnService.ListOrganizationAddOns(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondAddOnError(c, err, "Failed to list add-ons")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"add_ons": addOns})
+}
+
+// SetOrganizationAddOn sets how many units of an add-on the organization buys
+func (h *AddOnHandler) SetOrganizationAddOn(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	addOnID, ok := parseIDParam(c, "addOnId")
+	if !ok {
+		return
+	}
+
+	var req AddOnQuantityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	addOns, err := h.addOnService.SetOrganizationAddOn(c.Request.Context(), middleware.GetUserID(c), orgID, addOnID, *req.Quantity)
+	if err != nil {
+		respondAddOnError(c, err, "Failed to update add-ons")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"add_ons": addOns})
+}
+
+func respondAddOnError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrAddOnNotFound), errors.Is(err, services.ErrNoSubscription):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSubscriptionNotActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAddOnArchived), errors.Is(err, services.ErrInvalidAddOnQuantity),
+		errors.Is(err, models.ErrEmptyAddOnName), errors.Is(err, models.ErrInvalidAddOnPrice),
+		isMoneyError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// multipartOverhead is the room left for form fields around an uploaded file
+const multipartOverhead = 1 << 20
+
+// AttachmentHandler handles attachment upload and download requests
+type AttachmentHandler struct {
+	attachmentService *services.AttachmentService
+	maxFileSize       int64
+}
+
+// NewAttachmentHandler creates a new instance of AttachmentHandler
+func NewAttachmentHandler(attachmentService *services.AttachmentService, maxFileSize int64) *AttachmentHandler {
+	return &AttachmentHandler{
+		attachmentService: attachmentService,
+		maxFileSize:       maxFileSize,
+	}
+}
+
+type PresignUploadRequest struct {
+	FileName    string `json:"file_name" binding:"required"`
+	Size        int64  `json:"size" binding:"required,gt=0"`
+	ContentType string `json:"content_type"`
+}
+
+// UploadTaskAttachment uploads a file to a task as the multipart form field "file"
+func (h *AttachmentHandler) UploadTaskAttachment(c *gin.Context) {
+	h.upload(c, "task")
+}
+
+// UploadCommentAttachment uploads a file to a comment as the multipart form field "file"
+func (h *AttachmentHandler) UploadCommentAttachment(c *gin.Context) {
+	h.upload(c, "comment")
+}
+
+// PresignTaskAttachment returns a URL to upload a task attachment to directly
+func (h *AttachmentHandler) PresignTaskAttachment(c *gin.Context) {
+	h.presign(c, "task")
+}
+
+// PresignCommentAttachment returns a URL to upload a comment attachment to directly
+func (h *AttachmentHandler) PresignCommentAttachment(c *gin.Context) {
+	h.presign(c, "comment")
+}
+
+// ListTaskAttachments lists the attachments of a task
+func (h *AttachmentHandler) ListTaskAttachments(c *gin.Context) {
+	h.list(c, "task")
+}
+
+// ListCommentAttachments lists the attachments of a comment
+func (h *AttachmentHandler) ListCommentAttachments(c *gin.Context) {
+	h.list(c, "comment")
+}
+
+// UploadExpenseReceipt uploads a receipt to an expense as the multipart form field "file"
+func (h *AttachmentHandler) UploadExpenseReceipt(c *gin.Context) {
+	h.upload(c, "expense")
+}
+
+// PresignExpenseReceipt returns a URL to upload an expense receipt to directly
+func (h *AttachmentHandler) PresignExpenseReceipt(c *gin.Context) {
+	h.presign(c, "expense")
+}
+
+// ListExpenseReceipts lists the receipts of an expense
+func (h *AttachmentHandler) ListExpenseReceipts(c *gin.Context) {
+	h.list(c, "expense")
+}
+
+// CompleteUpload finishes a presigned upload once the client has sent the file
+func (h *AttachmentHandler) CompleteUpload(c *gin.Context) {
+	attachmentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	attachment, err := h.attachmentService.CompleteUpload(c.Request.Context(), middleware.GetUserID(c), attachmentID)
+	if err != nil {
+		respondAttachmentError(c, err, "Failed to complete upload")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachment": attachment})
+}
+
+// GetAttachment returns an attachment with a short-lived download URL
+func (h *AttachmentHandler) GetAttachment(c *gin.Context) {
+	attachmentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	userID := middleware.GetUserID(c)
+	attachment, err := h.attachmentService.GetAttachment(ctx, userID, attachmentID)
+	if err != nil {
+		respondAttachmentError(c, err, "Failed to get attachment")
+		return
+	}
+
+	response := gin.H{"attachment": attachment}
+	if attachment.IsAvailable() {
+		url, err := h.attachmentService.DownloadURL(ctx, userID, attachmentID)
+		if err != nil {
+			respondAttachmentError(c, err, "Failed to get attachment")
+			return
+		}
+		response["download_url"] = url
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// DownloadAttachment redirects to a short-lived download URL
+func (h *AttachmentHandler) DownloadAttachment(c *gin.Context) {
+	attachmentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	url, err := h.attachmentService.DownloadURL(c.Request.Context(), middleware.GetUserID(c), attachmentID)
+	if err != nil {
+		respondAttachmentError(c, err, "Failed to download attachment")
+		return
+	}
+
+	c.Redirect(http.StatusFound, url)
+}
+
+// DeleteAttachment deletes an attachment
+func (h *AttachmentHandler) DeleteAttachment(c *gin.Context) {
+	attachmentID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.attachmentService.DeleteAttachment(c.Request.Context(), middleware.GetUserID(c), attachmentID); err != nil {
+		respondAttachmentError(c, err, "Failed to delete attachment")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted successfully"})
+}
+
+func (h *AttachmentHandler) upload(c *gin.Context, kind string) {
+	target, ok := attachmentTarget(c, kind)
+	if !ok {
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxFileSize+multipartOverhead)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondAttachmentError(c, services.ErrFileTooLarge, "")
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A file is required in the \"file\" form field"})
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read uploaded file"})
+		return
+	}
+	defer file.Close()
+
+	attachment, err := h.attachmentService.Upload(c.Request.Context(), middleware.GetUserID(c), target, header.Filename, header.Size, file)
+	if err != nil {
+		respondAttachmentError(c, err, "Failed to upload attachment")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"attachment": attachment})
+}
+
+func (h *AttachmentHandler) presign(c *gin.Context, kind string) {
+	target, ok := attachmentTarget(c, kind)
+	if !ok {
+		return
+	}
+
+	var req PresignUploadRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	upload, err := h.attachmentService.PresignUpload(c.Request.Context(), middleware.GetUserID(c), target, req.FileName, req.Size, req.ContentType)
+	if err != nil {
+		respondAttachmentError(c, err, "Failed to prepare upload")
+		return
+	}
+
+	c.JSON(http.StatusCreated, upload)
+}
+
+func (h *AttachmentHandler) list(c *gin.Context, kind string) {
+	target, ok := attachmentTarget(c, kind)
+	if !ok {
+		return
+	}
+
+	attachments, err := h.attachmentService.ListAttachments(c.Request.Context(), middleware.GetUserID(c), target)
+	if err != nil {
+		respondAttachmentError(c, err, "Failed to list attachments")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachments": attachments})
+}
+
+// attachmentTarget reads the task, comment or expense ID from the path
+func attachmentTarget(c *gin.Context, kind string) (services.AttachmentTarget, bool) {
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return services.AttachmentTarget{}, false
+	}
+	switch kind {
+	case "comment":
+		return services.AttachmentTarget{CommentID: &id}, true
+	case "expense":
+		return services.AttachmentTarget{ExpenseID: &id}, true
+	}
+	return services.AttachmentTarget{TaskID: &id}, true
+}
+
+func respondAttachmentError(c *gin.Context, err error, fallback string) {
+	var limitErr *models.LimitError
+	switch {
+	case errors.Is(err, services.ErrAttachmentNotFound), errors.Is(err, services.ErrTaskNotFound),
+		errors.Is(err, services.ErrCommentNotFound), errors.Is(err, services.ErrExpenseNotFound),
+		errors.Is(err, services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrFileTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+	case errors.As(err, &limitErr):
+		respondLimitError(c, limitErr)
+	case errors.Is(err, services.ErrInfectedFile):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUploadNotPending), errors.Is(err, services.ErrAttachmentNotReady):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUploadMissing), errors.Is(err, models.ErrEmptyFileName),
+		errors.Is(err, models.ErrInvalidFileSize):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/gin-gonic/gin"
+)
+
+// BudgetHandler handles project budget, hourly rate and cost report requests
+type BudgetHandler struct {
+	budgetService *services.BudgetService
+}
+
+// NewBudgetHandler creates a new instance of BudgetHandler
+func NewBudgetHandler(budgetService *services.BudgetService) *BudgetHandler {
+	return &BudgetHandler{
+		budgetService: budgetService,
+	}
+}
+
+type BudgetLineRequest struct {
+	Category    string      `json:"category" binding:"required"`
+	Description string      `json:"description"`
+	Amount      json.Number `json:"amount" binding:"required"`
+	Currency    string      `json:"currency"` // defaults to the project's budget currency
+}
+
+type HourlyRateRequest struct {
+	UserID        uint        `json:"user_id" binding:"required"`
+	Rate          json.Number `json:"rate" binding:"required"`
+	Currency      string      `json:"currency" binding:"required"`
+	EffectiveFrom string      `json:"effective_from"` // YYYY-MM-DD; defaults to today
+}
+
+func (r BudgetLineRequest) toInput() services.BudgetLineInput {
+	return services.BudgetLineInput{
+		Category:    r.Category,
+		Description: r.Description,
+		Amount:      r.Amount.String(),
+		Currency:    r.Currency,
+	}
+}
+
+// ListBudgetLines lists the budget lines of a project
+func (h *BudgetHandler) ListBudgetLines(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	lines, err := h.budgetService.ListLines(c.Request.Context(), middleware.GetUserID(c), projectID)
+	if err != nil {
+		respondBudgetError(c, err, "Failed to list budget lines")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"budget_lines": lines})
+}
+
+// CreateBudgetLine adds a category to a project's budget
+func (h *BudgetHandler) CreateBudgetLine(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req BudgetLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	line, err := h.budgetService.CreateLine(c.Request.Context(), middleware.GetUserID(c), projectID, req.toInput())
+	if err != nil {
+		respondBudgetError(c, err, "Failed to create budget line")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"budget_line": line})
+}
+
+// UpdateBudgetLine changes a budget line
+func (h *BudgetHandler) UpdateBudgetLine(c *gin.Context) {
+	lineID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req BudgetLineRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	line, err := h.budgetService.UpdateLine(c.Request.Context(), middleware.GetUserID(c), lineID, req.toInput())
+	if err != nil {
+		respondBudgetError(c, err, "Failed to update budget line")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"budget_line": line})
+}
+
+// DeleteBudgetLine removes a budget line
+func (h *BudgetHandler) DeleteBudgetLine(c *gin.Context) {
+	lineID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.budgetService.DeleteLine(c.Request.Context(), middleware.GetUserID(c), lineID); err != nil {
+		respondBudgetError(c, err, "Failed to delete budget line")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Budget line deleted successfully"})
+}
+
+// GetBudgetReport returns a project's budget against its actual and forecast cost
+func (h *BudgetHandler) GetBudgetReport(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	report, err := h.budgetService.Report(c.Request.Context(), middleware.GetUserID(c), projectID)
+	if err != nil {
+		respondBudgetError(c, err, "Failed to build budget report")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"report": report})
+}
+
+// ListBudgetAlerts lists the spending thresholds a project has crossed
+func (h *BudgetHandler) ListBudgetAlerts(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	alerts, err := h.budgetService.ListAlerts(c.Request.Context(), middleware.GetUserID(c), projectID)
+	if err != nil {
+		respondBudgetError(c, err, "Failed to list budget alerts")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"alerts": alerts})
+}
+
+// ListHourlyRates lists the hourly rates of an organization's members
+func (h *BudgetHandler) ListHourlyRates(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	rates, err := h.budgetService.ListHourlyRates(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondBudgetError(c, err, "Failed to list hourly rates")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"hourly_rates": rates})
+}
+
+// SetHourlyRate sets a member's hourly rate from a date on
+func (h *BudgetHandler) SetHourlyRate(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req HourlyRateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	effectiveFrom := time.Now()
+	if req.EffectiveFrom != "" {
+		parsed, err := time.Parse(dateLayout, req.EffectiveFrom)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "effective_from must be a date in YYYY-MM-DD format"})
+			return
+		}
+		effectiveFrom = parsed
+	}
+
+	rate, err := h.budgetService.SetHourlyRate(c.Request.Context(), middleware.GetUserID(c), orgID, services.HourlyRateInput{
+		UserID:        req.UserID,
+		Rate:          req.Rate.String(),
+		Currency:      req.Currency,
+		EffectiveFrom: effectiveFrom,
+	})
+	if err != nil {
+		respondBudgetError(c, err, "Failed to set hourly rate")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"hourly_rate": rate})
+}
+
+func respondBudgetError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrBudgetLineNotFound), errors.Is(err, services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDuplicateBudgetLine):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNotOrganizationMember), errors.Is(err, models.ErrEmptyBudgetCategory),
+		errors.Is(err, models.ErrInvalidBudgetAmount), errors.Is(err, models.ErrInvalidHourlyRate),
+		isMoneyError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrFeatureNotEntitled):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// isMoneyError reports whether err comes from an invalid amount or currency
+func isMoneyError(err error) bool {
+	return errors.Is(err, money.ErrInvalidAmount) || errors.Is(err, money.ErrTooPrecise) ||
+		errors.Is(err, money.ErrInvalidCurrency) || errors.Is(err, money.ErrCurrencyMismatch) ||
+		errors.Is(err, money.ErrOverflow)
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CouponHandler handles coupon, discount and account credit requests
+type CouponHandler struct {
+	couponService *services.CouponService
+}
+
+// NewCouponHandler creates a new instance of CouponHandler
+func NewCouponHandler(couponService *services.CouponService) *CouponHandler {
+	return &CouponHandler{
+		couponService: couponService,
+	}
+}
+
+type CouponRequest struct {
+	Code           string                `json:"code" binding:"required"`
+	Name           string                `json:"name"`
+	DiscountType   models.DiscountType   `json:"discount_type" binding:"required"`
+	PercentOff     int                   `json:"percent_off"`
+	AmountOff      json.Number           `json:"amount_off"`
+	Currency       string                `json:"currency"`
+	Duration       models.CouponDuration `json:"duration" binding:"required"`
+	DurationCycles int                   `json:"duration_cycles"`
+	MaxRedemptions int                   `json:"max_redemptions"`
+	ExpiresAt      *time.Time            `json:"expires_at"`
+}
+
+type RedeemCouponRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type CreditRequest struct {
+	Amount      json.Number `json:"amount" binding:"required"`
+	Currency    string      `json:"currency" binding:"required"`
+	Description string      `json:"description"`
+}
+
+// ListCoupons lists the coupons; add ?archived=true to include archived ones
+func (h *CouponHandler) ListCoupons(c *gin.Context) {
+	coupons, err := h.couponService.ListCoupons(c.Request.Context(), middleware.GetUserID(c), c.Query("archived") == "true")
+	if err != nil {
+		respondCouponError(c, err, "Failed to list coupons")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"coupons": coupons})
+}
+
+// CreateCoupon adds a coupon
+func (h *CouponHandler) CreateCoupon(c *gin.Context) {
+	var req CouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	coupon, err := h.couponService.CreateCoupon(c.Request.Context(), middleware.GetUserID(c), services.CouponInput{
+		Code:           req.Code,
+		Name:           req.Name,
+		DiscountType:   req.DiscountType,
+		PercentOff:     req.PercentOff,
+		AmountOff:      req.AmountOff.String(),
+		Currency:       req.Currency,
+		Duration:       req.Duration,
+		DurationCycles: req.DurationCycles,
+		MaxRedemptions: req.MaxRedemptions,
+		ExpiresAt:      req.ExpiresAt,
+	})
+	if err != nil {
+		respondCouponError(c, err, "Failed to create coupon")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"coupon": coupon})
+}
+
+// ArchiveCoupon stops a coupon from being redeemed
+func (h *CouponHandler) ArchiveCoupon(c *gin.Context) {
+	couponID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	coupon, err := h.couponService.ArchiveCoupon(c.Request.Context(), middleware.GetUserID(c), couponID)
+	if err != nil {
+		respondCouponError(c, err, "Failed to archive coupon")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"coupon": coupon})
+}
+
+// GetDiscount returns the organization's running discount
+func (h *CouponHandler) GetDiscount(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	discount, err := h.couponService.GetDiscount(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondCouponError(c, err, "Failed to get discount")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"discount": discount})
+}
+
+// RedeemCoupon gives the organization a coupon's discount
+func (h *CouponHandler) RedeemCoupon(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req RedeemCouponRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	discount, err := h.couponService.RedeemCoupon(c.Request.Context(), middleware.GetUserID(c), orgID, req.Code)
+	if err != nil {
+		respondCouponError(c, err, "Failed to redeem coupon")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"discount": discount})
+}
+
+// RemoveDiscount ends the organization's running discount
+func (h *CouponHandler) RemoveDiscount(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.couponService.RemoveDiscount(c.Request.Context(), middleware.GetUserID(c), orgID); err != nil {
+		respondCouponError(c, err, "Failed to remove discount")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Discount removed successfully"})
+}
+
+// GetCredits returns the organization's account credit
+func (h *CouponHandler) GetCredits(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	credits, err := h.couponService.GetCredits(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondCouponError(c, err, "Failed to get credits")
+		return
+	}
+
+	c.JSON(http.StatusOK, credits)
+}
+
+// GrantCredit adds promotional credit to the organization's account
+func (h *CouponHandler) GrantCredit(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CreditRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	credits, err := h.couponService.GrantCredit(c.Request.Context(), middleware.GetUserID(c), orgID, services.CreditInput{
+		Amount:      req.Amount.String(),
+		Currency:    req.Currency,
+		Description: req.Description,
+	})
+	if err != nil {
+		respondCouponError(c, err, "Failed to grant credit")
+		return
+	}
+
+	c.JSON(http.StatusCreated, credits)
+}
+
+func respondCouponError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrCouponNotFound), errors.Is(err, services.ErrNoDiscount),
+		errors.Is(err, services.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCouponAlreadyRedeemed), errors.Is(err, services.ErrDiscountActive):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCouponNotRedeemable), errors.Is(err, services.ErrInvalidCreditAmount),
+		errors.Is(err, models.ErrInvalidCouponCode), errors.Is(err, models.ErrInvalidDiscountType),
+		errors.Is(err, models.ErrInvalidPercentOff), errors.Is(err, models.ErrInvalidAmountOff),
+		errors.Is(err, models.ErrInvalidCouponDuration), errors.Is(err, models.ErrInvalidDurationCycles),
+		errors.Is(err, models.ErrInvalidMaxRedemptions), isMoneyError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
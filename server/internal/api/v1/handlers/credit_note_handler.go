@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CreditNoteHandler handles credit note requests
+type CreditNoteHandler struct {
+	creditNoteService *services.CreditNoteService
+}
+
+// NewCreditNoteHandler creates a new instance of CreditNoteHandler
+func NewCreditNoteHandler(creditNoteService *services.CreditNoteService) *CreditNoteHandler {
+	return &CreditNoteHandler{
+		creditNoteService: creditNoteService,
+	}
+}
+
+// ListCreditNotes lists the organization's credit notes
+func (h *CreditNoteHandler) ListCreditNotes(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	notes, err := h.creditNoteService.ListCreditNotes(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondCreditNoteError(c, err, "Failed to list credit notes")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"credit_notes": notes})
+}
+
+// GetCreditNote returns a credit note
+func (h *CreditNoteHandler) GetCreditNote(c *gin.Context) {
+	noteID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	note, err := h.creditNoteService.GetCreditNote(c.Request.Context(), middleware.GetUserID(c), noteID)
+	if err != nil {
+		respondCreditNoteError(c, err, "Failed to get credit note")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"credit_note": note})
+}
+
+// DownloadPDF sends the credit note as a PDF file
+func (h *CreditNoteHandler) DownloadPDF(c *gin.Context) {
+	h.render(c, services.InvoiceFormatPDF, "application/pdf", "attachment")
+}
+
+// ViewHTML shows the credit note as a web page
+func (h *CreditNoteHandler) ViewHTML(c *gin.Context) {
+	h.render(c, services.InvoiceFormatHTML, "text/html; charset=utf-8", "inline")
+}
+
+func (h *CreditNoteHandler) render(c *gin.Context, format services.InvoiceFormat, contentType, disposition string) {
+	noteID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	note, err := h.creditNoteService.RenderCreditNote(c.Request.Context(), middleware.GetUserID(c), noteID, format, &buf)
+	if err != nil {
+		respondCreditNoteError(c, err, "Failed to render credit note")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, note.CreditNoteNumber+"."+string(format)))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+func respondCreditNoteError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrCreditNoteNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnknownInvoiceFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,196 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// CustomFieldHandler handles custom field definition and value requests
+type CustomFieldHandler struct {
+	fieldService *services.CustomFieldService
+}
+
+// NewCustomFieldHandler creates a new instance of CustomFieldHandler
+func NewCustomFieldHandler(fieldService *services.CustomFieldService) *CustomFieldHandler {
+	return &CustomFieldHandler{
+		fieldService: fieldService,
+	}
+}
+
+type CustomFieldRequest struct {
+	Name     string                 `json:"name" binding:"required"`
+	Key      string                 `json:"key" binding:"required"`
+	Type     models.CustomFieldType `json:"type" binding:"required"`
+	Options  []string               `json:"options"`
+	Currency string                 `json:"currency"`
+	Required bool                   `json:"required"`
+	Position int                    `json:"position"`
+}
+
+type CustomFieldTemplateRequest struct {
+	Template string `json:"template" binding:"required"`
+}
+
+type CustomFieldValuesRequest struct {
+	Values map[string]json.RawMessage `json:"values" binding:"required"`
+}
+
+func (r CustomFieldRequest) toInput() services.CustomFieldInput {
+	return services.CustomFieldInput{
+		Name:     r.Name,
+		Key:      r.Key,
+		Type:     r.Type,
+		Options:  r.Options,
+		Currency: r.Currency,
+		Required: r.Required,
+		Position: r.Position,
+	}
+}
+
+// CreateField defines a custom field on a project
+func (h *CustomFieldHandler) CreateField(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	field, err := h.fieldService.CreateField(c.Request.Context(), middleware.GetUserID(c), projectID, req.toInput())
+	if err != nil {
+		respondCustomFieldError(c, err, "Failed to create custom field")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"custom_field": field})
+}
+
+// ListFields lists the custom fields of a project
+func (h *CustomFieldHandler) ListFields(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	fields, err := h.fieldService.ListFields(c.Request.Context(), middleware.GetUserID(c), projectID)
+	if err != nil {
+		respondCustomFieldError(c, err, "Failed to list custom fields")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"custom_fields": fields})
+}
+
+// ApplyTemplate adds a category's preset custom fields to a project
+func (h *CustomFieldHandler) ApplyTemplate(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CustomFieldTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	fields, err := h.fieldService.ApplyTemplate(c.Request.Context(), middleware.GetUserID(c), projectID, req.Template)
+	if err != nil {
+		respondCustomFieldError(c, err, "Failed to apply custom field template")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"custom_fields": fields})
+}
+
+// UpdateField updates a custom field definition
+func (h *CustomFieldHandler) UpdateField(c *gin.Context) {
+	fieldID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CustomFieldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	field, err := h.fieldService.UpdateField(c.Request.Context(), middleware.GetUserID(c), fieldID, req.toInput())
+	if err != nil {
+		respondCustomFieldError(c, err, "Failed to update custom field")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"custom_field": field})
+}
+
+// DeleteField deletes a custom field and all of its values
+func (h *CustomFieldHandler) DeleteField(c *gin.Context) {
+	fieldID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.fieldService.DeleteField(c.Request.Context(), middleware.GetUserID(c), fieldID); err != nil {
+		respondCustomFieldError(c, err, "Failed to delete custom field")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Custom field deleted successfully"})
+}
+
+// SetTaskValues sets custom field values on a task
+func (h *CustomFieldHandler) SetTaskValues(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CustomFieldValuesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	values, err := h.fieldService.SetTaskValues(c.Request.Context(), middleware.GetUserID(c), taskID, req.Values)
+	if err != nil {
+		respondCustomFieldError(c, err, "Failed to update custom field values")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"custom_fields": values})
+}
+
+func respondCustomFieldError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrCustomFieldNotFound), errors.Is(err, services.ErrTaskNotFound),
+		errors.Is(err, services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCustomFieldKeyExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrCustomFieldTypeFixed), errors.Is(err, services.ErrUnknownTemplate),
+		errors.Is(err, services.ErrUnknownCustomField), errors.Is(err, services.ErrInvalidFieldUser),
+		errors.Is(err, models.ErrEmptyFieldName), errors.Is(err, models.ErrInvalidFieldKey),
+		errors.Is(err, models.ErrInvalidFieldType), errors.Is(err, models.ErrMissingFieldOptions),
+		errors.Is(err, models.ErrInvalidCurrency), errors.Is(err, models.ErrInvalidFieldValue),
+		errors.Is(err, models.ErrUnknownFieldOption), errors.Is(err, models.ErrFieldValueRequired):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrFeatureNotEntitled):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
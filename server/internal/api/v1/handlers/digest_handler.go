@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/mail"
+	"github.com/gin-gonic/gin"
+)
+
+// DigestHandler serves the user's email digest setting and the unsubscribe
+// links digests carry
+type DigestHandler struct {
+	digestService *services.DigestService
+}
+
+// NewDigestHandler creates a new instance of DigestHandler
+func NewDigestHandler(digestService *services.DigestService) *DigestHandler {
+	return &DigestHandler{
+		digestService: digestService,
+	}
+}
+
+// DigestRequest changes the user's digest. Fields left out keep their value.
+type DigestRequest struct {
+	Frequency models.DigestFrequency `json:"frequency"`
+	Hour      *int                   `json:"hour"`    // in the user's time zone
+	Weekday   *time.Weekday          `json:"weekday"` // 0 is Sunday; used by weekly digests
+}
+
+// GetDigest returns the user's digest setting
+func (h *DigestHandler) GetDigest(c *gin.Context) {
+	digest, err := h.digestService.GetDigest(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		respondDigestError(c, err, "Failed to load digest")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"digest": digest})
+}
+
+// UpdateDigest turns the user's digest on or off or changes when it is sent
+func (h *DigestHandler) UpdateDigest(c *gin.Context) {
+	var req DigestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	digest, err := h.digestService.UpdateDigest(c.Request.Context(), middleware.GetUserID(c), services.DigestInput{
+		Frequency: req.Frequency,
+		Hour:      req.Hour,
+		Weekday:   req.Weekday,
+	})
+	if err != nil {
+		respondDigestError(c, err, "Failed to update digest")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"digest": digest})
+}
+
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Unsubscribe - Chorvo</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; max-width: 480px; margin: 80px auto; text-align: center; }
+button { font-size: 16px; padding: 8px 20px; }
+</style>
+</head>
+<body>
+{{if .Done}}<h1>You are unsubscribed</h1>
+<p>You will no longer get the email digest. You can turn it back on in your settings.</p>
+{{else if .Invalid}}<h1>Link not valid</h1>
+<p>This unsubscribe link is not valid. You can turn the email digest off in your settings.</p>
+{{else}}<h1>Unsubscribe from the digest?</h1>
+<form method="post"><input type="hidden" name="token" value="{{.Token}}"><button type="submit">Unsubscribe</button></form>
+{{end}}</body>
+</html>
+`))
+
+type unsubscribePageData struct {
+	Token   string
+	Done    bool
+	Invalid bool
+}
+
+// ConfirmUnsubscribe asks the reader of a digest to confirm they want no
+// more. Unsubscribing takes a POST so that mail scanners following the link
+// do not unsubscribe anyone.
+func (h *DigestHandler) ConfirmUnsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		renderUnsubscribePage(c, http.StatusBadRequest, unsubscribePageData{Invalid: true})
+		return
+	}
+	renderUnsubscribePage(c, http.StatusOK, unsubscribePageData{Token: token})
+}
+
+// Unsubscribe turns off the digest an unsubscribe link was made for, from
+// the confirmation page or from mail clients' one-click unsubscribe
+func (h *DigestHandler) Unsubscribe(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		token = c.PostForm("token")
+	}
+
+	err := h.digestService.Unsubscribe(c.Request.Context(), token)
+	switch {
+	case errors.Is(err, mail.ErrInvalidUnsubscribeToken):
+		renderUnsubscribePage(c, http.StatusBadRequest, unsubscribePageData{Invalid: true})
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unsubscribe"})
+	default:
+		renderUnsubscribePage(c, http.StatusOK, unsubscribePageData{Done: true})
+	}
+}
+
+func renderUnsubscribePage(c *gin.Context, status int, data unsubscribePageData) {
+	var buf bytes.Buffer
+	if err := unsubscribePage.Execute(&buf, data); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render page"})
+		return
+	}
+	c.Data(status, "text/html; charset=utf-8", buf.Bytes())
+}
+
+func respondDigestError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrInvalidDigestFrequency),
+		errors.Is(err, models.ErrInvalidDigestHour),
+		errors.Is(err, models.ErrInvalidDigestWeekday):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
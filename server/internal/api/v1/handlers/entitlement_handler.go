@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// EntitlementHandler handles feature registry, entitlement and override requests
+type EntitlementHandler struct {
+	entitlementService *services.EntitlementService
+}
+
+// NewEntitlementHandler creates a new instance of EntitlementHandler
+func NewEntitlementHandler(entitlementService *services.EntitlementService) *EntitlementHandler {
+	return &EntitlementHandler{
+		entitlementService: entitlementService,
+	}
+}
+
+type FeatureOverrideRequest struct {
+	Enabled   *bool      `json:"enabled" binding:"required"`
+	Reason    string     `json:"reason" binding:"required"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+// ListFeatures lists the feature keys the code recognizes
+func (h *EntitlementHandler) ListFeatures(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"features": models.Features})
+}
+
+// GetEntitlements lists which features the organization can use
+func (h *EntitlementHandler) GetEntitlements(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	entitlements, err := h.entitlementService.Entitlements(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondEntitlementError(c, err, "Failed to get entitlements")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"entitlements": entitlements})
+}
+
+// ListOverrides lists the organization's feature overrides
+func (h *EntitlementHandler) ListOverrides(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	overrides, err := h.entitlementService.ListOverrides(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondEntitlementError(c, err, "Failed to list feature overrides")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"overrides": overrides})
+}
+
+// SetOverride grants or withholds a feature for the organization
+func (h *EntitlementHandler) SetOverride(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req FeatureOverrideRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	override, err := h.entitlementService.SetOverride(c.Request.Context(), middleware.GetUserID(c), orgID, c.Param("feature"), services.FeatureOverrideInput{
+		Enabled:   *req.Enabled,
+		Reason:    req.Reason,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		respondEntitlementError(c, err, "Failed to set feature override")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"override": override})
+}
+
+// DeleteOverride removes the organization's override for a feature
+func (h *EntitlementHandler) DeleteOverride(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.entitlementService.DeleteOverride(c.Request.Context(), middleware.GetUserID(c), orgID, c.Param("feature")); err != nil {
+		respondEntitlementError(c, err, "Failed to delete feature override")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Feature override deleted successfully"})
+}
+
+func respondEntitlementError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrOrganizationNotFound), errors.Is(err, services.ErrFeatureOverrideNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrFeatureNotEntitled):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrUnknownFeature), errors.Is(err, services.ErrOverrideReasonRequired),
+		errors.Is(err, services.ErrInvalidOverrideExpiry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
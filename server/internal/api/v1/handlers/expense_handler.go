@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// ExpenseHandler handles project expense requests
+type ExpenseHandler struct {
+	expenseService *services.ExpenseService
+}
+
+// NewExpenseHandler creates a new instance of ExpenseHandler
+func NewExpenseHandler(expenseService *services.ExpenseService) *ExpenseHandler {
+	return &ExpenseHandler{
+		expenseService: expenseService,
+	}
+}
+
+type ExpenseRequest struct {
+	TaskID      *uint       `json:"task_id"`
+	Category    string      `json:"category" binding:"required"`
+	Description string      `json:"description" binding:"required"`
+	Amount      json.Number `json:"amount" binding:"required"`
+	Currency    string      `json:"currency"`                       // defaults to the project's budget currency
+	IncurredOn  string      `json:"incurred_on" binding:"required"` // YYYY-MM-DD
+}
+
+type ReviewExpenseRequest struct {
+	Note string `json:"note"`
+}
+
+// bindExpenseRequest binds and converts an expense request, responding with 400 when it is invalid
+func bindExpenseRequest(c *gin.Context) (services.ExpenseInput, bool) {
+	var req ExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return services.ExpenseInput{}, false
+	}
+	incurredOn, err := time.Parse(dateLayout, req.IncurredOn)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "incurred_on must be a date in YYYY-MM-DD format"})
+		return services.ExpenseInput{}, false
+	}
+
+	return services.ExpenseInput{
+		TaskID:      req.TaskID,
+		Category:    req.Category,
+		Description: req.Description,
+		Amount:      req.Amount.String(),
+		Currency:    req.Currency,
+		IncurredOn:  incurredOn,
+	}, true
+}
+
+// CreateExpense submits an expense on a project
+func (h *ExpenseHandler) CreateExpense(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	input, ok := bindExpenseRequest(c)
+	if !ok {
+		return
+	}
+
+	expense, err := h.expenseService.CreateExpense(c.Request.Context(), middleware.GetUserID(c), projectID, input)
+	if err != nil {
+		respondExpenseError(c, err, "Failed to create expense")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"expense": expense})
+}
+
+// ListExpenses lists a project's expenses, filtered by the optional status query parameter
+func (h *ExpenseHandler) ListExpenses(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	status := models.ExpenseStatus(c.Query("status"))
+	switch status {
+	case "", models.ExpenseStatusPending, models.ExpenseStatusApproved, models.ExpenseStatusRejected:
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "status must be pending, approved or rejected"})
+		return
+	}
+
+	expenses, err := h.expenseService.ListExpenses(c.Request.Context(), middleware.GetUserID(c), projectID, status)
+	if err != nil {
+		respondExpenseError(c, err, "Failed to list expenses")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expenses": expenses})
+}
+
+// GetExpense returns an expense with its receipts
+func (h *ExpenseHandler) GetExpense(c *gin.Context) {
+	expenseID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	expense, err := h.expenseService.GetExpense(c.Request.Context(), middleware.GetUserID(c), expenseID)
+	if err != nil {
+		respondExpenseError(c, err, "Failed to get expense")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expense": expense})
+}
+
+// UpdateExpense changes a pending expense
+func (h *ExpenseHandler) UpdateExpense(c *gin.Context) {
+	expenseID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	input, ok := bindExpenseRequest(c)
+	if !ok {
+		return
+	}
+
+	expense, err := h.expenseService.UpdateExpense(c.Request.Context(), middleware.GetUserID(c), expenseID, input)
+	if err != nil {
+		respondExpenseError(c, err, "Failed to update expense")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expense": expense})
+}
+
+// DeleteExpense withdraws a pending expense
+func (h *ExpenseHandler) DeleteExpense(c *gin.Context) {
+	expenseID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.expenseService.DeleteExpense(c.Request.Context(), middleware.GetUserID(c), expenseID); err != nil {
+		respondExpenseError(c, err, "Failed to delete expense")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Expense deleted successfully"})
+}
+
+// ApproveExpense approves a pending expense
+func (h *ExpenseHandler) ApproveExpense(c *gin.Context) {
+	h.review(c, h.expenseService.ApproveExpense, "Failed to approve expense")
+}
+
+// RejectExpense rejects a pending expense
+func (h *ExpenseHandler) RejectExpense(c *gin.Context) {
+	h.review(c, h.expenseService.RejectExpense, "Failed to reject expense")
+}
+
+func (h *ExpenseHandler) review(c *gin.Context, review func(ctx context.Context, userID, expenseID uint, note string) (*models.Expense, error), fallback string) {
+	expenseID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req ReviewExpenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	expense, err := review(c.Request.Context(), middleware.GetUserID(c), expenseID, req.Note)
+	if err != nil {
+		respondExpenseError(c, err, fallback)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"expense": expense})
+}
+
+func respondExpenseError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrExpenseNotFound), errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden), errors.Is(err, services.ErrNotExpenseOwner),
+		errors.Is(err, services.ErrOwnExpense):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrExpenseNotPending):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrEmptyBudgetCategory), errors.Is(err, models.ErrEmptyExpenseDescription),
+		errors.Is(err, models.ErrInvalidExpenseAmount), errors.Is(err, models.ErrMissingExpenseDate),
+		isMoneyError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrFeatureNotEntitled):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
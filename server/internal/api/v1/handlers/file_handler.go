@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/storage"
+	"github.com/gin-gonic/gin"
+)
+
+// FileHandler serves presigned uploads and downloads for the local storage
+// backend. Requests are authorized by the URL signature instead of a token.
+type FileHandler struct {
+	store *storage.LocalStorage
+}
+
+// NewFileHandler creates a new instance of FileHandler
+func NewFileHandler(store *storage.LocalStorage) *FileHandler {
+	return &FileHandler{
+		store: store,
+	}
+}
+
+// UploadFile stores the request body under the signed key
+func (h *FileHandler) UploadFile(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	params, err := h.store.Verify(http.MethodPut, key, c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	if c.Request.ContentLength > params.MaxSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": storage.ErrTooLarge.Error()})
+		return
+	}
+
+	err = h.store.Put(c.Request.Context(), key, c.Request.Body, params.MaxSize, params.ContentType)
+	if err != nil {
+		if errors.Is(err, storage.ErrTooLarge) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store file"})
+		return
+	}
+
+	c.Status(http.StatusOK)
+}
+
+// DownloadFile streams the file stored under the signed key
+func (h *FileHandler) DownloadFile(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+	params, err := h.store.Verify(http.MethodGet, key, c.Request.URL.Query())
+	if err != nil {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	file, err := h.store.Get(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file"})
+		return
+	}
+	defer file.Close()
+
+	if params.FileName != "" {
+		c.Header("Content-Disposition", h.store.ContentDisposition(params.FileName))
+	}
+	// Never let browsers render uploaded content as a page of this origin
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Header("Content-Security-Policy", "sandbox")
+	c.Header("Content-Type", "application/octet-stream")
+	c.Status(http.StatusOK)
+	io.Copy(c.Writer, file)
+}
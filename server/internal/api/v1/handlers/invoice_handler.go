@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/gin-gonic/gin"
+)
+
+// InvoiceHandler handles invoice requests
+type InvoiceHandler struct {
+	invoiceService *services.InvoiceService
+}
+
+// NewInvoiceHandler creates a new instance of InvoiceHandler
+func NewInvoiceHandler(invoiceService *services.InvoiceService) *InvoiceHandler {
+	return &InvoiceHandler{
+		invoiceService: invoiceService,
+	}
+}
+
+// ListInvoices lists the organization's invoices
+func (h *InvoiceHandler) ListInvoices(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	invoices, err := h.invoiceService.ListInvoices(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondInvoiceError(c, err, "Failed to list invoices")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invoices": invoices})
+}
+
+// GetInvoice returns an invoice with its line items
+func (h *InvoiceHandler) GetInvoice(c *gin.Context) {
+	invoiceID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	invoice, err := h.invoiceService.GetInvoice(c.Request.Context(), middleware.GetUserID(c), invoiceID)
+	if err != nil {
+		respondInvoiceError(c, err, "Failed to get invoice")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invoice": invoice})
+}
+
+// DownloadPDF sends the invoice as a PDF file
+func (h *InvoiceHandler) DownloadPDF(c *gin.Context) {
+	h.render(c, services.InvoiceFormatPDF, "application/pdf", "attachment")
+}
+
+// ViewHTML shows the invoice as a web page
+func (h *InvoiceHandler) ViewHTML(c *gin.Context) {
+	h.render(c, services.InvoiceFormatHTML, "text/html; charset=utf-8", "inline")
+}
+
+func (h *InvoiceHandler) render(c *gin.Context, format services.InvoiceFormat, contentType, disposition string) {
+	invoiceID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var buf bytes.Buffer
+	invoice, err := h.invoiceService.RenderInvoice(c.Request.Context(), middleware.GetUserID(c), invoiceID, format, &buf)
+	if err != nil {
+		respondInvoiceError(c, err, "Failed to render invoice")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, invoice.InvoiceNumber+"."+string(format)))
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
+
+func respondInvoiceError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrInvoiceNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnknownInvoiceFormat):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
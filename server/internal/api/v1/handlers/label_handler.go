@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// LabelHandler handles label management requests
+type LabelHandler struct {
+	labelService *services.LabelService
+}
+
+// NewLabelHandler creates a new instance of LabelHandler
+func NewLabelHandler(labelService *services.LabelService) *LabelHandler {
+	return &LabelHandler{
+		labelService: labelService,
+	}
+}
+
+type LabelRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Color       string `json:"color"`
+	Description string `json:"description"`
+}
+
+type TaskLabelsRequest struct {
+	LabelIDs []uint `json:"label_ids" binding:"required"`
+}
+
+func (r LabelRequest) toInput() services.LabelInput {
+	return services.LabelInput{
+		Name:        r.Name,
+		Color:       r.Color,
+		Description: r.Description,
+	}
+}
+
+// CreateLabel creates a label in an organization
+func (h *LabelHandler) CreateLabel(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req LabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	label, err := h.labelService.CreateLabel(c.Request.Context(), middleware.GetUserID(c), orgID, req.toInput())
+	if err != nil {
+		respondLabelError(c, err, "Failed to create label")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"label": label})
+}
+
+// ListLabels lists the labels of an organization
+func (h *LabelHandler) ListLabels(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	labels, err := h.labelService.ListLabels(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondLabelError(c, err, "Failed to list labels")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"labels": labels})
+}
+
+// UpdateLabel updates a label
+func (h *LabelHandler) UpdateLabel(c *gin.Context) {
+	labelID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req LabelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	label, err := h.labelService.UpdateLabel(c.Request.Context(), middleware.GetUserID(c), labelID, req.toInput())
+	if err != nil {
+		respondLabelError(c, err, "Failed to update label")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"label": label})
+}
+
+// DeleteLabel deletes a label and removes it from all tasks
+func (h *LabelHandler) DeleteLabel(c *gin.Context) {
+	labelID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.labelService.DeleteLabel(c.Request.Context(), middleware.GetUserID(c), labelID); err != nil {
+		respondLabelError(c, err, "Failed to delete label")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Label deleted successfully"})
+}
+
+// SetTaskLabels replaces the labels of a task
+func (h *LabelHandler) SetTaskLabels(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req TaskLabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	labels, err := h.labelService.SetTaskLabels(c.Request.Context(), middleware.GetUserID(c), taskID, req.LabelIDs)
+	if err != nil {
+		respondLabelError(c, err, "Failed to update task labels")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"labels": labels})
+}
+
+func respondLabelError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrLabelNotFound), errors.Is(err, services.ErrTaskNotFound),
+		errors.Is(err, services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrLabelExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrEmptyLabelName), errors.Is(err, models.ErrInvalidLabelColor):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
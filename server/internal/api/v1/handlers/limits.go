@@ -0,0 +1,20 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// respondLimitError answers a request that would take the organization over
+// a limit of its plan with 402, naming the limit so that clients can offer
+// an upgrade
+func respondLimitError(c *gin.Context, err *models.LimitError) {
+	c.JSON(http.StatusPaymentRequired, gin.H{
+		"error": err.Error(),
+		"limit": err.Limit,
+		"max":   err.Max,
+		"used":  err.Used,
+	})
+}
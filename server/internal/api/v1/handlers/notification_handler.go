@@ -0,0 +1,159 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// NotificationHandler serves the user's notification inbox and preferences
+type NotificationHandler struct {
+	notificationService *services.NotificationService
+}
+
+// NewNotificationHandler creates a new instance of NotificationHandler
+func NewNotificationHandler(notificationService *services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// MarkAllReadRequest marks the notifications with the given IDs read, or all
+// of them when no IDs are given
+type MarkAllReadRequest struct {
+	IDs []uint `json:"ids"`
+}
+
+// NotificationPreferencesRequest turns types of notification on or off on channels
+type NotificationPreferencesRequest struct {
+	Preferences []services.NotificationSetting `json:"preferences" binding:"required"`
+}
+
+// ListNotifications returns a page of the user's inbox
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	limit, ok := parseLimitQuery(c)
+	if !ok {
+		return
+	}
+
+	page, err := h.notificationService.ListNotifications(c.Request.Context(), middleware.GetUserID(c), c.Query("unread") == "true", c.Query("cursor"), limit)
+	if err != nil {
+		respondNotificationError(c, err, "Failed to load notifications")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetUnreadCount returns the number of unread notifications in the user's inbox
+func (h *NotificationHandler) GetUnreadCount(c *gin.Context) {
+	count, err := h.notificationService.CountUnread(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		respondNotificationError(c, err, "Failed to count notifications")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread_count": count})
+}
+
+// MarkRead marks a notification read
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	notificationID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	notification, err := h.notificationService.MarkRead(c.Request.Context(), middleware.GetUserID(c), notificationID)
+	if err != nil {
+		respondNotificationError(c, err, "Failed to mark notification read")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notification": notification})
+}
+
+// MarkUnread marks a notification unread
+func (h *NotificationHandler) MarkUnread(c *gin.Context) {
+	notificationID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	notification, err := h.notificationService.MarkUnread(c.Request.Context(), middleware.GetUserID(c), notificationID)
+	if err != nil {
+		respondNotificationError(c, err, "Failed to mark notification unread")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"notification": notification})
+}
+
+// MarkAllRead marks many or all of the user's notifications read
+func (h *NotificationHandler) MarkAllRead(c *gin.Context) {
+	var req MarkAllReadRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	marked, err := h.notificationService.MarkAllRead(c.Request.Context(), middleware.GetUserID(c), req.IDs)
+	if err != nil {
+		respondNotificationError(c, err, "Failed to mark notifications read")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// ListTypes lists the types of notification and the channels they are sent on
+func (h *NotificationHandler) ListTypes(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"types":    models.NotificationTypes,
+		"channels": models.NotificationChannels,
+	})
+}
+
+// GetPreferences returns the user's notification preferences
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	preferences, err := h.notificationService.GetPreferences(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		respondNotificationError(c, err, "Failed to load notification preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preferences})
+}
+
+// UpdatePreferences changes the user's notification preferences
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	var req NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(c.Request.Context(), middleware.GetUserID(c), req.Preferences)
+	if err != nil {
+		respondNotificationError(c, err, "Failed to update notification preferences")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"preferences": preferences})
+}
+
+func respondNotificationError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrNotificationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidCursor),
+		errors.Is(err, models.ErrUnknownNotificationType),
+		errors.Is(err, models.ErrUnknownNotificationChannel):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// dateLayout is the format of date query parameters
+const dateLayout = "2006-01-02"
+
+// parseIDParam reads a numeric path parameter, responding with 400 when it is invalid
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// parseIDsQuery reads a query parameter of IDs, given repeated or separated by
+// commas, responding with 400 when one is invalid
+func parseIDsQuery(c *gin.Context, name string) ([]uint, bool) {
+	var ids []uint
+	for _, raw := range c.QueryArray(name) {
+		for _, field := range strings.Split(raw, ",") {
+			id, err := strconv.ParseUint(strings.TrimSpace(field), 10, 64)
+			if err != nil || id == 0 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid " + name})
+				return nil, false
+			}
+			ids = append(ids, uint(id))
+		}
+	}
+	return ids, true
+}
+
+// parseLimitQuery reads the optional limit query parameter, responding with 400 when it is invalid
+func parseLimitQuery(c *gin.Context) (int, bool) {
+	raw := c.Query("limit")
+	if raw == "" {
+		return 0, true
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+		return 0, false
+	}
+	return limit, true
+}
+
+// parseDateQuery reads an optional YYYY-MM-DD query parameter as midnight UTC,
+// responding with 400 when it is invalid
+func parseDateQuery(c *gin.Context, name string) (time.Time, bool) {
+	raw := c.Query(name)
+	if raw == "" {
+		return time.Time{}, true
+	}
+	date, err := time.Parse(dateLayout, raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": name + " must be a date in YYYY-MM-DD format"})
+		return time.Time{}, false
+	}
+	return date, true
+}
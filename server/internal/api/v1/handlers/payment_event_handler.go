@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/payments"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookSize caps the webhook payloads read, well above what providers send
+const maxWebhookSize = 1 << 20
+
+// PaymentEventHandler handles the payment provider's webhooks and the
+// inspection of the events they delivered
+type PaymentEventHandler struct {
+	paymentEventService *services.PaymentEventService
+}
+
+// NewPaymentEventHandler creates a new instance of PaymentEventHandler
+func NewPaymentEventHandler(paymentEventService *services.PaymentEventService) *PaymentEventHandler {
+	return &PaymentEventHandler{
+		paymentEventService: paymentEventService,
+	}
+}
+
+// ReceiveWebhook accepts a signed webhook from the payment provider. Events
+// received before are acknowledged without being applied again.
+func (h *PaymentEventHandler) ReceiveWebhook(c *gin.Context) {
+	payload, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read webhook"})
+		return
+	}
+	if len(payload) > maxWebhookSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Webhook is too large"})
+		return
+	}
+
+	created, err := h.paymentEventService.Receive(c.Request.Context(), payload, c.Request.Header)
+	if err != nil {
+		respondPaymentEventError(c, err, "Failed to process webhook")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"received": true, "duplicate": !created})
+}
+
+// ListEvents lists the latest provider events, optionally filtered by status
+func (h *PaymentEventHandler) ListEvents(c *gin.Context) {
+	status := models.PaymentEventStatus(c.Query("status"))
+	events, err := h.paymentEventService.ListEvents(c.Request.Context(), middleware.GetUserID(c), status)
+	if err != nil {
+		respondPaymentEventError(c, err, "Failed to list payment events")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"events": events})
+}
+
+// GetEvent returns a provider event with its raw payload
+func (h *PaymentEventHandler) GetEvent(c *gin.Context) {
+	eventID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	event, err := h.paymentEventService.GetEvent(c.Request.Context(), middleware.GetUserID(c), eventID)
+	if err != nil {
+		respondPaymentEventError(c, err, "Failed to get payment event")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"event": event})
+}
+
+// ReprocessEvent processes a stored provider event again
+func (h *PaymentEventHandler) ReprocessEvent(c *gin.Context) {
+	eventID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	event, err := h.paymentEventService.ReprocessEvent(c.Request.Context(), middleware.GetUserID(c), eventID)
+	if err != nil {
+		respondPaymentEventError(c, err, "Failed to reprocess payment event")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"event": event})
+}
+
+func respondPaymentEventError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, payments.ErrInvalidSignature), errors.Is(err, payments.ErrStaleWebhook),
+		errors.Is(err, payments.ErrInvalidEvent), errors.Is(err, services.ErrInvalidPaymentEventStatus):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPaymentEventNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
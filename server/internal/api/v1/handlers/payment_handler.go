@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/payments"
+	"github.com/gin-gonic/gin"
+)
+
+// PaymentHandler handles payment method and invoice payment requests
+type PaymentHandler struct {
+	paymentService *services.PaymentService
+}
+
+// NewPaymentHandler creates a new instance of PaymentHandler
+func NewPaymentHandler(paymentService *services.PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+	}
+}
+
+// PaymentMethodRequest carries a token from the payment provider's client library
+type PaymentMethodRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+// RefundRequest refunds part of an invoice, or what is left of it when no
+// amount is given
+type RefundRequest struct {
+	Amount string `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+// GetPaymentMethod returns the organization's payment method
+func (h *PaymentHandler) GetPaymentMethod(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	profile, err := h.paymentService.GetPaymentMethod(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondPaymentError(c, err, "Failed to get payment method")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payment_method": profile})
+}
+
+// SetPaymentMethod saves the organization's payment method
+func (h *PaymentHandler) SetPaymentMethod(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req PaymentMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := h.paymentService.SetPaymentMethod(c.Request.Context(), middleware.GetUserID(c), orgID, req.Token)
+	if err != nil {
+		respondPaymentError(c, err, "Failed to save payment method")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"payment_method": profile})
+}
+
+// PayInvoice charges an unpaid invoice to the organization's payment method
+func (h *PaymentHandler) PayInvoice(c *gin.Context) {
+	invoiceID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	invoice, err := h.paymentService.PayInvoice(c.Request.Context(), middleware.GetUserID(c), invoiceID)
+	if err != nil {
+		respondPaymentError(c, err, "Failed to pay invoice")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invoice": invoice})
+}
+
+// RefundInvoice refunds all or part of a paid invoice and issues a credit note
+func (h *PaymentHandler) RefundInvoice(c *gin.Context) {
+	invoiceID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req RefundRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	refund, err := h.paymentService.RefundInvoice(c.Request.Context(), middleware.GetUserID(c), invoiceID, req.Amount, req.Reason)
+	if err != nil {
+		respondPaymentError(c, err, "Failed to refund invoice")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"refund": refund})
+}
+
+// ListTransactions lists the payment attempts for an invoice
+func (h *PaymentHandler) ListTransactions(c *gin.Context) {
+	invoiceID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	transactions, err := h.paymentService.ListTransactions(c.Request.Context(), middleware.GetUserID(c), invoiceID)
+	if err != nil {
+		respondPaymentError(c, err, "Failed to list transactions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"transactions": transactions})
+}
+
+func respondPaymentError(c *gin.Context, err error, fallback string) {
+	var providerErr *payments.Error
+	switch {
+	case errors.Is(err, services.ErrInvoiceNotFound), errors.Is(err, services.ErrOrganizationNotFound),
+		errors.Is(err, services.ErrNoPaymentMethod):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvoiceNotPayable), errors.Is(err, services.ErrInvoiceNotRefundable),
+		errors.Is(err, services.ErrRefundTooLarge), errors.Is(err, models.ErrInvalidPaymentTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, payments.ErrPaymentMethodNotFound), errors.Is(err, services.ErrInvalidRefundAmount), isMoneyError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.As(err, &providerErr):
+		c.JSON(http.StatusBadGateway, gin.H{"error": providerErr.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
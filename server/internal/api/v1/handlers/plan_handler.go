@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// PlanHandler handles plan catalog requests
+type PlanHandler struct {
+	planService *services.PlanService
+}
+
+// NewPlanHandler creates a new instance of PlanHandler
+func NewPlanHandler(planService *services.PlanService) *PlanHandler {
+	return &PlanHandler{
+		planService: planService,
+	}
+}
+
+type PlanFeatureRequest struct {
+	Key         string `json:"key"` // grants the feature when set
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+	Included    *bool  `json:"included"` // defaults to true
+}
+
+type PlanRequest struct {
+	Name            string                 `json:"name" binding:"required"`
+	Description     string                 `json:"description"`
+	Price           json.Number            `json:"price" binding:"required"`
+	SeatPrice       json.Number            `json:"seat_price"` // per seat beyond included_seats
+	Currency        string                 `json:"currency" binding:"required"`
+	BillingInterval models.BillingInterval `json:"billing_interval" binding:"required"`
+	IncludedSeats   int                    `json:"included_seats" binding:"gte=0"`
+	TrialDays       int                    `json:"trial_days" binding:"gte=0"`
+	MaxUsers        int                    `json:"max_users" binding:"gte=0"`
+	MaxProjects     int                    `json:"max_projects" binding:"gte=0"`
+	MaxStorage      int                    `json:"max_storage" binding:"gte=0"` // in GB
+	CustomDomain    bool                   `json:"custom_domain"`
+	APIAccess       bool                   `json:"api_access"`
+	PrioritySupport bool                   `json:"priority_support"`
+	Features        []PlanFeatureRequest   `json:"features" binding:"dive"`
+}
+
+func (r PlanRequest) toInput() services.PlanInput {
+	input := services.PlanInput{
+		Name:            r.Name,
+		Description:     r.Description,
+		Price:           r.Price.String(),
+		SeatPrice:       r.SeatPrice.String(),
+		Currency:        r.Currency,
+		BillingInterval: r.BillingInterval,
+		IncludedSeats:   r.IncludedSeats,
+		TrialDays:       r.TrialDays,
+		MaxUsers:        r.MaxUsers,
+		MaxProjects:     r.MaxProjects,
+		MaxStorage:      r.MaxStorage,
+		CustomDomain:    r.CustomDomain,
+		APIAccess:       r.APIAccess,
+		PrioritySupport: r.PrioritySupport,
+	}
+	for _, feature := range r.Features {
+		input.Features = append(input.Features, services.PlanFeatureInput{
+			Key:         feature.Key,
+			Name:        feature.Name,
+			Description: feature.Description,
+			Included:    feature.Included == nil || *feature.Included,
+		})
+	}
+	return input
+}
+
+// ListPlans lists the plans on offer; platform admins can add ?archived=true
+func (h *PlanHandler) ListPlans(c *gin.Context) {
+	plans, err := h.planService.ListPlans(c.Request.Context(), middleware.GetUserID(c), c.Query("archived") == "true")
+	if err != nil {
+		respondPlanError(c, err, "Failed to list plans")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"plans": plans})
+}
+
+// GetPlan returns a plan with its features
+func (h *PlanHandler) GetPlan(c *gin.Context) {
+	planID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	plan, err := h.planService.GetPlan(c.Request.Context(), planID)
+	if err != nil {
+		respondPlanError(c, err, "Failed to get plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"plan": plan})
+}
+
+// CreatePlan adds a plan to the catalog
+func (h *PlanHandler) CreatePlan(c *gin.Context) {
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.planService.CreatePlan(c.Request.Context(), middleware.GetUserID(c), req.toInput())
+	if err != nil {
+		respondPlanError(c, err, "Failed to create plan")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"plan": plan})
+}
+
+// UpdatePlan changes a plan and its features
+func (h *PlanHandler) UpdatePlan(c *gin.Context) {
+	planID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req PlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	plan, err := h.planService.UpdatePlan(c.Request.Context(), middleware.GetUserID(c), planID, req.toInput())
+	if err != nil {
+		respondPlanError(c, err, "Failed to update plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"plan": plan})
+}
+
+// ArchivePlan withdraws a plan from the catalog
+func (h *PlanHandler) ArchivePlan(c *gin.Context) {
+	planID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	plan, err := h.planService.ArchivePlan(c.Request.Context(), middleware.GetUserID(c), planID)
+	if err != nil {
+		respondPlanError(c, err, "Failed to archive plan")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"plan": plan})
+}
+
+func respondPlanError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrPlanNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrEmptyPlanName), errors.Is(err, models.ErrInvalidPrice),
+		errors.Is(err, models.ErrInvalidBillingInterval), errors.Is(err, models.ErrInvalidPlanLimits),
+		errors.Is(err, models.ErrUnknownFeature), errors.Is(err, models.ErrDuplicateFeature),
+		isMoneyError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
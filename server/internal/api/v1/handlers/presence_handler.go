@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/realtime"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// maxPresencePages is the most pages one connection may be present on
+const maxPresencePages = 20
+
+// PresenceHandler serves who is viewing, typing on and editing tasks and
+// boards
+type PresenceHandler struct {
+	presenceService *services.PresenceService
+	realtimeService *services.RealtimeService
+}
+
+// NewPresenceHandler creates a new instance of PresenceHandler
+func NewPresenceHandler(presenceService *services.PresenceService, realtimeService *services.RealtimeService) *PresenceHandler {
+	return &PresenceHandler{
+		presenceService: presenceService,
+		realtimeService: realtimeService,
+	}
+}
+
+// PresenceMessage is a message sent by a client over the presence connection.
+// Its type is one of:
+//
+//   - "join": the user opened the page; the server answers with its viewers
+//   - "activity": the user is typing a comment or editing the task, or
+//     "viewing" again; typing and editing are to be reported every few
+//     seconds while they last
+//   - "leave": the user closed the page
+//   - "ping": keeps the connection open; clients send one at least every
+//     30 seconds
+type PresenceMessage struct {
+	Type     string                  `json:"type"`
+	Resource models.PresenceResource `json:"resource"`
+	ID       uint                    `json:"id"`
+	Activity models.PresenceActivity `json:"activity"`
+}
+
+// page is a task or board a connection is present on
+type page struct {
+	resource models.PresenceResource
+	id       uint
+}
+
+// GetViewers lists the users present on a task or board
+func (h *PresenceHandler) GetViewers(c *gin.Context) {
+	resourceID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	viewers, err := h.presenceService.ListViewers(c.Request.Context(), middleware.GetUserID(c), models.PresenceResource(c.Param("resource")), resourceID)
+	if err != nil {
+		respondPresenceError(c, err, "Failed to load viewers")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"viewers": viewers})
+}
+
+// Connect upgrades the request to a WebSocket over which the client reports
+// the pages the user has open and what they do there, and is told of the
+// other users arriving, leaving, typing and editing on those pages and of
+// changes to the tasks among them, so that an edit made elsewhere is seen
+// before it is overwritten. Messages are JSON, see PresenceMessage.
+func (h *PresenceHandler) Connect(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	connectionID, err := h.presenceService.NewConnectionID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to connect"})
+		return
+	}
+
+	// Clients authenticate with a token rather than cookies, so the
+	// connection's origin does not need checking
+	server := websocket.Server{Handler: func(ws *websocket.Conn) {
+		defer ws.Close()
+		h.serve(context.WithoutCancel(c.Request.Context()), ws, userID, connectionID)
+	}}
+	server.ServeHTTP(c.Writer, c.Request)
+}
+
+func (h *PresenceHandler) serve(ctx context.Context, ws *websocket.Conn, userID uint, connectionID string) {
+	sub := h.presenceService.Subscribe(userID)
+	defer sub.Close()
+	defer func() {
+		if err := h.presenceService.Disconnect(ctx, connectionID); err != nil {
+			log.Printf("Failed to clear presence of connection %s: %v", connectionID, err)
+		}
+	}()
+
+	messages := make(chan PresenceMessage)
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		defer close(messages)
+		for {
+			var msg PresenceMessage
+			ws.SetReadDeadline(time.Now().Add(services.PresenceTTL))
+			if err := websocket.JSON.Receive(ws, &msg); err != nil {
+				return
+			}
+			select {
+			case messages <- msg:
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	renew := time.NewTicker(services.PresenceRenewInterval)
+	defer renew.Stop()
+
+	pages := make(map[page]uint) // the project of each page
+	send := func(v interface{}) bool {
+		return websocket.JSON.Send(ws, v) == nil
+	}
+	if !send(gin.H{"type": "ready", "connection_id": connectionID}) {
+		return
+	}
+
+	for {
+		select {
+		case msg, ok := <-messages:
+			if !ok {
+				return
+			}
+			if !h.handle(ctx, sub, userID, connectionID, pages, msg, send) {
+				return
+			}
+		case <-renew.C:
+			if err := h.presenceService.Renew(ctx, connectionID); err != nil {
+				log.Printf("Failed to renew presence of connection %s: %v", connectionID, err)
+			}
+		case <-sub.Dropped():
+			// The client fell behind; it reconnects and starts afresh
+			send(gin.H{"type": realtime.EventResync})
+			return
+		case event := <-sub.Events():
+			if h.realtimeService.AffectsAccess(&event) {
+				h.revoke(ctx, sub, userID, connectionID, pages, send)
+			}
+			if wantsPresenceEvent(pages, &event) && !send(event) {
+				return
+			}
+		}
+	}
+}
+
+// handle acts on a client's message, and reports whether the connection is
+// still usable
+func (h *PresenceHandler) handle(ctx context.Context, sub *realtime.Subscription, userID uint, connectionID string, pages map[page]uint, msg PresenceMessage, send func(interface{}) bool) bool {
+	p := page{msg.Resource, msg.ID}
+	fail := func(err error) bool {
+		return send(gin.H{"type": "error", "resource": msg.Resource, "id": msg.ID, "error": presenceErrorMessage(err)})
+	}
+
+	switch msg.Type {
+	case "ping":
+		return send(gin.H{"type": "pong"})
+	case "join", "activity":
+		activity := msg.Activity
+		if msg.Type == "join" || activity == "" {
+			activity = models.PresenceActivityViewing
+		}
+		if _, ok := pages[p]; !ok && len(pages) >= maxPresencePages {
+			return fail(errTooManyPages)
+		}
+		presence, err := h.presenceService.Enter(ctx, userID, connectionID, msg.Resource, msg.ID, activity)
+		if err != nil {
+			return fail(err)
+		}
+		if _, ok := pages[p]; !ok {
+			pages[p] = presence.ProjectID
+			h.follow(sub, pages)
+		}
+		if msg.Type != "join" {
+			return true
+		}
+		viewers, err := h.presenceService.ListViewers(ctx, userID, msg.Resource, msg.ID)
+		if err != nil {
+			return fail(err)
+		}
+		return send(gin.H{"type": "viewers", "resource": msg.Resource, "id": msg.ID, "viewers": viewers})
+	case "leave":
+		if _, ok := pages[p]; !ok {
+			return true
+		}
+		delete(pages, p)
+		h.follow(sub, pages)
+		if err := h.presenceService.Leave(ctx, connectionID, msg.Resource, msg.ID); err != nil {
+			return fail(err)
+		}
+		return true
+	default:
+		return fail(errUnknownPresenceMessage)
+	}
+}
+
+// revoke leaves the pages the user may no longer see, such as after being
+// removed from the organization
+func (h *PresenceHandler) revoke(ctx context.Context, sub *realtime.Subscription, userID uint, connectionID string, pages map[page]uint, send func(interface{}) bool) {
+	for p := range pages {
+		err := h.presenceService.Authorize(ctx, userID, p.resource, p.id)
+		if err == nil {
+			continue
+		}
+		delete(pages, p)
+		if err := h.presenceService.Leave(ctx, connectionID, p.resource, p.id); err != nil {
+			log.Printf("Failed to clear presence of connection %s: %v", connectionID, err)
+		}
+		send(gin.H{"type": "revoked", "resource": p.resource, "id": p.id, "error": presenceErrorMessage(err)})
+	}
+	h.follow(sub, pages)
+}
+
+func (h *PresenceHandler) follow(sub *realtime.Subscription, pages map[page]uint) {
+	projectIDs := make([]uint, 0, len(pages))
+	for _, projectID := range pages {
+		projectIDs = append(projectIDs, projectID)
+	}
+	h.presenceService.Follow(sub, projectIDs)
+}
+
+// wantsPresenceEvent checks if an event concerns the pages a connection is
+// present on: someone arriving, leaving or changing what they do there, or a
+// change to one of the tasks
+func wantsPresenceEvent(pages map[page]uint, event *models.RealtimeEvent) bool {
+	switch event.Type {
+	case models.RealtimeEventPresence:
+		if event.Presence == nil {
+			return false
+		}
+		_, ok := pages[page{event.Presence.ResourceType, event.Presence.ResourceID}]
+		return ok
+	case string(models.ActivityEntityTask):
+		_, ok := pages[page{models.PresenceResourceTask, event.EntityID}]
+		return ok
+	}
+	return false
+}
+
+var (
+	errTooManyPages           = fmt.Errorf("a connection may be present on at most %d pages", maxPresencePages)
+	errUnknownPresenceMessage = errors.New("unknown message type")
+)
+
+// presenceErrorMessage tells the client what went wrong, without the details
+// of unexpected errors
+func presenceErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, services.ErrForbidden),
+		errors.Is(err, services.ErrTaskNotFound),
+		errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, models.ErrInvalidPresenceResource),
+		errors.Is(err, models.ErrInvalidPresenceActivity),
+		errors.Is(err, errTooManyPages),
+		errors.Is(err, errUnknownPresenceMessage):
+		return err.Error()
+	}
+	log.Printf("Presence error: %v", err)
+	return "Something went wrong"
+}
+
+func respondPresenceError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTaskNotFound), errors.Is(err, services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrInvalidPresenceResource):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
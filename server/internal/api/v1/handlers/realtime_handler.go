@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/realtime"
+	"github.com/gin-gonic/gin"
+)
+
+// realtimeHeartbeat is how often an idle stream is written to, so that
+// proxies do not close it
+const realtimeHeartbeat = 25 * time.Second
+
+// RealtimeHandler streams live changes to clients
+type RealtimeHandler struct {
+	realtimeService *services.RealtimeService
+}
+
+// NewRealtimeHandler creates a new instance of RealtimeHandler
+func NewRealtimeHandler(realtimeService *services.RealtimeService) *RealtimeHandler {
+	return &RealtimeHandler{
+		realtimeService: realtimeService,
+	}
+}
+
+// Stream sends the changes to the organizations and projects given by the
+// organization_id and project_id query parameters, and the user's new
+// notifications, as server-sent events named after the type of what changed:
+// task, comment, project, organization_member, project_member, team_member,
+// presence or notification. Boards follow the task events of their project. A resync
+// event means changes may have been missed and what the client shows is to
+// be fetched again. To follow other organizations or projects the client
+// opens a new stream.
+func (h *RealtimeHandler) Stream(c *gin.Context) {
+	orgIDs, ok := parseIDsQuery(c, "organization_id")
+	if !ok {
+		return
+	}
+	projectIDs, ok := parseIDsQuery(c, "project_id")
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	sub, err := h.realtimeService.Subscribe(ctx, middleware.GetUserID(c), orgIDs, projectIDs)
+	if err != nil {
+		respondRealtimeError(c, err, "Failed to subscribe")
+		return
+	}
+	defer sub.Close()
+
+	heartbeat := time.NewTicker(realtimeHeartbeat)
+	defer heartbeat.Stop()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("X-Accel-Buffering", "no")
+	c.SSEvent("ready", gin.H{"organization_ids": sub.Organizations, "project_ids": sub.Projects})
+	c.Writer.Flush()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case <-sub.Dropped():
+			// The client fell behind; it reconnects and starts afresh
+			c.SSEvent(realtime.EventResync, gin.H{})
+			return false
+		case <-heartbeat.C:
+			_, err := io.WriteString(w, ": ping\n\n")
+			return err == nil
+		case event := <-sub.Events():
+			if h.realtimeService.AffectsAccess(&event) {
+				if err := h.realtimeService.Authorize(ctx, sub); err != nil {
+					c.SSEvent("revoked", gin.H{"error": err.Error()})
+					return false
+				}
+			}
+			c.SSEvent(event.Type, event)
+			return true
+		}
+	})
+}
+
+func respondRealtimeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTooManyTopics):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
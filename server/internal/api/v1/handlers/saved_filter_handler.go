@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// SavedFilterHandler handles saved filter and filter subscription requests
+type SavedFilterHandler struct {
+	filterService *services.SavedFilterService
+}
+
+// NewSavedFilterHandler creates a new instance of SavedFilterHandler
+func NewSavedFilterHandler(filterService *services.SavedFilterService) *SavedFilterHandler {
+	return &SavedFilterHandler{
+		filterService: filterService,
+	}
+}
+
+type SavedFilterRequest struct {
+	ProjectID   *uint                   `json:"project_id"`
+	Name        string                  `json:"name" binding:"required"`
+	Description string                  `json:"description"`
+	Query       string                  `json:"query"`
+	Sort        string                  `json:"sort"`
+	Visibility  models.FilterVisibility `json:"visibility"`
+}
+
+type FilterSubscriptionRequest struct {
+	Frequency models.SubscriptionFrequency `json:"frequency"`
+}
+
+func (r SavedFilterRequest) toInput() services.SavedFilterInput {
+	return services.SavedFilterInput{
+		ProjectID:   r.ProjectID,
+		Name:        r.Name,
+		Description: r.Description,
+		Query:       r.Query,
+		Sort:        r.Sort,
+		Visibility:  r.Visibility,
+	}
+}
+
+// CreateFilter saves a task query in an organization
+func (h *SavedFilterHandler) CreateFilter(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SavedFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := h.filterService.CreateFilter(c.Request.Context(), middleware.GetUserID(c), orgID, req.toInput())
+	if err != nil {
+		respondSavedFilterError(c, err, "Failed to create filter")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"filter": filter})
+}
+
+// ListFilters lists the filters of an organization visible to the user
+func (h *SavedFilterHandler) ListFilters(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	filters, err := h.filterService.ListFilters(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondSavedFilterError(c, err, "Failed to list filters")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"filters": filters})
+}
+
+// GetFilter returns a saved filter
+func (h *SavedFilterHandler) GetFilter(c *gin.Context) {
+	filterID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	filter, err := h.filterService.GetFilter(c.Request.Context(), middleware.GetUserID(c), filterID)
+	if err != nil {
+		respondSavedFilterError(c, err, "Failed to get filter")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"filter": filter})
+}
+
+// UpdateFilter updates a saved filter owned by the user
+func (h *SavedFilterHandler) UpdateFilter(c *gin.Context) {
+	filterID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SavedFilterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	filter, err := h.filterService.UpdateFilter(c.Request.Context(), middleware.GetUserID(c), filterID, req.toInput())
+	if err != nil {
+		respondSavedFilterError(c, err, "Failed to update filter")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"filter": filter})
+}
+
+// DeleteFilter deletes a saved filter owned by the user, along with its subscriptions
+func (h *SavedFilterHandler) DeleteFilter(c *gin.Context) {
+	filterID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.filterService.DeleteFilter(c.Request.Context(), middleware.GetUserID(c), filterID); err != nil {
+		respondSavedFilterError(c, err, "Failed to delete filter")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Filter deleted successfully"})
+}
+
+// FilterTasks runs a saved filter. It accepts the page and page_size query parameters.
+func (h *SavedFilterHandler) FilterTasks(c *gin.Context) {
+	filterID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, err := taskListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.filterService.FilterTasks(c.Request.Context(), middleware.GetUserID(c), filterID, params.Page, params.PageSize)
+	if err != nil {
+		respondSavedFilterError(c, err, "Failed to run filter")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// Subscribe subscribes the user to a filter's results
+func (h *SavedFilterHandler) Subscribe(c *gin.Context) {
+	filterID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req FilterSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	subscription, err := h.filterService.Subscribe(c.Request.Context(), middleware.GetUserID(c), filterID, req.Frequency)
+	if err != nil {
+		respondSavedFilterError(c, err, "Failed to subscribe to filter")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscription": subscription})
+}
+
+// Unsubscribe removes the user's subscription to a filter
+func (h *SavedFilterHandler) Unsubscribe(c *gin.Context) {
+	filterID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.filterService.Unsubscribe(c.Request.Context(), middleware.GetUserID(c), filterID); err != nil {
+		respondSavedFilterError(c, err, "Failed to unsubscribe from filter")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Unsubscribed successfully"})
+}
+
+func respondSavedFilterError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrSavedFilterNotFound), errors.Is(err, services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden), errors.Is(err, services.ErrNotFilterOwner):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTaskFilter), errors.Is(err, models.ErrEmptyFilterName),
+		errors.Is(err, models.ErrInvalidFilterVisibility), errors.Is(err, models.ErrInvalidFilterSubscription):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrFeatureNotEntitled):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
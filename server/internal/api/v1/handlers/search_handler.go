@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchHandler handles full-text search requests
+type SearchHandler struct {
+	searchService *services.SearchService
+}
+
+// NewSearchHandler creates a new instance of SearchHandler
+func NewSearchHandler(searchService *services.SearchService) *SearchHandler {
+	return &SearchHandler{
+		searchService: searchService,
+	}
+}
+
+// Search searches an organization's tasks, comments and projects.
+//
+// Query parameters: q is the search text, which accepts quoted phrases, OR
+// and -word; type takes a comma-separated list of task, comment and project;
+// project_id, page and page_size take numbers.
+func (h *SearchHandler) Search(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params := services.SearchParams{
+		Query: c.Query("q"),
+		Types: splitQuery(c.Query("type")),
+	}
+
+	if raw := c.Query("project_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "project_id must be a number"})
+			return
+		}
+		projectID := uint(id)
+		params.ProjectID = &projectID
+	}
+
+	for name, target := range map[string]*int{"page": &params.Page, "page_size": &params.PageSize} {
+		if raw := c.Query(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be a positive number", name)})
+				return
+			}
+			*target = n
+		}
+	}
+
+	results, err := h.searchService.Search(c.Request.Context(), middleware.GetUserID(c), orgID, params)
+	if err != nil {
+		respondSearchError(c, err, "Failed to search")
+		return
+	}
+
+	c.JSON(http.StatusOK, results)
+}
+
+func respondSearchError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidSearch):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,288 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// SprintHandler handles sprint planning and reporting requests
+type SprintHandler struct {
+	sprintService *services.SprintService
+}
+
+// NewSprintHandler creates a new instance of SprintHandler
+func NewSprintHandler(sprintService *services.SprintService) *SprintHandler {
+	return &SprintHandler{
+		sprintService: sprintService,
+	}
+}
+
+type SprintRequest struct {
+	Name           string                `json:"name" binding:"required"`
+	Goal           string                `json:"goal"`
+	StartDate      time.Time             `json:"start_date" binding:"required"`
+	EndDate        time.Time             `json:"end_date" binding:"required"`
+	TeamID         *uint                 `json:"team_id"`
+	EstimationUnit models.EstimationUnit `json:"estimation_unit" binding:"omitempty,oneof=story_points hours"`
+}
+
+type SprintTasksRequest struct {
+	TaskIDs []uint `json:"task_ids" binding:"required,min=1"`
+}
+
+type CompleteSprintRequest struct {
+	CarryOverSprintID *uint `json:"carry_over_sprint_id"`
+}
+
+func (r SprintRequest) toInput() services.SprintInput {
+	return services.SprintInput{
+		Name:           r.Name,
+		Goal:           r.Goal,
+		StartDate:      r.StartDate,
+		EndDate:        r.EndDate,
+		TeamID:         r.TeamID,
+		EstimationUnit: r.EstimationUnit,
+	}
+}
+
+// CreateSprint creates a sprint in a project
+func (h *SprintHandler) CreateSprint(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SprintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sprint, err := h.sprintService.CreateSprint(c.Request.Context(), middleware.GetUserID(c), projectID, req.toInput())
+	if err != nil {
+		respondSprintError(c, err, "Failed to create sprint")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"sprint": sprint})
+}
+
+// ListSprints lists the sprints of a project
+func (h *SprintHandler) ListSprints(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	sprints, err := h.sprintService.ListSprints(c.Request.Context(), middleware.GetUserID(c), projectID)
+	if err != nil {
+		respondSprintError(c, err, "Failed to list sprints")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sprints": sprints})
+}
+
+// GetSprint returns a sprint with its current tasks
+func (h *SprintHandler) GetSprint(c *gin.Context) {
+	sprintID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	sprint, err := h.sprintService.GetSprint(c.Request.Context(), middleware.GetUserID(c), sprintID)
+	if err != nil {
+		respondSprintError(c, err, "Failed to get sprint")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sprint": sprint})
+}
+
+// UpdateSprint updates a sprint's details
+func (h *SprintHandler) UpdateSprint(c *gin.Context) {
+	sprintID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SprintRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sprint, err := h.sprintService.UpdateSprint(c.Request.Context(), middleware.GetUserID(c), sprintID, req.toInput())
+	if err != nil {
+		respondSprintError(c, err, "Failed to update sprint")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sprint": sprint})
+}
+
+// DeleteSprint deletes a sprint that has not been started
+func (h *SprintHandler) DeleteSprint(c *gin.Context) {
+	sprintID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.sprintService.DeleteSprint(c.Request.Context(), middleware.GetUserID(c), sprintID); err != nil {
+		respondSprintError(c, err, "Failed to delete sprint")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Sprint deleted successfully"})
+}
+
+// AddTasks adds tasks to a sprint
+func (h *SprintHandler) AddTasks(c *gin.Context) {
+	sprintID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SprintTasksRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	sprint, err := h.sprintService.AddTasks(c.Request.Context(), middleware.GetUserID(c), sprintID, req.TaskIDs)
+	if err != nil {
+		respondSprintError(c, err, "Failed to add tasks to sprint")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sprint": sprint})
+}
+
+// RemoveTask removes a task from a sprint
+func (h *SprintHandler) RemoveTask(c *gin.Context) {
+	sprintID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	taskID, ok := parseIDParam(c, "taskId")
+	if !ok {
+		return
+	}
+
+	if err := h.sprintService.RemoveTask(c.Request.Context(), middleware.GetUserID(c), sprintID, taskID); err != nil {
+		respondSprintError(c, err, "Failed to remove task from sprint")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Task removed from sprint"})
+}
+
+// StartSprint starts a planned sprint
+func (h *SprintHandler) StartSprint(c *gin.Context) {
+	sprintID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	sprint, err := h.sprintService.StartSprint(c.Request.Context(), middleware.GetUserID(c), sprintID)
+	if err != nil {
+		respondSprintError(c, err, "Failed to start sprint")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sprint": sprint})
+}
+
+// CompleteSprint completes an active sprint and carries over unfinished tasks
+func (h *SprintHandler) CompleteSprint(c *gin.Context) {
+	sprintID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req CompleteSprintRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	sprint, err := h.sprintService.CompleteSprint(c.Request.Context(), middleware.GetUserID(c), sprintID, req.CarryOverSprintID)
+	if err != nil {
+		respondSprintError(c, err, "Failed to complete sprint")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sprint": sprint})
+}
+
+// GetBurndown returns the daily burndown and burnup series of a sprint
+func (h *SprintHandler) GetBurndown(c *gin.Context) {
+	sprintID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	report, err := h.sprintService.Burndown(c.Request.Context(), middleware.GetUserID(c), sprintID)
+	if err != nil {
+		respondSprintError(c, err, "Failed to build burndown")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetTeamVelocity returns the historical velocity of a team
+func (h *SprintHandler) GetTeamVelocity(c *gin.Context) {
+	teamID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	unit := models.EstimationUnit(c.Query("unit"))
+	if unit != "" && unit != models.EstimationUnitStoryPoints && unit != models.EstimationUnitHours {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "unit must be story_points or hours"})
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "10"))
+	if err != nil || limit < 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be a positive number"})
+		return
+	}
+
+	report, err := h.sprintService.TeamVelocity(c.Request.Context(), middleware.GetUserID(c), teamID, unit, limit)
+	if err != nil {
+		respondSprintError(c, err, "Failed to compute velocity")
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+func respondSprintError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound), errors.Is(err, services.ErrSprintNotFound),
+		errors.Is(err, services.ErrTeamNotFound), errors.Is(err, services.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrSprintNotPlanned), errors.Is(err, services.ErrSprintNotActive),
+		errors.Is(err, services.ErrSprintCompleted), errors.Is(err, services.ErrActiveSprintExists):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTaskNotInProject), errors.Is(err, services.ErrInvalidCarryOverSprint),
+		errors.Is(err, models.ErrEmptySprintName), errors.Is(err, models.ErrInvalidSprintDates),
+		errors.Is(err, models.ErrInvalidEstimationUnit):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
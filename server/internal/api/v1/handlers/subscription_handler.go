@@ -0,0 +1,228 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// SubscriptionHandler handles an organization's subscription requests
+type SubscriptionHandler struct {
+	subscriptionService *services.SubscriptionService
+}
+
+// NewSubscriptionHandler creates a new instance of SubscriptionHandler
+func NewSubscriptionHandler(subscriptionService *services.SubscriptionService) *SubscriptionHandler {
+	return &SubscriptionHandler{
+		subscriptionService: subscriptionService,
+	}
+}
+
+type PlanChoiceRequest struct {
+	PlanID uint `json:"plan_id" binding:"required"`
+}
+
+type SubscribeRequest struct {
+	PlanID        uint   `json:"plan_id" binding:"required"`
+	Seats         int    `json:"seats" binding:"gte=0"` // defaults to the seats the plan includes
+	PaymentMethod string `json:"payment_method"`        // card, paypal or bank_transfer; required for paid plans
+}
+
+type SeatsRequest struct {
+	Seats int `json:"seats" binding:"required,gte=1"`
+}
+
+// GetSubscription returns the organization's current and scheduled subscriptions
+func (h *SubscriptionHandler) GetSubscription(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	overview, err := h.subscriptionService.GetSubscription(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondSubscriptionError(c, err, "Failed to get subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscription": overview})
+}
+
+// ListSubscriptions lists the organization's subscription history
+func (h *SubscriptionHandler) ListSubscriptions(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	subscriptions, err := h.subscriptionService.ListSubscriptions(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondSubscriptionError(c, err, "Failed to list subscriptions")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscriptions": subscriptions})
+}
+
+// StartTrial starts a free trial of a plan
+func (h *SubscriptionHandler) StartTrial(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req PlanChoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	overview, err := h.subscriptionService.StartTrial(c.Request.Context(), middleware.GetUserID(c), orgID, req.PlanID)
+	if err != nil {
+		respondSubscriptionError(c, err, "Failed to start trial")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"subscription": overview})
+}
+
+// Subscribe puts the organization on a plan
+func (h *SubscriptionHandler) Subscribe(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SubscribeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	overview, err := h.subscriptionService.Subscribe(c.Request.Context(), middleware.GetUserID(c), orgID, services.SubscribeInput{
+		PlanID:        req.PlanID,
+		Seats:         req.Seats,
+		PaymentMethod: req.PaymentMethod,
+	})
+	if err != nil {
+		respondSubscriptionError(c, err, "Failed to subscribe")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscription": overview})
+}
+
+// Upgrade moves the organization to a more expensive plan straight away
+func (h *SubscriptionHandler) Upgrade(c *gin.Context) {
+	h.changePlan(c, h.subscriptionService.Upgrade, "Failed to upgrade subscription")
+}
+
+// Downgrade schedules a move to a cheaper plan at the end of the period
+func (h *SubscriptionHandler) Downgrade(c *gin.Context) {
+	h.changePlan(c, h.subscriptionService.Downgrade, "Failed to downgrade subscription")
+}
+
+func (h *SubscriptionHandler) changePlan(
+	c *gin.Context,
+	change func(ctx context.Context, userID, orgID, planID uint) (*services.SubscriptionOverview, error),
+	fallback string,
+) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req PlanChoiceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	overview, err := change(c.Request.Context(), middleware.GetUserID(c), orgID, req.PlanID)
+	if err != nil {
+		respondSubscriptionError(c, err, fallback)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscription": overview})
+}
+
+// SetSeats changes the number of seats paid for, prorating the difference
+func (h *SubscriptionHandler) SetSeats(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SeatsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	overview, err := h.subscriptionService.SetSeats(c.Request.Context(), middleware.GetUserID(c), orgID, req.Seats)
+	if err != nil {
+		respondSubscriptionError(c, err, "Failed to change seats")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscription": overview})
+}
+
+// Cancel ends the subscription at the end of its period
+func (h *SubscriptionHandler) Cancel(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	overview, err := h.subscriptionService.Cancel(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondSubscriptionError(c, err, "Failed to cancel subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscription": overview})
+}
+
+// Resume keeps a subscription that was set to cancel
+func (h *SubscriptionHandler) Resume(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	overview, err := h.subscriptionService.Resume(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondSubscriptionError(c, err, "Failed to resume subscription")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"subscription": overview})
+}
+
+func respondSubscriptionError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrPlanNotFound), errors.Is(err, services.ErrNoSubscription):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrAlreadySubscribed), errors.Is(err, services.ErrTrialUsed),
+		errors.Is(err, services.ErrSamePlan), errors.Is(err, services.ErrSubscriptionNotActive),
+		errors.Is(err, services.ErrNotCancelling), errors.Is(err, models.ErrInvalidStatusTransition):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPlanArchived), errors.Is(err, services.ErrTrialUnavailable),
+		errors.Is(err, services.ErrPaymentMethodRequired), errors.Is(err, services.ErrNotAnUpgrade),
+		errors.Is(err, services.ErrNotADowngrade), errors.Is(err, models.ErrInvalidPaymentMethod),
+		errors.Is(err, services.ErrSeatLimit), errors.Is(err, models.ErrInvalidSeats),
+		isMoneyError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
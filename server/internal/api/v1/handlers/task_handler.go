@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/gin-gonic/gin"
+)
+
+// TaskHandler handles task listing and export requests
+type TaskHandler struct {
+	taskService *services.TaskService
+}
+
+// NewTaskHandler creates a new instance of TaskHandler
+func NewTaskHandler(taskService *services.TaskService) *TaskHandler {
+	return &TaskHandler{
+		taskService: taskService,
+	}
+}
+
+// ListTasks lists a project's tasks.
+//
+// Query parameters: q takes a task query such as
+// "assignee = me AND status != done AND due < +7d"; status, priority, label and sort take comma-separated
+// values; assignee_id, page and page_size take numbers. Custom fields are
+// filtered with cf.<key>=value or cf.<key>.<op>=value and sorted with
+// sort=cf.<key> (prefix - for descending).
+func (h *TaskHandler) ListTasks(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, err := taskListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.taskService.ListTasks(c.Request.Context(), middleware.GetUserID(c), projectID, params)
+	if err != nil {
+		respondTaskError(c, err, "Failed to list tasks")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// ExportTasks downloads a project's tasks, including custom fields, as CSV
+func (h *TaskHandler) ExportTasks(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, err := taskListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var buf strings.Builder
+	if err := h.taskService.ExportTasks(c.Request.Context(), middleware.GetUserID(c), projectID, params, &buf); err != nil {
+		respondTaskError(c, err, "Failed to export tasks")
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="project-%d-tasks.csv"`, projectID))
+	c.Data(http.StatusOK, "text/csv; charset=utf-8", []byte(buf.String()))
+}
+
+// SearchTasks lists the tasks across all projects of an organization. It
+// accepts the same query parameters as ListTasks except custom fields.
+func (h *TaskHandler) SearchTasks(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, err := taskListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	page, err := h.taskService.SearchTasks(c.Request.Context(), middleware.GetUserID(c), orgID, params)
+	if err != nil {
+		respondTaskError(c, err, "Failed to search tasks")
+		return
+	}
+
+	c.JSON(http.StatusOK, page)
+}
+
+// GetBoard returns a project's tasks grouped by status, filtered like ListTasks
+func (h *TaskHandler) GetBoard(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, err := taskListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	columns, err := h.taskService.Board(c.Request.Context(), middleware.GetUserID(c), projectID, params)
+	if err != nil {
+		respondTaskError(c, err, "Failed to load board")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"columns": columns})
+}
+
+// GetTaskReport summarizes a project's tasks, filtered like ListTasks and
+// grouped by the group_by query parameter (status by default)
+func (h *TaskHandler) GetTaskReport(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	params, err := taskListParams(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	groupBy := c.DefaultQuery("group_by", "status")
+	groups, err := h.taskService.Report(c.Request.Context(), middleware.GetUserID(c), projectID, params, groupBy)
+	if err != nil {
+		respondTaskError(c, err, "Failed to build task report")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"group_by": groupBy, "groups": groups})
+}
+
+type ValidateQueryRequest struct {
+	Query     string `json:"query"`
+	ProjectID *uint  `json:"project_id"`
+}
+
+// ValidateQuery checks a task query and returns its syntax tree, so clients
+// can point at mistakes before saving a filter
+func (h *TaskHandler) ValidateQuery(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req ValidateQueryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	node, err := h.taskService.ParseQuery(c.Request.Context(), middleware.GetUserID(c), orgID, req.ProjectID, req.Query)
+	if err != nil {
+		respondTaskError(c, err, "Failed to validate query")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": true, "ast": node})
+}
+
+// taskListParams reads task list filters from the query string
+func taskListParams(c *gin.Context) (services.TaskListParams, error) {
+	params := services.TaskListParams{
+		Query:      c.Query("q"),
+		Statuses:   splitQuery(c.Query("status")),
+		Priorities: splitQuery(c.Query("priority")),
+		Labels:     splitQuery(c.Query("label")),
+		Sort:       splitQuery(c.Query("sort")),
+	}
+
+	if raw := c.Query("assignee_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return params, errors.New("assignee_id must be a number")
+		}
+		assigneeID := uint(id)
+		params.AssigneeID = &assigneeID
+	}
+
+	for name, target := range map[string]*int{"page": &params.Page, "page_size": &params.PageSize} {
+		if raw := c.Query(name); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 1 {
+				return params, fmt.Errorf("%s must be a positive number", name)
+			}
+			*target = n
+		}
+	}
+
+	for key, values := range c.Request.URL.Query() {
+		rest, ok := strings.CutPrefix(key, "cf.")
+		if !ok {
+			continue
+		}
+		fieldKey, operator, _ := strings.Cut(rest, ".")
+		for _, value := range values {
+			params.CustomFields = append(params.CustomFields, services.CustomFieldParam{
+				Key:      fieldKey,
+				Operator: operator,
+				Value:    value,
+			})
+		}
+	}
+
+	return params, nil
+}
+
+// splitQuery splits a comma-separated query value, dropping empty items
+func splitQuery(raw string) []string {
+	var values []string
+	for _, value := range strings.Split(raw, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// GetWatching returns whether the user watches a task
+func (h *TaskHandler) GetWatching(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	watching, err := h.taskService.IsWatching(c.Request.Context(), middleware.GetUserID(c), taskID)
+	if err != nil {
+		respondTaskError(c, err, "Failed to load task")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"watching": watching})
+}
+
+// WatchTask makes the user watch a task
+func (h *TaskHandler) WatchTask(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.taskService.WatchTask(c.Request.Context(), middleware.GetUserID(c), taskID); err != nil {
+		respondTaskError(c, err, "Failed to watch task")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"watching": true})
+}
+
+// UnwatchTask stops the user watching a task
+func (h *TaskHandler) UnwatchTask(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.taskService.UnwatchTask(c.Request.Context(), middleware.GetUserID(c), taskID); err != nil {
+		respondTaskError(c, err, "Failed to unwatch task")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"watching": false})
+}
+
+func respondTaskError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrProjectNotFound), errors.Is(err, services.ErrTaskNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidTaskFilter):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
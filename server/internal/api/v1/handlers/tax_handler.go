@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/tax"
+	"github.com/gin-gonic/gin"
+)
+
+// TaxHandler handles tax rule and billing detail requests
+type TaxHandler struct {
+	taxService *services.TaxService
+}
+
+// NewTaxHandler creates a new instance of TaxHandler
+func NewTaxHandler(taxService *services.TaxService) *TaxHandler {
+	return &TaxHandler{
+		taxService: taxService,
+	}
+}
+
+type BillingDetailsRequest struct {
+	Name    string `json:"billing_name"`
+	Email   string `json:"billing_email"`
+	Address string `json:"billing_address"`
+	Country string `json:"billing_country"`
+	TaxID   string `json:"tax_id"`
+}
+
+// ListJurisdictions lists the countries tax is charged in
+func (h *TaxHandler) ListJurisdictions(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"jurisdictions": h.taxService.ListJurisdictions()})
+}
+
+// ValidateTaxID checks the tax_id query parameter against the format of the
+// country query parameter. An ID in the wrong format is answered with
+// valid: false rather than an error.
+func (h *TaxHandler) ValidateTaxID(c *gin.Context) {
+	taxID, treatment, err := h.taxService.ValidateTaxID(c.Query("country"), c.Query("tax_id"))
+	if err != nil {
+		if errors.Is(err, tax.ErrInvalidTaxID) {
+			c.JSON(http.StatusOK, gin.H{"valid": false, "error": err.Error()})
+			return
+		}
+		respondTaxError(c, err, "Failed to validate tax ID")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": true, "tax_id": taxID, "tax": treatment})
+}
+
+// GetBillingDetails returns the organization's billing details
+func (h *TaxHandler) GetBillingDetails(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	details, err := h.taxService.GetBillingDetails(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondTaxError(c, err, "Failed to get billing details")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"billing_details": details})
+}
+
+// UpdateBillingDetails sets the details the organization is invoiced to
+func (h *TaxHandler) UpdateBillingDetails(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req BillingDetailsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	details, err := h.taxService.UpdateBillingDetails(c.Request.Context(), middleware.GetUserID(c), orgID, services.BillingDetailsInput{
+		Name:    req.Name,
+		Email:   req.Email,
+		Address: req.Address,
+		Country: req.Country,
+		TaxID:   req.TaxID,
+	})
+	if err != nil {
+		respondTaxError(c, err, "Failed to update billing details")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"billing_details": details})
+}
+
+func respondTaxError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrOrganizationNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, tax.ErrInvalidCountry), errors.Is(err, tax.ErrInvalidTaxID),
+		errors.Is(err, services.ErrTaxIDNeedsCountry):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -0,0 +1,433 @@
+package handlers
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/services"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"github.com/gin-gonic/gin"
+)
+
+// TimeHandler handles time tracking and timesheet requests
+type TimeHandler struct {
+	timeService *services.TimeService
+}
+
+// NewTimeHandler creates a new instance of TimeHandler
+func NewTimeHandler(timeService *services.TimeService) *TimeHandler {
+	return &TimeHandler{
+		timeService: timeService,
+	}
+}
+
+type StartTimerRequest struct {
+	Billable bool   `json:"billable"`
+	Notes    string `json:"notes"`
+}
+
+type TimeEntryRequest struct {
+	StartedAt time.Time  `json:"started_at" binding:"required"`
+	EndedAt   *time.Time `json:"ended_at"`
+	Billable  bool       `json:"billable"`
+	Notes     string     `json:"notes"`
+}
+
+type SubmitTimesheetRequest struct {
+	Week string `json:"week"` // any date in the week, YYYY-MM-DD; defaults to the current week
+}
+
+type ReviewTimesheetRequest struct {
+	Note string `json:"note"`
+}
+
+func (r TimeEntryRequest) toInput() services.TimeEntryInput {
+	return services.TimeEntryInput{
+		StartedAt: r.StartedAt,
+		EndedAt:   r.EndedAt,
+		Billable:  r.Billable,
+		Notes:     r.Notes,
+	}
+}
+
+// StartTimer starts a timer on a task, stopping any running timer
+func (h *TimeHandler) StartTimer(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req StartTimerRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.timeService.StartTimer(c.Request.Context(), middleware.GetUserID(c), taskID, req.Billable, req.Notes)
+	if err != nil {
+		respondTimeError(c, err, "Failed to start timer")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"time_entry": entry})
+}
+
+// StopTimer stops the user's running timer
+func (h *TimeHandler) StopTimer(c *gin.Context) {
+	entry, err := h.timeService.StopTimer(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		respondTimeError(c, err, "Failed to stop timer")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"time_entry": entry})
+}
+
+// GetTimer returns the user's running timer, or null
+func (h *TimeHandler) GetTimer(c *gin.Context) {
+	entry, err := h.timeService.RunningTimer(c.Request.Context(), middleware.GetUserID(c))
+	if err != nil {
+		respondTimeError(c, err, "Failed to get timer")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"time_entry": entry})
+}
+
+// CreateTimeEntry records time on a task manually
+func (h *TimeHandler) CreateTimeEntry(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req TimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.timeService.CreateEntry(c.Request.Context(), middleware.GetUserID(c), taskID, req.toInput())
+	if err != nil {
+		respondTimeError(c, err, "Failed to create time entry")
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"time_entry": entry})
+}
+
+// ListTimeEntries lists the time tracked on a task
+func (h *TimeHandler) ListTimeEntries(c *gin.Context) {
+	taskID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	entries, err := h.timeService.ListTaskEntries(c.Request.Context(), middleware.GetUserID(c), taskID)
+	if err != nil {
+		respondTimeError(c, err, "Failed to list time entries")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"time_entries": entries})
+}
+
+// UpdateTimeEntry changes one of the user's time entries
+func (h *TimeHandler) UpdateTimeEntry(c *gin.Context) {
+	entryID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req TimeEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := h.timeService.UpdateEntry(c.Request.Context(), middleware.GetUserID(c), entryID, req.toInput())
+	if err != nil {
+		respondTimeError(c, err, "Failed to update time entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"time_entry": entry})
+}
+
+// DeleteTimeEntry deletes one of the user's time entries
+func (h *TimeHandler) DeleteTimeEntry(c *gin.Context) {
+	entryID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.timeService.DeleteEntry(c.Request.Context(), middleware.GetUserID(c), entryID); err != nil {
+		respondTimeError(c, err, "Failed to delete time entry")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Time entry deleted successfully"})
+}
+
+// GetTimesheet returns the user's timesheet for the week of the week query
+// parameter, defaulting to the current week
+func (h *TimeHandler) GetTimesheet(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	week, ok := parseDateQuery(c, "week")
+	if !ok {
+		return
+	}
+	if week.IsZero() {
+		week = time.Now()
+	}
+
+	timesheet, err := h.timeService.GetTimesheet(c.Request.Context(), middleware.GetUserID(c), orgID, week)
+	if err != nil {
+		respondTimeError(c, err, "Failed to get timesheet")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timesheet": timesheet})
+}
+
+// SubmitTimesheet submits the user's week for approval
+func (h *TimeHandler) SubmitTimesheet(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SubmitTimesheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	week := time.Now()
+	if req.Week != "" {
+		parsed, err := time.Parse(dateLayout, req.Week)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "week must be a date in YYYY-MM-DD format"})
+			return
+		}
+		week = parsed
+	}
+
+	timesheet, err := h.timeService.SubmitTimesheet(c.Request.Context(), middleware.GetUserID(c), orgID, week)
+	if err != nil {
+		respondTimeError(c, err, "Failed to submit timesheet")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timesheet": timesheet})
+}
+
+// ListPendingTimesheets lists the submitted timesheets the user may review
+func (h *TimeHandler) ListPendingTimesheets(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	timesheets, err := h.timeService.ListPendingTimesheets(c.Request.Context(), middleware.GetUserID(c), orgID)
+	if err != nil {
+		respondTimeError(c, err, "Failed to list timesheets")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timesheets": timesheets})
+}
+
+// GetTimesheetByID returns a timesheet to its owner or a reviewer
+func (h *TimeHandler) GetTimesheetByID(c *gin.Context) {
+	timesheetID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	timesheet, err := h.timeService.GetTimesheetByID(c.Request.Context(), middleware.GetUserID(c), timesheetID)
+	if err != nil {
+		respondTimeError(c, err, "Failed to get timesheet")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timesheet": timesheet})
+}
+
+// ApproveTimesheet approves a submitted timesheet
+func (h *TimeHandler) ApproveTimesheet(c *gin.Context) {
+	h.review(c, h.timeService.ApproveTimesheet, "Failed to approve timesheet")
+}
+
+// RejectTimesheet returns a submitted timesheet to its owner for correction
+func (h *TimeHandler) RejectTimesheet(c *gin.Context) {
+	h.review(c, h.timeService.RejectTimesheet, "Failed to reject timesheet")
+}
+
+// ExportUserTimeEntries downloads an organization's finished time entries as
+// CSV, for the user_id query parameter or the current user. Admins may omit
+// user_id with all=true to export every member.
+func (h *TimeHandler) ExportUserTimeEntries(c *gin.Context) {
+	orgID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	from, to, ok := parseExportRange(c)
+	if !ok {
+		return
+	}
+
+	userID := middleware.GetUserID(c)
+	memberID := userID
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil || id == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user_id"})
+			return
+		}
+		memberID = uint(id)
+	} else if c.Query("all") == "true" {
+		memberID = 0
+	}
+
+	rows, err := h.timeService.ExportUserEntries(c.Request.Context(), userID, orgID, memberID, from, to)
+	if err != nil {
+		respondTimeError(c, err, "Failed to export time entries")
+		return
+	}
+
+	writeTimeEntriesCSV(c, fmt.Sprintf("time-entries-org-%d.csv", orgID), rows)
+}
+
+// ExportProjectTimeEntries downloads a project's finished time entries as CSV
+func (h *TimeHandler) ExportProjectTimeEntries(c *gin.Context) {
+	projectID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	from, to, ok := parseExportRange(c)
+	if !ok {
+		return
+	}
+
+	rows, err := h.timeService.ExportProjectEntries(c.Request.Context(), middleware.GetUserID(c), projectID, from, to)
+	if err != nil {
+		respondTimeError(c, err, "Failed to export time entries")
+		return
+	}
+
+	writeTimeEntriesCSV(c, fmt.Sprintf("time-entries-project-%d.csv", projectID), rows)
+}
+
+func (h *TimeHandler) review(c *gin.Context, review func(ctx context.Context, userID, timesheetID uint, note string) (*models.Timesheet, error), fallback string) {
+	timesheetID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req ReviewTimesheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil && c.Request.ContentLength != 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	timesheet, err := review(c.Request.Context(), middleware.GetUserID(c), timesheetID, req.Note)
+	if err != nil {
+		respondTimeError(c, err, fallback)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"timesheet": timesheet})
+}
+
+// parseExportRange reads the from and to dates of an export. Both are
+// inclusive days; to is returned as the exclusive start of the next day.
+func parseExportRange(c *gin.Context) (time.Time, time.Time, bool) {
+	from, ok := parseDateQuery(c, "from")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	to, ok := parseDateQuery(c, "to")
+	if !ok {
+		return time.Time{}, time.Time{}, false
+	}
+	if !to.IsZero() {
+		to = to.AddDate(0, 0, 1)
+	}
+	return from, to, true
+}
+
+func writeTimeEntriesCSV(c *gin.Context, filename string, rows []repositories.TimeEntryExportRow) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{
+		"date", "user_id", "email", "name", "project_id", "project", "task_id", "task",
+		"started_at", "ended_at", "hours", "billable", "notes", "timesheet_status",
+	})
+	for _, row := range rows {
+		status := string(models.TimesheetStatusDraft)
+		if row.TimesheetStatus != nil {
+			status = *row.TimesheetStatus
+		}
+		w.Write([]string{
+			row.StartedAt.UTC().Format(dateLayout),
+			strconv.FormatUint(uint64(row.UserID), 10),
+			row.UserEmail,
+			strings.TrimSpace(row.UserFirstName + " " + row.UserLastName),
+			strconv.FormatUint(uint64(row.ProjectID), 10),
+			csvSafe(row.ProjectName),
+			strconv.FormatUint(uint64(row.TaskID), 10),
+			csvSafe(row.TaskTitle),
+			row.StartedAt.UTC().Format(time.RFC3339),
+			row.EndedAt.UTC().Format(time.RFC3339),
+			strconv.FormatFloat(float64(row.Duration)/3600, 'f', 2, 64),
+			strconv.FormatBool(row.Billable),
+			csvSafe(row.Notes),
+			status,
+		})
+	}
+	w.Flush()
+}
+
+// csvSafe keeps spreadsheets from evaluating user text as a formula
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func respondTimeError(c *gin.Context, err error, fallback string) {
+	switch {
+	case errors.Is(err, services.ErrTimeEntryNotFound), errors.Is(err, services.ErrTimesheetNotFound),
+		errors.Is(err, services.ErrTaskNotFound), errors.Is(err, services.ErrProjectNotFound),
+		errors.Is(err, services.ErrNoRunningTimer):
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrForbidden), errors.Is(err, services.ErrNotEntryOwner),
+		errors.Is(err, services.ErrOwnTimesheet):
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrTimesheetLocked), errors.Is(err, services.ErrTimerRunning),
+		errors.Is(err, services.ErrTimesheetNotSubmitted), errors.Is(err, services.ErrEmptyTimesheet):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrEntryInFuture), errors.Is(err, models.ErrInvalidEntryTimes),
+		errors.Is(err, models.ErrInvalidWeekStart):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrFeatureNotEntitled):
+		c.JSON(http.StatusPaymentRequired, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/utils"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
 	"github.com/gin-gonic/gin"
 )
 
@@ -26,18 +27,53 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		claims, err := utils.ValidateToken(parts[1])
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		authenticate(c, parts[1])
+	}
+}
+
+// StreamAuthMiddleware verifies JWT token like AuthMiddleware, also taking it
+// from the access_token query parameter for clients such as browsers'
+// EventSource and WebSocket that cannot set headers
+func StreamAuthMiddleware() gin.HandlerFunc {
+	auth := AuthMiddleware()
+	return func(c *gin.Context) {
+		if c.GetHeader("Authorization") != "" {
+			auth(c)
+			return
+		}
+		token := c.Query("access_token")
+		if token == "" {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header or access_token is required"})
 			c.Abort()
 			return
 		}
+		authenticate(c, token)
+	}
+}
+
+// authenticate validates the token and sets the user's claims in the context
+func authenticate(c *gin.Context, token string) {
+	claims, err := utils.ValidateToken(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+		c.Abort()
+		return
+	}
+
+	// Set user info in context
+	c.Set("user_id", claims.UserID)
+	c.Set("email", claims.Email)
 
-		// Set user info in context
-		c.Set("user_id", claims.UserID)
-		c.Set("email", claims.Email)
-		c.Next()
+	// Attribute history records written during this request to the user
+	ctx := models.WithActor(c.Request.Context(), claims.UserID)
+	// Mark requests that change data, which read-only organizations cannot make
+	switch c.Request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		ctx = models.WithWrite(ctx)
 	}
+	c.Request = c.Request.WithContext(ctx)
+	c.Next()
 }
 
 // GetUserID retrieves the authenticated user's ID from the context
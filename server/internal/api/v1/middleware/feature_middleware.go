@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// FeatureChecker tells whether an organization can use a feature
+type FeatureChecker interface {
+	HasFeature(ctx context.Context, orgID uint, feature string) (bool, error)
+}
+
+// RequireFeature only lets requests through when the organization named by
+// the route's :id parameter can use the feature, answering 402 otherwise. It
+// is meant for /organizations/:id routes; services gate other routes themselves.
+func RequireFeature(checker FeatureChecker, feature string) gin.HandlerFunc {
+	if !models.IsKnownFeature(feature) {
+		panic("middleware: unknown feature " + feature)
+	}
+	return func(c *gin.Context) {
+		orgID, err := strconv.ParseUint(c.Param("id"), 10, 64)
+		if err != nil || orgID == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid id"})
+			c.Abort()
+			return
+		}
+
+		enabled, err := checker.HasFeature(c.Request.Context(), uint(orgID), feature)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check features"})
+			c.Abort()
+			return
+		}
+		if !enabled {
+			c.JSON(http.StatusPaymentRequired, gin.H{
+				"error":   "The organization's plan does not include this feature",
+				"feature": feature,
+			})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
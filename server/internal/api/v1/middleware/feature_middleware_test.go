@@ -0,0 +1,61 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// featureSet lets organization 1 use the features it holds
+type featureSet map[string]bool
+
+func (f featureSet) HasFeature(ctx context.Context, orgID uint, feature string) (bool, error) {
+	if f == nil {
+		return false, errors.New("entitlements unavailable")
+	}
+	return orgID == 1 && f[feature], nil
+}
+
+func TestRequireFeature(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		checker featureSet
+		path    string
+		status  int
+	}{
+		{"entitled", featureSet{models.FeatureBudgets: true}, "/organizations/1/hourly-rates", http.StatusOK},
+		{"another feature", featureSet{models.FeatureTimeTracking: true}, "/organizations/1/hourly-rates", http.StatusPaymentRequired},
+		{"another organization", featureSet{models.FeatureBudgets: true}, "/organizations/2/hourly-rates", http.StatusPaymentRequired},
+		{"invalid id", featureSet{models.FeatureBudgets: true}, "/organizations/acme/hourly-rates", http.StatusBadRequest},
+		{"check failed", nil, "/organizations/1/hourly-rates", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.GET("/organizations/:id/hourly-rates", RequireFeature(tt.checker, models.FeatureBudgets), func(c *gin.Context) {
+				c.Status(http.StatusOK)
+			})
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if recorder.Code != tt.status {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestRequireFeatureRejectsUnknownFeatures(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RequireFeature accepted an unknown feature")
+		}
+	}()
+	RequireFeature(featureSet{}, "teleportation")
+}
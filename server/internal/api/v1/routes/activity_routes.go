@@ -0,0 +1,12 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupActivityRoutes(router *gin.RouterGroup, activityHandler *handlers.ActivityHandler) {
+	router.GET("/tasks/:id/activity", activityHandler.GetTaskActivity)
+	router.GET("/projects/:id/activity", activityHandler.GetProjectActivity)
+	router.GET("/organizations/:id/activity", activityHandler.GetOrganizationActivity)
+}
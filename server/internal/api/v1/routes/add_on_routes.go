@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAddOnRoutes(router *gin.RouterGroup, addOnHandler *handlers.AddOnHandler) {
+	router.GET("/add-ons", addOnHandler.ListAddOns)
+	router.POST("/add-ons", addOnHandler.CreateAddOn)
+	router.PUT("/add-ons/:id", addOnHandler.UpdateAddOn)
+	router.DELETE("/add-ons/:id", addOnHandler.ArchiveAddOn)
+	router.GET("/organizations/:id/add-ons", addOnHandler.ListOrganizationAddOns)
+	router.PUT("/organizations/:id/add-ons/:addOnId", addOnHandler.SetOrganizationAddOn)
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAttachmentRoutes(router *gin.RouterGroup, attachmentHandler *handlers.AttachmentHandler) {
+	router.GET("/tasks/:id/attachments", attachmentHandler.ListTaskAttachments)
+	router.POST("/tasks/:id/attachments", attachmentHandler.UploadTaskAttachment)
+	router.POST("/tasks/:id/attachments/presign", attachmentHandler.PresignTaskAttachment)
+	router.GET("/comments/:id/attachments", attachmentHandler.ListCommentAttachments)
+	router.POST("/comments/:id/attachments", attachmentHandler.UploadCommentAttachment)
+	router.POST("/comments/:id/attachments/presign", attachmentHandler.PresignCommentAttachment)
+	router.GET("/expenses/:id/receipts", attachmentHandler.ListExpenseReceipts)
+	router.POST("/expenses/:id/receipts", attachmentHandler.UploadExpenseReceipt)
+	router.POST("/expenses/:id/receipts/presign", attachmentHandler.PresignExpenseReceipt)
+	router.GET("/attachments/:id", attachmentHandler.GetAttachment)
+	router.GET("/attachments/:id/download", attachmentHandler.DownloadAttachment)
+	router.POST("/attachments/:id/complete", attachmentHandler.CompleteUpload)
+	router.DELETE("/attachments/:id", attachmentHandler.DeleteAttachment)
+}
+
+// SetupFileRoutes serves presigned URLs of the local storage backend. They are
+// public because the URL signature authorizes each request.
+func SetupFileRoutes(router *gin.Engine, fileHandler *handlers.FileHandler) {
+	router.GET("/files/*key", fileHandler.DownloadFile)
+	router.PUT("/files/*key", fileHandler.UploadFile)
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupBudgetRoutes gates the organization routes with RequireFeature; BudgetService checks
+// the feature itself on the others, whose :id is not an organization
+func SetupBudgetRoutes(router *gin.RouterGroup, budgetHandler *handlers.BudgetHandler, features middleware.FeatureChecker) {
+	org := router.Group("/organizations/:id", middleware.RequireFeature(features, models.FeatureBudgets))
+	router.GET("/projects/:id/budget", budgetHandler.GetBudgetReport)
+	router.GET("/projects/:id/budget/lines", budgetHandler.ListBudgetLines)
+	router.POST("/projects/:id/budget/lines", budgetHandler.CreateBudgetLine)
+	router.GET("/projects/:id/budget/alerts", budgetHandler.ListBudgetAlerts)
+	router.PUT("/budget-lines/:id", budgetHandler.UpdateBudgetLine)
+	router.DELETE("/budget-lines/:id", budgetHandler.DeleteBudgetLine)
+	org.GET("/hourly-rates", budgetHandler.ListHourlyRates)
+	org.PUT("/hourly-rates", budgetHandler.SetHourlyRate)
+}
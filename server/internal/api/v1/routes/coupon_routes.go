@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupCouponRoutes(router *gin.RouterGroup, couponHandler *handlers.CouponHandler) {
+	router.GET("/coupons", couponHandler.ListCoupons)
+	router.POST("/coupons", couponHandler.CreateCoupon)
+	router.DELETE("/coupons/:id", couponHandler.ArchiveCoupon)
+	router.GET("/organizations/:id/discount", couponHandler.GetDiscount)
+	router.POST("/organizations/:id/discount", couponHandler.RedeemCoupon)
+	router.DELETE("/organizations/:id/discount", couponHandler.RemoveDiscount)
+	router.GET("/organizations/:id/credits", couponHandler.GetCredits)
+	router.POST("/organizations/:id/credits", couponHandler.GrantCredit)
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupCreditNoteRoutes(router *gin.RouterGroup, creditNoteHandler *handlers.CreditNoteHandler) {
+	router.GET("/organizations/:id/credit-notes", creditNoteHandler.ListCreditNotes)
+	router.GET("/credit-notes/:id", creditNoteHandler.GetCreditNote)
+	router.GET("/credit-notes/:id/pdf", creditNoteHandler.DownloadPDF)
+	router.GET("/credit-notes/:id/html", creditNoteHandler.ViewHTML)
+}
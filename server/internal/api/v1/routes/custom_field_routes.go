@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupCustomFieldRoutes(router *gin.RouterGroup, fieldHandler *handlers.CustomFieldHandler) {
+	router.GET("/projects/:id/custom-fields", fieldHandler.ListFields)
+	router.POST("/projects/:id/custom-fields", fieldHandler.CreateField)
+	router.POST("/projects/:id/custom-fields/templates", fieldHandler.ApplyTemplate)
+	router.PUT("/custom-fields/:id", fieldHandler.UpdateField)
+	router.DELETE("/custom-fields/:id", fieldHandler.DeleteField)
+	router.PUT("/tasks/:id/custom-fields", fieldHandler.SetTaskValues)
+}
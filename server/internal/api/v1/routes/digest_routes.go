@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupDigestRoutes(router *gin.RouterGroup, digestHandler *handlers.DigestHandler) {
+	router.GET("/digest", digestHandler.GetDigest)
+	router.PUT("/digest", digestHandler.UpdateDigest)
+}
+
+// SetupDigestUnsubscribeRoutes serves the unsubscribe links in digests. The
+// routes are public: the signed token in the link says whose digest it is.
+func SetupDigestUnsubscribeRoutes(router *gin.Engine, digestHandler *handlers.DigestHandler) {
+	router.GET("/api/v1/digest/unsubscribe", digestHandler.ConfirmUnsubscribe)
+	router.POST("/api/v1/digest/unsubscribe", digestHandler.Unsubscribe)
+}
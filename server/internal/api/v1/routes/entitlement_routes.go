@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupEntitlementRoutes(router *gin.RouterGroup, entitlementHandler *handlers.EntitlementHandler) {
+	router.GET("/features", entitlementHandler.ListFeatures)
+	router.GET("/organizations/:id/entitlements", entitlementHandler.GetEntitlements)
+	router.GET("/organizations/:id/feature-overrides", entitlementHandler.ListOverrides)
+	router.PUT("/organizations/:id/feature-overrides/:feature", entitlementHandler.SetOverride)
+	router.DELETE("/organizations/:id/feature-overrides/:feature", entitlementHandler.DeleteOverride)
+}
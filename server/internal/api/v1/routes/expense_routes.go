@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupExpenseRoutes(router *gin.RouterGroup, expenseHandler *handlers.ExpenseHandler) {
+	router.GET("/projects/:id/expenses", expenseHandler.ListExpenses)
+	router.POST("/projects/:id/expenses", expenseHandler.CreateExpense)
+	router.GET("/expenses/:id", expenseHandler.GetExpense)
+	router.PUT("/expenses/:id", expenseHandler.UpdateExpense)
+	router.DELETE("/expenses/:id", expenseHandler.DeleteExpense)
+	router.POST("/expenses/:id/approve", expenseHandler.ApproveExpense)
+	router.POST("/expenses/:id/reject", expenseHandler.RejectExpense)
+}
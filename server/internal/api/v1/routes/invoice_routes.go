@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupInvoiceRoutes(router *gin.RouterGroup, invoiceHandler *handlers.InvoiceHandler) {
+	router.GET("/organizations/:id/invoices", invoiceHandler.ListInvoices)
+	router.GET("/invoices/:id", invoiceHandler.GetInvoice)
+	router.GET("/invoices/:id/pdf", invoiceHandler.DownloadPDF)
+	router.GET("/invoices/:id/html", invoiceHandler.ViewHTML)
+}
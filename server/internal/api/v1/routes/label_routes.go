@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupLabelRoutes(router *gin.RouterGroup, labelHandler *handlers.LabelHandler) {
+	router.GET("/organizations/:id/labels", labelHandler.ListLabels)
+	router.POST("/organizations/:id/labels", labelHandler.CreateLabel)
+	router.PUT("/labels/:id", labelHandler.UpdateLabel)
+	router.DELETE("/labels/:id", labelHandler.DeleteLabel)
+	router.PUT("/tasks/:id/labels", labelHandler.SetTaskLabels)
+}
@@ -0,0 +1,20 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupNotificationRoutes(router *gin.RouterGroup, notificationHandler *handlers.NotificationHandler) {
+	notifications := router.Group("/notifications")
+	{
+		notifications.GET("", notificationHandler.ListNotifications)
+		notifications.GET("/unread-count", notificationHandler.GetUnreadCount)
+		notifications.POST("/read-all", notificationHandler.MarkAllRead)
+		notifications.GET("/types", notificationHandler.ListTypes)
+		notifications.GET("/preferences", notificationHandler.GetPreferences)
+		notifications.PUT("/preferences", notificationHandler.UpdatePreferences)
+		notifications.POST("/:id/read", notificationHandler.MarkRead)
+		notifications.DELETE("/:id/read", notificationHandler.MarkUnread)
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupPaymentWebhookRoutes receives the payment provider's webhooks. The
+// route is public because the webhook signature authenticates each request.
+func SetupPaymentWebhookRoutes(router *gin.Engine, paymentEventHandler *handlers.PaymentEventHandler) {
+	router.POST("/api/v1/webhooks/payments", paymentEventHandler.ReceiveWebhook)
+}
+
+func SetupPaymentEventRoutes(router *gin.RouterGroup, paymentEventHandler *handlers.PaymentEventHandler) {
+	router.GET("/payment-events", paymentEventHandler.ListEvents)
+	router.GET("/payment-events/:id", paymentEventHandler.GetEvent)
+	router.POST("/payment-events/:id/reprocess", paymentEventHandler.ReprocessEvent)
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupPaymentRoutes(router *gin.RouterGroup, paymentHandler *handlers.PaymentHandler) {
+	router.GET("/organizations/:id/payment-method", paymentHandler.GetPaymentMethod)
+	router.PUT("/organizations/:id/payment-method", paymentHandler.SetPaymentMethod)
+	router.POST("/invoices/:id/pay", paymentHandler.PayInvoice)
+	router.POST("/invoices/:id/refund", paymentHandler.RefundInvoice)
+	router.GET("/invoices/:id/transactions", paymentHandler.ListTransactions)
+}
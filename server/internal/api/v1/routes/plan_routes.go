@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupPlanRoutes(router *gin.RouterGroup, planHandler *handlers.PlanHandler) {
+	router.GET("/plans", planHandler.ListPlans)
+	router.POST("/plans", planHandler.CreatePlan)
+	router.GET("/plans/:id", planHandler.GetPlan)
+	router.PUT("/plans/:id", planHandler.UpdatePlan)
+	router.DELETE("/plans/:id", planHandler.ArchivePlan)
+}
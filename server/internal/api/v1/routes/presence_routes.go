@@ -0,0 +1,17 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupPresenceRoutes(router *gin.RouterGroup, presenceHandler *handlers.PresenceHandler) {
+	router.GET("/presence/:resource/:id", presenceHandler.GetViewers)
+}
+
+// SetupPresenceSocketRoutes serves the presence WebSocket. The router
+// authenticates with middleware.StreamAuthMiddleware, as browsers cannot send
+// headers when opening a WebSocket.
+func SetupPresenceSocketRoutes(router *gin.RouterGroup, presenceHandler *handlers.PresenceHandler) {
+	router.GET("/presence/ws", presenceHandler.Connect)
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRealtimeRoutes serves the live event stream. The router authenticates
+// with middleware.StreamAuthMiddleware, as browsers cannot send headers with
+// an EventSource.
+func SetupRealtimeRoutes(router *gin.RouterGroup, realtimeHandler *handlers.RealtimeHandler) {
+	router.GET("/events", realtimeHandler.Stream)
+}
@@ -0,0 +1,22 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupSavedFilterRoutes gates the organization routes with RequireFeature; SavedFilterService checks
+// the feature itself on the others, whose :id is not an organization
+func SetupSavedFilterRoutes(router *gin.RouterGroup, filterHandler *handlers.SavedFilterHandler, features middleware.FeatureChecker) {
+	org := router.Group("/organizations/:id", middleware.RequireFeature(features, models.FeatureSavedFilters))
+	org.GET("/filters", filterHandler.ListFilters)
+	org.POST("/filters", filterHandler.CreateFilter)
+	router.GET("/filters/:id", filterHandler.GetFilter)
+	router.PUT("/filters/:id", filterHandler.UpdateFilter)
+	router.DELETE("/filters/:id", filterHandler.DeleteFilter)
+	router.GET("/filters/:id/tasks", filterHandler.FilterTasks)
+	router.PUT("/filters/:id/subscription", filterHandler.Subscribe)
+	router.DELETE("/filters/:id/subscription", filterHandler.Unsubscribe)
+}
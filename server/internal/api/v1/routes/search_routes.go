@@ -0,0 +1,10 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupSearchRoutes(router *gin.RouterGroup, searchHandler *handlers.SearchHandler) {
+	router.GET("/organizations/:id/search", searchHandler.Search)
+}
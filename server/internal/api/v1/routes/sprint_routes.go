@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupSprintRoutes(router *gin.RouterGroup, sprintHandler *handlers.SprintHandler) {
+	router.POST("/projects/:id/sprints", sprintHandler.CreateSprint)
+	router.GET("/projects/:id/sprints", sprintHandler.ListSprints)
+	router.GET("/teams/:id/velocity", sprintHandler.GetTeamVelocity)
+
+	sprints := router.Group("/sprints")
+	{
+		sprints.GET("/:id", sprintHandler.GetSprint)
+		sprints.PUT("/:id", sprintHandler.UpdateSprint)
+		sprints.DELETE("/:id", sprintHandler.DeleteSprint)
+		sprints.POST("/:id/start", sprintHandler.StartSprint)
+		sprints.POST("/:id/complete", sprintHandler.CompleteSprint)
+		sprints.POST("/:id/tasks", sprintHandler.AddTasks)
+		sprints.DELETE("/:id/tasks/:taskId", sprintHandler.RemoveTask)
+		sprints.GET("/:id/burndown", sprintHandler.GetBurndown)
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupSubscriptionRoutes(router *gin.RouterGroup, subscriptionHandler *handlers.SubscriptionHandler) {
+	router.GET("/organizations/:id/subscription", subscriptionHandler.GetSubscription)
+	router.POST("/organizations/:id/subscription", subscriptionHandler.Subscribe)
+	router.GET("/organizations/:id/subscriptions", subscriptionHandler.ListSubscriptions)
+	router.POST("/organizations/:id/subscription/trial", subscriptionHandler.StartTrial)
+	router.POST("/organizations/:id/subscription/upgrade", subscriptionHandler.Upgrade)
+	router.POST("/organizations/:id/subscription/downgrade", subscriptionHandler.Downgrade)
+	router.PUT("/organizations/:id/subscription/seats", subscriptionHandler.SetSeats)
+	router.POST("/organizations/:id/subscription/cancel", subscriptionHandler.Cancel)
+	router.POST("/organizations/:id/subscription/resume", subscriptionHandler.Resume)
+}
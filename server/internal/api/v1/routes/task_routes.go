@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupTaskRoutes(router *gin.RouterGroup, taskHandler *handlers.TaskHandler) {
+	router.GET("/projects/:id/tasks", taskHandler.ListTasks)
+	router.GET("/projects/:id/tasks/export", taskHandler.ExportTasks)
+	router.GET("/projects/:id/board", taskHandler.GetBoard)
+	router.GET("/projects/:id/reports/tasks", taskHandler.GetTaskReport)
+	router.GET("/organizations/:id/tasks", taskHandler.SearchTasks)
+	router.POST("/organizations/:id/tasks/query/validate", taskHandler.ValidateQuery)
+	router.GET("/tasks/:id/watch", taskHandler.GetWatching)
+	router.POST("/tasks/:id/watch", taskHandler.WatchTask)
+	router.DELETE("/tasks/:id/watch", taskHandler.UnwatchTask)
+}
@@ -0,0 +1,13 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/gin-gonic/gin"
+)
+
+func SetupTaxRoutes(router *gin.RouterGroup, taxHandler *handlers.TaxHandler) {
+	router.GET("/tax/jurisdictions", taxHandler.ListJurisdictions)
+	router.GET("/tax/validate-id", taxHandler.ValidateTaxID)
+	router.GET("/organizations/:id/billing-details", taxHandler.GetBillingDetails)
+	router.PUT("/organizations/:id/billing-details", taxHandler.UpdateBillingDetails)
+}
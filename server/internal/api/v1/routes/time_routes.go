@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/handlers"
+	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/middleware"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/gin-gonic/gin"
+)
+
+// SetupTimeRoutes gates the organization routes with RequireFeature; TimeService checks
+// the feature itself on the others, whose :id is not an organization
+func SetupTimeRoutes(router *gin.RouterGroup, timeHandler *handlers.TimeHandler, features middleware.FeatureChecker) {
+	org := router.Group("/organizations/:id", middleware.RequireFeature(features, models.FeatureTimeTracking))
+	router.GET("/timer", timeHandler.GetTimer)
+	router.POST("/timer/stop", timeHandler.StopTimer)
+	router.POST("/tasks/:id/timer/start", timeHandler.StartTimer)
+	router.GET("/tasks/:id/time-entries", timeHandler.ListTimeEntries)
+	router.POST("/tasks/:id/time-entries", timeHandler.CreateTimeEntry)
+	router.PUT("/time-entries/:id", timeHandler.UpdateTimeEntry)
+	router.DELETE("/time-entries/:id", timeHandler.DeleteTimeEntry)
+	org.GET("/timesheets", timeHandler.GetTimesheet)
+	org.POST("/timesheets/submit", timeHandler.SubmitTimesheet)
+	org.GET("/timesheets/pending", timeHandler.ListPendingTimesheets)
+	org.GET("/time-entries/export", timeHandler.ExportUserTimeEntries)
+	router.GET("/projects/:id/time-entries/export", timeHandler.ExportProjectTimeEntries)
+	router.GET("/timesheets/:id", timeHandler.GetTimesheetByID)
+	router.POST("/timesheets/:id/approve", timeHandler.ApproveTimesheet)
+	router.POST("/timesheets/:id/reject", timeHandler.RejectTimesheet)
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrForbidden            = errors.New("you do not have access to this resource")
+	ErrOrganizationNotFound = errors.New("organization not found")
+	ErrProjectNotFound      = errors.New("project not found")
+	ErrTeamNotFound         = errors.New("team not found")
+	ErrTaskNotFound         = errors.New("task not found")
+
+	// ErrOrganizationReadOnly is a kind of ErrForbidden, so handlers answer it
+	// like any other denied request
+	ErrOrganizationReadOnly = fmt.Errorf("%w: the organization is read-only until its overdue invoices are paid", ErrForbidden)
+)
+
+// accessChecker verifies that a user belongs to the organization owning a
+// resource, and that the organization can use the feature the resource
+// belongs to
+type accessChecker struct {
+	orgRepo     repositories.OrganizationRepository
+	projectRepo repositories.ProjectRepository
+	feature     featureGate
+}
+
+// featureGate limits a service to organizations that can use a paid feature.
+// The zero gate lets every organization through.
+type featureGate struct {
+	entitlements *EntitlementService
+	key          string
+}
+
+// check returns ErrFeatureNotEntitled if the organization cannot use the feature
+func (g featureGate) check(ctx context.Context, orgID uint) error {
+	if g.entitlements == nil {
+		return nil
+	}
+	return g.entitlements.RequireFeature(ctx, orgID, g.key)
+}
+
+// organization checks that the user is a member of the organization, and
+// that the organization is not read-only if the request changes data
+func (a accessChecker) organization(ctx context.Context, orgID, userID uint) error {
+	if err := a.member(ctx, orgID, userID); err != nil {
+		return err
+	}
+	return a.writable(ctx, orgID)
+}
+
+// member checks that the user is a member of the organization, and that the
+// organization can use the checker's feature
+func (a accessChecker) member(ctx context.Context, orgID, userID uint) error {
+	isMember, err := a.orgRepo.IsMember(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if !isMember {
+		return ErrForbidden
+	}
+	return a.feature.check(ctx, orgID)
+}
+
+// writable checks that a request changing data is not made to a read-only
+// organization
+func (a accessChecker) writable(ctx context.Context, orgID uint) error {
+	if !models.IsWrite(ctx) {
+		return nil
+	}
+	readOnly, err := a.orgRepo.IsReadOnly(ctx, orgID)
+	if err != nil {
+		return err
+	}
+	if readOnly {
+		return ErrOrganizationReadOnly
+	}
+	return nil
+}
+
+// project loads the project and checks that the user belongs to its organization
+func (a accessChecker) project(ctx context.Context, projectID, userID uint) (*models.Project, error) {
+	project, err := a.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProjectNotFound
+		}
+		return nil, err
+	}
+	if err := a.organization(ctx, project.OrganizationID, userID); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// isOrganizationAdmin checks if the user is an admin of the organization
+func (a accessChecker) isOrganizationAdmin(ctx context.Context, orgID, userID uint) (bool, error) {
+	member, err := a.orgRepo.FindMember(ctx, orgID, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		return false, err
+	}
+	return member.Role == "admin", nil
+}
+
+// admin checks that the user is an admin of the organization
+func (a accessChecker) admin(ctx context.Context, orgID, userID uint) error {
+	if err := a.billing(ctx, orgID, userID); err != nil {
+		return err
+	}
+	return a.writable(ctx, orgID)
+}
+
+// billing checks that the user is an admin of the organization. Unlike admin
+// it lets read-only organizations through, so that they can pay what they
+// owe and manage their subscription.
+func (a accessChecker) billing(ctx context.Context, orgID, userID uint) error {
+	if err := a.member(ctx, orgID, userID); err != nil {
+		return err
+	}
+	isAdmin, err := a.isOrganizationAdmin(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if !isAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// manager checks that the user is an admin of the organization or a manager
+// of every one of the projects
+func (a accessChecker) manager(ctx context.Context, orgID, userID uint, projectIDs []uint) error {
+	if err := a.writable(ctx, orgID); err != nil {
+		return err
+	}
+	isAdmin, err := a.isOrganizationAdmin(ctx, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if isAdmin {
+		return nil
+	}
+	if len(projectIDs) == 0 {
+		return ErrForbidden
+	}
+
+	for _, projectID := range projectIDs {
+		member, err := a.projectRepo.FindMember(ctx, projectID, userID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrForbidden
+			}
+			return err
+		}
+		if member.Role != "manager" {
+			return ErrForbidden
+		}
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultActivityPageSize = 50
+	maxActivityPageSize     = 200
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ActivityPage is one page of an activity feed. NextCursor is empty on the last page.
+type ActivityPage struct {
+	Events     []models.ActivityEvent `json:"events"`
+	NextCursor string                 `json:"next_cursor,omitempty"`
+}
+
+type ActivityService struct {
+	activityRepo repositories.ActivityRepository
+	taskRepo     repositories.TaskRepository
+	access       accessChecker
+}
+
+func NewActivityService(
+	activityRepo repositories.ActivityRepository,
+	taskRepo repositories.TaskRepository,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *ActivityService {
+	return &ActivityService{
+		activityRepo: activityRepo,
+		taskRepo:     taskRepo,
+		access:       accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+	}
+}
+
+func (s *ActivityService) TaskFeed(ctx context.Context, userID, taskID uint, cursor string, limit int) (*ActivityPage, error) {
+	task, err := s.taskRepo.FindByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	project, err := s.access.project(ctx, task.ProjectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.page(ctx, repositories.ActivityFilter{OrganizationID: project.OrganizationID, TaskID: task.ID}, cursor, limit)
+}
+
+func (s *ActivityService) ProjectFeed(ctx context.Context, userID, projectID uint, cursor string, limit int) (*ActivityPage, error) {
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.page(ctx, repositories.ActivityFilter{OrganizationID: project.OrganizationID, ProjectID: project.ID}, cursor, limit)
+}
+
+func (s *ActivityService) OrganizationFeed(ctx context.Context, userID, orgID uint, cursor string, limit int) (*ActivityPage, error) {
+	if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.page(ctx, repositories.ActivityFilter{OrganizationID: orgID}, cursor, limit)
+}
+
+// page fetches one page of events newest first. The cursor is the ID of the
+// last event of the previous page.
+func (s *ActivityService) page(ctx context.Context, filter repositories.ActivityFilter, cursor string, limit int) (*ActivityPage, error) {
+	if cursor != "" {
+		beforeID, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil || beforeID == 0 {
+			return nil, ErrInvalidCursor
+		}
+		filter.BeforeID = uint(beforeID)
+	}
+
+	if limit <= 0 {
+		limit = defaultActivityPageSize
+	}
+	if limit > maxActivityPageSize {
+		limit = maxActivityPageSize
+	}
+
+	// Fetch one extra event to know whether another page follows
+	filter.Limit = limit + 1
+	events, err := s.activityRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &ActivityPage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		page.NextCursor = strconv.FormatUint(uint64(page.Events[limit-1].ID), 10)
+	}
+	return page, nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAddOnNotFound        = errors.New("add-on not found")
+	ErrAddOnArchived        = errors.New("add-on is no longer available")
+	ErrInvalidAddOnQuantity = errors.New("add-on quantity cannot be negative")
+)
+
+// AddOnInput holds the editable fields of an add-on. Price is a decimal in Currency.
+type AddOnInput struct {
+	Name        string
+	Description string
+	Price       string
+	Currency    string
+}
+
+type AddOnService struct {
+	addOnRepo        repositories.AddOnRepository
+	subscriptionRepo repositories.SubscriptionRepository
+	userRepo         repositories.UserRepository
+	access           accessChecker
+	now              func() time.Time
+}
+
+func NewAddOnService(
+	addOnRepo repositories.AddOnRepository,
+	subscriptionRepo repositories.SubscriptionRepository,
+	userRepo repositories.UserRepository,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *AddOnService {
+	return &AddOnService{
+		addOnRepo:        addOnRepo,
+		subscriptionRepo: subscriptionRepo,
+		userRepo:         userRepo,
+		access:           accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+		now:              time.Now,
+	}
+}
+
+// ListAddOns lists the add-ons on offer. Platform admins can include archived ones.
+func (s *AddOnService) ListAddOns(ctx context.Context, userID uint, includeArchived bool) ([]models.AddOn, error) {
+	if includeArchived {
+		if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+			return nil, err
+		}
+	}
+	return s.addOnRepo.List(ctx, includeArchived)
+}
+
+func (s *AddOnService) CreateAddOn(ctx context.Context, userID uint, input AddOnInput) (*models.AddOn, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+
+	addOn := &models.AddOn{}
+	if err := applyAddOnInput(addOn, input); err != nil {
+		return nil, err
+	}
+	if err := s.addOnRepo.Create(ctx, addOn); err != nil {
+		return nil, err
+	}
+	return addOn, nil
+}
+
+// UpdateAddOn changes an add-on. Price changes apply from the next invoice.
+func (s *AddOnService) UpdateAddOn(ctx context.Context, userID, addOnID uint, input AddOnInput) (*models.AddOn, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	addOn, err := s.loadAddOn(ctx, addOnID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyAddOnInput(addOn, input); err != nil {
+		return nil, err
+	}
+	if err := s.addOnRepo.Update(ctx, addOn); err != nil {
+		return nil, err
+	}
+	return addOn, nil
+}
+
+// ArchiveAddOn withdraws an add-on from the catalog. Organizations that have
+// it keep it until they remove it.
+func (s *AddOnService) ArchiveAddOn(ctx context.Context, userID, addOnID uint) (*models.AddOn, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	addOn, err := s.loadAddOn(ctx, addOnID)
+	if err != nil {
+		return nil, err
+	}
+
+	if addOn.ArchivedAt == nil {
+		now := s.now()
+		addOn.ArchivedAt = &now
+		if err := s.addOnRepo.Update(ctx, addOn); err != nil {
+			return nil, err
+		}
+	}
+	return addOn, nil
+}
+
+// ListOrganizationAddOns lists the add-ons the organization buys
+func (s *AddOnService) ListOrganizationAddOns(ctx context.Context, userID, orgID uint) ([]models.OrganizationAddOn, error) {
+	if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.addOnRepo.ListByOrganization(ctx, orgID)
+}
+
+// SetOrganizationAddOn sets how many units of an add-on the organization
+// buys; zero removes it. The add-on must be priced in the currency of the
+// organization's plan. Changes are billed from the next period.
+func (s *AddOnService) SetOrganizationAddOn(ctx context.Context, userID, orgID, addOnID uint, quantity int) ([]models.OrganizationAddOn, error) {
+	if err := s.access.admin(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	if quantity < 0 {
+		return nil, ErrInvalidAddOnQuantity
+	}
+	addOn, err := s.loadAddOn(ctx, addOnID)
+	if err != nil {
+		return nil, err
+	}
+
+	if quantity > 0 {
+		if addOn.IsArchived() {
+			return nil, ErrAddOnArchived
+		}
+		current, err := s.subscriptionRepo.FindCurrent(ctx, orgID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrNoSubscription
+			}
+			return nil, err
+		}
+		if current.Status != models.SubscriptionStatusActive {
+			return nil, ErrSubscriptionNotActive
+		}
+		if !current.Plan.Price.SameCurrency(addOn.Price) {
+			return nil, money.ErrCurrencyMismatch
+		}
+	}
+
+	if err := s.addOnRepo.SetQuantity(ctx, orgID, addOn.ID, quantity); err != nil {
+		return nil, err
+	}
+	return s.addOnRepo.ListByOrganization(ctx, orgID)
+}
+
+func (s *AddOnService) loadAddOn(ctx context.Context, addOnID uint) (*models.AddOn, error) {
+	addOn, err := s.addOnRepo.FindByID(ctx, addOnID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAddOnNotFound
+		}
+		return nil, err
+	}
+	return addOn, nil
+}
+
+// applyAddOnInput sets the editable fields of an add-on
+func applyAddOnInput(addOn *models.AddOn, input AddOnInput) error {
+	price, err := parseAmount(input.Price, input.Currency, "")
+	if err != nil {
+		return err
+	}
+
+	addOn.Name = strings.TrimSpace(input.Name)
+	addOn.Description = input.Description
+	addOn.Price = price
+	return addOn.Validate()
+}
@@ -0,0 +1,467 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"github.com/0-jagadeesh-0/chorvo/internal/storage"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	ErrCommentNotFound    = errors.New("comment not found")
+	ErrFileTooLarge       = errors.New("file is larger than the maximum upload size")
+	ErrInfectedFile       = errors.New("file was rejected by the virus scanner")
+	ErrUploadNotPending   = errors.New("attachment upload has already been completed")
+	ErrUploadMissing      = errors.New("the file has not been uploaded yet")
+	ErrAttachmentNotReady = errors.New("attachment is not available for download")
+)
+
+// sniffLength is the number of bytes content types are detected from
+const sniffLength = 512
+
+// AttachmentLimits configures attachment uploads and presigned URLs
+type AttachmentLimits struct {
+	MaxFileSize    int64 // in bytes
+	UploadExpiry   time.Duration
+	DownloadExpiry time.Duration
+}
+
+// AttachmentTarget is the task, comment or expense an attachment belongs to. Exactly one is set.
+type AttachmentTarget struct {
+	TaskID    *uint
+	CommentID *uint
+	ExpenseID *uint
+}
+
+// AttachmentUpload is a pending attachment with the URL its content must be PUT to
+type AttachmentUpload struct {
+	Attachment *models.Attachment `json:"attachment"`
+	UploadURL  string             `json:"upload_url"`
+	ExpiresAt  time.Time          `json:"expires_at"`
+}
+
+type AttachmentService struct {
+	attachmentRepo repositories.AttachmentRepository
+	taskRepo       repositories.TaskRepository
+	commentRepo    repositories.CommentRepository
+	expenseRepo    repositories.ExpenseRepository
+	orgRepo        repositories.OrganizationRepository
+	store          storage.Storage
+	scanner        storage.Scanner
+	limits         AttachmentLimits
+	access         accessChecker
+}
+
+func NewAttachmentService(
+	attachmentRepo repositories.AttachmentRepository,
+	taskRepo repositories.TaskRepository,
+	commentRepo repositories.CommentRepository,
+	expenseRepo repositories.ExpenseRepository,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+	store storage.Storage,
+	scanner storage.Scanner,
+	limits AttachmentLimits,
+) *AttachmentService {
+	if scanner == nil {
+		scanner = storage.NoopScanner{}
+	}
+	return &AttachmentService{
+		attachmentRepo: attachmentRepo,
+		taskRepo:       taskRepo,
+		commentRepo:    commentRepo,
+		expenseRepo:    expenseRepo,
+		orgRepo:        orgRepo,
+		store:          store,
+		scanner:        scanner,
+		limits:         limits,
+		access:         accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+	}
+}
+
+// Upload stores a file sent through the API. The file is spooled to disk so
+// it can be checksummed, sniffed and scanned before it reaches storage.
+func (s *AttachmentService) Upload(ctx context.Context, userID uint, target AttachmentTarget, fileName string, size int64, r io.Reader) (*models.Attachment, error) {
+	if size > s.limits.MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	attachment, err := s.reserve(ctx, userID, target, fileName, size)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ingest(ctx, attachment, r); err != nil {
+		s.discard(ctx, attachment)
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// PresignUpload reserves space for a file and returns a URL the client
+// uploads it to directly. CompleteUpload must be called afterwards.
+func (s *AttachmentService) PresignUpload(ctx context.Context, userID uint, target AttachmentTarget, fileName string, size int64, contentType string) (*AttachmentUpload, error) {
+	if size > s.limits.MaxFileSize {
+		return nil, ErrFileTooLarge
+	}
+
+	attachment, err := s.reserve(ctx, userID, target, fileName, size)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := s.store.PresignPut(ctx, attachment.StorageKey, contentType, size, s.limits.UploadExpiry)
+	if err != nil {
+		s.discard(ctx, attachment)
+		return nil, err
+	}
+
+	return &AttachmentUpload{
+		Attachment: attachment,
+		UploadURL:  url,
+		ExpiresAt:  time.Now().Add(s.limits.UploadExpiry),
+	}, nil
+}
+
+// CompleteUpload verifies, scans and publishes a file uploaded to a presigned URL
+func (s *AttachmentService) CompleteUpload(ctx context.Context, userID, attachmentID uint) (*models.Attachment, error) {
+	attachment, err := s.loadAttachment(ctx, userID, attachmentID)
+	if err != nil {
+		return nil, err
+	}
+	if attachment.UploadedByID != userID {
+		return nil, ErrForbidden
+	}
+	if attachment.Status != models.AttachmentStatusPending {
+		return nil, ErrUploadNotPending
+	}
+
+	object, err := s.store.Get(ctx, attachment.StorageKey)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			return nil, ErrUploadMissing
+		}
+		return nil, err
+	}
+	defer object.Close()
+
+	// The presigned URL stays valid until it expires, so the scanned content
+	// is published under a new key the client cannot write to
+	uploadKey := attachment.StorageKey
+	if attachment.StorageKey, err = storageKey(attachment.OrganizationID, attachment.FileName); err != nil {
+		return nil, err
+	}
+
+	err = s.ingest(ctx, attachment, object)
+	switch {
+	case err == nil || errors.Is(err, ErrInfectedFile):
+		s.store.Delete(ctx, uploadKey)
+	case errors.Is(err, ErrFileTooLarge) || errors.Is(err, models.ErrUsageLimitReached):
+		s.store.Delete(ctx, uploadKey)
+		s.discard(ctx, attachment)
+	default:
+		// Keep the upload so that completing it can be retried
+		s.store.Delete(ctx, attachment.StorageKey)
+		attachment.StorageKey = uploadKey
+	}
+	if err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// ListAttachments lists the attachments of a task, comment or expense
+func (s *AttachmentService) ListAttachments(ctx context.Context, userID uint, target AttachmentTarget) ([]models.Attachment, error) {
+	if _, err := s.resolveTarget(ctx, userID, target); err != nil {
+		return nil, err
+	}
+	switch {
+	case target.TaskID != nil:
+		return s.attachmentRepo.ListByTask(ctx, *target.TaskID)
+	case target.ExpenseID != nil:
+		return s.attachmentRepo.ListByExpense(ctx, *target.ExpenseID)
+	}
+	return s.attachmentRepo.ListByComment(ctx, *target.CommentID)
+}
+
+func (s *AttachmentService) GetAttachment(ctx context.Context, userID, attachmentID uint) (*models.Attachment, error) {
+	return s.loadAttachment(ctx, userID, attachmentID)
+}
+
+// DownloadURL returns a short-lived URL to download an available attachment from
+func (s *AttachmentService) DownloadURL(ctx context.Context, userID, attachmentID uint) (string, error) {
+	attachment, err := s.loadAttachment(ctx, userID, attachmentID)
+	if err != nil {
+		return "", err
+	}
+	if !attachment.IsAvailable() {
+		return "", ErrAttachmentNotReady
+	}
+	return s.store.PresignGet(ctx, attachment.StorageKey, attachment.FileName, s.limits.DownloadExpiry)
+}
+
+// DeleteAttachment deletes an attachment and its content. Only the uploader
+// and organization admins may delete attachments.
+func (s *AttachmentService) DeleteAttachment(ctx context.Context, userID, attachmentID uint) error {
+	attachment, err := s.loadAttachment(ctx, userID, attachmentID)
+	if err != nil {
+		return err
+	}
+	if attachment.UploadedByID != userID {
+		isAdmin, err := s.access.isOrganizationAdmin(ctx, attachment.OrganizationID, userID)
+		if err != nil {
+			return err
+		}
+		if !isAdmin {
+			return ErrForbidden
+		}
+	}
+
+	if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+		return err
+	}
+	return s.attachmentRepo.Delete(ctx, attachment)
+}
+
+// reserve creates a pending attachment, claiming its size against the
+// organization's storage limit
+func (s *AttachmentService) reserve(ctx context.Context, userID uint, target AttachmentTarget, fileName string, size int64) (*models.Attachment, error) {
+	orgID, err := s.resolveTarget(ctx, userID, target)
+	if err != nil {
+		return nil, err
+	}
+
+	fileName = path.Base(strings.ReplaceAll(strings.TrimSpace(fileName), "\\", "/"))
+	if fileName == "." || fileName == "/" {
+		fileName = ""
+	}
+
+	key, err := storageKey(orgID, fileName)
+	if err != nil {
+		return nil, err
+	}
+
+	attachment := &models.Attachment{
+		OrganizationID: orgID,
+		TaskID:         target.TaskID,
+		CommentID:      target.CommentID,
+		ExpenseID:      target.ExpenseID,
+		UploadedByID:   userID,
+		FileName:       fileName,
+		Size:           size,
+		StorageKey:     key,
+		Status:         models.AttachmentStatusPending,
+	}
+	if err := s.saveWithinLimit(ctx, attachment); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// ingest checksums, sniffs and scans content, stores it under the
+// attachment's key and marks the attachment available or quarantined
+func (s *AttachmentService) ingest(ctx context.Context, attachment *models.Attachment, r io.Reader) error {
+	spool, err := os.CreateTemp("", "attachment-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(spool.Name())
+	defer spool.Close()
+
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(spool, hash), io.LimitReader(r, s.limits.MaxFileSize+1))
+	if err != nil {
+		return err
+	}
+	if size > s.limits.MaxFileSize {
+		return ErrFileTooLarge
+	}
+	if size == 0 {
+		return models.ErrInvalidFileSize
+	}
+
+	head := make([]byte, sniffLength)
+	n, err := spool.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	result, err := s.scanner.Scan(ctx, spool)
+	if err != nil {
+		return fmt.Errorf("scanning attachment: %w", err)
+	}
+
+	now := time.Now()
+	attachment.Size = size
+	attachment.Checksum = hex.EncodeToString(hash.Sum(nil))
+	attachment.ContentType = http.DetectContentType(head[:n])
+	attachment.ScannedAt = &now
+
+	if !result.Clean {
+		attachment.Status = models.AttachmentStatusQuarantined
+		attachment.ScanResult = result.Signature
+		if err := s.store.Delete(ctx, attachment.StorageKey); err != nil {
+			return err
+		}
+		if err := s.saveWithinLimit(ctx, attachment); err != nil {
+			return err
+		}
+		return ErrInfectedFile
+	}
+
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := s.store.Put(ctx, attachment.StorageKey, spool, size, attachment.ContentType); err != nil {
+		return err
+	}
+
+	attachment.Status = models.AttachmentStatusAvailable
+	attachment.ScanResult = "clean"
+	return s.saveWithinLimit(ctx, attachment)
+}
+
+// saveWithinLimit saves an attachment unless it would take the organization
+// over its storage limit, which is reported as a LimitError
+func (s *AttachmentService) saveWithinLimit(ctx context.Context, attachment *models.Attachment) error {
+	org, err := s.orgRepo.FindByID(ctx, attachment.OrganizationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOrganizationNotFound
+		}
+		return err
+	}
+
+	saved, err := s.attachmentRepo.SaveWithinLimit(ctx, attachment, int64(org.StorageLimit)*models.BytesPerGB)
+	if err != nil || saved {
+		return err
+	}
+	used, err := s.attachmentRepo.StorageUsed(ctx, attachment.OrganizationID)
+	if err != nil {
+		return err
+	}
+	return &models.LimitError{
+		Limit: models.UsageLimitStorage,
+		Max:   float64(org.StorageLimit),
+		Used:  float64(used) / models.BytesPerGB,
+	}
+}
+
+// discard removes an attachment that could not be completed
+func (s *AttachmentService) discard(ctx context.Context, attachment *models.Attachment) {
+	if attachment.Status == models.AttachmentStatusQuarantined {
+		return
+	}
+	s.store.Delete(ctx, attachment.StorageKey)
+	if attachment.ID != 0 {
+		s.attachmentRepo.Delete(ctx, attachment)
+	}
+}
+
+// resolveTarget checks the user's access to the task, comment or expense and
+// returns its organization
+func (s *AttachmentService) resolveTarget(ctx context.Context, userID uint, target AttachmentTarget) (uint, error) {
+	owners := 0
+	for _, id := range []*uint{target.TaskID, target.CommentID, target.ExpenseID} {
+		if id != nil {
+			owners++
+		}
+	}
+	if owners != 1 {
+		return 0, models.ErrMissingAttachmentOwner
+	}
+
+	var projectID uint
+	switch {
+	case target.ExpenseID != nil:
+		expense, err := s.expenseRepo.FindByID(ctx, *target.ExpenseID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, ErrExpenseNotFound
+			}
+			return 0, err
+		}
+		projectID = expense.ProjectID
+	default:
+		taskID := target.TaskID
+		if target.CommentID != nil {
+			comment, err := s.commentRepo.FindByID(ctx, *target.CommentID)
+			if err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return 0, ErrCommentNotFound
+				}
+				return 0, err
+			}
+			taskID = &comment.TaskID
+		}
+
+		task, err := s.taskRepo.FindByID(ctx, *taskID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return 0, ErrTaskNotFound
+			}
+			return 0, err
+		}
+		projectID = task.ProjectID
+	}
+
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return 0, err
+	}
+	return project.OrganizationID, nil
+}
+
+// loadAttachment loads an attachment and checks the user's access to it
+func (s *AttachmentService) loadAttachment(ctx context.Context, userID, attachmentID uint) (*models.Attachment, error) {
+	attachment, err := s.attachmentRepo.FindByID(ctx, attachmentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, err
+	}
+	target := AttachmentTarget{TaskID: attachment.TaskID, CommentID: attachment.CommentID, ExpenseID: attachment.ExpenseID}
+	if _, err := s.resolveTarget(ctx, userID, target); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// storageKey builds a unique, unguessable key for a file of an organization
+func storageKey(orgID uint, fileName string) (string, error) {
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", err
+	}
+
+	safe := strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '.' || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, fileName)
+	safe = strings.TrimLeft(safe, ".")
+	if safe == "" {
+		safe = "file"
+	}
+
+	return fmt.Sprintf("orgs/%d/attachments/%s/%s", orgID, hex.EncodeToString(random), safe), nil
+}
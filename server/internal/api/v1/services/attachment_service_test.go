@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// memOrganizationRepo finds organizations from a map
+type memOrganizationRepo struct {
+	repositories.OrganizationRepository
+	orgs map[uint]*models.Organization
+}
+
+func (r memOrganizationRepo) FindByID(ctx context.Context, id uint) (*models.Organization, error) {
+	if org, ok := r.orgs[id]; ok {
+		return org, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// memStorageRepo holds an organization's stored bytes and saves attachments
+// that fit under the limit
+type memStorageRepo struct {
+	repositories.AttachmentRepository
+	used int64
+}
+
+func (r *memStorageRepo) SaveWithinLimit(ctx context.Context, attachment *models.Attachment, limitBytes int64) (bool, error) {
+	if r.used+attachment.Size > limitBytes {
+		return false, nil
+	}
+	r.used += attachment.Size
+	return true, nil
+}
+
+func (r *memStorageRepo) StorageUsed(ctx context.Context, orgID uint) (int64, error) {
+	return r.used, nil
+}
+
+func TestSaveWithinStorageLimit(t *testing.T) {
+	orgs := map[uint]*models.Organization{1: {StorageLimit: 2}}
+
+	tests := []struct {
+		name  string
+		orgID uint
+		used  int64
+		size  int64
+		err   error
+		limit *models.LimitError
+	}{
+		{"fits", 1, models.BytesPerGB, models.BytesPerGB / 2, nil, nil},
+		{"fills the limit", 1, models.BytesPerGB, models.BytesPerGB, nil, nil},
+		{"over the limit", 1, models.BytesPerGB * 3 / 2, models.BytesPerGB, models.ErrUsageLimitReached,
+			&models.LimitError{Limit: models.UsageLimitStorage, Max: 2, Used: 1.5}},
+		{"unknown organization", 2, 0, 1, ErrOrganizationNotFound, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &AttachmentService{
+				attachmentRepo: &memStorageRepo{used: tt.used},
+				orgRepo:        memOrganizationRepo{orgs: orgs},
+			}
+			attachment := &models.Attachment{OrganizationID: tt.orgID, Size: tt.size}
+			err := service.saveWithinLimit(context.Background(), attachment)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("saveWithinLimit() error = %v, want %v", err, tt.err)
+			}
+			var limit *models.LimitError
+			errors.As(err, &limit)
+			if !reflect.DeepEqual(limit, tt.limit) {
+				t.Errorf("limit error = %+v, want %+v", limit, tt.limit)
+			}
+		})
+	}
+}
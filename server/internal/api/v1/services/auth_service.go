@@ -3,21 +3,25 @@ package services
 import (
 	"context"
 	"errors"
+	"net/url"
 	"time"
 
 	"github.com/0-jagadeesh-0/chorvo/internal/api/v1/utils"
 	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/mail"
 	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthService struct {
-	userRepo repositories.UserRepository
+	userRepo    repositories.UserRepository
+	mailService *MailService
 }
 
-func NewAuthService(userRepo repositories.UserRepository) *AuthService {
+func NewAuthService(userRepo repositories.UserRepository, mailService *MailService) *AuthService {
 	return &AuthService{
-		userRepo: userRepo,
+		userRepo:    userRepo,
+		mailService: mailService,
 	}
 }
 
@@ -62,16 +66,17 @@ func (s *AuthService) Register(email, password, firstName, lastName string) (*mo
 		CodeExpiresAt:    time.Now().Add(10 * time.Minute),
 	}
 
-	// Save user to database
-	if err := s.userRepo.Create(ctx, user); err != nil {
+	// Save user to database along with their verification email, which the
+	// outbox sends and retries in the background
+	verificationEmail, err := s.mailService.Render(user.Email, user.Language, mail.TemplateVerification, mail.VerificationData{
+		Name: user.FirstName,
+		Code: verificationCode,
+	})
+	if err != nil {
 		return nil, err
 	}
-
-	// Send verification email
-	if err := utils.SendVerificationEmail(user.Email, verificationCode); err != nil {
-		// Log the error but don't fail the registration
-		// In a production environment, you might want to implement retry logic
-		return user, nil
+	if err := s.userRepo.CreateWithEmail(ctx, user, verificationEmail); err != nil {
+		return nil, err
 	}
 
 	return user, nil
@@ -134,8 +139,11 @@ func (s *AuthService) ResendVerificationCode(email string) error {
 		return err
 	}
 
-	// Send new verification email
-	return utils.SendVerificationEmail(user.Email, verificationCode)
+	// Queue new verification email
+	return s.mailService.Enqueue(ctx, user.Email, user.Language, mail.TemplateVerification, mail.VerificationData{
+		Name: user.FirstName,
+		Code: verificationCode,
+	})
 }
 
 func (s *AuthService) Login(email, password string) (string, error) {
@@ -191,8 +199,11 @@ func (s *AuthService) RequestPasswordReset(email string) error {
 		return err
 	}
 
-	// Send reset email
-	return utils.SendPasswordResetEmail(user.Email, token)
+	// Queue reset email
+	return s.mailService.Enqueue(ctx, user.Email, user.Language, mail.TemplatePasswordReset, mail.PasswordResetData{
+		Name: user.FirstName,
+		Link: s.mailService.Link("/reset-password?token=" + url.QueryEscape(token)),
+	})
 }
 
 func (s *AuthService) ResetPassword(token, newPassword string) error {
@@ -0,0 +1,421 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrBudgetLineNotFound    = errors.New("budget line not found")
+	ErrDuplicateBudgetLine   = errors.New("the project already has a budget line for this category")
+	ErrNotOrganizationMember = errors.New("user is not a member of this organization")
+)
+
+// budgetAlertThresholds are the percentages of a budget that raise an alert
+// the first time a project's spending reaches them
+var budgetAlertThresholds = []int{50, 75, 90, 100}
+
+// BudgetLineInput holds the editable fields of a budget line. Amount is a
+// decimal in Currency, which defaults to the currency of the project's budget.
+type BudgetLineInput struct {
+	Category    string
+	Description string
+	Amount      string
+	Currency    string
+}
+
+// HourlyRateInput sets a member's rate from a date on. Rate is a decimal in Currency.
+type HourlyRateInput struct {
+	UserID        uint
+	Rate          string
+	Currency      string
+	EffectiveFrom time.Time
+}
+
+// BudgetCategoryReport compares the budget of one category with its spending
+type BudgetCategoryReport struct {
+	Category    string      `json:"category"`
+	Budget      money.Money `json:"budget"`
+	Spent       money.Money `json:"spent"`
+	Pending     money.Money `json:"pending"`
+	Remaining   money.Money `json:"remaining"`
+	PercentUsed float64     `json:"percent_used"`
+}
+
+// BudgetReport compares a project's budget with its actual spending and
+// forecasts the cost at completion. Spent counts approved expenses and labor
+// on approved timesheets; pending counts what still awaits approval.
+type BudgetReport struct {
+	ProjectID       uint                   `json:"project_id"`
+	Budget          money.Money            `json:"budget"`
+	Spent           money.Money            `json:"spent"`
+	Pending         money.Money            `json:"pending"`
+	Remaining       money.Money            `json:"remaining"`
+	PercentUsed     float64                `json:"percent_used"`
+	PercentComplete float64                `json:"percent_complete"`
+	Forecast        *money.Money           `json:"forecast_at_completion"`
+	Variance        *money.Money           `json:"variance_at_completion"` // budget minus forecast
+	Labor           repositories.LaborCost `json:"labor"`
+	Categories      []BudgetCategoryReport `json:"categories"`
+	Alerts          []models.BudgetAlert   `json:"alerts"`
+}
+
+type BudgetService struct {
+	budgetRepo  repositories.BudgetRepository
+	projectRepo repositories.ProjectRepository
+	access      accessChecker
+}
+
+func NewBudgetService(
+	budgetRepo repositories.BudgetRepository,
+	entitlements *EntitlementService,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *BudgetService {
+	return &BudgetService{
+		budgetRepo:  budgetRepo,
+		projectRepo: projectRepo,
+		access:      accessChecker{orgRepo: orgRepo, projectRepo: projectRepo, feature: featureGate{entitlements, models.FeatureBudgets}},
+	}
+}
+
+func (s *BudgetService) ListLines(ctx context.Context, userID, projectID uint) ([]models.BudgetLine, error) {
+	project, err := s.managedProject(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.budgetRepo.ListLines(ctx, project.ID)
+}
+
+// CreateLine adds a category to a project's budget
+func (s *BudgetService) CreateLine(ctx context.Context, userID, projectID uint, input BudgetLineInput) (*models.BudgetLine, error) {
+	project, err := s.managedProject(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := parseAmount(input.Amount, input.Currency, project.Budget.Currency)
+	if err != nil {
+		return nil, err
+	}
+	line := &models.BudgetLine{
+		ProjectID:   project.ID,
+		Category:    input.Category,
+		Description: input.Description,
+		Amount:      amount,
+	}
+	if err := s.saveLine(ctx, line, s.budgetRepo.CreateLine); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func (s *BudgetService) UpdateLine(ctx context.Context, userID, lineID uint, input BudgetLineInput) (*models.BudgetLine, error) {
+	line, err := s.loadLine(ctx, userID, lineID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := parseAmount(input.Amount, input.Currency, line.Amount.Currency)
+	if err != nil {
+		return nil, err
+	}
+	line.Category = input.Category
+	line.Description = input.Description
+	line.Amount = amount
+	if err := s.saveLine(ctx, line, s.budgetRepo.UpdateLine); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func (s *BudgetService) DeleteLine(ctx context.Context, userID, lineID uint) error {
+	line, err := s.loadLine(ctx, userID, lineID)
+	if err != nil {
+		return err
+	}
+	return s.budgetRepo.DeleteLine(ctx, line)
+}
+
+// SetHourlyRate sets the cost of a member's hour from a date on. Only
+// organization admins may see and change rates.
+func (s *BudgetService) SetHourlyRate(ctx context.Context, userID, orgID uint, input HourlyRateInput) (*models.HourlyRate, error) {
+	if err := s.access.admin(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	isMember, err := s.access.orgRepo.IsMember(ctx, orgID, input.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !isMember {
+		return nil, ErrNotOrganizationMember
+	}
+
+	amount, err := parseAmount(input.Rate, input.Currency, "")
+	if err != nil {
+		return nil, err
+	}
+	effectiveFrom := input.EffectiveFrom.UTC()
+	rate := &models.HourlyRate{
+		OrganizationID: orgID,
+		UserID:         input.UserID,
+		Rate:           amount,
+		EffectiveFrom:  time.Date(effectiveFrom.Year(), effectiveFrom.Month(), effectiveFrom.Day(), 0, 0, 0, 0, time.UTC),
+	}
+	if err := s.budgetRepo.SaveRate(ctx, rate); err != nil {
+		return nil, err
+	}
+	return rate, nil
+}
+
+func (s *BudgetService) ListHourlyRates(ctx context.Context, userID, orgID uint) ([]models.HourlyRate, error) {
+	if err := s.access.admin(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.budgetRepo.ListRates(ctx, orgID)
+}
+
+// Report compares a project's budget with its spending, by category
+func (s *BudgetService) Report(ctx context.Context, userID, projectID uint) (*BudgetReport, error) {
+	project, err := s.managedProject(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.report(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.raiseAlerts(ctx, report); err != nil {
+		return nil, err
+	}
+	report.Alerts, err = s.budgetRepo.ListAlerts(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *BudgetService) ListAlerts(ctx context.Context, userID, projectID uint) ([]models.BudgetAlert, error) {
+	project, err := s.managedProject(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return s.budgetRepo.ListAlerts(ctx, project.ID)
+}
+
+// CheckThresholds raises the alerts for any thresholds a project's spending
+// has reached. It is called whenever spending is approved.
+func (s *BudgetService) CheckThresholds(ctx context.Context, projectID uint) error {
+	project, err := s.projectRepo.FindByID(ctx, projectID)
+	if err != nil {
+		return err
+	}
+	report, err := s.report(ctx, project)
+	if err != nil {
+		return err
+	}
+	return s.raiseAlerts(ctx, report)
+}
+
+func (s *BudgetService) report(ctx context.Context, project *models.Project) (*BudgetReport, error) {
+	currency := project.Budget.Currency
+	lines, err := s.budgetRepo.ListLines(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	labor, err := s.budgetRepo.LaborCost(ctx, project.ID, currency)
+	if err != nil {
+		return nil, err
+	}
+	expenses, err := s.budgetRepo.ExpenseTotals(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	progress, err := s.budgetRepo.Progress(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make(map[string]*BudgetCategoryReport)
+	category := func(name string) *BudgetCategoryReport {
+		if categories[name] == nil {
+			categories[name] = &BudgetCategoryReport{
+				Category: name,
+				Budget:   money.Zero(currency),
+				Spent:    money.Zero(currency),
+				Pending:  money.Zero(currency),
+			}
+		}
+		return categories[name]
+	}
+	add := func(total *money.Money, amount money.Money) {
+		if err == nil {
+			*total, err = total.Add(amount)
+		}
+	}
+
+	for _, line := range lines {
+		add(&category(line.Category).Budget, line.Amount)
+	}
+	if labor.ApprovedCost.IsPositive() || labor.PendingCost.IsPositive() {
+		add(&category(models.BudgetCategoryLabor).Spent, labor.ApprovedCost)
+		add(&category(models.BudgetCategoryLabor).Pending, labor.PendingCost)
+	}
+	for _, total := range expenses {
+		switch total.Status {
+		case models.ExpenseStatusApproved:
+			add(&category(total.Category).Spent, total.Amount)
+		case models.ExpenseStatusPending:
+			add(&category(total.Category).Pending, total.Amount)
+		}
+	}
+
+	report := &BudgetReport{
+		ProjectID: project.ID,
+		Budget:    project.Budget,
+		Spent:     money.Zero(currency),
+		Pending:   money.Zero(currency),
+		Labor:     *labor,
+	}
+	for _, c := range categories {
+		add(&report.Spent, c.Spent)
+		add(&report.Pending, c.Pending)
+		c.Remaining, _ = c.Budget.Sub(c.Spent)
+		c.PercentUsed = percentOf(c.Spent.Minor, c.Budget.Minor)
+		report.Categories = append(report.Categories, *c)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(report.Categories, func(i, j int) bool {
+		return report.Categories[i].Category < report.Categories[j].Category
+	})
+
+	if report.Remaining, err = report.Budget.Sub(report.Spent); err != nil {
+		return nil, err
+	}
+	report.PercentUsed = percentOf(report.Spent.Minor, report.Budget.Minor)
+
+	// Progress is measured in estimated hours, to the hundredth, when tasks are
+	// estimated, and in tasks otherwise. The forecast assumes the remaining
+	// work costs what the finished work did.
+	var done, total int64
+	switch {
+	case progress.TotalEstimate > 0:
+		done, total = int64(math.Round(progress.DoneEstimate*100)), int64(math.Round(progress.TotalEstimate*100))
+	case progress.TotalTasks > 0:
+		done, total = progress.DoneTasks, progress.TotalTasks
+	}
+	report.PercentComplete = percentOf(done, total)
+	if done > 0 {
+		forecast, err := report.Spent.MulRat(total, done)
+		if err != nil {
+			return nil, err
+		}
+		variance, err := report.Budget.Sub(forecast)
+		if err != nil {
+			return nil, err
+		}
+		report.Forecast = &forecast
+		report.Variance = &variance
+	}
+	return report, nil
+}
+
+// raiseAlerts stores an alert for each threshold the report's spending has reached
+func (s *BudgetService) raiseAlerts(ctx context.Context, report *BudgetReport) error {
+	if !report.Budget.IsPositive() {
+		return nil
+	}
+	for _, threshold := range budgetAlertThresholds {
+		if report.PercentUsed < float64(threshold) {
+			break
+		}
+		alert := &models.BudgetAlert{
+			ProjectID: report.ProjectID,
+			Threshold: threshold,
+			Budget:    report.Budget,
+			Spent:     report.Spent,
+		}
+		if _, err := s.budgetRepo.CreateAlert(ctx, alert); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// saveLine saves a line unless another line of the project has its category.
+// All lines share one currency, which becomes the currency of the project's budget.
+func (s *BudgetService) saveLine(ctx context.Context, line *models.BudgetLine, save func(context.Context, *models.BudgetLine) error) error {
+	lines, err := s.budgetRepo.ListLines(ctx, line.ProjectID)
+	if err != nil {
+		return err
+	}
+	for _, other := range lines {
+		if other.ID == line.ID {
+			continue
+		}
+		if other.Category == models.NormalizeBudgetCategory(line.Category) {
+			return ErrDuplicateBudgetLine
+		}
+		if !other.Amount.SameCurrency(line.Amount) {
+			return money.ErrCurrencyMismatch
+		}
+	}
+	return save(ctx, line)
+}
+
+func (s *BudgetService) loadLine(ctx context.Context, userID, lineID uint) (*models.BudgetLine, error) {
+	line, err := s.budgetRepo.FindLine(ctx, lineID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrBudgetLineNotFound
+		}
+		return nil, err
+	}
+	if _, err := s.managedProject(ctx, userID, line.ProjectID); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+// managedProject loads a project whose budget the user manages
+func (s *BudgetService) managedProject(ctx context.Context, userID, projectID uint) (*models.Project, error) {
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.manager(ctx, project.OrganizationID, userID, []uint{project.ID}); err != nil {
+		return nil, err
+	}
+	return project, nil
+}
+
+// parseAmount reads a decimal amount in currency, falling back to the given
+// currency and then the default one when no currency is set
+func parseAmount(amount, currency, fallback string) (money.Money, error) {
+	if currency == "" {
+		currency = fallback
+	}
+	if currency == "" {
+		currency = money.DefaultCurrency
+	}
+	return money.Parse(amount, currency)
+}
+
+// percentOf returns part as a percentage of whole, rounded to two decimals
+func percentOf(part, whole int64) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return math.Round(float64(part)/float64(whole)*10000) / 100
+}
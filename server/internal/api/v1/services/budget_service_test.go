@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+)
+
+// memBudgetRepo reports fixed lines, costs and progress for every project and
+// records the thresholds it is asked to alert
+type memBudgetRepo struct {
+	repositories.BudgetRepository
+	lines    []models.BudgetLine
+	labor    repositories.LaborCost
+	expenses []repositories.ExpenseTotal
+	progress repositories.ProjectProgress
+	alerts   *[]int
+}
+
+func (r memBudgetRepo) ListLines(ctx context.Context, projectID uint) ([]models.BudgetLine, error) {
+	return r.lines, nil
+}
+
+func (r memBudgetRepo) LaborCost(ctx context.Context, projectID uint, currency string) (*repositories.LaborCost, error) {
+	labor := r.labor
+	return &labor, nil
+}
+
+func (r memBudgetRepo) ExpenseTotals(ctx context.Context, projectID uint) ([]repositories.ExpenseTotal, error) {
+	return r.expenses, nil
+}
+
+func (r memBudgetRepo) Progress(ctx context.Context, projectID uint) (*repositories.ProjectProgress, error) {
+	progress := r.progress
+	return &progress, nil
+}
+
+func (r memBudgetRepo) CreateAlert(ctx context.Context, alert *models.BudgetAlert) (bool, error) {
+	*r.alerts = append(*r.alerts, alert.Threshold)
+	return true, nil
+}
+
+// memProjectRepo finds a single project
+type memProjectRepo struct {
+	repositories.ProjectRepository
+	project *models.Project
+}
+
+func (r memProjectRepo) FindByID(ctx context.Context, id uint) (*models.Project, error) {
+	return r.project, nil
+}
+
+func usd(minor int64) money.Money {
+	return money.New(minor, "USD")
+}
+
+func moneyPtr(amount money.Money) *money.Money {
+	return &amount
+}
+
+func TestBudgetReport(t *testing.T) {
+	project := &models.Project{Budget: usd(1000000)}
+	project.ID = 1
+	repo := memBudgetRepo{
+		lines: []models.BudgetLine{
+			{Category: "travel", Amount: usd(400000)},
+			{Category: models.BudgetCategoryLabor, Amount: usd(600000)},
+		},
+		labor: repositories.LaborCost{ApprovedCost: usd(300000), PendingCost: usd(50000)},
+		expenses: []repositories.ExpenseTotal{
+			{Category: "travel", Status: models.ExpenseStatusApproved, Amount: usd(100000)},
+			{Category: "travel", Status: models.ExpenseStatusPending, Amount: usd(20000)},
+			{Category: "travel", Status: models.ExpenseStatusRejected, Amount: usd(99999)},
+			{Category: "software", Status: models.ExpenseStatusApproved, Amount: usd(50000)},
+		},
+	}
+	categories := []BudgetCategoryReport{
+		{Category: models.BudgetCategoryLabor, Budget: usd(600000), Spent: usd(300000), Pending: usd(50000), Remaining: usd(300000), PercentUsed: 50},
+		{Category: "software", Budget: usd(0), Spent: usd(50000), Pending: usd(0), Remaining: usd(-50000)},
+		{Category: "travel", Budget: usd(400000), Spent: usd(100000), Pending: usd(20000), Remaining: usd(300000), PercentUsed: 25},
+	}
+
+	tests := []struct {
+		name     string
+		progress repositories.ProjectProgress
+		complete float64
+		forecast *money.Money
+		variance *money.Money
+	}{
+		{"by estimated hours", repositories.ProjectProgress{TotalTasks: 10, DoneTasks: 9, TotalEstimate: 40, DoneEstimate: 18}, 45, moneyPtr(usd(1000000)), moneyPtr(usd(0))},
+		{"by tasks without estimates", repositories.ProjectProgress{TotalTasks: 8, DoneTasks: 3}, 37.5, moneyPtr(usd(1200000)), moneyPtr(usd(-200000))},
+		{"nothing done yet", repositories.ProjectProgress{TotalTasks: 8, TotalEstimate: 40}, 0, nil, nil},
+		{"no tasks", repositories.ProjectProgress{}, 0, nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := repo
+			repo.progress = tt.progress
+			service := &BudgetService{budgetRepo: repo}
+			report, err := service.report(context.Background(), project)
+			if err != nil {
+				t.Fatalf("report() error = %v", err)
+			}
+			if report.Spent != usd(450000) || report.Pending != usd(70000) || report.Remaining != usd(550000) {
+				t.Errorf("spent/pending/remaining = %v/%v/%v, want 4500.00/700.00/5500.00", report.Spent, report.Pending, report.Remaining)
+			}
+			if report.PercentUsed != 45 {
+				t.Errorf("PercentUsed = %v, want 45", report.PercentUsed)
+			}
+			if !reflect.DeepEqual(report.Categories, categories) {
+				t.Errorf("Categories = %+v, want %+v", report.Categories, categories)
+			}
+			if report.PercentComplete != tt.complete {
+				t.Errorf("PercentComplete = %v, want %v", report.PercentComplete, tt.complete)
+			}
+			if !reflect.DeepEqual(report.Forecast, tt.forecast) || !reflect.DeepEqual(report.Variance, tt.variance) {
+				t.Errorf("forecast/variance = %v/%v, want %v/%v", report.Forecast, report.Variance, tt.forecast, tt.variance)
+			}
+		})
+	}
+}
+
+func TestBudgetAlertThresholds(t *testing.T) {
+	tests := []struct {
+		name   string
+		budget money.Money
+		spent  money.Money
+		alerts []int
+	}{
+		{"under half", usd(100000), usd(49990), nil},
+		{"half", usd(100000), usd(50000), []int{50}},
+		{"most", usd(100000), usd(95000), []int{50, 75, 90}},
+		{"all", usd(100000), usd(100000), []int{50, 75, 90, 100}},
+		{"over", usd(100000), usd(120000), []int{50, 75, 90, 100}},
+		{"no budget", usd(0), usd(120000), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var alerts []int
+			project := &models.Project{Budget: tt.budget}
+			service := &BudgetService{
+				budgetRepo: memBudgetRepo{
+					labor:  repositories.LaborCost{ApprovedCost: tt.spent, PendingCost: usd(0)},
+					alerts: &alerts,
+				},
+				projectRepo: memProjectRepo{project: project},
+			}
+			if err := service.CheckThresholds(context.Background(), 1); err != nil {
+				t.Fatalf("CheckThresholds() error = %v", err)
+			}
+			if !reflect.DeepEqual(alerts, tt.alerts) {
+				t.Errorf("alerted %v, want %v", alerts, tt.alerts)
+			}
+		})
+	}
+}
@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCouponNotFound        = errors.New("coupon not found")
+	ErrCouponNotRedeemable   = errors.New("coupon has expired or can no longer be redeemed")
+	ErrCouponAlreadyRedeemed = errors.New("the organization has already redeemed this coupon")
+	ErrDiscountActive        = errors.New("the organization already has a discount; remove it before redeeming another coupon")
+	ErrNoDiscount            = errors.New("the organization has no discount")
+	ErrInvalidCreditAmount   = errors.New("credit amount must be positive")
+)
+
+// CouponInput holds the fields of a new coupon. AmountOff is a decimal in
+// Currency and only used by fixed discounts.
+type CouponInput struct {
+	Code           string
+	Name           string
+	DiscountType   models.DiscountType
+	PercentOff     int
+	AmountOff      string
+	Currency       string
+	Duration       models.CouponDuration
+	DurationCycles int
+	MaxRedemptions int
+	ExpiresAt      *time.Time
+}
+
+// CreditInput holds a promotional credit granted to an organization. Amount is
+// a decimal in Currency.
+type CreditInput struct {
+	Amount      string
+	Currency    string
+	Description string
+}
+
+// CreditBalance is an organization's account credit that has not been applied
+// to an invoice yet, with the total in each currency
+type CreditBalance struct {
+	Balances []money.Money        `json:"balances"`
+	Credits  []models.InvoiceItem `json:"credits"`
+}
+
+type CouponService struct {
+	couponRepo  repositories.CouponRepository
+	invoiceRepo repositories.InvoiceRepository
+	userRepo    repositories.UserRepository
+	orgRepo     repositories.OrganizationRepository
+	access      accessChecker
+	now         func() time.Time
+}
+
+func NewCouponService(
+	couponRepo repositories.CouponRepository,
+	invoiceRepo repositories.InvoiceRepository,
+	userRepo repositories.UserRepository,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *CouponService {
+	return &CouponService{
+		couponRepo:  couponRepo,
+		invoiceRepo: invoiceRepo,
+		userRepo:    userRepo,
+		orgRepo:     orgRepo,
+		access:      accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+		now:         time.Now,
+	}
+}
+
+// ListCoupons lists the coupons. Only platform admins can see them.
+func (s *CouponService) ListCoupons(ctx context.Context, userID uint, includeArchived bool) ([]models.Coupon, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	return s.couponRepo.List(ctx, includeArchived)
+}
+
+// CreateCoupon adds a coupon. Coupons cannot be changed once created, since
+// organizations may have redeemed them; archive one and create another instead.
+func (s *CouponService) CreateCoupon(ctx context.Context, userID uint, input CouponInput) (*models.Coupon, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+
+	coupon := &models.Coupon{
+		Code:           normalizeCouponCode(input.Code),
+		Name:           strings.TrimSpace(input.Name),
+		DiscountType:   input.DiscountType,
+		PercentOff:     input.PercentOff,
+		Duration:       input.Duration,
+		DurationCycles: input.DurationCycles,
+		MaxRedemptions: input.MaxRedemptions,
+		ExpiresAt:      input.ExpiresAt,
+	}
+	if input.DiscountType == models.DiscountTypeFixed {
+		amountOff, err := parseAmount(input.AmountOff, input.Currency, "")
+		if err != nil {
+			return nil, err
+		}
+		coupon.AmountOff = amountOff
+	}
+	if err := coupon.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.couponRepo.Create(ctx, coupon); err != nil {
+		return nil, err
+	}
+	return coupon, nil
+}
+
+// ArchiveCoupon stops a coupon from being redeemed. Organizations that
+// redeemed it keep their discount until it runs out.
+func (s *CouponService) ArchiveCoupon(ctx context.Context, userID, couponID uint) (*models.Coupon, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	coupon, err := s.couponRepo.FindByID(ctx, couponID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCouponNotFound
+		}
+		return nil, err
+	}
+
+	if coupon.ArchivedAt == nil {
+		now := s.now()
+		coupon.ArchivedAt = &now
+		if err := s.couponRepo.Update(ctx, coupon); err != nil {
+			return nil, err
+		}
+	}
+	return coupon, nil
+}
+
+// GetDiscount returns the organization's running discount
+func (s *CouponService) GetDiscount(ctx context.Context, userID, orgID uint) (*models.CouponRedemption, error) {
+	if err := s.access.admin(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.activeRedemption(ctx, orgID)
+}
+
+// RedeemCoupon gives the organization a coupon's discount, starting with its
+// next invoice. An organization can redeem a coupon once, and has one
+// discount at a time.
+func (s *CouponService) RedeemCoupon(ctx context.Context, userID, orgID uint, code string) (*models.CouponRedemption, error) {
+	if err := s.access.billing(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	coupon, err := s.couponRepo.FindByCode(ctx, normalizeCouponCode(code))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCouponNotFound
+		}
+		return nil, err
+	}
+	now := s.now()
+	if !coupon.IsRedeemable(now) {
+		return nil, ErrCouponNotRedeemable
+	}
+
+	redeemed, err := s.couponRepo.HasRedeemed(ctx, coupon.ID, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if redeemed {
+		return nil, ErrCouponAlreadyRedeemed
+	}
+	if _, err := s.couponRepo.FindActiveRedemption(ctx, orgID); err == nil {
+		return nil, ErrDiscountActive
+	} else if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	redemption := &models.CouponRedemption{
+		CouponID:       coupon.ID,
+		OrganizationID: orgID,
+		RedeemedByID:   userID,
+		Cycles:         coupon.Cycles(),
+	}
+	ok, err := s.couponRepo.Redeem(ctx, redemption, now)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrCouponNotRedeemable
+	}
+	return s.activeRedemption(ctx, orgID)
+}
+
+// RemoveDiscount ends the organization's running discount
+func (s *CouponService) RemoveDiscount(ctx context.Context, userID, orgID uint) error {
+	if err := s.access.billing(ctx, orgID, userID); err != nil {
+		return err
+	}
+	ended, err := s.couponRepo.EndRedemption(ctx, orgID, s.now())
+	if err != nil {
+		return err
+	}
+	if !ended {
+		return ErrNoDiscount
+	}
+	return nil
+}
+
+// GetCredits returns the organization's account credit. Credit is applied to
+// the next invoices in its currency before anything is charged.
+func (s *CouponService) GetCredits(ctx context.Context, userID, orgID uint) (*CreditBalance, error) {
+	if err := s.access.admin(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.credits(ctx, orgID)
+}
+
+// GrantCredit adds promotional credit to the organization's account. Only
+// platform admins can grant credit.
+func (s *CouponService) GrantCredit(ctx context.Context, userID, orgID uint, input CreditInput) (*CreditBalance, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	amount, err := parseAmount(input.Amount, input.Currency, "")
+	if err != nil {
+		return nil, err
+	}
+	if !amount.IsPositive() {
+		return nil, ErrInvalidCreditAmount
+	}
+	if _, err := s.orgRepo.FindByID(ctx, orgID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+
+	description := strings.TrimSpace(input.Description)
+	if description == "" {
+		description = "Promotional credit"
+	}
+	now := s.now()
+	credit := &models.InvoiceItem{
+		OrganizationID: orgID,
+		Kind:           models.InvoiceItemKindCredit,
+		Description:    description,
+		Quantity:       1,
+		UnitPrice:      amount.Neg(),
+		Amount:         amount.Neg(),
+		PeriodStart:    now,
+		PeriodEnd:      now,
+	}
+	if err := s.invoiceRepo.CreatePendingItem(ctx, credit); err != nil {
+		return nil, err
+	}
+	return s.credits(ctx, orgID)
+}
+
+func (s *CouponService) activeRedemption(ctx context.Context, orgID uint) (*models.CouponRedemption, error) {
+	redemption, err := s.couponRepo.FindActiveRedemption(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNoDiscount
+		}
+		return nil, err
+	}
+	return redemption, nil
+}
+
+// credits adds up the organization's pending credits per currency. Credit is
+// stored as negative items; balances are reported as positive amounts.
+func (s *CouponService) credits(ctx context.Context, orgID uint) (*CreditBalance, error) {
+	credits, err := s.invoiceRepo.ListPendingCredits(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	balance := &CreditBalance{Balances: []money.Money{}, Credits: credits}
+	index := make(map[string]int)
+	for _, credit := range credits {
+		i, ok := index[credit.Amount.Currency]
+		if !ok {
+			i = len(balance.Balances)
+			index[credit.Amount.Currency] = i
+			balance.Balances = append(balance.Balances, money.Zero(credit.Amount.Currency))
+		}
+		total, err := balance.Balances[i].Sub(credit.Amount)
+		if err != nil {
+			return nil, err
+		}
+		balance.Balances[i] = total
+	}
+	return balance, nil
+}
+
+// normalizeCouponCode makes codes case-insensitive
+func normalizeCouponCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
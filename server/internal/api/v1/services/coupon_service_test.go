@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// memAdminRepo makes user 1 the admin of every organization
+type memAdminRepo struct {
+	memMemberRepo
+}
+
+func (r memAdminRepo) FindMember(ctx context.Context, orgID, userID uint) (*models.OrganizationUser, error) {
+	if userID != 1 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &models.OrganizationUser{OrganizationID: orgID, UserID: userID, Role: "admin"}, nil
+}
+
+// memCouponRepo holds coupons by code and the redemptions of one organization.
+// A coupon in taken is redeemed by another organization before Redeem runs.
+type memCouponRepo struct {
+	repositories.CouponRepository
+	coupons     map[string]*models.Coupon
+	redemptions []models.CouponRedemption
+	taken       string
+}
+
+func (r *memCouponRepo) FindByCode(ctx context.Context, code string) (*models.Coupon, error) {
+	if coupon, ok := r.coupons[code]; ok {
+		return coupon, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memCouponRepo) HasRedeemed(ctx context.Context, couponID, orgID uint) (bool, error) {
+	for _, redemption := range r.redemptions {
+		if redemption.CouponID == couponID {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *memCouponRepo) FindActiveRedemption(ctx context.Context, orgID uint) (*models.CouponRedemption, error) {
+	for i := range r.redemptions {
+		if r.redemptions[i].IsActive() {
+			return &r.redemptions[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memCouponRepo) Redeem(ctx context.Context, redemption *models.CouponRedemption, now time.Time) (bool, error) {
+	for _, coupon := range r.coupons {
+		if coupon.ID != redemption.CouponID {
+			continue
+		}
+		if coupon.Code == r.taken {
+			coupon.TimesRedeemed++
+		}
+		if !coupon.IsRedeemable(now) {
+			return false, nil
+		}
+		coupon.TimesRedeemed++
+		r.redemptions = append(r.redemptions, *redemption)
+		return true, nil
+	}
+	return false, gorm.ErrRecordNotFound
+}
+
+func TestRedeemCoupon(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	earlier := now.Add(-time.Hour)
+	coupon := func(id uint, code string, duration models.CouponDuration, cycles int) *models.Coupon {
+		c := &models.Coupon{Code: code, DiscountType: models.DiscountTypePercent, PercentOff: 20, Duration: duration, DurationCycles: cycles}
+		c.ID = id
+		return c
+	}
+	coupons := func() map[string]*models.Coupon {
+		expired := coupon(4, "EXPIRED", models.CouponDurationOnce, 0)
+		expired.ExpiresAt = &earlier
+		archived := coupon(5, "ARCHIVED", models.CouponDurationOnce, 0)
+		archived.ArchivedAt = &earlier
+		usedUp := coupon(6, "USED-UP", models.CouponDurationOnce, 0)
+		usedUp.MaxRedemptions, usedUp.TimesRedeemed = 50, 50
+		lastOne := coupon(7, "LAST-ONE", models.CouponDurationOnce, 0)
+		lastOne.MaxRedemptions, lastOne.TimesRedeemed = 50, 49
+		return map[string]*models.Coupon{
+			"ONCE":     coupon(1, "ONCE", models.CouponDurationOnce, 0),
+			"QUARTER":  coupon(2, "QUARTER", models.CouponDurationRepeating, 3),
+			"FOREVER":  coupon(3, "FOREVER", models.CouponDurationForever, 0),
+			"EXPIRED":  expired,
+			"ARCHIVED": archived,
+			"USED-UP":  usedUp,
+			"LAST-ONE": lastOne,
+		}
+	}
+	ended := []models.CouponRedemption{{CouponID: 1, EndedAt: &earlier}}
+	running := []models.CouponRedemption{{CouponID: 3}}
+
+	tests := []struct {
+		name        string
+		userID      uint
+		code        string
+		redemptions []models.CouponRedemption
+		taken       string
+		cycles      int
+		err         error
+	}{
+		{"once", 1, "ONCE", nil, "", 1, nil},
+		{"repeating", 1, "QUARTER", nil, "", 3, nil},
+		{"forever", 1, "FOREVER", nil, "", 0, nil},
+		{"code as typed", 1, " quarter ", nil, "", 3, nil},
+		{"after an ended discount", 1, "QUARTER", ended, "", 3, nil},
+		{"last redemption", 1, "LAST-ONE", nil, "", 1, nil},
+		{"unknown code", 1, "NOPE", nil, "", 0, ErrCouponNotFound},
+		{"expired", 1, "EXPIRED", nil, "", 0, ErrCouponNotRedeemable},
+		{"archived", 1, "ARCHIVED", nil, "", 0, ErrCouponNotRedeemable},
+		{"redemptions used up", 1, "USED-UP", nil, "", 0, ErrCouponNotRedeemable},
+		{"last redemption taken meanwhile", 1, "LAST-ONE", nil, "LAST-ONE", 0, ErrCouponNotRedeemable},
+		{"redeemed before", 1, "ONCE", ended, "", 0, ErrCouponAlreadyRedeemed},
+		{"discount running", 1, "ONCE", running, "", 0, ErrDiscountActive},
+		{"not a member", 2, "ONCE", nil, "", 0, ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memCouponRepo{coupons: coupons(), redemptions: append([]models.CouponRedemption(nil), tt.redemptions...), taken: tt.taken}
+			service := &CouponService{
+				couponRepo: repo,
+				access:     accessChecker{orgRepo: memAdminRepo{}},
+				now:        func() time.Time { return now },
+			}
+			redemption, err := service.RedeemCoupon(context.Background(), tt.userID, 1, tt.code)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("RedeemCoupon() error = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				if len(repo.redemptions) != len(tt.redemptions) {
+					t.Errorf("failed redemption was stored")
+				}
+				return
+			}
+			if redemption.Cycles != tt.cycles || redemption.RedeemedByID != tt.userID || !redemption.IsActive() {
+				t.Errorf("redemption = %+v, want an active redemption for %d cycles", redemption, tt.cycles)
+			}
+		})
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/billing"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var ErrCreditNoteNotFound = errors.New("credit note not found")
+
+type CreditNoteService struct {
+	creditNoteRepo repositories.CreditNoteRepository
+	invoiceRepo    repositories.InvoiceRepository
+	access         accessChecker
+	settings       InvoiceSettings
+	now            func() time.Time
+}
+
+func NewCreditNoteService(
+	creditNoteRepo repositories.CreditNoteRepository,
+	invoiceRepo repositories.InvoiceRepository,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+	settings InvoiceSettings,
+) *CreditNoteService {
+	return &CreditNoteService{
+		creditNoteRepo: creditNoteRepo,
+		invoiceRepo:    invoiceRepo,
+		access:         accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+		settings:       settings,
+		now:            time.Now,
+	}
+}
+
+// ListCreditNotes lists the organization's credit notes, newest first
+func (s *CreditNoteService) ListCreditNotes(ctx context.Context, userID, orgID uint) ([]models.CreditNote, error) {
+	if err := s.access.admin(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.creditNoteRepo.ListByOrganization(ctx, orgID)
+}
+
+// GetCreditNote returns a credit note
+func (s *CreditNoteService) GetCreditNote(ctx context.Context, userID, noteID uint) (*models.CreditNote, error) {
+	note, err := s.creditNoteRepo.FindByID(ctx, noteID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCreditNoteNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.admin(ctx, note.OrganizationID, userID); err != nil {
+		return nil, err
+	}
+	return note, nil
+}
+
+// RenderCreditNote writes the credit note as a PDF or HTML document
+func (s *CreditNoteService) RenderCreditNote(ctx context.Context, userID, noteID uint, format InvoiceFormat, w io.Writer) (*models.CreditNote, error) {
+	if format != InvoiceFormatPDF && format != InvoiceFormatHTML {
+		return nil, ErrUnknownInvoiceFormat
+	}
+	note, err := s.GetCreditNote(ctx, userID, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := billing.CreditNoteDocument{Note: note, Seller: s.settings.Seller}
+	if format == InvoiceFormatPDF {
+		return note, billing.RenderCreditNotePDF(w, doc)
+	}
+	return note, billing.RenderCreditNoteHTML(w, doc)
+}
+
+// IssueForRefund issues the credit note for a refund that succeeded, or
+// returns the one issued for it before
+func (s *CreditNoteService) IssueForRefund(ctx context.Context, transaction *models.PaymentTransaction) (*models.CreditNote, error) {
+	invoice, err := s.invoiceRepo.FindByID(ctx, transaction.InvoiceID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	note, err := billing.NewCreditNote(invoice, transaction, now)
+	if err != nil {
+		return nil, err
+	}
+
+	scope := billing.CreditNoteScope(s.settings.NumberPerOrganization, invoice.OrganizationID)
+	issued, err := s.creditNoteRepo.Issue(ctx, note, scope, func(seq int64) string {
+		return s.settings.CreditNoteFormat.Format(seq, invoice.OrganizationID, now)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !issued {
+		return s.creditNoteRepo.FindByTransaction(ctx, transaction.ID)
+	}
+	return note, nil
+}
+
+// IssueMissing issues credit notes for refunds that succeeded without one,
+// such as those settled by a webhook, and returns the number issued
+func (s *CreditNoteService) IssueMissing(ctx context.Context) (int, error) {
+	transactions, err := s.creditNoteRepo.ListUncredited(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	issued := 0
+	var errs []error
+	for i := range transactions {
+		if _, err := s.IssueForRefund(ctx, &transactions[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		issued++
+	}
+	return issued, errors.Join(errs...)
+}
+
+// reversedRefundReason is recorded on credit notes voided by VoidReversed
+const reversedRefundReason = "The refund was reversed"
+
+// VoidReversed voids the credit notes of refunds that failed after
+// succeeding, such as those the card issuer returned, and returns the number
+// voided
+func (s *CreditNoteService) VoidReversed(ctx context.Context) (int, error) {
+	notes, err := s.creditNoteRepo.ListReversed(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	voided := 0
+	var errs []error
+	now := s.now().UTC()
+	for i := range notes {
+		if !notes[i].Void(now, reversedRefundReason) {
+			continue
+		}
+		if err := s.creditNoteRepo.Void(ctx, &notes[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		voided++
+	}
+	return voided, errors.Join(errs...)
+}
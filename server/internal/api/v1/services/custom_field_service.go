@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrCustomFieldNotFound  = errors.New("custom field not found")
+	ErrCustomFieldKeyExists = errors.New("a custom field with this key already exists in the project")
+	ErrCustomFieldTypeFixed = errors.New("the type of a custom field cannot be changed")
+	ErrUnknownTemplate      = errors.New("unknown custom field template")
+	ErrUnknownCustomField   = errors.New("unknown custom field")
+	ErrInvalidFieldUser     = errors.New("user is not a member of the organization")
+)
+
+// CustomFieldInput holds the editable fields of a custom field definition
+type CustomFieldInput struct {
+	Name     string
+	Key      string
+	Type     models.CustomFieldType
+	Options  []string
+	Currency string
+	Required bool
+	Position int
+}
+
+type CustomFieldService struct {
+	fieldRepo repositories.CustomFieldRepository
+	taskRepo  repositories.TaskRepository
+	orgRepo   repositories.OrganizationRepository
+	access    accessChecker
+}
+
+func NewCustomFieldService(
+	fieldRepo repositories.CustomFieldRepository,
+	taskRepo repositories.TaskRepository,
+	entitlements *EntitlementService,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *CustomFieldService {
+	return &CustomFieldService{
+		fieldRepo: fieldRepo,
+		taskRepo:  taskRepo,
+		orgRepo:   orgRepo,
+		access:    accessChecker{orgRepo: orgRepo, projectRepo: projectRepo, feature: featureGate{entitlements, models.FeatureCustomFields}},
+	}
+}
+
+func (s *CustomFieldService) CreateField(ctx context.Context, userID, projectID uint, input CustomFieldInput) (*models.CustomFieldDefinition, error) {
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.fieldRepo.ListByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, field := range existing {
+		if field.Key == input.Key {
+			return nil, ErrCustomFieldKeyExists
+		}
+	}
+
+	field := &models.CustomFieldDefinition{
+		ProjectID: project.ID,
+		Name:      input.Name,
+		Key:       input.Key,
+		Type:      input.Type,
+		Options:   input.Options,
+		Currency:  input.Currency,
+		Required:  input.Required,
+		Position:  input.Position,
+	}
+	if err := s.fieldRepo.Create(ctx, field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+func (s *CustomFieldService) ListFields(ctx context.Context, userID, projectID uint) ([]models.CustomFieldDefinition, error) {
+	if _, err := s.access.project(ctx, projectID, userID); err != nil {
+		return nil, err
+	}
+	return s.fieldRepo.ListByProject(ctx, projectID)
+}
+
+func (s *CustomFieldService) UpdateField(ctx context.Context, userID, fieldID uint, input CustomFieldInput) (*models.CustomFieldDefinition, error) {
+	field, err := s.loadField(ctx, userID, fieldID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Type != "" && input.Type != field.Type {
+		return nil, ErrCustomFieldTypeFixed
+	}
+
+	if input.Key != field.Key {
+		existing, err := s.fieldRepo.ListByProject(ctx, field.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		for _, other := range existing {
+			if other.Key == input.Key {
+				return nil, ErrCustomFieldKeyExists
+			}
+		}
+	}
+
+	field.Name = input.Name
+	field.Key = input.Key
+	field.Options = input.Options
+	field.Currency = input.Currency
+	field.Required = input.Required
+	field.Position = input.Position
+
+	if err := s.fieldRepo.Update(ctx, field); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
+
+func (s *CustomFieldService) DeleteField(ctx context.Context, userID, fieldID uint) error {
+	field, err := s.loadField(ctx, userID, fieldID)
+	if err != nil {
+		return err
+	}
+	return s.fieldRepo.Delete(ctx, field.ID)
+}
+
+// ApplyTemplate adds the preset fields for a project category, such as a cost
+// center for finance projects. Fields whose key already exists are skipped.
+func (s *CustomFieldService) ApplyTemplate(ctx context.Context, userID, projectID uint, template string) ([]models.CustomFieldDefinition, error) {
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	preset, ok := models.CustomFieldTemplate(template)
+	if !ok {
+		return nil, ErrUnknownTemplate
+	}
+
+	existing, err := s.fieldRepo.ListByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	keys := make(map[string]bool, len(existing))
+	for _, field := range existing {
+		keys[field.Key] = true
+	}
+
+	var created []models.CustomFieldDefinition
+	for i, field := range preset {
+		if keys[field.Key] {
+			continue
+		}
+		field.ProjectID = project.ID
+		field.Position = len(existing) + i
+		created = append(created, field)
+	}
+
+	if err := s.fieldRepo.CreateMany(ctx, created); err != nil {
+		return nil, err
+	}
+	return s.fieldRepo.ListByProject(ctx, project.ID)
+}
+
+// SetTaskValues validates and stores custom field values for a task, keyed by
+// field key. A null value clears the field.
+func (s *CustomFieldService) SetTaskValues(ctx context.Context, userID, taskID uint, values map[string]json.RawMessage) ([]models.CustomFieldValue, error) {
+	task, err := s.taskRepo.FindByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	project, err := s.access.project(ctx, task.ProjectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := s.fieldRepo.ListByProject(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+	byKey := make(map[string]*models.CustomFieldDefinition, len(fields))
+	for i := range fields {
+		byKey[fields[i].Key] = &fields[i]
+	}
+
+	var set []models.CustomFieldValue
+	var clear []uint
+	for key, raw := range values {
+		field, ok := byKey[key]
+		if !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownCustomField, key)
+		}
+
+		value, err := field.ParseValue(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if value == nil {
+			clear = append(clear, field.ID)
+			continue
+		}
+
+		if value.UserID != nil {
+			isMember, err := s.orgRepo.IsMember(ctx, project.OrganizationID, *value.UserID)
+			if err != nil {
+				return nil, err
+			}
+			if !isMember {
+				return nil, fmt.Errorf("%s: %w", key, ErrInvalidFieldUser)
+			}
+		}
+		set = append(set, *value)
+	}
+
+	if err := s.fieldRepo.SetTaskValues(ctx, task.ID, set, clear); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.taskRepo.FindByID(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+	return updated.CustomFields, nil
+}
+
+// loadField loads a custom field and checks the user's access to its project
+func (s *CustomFieldService) loadField(ctx context.Context, userID, fieldID uint) (*models.CustomFieldDefinition, error) {
+	field, err := s.fieldRepo.FindByID(ctx, fieldID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrCustomFieldNotFound
+		}
+		return nil, err
+	}
+	if _, err := s.access.project(ctx, field.ProjectID, userID); err != nil {
+		return nil, err
+	}
+	return field, nil
+}
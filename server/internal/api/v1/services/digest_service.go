@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/mail"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+const (
+	// digestCommentLimit is the most comments a digest lists
+	digestCommentLimit = 20
+	// digestExcerptLength is how many characters of a comment a digest shows
+	digestExcerptLength = 140
+)
+
+// Digest is a user's digest setting, with when the next one is sent
+type Digest struct {
+	Frequency  models.DigestFrequency `json:"frequency"`
+	Hour       int                    `json:"hour"`
+	Weekday    time.Weekday           `json:"weekday"`
+	TimeZone   string                 `json:"time_zone"` // the hour is in the user's time zone
+	LastSentAt *time.Time             `json:"last_sent_at"`
+	NextSendAt *time.Time             `json:"next_send_at,omitempty"`
+}
+
+// DigestInput changes a user's digest. Fields left out keep their value.
+type DigestInput struct {
+	Frequency models.DigestFrequency
+	Hour      *int
+	Weekday   *time.Weekday
+}
+
+type DigestService struct {
+	digestRepo  repositories.DigestRepository
+	userRepo    repositories.UserRepository
+	mailService *MailService
+	now         func() time.Time
+}
+
+func NewDigestService(
+	digestRepo repositories.DigestRepository,
+	userRepo repositories.UserRepository,
+	mailService *MailService,
+) *DigestService {
+	return &DigestService{
+		digestRepo:  digestRepo,
+		userRepo:    userRepo,
+		mailService: mailService,
+		now:         time.Now,
+	}
+}
+
+// GetDigest returns the user's digest setting. Users get no digest until
+// they turn it on.
+func (s *DigestService) GetDigest(ctx context.Context, userID uint) (*Digest, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	setting, err := s.findSetting(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.digest(user, setting), nil
+}
+
+// UpdateDigest turns the user's digest on or off or changes when it is sent
+func (s *DigestService) UpdateDigest(ctx context.Context, userID uint, input DigestInput) (*Digest, error) {
+	user, err := s.findUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	setting, err := s.findSetting(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	wasEnabled := setting.IsEnabled()
+	if input.Frequency != "" {
+		setting.Frequency = input.Frequency
+	}
+	if input.Hour != nil {
+		setting.Hour = *input.Hour
+	}
+	if input.Weekday != nil {
+		setting.Weekday = *input.Weekday
+	}
+	if err := setting.Validate(); err != nil {
+		return nil, err
+	}
+	// A digest just turned on covers what happens from now on
+	if setting.IsEnabled() && !wasEnabled {
+		now := s.now()
+		setting.LastSentAt = &now
+	}
+
+	if err := s.digestRepo.SaveSetting(ctx, setting); err != nil {
+		return nil, err
+	}
+	return s.digest(user, setting), nil
+}
+
+// Unsubscribe turns off the digest of the user an unsubscribe link was made
+// for. It works without logging in, the link's signature vouching for it.
+func (s *DigestService) Unsubscribe(ctx context.Context, token string) error {
+	userID, err := s.mailService.ParseUnsubscribeToken(token)
+	if err != nil {
+		return err
+	}
+	return s.digestRepo.Unsubscribe(ctx, userID)
+}
+
+// SendDue queues the digests whose time has come in their users' time zones
+// and returns the number queued. Digests with nothing to report are skipped.
+func (s *DigestService) SendDue(ctx context.Context) (int, error) {
+	recipients, err := s.digestRepo.ListEnabled(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	now := s.now()
+	queued := 0
+	var errs []error
+	for i := range recipients {
+		recipient := &recipients[i]
+		loc := (&models.User{TimeZone: recipient.TimeZone}).Location()
+		if !recipient.Due(now, loc) {
+			continue
+		}
+		email, err := s.compose(ctx, recipient, now, loc)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("digest for user %d: %w", recipient.UserID, err))
+			continue
+		}
+		if err := s.digestRepo.QueueDigest(ctx, recipient.UserID, email, now); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if email != nil {
+			queued++
+		}
+	}
+	return queued, errors.Join(errs...)
+}
+
+// compose renders a recipient's digest of what happened since their last
+// one, or returns nil if nothing did
+func (s *DigestService) compose(ctx context.Context, recipient *repositories.DigestRecipient, now time.Time, loc *time.Location) (*models.OutboxEmail, error) {
+	since := now.Add(-recipient.Horizon())
+	if recipient.LastSentAt != nil {
+		since = *recipient.LastSentAt
+	}
+
+	tasks, err := s.digestRepo.ListAssignedDue(ctx, recipient.UserID, now.Add(recipient.Horizon()))
+	if err != nil {
+		return nil, err
+	}
+	comments, commentCount, err := s.digestRepo.ListWatchedComments(ctx, recipient.UserID, since, now, digestCommentLimit)
+	if err != nil {
+		return nil, err
+	}
+	statusChanges, err := s.digestRepo.ListProjectStatusChanges(ctx, recipient.UserID, since, now)
+	if err != nil {
+		return nil, err
+	}
+
+	data := mail.DigestData{
+		Name:            recipient.FirstName,
+		Weekly:          recipient.Frequency == models.DigestFrequencyWeekly,
+		Link:            s.mailService.Link("/"),
+		UnsubscribeLink: s.mailService.UnsubscribeLink(recipient.UserID),
+	}
+	for _, task := range tasks {
+		item := mail.DigestTask{
+			Title:   task.Title,
+			DueDate: task.DueDate.In(loc),
+			Link:    s.mailService.Link(fmt.Sprintf("/tasks/%d", task.ID)),
+		}
+		if task.DueDate.Before(now) {
+			data.Overdue = append(data.Overdue, item)
+		} else {
+			data.DueSoon = append(data.DueSoon, item)
+		}
+	}
+	data.MoreComments = int(commentCount) - len(comments)
+	names := make(map[uint]string)
+	for _, comment := range comments {
+		data.Comments = append(data.Comments, mail.DigestComment{
+			TaskTitle: comment.TaskTitle,
+			Author:    strings.TrimSpace(comment.AuthorFirstName + " " + comment.AuthorLastName),
+			Excerpt:   s.excerpt(ctx, comment.Content, names),
+			Link:      s.mailService.Link(fmt.Sprintf("/tasks/%d", comment.TaskID)),
+		})
+	}
+	for _, change := range statusChanges {
+		for _, field := range change.Changes {
+			if field.Field != "status" {
+				continue
+			}
+			data.StatusChanges = append(data.StatusChanges, mail.DigestStatusChange{
+				ProjectName: change.ProjectName,
+				From:        fmt.Sprint(field.Before),
+				To:          fmt.Sprint(field.After),
+				Link:        s.mailService.Link(fmt.Sprintf("/projects/%d", change.ProjectID)),
+			})
+		}
+	}
+
+	if len(data.Overdue) == 0 && len(data.DueSoon) == 0 && len(data.Comments) == 0 && len(data.StatusChanges) == 0 {
+		return nil, nil
+	}
+	email, err := s.mailService.Render(recipient.Email, recipient.Language, mail.TemplateDigest, data)
+	if err != nil {
+		return nil, err
+	}
+	email.UnsubscribeURL = data.UnsubscribeLink
+	return email, nil
+}
+
+// excerpt shortens a comment to a line, with mentions written as names
+func (s *DigestService) excerpt(ctx context.Context, content string, names map[uint]string) string {
+	text := models.ReplaceMentions(content, func(id uint) string {
+		name, ok := names[id]
+		if !ok {
+			name = "someone"
+			if user, err := s.userRepo.FindByID(ctx, id); err == nil {
+				name = user.FullName()
+			}
+			names[id] = name
+		}
+		return "@" + name
+	})
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) > digestExcerptLength {
+		text = string([]rune(text)[:digestExcerptLength-1]) + "…"
+	}
+	return text
+}
+
+// digest describes a user's setting, with when their next digest is sent
+func (s *DigestService) digest(user *models.User, setting *models.DigestSetting) *Digest {
+	loc := user.Location()
+	digest := &Digest{
+		Frequency:  setting.Frequency,
+		Hour:       setting.Hour,
+		Weekday:    setting.Weekday,
+		TimeZone:   loc.String(),
+		LastSentAt: setting.LastSentAt,
+	}
+	if setting.IsEnabled() {
+		next := setting.NextSlot(s.now(), loc)
+		digest.NextSendAt = &next
+	}
+	return digest
+}
+
+func (s *DigestService) findUser(ctx context.Context, userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// findSetting returns the user's digest setting, or the default of no
+// digest if they never chose
+func (s *DigestService) findSetting(ctx context.Context, userID uint) (*models.DigestSetting, error) {
+	setting, err := s.digestRepo.FindSetting(ctx, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return &models.DigestSetting{
+			UserID:    userID,
+			Frequency: models.DigestFrequencyOff,
+			Hour:      models.DefaultDigestHour,
+			Weekday:   time.Monday,
+		}, nil
+	}
+	return setting, err
+}
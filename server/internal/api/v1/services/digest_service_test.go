@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// memDigestRepo holds at most one digest setting
+type memDigestRepo struct {
+	repositories.DigestRepository
+	setting *models.DigestSetting
+}
+
+func (r *memDigestRepo) FindSetting(ctx context.Context, userID uint) (*models.DigestSetting, error) {
+	if r.setting == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	setting := *r.setting
+	return &setting, nil
+}
+
+func (r *memDigestRepo) SaveSetting(ctx context.Context, setting *models.DigestSetting) error {
+	saved := *setting
+	r.setting = &saved
+	return nil
+}
+
+func TestUpdateDigest(t *testing.T) {
+	now := time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)
+	sentAt := now.AddDate(0, 0, -1)
+	at := func(t time.Time) *time.Time { return &t }
+	hour := func(h int) *int { return &h }
+	weekday := func(d time.Weekday) *time.Weekday { return &d }
+	daily := &models.DigestSetting{UserID: 1, Frequency: models.DigestFrequencyDaily, Hour: 8, Weekday: time.Monday, LastSentAt: &sentAt}
+
+	tests := []struct {
+		name     string
+		saved    *models.DigestSetting
+		input    DigestInput
+		want     models.DigestSetting
+		lastSent *time.Time
+		next     *time.Time
+		err      error
+	}{
+		{"turned on", nil, DigestInput{Frequency: models.DigestFrequencyDaily},
+			models.DigestSetting{Frequency: models.DigestFrequencyDaily, Hour: 8, Weekday: time.Monday}, &now,
+			at(time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)), nil},
+		{"weekly on friday evening", nil, DigestInput{Frequency: models.DigestFrequencyWeekly, Hour: hour(18), Weekday: weekday(time.Friday)},
+			models.DigestSetting{Frequency: models.DigestFrequencyWeekly, Hour: 18, Weekday: time.Friday}, &now,
+			at(time.Date(2026, 3, 13, 22, 0, 0, 0, time.UTC)), nil},
+		{"hour changed", daily, DigestInput{Hour: hour(7)},
+			models.DigestSetting{Frequency: models.DigestFrequencyDaily, Hour: 7, Weekday: time.Monday}, &sentAt,
+			at(time.Date(2026, 3, 9, 11, 0, 0, 0, time.UTC)), nil},
+		{"turned off", daily, DigestInput{Frequency: models.DigestFrequencyOff},
+			models.DigestSetting{Frequency: models.DigestFrequencyOff, Hour: 8, Weekday: time.Monday}, &sentAt, nil, nil},
+		{"invalid hour", daily, DigestInput{Hour: hour(24)}, models.DigestSetting{}, nil, nil, models.ErrInvalidDigestHour},
+		{"invalid frequency", nil, DigestInput{Frequency: "hourly"}, models.DigestSetting{}, nil, nil, models.ErrInvalidDigestFrequency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memDigestRepo{setting: tt.saved}
+			service := &DigestService{
+				digestRepo: repo,
+				userRepo:   memUserRepo{users: map[uint]models.User{1: {TimeZone: "America/New_York"}}},
+				now:        func() time.Time { return now },
+			}
+			digest, err := service.UpdateDigest(context.Background(), 1, tt.input)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("UpdateDigest() error = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				if repo.setting != tt.saved {
+					t.Error("invalid setting was saved")
+				}
+				return
+			}
+
+			saved := repo.setting
+			if saved.Frequency != tt.want.Frequency || saved.Hour != tt.want.Hour || saved.Weekday != tt.want.Weekday {
+				t.Errorf("saved %s at %d on %s, want %s at %d on %s",
+					saved.Frequency, saved.Hour, saved.Weekday, tt.want.Frequency, tt.want.Hour, tt.want.Weekday)
+			}
+			if saved.LastSentAt == nil || !saved.LastSentAt.Equal(*tt.lastSent) {
+				t.Errorf("LastSentAt = %v, want %v", saved.LastSentAt, tt.lastSent)
+			}
+			if digest.TimeZone != "America/New_York" {
+				t.Errorf("TimeZone = %q, want America/New_York", digest.TimeZone)
+			}
+			if (digest.NextSendAt == nil) != (tt.next == nil) || (tt.next != nil && !digest.NextSendAt.Equal(*tt.next)) {
+				t.Errorf("NextSendAt = %v, want %v", digest.NextSendAt, tt.next)
+			}
+		})
+	}
+}
+
+func TestDigestExcerpt(t *testing.T) {
+	service := &DigestService{
+		userRepo: memUserRepo{users: map[uint]models.User{7: {FirstName: "Ada", LastName: "Lovelace"}}},
+	}
+	long := strings.Repeat("é", digestExcerptLength+10)
+
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"plain", "Looks good", "Looks good"},
+		{"mentions", "<@7> and <@8>, please review", "@Ada Lovelace and @someone, please review"},
+		{"whitespace", "  first line\n\n\tsecond   line ", "first line second line"},
+		{"long", long, strings.Repeat("é", digestExcerptLength-1) + "…"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := service.excerpt(context.Background(), tt.content, map[uint]string{}); got != tt.want {
+				t.Errorf("excerpt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
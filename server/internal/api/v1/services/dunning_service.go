@@ -0,0 +1,222 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/billing"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/mail"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// DunningService recovers overdue invoices. It charges them again on the
+// policy's retry schedule and sends reminders on its reminder schedule. The
+// subscription is past due while an invoice is overdue and read-only once
+// the grace period is over. Paying the invoice, by any means, lifts both
+// straight away.
+type DunningService struct {
+	invoiceRepo      repositories.InvoiceRepository
+	subscriptionRepo repositories.SubscriptionRepository
+	orgRepo          repositories.OrganizationRepository
+	paymentService   *PaymentService
+	mailService      *MailService
+	policy           billing.DunningPolicy
+	now              func() time.Time
+}
+
+func NewDunningService(
+	invoiceRepo repositories.InvoiceRepository,
+	subscriptionRepo repositories.SubscriptionRepository,
+	orgRepo repositories.OrganizationRepository,
+	paymentService *PaymentService,
+	mailService *MailService,
+	policy billing.DunningPolicy,
+) *DunningService {
+	return &DunningService{
+		invoiceRepo:      invoiceRepo,
+		subscriptionRepo: subscriptionRepo,
+		orgRepo:          orgRepo,
+		paymentService:   paymentService,
+		mailService:      mailService,
+		policy:           policy,
+		now:              time.Now,
+	}
+}
+
+// RunDunning works through the overdue invoices of each subscription and
+// returns the number recovered by a retried payment
+func (s *DunningService) RunDunning(ctx context.Context) (int, error) {
+	invoices, err := s.invoiceRepo.ListOverdue(ctx, s.now())
+	if err != nil {
+		return 0, err
+	}
+
+	recovered := 0
+	var errs []error
+	for start := 0; start < len(invoices); {
+		end := start + 1
+		for end < len(invoices) && invoices[end].SubscriptionID == invoices[start].SubscriptionID {
+			end++
+		}
+		n, err := s.dun(ctx, invoices[start:end])
+		recovered += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+		start = end
+	}
+	return recovered, errors.Join(errs...)
+}
+
+// dun retries and reminds of one subscription's overdue invoices, oldest
+// first, and updates the subscription's dunning state
+func (s *DunningService) dun(ctx context.Context, invoices []models.Invoice) (int, error) {
+	now := s.now().UTC()
+	recovered := 0
+	var errs []error
+	var unpaid []*models.Invoice
+	for i := range invoices {
+		invoice := &invoices[i]
+		if err := s.retry(ctx, invoice, now); err != nil {
+			errs = append(errs, err)
+		}
+		if !invoice.IsOverdue(now) {
+			recovered++
+			continue
+		}
+		unpaid = append(unpaid, invoice)
+	}
+	if len(unpaid) == 0 {
+		return recovered, errors.Join(errs...)
+	}
+
+	subscription, err := s.subscriptionRepo.FindByID(ctx, unpaid[0].SubscriptionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return recovered, errors.Join(errs...)
+		}
+		return recovered, errors.Join(append(errs, err)...)
+	}
+	if subscription.Status != models.SubscriptionStatusActive {
+		return recovered, errors.Join(errs...)
+	}
+	if err := s.updateSubscription(ctx, subscription, unpaid, now); err != nil {
+		return recovered, errors.Join(append(errs, err)...)
+	}
+
+	for _, invoice := range unpaid {
+		if err := s.remind(ctx, invoice, subscription, now); err != nil {
+			errs = append(errs, err)
+		}
+		if err := s.invoiceRepo.SaveDunning(ctx, invoice); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return recovered, errors.Join(errs...)
+}
+
+// retry charges a failed invoice again if a retry is due. Retries missed
+// while the job was not running are skipped rather than made all at once.
+// Invoices still pending have a payment in progress and are left alone.
+func (s *DunningService) retry(ctx context.Context, invoice *models.Invoice, now time.Time) error {
+	defer func() {
+		invoice.NextRetryAt = nil
+		if at, ok := s.policy.NextRetry(invoice.DueDate, invoice.DunningRetries); ok {
+			invoice.NextRetryAt = &at
+		}
+	}()
+
+	if invoice.Status != models.PaymentStatusFailed {
+		return nil
+	}
+	due := false
+	for {
+		at, ok := s.policy.NextRetry(invoice.DueDate, invoice.DunningRetries)
+		if !ok || at.After(now) {
+			break
+		}
+		invoice.DunningRetries++
+		due = true
+	}
+	if !due {
+		return nil
+	}
+
+	// A declined payment leaves the invoice failed without an error
+	if _, err := s.paymentService.charge(ctx, invoice); err != nil && !errors.Is(err, ErrNoPaymentMethod) {
+		return err
+	}
+	return nil
+}
+
+// updateSubscription sets the subscription's dunning state from its unpaid
+// invoices, saving it if it changed
+func (s *DunningService) updateSubscription(ctx context.Context, subscription *models.Subscription, unpaid []*models.Invoice, now time.Time) error {
+	var nextRetry *time.Time
+	for _, invoice := range unpaid {
+		if invoice.NextRetryAt != nil && (nextRetry == nil || invoice.NextRetryAt.Before(*nextRetry)) {
+			nextRetry = invoice.NextRetryAt
+		}
+	}
+
+	before := *subscription
+	oldest := unpaid[0].DueDate
+	subscription.SetDunning(oldest, s.policy.GraceEnds(oldest), nextRetry, now)
+	if before.DunningStatus == subscription.DunningStatus &&
+		sameTime(before.PastDueSince, subscription.PastDueSince) &&
+		sameTime(before.GraceEndsAt, subscription.GraceEndsAt) &&
+		sameTime(before.NextPaymentRetryAt, subscription.NextPaymentRetryAt) {
+		return nil
+	}
+	return s.subscriptionRepo.Save(ctx, subscription)
+}
+
+// remind queues a reminder for the invoice if one is due, to its billing
+// email or, without one, to the organization's admins in their languages
+func (s *DunningService) remind(ctx context.Context, invoice *models.Invoice, subscription *models.Subscription, now time.Time) error {
+	due := s.policy.RemindersDue(invoice.DueDate, now)
+	if due <= invoice.RemindersSent {
+		return nil
+	}
+
+	recipients := []models.User{{Email: invoice.BillingEmail}}
+	if invoice.BillingEmail == "" {
+		admins, err := s.orgRepo.ListAdmins(ctx, invoice.OrganizationID)
+		if err != nil {
+			return err
+		}
+		recipients = admins
+	}
+
+	data := mail.PaymentReminderData{
+		InvoiceNumber: invoice.InvoiceNumber,
+		Amount:        invoice.Amount.String(),
+		DueDate:       invoice.DueDate,
+		GraceEnds:     *subscription.GraceEndsAt,
+		ReadOnly:      subscription.IsReadOnly(),
+		Link:          s.mailService.Link("/billing"),
+	}
+	var errs []error
+	for _, recipient := range recipients {
+		err := s.mailService.Enqueue(ctx, recipient.Email, recipient.Language, mail.TemplatePaymentReminder, data)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	// A reminder is not queued again if some recipients could not be reached
+	if len(errs) < len(recipients) {
+		invoice.RemindersSent = due
+	}
+	return errors.Join(errs...)
+}
+
+// sameTime checks if two optional times are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
@@ -0,0 +1,219 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrFeatureNotEntitled      = errors.New("the organization's plan does not include this feature")
+	ErrFeatureOverrideNotFound = errors.New("feature override not found")
+	ErrInvalidOverrideExpiry   = errors.New("override expiry must be in the future")
+	ErrOverrideReasonRequired  = errors.New("a reason is required for a feature override")
+)
+
+// EntitlementSource tells where an organization's access to a feature comes from
+type EntitlementSource string
+
+const (
+	EntitlementSourcePlan     EntitlementSource = "plan"
+	EntitlementSourceOverride EntitlementSource = "override"
+	EntitlementSourceNone     EntitlementSource = "none"
+)
+
+// Entitlement is whether an organization can use a feature, and why
+type Entitlement struct {
+	models.FeatureInfo
+	Enabled   bool              `json:"enabled"`
+	Source    EntitlementSource `json:"source"`
+	ExpiresAt *time.Time        `json:"expires_at,omitempty"` // when an override ends
+}
+
+// FeatureOverrideInput holds the fields support staff set on an override
+type FeatureOverrideInput struct {
+	Enabled   bool
+	Reason    string
+	ExpiresAt *time.Time
+}
+
+type EntitlementService struct {
+	featureRepo      repositories.FeatureRepository
+	subscriptionRepo repositories.SubscriptionRepository
+	userRepo         repositories.UserRepository
+	orgRepo          repositories.OrganizationRepository
+	access           accessChecker
+	now              func() time.Time
+}
+
+func NewEntitlementService(
+	featureRepo repositories.FeatureRepository,
+	subscriptionRepo repositories.SubscriptionRepository,
+	userRepo repositories.UserRepository,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *EntitlementService {
+	return &EntitlementService{
+		featureRepo:      featureRepo,
+		subscriptionRepo: subscriptionRepo,
+		userRepo:         userRepo,
+		orgRepo:          orgRepo,
+		access:           accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+		now:              time.Now,
+	}
+}
+
+// Entitlements lists every feature in the registry with whether the
+// organization can use it, so clients can hide what it has not paid for
+func (s *EntitlementService) Entitlements(ctx context.Context, userID, orgID uint) ([]Entitlement, error) {
+	if err := s.access.member(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.entitlements(ctx, orgID)
+}
+
+// HasFeature checks if the organization can use a feature: an active
+// override decides, otherwise its active subscription's plan does
+func (s *EntitlementService) HasFeature(ctx context.Context, orgID uint, key string) (bool, error) {
+	if !models.IsKnownFeature(key) {
+		return false, fmt.Errorf("%w: %s", models.ErrUnknownFeature, key)
+	}
+	entitlements, err := s.entitlements(ctx, orgID)
+	if err != nil {
+		return false, err
+	}
+	for _, entitlement := range entitlements {
+		if entitlement.Key == key {
+			return entitlement.Enabled, nil
+		}
+	}
+	return false, nil
+}
+
+// RequireFeature returns ErrFeatureNotEntitled if the organization cannot use
+// the feature. Services call it before work that belongs to a paid feature.
+func (s *EntitlementService) RequireFeature(ctx context.Context, orgID uint, key string) error {
+	enabled, err := s.HasFeature(ctx, orgID, key)
+	if err != nil {
+		return err
+	}
+	if !enabled {
+		return fmt.Errorf("%w: %s", ErrFeatureNotEntitled, key)
+	}
+	return nil
+}
+
+// ListOverrides lists the organization's overrides, including expired ones.
+// Only platform admins can see them.
+func (s *EntitlementService) ListOverrides(ctx context.Context, userID, orgID uint) ([]models.FeatureOverride, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	return s.featureRepo.ListOverrides(ctx, orgID)
+}
+
+// SetOverride grants or withholds a feature for the organization regardless
+// of its plan, replacing any override it had for the feature
+func (s *EntitlementService) SetOverride(ctx context.Context, userID, orgID uint, key string, input FeatureOverrideInput) (*models.FeatureOverride, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	if !models.IsKnownFeature(key) {
+		return nil, fmt.Errorf("%w: %s", models.ErrUnknownFeature, key)
+	}
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, ErrOverrideReasonRequired
+	}
+	if input.ExpiresAt != nil && !input.ExpiresAt.After(s.now()) {
+		return nil, ErrInvalidOverrideExpiry
+	}
+	if _, err := s.orgRepo.FindByID(ctx, orgID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+
+	override := &models.FeatureOverride{
+		OrganizationID: orgID,
+		Feature:        key,
+		Enabled:        input.Enabled,
+		Reason:         reason,
+		SetByID:        userID,
+		ExpiresAt:      input.ExpiresAt,
+	}
+	if err := s.featureRepo.SaveOverride(ctx, override); err != nil {
+		return nil, err
+	}
+	return override, nil
+}
+
+// DeleteOverride removes the organization's override for a feature, so its
+// plan decides again
+func (s *EntitlementService) DeleteOverride(ctx context.Context, userID, orgID uint, key string) error {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return err
+	}
+	deleted, err := s.featureRepo.DeleteOverride(ctx, orgID, key)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrFeatureOverrideNotFound
+	}
+	return nil
+}
+
+// overrideSyncWindow is how far back SyncExpiredOverrides looks for expired
+// overrides, so that a run missed while the server was down is caught up
+const overrideSyncWindow = 24 * time.Hour
+
+// SyncExpiredOverrides hands the feature flags stored on organizations whose
+// overrides have expired back to their plan. HasFeature needs no sync, as it
+// checks expiry itself.
+func (s *EntitlementService) SyncExpiredOverrides(ctx context.Context) error {
+	now := s.now()
+	return s.featureRepo.SyncExpiredOverrides(ctx, now.Add(-overrideSyncWindow), now)
+}
+
+// entitlements works out every feature of the organization from its plan and
+// its active overrides
+func (s *EntitlementService) entitlements(ctx context.Context, orgID uint) ([]Entitlement, error) {
+	subscription, err := s.subscriptionRepo.FindCurrent(ctx, orgID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if subscription != nil && subscription.Status != models.SubscriptionStatusActive {
+		subscription = nil
+	}
+	overrides, err := s.featureRepo.ListOverrides(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	entitlements := make([]Entitlement, 0, len(models.Features))
+	for _, feature := range models.Features {
+		entitlement := Entitlement{FeatureInfo: feature, Source: EntitlementSourceNone}
+		if subscription != nil && subscription.HasFeature(feature.Key) {
+			entitlement.Enabled = true
+			entitlement.Source = EntitlementSourcePlan
+		}
+		for _, override := range overrides {
+			if override.Feature == feature.Key && override.IsActive(now) {
+				entitlement.Enabled = override.Enabled
+				entitlement.Source = EntitlementSourceOverride
+				entitlement.ExpiresAt = override.ExpiresAt
+			}
+		}
+		entitlements = append(entitlements, entitlement)
+	}
+	return entitlements, nil
+}
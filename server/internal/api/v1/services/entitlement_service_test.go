@@ -0,0 +1,90 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// memMemberRepo makes user 1 the only member of every organization
+type memMemberRepo struct {
+	repositories.OrganizationRepository
+}
+
+func (r memMemberRepo) IsMember(ctx context.Context, orgID, userID uint) (bool, error) {
+	return userID == 1, nil
+}
+
+// memSubscriptionRepo returns a single subscription, or none when it is nil
+type memSubscriptionRepo struct {
+	repositories.SubscriptionRepository
+	subscription *models.Subscription
+}
+
+func (r memSubscriptionRepo) FindCurrent(ctx context.Context, orgID uint) (*models.Subscription, error) {
+	if r.subscription == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return r.subscription, nil
+}
+
+type memFeatureRepo struct {
+	repositories.FeatureRepository
+	overrides []models.FeatureOverride
+}
+
+func (r memFeatureRepo) ListOverrides(ctx context.Context, orgID uint) ([]models.FeatureOverride, error) {
+	return r.overrides, nil
+}
+
+func TestFeatureGate(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+	withBudgets := &models.Subscription{
+		Status: models.SubscriptionStatusActive,
+		Plan:   models.Plan{Features: []models.PlanFeature{{Key: models.FeatureBudgets, Included: true}}},
+	}
+	override := func(enabled bool, expiresAt *time.Time) []models.FeatureOverride {
+		return []models.FeatureOverride{{Feature: models.FeatureBudgets, Enabled: enabled, ExpiresAt: expiresAt}}
+	}
+
+	tests := []struct {
+		name         string
+		userID       uint
+		subscription *models.Subscription
+		overrides    []models.FeatureOverride
+		err          error
+	}{
+		{"included in the plan", 1, withBudgets, nil, nil},
+		{"not in the plan", 1, &models.Subscription{Status: models.SubscriptionStatusActive}, nil, ErrFeatureNotEntitled},
+		{"no subscription", 1, nil, nil, ErrFeatureNotEntitled},
+		{"lapsed subscription", 1, &models.Subscription{Status: models.SubscriptionStatusCancelled, Plan: withBudgets.Plan}, nil, ErrFeatureNotEntitled},
+		{"granted by an override", 1, nil, override(true, &later), nil},
+		{"withheld by an override", 1, withBudgets, override(false, nil), ErrFeatureNotEntitled},
+		{"expired override", 1, nil, override(true, &earlier), ErrFeatureNotEntitled},
+		{"not a member", 2, withBudgets, nil, ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entitlements := &EntitlementService{
+				featureRepo:      memFeatureRepo{overrides: tt.overrides},
+				subscriptionRepo: memSubscriptionRepo{subscription: tt.subscription},
+				now:              func() time.Time { return now },
+			}
+			access := accessChecker{orgRepo: memMemberRepo{}, feature: featureGate{entitlements, models.FeatureBudgets}}
+			if err := access.member(context.Background(), 1, tt.userID); !errors.Is(err, tt.err) {
+				t.Errorf("member error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+
+	// Services without a feature let every organization through
+	if err := (accessChecker{orgRepo: memMemberRepo{}}).member(context.Background(), 1, 1); err != nil {
+		t.Errorf("member without a feature = %v", err)
+	}
+}
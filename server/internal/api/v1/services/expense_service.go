@@ -0,0 +1,239 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrExpenseNotFound   = errors.New("expense not found")
+	ErrExpenseNotPending = errors.New("only pending expenses can be changed or reviewed")
+	ErrNotExpenseOwner   = errors.New("only the submitter can change this expense")
+	ErrOwnExpense        = errors.New("you cannot review your own expense")
+)
+
+// ExpenseInput holds the editable fields of an expense. Amount is a decimal in
+// Currency, which must be the currency of the project's budget when given.
+type ExpenseInput struct {
+	TaskID      *uint
+	Category    string
+	Description string
+	Amount      string
+	Currency    string
+	IncurredOn  time.Time
+}
+
+type ExpenseService struct {
+	expenseRepo   repositories.ExpenseRepository
+	taskRepo      repositories.TaskRepository
+	budgetService *BudgetService
+	access        accessChecker
+	now           func() time.Time
+}
+
+func NewExpenseService(
+	expenseRepo repositories.ExpenseRepository,
+	taskRepo repositories.TaskRepository,
+	budgetService *BudgetService,
+	entitlements *EntitlementService,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *ExpenseService {
+	return &ExpenseService{
+		expenseRepo:   expenseRepo,
+		taskRepo:      taskRepo,
+		budgetService: budgetService,
+		access:        accessChecker{orgRepo: orgRepo, projectRepo: projectRepo, feature: featureGate{entitlements, models.FeatureBudgets}},
+		now:           time.Now,
+	}
+}
+
+// CreateExpense submits an expense on a project for approval
+func (s *ExpenseService) CreateExpense(ctx context.Context, userID, projectID uint, input ExpenseInput) (*models.Expense, error) {
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	expense := &models.Expense{
+		OrganizationID: project.OrganizationID,
+		ProjectID:      project.ID,
+		SubmittedByID:  userID,
+		Status:         models.ExpenseStatusPending,
+	}
+	if err := s.apply(ctx, expense, project.Budget.Currency, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.expenseRepo.Create(ctx, expense); err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+// ListExpenses lists a project's expenses, optionally by status. Managers see
+// every expense; other members see the ones they submitted.
+func (s *ExpenseService) ListExpenses(ctx context.Context, userID, projectID uint, status models.ExpenseStatus) ([]models.Expense, error) {
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := repositories.ExpenseFilter{ProjectID: project.ID, Status: status}
+	if err := s.access.manager(ctx, project.OrganizationID, userID, []uint{project.ID}); err != nil {
+		if !errors.Is(err, ErrForbidden) {
+			return nil, err
+		}
+		filter.SubmittedByID = userID
+	}
+	return s.expenseRepo.List(ctx, filter)
+}
+
+// GetExpense returns an expense to its submitter or a manager of its project
+func (s *ExpenseService) GetExpense(ctx context.Context, userID, expenseID uint) (*models.Expense, error) {
+	expense, err := s.loadExpense(ctx, userID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if expense.SubmittedByID != userID {
+		if err := s.access.manager(ctx, expense.OrganizationID, userID, []uint{expense.ProjectID}); err != nil {
+			return nil, err
+		}
+	}
+	return expense, nil
+}
+
+// UpdateExpense changes a pending expense of the user
+func (s *ExpenseService) UpdateExpense(ctx context.Context, userID, expenseID uint, input ExpenseInput) (*models.Expense, error) {
+	expense, err := s.loadOwnPendingExpense(ctx, userID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(ctx, expense, expense.Amount.Currency, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.expenseRepo.Update(ctx, expense); err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+// DeleteExpense withdraws a pending expense of the user
+func (s *ExpenseService) DeleteExpense(ctx context.Context, userID, expenseID uint) error {
+	expense, err := s.loadOwnPendingExpense(ctx, userID, expenseID)
+	if err != nil {
+		return err
+	}
+	return s.expenseRepo.Delete(ctx, expense)
+}
+
+// ApproveExpense approves a pending expense, which counts it as spent and
+// may raise budget alerts
+func (s *ExpenseService) ApproveExpense(ctx context.Context, userID, expenseID uint, note string) (*models.Expense, error) {
+	expense, err := s.review(ctx, userID, expenseID, models.ExpenseStatusApproved, note)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.budgetService.CheckThresholds(ctx, expense.ProjectID); err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+func (s *ExpenseService) RejectExpense(ctx context.Context, userID, expenseID uint, note string) (*models.Expense, error) {
+	return s.review(ctx, userID, expenseID, models.ExpenseStatusRejected, note)
+}
+
+func (s *ExpenseService) review(ctx context.Context, userID, expenseID uint, status models.ExpenseStatus, note string) (*models.Expense, error) {
+	expense, err := s.loadExpense(ctx, userID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.manager(ctx, expense.OrganizationID, userID, []uint{expense.ProjectID}); err != nil {
+		return nil, err
+	}
+	if expense.SubmittedByID == userID {
+		return nil, ErrOwnExpense
+	}
+	if !expense.IsPending() {
+		return nil, ErrExpenseNotPending
+	}
+
+	now := s.now()
+	expense.Status = status
+	expense.ReviewedByID = &userID
+	expense.ReviewedAt = &now
+	expense.ReviewNote = note
+	if err := s.expenseRepo.Update(ctx, expense); err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+// apply sets the editable fields of an expense after checking that its task
+// belongs to its project and its amount is in the project's currency
+func (s *ExpenseService) apply(ctx context.Context, expense *models.Expense, currency string, input ExpenseInput) error {
+	amount, err := parseAmount(input.Amount, input.Currency, currency)
+	if err != nil {
+		return err
+	}
+	if currency != "" && amount.Currency != currency {
+		return money.ErrCurrencyMismatch
+	}
+	if input.TaskID != nil {
+		task, err := s.taskRepo.FindByID(ctx, *input.TaskID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrTaskNotFound
+			}
+			return err
+		}
+		if task.ProjectID != expense.ProjectID {
+			return ErrTaskNotFound
+		}
+	}
+
+	incurredOn := input.IncurredOn.UTC()
+	expense.TaskID = input.TaskID
+	expense.Category = input.Category
+	expense.Description = input.Description
+	expense.Amount = amount
+	expense.IncurredOn = time.Date(incurredOn.Year(), incurredOn.Month(), incurredOn.Day(), 0, 0, 0, 0, time.UTC)
+	return expense.Validate()
+}
+
+// loadExpense loads an expense and checks that the user can access its project
+func (s *ExpenseService) loadExpense(ctx context.Context, userID, expenseID uint) (*models.Expense, error) {
+	expense, err := s.expenseRepo.FindByID(ctx, expenseID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrExpenseNotFound
+		}
+		return nil, err
+	}
+	if _, err := s.access.project(ctx, expense.ProjectID, userID); err != nil {
+		return nil, err
+	}
+	return expense, nil
+}
+
+func (s *ExpenseService) loadOwnPendingExpense(ctx context.Context, userID, expenseID uint) (*models.Expense, error) {
+	expense, err := s.loadExpense(ctx, userID, expenseID)
+	if err != nil {
+		return nil, err
+	}
+	if expense.SubmittedByID != userID {
+		return nil, ErrNotExpenseOwner
+	}
+	if !expense.IsPending() {
+		return nil, ErrExpenseNotPending
+	}
+	return expense, nil
+}
@@ -0,0 +1,249 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/billing"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/tax"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvoiceNotFound      = errors.New("invoice not found")
+	ErrUnknownInvoiceFormat = errors.New("invoices can be rendered as pdf or html")
+)
+
+// InvoiceFormat is a format invoices can be rendered in
+type InvoiceFormat string
+
+const (
+	InvoiceFormatPDF  InvoiceFormat = "pdf"
+	InvoiceFormatHTML InvoiceFormat = "html"
+)
+
+// InvoiceSettings configures how invoices are numbered, taxed and issued
+type InvoiceSettings struct {
+	NumberPerOrganization bool
+	NumberFormat          billing.NumberFormat
+	CreditNoteFormat      billing.NumberFormat
+	DueDays               int
+	Seller                billing.Party
+	SellerCountry         string
+	Tax                   *tax.Rules
+}
+
+type InvoiceService struct {
+	invoiceRepo repositories.InvoiceRepository
+	addOnRepo   repositories.AddOnRepository
+	couponRepo  repositories.CouponRepository
+	orgRepo     repositories.OrganizationRepository
+	access      accessChecker
+	settings    InvoiceSettings
+	now         func() time.Time
+}
+
+func NewInvoiceService(
+	invoiceRepo repositories.InvoiceRepository,
+	addOnRepo repositories.AddOnRepository,
+	couponRepo repositories.CouponRepository,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+	settings InvoiceSettings,
+) *InvoiceService {
+	return &InvoiceService{
+		invoiceRepo: invoiceRepo,
+		addOnRepo:   addOnRepo,
+		couponRepo:  couponRepo,
+		orgRepo:     orgRepo,
+		access:      accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+		settings:    settings,
+		now:         time.Now,
+	}
+}
+
+// ListInvoices lists the organization's invoices, newest first
+func (s *InvoiceService) ListInvoices(ctx context.Context, userID, orgID uint) ([]models.Invoice, error) {
+	if err := s.access.admin(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.invoiceRepo.ListByOrganization(ctx, orgID)
+}
+
+// GetInvoice returns an invoice with its items
+func (s *InvoiceService) GetInvoice(ctx context.Context, userID, invoiceID uint) (*models.Invoice, error) {
+	invoice, err := s.invoiceRepo.FindByID(ctx, invoiceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.admin(ctx, invoice.OrganizationID, userID); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// RenderInvoice writes the invoice as a PDF or HTML document
+func (s *InvoiceService) RenderInvoice(ctx context.Context, userID, invoiceID uint, format InvoiceFormat, w io.Writer) (*models.Invoice, error) {
+	if format != InvoiceFormatPDF && format != InvoiceFormatHTML {
+		return nil, ErrUnknownInvoiceFormat
+	}
+	invoice, err := s.GetInvoice(ctx, userID, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := billing.Document{Invoice: invoice, Seller: s.settings.Seller}
+	if format == InvoiceFormatPDF {
+		return invoice, billing.RenderPDF(w, doc)
+	}
+	return invoice, billing.RenderHTML(w, doc)
+}
+
+// RunBilling issues invoices for the subscriptions whose current period has
+// started and not been billed, and returns the number issued. Each invoice
+// bills the period in advance: the plan, extra seats and add-ons, together
+// with items left pending since the last invoice such as prorated charges and
+// account credit. A running coupon discount is taken off the charges, and tax
+// is charged according to the organization's country and tax ID.
+// A failure on one subscription does not stop the others; it is retried on
+// the next run.
+func (s *InvoiceService) RunBilling(ctx context.Context) (int, error) {
+	now := s.now().UTC()
+	due, err := s.invoiceRepo.ListDueSubscriptions(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+
+	issued := 0
+	var errs []error
+	for i := range due {
+		ok, err := s.bill(ctx, &due[i], now)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			issued++
+		}
+	}
+	return issued, errors.Join(errs...)
+}
+
+// bill issues the invoice for the subscription's current period, reporting
+// whether one was issued. A period with nothing to charge is only marked billed.
+func (s *InvoiceService) bill(ctx context.Context, subscription *models.Subscription, now time.Time) (bool, error) {
+	period := currentPeriod(subscription)
+	currency := subscription.Plan.Price.Currency
+
+	items, err := billing.PeriodItems(&subscription.Plan, subscription.Seats, period)
+	if err != nil {
+		return false, err
+	}
+	addOns, err := s.addOnRepo.ListByOrganization(ctx, subscription.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+	addOnItems, err := billing.AddOnItems(addOns, currency, period)
+	if err != nil {
+		return false, err
+	}
+	items = subscriptionItems(subscription, append(items, addOnItems...))
+
+	pending, err := s.invoiceRepo.ListPendingItems(ctx, subscription.OrganizationID, currency)
+	if err != nil {
+		return false, err
+	}
+	items = append(items, pending...)
+	if len(items) == 0 {
+		_, err := s.invoiceRepo.MarkBilled(ctx, subscription.ID, period.Start, now)
+		return false, err
+	}
+
+	redemption, err := s.couponRepo.FindActiveRedemption(ctx, subscription.OrganizationID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	if redemption != nil {
+		discount, err := billing.DiscountItem(redemption, items, currency, period)
+		if err != nil {
+			return false, err
+		}
+		if discount != nil {
+			items = append(items, subscriptionItems(subscription, []models.InvoiceItem{*discount})...)
+		}
+	}
+
+	org, err := s.orgRepo.FindByID(ctx, subscription.OrganizationID)
+	if err != nil {
+		return false, err
+	}
+	treatment := s.settings.Tax.Determine(s.settings.SellerCountry, org.BillingCountry, org.TaxID)
+	taxes, err := billing.ApplyTax(items, treatment, currency)
+	if err != nil {
+		return false, err
+	}
+	total, err := taxes.Total(treatment.Inclusive)
+	if err != nil {
+		return false, err
+	}
+	for _, item := range items {
+		if item.Kind == models.InvoiceItemKindCredit {
+			if total, err = total.Add(item.Amount); err != nil {
+				return false, err
+			}
+		}
+	}
+	var carried []models.InvoiceItem
+	if line, credit := billing.CarryCredit(total, period); line != nil {
+		items = append(items, subscriptionItems(subscription, []models.InvoiceItem{*line})...)
+		carried = subscriptionItems(subscription, []models.InvoiceItem{*credit})
+		total = money.Zero(currency)
+	}
+
+	invoice := &models.Invoice{
+		OrganizationID: subscription.OrganizationID,
+		SubscriptionID: subscription.ID,
+		Amount:         total,
+		AmountRefunded: money.Zero(currency),
+		IssuedAt:       now,
+		PeriodStart:    period.Start,
+		PeriodEnd:      period.End,
+		DueDate:        now.AddDate(0, 0, s.settings.DueDays),
+		Status:         models.PaymentStatusPending,
+		BillingName:    org.BillingName,
+		BillingEmail:   org.BillingEmail,
+		BillingAddress: org.BillingAddress,
+		BillingCountry: org.BillingCountry,
+		TaxID:          org.TaxID,
+		Subtotal:       taxes.Subtotal,
+		TaxAmount:      taxes.Tax,
+		TaxCountry:     treatment.Country,
+		TaxInclusive:   treatment.Inclusive,
+		ReverseCharge:  treatment.ReverseCharge,
+		TaxNote:        treatment.Note,
+		Taxes:          taxes.Breakdown,
+		PaymentMethod:  models.PaymentMethod(subscription.PaymentMethod),
+		Items:          items,
+	}
+	if invoice.BillingName == "" {
+		invoice.BillingName = org.Name
+	}
+	if total.IsZero() {
+		// Nothing is left to collect
+		invoice.Status = models.PaymentStatusSucceeded
+		invoice.PaidAt = &now
+	}
+
+	scope := billing.SequenceScope(s.settings.NumberPerOrganization, subscription.OrganizationID)
+	return s.invoiceRepo.Issue(ctx, invoice, scope, func(seq int64) string {
+		return s.settings.NumberFormat.Format(seq, subscription.OrganizationID, now)
+	}, carried)
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrLabelNotFound = errors.New("label not found")
+	ErrLabelExists   = errors.New("a label with this name already exists")
+)
+
+// LabelInput holds the editable fields of a label
+type LabelInput struct {
+	Name        string
+	Color       string
+	Description string
+}
+
+type LabelService struct {
+	labelRepo repositories.LabelRepository
+	taskRepo  repositories.TaskRepository
+	access    accessChecker
+}
+
+func NewLabelService(
+	labelRepo repositories.LabelRepository,
+	taskRepo repositories.TaskRepository,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *LabelService {
+	return &LabelService{
+		labelRepo: labelRepo,
+		taskRepo:  taskRepo,
+		access:    accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+	}
+}
+
+func (s *LabelService) CreateLabel(ctx context.Context, userID, orgID uint, input LabelInput) (*models.Label, error) {
+	if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNameFree(ctx, orgID, input.Name, 0); err != nil {
+		return nil, err
+	}
+
+	label := &models.Label{
+		OrganizationID: orgID,
+		Name:           input.Name,
+		Color:          input.Color,
+		Description:    input.Description,
+	}
+	if err := s.labelRepo.Create(ctx, label); err != nil {
+		return nil, err
+	}
+	return label, nil
+}
+
+func (s *LabelService) ListLabels(ctx context.Context, userID, orgID uint) ([]models.Label, error) {
+	if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.labelRepo.ListByOrganization(ctx, orgID)
+}
+
+func (s *LabelService) UpdateLabel(ctx context.Context, userID, labelID uint, input LabelInput) (*models.Label, error) {
+	label, err := s.loadLabel(ctx, userID, labelID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkNameFree(ctx, label.OrganizationID, input.Name, label.ID); err != nil {
+		return nil, err
+	}
+
+	label.Name = input.Name
+	label.Color = input.Color
+	label.Description = input.Description
+	if err := s.labelRepo.Update(ctx, label); err != nil {
+		return nil, err
+	}
+	return label, nil
+}
+
+func (s *LabelService) DeleteLabel(ctx context.Context, userID, labelID uint) error {
+	label, err := s.loadLabel(ctx, userID, labelID)
+	if err != nil {
+		return err
+	}
+	return s.labelRepo.Delete(ctx, label.ID)
+}
+
+// SetTaskLabels replaces the labels of a task
+func (s *LabelService) SetTaskLabels(ctx context.Context, userID, taskID uint, labelIDs []uint) ([]models.Label, error) {
+	task, err := s.taskRepo.FindByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	project, err := s.access.project(ctx, task.ProjectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	labelIDs = uniqueIDs(labelIDs)
+	labels, err := s.labelRepo.FindByIDs(ctx, labelIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(labels) != len(labelIDs) {
+		return nil, ErrLabelNotFound
+	}
+	for _, label := range labels {
+		if label.OrganizationID != project.OrganizationID {
+			return nil, ErrLabelNotFound
+		}
+	}
+
+	if err := s.labelRepo.ReplaceTaskLabels(ctx, task.ID, labelIDs); err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// loadLabel loads a label and checks the user's access to its organization
+func (s *LabelService) loadLabel(ctx context.Context, userID, labelID uint) (*models.Label, error) {
+	label, err := s.labelRepo.FindByID(ctx, labelID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrLabelNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.organization(ctx, label.OrganizationID, userID); err != nil {
+		return nil, err
+	}
+	return label, nil
+}
+
+// checkNameFree ensures no other label in the organization uses the name
+func (s *LabelService) checkNameFree(ctx context.Context, orgID uint, name string, exceptID uint) error {
+	existing, err := s.labelRepo.FindByNames(ctx, orgID, []string{name})
+	if err != nil {
+		return err
+	}
+	for _, label := range existing {
+		if label.ID != exceptID {
+			return ErrLabelExists
+		}
+	}
+	return nil
+}
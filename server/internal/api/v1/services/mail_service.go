@@ -0,0 +1,167 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/mail"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+)
+
+const (
+	// outboxBatchSize is the most emails one run of the worker sends
+	outboxBatchSize = 100
+	// outboxMaxAttempts is how many times an email is tried before the
+	// outbox gives up on it. With the backoff below, that is about 20 hours.
+	outboxMaxAttempts = 12
+	outboxFirstRetry  = time.Minute
+	outboxMaxRetry    = 6 * time.Hour
+	// outboxRetention is how long sent emails are kept
+	outboxRetention = 30 * 24 * time.Hour
+)
+
+// MailSettings holds what emails link to
+type MailSettings struct {
+	FrontendURL string // base URL of the web app
+	PublicURL   string // base URL of this server, for links that work without the web app
+	SigningKey  []byte // signs unsubscribe links
+}
+
+// MailService queues emails in the outbox, rendered in the recipient's
+// language, and delivers them in the background
+type MailService struct {
+	outboxRepo repositories.OutboxRepository
+	templates  *mail.Templates
+	mailer     mail.Mailer
+	settings   MailSettings
+	now        func() time.Time
+}
+
+func NewMailService(
+	outboxRepo repositories.OutboxRepository,
+	templates *mail.Templates,
+	mailer mail.Mailer,
+	settings MailSettings,
+) *MailService {
+	settings.FrontendURL = strings.TrimSuffix(settings.FrontendURL, "/")
+	settings.PublicURL = strings.TrimSuffix(settings.PublicURL, "/")
+	return &MailService{
+		outboxRepo: outboxRepo,
+		templates:  templates,
+		mailer:     mailer,
+		settings:   settings,
+		now:        time.Now,
+	}
+}
+
+// Link returns the URL of a page of the web app, such as "/billing"
+func (s *MailService) Link(path string) string {
+	return s.settings.FrontendURL + path
+}
+
+// UnsubscribeLink returns the signed link that unsubscribes the user from
+// digests without them logging in
+func (s *MailService) UnsubscribeLink(userID uint) string {
+	return s.settings.PublicURL + "/api/v1/digest/unsubscribe?token=" + url.QueryEscape(mail.UnsubscribeToken(s.settings.SigningKey, userID))
+}
+
+// ParseUnsubscribeToken returns the user an unsubscribe link was made for
+func (s *MailService) ParseUnsubscribeToken(token string) (uint, error) {
+	return mail.ParseUnsubscribeToken(s.settings.SigningKey, token)
+}
+
+// Render renders an email for the recipient in their language, ready to be
+// put in the outbox
+func (s *MailService) Render(to, language, template string, data interface{}) (*models.OutboxEmail, error) {
+	msg, err := s.templates.Render(template, language, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s email: %w", template, err)
+	}
+	return &models.OutboxEmail{
+		To:            to,
+		Template:      template,
+		Subject:       msg.Subject,
+		TextBody:      msg.Text,
+		HTMLBody:      msg.HTML,
+		Status:        models.OutboxStatusPending,
+		NextAttemptAt: s.now(),
+	}, nil
+}
+
+// Enqueue renders an email and puts it in the outbox to be sent
+func (s *MailService) Enqueue(ctx context.Context, to, language, template string, data interface{}) error {
+	email, err := s.Render(to, language, template, data)
+	if err != nil {
+		return err
+	}
+	return s.outboxRepo.Enqueue(ctx, email)
+}
+
+// DeliverPending sends the emails in the outbox that are due and returns the
+// number sent. A failed email is tried again later, waiting twice as long
+// each time, until it fails permanently or runs out of attempts.
+func (s *MailService) DeliverPending(ctx context.Context) (int, error) {
+	emails, err := s.outboxRepo.ListDue(ctx, s.now(), outboxBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var errs []error
+	for i := range emails {
+		email := &emails[i]
+		sendErr := s.mailer.Send(ctx, &mail.Message{
+			To:             email.To,
+			Subject:        email.Subject,
+			Text:           email.TextBody,
+			HTML:           email.HTMLBody,
+			UnsubscribeURL: email.UnsubscribeURL,
+		})
+
+		now := s.now()
+		email.Attempts++
+		if sendErr == nil {
+			email.Status = models.OutboxStatusSent
+			email.SentAt = &now
+			email.LastError = ""
+			sent++
+		} else {
+			email.LastError = sendErr.Error()
+			if mail.IsPermanent(sendErr) || email.Attempts >= outboxMaxAttempts {
+				email.Status = models.OutboxStatusFailed
+				log.Printf("Gave up sending %s email %d to %s after %d attempts: %v", email.Template, email.ID, email.To, email.Attempts, sendErr)
+			} else {
+				email.NextAttemptAt = now.Add(outboxBackoff(email.Attempts))
+			}
+		}
+		if err := s.outboxRepo.Save(ctx, email); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return sent, errors.Join(errs...)
+}
+
+// PruneSent deletes the emails sent longer ago than they are kept for and
+// returns the number deleted
+func (s *MailService) PruneSent(ctx context.Context) (int64, error) {
+	return s.outboxRepo.DeleteSentBefore(ctx, s.now().Add(-outboxRetention))
+}
+
+// outboxBackoff is how long to wait before trying an email again after the
+// given number of attempts
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxFirstRetry
+	for i := 1; i < attempts && delay < outboxMaxRetry; i++ {
+		delay *= 2
+	}
+	if delay > outboxMaxRetry {
+		delay = outboxMaxRetry
+	}
+	return delay
+}
@@ -0,0 +1,346 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	chorvomail "github.com/0-jagadeesh-0/chorvo/internal/mail"
+)
+
+// memOutboxRepo keeps the outbox in memory
+type memOutboxRepo struct {
+	emails []models.OutboxEmail
+}
+
+func (r *memOutboxRepo) Enqueue(ctx context.Context, email *models.OutboxEmail) error {
+	email.ID = uint(len(r.emails) + 1)
+	r.emails = append(r.emails, *email)
+	return nil
+}
+
+func (r *memOutboxRepo) ListDue(ctx context.Context, now time.Time, limit int) ([]models.OutboxEmail, error) {
+	var due []models.OutboxEmail
+	for _, email := range r.emails {
+		if email.Status == models.OutboxStatusPending && !email.NextAttemptAt.After(now) && len(due) < limit {
+			due = append(due, email)
+		}
+	}
+	return due, nil
+}
+
+func (r *memOutboxRepo) Save(ctx context.Context, email *models.OutboxEmail) error {
+	r.emails[email.ID-1] = *email
+	return nil
+}
+
+func (r *memOutboxRepo) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	return 0, nil
+}
+
+// smtpServer is a minimal SMTP server on a local port. It answers RCPT TO
+// for a recipient with the reply set for it, once per queued reply, and
+// accepts everyone else.
+type smtpServer struct {
+	listener net.Listener
+	mu       sync.Mutex
+	replies  map[string][]string // by recipient address
+	received []smtpDelivery
+	wg       sync.WaitGroup
+}
+
+type smtpDelivery struct {
+	from, to string
+	data     []byte
+}
+
+func newSMTPServer(t *testing.T) *smtpServer {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listening: %v", err)
+	}
+	server := &smtpServer{listener: listener, replies: make(map[string][]string)}
+	server.wg.Add(1)
+	go server.serve()
+	t.Cleanup(func() {
+		listener.Close()
+		server.wg.Wait()
+	})
+	return server
+}
+
+func (s *smtpServer) port() string {
+	_, port, _ := net.SplitHostPort(s.listener.Addr().String())
+	return port
+}
+
+// reply queues a reply to the next RCPT TO for the address
+func (s *smtpServer) reply(address, reply string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies[address] = append(s.replies[address], reply)
+}
+
+func (s *smtpServer) deliveries() []smtpDelivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]smtpDelivery(nil), s.received...)
+}
+
+func (s *smtpServer) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer conn.Close()
+			conn.SetDeadline(time.Now().Add(10 * time.Second))
+			s.session(conn)
+		}()
+	}
+}
+
+func (s *smtpServer) session(conn net.Conn) {
+	r := bufio.NewReader(conn)
+	reply := func(line string) {
+		io.WriteString(conn, line+"\r\n")
+	}
+	reply("220 localhost ESMTP test")
+
+	var delivery smtpDelivery
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			delivery = smtpDelivery{from: strings.Trim(line[len("MAIL FROM:"):], "<> ")}
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			delivery.to = strings.Trim(line[len("RCPT TO:"):], "<> ")
+			s.mu.Lock()
+			queued := s.replies[delivery.to]
+			answer := "250 OK"
+			if len(queued) > 0 {
+				answer, s.replies[delivery.to] = queued[0], queued[1:]
+			}
+			s.mu.Unlock()
+			reply(answer)
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(line, "."))
+			}
+			delivery.data = []byte(data.String())
+			s.mu.Lock()
+			s.received = append(s.received, delivery)
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// parsedEmail is a delivered message with its decoded bodies
+type parsedEmail struct {
+	subject string
+	text    string
+	html    string
+}
+
+func parseDelivery(t *testing.T, data []byte) parsedEmail {
+	t.Helper()
+	msg, err := mail.ReadMessage(strings.NewReader(string(data)))
+	if err != nil {
+		t.Fatalf("reading message: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decoding subject: %v", err)
+	}
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("content type = %q, %v, want multipart/alternative", mediaType, err)
+	}
+
+	parsed := parsedEmail{subject: subject}
+	parts := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := parts.NextRawPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		if encoding := part.Header.Get("Content-Transfer-Encoding"); encoding != "quoted-printable" {
+			t.Errorf("part encoding = %q", encoding)
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatalf("decoding part: %v", err)
+		}
+		switch part.Header.Get("Content-Type") {
+		case "text/plain; charset=utf-8":
+			parsed.text = string(body)
+		case "text/html; charset=utf-8":
+			parsed.html = string(body)
+		default:
+			t.Errorf("unexpected part %q", part.Header.Get("Content-Type"))
+		}
+	}
+	return parsed
+}
+
+func TestDeliverPendingOverSMTP(t *testing.T) {
+	server := newSMTPServer(t)
+	mailer, err := chorvomail.NewSMTPMailer(chorvomail.SMTPConfig{Host: "127.0.0.1", Port: server.port(), From: "Chorvo <noreply@chorvo.test>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	templates, err := chorvomail.LoadTemplates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	outbox := &memOutboxRepo{}
+	service := NewMailService(outbox, templates, mailer, MailSettings{})
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time { return now }
+
+	users := []models.User{
+		{Email: "ana@example.com", FirstName: "Ana", Language: "en"},
+		{Email: "jonas@example.com", FirstName: "Jonas", Language: "de-AT"},
+		{Email: "busy@example.com", FirstName: "Bea", Language: "es"},
+		{Email: "gone@example.com", FirstName: "Gus", Language: "en"},
+	}
+	for _, user := range users {
+		err := service.Enqueue(context.Background(), user.Email, user.Language, chorvomail.TemplateVerification, chorvomail.VerificationData{Name: user.FirstName, Code: "482910"})
+		if err != nil {
+			t.Fatalf("Enqueue for %s: %v", user.Email, err)
+		}
+	}
+	server.reply("busy@example.com", "451 4.3.0 Mailbox temporarily unavailable")
+	server.reply("gone@example.com", "550 5.1.1 No such user")
+
+	sent, err := service.DeliverPending(context.Background())
+	if err != nil || sent != 2 {
+		t.Fatalf("DeliverPending = %d, %v, want 2 sent", sent, err)
+	}
+
+	byRecipient := make(map[string]parsedEmail)
+	for _, delivery := range server.deliveries() {
+		if delivery.from != "noreply@chorvo.test" {
+			t.Errorf("sender = %q", delivery.from)
+		}
+		byRecipient[delivery.to] = parseDelivery(t, delivery.data)
+	}
+	if len(byRecipient) != 2 {
+		t.Fatalf("delivered to %d recipients, want 2", len(byRecipient))
+	}
+	english, german := byRecipient["ana@example.com"], byRecipient["jonas@example.com"]
+	if english.subject != "Verify your email - Chorvo" || !strings.Contains(english.text, "Hi Ana,") || !strings.Contains(english.text, "482910") {
+		t.Errorf("English email = %q\n%s", english.subject, english.text)
+	}
+	if !strings.Contains(english.html, `<html lang="en">`) || !strings.Contains(english.html, "<strong>482910</strong>") {
+		t.Errorf("English HTML body = %s", english.html)
+	}
+	if german.subject != "Bestätige deine E-Mail-Adresse - Chorvo" || !strings.Contains(german.text, "Hallo Jonas,") {
+		t.Errorf("German email = %q\n%s", german.subject, german.text)
+	}
+	if !strings.Contains(german.html, `<html lang="de">`) || !strings.Contains(german.html, "Dein Bestätigungscode lautet") {
+		t.Errorf("German HTML body = %s", german.html)
+	}
+
+	status := func(id uint) models.OutboxEmail { return outbox.emails[id-1] }
+	for _, id := range []uint{1, 2} {
+		if email := status(id); email.Status != models.OutboxStatusSent || email.SentAt == nil || email.Attempts != 1 {
+			t.Errorf("email %d = %s, sent at %v, %d attempts", id, email.Status, email.SentAt, email.Attempts)
+		}
+	}
+	busy := status(3)
+	if busy.Status != models.OutboxStatusPending || busy.Attempts != 1 || !busy.NextAttemptAt.Equal(now.Add(outboxFirstRetry)) || !strings.Contains(busy.LastError, "451") {
+		t.Errorf("temporarily failed email = %s, %d attempts, next at %v, error %q", busy.Status, busy.Attempts, busy.NextAttemptAt, busy.LastError)
+	}
+	if gone := status(4); gone.Status != models.OutboxStatusFailed || gone.Attempts != 1 || !strings.Contains(gone.LastError, "550") {
+		t.Errorf("rejected email = %s, %d attempts, error %q", gone.Status, gone.Attempts, gone.LastError)
+	}
+
+	// Nothing is due until the backoff has passed
+	now = now.Add(outboxFirstRetry - time.Second)
+	if sent, err := service.DeliverPending(context.Background()); sent != 0 || err != nil || len(server.deliveries()) != 2 {
+		t.Errorf("DeliverPending before the retry = %d, %v, %d delivered", sent, err, len(server.deliveries()))
+	}
+
+	// The second 4xx doubles the wait
+	server.reply("busy@example.com", "421 4.7.0 Try again later")
+	now = now.Add(time.Second)
+	if sent, err := service.DeliverPending(context.Background()); sent != 0 || err != nil {
+		t.Errorf("second attempt = %d, %v", sent, err)
+	}
+	if busy := status(3); busy.Attempts != 2 || !busy.NextAttemptAt.Equal(now.Add(2*outboxFirstRetry)) {
+		t.Errorf("after a second failure = %d attempts, next at %v", busy.Attempts, busy.NextAttemptAt)
+	}
+
+	now = now.Add(2 * outboxFirstRetry)
+	if sent, err := service.DeliverPending(context.Background()); sent != 1 || err != nil {
+		t.Fatalf("third attempt = %d, %v, want 1 sent", sent, err)
+	}
+	if busy := status(3); busy.Status != models.OutboxStatusSent || busy.Attempts != 3 || busy.LastError != "" {
+		t.Errorf("retried email = %s, %d attempts, error %q", busy.Status, busy.Attempts, busy.LastError)
+	}
+	spanish := parseDelivery(t, server.deliveries()[2].data)
+	if spanish.subject != "Verifica tu correo electrónico - Chorvo" || !strings.Contains(spanish.html, `<html lang="es">`) {
+		t.Errorf("Spanish email = %q\n%s", spanish.subject, spanish.html)
+	}
+}
+
+func TestOutboxBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, 6 * time.Hour},
+		{outboxMaxAttempts, 6 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got := outboxBackoff(tt.attempts); got != tt.want {
+			t.Errorf("outboxBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
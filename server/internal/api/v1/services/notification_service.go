@@ -0,0 +1,289 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/mail"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+const (
+	defaultNotificationPageSize = 50
+	maxNotificationPageSize     = 200
+
+	// dueSoonHorizon is how long before a task is due its assignee is told
+	dueSoonHorizon = 24 * time.Hour
+
+	// notificationEmailBatchSize is the most notifications one run puts in
+	// the email outbox
+	notificationEmailBatchSize = 200
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// NotificationPage is one page of a user's inbox. NextCursor is empty on the last page.
+type NotificationPage struct {
+	Notifications []models.Notification `json:"notifications"`
+	UnreadCount   int64                 `json:"unread_count"`
+	NextCursor    string                `json:"next_cursor,omitempty"`
+}
+
+// NotificationSetting is whether one type of notification is sent on one channel
+type NotificationSetting struct {
+	Type    models.NotificationType    `json:"type"`
+	Channel models.NotificationChannel `json:"channel"`
+	Enabled bool                       `json:"enabled"`
+}
+
+type NotificationService struct {
+	notificationRepo repositories.NotificationRepository
+	userRepo         repositories.UserRepository
+	mailService      *MailService
+	now              func() time.Time
+}
+
+func NewNotificationService(
+	notificationRepo repositories.NotificationRepository,
+	userRepo repositories.UserRepository,
+	mailService *MailService,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepo: notificationRepo,
+		userRepo:         userRepo,
+		mailService:      mailService,
+		now:              time.Now,
+	}
+}
+
+// ListNotifications fetches one page of the user's inbox newest first. The
+// cursor is the ID of the last notification of the previous page.
+func (s *NotificationService) ListNotifications(ctx context.Context, userID uint, unreadOnly bool, cursor string, limit int) (*NotificationPage, error) {
+	filter := repositories.NotificationFilter{UserID: userID, UnreadOnly: unreadOnly}
+	if cursor != "" {
+		beforeID, err := strconv.ParseUint(cursor, 10, 64)
+		if err != nil || beforeID == 0 {
+			return nil, ErrInvalidCursor
+		}
+		filter.BeforeID = uint(beforeID)
+	}
+
+	if limit <= 0 {
+		limit = defaultNotificationPageSize
+	}
+	if limit > maxNotificationPageSize {
+		limit = maxNotificationPageSize
+	}
+
+	// Fetch one extra notification to know whether another page follows
+	filter.Limit = limit + 1
+	notifications, err := s.notificationRepo.List(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	unread, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &NotificationPage{Notifications: notifications, UnreadCount: unread}
+	if len(notifications) > limit {
+		page.Notifications = notifications[:limit]
+		page.NextCursor = strconv.FormatUint(uint64(page.Notifications[limit-1].ID), 10)
+	}
+	return page, nil
+}
+
+// CountUnread returns the number of unread notifications in the user's inbox
+func (s *NotificationService) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	return s.notificationRepo.CountUnread(ctx, userID)
+}
+
+// MarkRead marks one of the user's notifications read
+func (s *NotificationService) MarkRead(ctx context.Context, userID, notificationID uint) (*models.Notification, error) {
+	notification, err := s.getNotification(ctx, userID, notificationID)
+	if err != nil {
+		return nil, err
+	}
+	if notification.IsRead() {
+		return notification, nil
+	}
+	if _, err := s.notificationRepo.MarkRead(ctx, userID, []uint{notification.ID}, s.now()); err != nil {
+		return nil, err
+	}
+	return s.getNotification(ctx, userID, notificationID)
+}
+
+// MarkUnread marks one of the user's notifications unread again
+func (s *NotificationService) MarkUnread(ctx context.Context, userID, notificationID uint) (*models.Notification, error) {
+	notification, err := s.getNotification(ctx, userID, notificationID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.notificationRepo.MarkUnread(ctx, userID, notification.ID); err != nil {
+		return nil, err
+	}
+	notification.ReadAt = nil
+	return notification, nil
+}
+
+// MarkAllRead marks the user's notifications with the given IDs read, or all
+// of them when no IDs are given, and returns the number marked. IDs of
+// notifications that are read already or are not the user's are skipped.
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID uint, ids []uint) (int64, error) {
+	return s.notificationRepo.MarkRead(ctx, userID, ids, s.now())
+}
+
+// GetPreferences lists every type of notification on every channel with
+// whether the user gets it
+func (s *NotificationService) GetPreferences(ctx context.Context, userID uint) ([]NotificationSetting, error) {
+	preferences, err := s.notificationRepo.ListPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := make([]NotificationSetting, 0, len(models.NotificationTypes)*len(models.NotificationChannels))
+	for _, info := range models.NotificationTypes {
+		enabled := models.ChannelsEnabled(preferences, info.Type)
+		for _, channel := range models.NotificationChannels {
+			settings = append(settings, NotificationSetting{
+				Type:    info.Type,
+				Channel: channel,
+				Enabled: enabled[channel],
+			})
+		}
+	}
+	return settings, nil
+}
+
+// UpdatePreferences turns types of notification on or off on channels for
+// the user. Settings not given are left as they are.
+func (s *NotificationService) UpdatePreferences(ctx context.Context, userID uint, settings []NotificationSetting) ([]NotificationSetting, error) {
+	preferences := make([]models.NotificationPreference, 0, len(settings))
+	for _, setting := range settings {
+		preference := models.NotificationPreference{
+			UserID:  userID,
+			Type:    setting.Type,
+			Channel: setting.Channel,
+			Enabled: setting.Enabled,
+		}
+		if err := preference.Validate(); err != nil {
+			return nil, err
+		}
+		preferences = append(preferences, preference)
+	}
+
+	if err := s.notificationRepo.SavePreferences(ctx, preferences); err != nil {
+		return nil, err
+	}
+	return s.GetPreferences(ctx, userID)
+}
+
+// NotifyDueSoon tells assignees of the tasks due within the next day that
+// they are due, once per due date, and returns the number notified
+func (s *NotificationService) NotifyDueSoon(ctx context.Context) (int, error) {
+	now := s.now().UTC()
+	tasks, err := s.notificationRepo.ListDueSoon(ctx, now, now.Add(dueSoonHorizon))
+	if err != nil {
+		return 0, err
+	}
+
+	notified := 0
+	var errs []error
+	for _, task := range tasks {
+		projectID, taskID := task.ProjectID, task.ID
+		dueDate := task.DueDate.UTC()
+		notification := &models.Notification{
+			UserID:    *task.AssigneeID,
+			Type:      models.NotificationTypeDueSoon,
+			ProjectID: &projectID,
+			TaskID:    &taskID,
+			Message:   fmt.Sprintf("\"%s\" is due %s", task.Title, dueDate.Format("Jan 2, 2006 15:04 MST")),
+			// A task whose due date moves is due soon again
+			DedupeKey: fmt.Sprintf("due_soon:task:%d:%s", task.ID, dueDate.Format(time.RFC3339)),
+		}
+		if err := s.notificationRepo.Notify(ctx, notification); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if notification.ID != 0 {
+			notified++
+		}
+	}
+	return notified, errors.Join(errs...)
+}
+
+// QueueEmails puts the notifications to be sent by email in the outbox, in
+// their users' languages, and returns the number queued
+func (s *NotificationService) QueueEmails(ctx context.Context) (int, error) {
+	notifications, err := s.notificationRepo.ListUnemailed(ctx, notificationEmailBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	queued := 0
+	users := make(map[uint]*models.User)
+	var errs []error
+	for i := range notifications {
+		notification := &notifications[i]
+		user, ok := users[notification.UserID]
+		if !ok {
+			if user, err = s.userRepo.FindByID(ctx, notification.UserID); err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				errs = append(errs, err)
+				continue
+			}
+			users[notification.UserID] = user
+		}
+
+		// Users deleted since are not sent anything
+		var email *models.OutboxEmail
+		if user != nil {
+			email, err = s.mailService.Render(user.Email, user.Language, mail.TemplateNotification, mail.NotificationData{
+				Name:    user.FirstName,
+				Message: notification.Message,
+				Link:    s.mailService.Link(notificationPath(notification)),
+			})
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+		}
+		if err := s.notificationRepo.QueueEmail(ctx, notification.ID, email, s.now()); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if email != nil {
+			queued++
+		}
+	}
+	return queued, errors.Join(errs...)
+}
+
+// notificationPath is the page of the web app a notification links to
+func notificationPath(notification *models.Notification) string {
+	switch {
+	case notification.TaskID != nil:
+		return fmt.Sprintf("/tasks/%d", *notification.TaskID)
+	case notification.ProjectID != nil:
+		return fmt.Sprintf("/projects/%d", *notification.ProjectID)
+	case notification.OrganizationID != nil:
+		return fmt.Sprintf("/organizations/%d", *notification.OrganizationID)
+	}
+	return "/notifications"
+}
+
+func (s *NotificationService) getNotification(ctx context.Context, userID, notificationID uint) (*models.Notification, error) {
+	notification, err := s.notificationRepo.FindByID(ctx, userID, notificationID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrNotificationNotFound
+		}
+		return nil, err
+	}
+	return notification, nil
+}
@@ -0,0 +1,223 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+)
+
+// memNotificationRepo keeps one user's preferences and inbox, newest first,
+// and stores the notifications it is asked to send once per dedupe key
+type memNotificationRepo struct {
+	repositories.NotificationRepository
+	preferences []models.NotificationPreference
+	inbox       []models.Notification
+	dueSoon     []models.Task
+	sent        []models.Notification
+}
+
+func (r *memNotificationRepo) ListPreferences(ctx context.Context, userID uint) ([]models.NotificationPreference, error) {
+	return r.preferences, nil
+}
+
+// SavePreferences replaces the preferences for the same type and channel
+func (r *memNotificationRepo) SavePreferences(ctx context.Context, preferences []models.NotificationPreference) error {
+	for _, preference := range preferences {
+		saved := false
+		for i := range r.preferences {
+			if r.preferences[i].Type == preference.Type && r.preferences[i].Channel == preference.Channel {
+				r.preferences[i], saved = preference, true
+			}
+		}
+		if !saved {
+			r.preferences = append(r.preferences, preference)
+		}
+	}
+	return nil
+}
+
+func (r *memNotificationRepo) List(ctx context.Context, filter repositories.NotificationFilter) ([]models.Notification, error) {
+	var notifications []models.Notification
+	for _, notification := range r.inbox {
+		if filter.BeforeID != 0 && notification.ID >= filter.BeforeID {
+			continue
+		}
+		if len(notifications) == filter.Limit {
+			break
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications, nil
+}
+
+func (r *memNotificationRepo) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	return int64(len(r.inbox)), nil
+}
+
+func (r *memNotificationRepo) ListDueSoon(ctx context.Context, now, until time.Time) ([]models.Task, error) {
+	return r.dueSoon, nil
+}
+
+func (r *memNotificationRepo) Notify(ctx context.Context, notification *models.Notification) error {
+	for _, sent := range r.sent {
+		if sent.DedupeKey == notification.DedupeKey {
+			return nil
+		}
+	}
+	notification.ID = uint(len(r.sent) + 1)
+	r.sent = append(r.sent, *notification)
+	return nil
+}
+
+func TestUpdateNotificationPreferences(t *testing.T) {
+	repo := &memNotificationRepo{
+		preferences: []models.NotificationPreference{
+			{Type: models.NotificationTypeMentioned, Channel: models.NotificationChannelEmail, Enabled: false},
+		},
+	}
+	service := &NotificationService{notificationRepo: repo}
+
+	settings, err := service.UpdatePreferences(context.Background(), 1, []NotificationSetting{
+		{Type: models.NotificationTypeMentioned, Channel: models.NotificationChannelEmail, Enabled: true},
+		{Type: models.NotificationTypeDueSoon, Channel: models.NotificationChannelPush, Enabled: false},
+	})
+	if err != nil {
+		t.Fatalf("UpdatePreferences() error = %v", err)
+	}
+	if want := len(models.NotificationTypes) * len(models.NotificationChannels); len(settings) != want {
+		t.Fatalf("got %d settings, want one per type and channel, %d", len(settings), want)
+	}
+
+	tests := []struct {
+		t       models.NotificationType
+		channel models.NotificationChannel
+		enabled bool
+	}{
+		{models.NotificationTypeMentioned, models.NotificationChannelEmail, true},
+		{models.NotificationTypeMentioned, models.NotificationChannelPush, true},
+		{models.NotificationTypeDueSoon, models.NotificationChannelPush, false},
+		{models.NotificationTypeDueSoon, models.NotificationChannelInApp, true},
+		{models.NotificationTypeAssigned, models.NotificationChannelEmail, true},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.t)+"/"+string(tt.channel), func(t *testing.T) {
+			for _, setting := range settings {
+				if setting.Type == tt.t && setting.Channel == tt.channel {
+					if setting.Enabled != tt.enabled {
+						t.Errorf("Enabled = %v, want %v", setting.Enabled, tt.enabled)
+					}
+					return
+				}
+			}
+			t.Error("setting missing")
+		})
+	}
+
+	// Unknown names are rejected before anything is saved
+	_, err = service.UpdatePreferences(context.Background(), 1, []NotificationSetting{
+		{Type: models.NotificationTypeAssigned, Channel: models.NotificationChannelEmail, Enabled: false},
+		{Type: models.NotificationTypeAssigned, Channel: "sms", Enabled: false},
+	})
+	if !errors.Is(err, models.ErrUnknownNotificationChannel) {
+		t.Errorf("UpdatePreferences() with an unknown channel error = %v, want %v", err, models.ErrUnknownNotificationChannel)
+	}
+	if len(repo.preferences) != 2 {
+		t.Errorf("got %d preferences saved, want 2", len(repo.preferences))
+	}
+}
+
+func TestListNotifications(t *testing.T) {
+	repo := &memNotificationRepo{}
+	for id := uint(5); id >= 1; id-- {
+		repo.inbox = append(repo.inbox, models.Notification{ID: id})
+	}
+	service := &NotificationService{notificationRepo: repo}
+
+	tests := []struct {
+		name   string
+		cursor string
+		limit  int
+		ids    []uint
+		next   string
+		err    error
+	}{
+		{"first page", "", 2, []uint{5, 4}, "4", nil},
+		{"next page", "4", 2, []uint{3, 2}, "2", nil},
+		{"last page", "2", 2, []uint{1}, "", nil},
+		{"exactly one page", "", 5, []uint{5, 4, 3, 2, 1}, "", nil},
+		{"default size", "", 0, []uint{5, 4, 3, 2, 1}, "", nil},
+		{"invalid cursor", "abc", 2, nil, "", ErrInvalidCursor},
+		{"zero cursor", "0", 2, nil, "", ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := service.ListNotifications(context.Background(), 1, false, tt.cursor, tt.limit)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ListNotifications() error = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			var ids []uint
+			for _, notification := range page.Notifications {
+				ids = append(ids, notification.ID)
+			}
+			if !reflect.DeepEqual(ids, tt.ids) || page.NextCursor != tt.next {
+				t.Errorf("page = %v next %q, want %v next %q", ids, page.NextCursor, tt.ids, tt.next)
+			}
+			if page.UnreadCount != 5 {
+				t.Errorf("UnreadCount = %d, want 5", page.UnreadCount)
+			}
+		})
+	}
+}
+
+func TestNotifyDueSoon(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	assignee := uint(3)
+	task := func(id uint, due time.Time) models.Task {
+		task := models.Task{Title: "Ship it", ProjectID: 9, AssigneeID: &assignee, DueDate: &due}
+		task.ID = id
+		return task
+	}
+	repo := &memNotificationRepo{dueSoon: []models.Task{task(1, now.Add(6*time.Hour)), task(2, now.Add(20*time.Hour))}}
+	service := &NotificationService{notificationRepo: repo, now: func() time.Time { return now }}
+
+	notified, err := service.NotifyDueSoon(context.Background())
+	if err != nil || notified != 2 {
+		t.Fatalf("NotifyDueSoon() = %d, %v, want 2 notified", notified, err)
+	}
+	if got, want := repo.sent[0].Message, `"Ship it" is due Mar 1, 2026 18:00 UTC`; got != want {
+		t.Errorf("Message = %q, want %q", got, want)
+	}
+
+	// The next run does not notify again, unless the due date moved
+	repo.dueSoon[1] = task(2, now.Add(22*time.Hour))
+	notified, err = service.NotifyDueSoon(context.Background())
+	if err != nil || notified != 1 {
+		t.Errorf("NotifyDueSoon() again = %d, %v, want 1 notified", notified, err)
+	}
+}
+
+func TestNotificationPath(t *testing.T) {
+	id := uint(7)
+	tests := []struct {
+		notification models.Notification
+		want         string
+	}{
+		{models.Notification{TaskID: &id, ProjectID: &id}, "/tasks/7"},
+		{models.Notification{ProjectID: &id, OrganizationID: &id}, "/projects/7"},
+		{models.Notification{OrganizationID: &id}, "/organizations/7"},
+		{models.Notification{}, "/notifications"},
+	}
+	for _, tt := range tests {
+		if got := notificationPath(&tt.notification); got != tt.want {
+			t.Errorf("notificationPath() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,411 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/payments"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPaymentEventNotFound      = errors.New("payment event not found")
+	ErrInvalidPaymentEventStatus = errors.New("invalid payment event status")
+)
+
+// Failed events are retried on each run of the retry job, once they have
+// rested for eventRetryDelay, until they have been tried maxEventAttempts times
+const (
+	maxEventAttempts = 10
+	eventRetryDelay  = time.Minute
+	eventListLimit   = 100
+)
+
+// errEventOutOfOrder marks an event that arrived before an event it depends
+// on, such as a refund before the payment it refunds. It is retried later.
+var errEventOutOfOrder = errors.New("event depends on an event not received yet")
+
+// PaymentEventService ingests the payment provider's webhooks. Each event is
+// stored once, keyed by its provider event ID, so redelivered webhooks are
+// acknowledged without being applied twice. Events about a payment older
+// than the latest one applied to their invoice are ignored, refunds are
+// settled by their own ID, and events that arrive before one they depend on
+// are retried.
+type PaymentEventService struct {
+	paymentRepo      repositories.PaymentRepository
+	invoiceRepo      repositories.InvoiceRepository
+	subscriptionRepo repositories.SubscriptionRepository
+	userRepo         repositories.UserRepository
+	provider         payments.PaymentProvider
+	now              func() time.Time
+}
+
+func NewPaymentEventService(
+	paymentRepo repositories.PaymentRepository,
+	invoiceRepo repositories.InvoiceRepository,
+	subscriptionRepo repositories.SubscriptionRepository,
+	userRepo repositories.UserRepository,
+	provider payments.PaymentProvider,
+) *PaymentEventService {
+	return &PaymentEventService{
+		paymentRepo:      paymentRepo,
+		invoiceRepo:      invoiceRepo,
+		subscriptionRepo: subscriptionRepo,
+		userRepo:         userRepo,
+		provider:         provider,
+		now:              time.Now,
+	}
+}
+
+// Receive verifies and stores a webhook, then processes its event. It reports
+// false for an event received before. Processing failures are recorded on the
+// event and retried rather than returned, since the webhook was accepted.
+func (s *PaymentEventService) Receive(ctx context.Context, payload []byte, header http.Header) (bool, error) {
+	event, err := s.provider.ParseWebhook(payload, header, s.now())
+	if err != nil {
+		return false, err
+	}
+
+	record := &models.PaymentEvent{
+		Provider:   s.provider.Name(),
+		EventID:    event.ID,
+		Type:       event.ProviderType,
+		PaymentID:  event.PaymentID,
+		OccurredAt: event.OccurredAt,
+		Payload:    string(payload),
+		Status:     models.PaymentEventReceived,
+	}
+	created, err := s.paymentRepo.CreateEvent(ctx, record)
+	if err != nil || !created {
+		return false, err
+	}
+	return true, s.process(ctx, record)
+}
+
+// ListEvents lists the latest provider events, optionally with one status
+func (s *PaymentEventService) ListEvents(ctx context.Context, userID uint, status models.PaymentEventStatus) ([]models.PaymentEvent, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	if status != "" && !status.IsValid() {
+		return nil, ErrInvalidPaymentEventStatus
+	}
+	return s.paymentRepo.ListEvents(ctx, status, eventListLimit)
+}
+
+// GetEvent returns a provider event with its raw payload
+func (s *PaymentEventService) GetEvent(ctx context.Context, userID, eventID uint) (*models.PaymentEvent, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	return s.loadEvent(ctx, eventID)
+}
+
+// ReprocessEvent processes a stored event again from its raw payload, for
+// instance after fixing whatever made it fail. Changes it already made are
+// not made twice.
+func (s *PaymentEventService) ReprocessEvent(ctx context.Context, userID, eventID uint) (*models.PaymentEvent, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	record, err := s.loadEvent(ctx, eventID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.process(ctx, record); err != nil {
+		return nil, err
+	}
+	return record, nil
+}
+
+// RetryFailed processes again the events that failed or were left
+// unprocessed, and returns the number that succeeded this time
+func (s *PaymentEventService) RetryFailed(ctx context.Context) (int, error) {
+	records, err := s.paymentRepo.ListRetryableEvents(ctx, s.now().Add(-eventRetryDelay), maxEventAttempts)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	var errs []error
+	for i := range records {
+		if err := s.process(ctx, &records[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if records[i].Status != models.PaymentEventFailed {
+			processed++
+		}
+	}
+	return processed, errors.Join(errs...)
+}
+
+// process applies an event and records the outcome on it. Only failures to
+// store the outcome are returned.
+func (s *PaymentEventService) process(ctx context.Context, record *models.PaymentEvent) error {
+	record.Attempts++
+	change := &eventChange{}
+	status, err := s.apply(ctx, record, change)
+	record.Status = status
+	record.Error = ""
+	if err != nil {
+		record.Status = models.PaymentEventFailed
+		record.Error = err.Error()
+		return s.paymentRepo.SaveEvent(ctx, record)
+	}
+
+	now := s.now().UTC()
+	record.ProcessedAt = &now
+	return s.paymentRepo.ApplyEvent(ctx, record, change.invoice, change.transaction, change.subscription)
+}
+
+// eventChange collects what an event changes, to be stored together
+type eventChange struct {
+	invoice      *models.Invoice
+	transaction  *models.PaymentTransaction
+	subscription *models.Subscription
+}
+
+// apply works out the changes an event makes and the status it leaves the
+// event in
+func (s *PaymentEventService) apply(ctx context.Context, record *models.PaymentEvent, change *eventChange) (models.PaymentEventStatus, error) {
+	event, err := s.provider.ParseEvent([]byte(record.Payload))
+	if err != nil {
+		return "", err
+	}
+	if event.Type == payments.EventIgnored {
+		return models.PaymentEventIgnored, nil
+	}
+
+	invoice, err := s.findInvoice(ctx, event)
+	if err != nil {
+		return "", err
+	}
+	if invoice == nil {
+		// The attempt that started the payment may not be stored yet
+		return "", fmt.Errorf("%w: no invoice for payment %s", errEventOutOfOrder, event.PaymentID)
+	}
+	record.InvoiceID = &invoice.ID
+
+	// A refund is settled by its own transaction, whatever happened to the
+	// payment since, so only events about the payment can be superseded
+	refund := event.Type == payments.EventRefundSucceeded || event.Type == payments.EventRefundFailed
+	if !refund && invoice.PaymentEventAt != nil && event.OccurredAt.Before(*invoice.PaymentEventAt) {
+		record.Error = "superseded by a later event"
+		return models.PaymentEventIgnored, nil
+	}
+
+	applied, err := s.applyToInvoice(ctx, event, invoice, change)
+	if err != nil || !applied {
+		return models.PaymentEventIgnored, err
+	}
+	if !refund {
+		occurredAt := event.OccurredAt
+		invoice.PaymentEventAt = &occurredAt
+	}
+	change.invoice = invoice
+	return models.PaymentEventProcessed, nil
+}
+
+// applyToInvoice changes the invoice and its transactions as the event says,
+// reporting false if the event changes nothing
+func (s *PaymentEventService) applyToInvoice(ctx context.Context, event *payments.Event, invoice *models.Invoice, change *eventChange) (bool, error) {
+	switch event.Type {
+	case payments.EventPaymentSucceeded:
+		if err := s.settleTransaction(ctx, event.PaymentID, models.PaymentStatusSucceeded, event, change); err != nil {
+			return false, err
+		}
+		switch invoice.Status {
+		case models.PaymentStatusPending, models.PaymentStatusFailed:
+			invoice.PaymentID = event.PaymentID
+			if err := invoice.TransitionTo(models.PaymentStatusSucceeded, event.OccurredAt); err != nil {
+				return false, err
+			}
+			return true, s.reactivate(ctx, invoice, change)
+		}
+		return change.transaction != nil, nil
+
+	case payments.EventPaymentFailed:
+		if err := s.settleTransaction(ctx, event.PaymentID, models.PaymentStatusFailed, event, change); err != nil {
+			return false, err
+		}
+		// Only the latest attempt decides the invoice's status
+		latest := invoice.PaymentID == "" || invoice.PaymentID == event.PaymentID
+		if invoice.Status == models.PaymentStatusPending && latest {
+			return true, invoice.TransitionTo(models.PaymentStatusFailed, event.OccurredAt)
+		}
+		return change.transaction != nil, nil
+
+	case payments.EventRefundFailed:
+		return s.settleRefund(ctx, event, invoice, models.PaymentStatusFailed, change)
+
+	case payments.EventRefundSucceeded:
+		switch invoice.Status {
+		case models.PaymentStatusPending, models.PaymentStatusFailed:
+			return false, fmt.Errorf("%w: refund of unpaid invoice %d", errEventOutOfOrder, invoice.ID)
+		}
+		return s.settleRefund(ctx, event, invoice, models.PaymentStatusSucceeded, change)
+
+	case payments.EventDisputeOpened:
+		switch invoice.Status {
+		case models.PaymentStatusSucceeded:
+			return true, invoice.TransitionTo(models.PaymentStatusDisputed, event.OccurredAt)
+		case models.PaymentStatusPending, models.PaymentStatusFailed:
+			return false, fmt.Errorf("%w: dispute of unpaid invoice %d", errEventOutOfOrder, invoice.ID)
+		}
+		return false, nil
+
+	case payments.EventDisputeWon:
+		if invoice.Status == models.PaymentStatusDisputed {
+			return true, invoice.TransitionTo(models.PaymentStatusSucceeded, event.OccurredAt)
+		}
+		return false, nil
+
+	case payments.EventDisputeLost:
+		switch invoice.Status {
+		case models.PaymentStatusSucceeded:
+			// The dispute was opened without us hearing of it
+			if err := invoice.TransitionTo(models.PaymentStatusDisputed, event.OccurredAt); err != nil {
+				return false, err
+			}
+			fallthrough
+		case models.PaymentStatusDisputed:
+			return true, invoice.TransitionTo(models.PaymentStatusFailed, event.OccurredAt)
+		case models.PaymentStatusPending, models.PaymentStatusFailed:
+			return false, fmt.Errorf("%w: dispute of unpaid invoice %d", errEventOutOfOrder, invoice.ID)
+		}
+		return false, nil
+	}
+	return false, nil
+}
+
+// settleTransaction records the final status of the charge attempt that
+// started a payment, if it is still pending
+func (s *PaymentEventService) settleTransaction(ctx context.Context, paymentID string, status models.PaymentStatus, event *payments.Event, change *eventChange) error {
+	transaction, err := s.paymentRepo.FindTransaction(ctx, s.provider.Name(), paymentID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if transaction.Status != models.PaymentStatusPending {
+		return nil
+	}
+	transaction.Status = status
+	transaction.ErrorCode = event.FailureCode
+	transaction.ErrorMessage = event.FailureMessage
+	change.transaction = transaction
+	return nil
+}
+
+// settleRefund records the final status of a refund, found by the refund's
+// own ID. Refunds made outside the app, such as from the provider's
+// dashboard, are recorded too. A refund that succeeds is added to the
+// invoice's refunded amount, once; its credit note is issued by the credit
+// note job. A refund that fails after succeeding, such as one the card
+// issuer returned, is taken back off the invoice; its credit note is voided
+// by a job as well. A failed refund is final, so a success reported
+// after it is ignored, reporting false.
+func (s *PaymentEventService) settleRefund(ctx context.Context, event *payments.Event, invoice *models.Invoice, status models.PaymentStatus, change *eventChange) (bool, error) {
+	transaction, err := s.paymentRepo.FindTransaction(ctx, s.provider.Name(), event.RefundID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, err
+	}
+	if transaction == nil {
+		transaction = &models.PaymentTransaction{
+			InvoiceID:     invoice.ID,
+			Kind:          models.TransactionKindRefund,
+			Amount:        event.Amount,
+			PaymentMethod: invoice.PaymentMethod,
+			Provider:      s.provider.Name(),
+			ProviderID:    event.RefundID,
+		}
+		transaction.ProviderFee.Currency = event.Amount.Currency
+	}
+	if transaction.Status == models.PaymentStatusFailed {
+		return false, nil
+	}
+	settled := transaction.Status == models.PaymentStatusSucceeded
+	transaction.Status = status
+	transaction.ErrorCode = event.FailureCode
+	change.transaction = transaction
+	switch {
+	case status == models.PaymentStatusSucceeded && !settled:
+		return true, invoice.AddRefund(transaction.Amount, event.OccurredAt)
+	case status != models.PaymentStatusSucceeded && settled:
+		return true, invoice.RemoveRefund(transaction.Amount, event.OccurredAt)
+	}
+	return true, nil
+}
+
+// reactivate restores the invoice's subscription if it lapsed, now that it
+// has been paid. A period that ended in the meantime is replaced by a new
+// one starting now.
+func (s *PaymentEventService) reactivate(ctx context.Context, invoice *models.Invoice, change *eventChange) error {
+	subscription, err := s.subscriptionRepo.FindByID(ctx, invoice.SubscriptionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if subscription.Status != models.SubscriptionStatusInactive {
+		return nil
+	}
+
+	now := s.now().UTC()
+	if err := subscription.TransitionTo(models.SubscriptionStatusActive, now); err != nil {
+		return err
+	}
+	if subscription.EndDate.After(now) {
+		subscription.StartPeriod(subscription.CurrentPeriodStart, subscription.Plan.BillingInterval)
+	} else {
+		subscription.BillingAnchor = now
+		subscription.StartPeriod(now, subscription.Plan.BillingInterval)
+	}
+	change.subscription = subscription
+	return nil
+}
+
+// findInvoice finds the invoice an event is about, from the metadata the
+// charge was made with or from the payment
+func (s *PaymentEventService) findInvoice(ctx context.Context, event *payments.Event) (*models.Invoice, error) {
+	invoice, err := s.paymentRepo.FindInvoiceByPayment(ctx, event.PaymentID)
+	if err == nil {
+		return invoice, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	invoiceID, err := strconv.ParseUint(event.Metadata["invoice_id"], 10, 64)
+	if err != nil {
+		return nil, nil
+	}
+	invoice, err = s.invoiceRepo.FindByID(ctx, uint(invoiceID))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return invoice, nil
+}
+
+func (s *PaymentEventService) loadEvent(ctx context.Context, eventID uint) (*models.PaymentEvent, error) {
+	record, err := s.paymentRepo.FindEvent(ctx, eventID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPaymentEventNotFound
+		}
+		return nil, err
+	}
+	return record, nil
+}
@@ -0,0 +1,257 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/payments"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// memEventRepo adds the lookups events use to memPaymentRepo
+type memEventRepo struct {
+	memPaymentRepo
+	invoice *models.Invoice
+}
+
+func (r *memEventRepo) FindInvoiceByPayment(ctx context.Context, paymentID string) (*models.Invoice, error) {
+	if r.invoice.PaymentID != paymentID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	invoice := *r.invoice
+	return &invoice, nil
+}
+
+func (r *memEventRepo) FindTransaction(ctx context.Context, provider, providerID string) (*models.PaymentTransaction, error) {
+	for _, transaction := range r.transactions {
+		if transaction.Provider == provider && transaction.ProviderID == providerID {
+			return &transaction, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// eventTest applies webhooks to a paid invoice of 100.00 USD
+type eventTest struct {
+	t        *testing.T
+	service  *PaymentEventService
+	repo     *memEventRepo
+	provider *payments.FakeProvider
+}
+
+func newEventTest(t *testing.T, lastEvent time.Time) *eventTest {
+	paidAt := lastEvent
+	invoice := &models.Invoice{
+		OrganizationID: 1,
+		Amount:         money.New(10000, "USD"),
+		NetAmount:      money.New(10000, "USD"),
+		Status:         models.PaymentStatusSucceeded,
+		PaymentID:      "pi_paid",
+		PaidAt:         &paidAt,
+		PaymentEventAt: &lastEvent,
+	}
+	invoice.ID = 7
+	provider := payments.NewFakeProvider("whsec_test")
+	repo := &memEventRepo{invoice: invoice}
+	service := &PaymentEventService{paymentRepo: repo, provider: provider, now: time.Now}
+	return &eventTest{t: t, service: service, repo: repo, provider: provider}
+}
+
+// apply applies an event of the given type about object, storing what it
+// changed, and returns the status it leaves the event in
+func (e *eventTest) apply(eventType string, object map[string]interface{}, at time.Time) models.PaymentEventStatus {
+	e.t.Helper()
+	payload, _, err := e.provider.SignedEvent(eventType, object, at)
+	if err != nil {
+		e.t.Fatal(err)
+	}
+	change := &eventChange{}
+	status, err := e.service.apply(context.Background(), &models.PaymentEvent{Payload: string(payload)}, change)
+	if err != nil {
+		e.t.Fatalf("applying %s: %v", eventType, err)
+	}
+	if change.invoice != nil {
+		*e.repo.invoice = *change.invoice
+	}
+	if transaction := change.transaction; transaction != nil {
+		save := e.repo.CompleteTransaction
+		if transaction.ID == 0 {
+			save = func(ctx context.Context, transaction *models.PaymentTransaction, _ *models.Invoice) error {
+				return e.repo.CreateTransaction(ctx, transaction)
+			}
+		}
+		if err := save(context.Background(), transaction, nil); err != nil {
+			e.t.Fatalf("saving transaction: %v", err)
+		}
+	}
+	return status
+}
+
+func refundObject(id, status string, amount int64) map[string]interface{} {
+	return map[string]interface{}{
+		"id":             id,
+		"object":         "refund",
+		"payment_intent": "pi_paid",
+		"amount":         amount,
+		"currency":       "usd",
+		"status":         status,
+	}
+}
+
+func TestRefundEventsAreNotSuperseded(t *testing.T) {
+	latest := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	e := newEventTest(t, latest)
+
+	// The refund happened before the latest payment event, but is a change
+	// of its own and must still be applied
+	if status := e.apply("refund.updated", refundObject("re_1", "succeeded", 2500), latest.Add(-time.Hour)); status != models.PaymentEventProcessed {
+		t.Fatalf("refund status = %s, want processed", status)
+	}
+	invoice := e.repo.invoice
+	if invoice.Refunded() != money.New(2500, "USD") || invoice.Status != models.PaymentStatusSucceeded {
+		t.Errorf("invoice refunded %v, status %s", invoice.Refunded(), invoice.Status)
+	}
+	if !invoice.PaymentEventAt.Equal(latest) {
+		t.Errorf("PaymentEventAt = %v, want %v unchanged by the refund", invoice.PaymentEventAt, latest)
+	}
+	if len(e.repo.transactions) != 1 || e.repo.transactions[0].ProviderID != "re_1" || e.repo.transactions[0].Status != models.PaymentStatusSucceeded {
+		t.Errorf("transactions = %+v", e.repo.transactions)
+	}
+
+	// A redelivered refund is not added twice
+	e.apply("refund.updated", refundObject("re_1", "succeeded", 2500), latest.Add(-time.Hour))
+	if invoice.Refunded() != money.New(2500, "USD") {
+		t.Errorf("refunded after redelivery = %v, want 25.00 USD", invoice.Refunded())
+	}
+
+	// A refund that failed stays failed
+	if status := e.apply("refund.failed", refundObject("re_2", "failed", 1000), latest.Add(-2*time.Hour)); status != models.PaymentEventProcessed {
+		t.Errorf("failed refund status = %s, want processed", status)
+	}
+	if status := e.apply("refund.updated", refundObject("re_2", "succeeded", 1000), latest.Add(-3*time.Hour)); status != models.PaymentEventIgnored {
+		t.Errorf("success after failure status = %s, want ignored", status)
+	}
+	if invoice.Refunded() != money.New(2500, "USD") || e.repo.transactions[1].Status != models.PaymentStatusFailed {
+		t.Errorf("refunded %v, refund %s", invoice.Refunded(), e.repo.transactions[1].Status)
+	}
+}
+
+func TestOlderPaymentEventIsSuperseded(t *testing.T) {
+	latest := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	e := newEventTest(t, latest)
+
+	status := e.apply("charge.dispute.created", map[string]interface{}{
+		"id":             "dp_1",
+		"object":         "dispute",
+		"payment_intent": "pi_paid",
+		"amount":         10000,
+		"currency":       "usd",
+		"status":         "needs_response",
+	}, latest.Add(-time.Minute))
+	if status != models.PaymentEventIgnored || e.repo.invoice.Status != models.PaymentStatusSucceeded {
+		t.Errorf("older dispute status = %s, invoice %s", status, e.repo.invoice.Status)
+	}
+}
+
+func TestRefundFailingAfterSuccessIsTakenBack(t *testing.T) {
+	latest := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	e := newEventTest(t, latest)
+	invoice := e.repo.invoice
+
+	e.apply("refund.updated", refundObject("re_1", "succeeded", 2500), latest.Add(time.Hour))
+	e.apply("refund.updated", refundObject("re_2", "succeeded", 7500), latest.Add(time.Hour))
+	if invoice.Status != models.PaymentStatusRefunded || invoice.NetAmount != money.New(0, "USD") {
+		t.Fatalf("invoice after full refund = %s, net %v", invoice.Status, invoice.NetAmount)
+	}
+
+	// The card the refund went to was closed, so the money came back
+	if status := e.apply("refund.failed", refundObject("re_2", "failed", 7500), latest.Add(2*time.Hour)); status != models.PaymentEventProcessed {
+		t.Fatalf("failed refund status = %s, want processed", status)
+	}
+	if invoice.Status != models.PaymentStatusSucceeded || invoice.Refunded() != money.New(2500, "USD") || invoice.NetAmount != money.New(7500, "USD") {
+		t.Errorf("invoice = %s, refunded %v, net %v", invoice.Status, invoice.Refunded(), invoice.NetAmount)
+	}
+	if e.repo.transactions[1].Status != models.PaymentStatusFailed {
+		t.Errorf("refund status = %s, want failed", e.repo.transactions[1].Status)
+	}
+
+	// Redelivering the failure does not take the refund back twice
+	e.apply("refund.failed", refundObject("re_2", "failed", 7500), latest.Add(2*time.Hour))
+	if invoice.Refunded() != money.New(2500, "USD") {
+		t.Errorf("refunded after redelivery = %v, want 25.00 USD", invoice.Refunded())
+	}
+}
+
+// memCreditNoteRepo keeps credit notes for the refunds of a memEventRepo
+type memCreditNoteRepo struct {
+	repositories.CreditNoteRepository
+	payments *memEventRepo
+	notes    []models.CreditNote
+}
+
+func (r *memCreditNoteRepo) ListReversed(ctx context.Context) ([]models.CreditNote, error) {
+	var notes []models.CreditNote
+	for _, note := range r.notes {
+		for _, transaction := range r.payments.transactions {
+			if transaction.ID == note.TransactionID && transaction.Status == models.PaymentStatusFailed && !note.IsVoid() {
+				notes = append(notes, note)
+			}
+		}
+	}
+	return notes, nil
+}
+
+func (r *memCreditNoteRepo) Void(ctx context.Context, note *models.CreditNote) error {
+	for i := range r.notes {
+		if r.notes[i].ID == note.ID && !r.notes[i].IsVoid() {
+			r.notes[i].VoidedAt = note.VoidedAt
+			r.notes[i].VoidReason = note.VoidReason
+		}
+	}
+	return nil
+}
+
+func TestReversedRefundVoidsItsCreditNote(t *testing.T) {
+	latest := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	e := newEventTest(t, latest)
+	e.apply("refund.updated", refundObject("re_1", "succeeded", 2500), latest.Add(time.Hour))
+	e.apply("refund.updated", refundObject("re_2", "succeeded", 7500), latest.Add(time.Hour))
+
+	// The credit note job has issued a note for each refund
+	repo := &memCreditNoteRepo{payments: e.repo}
+	for i, transaction := range e.repo.transactions {
+		note := models.CreditNote{TransactionID: transaction.ID, CreditNoteNumber: "CN-" + transaction.ProviderID, Amount: transaction.Amount}
+		note.ID = uint(i + 1)
+		repo.notes = append(repo.notes, note)
+	}
+	voidedAt := latest.Add(3 * time.Hour)
+	service := &CreditNoteService{creditNoteRepo: repo, now: func() time.Time { return voidedAt }}
+
+	if voided, err := service.VoidReversed(context.Background()); err != nil || voided != 0 {
+		t.Fatalf("VoidReversed before any reversal = %d, %v", voided, err)
+	}
+
+	e.apply("refund.failed", refundObject("re_2", "failed", 7500), latest.Add(2*time.Hour))
+	if voided, err := service.VoidReversed(context.Background()); err != nil || voided != 1 {
+		t.Fatalf("VoidReversed = %d, %v, want 1", voided, err)
+	}
+	kept, reversed := repo.notes[0], repo.notes[1]
+	if kept.IsVoid() {
+		t.Errorf("credit note of re_1 voided at %v", kept.VoidedAt)
+	}
+	if !reversed.IsVoid() || !reversed.VoidedAt.Equal(voidedAt) || reversed.VoidReason != reversedRefundReason {
+		t.Errorf("credit note of re_2 voided at %v for %q", reversed.VoidedAt, reversed.VoidReason)
+	}
+	if reversed.CreditNoteNumber != "CN-re_2" {
+		t.Errorf("voided credit note number = %s, want it kept", reversed.CreditNoteNumber)
+	}
+
+	// A voided note is not voided again
+	if voided, err := service.VoidReversed(context.Background()); err != nil || voided != 0 {
+		t.Errorf("second VoidReversed = %d, %v, want 0", voided, err)
+	}
+}
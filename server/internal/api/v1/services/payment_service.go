@@ -0,0 +1,494 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/payments"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoPaymentMethod      = errors.New("organization has no payment method on file")
+	ErrInvoiceNotPayable    = errors.New("invoice has nothing left to pay")
+	ErrInvoiceNotRefundable = errors.New("only paid invoices can be refunded")
+	ErrInvalidRefundAmount  = errors.New("refund amount must be positive")
+	ErrRefundTooLarge       = errors.New("refund exceeds the amount left to refund")
+)
+
+type PaymentService struct {
+	paymentRepo       repositories.PaymentRepository
+	invoiceRepo       repositories.InvoiceRepository
+	subscriptionRepo  repositories.SubscriptionRepository
+	userRepo          repositories.UserRepository
+	creditNoteService *CreditNoteService
+	orgRepo           repositories.OrganizationRepository
+	provider          payments.PaymentProvider
+	access            accessChecker
+	now               func() time.Time
+}
+
+func NewPaymentService(
+	paymentRepo repositories.PaymentRepository,
+	invoiceRepo repositories.InvoiceRepository,
+	subscriptionRepo repositories.SubscriptionRepository,
+	userRepo repositories.UserRepository,
+	creditNoteService *CreditNoteService,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+	provider payments.PaymentProvider,
+) *PaymentService {
+	return &PaymentService{
+		paymentRepo:       paymentRepo,
+		invoiceRepo:       invoiceRepo,
+		subscriptionRepo:  subscriptionRepo,
+		userRepo:          userRepo,
+		creditNoteService: creditNoteService,
+		orgRepo:           orgRepo,
+		provider:          provider,
+		access:            accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+		now:               time.Now,
+	}
+}
+
+// GetPaymentMethod returns the payment method the organization's invoices are charged to
+func (s *PaymentService) GetPaymentMethod(ctx context.Context, userID, orgID uint) (*models.PaymentProfile, error) {
+	if err := s.access.billing(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	profile, err := s.findProfile(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil || !profile.HasPaymentMethod() {
+		return nil, ErrNoPaymentMethod
+	}
+	return profile, nil
+}
+
+// SetPaymentMethod saves a payment method token, collected by the provider's
+// client library, as the one the organization's invoices are charged to. It
+// replaces the previous payment method, and the organization's subscriptions
+// take the new method's type.
+func (s *PaymentService) SetPaymentMethod(ctx context.Context, userID, orgID uint, token string) (*models.PaymentProfile, error) {
+	if err := s.access.billing(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	profile, err := s.findProfile(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A customer is registered the first time, and again if the provider changed
+	if profile == nil || profile.Provider != s.provider.Name() {
+		org, err := s.orgRepo.FindByID(ctx, orgID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrOrganizationNotFound
+			}
+			return nil, err
+		}
+		customerID, err := s.provider.CreateCustomer(ctx, payments.Customer{
+			Name:           org.Name,
+			Email:          org.BillingEmail,
+			OrganizationID: orgID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if profile == nil {
+			profile = &models.PaymentProfile{OrganizationID: orgID}
+		}
+		profile.Provider = s.provider.Name()
+		profile.CustomerID = customerID
+		profile.PaymentMethodID = ""
+	}
+
+	method, err := s.provider.AttachPaymentMethod(ctx, profile.CustomerID, token)
+	if err != nil {
+		return nil, err
+	}
+	previous := profile.PaymentMethodID
+	profile.PaymentMethodID = method.ID
+	profile.PaymentMethod = models.PaymentMethod(method.Type)
+	profile.Brand = method.Brand
+	profile.Last4 = method.Last4
+	profile.ExpMonth = method.ExpMonth
+	profile.ExpYear = method.ExpYear
+	if err := s.paymentRepo.SaveProfile(ctx, profile); err != nil {
+		return nil, err
+	}
+	if previous != "" && previous != method.ID {
+		// The new method is already in use, so a method left attached at the
+		// provider is harmless
+		_ = s.provider.DetachPaymentMethod(ctx, previous)
+	}
+
+	if err := s.syncSubscriptions(ctx, orgID, profile.PaymentMethod); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// PayInvoice charges an unpaid invoice to the organization's payment method
+func (s *PaymentService) PayInvoice(ctx context.Context, userID, invoiceID uint) (*models.Invoice, error) {
+	invoice, err := s.loadInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.billing(ctx, invoice.OrganizationID, userID); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.charge(ctx, invoice); err != nil {
+		return nil, err
+	}
+	return invoice, nil
+}
+
+// Refund is a refund of an invoice, with the credit note issued for it
+type Refund struct {
+	Invoice     *models.Invoice            `json:"invoice"`
+	Transaction *models.PaymentTransaction `json:"transaction"`
+	CreditNote  *models.CreditNote         `json:"credit_note"` // nil until the refund succeeds
+}
+
+// RefundInvoice refunds all or part of a paid invoice to the payment it was
+// paid with. An empty amount refunds what is left to refund. Refunds still
+// pending at the provider count as made, so they cannot be refunded twice.
+// A credit note is issued once the refund succeeds; if that fails, the
+// credit note job issues it later rather than the refund being reported as
+// failed.
+func (s *PaymentService) RefundInvoice(ctx context.Context, userID, invoiceID uint, amount, reason string) (*Refund, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	invoice, err := s.loadInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if invoice.Status != models.PaymentStatusSucceeded || invoice.PaymentID == "" {
+		return nil, ErrInvoiceNotRefundable
+	}
+
+	transactions, err := s.paymentRepo.ListTransactions(ctx, invoice.ID)
+	if err != nil {
+		return nil, err
+	}
+	refunds := 0
+	remaining, err := invoice.Amount.Sub(invoice.Refunded())
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range transactions {
+		if transaction.Kind != models.TransactionKindRefund {
+			continue
+		}
+		refunds++
+		if transaction.Status == models.PaymentStatusPending {
+			if remaining, err = remaining.Sub(transaction.Amount); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	refundAmount := remaining
+	if amount != "" {
+		if refundAmount, err = money.Parse(amount, invoice.Amount.Currency); err != nil {
+			return nil, err
+		}
+		if !refundAmount.IsPositive() {
+			return nil, ErrInvalidRefundAmount
+		}
+	}
+	if !remaining.IsPositive() || refundAmount.Minor > remaining.Minor {
+		return nil, fmt.Errorf("%w: %s is left", ErrRefundTooLarge, remaining)
+	}
+
+	transaction := &models.PaymentTransaction{
+		InvoiceID:      invoice.ID,
+		Kind:           models.TransactionKindRefund,
+		Amount:         refundAmount,
+		Status:         models.PaymentStatusPending,
+		PaymentMethod:  invoice.PaymentMethod,
+		Reason:         reason,
+		Provider:       s.provider.Name(),
+		ProviderFee:    money.Zero(invoice.Amount.Currency),
+		IdempotencyKey: fmt.Sprintf("invoice-%d-refund-%d", invoice.ID, refunds+1),
+	}
+	if err := s.paymentRepo.CreateTransaction(ctx, transaction); err != nil {
+		return nil, err
+	}
+
+	refund, err := s.provider.Refund(ctx, payments.RefundRequest{
+		ChargeID:       invoice.PaymentID,
+		Amount:         refundAmount,
+		Reason:         reason,
+		IdempotencyKey: transaction.IdempotencyKey,
+	})
+	if err != nil {
+		return nil, s.recordProviderError(ctx, transaction, invoice, err)
+	}
+
+	transaction.ProviderID = refund.ID
+	switch refund.Status {
+	case payments.ChargeStatusSucceeded:
+		transaction.Status = models.PaymentStatusSucceeded
+		if err := invoice.AddRefund(refundAmount, s.now().UTC()); err != nil {
+			return nil, err
+		}
+	case payments.ChargeStatusFailed:
+		transaction.Status = models.PaymentStatusFailed
+	}
+	if err := s.paymentRepo.CompleteTransaction(ctx, transaction, invoice); err != nil {
+		return nil, err
+	}
+
+	result := &Refund{Invoice: invoice, Transaction: transaction}
+	if transaction.Status == models.PaymentStatusSucceeded {
+		if note, err := s.creditNoteService.IssueForRefund(ctx, transaction); err == nil {
+			result.CreditNote = note
+		}
+	}
+	return result, nil
+}
+
+// ListTransactions lists the charges and refunds attempted for an invoice
+func (s *PaymentService) ListTransactions(ctx context.Context, userID, invoiceID uint) ([]models.PaymentTransaction, error) {
+	invoice, err := s.loadInvoice(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.billing(ctx, invoice.OrganizationID, userID); err != nil {
+		return nil, err
+	}
+	return s.paymentRepo.ListTransactions(ctx, invoice.ID)
+}
+
+// CollectPayments charges newly issued invoices to their organizations'
+// payment methods and returns the number paid. Declined payments are
+// recorded on their invoices rather than returned as errors.
+func (s *PaymentService) CollectPayments(ctx context.Context) (int, error) {
+	invoices, err := s.paymentRepo.ListUncollected(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	paid := 0
+	var errs []error
+	for i := range invoices {
+		if _, err := s.charge(ctx, &invoices[i]); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if invoices[i].Status == models.PaymentStatusSucceeded {
+			paid++
+		}
+	}
+	return paid, errors.Join(errs...)
+}
+
+// charge attempts to collect the invoice. The attempt is recorded before the
+// provider is called, with an idempotency key, so that a retried request
+// cannot charge twice. An attempt whose outcome is unknown is retried with its
+// key, and a new key is used only once the provider has answered the last
+// one. The invoice moves to the status the provider reports.
+func (s *PaymentService) charge(ctx context.Context, invoice *models.Invoice) (*models.PaymentTransaction, error) {
+	if !invoice.IsPayable() {
+		return nil, ErrInvoiceNotPayable
+	}
+	profile, err := s.findProfile(ctx, invoice.OrganizationID)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil || !profile.HasPaymentMethod() {
+		return nil, ErrNoPaymentMethod
+	}
+
+	transaction, err := s.paymentRepo.FindLastAttempt(ctx, invoice.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if transaction != nil && transaction.Status == models.PaymentStatusPending {
+		// The provider may have charged the last attempt already; sending
+		// its key again returns that charge instead of making another
+		transaction.ErrorCode = ""
+		transaction.ErrorMessage = ""
+	} else {
+		attempts, err := s.paymentRepo.CountAttempts(ctx, invoice.ID)
+		if err != nil {
+			return nil, err
+		}
+		transaction = &models.PaymentTransaction{
+			InvoiceID:      invoice.ID,
+			Kind:           models.TransactionKindCharge,
+			Amount:         invoice.Amount,
+			Status:         models.PaymentStatusPending,
+			PaymentMethod:  profile.PaymentMethod,
+			Provider:       profile.Provider,
+			ProviderFee:    money.Zero(invoice.Amount.Currency),
+			IdempotencyKey: fmt.Sprintf("invoice-%d-charge-%d", invoice.ID, attempts+1),
+		}
+		if err := s.paymentRepo.CreateTransaction(ctx, transaction); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := s.provider.Charge(ctx, payments.ChargeRequest{
+		CustomerID:      profile.CustomerID,
+		PaymentMethodID: profile.PaymentMethodID,
+		Amount:          invoice.Amount,
+		Description:     "Invoice " + invoice.InvoiceNumber,
+		IdempotencyKey:  transaction.IdempotencyKey,
+		Metadata: map[string]string{
+			"invoice_id":      strconv.FormatUint(uint64(invoice.ID), 10),
+			"organization_id": strconv.FormatUint(uint64(invoice.OrganizationID), 10),
+		},
+	})
+	if err != nil {
+		if outcomeUnknown(err) {
+			return transaction, s.recordUnknownOutcome(ctx, transaction, invoice, err)
+		}
+		return transaction, s.recordProviderError(ctx, transaction, invoice, err)
+	}
+
+	now := s.now().UTC()
+	transaction.ProviderID = result.ID
+	transaction.ProviderFee = result.Fee
+	invoice.PaymentMethod = profile.PaymentMethod
+	switch result.Status {
+	case payments.ChargeStatusSucceeded:
+		transaction.Status = models.PaymentStatusSucceeded
+		invoice.PaymentID = result.ID
+		err = invoice.TransitionTo(models.PaymentStatusSucceeded, now)
+	case payments.ChargeStatusFailed:
+		transaction.Status = models.PaymentStatusFailed
+		transaction.ErrorCode = result.FailureCode
+		transaction.ErrorMessage = result.FailureMessage
+		err = invoice.TransitionTo(models.PaymentStatusFailed, now)
+	default:
+		// The payment settles later; a failed invoice is being paid again
+		invoice.PaymentID = result.ID
+		if invoice.Status == models.PaymentStatusFailed {
+			err = invoice.TransitionTo(models.PaymentStatusPending, now)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.paymentRepo.CompleteTransaction(ctx, transaction, invoice); err != nil {
+		return nil, err
+	}
+	return transaction, nil
+}
+
+// recordProviderError marks an attempt failed when the provider could not be
+// reached or rejected the request, leaving the invoice as it was. It returns
+// the provider's error.
+func (s *PaymentService) recordProviderError(ctx context.Context, transaction *models.PaymentTransaction, invoice *models.Invoice, err error) error {
+	transaction.Status = models.PaymentStatusFailed
+	describeProviderError(transaction, err)
+	if saveErr := s.paymentRepo.CompleteTransaction(ctx, transaction, invoice); saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+	return err
+}
+
+// recordUnknownOutcome records the error of a charge the provider may have
+// carried out, keeping it pending so that the next attempt reuses its
+// idempotency key. It returns the provider's error.
+func (s *PaymentService) recordUnknownOutcome(ctx context.Context, transaction *models.PaymentTransaction, invoice *models.Invoice, err error) error {
+	transaction.Status = models.PaymentStatusPending
+	describeProviderError(transaction, err)
+	if saveErr := s.paymentRepo.CompleteTransaction(ctx, transaction, invoice); saveErr != nil {
+		return errors.Join(err, saveErr)
+	}
+	return err
+}
+
+// describeProviderError copies the provider's error code and message onto the
+// transaction
+func describeProviderError(transaction *models.PaymentTransaction, err error) {
+	transaction.ErrorCode = "provider_error"
+	transaction.ErrorMessage = err.Error()
+	var providerErr *payments.Error
+	if errors.As(err, &providerErr) {
+		if providerErr.Code != "" {
+			transaction.ErrorCode = providerErr.Code
+		}
+		transaction.ErrorMessage = providerErr.Message
+	}
+}
+
+// outcomeUnknown reports whether the provider may have acted on a request
+// that returned the error: the request timed out or never got a response,
+// the provider failed, or it is still processing the same idempotency key.
+// Any other response from the provider declined the request.
+func outcomeUnknown(err error) bool {
+	if errors.Is(err, payments.ErrCustomerNotFound) || errors.Is(err, payments.ErrPaymentMethodNotFound) {
+		return false
+	}
+	var providerErr *payments.Error
+	if !errors.As(err, &providerErr) {
+		return true
+	}
+	switch status := providerErr.StatusCode; {
+	case status == 0, status >= http.StatusInternalServerError:
+		return true
+	case status == http.StatusConflict, status == http.StatusTooManyRequests:
+		return true
+	}
+	return false
+}
+
+// syncSubscriptions records the payment method's type on the organization's
+// current and scheduled subscriptions
+func (s *PaymentService) syncSubscriptions(ctx context.Context, orgID uint, method models.PaymentMethod) error {
+	var changes []*models.Subscription
+	for _, find := range []func(context.Context, uint) (*models.Subscription, error){
+		s.subscriptionRepo.FindCurrent, s.subscriptionRepo.FindScheduled,
+	} {
+		subscription, err := find(ctx, orgID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				continue
+			}
+			return err
+		}
+		subscription.PaymentMethod = string(method)
+		changes = append(changes, subscription)
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+	return s.subscriptionRepo.Save(ctx, changes...)
+}
+
+func (s *PaymentService) findProfile(ctx context.Context, orgID uint) (*models.PaymentProfile, error) {
+	profile, err := s.paymentRepo.FindProfile(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (s *PaymentService) loadInvoice(ctx context.Context, invoiceID uint) (*models.Invoice, error) {
+	invoice, err := s.invoiceRepo.FindByID(ctx, invoiceID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvoiceNotFound
+		}
+		return nil, err
+	}
+	return invoice, nil
+}
@@ -0,0 +1,317 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/payments"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// memPaymentRepo keeps the profiles and transactions charge uses in memory.
+// Other methods are not implemented.
+type memPaymentRepo struct {
+	repositories.PaymentRepository
+	profiles     map[uint]models.PaymentProfile
+	transactions []models.PaymentTransaction
+}
+
+func (r *memPaymentRepo) FindProfile(ctx context.Context, orgID uint) (*models.PaymentProfile, error) {
+	profile, ok := r.profiles[orgID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &profile, nil
+}
+
+func (r *memPaymentRepo) CountAttempts(ctx context.Context, invoiceID uint) (int64, error) {
+	var count int64
+	for _, transaction := range r.transactions {
+		if transaction.InvoiceID == invoiceID && transaction.Kind == models.TransactionKindCharge {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (r *memPaymentRepo) FindLastAttempt(ctx context.Context, invoiceID uint) (*models.PaymentTransaction, error) {
+	for i := len(r.transactions) - 1; i >= 0; i-- {
+		transaction := r.transactions[i]
+		if transaction.InvoiceID == invoiceID && transaction.Kind == models.TransactionKindCharge {
+			return &transaction, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *memPaymentRepo) CreateTransaction(ctx context.Context, transaction *models.PaymentTransaction) error {
+	for _, existing := range r.transactions {
+		if transaction.IdempotencyKey != "" && existing.IdempotencyKey == transaction.IdempotencyKey {
+			return errors.New("duplicate idempotency key " + transaction.IdempotencyKey)
+		}
+	}
+	transaction.ID = uint(len(r.transactions) + 1)
+	r.transactions = append(r.transactions, *transaction)
+	return nil
+}
+
+func (r *memPaymentRepo) CompleteTransaction(ctx context.Context, transaction *models.PaymentTransaction, invoice *models.Invoice) error {
+	for i := range r.transactions {
+		if r.transactions[i].ID == transaction.ID {
+			r.transactions[i] = *transaction
+			return nil
+		}
+	}
+	return gorm.ErrRecordNotFound
+}
+
+// flakyProvider fails the next charges with err. If forward is set, the
+// charge reaches the provider first and only its response is lost.
+type flakyProvider struct {
+	*payments.FakeProvider
+	err     error
+	forward bool
+	fails   int
+	keys    []string
+	charged []string
+}
+
+func (p *flakyProvider) Charge(ctx context.Context, req payments.ChargeRequest) (*payments.Charge, error) {
+	p.keys = append(p.keys, req.IdempotencyKey)
+	if p.fails > 0 {
+		p.fails--
+		if p.forward {
+			charge, err := p.FakeProvider.Charge(ctx, req)
+			if err == nil {
+				p.charged = append(p.charged, charge.ID)
+			}
+		}
+		return nil, p.err
+	}
+	charge, err := p.FakeProvider.Charge(ctx, req)
+	if err == nil {
+		p.charged = append(p.charged, charge.ID)
+	}
+	return charge, err
+}
+
+// newChargeTest returns a service charging organization 1 through the fake
+// provider, with a payment method attached with the token
+func newChargeTest(t *testing.T, token string) (*PaymentService, *memPaymentRepo, *flakyProvider) {
+	t.Helper()
+	fake := payments.NewFakeProvider("whsec_test")
+	ctx := context.Background()
+	customerID, err := fake.CreateCustomer(ctx, payments.Customer{Name: "Acme", OrganizationID: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	method, err := fake.AttachPaymentMethod(ctx, customerID, token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo := &memPaymentRepo{profiles: map[uint]models.PaymentProfile{
+		1: {
+			OrganizationID:  1,
+			Provider:        fake.Name(),
+			CustomerID:      customerID,
+			PaymentMethodID: method.ID,
+			PaymentMethod:   models.PaymentMethod(method.Type),
+		},
+	}}
+	provider := &flakyProvider{FakeProvider: fake}
+	service := &PaymentService{
+		paymentRepo: repo,
+		provider:    provider,
+		now:         func() time.Time { return time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC) },
+	}
+	return service, repo, provider
+}
+
+func newPayableInvoice() *models.Invoice {
+	invoice := &models.Invoice{
+		OrganizationID: 1,
+		InvoiceNumber:  "INV-0001",
+		Amount:         money.New(4900, "USD"),
+		Status:         models.PaymentStatusPending,
+	}
+	invoice.ID = 7
+	return invoice
+}
+
+func TestChargeSucceeds(t *testing.T) {
+	service, repo, provider := newChargeTest(t, "tok_visa")
+	invoice := newPayableInvoice()
+
+	transaction, err := service.charge(context.Background(), invoice)
+	if err != nil {
+		t.Fatalf("charge: %v", err)
+	}
+	if transaction.Status != models.PaymentStatusSucceeded || transaction.IdempotencyKey != "invoice-7-charge-1" {
+		t.Errorf("transaction = %s %q", transaction.Status, transaction.IdempotencyKey)
+	}
+	if invoice.Status != models.PaymentStatusSucceeded || invoice.PaidAt == nil || invoice.PaymentID != provider.charged[0] {
+		t.Errorf("invoice = %s paid at %v payment %q", invoice.Status, invoice.PaidAt, invoice.PaymentID)
+	}
+	// 2.9% of 49.00 plus 0.30
+	if transaction.ProviderFee != money.New(172, "USD") {
+		t.Errorf("fee = %v, want 1.72 USD", transaction.ProviderFee)
+	}
+	if len(repo.transactions) != 1 || repo.transactions[0].Status != models.PaymentStatusSucceeded {
+		t.Errorf("stored transactions = %+v", repo.transactions)
+	}
+
+	if _, err := service.charge(context.Background(), invoice); !errors.Is(err, ErrInvoiceNotPayable) {
+		t.Errorf("charging a paid invoice error = %v, want ErrInvoiceNotPayable", err)
+	}
+}
+
+func TestChargeDeclined(t *testing.T) {
+	for _, tt := range []struct {
+		token string
+		code  string
+	}{
+		{payments.FakeTokenDeclined, "card_declined"},
+		{payments.FakeTokenInsufficientFunds, "insufficient_funds"},
+	} {
+		t.Run(tt.code, func(t *testing.T) {
+			service, repo, _ := newChargeTest(t, tt.token)
+			invoice := newPayableInvoice()
+
+			transaction, err := service.charge(context.Background(), invoice)
+			if err != nil {
+				t.Fatalf("charge: %v", err)
+			}
+			if transaction.Status != models.PaymentStatusFailed || transaction.ErrorCode != tt.code {
+				t.Errorf("transaction = %s %q, want failed %q", transaction.Status, transaction.ErrorCode, tt.code)
+			}
+			if invoice.Status != models.PaymentStatusFailed {
+				t.Errorf("invoice status = %s, want failed", invoice.Status)
+			}
+
+			// A declined attempt is answered, so the retry is a new request
+			retry, err := service.charge(context.Background(), invoice)
+			if err != nil {
+				t.Fatalf("retry: %v", err)
+			}
+			if retry.IdempotencyKey != "invoice-7-charge-2" || len(repo.transactions) != 2 {
+				t.Errorf("retry key = %q with %d transactions", retry.IdempotencyKey, len(repo.transactions))
+			}
+		})
+	}
+}
+
+func TestChargeReplaysUnknownOutcome(t *testing.T) {
+	tests := []struct {
+		name    string
+		err     error
+		forward bool
+	}{
+		{"timeout after charging", &payments.Error{Message: "context deadline exceeded"}, true},
+		{"connection refused", errors.New("dial tcp: connection refused"), false},
+		{"server error", &payments.Error{StatusCode: http.StatusBadGateway, Message: "502 Bad Gateway"}, false},
+		{"rate limited", &payments.Error{StatusCode: http.StatusTooManyRequests, Code: "rate_limit", Message: "Too many requests"}, false},
+		{"key in flight", &payments.Error{StatusCode: http.StatusConflict, Code: "idempotency_key_in_use", Message: "in progress"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, provider := newChargeTest(t, "tok_visa")
+			provider.err, provider.forward, provider.fails = tt.err, tt.forward, 1
+			invoice := newPayableInvoice()
+
+			transaction, err := service.charge(context.Background(), invoice)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("charge error = %v, want %v", err, tt.err)
+			}
+			if transaction.Status != models.PaymentStatusPending || transaction.ErrorMessage == "" {
+				t.Errorf("transaction = %s %q, want pending with the error", transaction.Status, transaction.ErrorMessage)
+			}
+			if invoice.Status != models.PaymentStatusPending {
+				t.Errorf("invoice status = %s, want pending", invoice.Status)
+			}
+
+			retry, err := service.charge(context.Background(), invoice)
+			if err != nil {
+				t.Fatalf("retry: %v", err)
+			}
+			if len(provider.keys) != 2 || provider.keys[0] != provider.keys[1] {
+				t.Errorf("idempotency keys sent = %q, want the same key twice", provider.keys)
+			}
+			if retry.ID != transaction.ID || len(repo.transactions) != 1 {
+				t.Errorf("retry recorded transaction %d, %d transactions, want transaction %d reused", retry.ID, len(repo.transactions), transaction.ID)
+			}
+			if retry.Status != models.PaymentStatusSucceeded || retry.ErrorCode != "" || retry.ErrorMessage != "" {
+				t.Errorf("retry = %s %q %q", retry.Status, retry.ErrorCode, retry.ErrorMessage)
+			}
+			// A charge the provider made before the response was lost is
+			// returned again rather than made twice
+			if tt.forward && (len(provider.charged) != 2 || provider.charged[0] != provider.charged[1]) {
+				t.Errorf("charges = %q, want the first charge replayed", provider.charged)
+			}
+			if invoice.Status != models.PaymentStatusSucceeded || invoice.PaymentID != provider.charged[0] {
+				t.Errorf("invoice = %s payment %q", invoice.Status, invoice.PaymentID)
+			}
+		})
+	}
+}
+
+func TestChargeRejectedRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"invalid request", &payments.Error{StatusCode: http.StatusBadRequest, Code: "amount_too_small", Message: "amount must be positive"}},
+		{"missing payment method", payments.ErrPaymentMethodNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo, provider := newChargeTest(t, "tok_visa")
+			provider.err, provider.fails = tt.err, 1
+			invoice := newPayableInvoice()
+
+			transaction, err := service.charge(context.Background(), invoice)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("charge error = %v, want %v", err, tt.err)
+			}
+			if transaction.Status != models.PaymentStatusFailed {
+				t.Errorf("transaction status = %s, want failed", transaction.Status)
+			}
+
+			retry, err := service.charge(context.Background(), invoice)
+			if err != nil {
+				t.Fatalf("retry: %v", err)
+			}
+			if retry.IdempotencyKey != "invoice-7-charge-2" || len(repo.transactions) != 2 {
+				t.Errorf("retry key = %q with %d transactions", retry.IdempotencyKey, len(repo.transactions))
+			}
+		})
+	}
+}
+
+func TestChargePendingPaymentIsReplayed(t *testing.T) {
+	service, repo, provider := newChargeTest(t, payments.FakeTokenPending)
+	invoice := newPayableInvoice()
+
+	first, err := service.charge(context.Background(), invoice)
+	if err != nil {
+		t.Fatalf("charge: %v", err)
+	}
+	if first.Status != models.PaymentStatusPending || invoice.PaymentID == "" {
+		t.Fatalf("transaction = %s, invoice payment %q", first.Status, invoice.PaymentID)
+	}
+
+	// Paying again while the transfer clears must not start a second one
+	second, err := service.charge(context.Background(), invoice)
+	if err != nil {
+		t.Fatalf("second charge: %v", err)
+	}
+	if second.ID != first.ID || len(repo.transactions) != 1 || provider.charged[0] != provider.charged[1] {
+		t.Errorf("second charge = transaction %d, charges %q", second.ID, provider.charged)
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrPlanNotFound = errors.New("plan not found")
+	ErrPlanArchived = errors.New("plan is no longer available")
+)
+
+// PlanFeatureInput describes a feature listed on a plan
+type PlanFeatureInput struct {
+	Key         string // optional, from the feature registry
+	Name        string
+	Description string
+	Included    bool
+}
+
+// PlanInput holds the editable fields of a plan. Price is a decimal in Currency.
+type PlanInput struct {
+	Name            string
+	Description     string
+	Price           string
+	SeatPrice       string // optional, per seat beyond IncludedSeats
+	Currency        string
+	BillingInterval models.BillingInterval
+	IncludedSeats   int
+	TrialDays       int
+	MaxUsers        int
+	MaxProjects     int
+	MaxStorage      int
+	CustomDomain    bool
+	APIAccess       bool
+	PrioritySupport bool
+	Features        []PlanFeatureInput
+}
+
+type PlanService struct {
+	planRepo repositories.PlanRepository
+	userRepo repositories.UserRepository
+	now      func() time.Time
+}
+
+func NewPlanService(planRepo repositories.PlanRepository, userRepo repositories.UserRepository) *PlanService {
+	return &PlanService{
+		planRepo: planRepo,
+		userRepo: userRepo,
+		now:      time.Now,
+	}
+}
+
+// ListPlans lists the plans on offer. Platform admins can include archived plans.
+func (s *PlanService) ListPlans(ctx context.Context, userID uint, includeArchived bool) ([]models.Plan, error) {
+	if includeArchived {
+		if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+			return nil, err
+		}
+	}
+	return s.planRepo.List(ctx, includeArchived)
+}
+
+func (s *PlanService) GetPlan(ctx context.Context, planID uint) (*models.Plan, error) {
+	return s.loadPlan(ctx, planID)
+}
+
+func (s *PlanService) CreatePlan(ctx context.Context, userID uint, input PlanInput) (*models.Plan, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+
+	plan := &models.Plan{}
+	if err := applyPlanInput(plan, input); err != nil {
+		return nil, err
+	}
+	if err := s.planRepo.Create(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// UpdatePlan changes a plan and its features. Organizations subscribed to the
+// plan get its new feature flags straight away; price changes apply from
+// their next renewal.
+func (s *PlanService) UpdatePlan(ctx context.Context, userID, planID uint, input PlanInput) (*models.Plan, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	plan, err := s.loadPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := applyPlanInput(plan, input); err != nil {
+		return nil, err
+	}
+	if err := s.planRepo.Update(ctx, plan); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ArchivePlan withdraws a plan from the catalog. Existing subscriptions keep it.
+func (s *PlanService) ArchivePlan(ctx context.Context, userID, planID uint) (*models.Plan, error) {
+	if err := ensurePlatformAdmin(ctx, s.userRepo, userID); err != nil {
+		return nil, err
+	}
+	plan, err := s.loadPlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.ArchivedAt == nil {
+		now := s.now()
+		plan.ArchivedAt = &now
+		if err := s.planRepo.Update(ctx, plan); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+func (s *PlanService) loadPlan(ctx context.Context, planID uint) (*models.Plan, error) {
+	plan, err := s.planRepo.FindByID(ctx, planID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlanNotFound
+		}
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ensurePlatformAdmin checks that the user operates the platform
+func ensurePlatformAdmin(ctx context.Context, userRepo repositories.UserRepository, userID uint) error {
+	user, err := userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrForbidden
+		}
+		return err
+	}
+	if !user.IsPlatformAdmin {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// applyPlanInput sets the editable fields of a plan
+func applyPlanInput(plan *models.Plan, input PlanInput) error {
+	price, err := parseAmount(input.Price, input.Currency, "")
+	if err != nil {
+		return err
+	}
+	seatPrice := money.Zero(price.Currency)
+	if input.SeatPrice != "" {
+		if seatPrice, err = money.Parse(input.SeatPrice, price.Currency); err != nil {
+			return err
+		}
+	}
+
+	plan.Name = strings.TrimSpace(input.Name)
+	plan.Description = input.Description
+	plan.Price = price
+	plan.SeatPrice = seatPrice
+	plan.IncludedSeats = input.IncludedSeats
+	plan.BillingInterval = input.BillingInterval
+	plan.TrialDays = input.TrialDays
+	plan.MaxUsers = input.MaxUsers
+	plan.MaxProjects = input.MaxProjects
+	plan.MaxStorage = input.MaxStorage
+	plan.CustomDomain = input.CustomDomain
+	plan.APIAccess = input.APIAccess
+	plan.Priority = input.PrioritySupport
+
+	plan.Features = make([]models.PlanFeature, 0, len(input.Features))
+	for _, feature := range input.Features {
+		plan.Features = append(plan.Features, models.PlanFeature{
+			Key:         strings.TrimSpace(feature.Key),
+			Name:        strings.TrimSpace(feature.Name),
+			Description: feature.Description,
+			Included:    feature.Included,
+		})
+	}
+	return plan.Validate()
+}
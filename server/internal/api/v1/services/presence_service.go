@@ -0,0 +1,210 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/realtime"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+const (
+	// PresenceTTL is how long a presence lasts unless its connection renews it
+	PresenceTTL = time.Minute
+	// PresenceRenewInterval is how often open connections renew their presences
+	PresenceRenewInterval = 20 * time.Second
+	// presenceActivityTTL is how long typing or editing lasts unless the
+	// client reports it again
+	presenceActivityTTL = 8 * time.Second
+)
+
+// Viewer is a user present on a page, on one or more connections
+type Viewer struct {
+	UserID        uint                    `json:"user_id"`
+	FirstName     string                  `json:"first_name"`
+	LastName      string                  `json:"last_name"`
+	Activity      models.PresenceActivity `json:"activity"`
+	ActivityUntil *time.Time              `json:"activity_until,omitempty"`
+	Since         time.Time               `json:"since"`
+	Connections   int                     `json:"connections"`
+}
+
+// PresenceService tracks who has tasks and boards open and what they are
+// doing there
+type PresenceService struct {
+	presenceRepo repositories.PresenceRepository
+	taskRepo     repositories.TaskRepository
+	hub          *realtime.Hub
+	access       accessChecker
+	now          func() time.Time
+}
+
+func NewPresenceService(
+	presenceRepo repositories.PresenceRepository,
+	taskRepo repositories.TaskRepository,
+	hub *realtime.Hub,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *PresenceService {
+	return &PresenceService{
+		presenceRepo: presenceRepo,
+		taskRepo:     taskRepo,
+		hub:          hub,
+		access:       accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+		now:          time.Now,
+	}
+}
+
+// NewConnectionID names a new connection, which the presences made over it
+// are stored under
+func (s *PresenceService) NewConnectionID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// Subscribe starts passing the user the changes of the projects their
+// connection follows, which it sets with Follow
+func (s *PresenceService) Subscribe(userID uint) *realtime.Subscription {
+	return s.hub.Subscribe(userID, nil, nil)
+}
+
+// Follow sets the projects a connection's subscription follows, those of the
+// pages it is present on. Enter has checked the user may see them.
+func (s *PresenceService) Follow(sub *realtime.Subscription, projectIDs []uint) {
+	sub.Follow(nil, uniqueIDs(projectIDs))
+}
+
+// Enter records the user as present on a page over a connection, doing the
+// activity, after checking they may see it. Typing and editing lapse unless
+// reported again.
+func (s *PresenceService) Enter(ctx context.Context, userID uint, connectionID string, resource models.PresenceResource, resourceID uint, activity models.PresenceActivity) (*models.Presence, error) {
+	project, err := s.locate(ctx, userID, resource, resourceID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	presence := &models.Presence{
+		ConnectionID:   connectionID,
+		ResourceType:   resource,
+		ResourceID:     resourceID,
+		UserID:         userID,
+		OrganizationID: project.OrganizationID,
+		ProjectID:      project.ID,
+		Activity:       activity,
+		ExpiresAt:      now.Add(PresenceTTL),
+	}
+	if activity != models.PresenceActivityViewing {
+		until := now.Add(presenceActivityTTL)
+		presence.ActivityUntil = &until
+	}
+	if err := presence.Validate(); err != nil {
+		return nil, err
+	}
+	if err := s.presenceRepo.Save(ctx, presence); err != nil {
+		return nil, err
+	}
+	return presence, nil
+}
+
+// Leave records the connection as gone from a page
+func (s *PresenceService) Leave(ctx context.Context, connectionID string, resource models.PresenceResource, resourceID uint) error {
+	return s.presenceRepo.Leave(ctx, connectionID, resource, resourceID)
+}
+
+// Disconnect records a closed connection as gone from every page
+func (s *PresenceService) Disconnect(ctx context.Context, connectionID string) error {
+	return s.presenceRepo.LeaveAll(ctx, connectionID)
+}
+
+// Renew keeps an open connection's presences from lapsing
+func (s *PresenceService) Renew(ctx context.Context, connectionID string) error {
+	_, err := s.presenceRepo.Renew(ctx, connectionID, s.now().Add(PresenceTTL))
+	return err
+}
+
+// Authorize checks the user may still see a page they are present on
+func (s *PresenceService) Authorize(ctx context.Context, userID uint, resource models.PresenceResource, resourceID uint) error {
+	_, err := s.locate(ctx, userID, resource, resourceID)
+	return err
+}
+
+// ListViewers lists the users present on a page, earliest arrival first,
+// with what they are doing there. A user with the page open more than once
+// is listed once, doing the most of what they do on any connection.
+func (s *PresenceService) ListViewers(ctx context.Context, userID uint, resource models.PresenceResource, resourceID uint) ([]Viewer, error) {
+	if _, err := s.locate(ctx, userID, resource, resourceID); err != nil {
+		return nil, err
+	}
+
+	now := s.now()
+	presences, err := s.presenceRepo.ListViewers(ctx, resource, resourceID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	viewers := make([]Viewer, 0, len(presences))
+	index := make(map[uint]int)
+	for _, p := range presences {
+		activity := p.CurrentActivity(now)
+		i, ok := index[p.UserID]
+		if !ok {
+			index[p.UserID] = len(viewers)
+			viewers = append(viewers, Viewer{
+				UserID:    p.UserID,
+				FirstName: p.FirstName,
+				LastName:  p.LastName,
+				Activity:  models.PresenceActivityViewing,
+				Since:     p.CreatedAt,
+			})
+			i = len(viewers) - 1
+		}
+		viewer := &viewers[i]
+		viewer.Connections++
+		if presenceActivityRank[activity] > presenceActivityRank[viewer.Activity] {
+			viewer.Activity = activity
+			viewer.ActivityUntil = p.ActivityUntil
+		}
+	}
+	return viewers, nil
+}
+
+// PruneExpired removes the presences that lapsed, such as those of a server
+// that went away, and returns how many there were
+func (s *PresenceService) PruneExpired(ctx context.Context) (int, error) {
+	return s.presenceRepo.DeleteExpired(ctx, s.now())
+}
+
+// presenceActivityRank orders activities by how much a viewer is doing
+var presenceActivityRank = map[models.PresenceActivity]int{
+	models.PresenceActivityViewing: 0,
+	models.PresenceActivityTyping:  1,
+	models.PresenceActivityEditing: 2,
+}
+
+// locate finds the project a page belongs to, checking the user may see it
+func (s *PresenceService) locate(ctx context.Context, userID uint, resource models.PresenceResource, resourceID uint) (*models.Project, error) {
+	switch resource {
+	case models.PresenceResourceTask:
+		task, err := s.taskRepo.FindByID(ctx, resourceID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrTaskNotFound
+			}
+			return nil, err
+		}
+		return s.access.project(ctx, task.ProjectID, userID)
+	case models.PresenceResourceBoard:
+		return s.access.project(ctx, resourceID, userID)
+	default:
+		return nil, models.ErrInvalidPresenceResource
+	}
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// memPresenceRepo keeps presences by connection and page, lapsing them at
+// their ExpiresAt as the database does
+type memPresenceRepo struct {
+	repositories.PresenceRepository
+	presences map[string]*repositories.PresenceViewer
+	now       func() time.Time
+}
+
+func presenceKey(connectionID string, resource models.PresenceResource, resourceID uint) string {
+	return fmt.Sprintf("%s/%s/%d", connectionID, resource, resourceID)
+}
+
+func (r *memPresenceRepo) Save(ctx context.Context, presence *models.Presence) error {
+	key := presenceKey(presence.ConnectionID, presence.ResourceType, presence.ResourceID)
+	if saved, ok := r.presences[key]; ok {
+		presence.CreatedAt = saved.CreatedAt
+	} else {
+		presence.CreatedAt = r.now()
+	}
+	r.presences[key] = &repositories.PresenceViewer{Presence: *presence}
+	return nil
+}
+
+func (r *memPresenceRepo) Renew(ctx context.Context, connectionID string, expiresAt time.Time) (int64, error) {
+	var renewed int64
+	for _, p := range r.presences {
+		if p.ConnectionID == connectionID {
+			p.ExpiresAt = expiresAt
+			renewed++
+		}
+	}
+	return renewed, nil
+}
+
+func (r *memPresenceRepo) LeaveAll(ctx context.Context, connectionID string) error {
+	for key, p := range r.presences {
+		if p.ConnectionID == connectionID {
+			delete(r.presences, key)
+		}
+	}
+	return nil
+}
+
+func (r *memPresenceRepo) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	deleted := 0
+	for key, p := range r.presences {
+		if !p.ExpiresAt.After(now) {
+			delete(r.presences, key)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (r *memPresenceRepo) ListViewers(ctx context.Context, resource models.PresenceResource, resourceID uint, now time.Time) ([]repositories.PresenceViewer, error) {
+	var viewers []repositories.PresenceViewer
+	for _, p := range r.presences {
+		if p.ResourceType == resource && p.ResourceID == resourceID && p.ExpiresAt.After(now) {
+			viewers = append(viewers, *p)
+		}
+	}
+	sort.Slice(viewers, func(i, j int) bool {
+		if !viewers[i].CreatedAt.Equal(viewers[j].CreatedAt) {
+			return viewers[i].CreatedAt.Before(viewers[j].CreatedAt)
+		}
+		return viewers[i].ConnectionID < viewers[j].ConnectionID
+	})
+	return viewers, nil
+}
+
+// memTaskLookupRepo finds tasks from a map
+type memTaskLookupRepo struct {
+	repositories.TaskRepository
+	tasks map[uint]*models.Task
+}
+
+func (r memTaskLookupRepo) FindByID(ctx context.Context, id uint) (*models.Task, error) {
+	if task, ok := r.tasks[id]; ok {
+		return task, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+// memMembershipRepo makes the users in members members of every organization
+type memMembershipRepo struct {
+	repositories.OrganizationRepository
+	members map[uint]bool
+}
+
+func (r memMembershipRepo) IsMember(ctx context.Context, orgID, userID uint) (bool, error) {
+	return r.members[userID], nil
+}
+
+// presenceClock is a clock tests move forward
+type presenceClock struct {
+	at time.Time
+}
+
+func (c *presenceClock) now() time.Time {
+	return c.at
+}
+
+func newPresenceService(clock *presenceClock) *PresenceService {
+	return &PresenceService{
+		presenceRepo: &memPresenceRepo{presences: make(map[string]*repositories.PresenceViewer), now: clock.now},
+		taskRepo:     memTaskLookupRepo{tasks: map[uint]*models.Task{5: {ProjectID: 100}}},
+		access: accessChecker{
+			orgRepo:     memMembershipRepo{members: map[uint]bool{1: true, 2: true}},
+			projectRepo: memProjectsRepo{projects: map[uint]*models.Project{100: {Model: gorm.Model{ID: 100}, OrganizationID: 10}}},
+		},
+		now: clock.now,
+	}
+}
+
+func TestPresenceExpiry(t *testing.T) {
+	start := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		activity models.PresenceActivity
+		renewAt  []time.Duration // after entering
+		at       time.Duration   // when the page is looked at
+		present  bool
+		doing    models.PresenceActivity
+	}{
+		{"viewing", models.PresenceActivityViewing, nil, 30 * time.Second, true, models.PresenceActivityViewing},
+		{"typing", models.PresenceActivityTyping, nil, 5 * time.Second, true, models.PresenceActivityTyping},
+		{"typing lapsed", models.PresenceActivityTyping, nil, presenceActivityTTL, true, models.PresenceActivityViewing},
+		{"editing lapsed", models.PresenceActivityEditing, nil, 10 * time.Second, true, models.PresenceActivityViewing},
+		{"not renewed", models.PresenceActivityViewing, nil, PresenceTTL, false, ""},
+		{"renewed", models.PresenceActivityViewing, []time.Duration{PresenceRenewInterval, 2 * PresenceRenewInterval}, PresenceTTL + 2*PresenceRenewInterval - time.Second, true, models.PresenceActivityViewing},
+		{"renewals stopped", models.PresenceActivityViewing, []time.Duration{PresenceRenewInterval}, PresenceTTL + PresenceRenewInterval, false, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &presenceClock{at: start}
+			service := newPresenceService(clock)
+			ctx := context.Background()
+			if _, err := service.Enter(ctx, 1, "conn-a", models.PresenceResourceTask, 5, tt.activity); err != nil {
+				t.Fatalf("Enter() error = %v", err)
+			}
+			for _, renewAt := range tt.renewAt {
+				clock.at = start.Add(renewAt)
+				if err := service.Renew(ctx, "conn-a"); err != nil {
+					t.Fatalf("Renew() error = %v", err)
+				}
+			}
+
+			clock.at = start.Add(tt.at)
+			viewers, err := service.ListViewers(ctx, 2, models.PresenceResourceTask, 5)
+			if err != nil {
+				t.Fatalf("ListViewers() error = %v", err)
+			}
+			present, expired := 1, 0
+			if !tt.present {
+				present, expired = 0, 1
+			}
+			if len(viewers) != present {
+				t.Fatalf("got %d viewers, want %d", len(viewers), present)
+			}
+			if tt.present && viewers[0].Activity != tt.doing {
+				t.Errorf("Activity = %s, want %s", viewers[0].Activity, tt.doing)
+			}
+
+			pruned, err := service.PruneExpired(ctx)
+			if err != nil {
+				t.Fatalf("PruneExpired() error = %v", err)
+			}
+			if pruned != expired {
+				t.Errorf("pruned %d presences, want %d", pruned, expired)
+			}
+		})
+	}
+}
+
+func TestListViewersMergesConnections(t *testing.T) {
+	clock := &presenceClock{at: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+	service := newPresenceService(clock)
+	ctx := context.Background()
+	enter := func(userID uint, connectionID string, activity models.PresenceActivity) {
+		t.Helper()
+		if _, err := service.Enter(ctx, userID, connectionID, models.PresenceResourceBoard, 100, activity); err != nil {
+			t.Fatalf("Enter() error = %v", err)
+		}
+		clock.at = clock.at.Add(time.Second)
+	}
+
+	enter(2, "conn-c", models.PresenceActivityViewing)
+	enter(1, "conn-a", models.PresenceActivityViewing)
+	enter(1, "conn-b", models.PresenceActivityEditing)
+	enter(2, "conn-d", models.PresenceActivityTyping)
+	enter(2, "conn-c", models.PresenceActivityViewing) // again, keeping its arrival
+	if err := service.Disconnect(ctx, "conn-d"); err != nil {
+		t.Fatalf("Disconnect() error = %v", err)
+	}
+
+	viewers, err := service.ListViewers(ctx, 1, models.PresenceResourceBoard, 100)
+	if err != nil {
+		t.Fatalf("ListViewers() error = %v", err)
+	}
+	type summary struct {
+		UserID      uint
+		Activity    models.PresenceActivity
+		Connections int
+	}
+	var got []summary
+	for _, viewer := range viewers {
+		got = append(got, summary{viewer.UserID, viewer.Activity, viewer.Connections})
+	}
+	want := []summary{
+		{2, models.PresenceActivityViewing, 1},
+		{1, models.PresenceActivityEditing, 2},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("viewers = %+v, want %+v", got, want)
+	}
+	if viewers[1].ActivityUntil == nil {
+		t.Error("editing viewer has no ActivityUntil")
+	}
+}
+
+func TestEnterPresence(t *testing.T) {
+	tests := []struct {
+		name       string
+		userID     uint
+		resource   models.PresenceResource
+		resourceID uint
+		activity   models.PresenceActivity
+		projectID  uint
+		err        error
+	}{
+		{"task", 1, models.PresenceResourceTask, 5, models.PresenceActivityViewing, 100, nil},
+		{"board", 1, models.PresenceResourceBoard, 100, models.PresenceActivityTyping, 100, nil},
+		{"unknown task", 1, models.PresenceResourceTask, 6, models.PresenceActivityViewing, 0, ErrTaskNotFound},
+		{"unknown board", 1, models.PresenceResourceBoard, 101, models.PresenceActivityViewing, 0, ErrProjectNotFound},
+		{"not a member", 3, models.PresenceResourceTask, 5, models.PresenceActivityViewing, 0, ErrForbidden},
+		{"unknown resource", 1, "sprint", 5, models.PresenceActivityViewing, 0, models.ErrInvalidPresenceResource},
+		{"unknown activity", 1, models.PresenceResourceTask, 5, "dancing", 0, models.ErrInvalidPresenceActivity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &presenceClock{at: time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)}
+			presence, err := newPresenceService(clock).Enter(context.Background(), tt.userID, "conn-a", tt.resource, tt.resourceID, tt.activity)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Enter() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if presence.ProjectID != tt.projectID || presence.OrganizationID != 10 {
+				t.Errorf("presence in project %d of organization %d, want project %d of organization 10",
+					presence.ProjectID, presence.OrganizationID, tt.projectID)
+			}
+			if want := clock.at.Add(PresenceTTL); !presence.ExpiresAt.Equal(want) {
+				t.Errorf("ExpiresAt = %v, want %v", presence.ExpiresAt, want)
+			}
+		})
+	}
+}
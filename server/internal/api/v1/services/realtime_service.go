@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/realtime"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+)
+
+// maxRealtimeTopics is the most organizations and projects one connection
+// may follow
+const maxRealtimeTopics = 50
+
+var ErrTooManyTopics = fmt.Errorf("a connection may follow at most %d organizations and projects", maxRealtimeTopics)
+
+// RealtimeService subscribes clients to the live changes of the
+// organizations and projects they may see
+type RealtimeService struct {
+	hub    *realtime.Hub
+	access accessChecker
+}
+
+func NewRealtimeService(
+	hub *realtime.Hub,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *RealtimeService {
+	return &RealtimeService{
+		hub:    hub,
+		access: accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+	}
+}
+
+// Subscribe follows the changes of the organizations and projects, after
+// checking the user may see them. The user's notifications are always sent.
+func (s *RealtimeService) Subscribe(ctx context.Context, userID uint, orgIDs, projectIDs []uint) (*realtime.Subscription, error) {
+	orgIDs, projectIDs = uniqueIDs(orgIDs), uniqueIDs(projectIDs)
+	if len(orgIDs)+len(projectIDs) > maxRealtimeTopics {
+		return nil, ErrTooManyTopics
+	}
+	if err := s.authorize(ctx, userID, orgIDs, projectIDs); err != nil {
+		return nil, err
+	}
+	return s.hub.Subscribe(userID, orgIDs, projectIDs), nil
+}
+
+// Authorize checks the subscription's user may still see what it follows
+func (s *RealtimeService) Authorize(ctx context.Context, sub *realtime.Subscription) error {
+	return s.authorize(ctx, sub.UserID, sub.Organizations, sub.Projects)
+}
+
+// AffectsAccess checks if an event may take away what some users can see,
+// so that their subscriptions are to be checked again
+func (s *RealtimeService) AffectsAccess(event *models.RealtimeEvent) bool {
+	switch models.ActivityEntity(event.Type) {
+	case models.ActivityEntityOrganizationMember:
+		return event.Action != models.ActivityActionCreated
+	case models.ActivityEntityProject:
+		return event.Action == models.ActivityActionDeleted
+	}
+	return false
+}
+
+func (s *RealtimeService) authorize(ctx context.Context, userID uint, orgIDs, projectIDs []uint) error {
+	for _, orgID := range orgIDs {
+		if err := s.access.member(ctx, orgID, userID); err != nil {
+			if errors.Is(err, ErrForbidden) {
+				return fmt.Errorf("%w: organization %d", err, orgID)
+			}
+			return err
+		}
+	}
+	for _, projectID := range projectIDs {
+		if _, err := s.access.project(ctx, projectID, userID); err != nil {
+			if errors.Is(err, ErrForbidden) || errors.Is(err, ErrProjectNotFound) {
+				return fmt.Errorf("%w: project %d", err, projectID)
+			}
+			return err
+		}
+	}
+	return nil
+}
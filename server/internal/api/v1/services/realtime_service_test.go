@@ -0,0 +1,97 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/realtime"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// memProjectsRepo finds projects from a map
+type memProjectsRepo struct {
+	repositories.ProjectRepository
+	projects map[uint]*models.Project
+}
+
+func (r memProjectsRepo) FindByID(ctx context.Context, id uint) (*models.Project, error) {
+	if project, ok := r.projects[id]; ok {
+		return project, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func TestRealtimeSubscribe(t *testing.T) {
+	many := make([]uint, maxRealtimeTopics+1)
+	for i := range many {
+		many[i] = uint(i + 1)
+	}
+
+	tests := []struct {
+		name       string
+		userID     uint
+		orgIDs     []uint
+		projectIDs []uint
+		err        error
+	}{
+		{"member", 1, []uint{10}, []uint{100}, nil},
+		{"duplicates count once", 1, append(many[:maxRealtimeTopics:maxRealtimeTopics], 1), nil, nil},
+		{"too many topics", 1, many[:maxRealtimeTopics-1], []uint{100, 100, 101}, ErrTooManyTopics},
+		{"not a member of the organization", 2, []uint{10}, nil, ErrForbidden},
+		{"not a member of the project's organization", 2, nil, []uint{100}, ErrForbidden},
+		{"unknown project", 1, nil, []uint{999}, ErrProjectNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hub := realtime.NewHub()
+			service := &RealtimeService{
+				hub: hub,
+				access: accessChecker{
+					orgRepo: memMemberRepo{},
+					projectRepo: memProjectsRepo{projects: map[uint]*models.Project{
+						100: {OrganizationID: 10},
+						101: {OrganizationID: 10},
+					}},
+				},
+			}
+			sub, err := service.Subscribe(context.Background(), tt.userID, tt.orgIDs, tt.projectIDs)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Subscribe() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			defer sub.Close()
+			if err := service.Authorize(context.Background(), sub); err != nil {
+				t.Errorf("Authorize() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestRealtimeAffectsAccess(t *testing.T) {
+	tests := []struct {
+		entity  models.ActivityEntity
+		action  models.ActivityAction
+		affects bool
+	}{
+		{models.ActivityEntityOrganizationMember, models.ActivityActionCreated, false},
+		{models.ActivityEntityOrganizationMember, models.ActivityActionUpdated, true},
+		{models.ActivityEntityOrganizationMember, models.ActivityActionDeleted, true},
+		{models.ActivityEntityProject, models.ActivityActionUpdated, false},
+		{models.ActivityEntityProject, models.ActivityActionDeleted, true},
+		{models.ActivityEntityTask, models.ActivityActionDeleted, false},
+	}
+	service := &RealtimeService{}
+	for _, tt := range tests {
+		t.Run(string(tt.entity)+"/"+string(tt.action), func(t *testing.T) {
+			event := &models.RealtimeEvent{Type: string(tt.entity), Action: tt.action}
+			if got := service.AffectsAccess(event); got != tt.affects {
+				t.Errorf("AffectsAccess() = %v, want %v", got, tt.affects)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSavedFilterNotFound = errors.New("saved filter not found")
+	ErrNotFilterOwner      = errors.New("only the owner can change this filter")
+)
+
+// SavedFilterInput holds the editable fields of a saved filter
+type SavedFilterInput struct {
+	ProjectID   *uint
+	Name        string
+	Description string
+	Query       string
+	Sort        string
+	Visibility  models.FilterVisibility
+}
+
+type SavedFilterService struct {
+	filterRepo  repositories.SavedFilterRepository
+	taskService *TaskService
+	access      accessChecker
+}
+
+func NewSavedFilterService(
+	filterRepo repositories.SavedFilterRepository,
+	taskService *TaskService,
+	entitlements *EntitlementService,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *SavedFilterService {
+	return &SavedFilterService{
+		filterRepo:  filterRepo,
+		taskService: taskService,
+		access:      accessChecker{orgRepo: orgRepo, projectRepo: projectRepo, feature: featureGate{entitlements, models.FeatureSavedFilters}},
+	}
+}
+
+// CreateFilter saves a task query after checking that it compiles
+func (s *SavedFilterService) CreateFilter(ctx context.Context, userID, orgID uint, input SavedFilterInput) (*models.SavedFilter, error) {
+	if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	if _, err := s.taskService.ParseQuery(ctx, userID, orgID, input.ProjectID, input.Query); err != nil {
+		return nil, err
+	}
+
+	filter := &models.SavedFilter{
+		OrganizationID: orgID,
+		OwnerID:        userID,
+		ProjectID:      input.ProjectID,
+		Name:           input.Name,
+		Description:    input.Description,
+		Query:          input.Query,
+		Sort:           input.Sort,
+		Visibility:     input.Visibility,
+	}
+	if filter.Visibility == "" {
+		filter.Visibility = models.FilterVisibilityPrivate
+	}
+
+	if err := s.filterRepo.Create(ctx, filter); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+// ListFilters lists the user's own filters and those shared with the organization
+func (s *SavedFilterService) ListFilters(ctx context.Context, userID, orgID uint) ([]models.SavedFilter, error) {
+	if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.filterRepo.ListVisible(ctx, orgID, userID)
+}
+
+func (s *SavedFilterService) GetFilter(ctx context.Context, userID, filterID uint) (*models.SavedFilter, error) {
+	return s.loadFilter(ctx, userID, filterID)
+}
+
+func (s *SavedFilterService) UpdateFilter(ctx context.Context, userID, filterID uint, input SavedFilterInput) (*models.SavedFilter, error) {
+	filter, err := s.loadFilter(ctx, userID, filterID)
+	if err != nil {
+		return nil, err
+	}
+	if filter.OwnerID != userID {
+		return nil, ErrNotFilterOwner
+	}
+
+	if _, err := s.taskService.ParseQuery(ctx, userID, filter.OrganizationID, input.ProjectID, input.Query); err != nil {
+		return nil, err
+	}
+
+	filter.ProjectID = input.ProjectID
+	filter.Name = input.Name
+	filter.Description = input.Description
+	filter.Query = input.Query
+	filter.Sort = input.Sort
+	if input.Visibility != "" {
+		filter.Visibility = input.Visibility
+	}
+
+	if err := s.filterRepo.Update(ctx, filter); err != nil {
+		return nil, err
+	}
+	return filter, nil
+}
+
+func (s *SavedFilterService) DeleteFilter(ctx context.Context, userID, filterID uint) error {
+	filter, err := s.loadFilter(ctx, userID, filterID)
+	if err != nil {
+		return err
+	}
+	if filter.OwnerID != userID {
+		return ErrNotFilterOwner
+	}
+	return s.filterRepo.Delete(ctx, filter.ID)
+}
+
+// FilterTasks runs a saved filter and returns one page of its tasks
+func (s *SavedFilterService) FilterTasks(ctx context.Context, userID, filterID uint, page, pageSize int) (*TaskPage, error) {
+	filter, err := s.loadFilter(ctx, userID, filterID)
+	if err != nil {
+		return nil, err
+	}
+	return s.RunFilter(ctx, userID, filter, page, pageSize)
+}
+
+// RunFilter returns one page of a filter's tasks as seen by the user, who
+// "me" in the query refers to
+func (s *SavedFilterService) RunFilter(ctx context.Context, userID uint, filter *models.SavedFilter, page, pageSize int) (*TaskPage, error) {
+	params := TaskListParams{
+		Query:    filter.Query,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for _, sort := range strings.Split(filter.Sort, ",") {
+		if sort = strings.TrimSpace(sort); sort != "" {
+			params.Sort = append(params.Sort, sort)
+		}
+	}
+
+	if filter.ProjectID != nil {
+		return s.taskService.ListTasks(ctx, userID, *filter.ProjectID, params)
+	}
+	return s.taskService.SearchTasks(ctx, userID, filter.OrganizationID, params)
+}
+
+// Subscribe subscribes the user to a filter, or changes the frequency of an
+// existing subscription
+func (s *SavedFilterService) Subscribe(ctx context.Context, userID, filterID uint, frequency models.SubscriptionFrequency) (*models.FilterSubscription, error) {
+	filter, err := s.loadFilter(ctx, userID, filterID)
+	if err != nil {
+		return nil, err
+	}
+
+	if frequency == "" {
+		frequency = models.SubscriptionFrequencyDaily
+	}
+	subscription := &models.FilterSubscription{
+		FilterID:  filter.ID,
+		UserID:    userID,
+		Frequency: frequency,
+	}
+	if err := s.filterRepo.Subscribe(ctx, subscription); err != nil {
+		return nil, err
+	}
+	return s.filterRepo.FindSubscription(ctx, filter.ID, userID)
+}
+
+func (s *SavedFilterService) Unsubscribe(ctx context.Context, userID, filterID uint) error {
+	filter, err := s.loadFilter(ctx, userID, filterID)
+	if err != nil {
+		return err
+	}
+	return s.filterRepo.Unsubscribe(ctx, filter.ID, userID)
+}
+
+// loadFilter loads a filter the user can see. Private filters of other users
+// are reported as not found.
+func (s *SavedFilterService) loadFilter(ctx context.Context, userID, filterID uint) (*models.SavedFilter, error) {
+	filter, err := s.filterRepo.FindByID(ctx, filterID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrSavedFilterNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.organization(ctx, filter.OrganizationID, userID); err != nil {
+		return nil, err
+	}
+	if !filter.IsVisibleTo(userID) {
+		return nil, ErrSavedFilterNotFound
+	}
+	return filter, nil
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+)
+
+const (
+	defaultSearchPageSize = 20
+	maxSearchPageSize     = 100
+	maxSearchQueryLength  = 256
+)
+
+var ErrInvalidSearch = errors.New("invalid search")
+
+// SearchParams holds a search request
+type SearchParams struct {
+	Query     string
+	ProjectID *uint
+	Types     []string
+	Page      int
+	PageSize  int
+}
+
+// SearchResults is one page of search hits. Snippets are HTML-escaped with
+// matched words wrapped in <mark> tags.
+type SearchResults struct {
+	Results  []repositories.SearchHit `json:"results"`
+	Page     int                      `json:"page"`
+	PageSize int                      `json:"page_size"`
+	HasMore  bool                     `json:"has_more"`
+}
+
+type SearchService struct {
+	searchRepo repositories.SearchRepository
+	userRepo   repositories.UserRepository
+	access     accessChecker
+}
+
+func NewSearchService(
+	searchRepo repositories.SearchRepository,
+	userRepo repositories.UserRepository,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *SearchService {
+	return &SearchService{
+		searchRepo: searchRepo,
+		userRepo:   userRepo,
+		access:     accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+	}
+}
+
+// Search finds tasks, comments and projects of an organization matching the
+// query. Words are stemmed in the searching user's language.
+func (s *SearchService) Search(ctx context.Context, userID, orgID uint, params SearchParams) (*SearchResults, error) {
+	text := strings.TrimSpace(params.Query)
+	if text == "" {
+		return nil, fmt.Errorf("%w: query cannot be empty", ErrInvalidSearch)
+	}
+	if utf8.RuneCountInString(text) > maxSearchQueryLength {
+		return nil, fmt.Errorf("%w: query cannot be longer than %d characters", ErrInvalidSearch, maxSearchQueryLength)
+	}
+	for _, kind := range params.Types {
+		if !repositories.IsSearchType(kind) {
+			return nil, fmt.Errorf("%w: unknown type %q", ErrInvalidSearch, kind)
+		}
+	}
+
+	if params.ProjectID != nil {
+		project, err := s.access.project(ctx, *params.ProjectID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if project.OrganizationID != orgID {
+			return nil, ErrProjectNotFound
+		}
+	} else if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	config := models.DefaultSearchConfig
+	if user, err := s.userRepo.FindByID(ctx, userID); err == nil {
+		config = models.SearchConfig(user.Language)
+	}
+
+	page := params.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := params.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultSearchPageSize
+	}
+	if pageSize > maxSearchPageSize {
+		pageSize = maxSearchPageSize
+	}
+
+	hits, err := s.searchRepo.Search(ctx, repositories.SearchQuery{
+		OrganizationID: orgID,
+		ProjectID:      params.ProjectID,
+		Types:          params.Types,
+		Text:           text,
+		Config:         config,
+		Limit:          pageSize + 1,
+		Offset:         (page - 1) * pageSize,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(hits) > pageSize
+	if hasMore {
+		hits = hits[:pageSize]
+	}
+	for i := range hits {
+		hits[i].Snippet = highlight(hits[i].Snippet)
+	}
+
+	return &SearchResults{
+		Results:  hits,
+		Page:     page,
+		PageSize: pageSize,
+		HasMore:  hasMore,
+	}, nil
+}
+
+// highlight escapes a snippet for HTML and turns the match markers into <mark> tags
+func highlight(snippet string) string {
+	return strings.NewReplacer(
+		repositories.SnippetStart, "<mark>",
+		repositories.SnippetStop, "</mark>",
+	).Replace(html.EscapeString(snippet))
+}
@@ -0,0 +1,110 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+)
+
+// memSearchRepo returns hits numbered from the query's offset, up to total,
+// and records the last query
+type memSearchRepo struct {
+	total int
+	query repositories.SearchQuery
+}
+
+func (r *memSearchRepo) Search(ctx context.Context, query repositories.SearchQuery) ([]repositories.SearchHit, error) {
+	r.query = query
+	var hits []repositories.SearchHit
+	for i := query.Offset; i < r.total && len(hits) < query.Limit; i++ {
+		hits = append(hits, repositories.SearchHit{
+			Type:    repositories.SearchTypeTask,
+			ID:      uint(i + 1),
+			Snippet: fmt.Sprintf("<b>%slaunch%s</b> plan %d", repositories.SnippetStart, repositories.SnippetStop, i+1),
+		})
+	}
+	return hits, nil
+}
+
+// memUserRepo finds users by ID
+type memUserRepo struct {
+	repositories.UserRepository
+	users map[uint]models.User
+}
+
+func (r memUserRepo) FindByID(ctx context.Context, id uint) (*models.User, error) {
+	user, ok := r.users[id]
+	if !ok {
+		return nil, errors.New("user not found")
+	}
+	return &user, nil
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name     string
+		params   SearchParams
+		userID   uint
+		total    int
+		page     int
+		pageSize int
+		hits     int
+		hasMore  bool
+		offset   int
+		err      error
+	}{
+		{"first page", SearchParams{Query: "launch"}, 1, 30, 1, defaultSearchPageSize, 20, true, 0, nil},
+		{"last page", SearchParams{Query: "launch", Page: 2}, 1, 30, 2, defaultSearchPageSize, 10, false, 20, nil},
+		{"exactly one page", SearchParams{Query: "launch", PageSize: 10}, 1, 10, 1, 10, 10, false, 0, nil},
+		{"page size capped", SearchParams{Query: "launch", PageSize: 500}, 1, 300, 1, maxSearchPageSize, 100, true, 0, nil},
+		{"page before the first", SearchParams{Query: "launch", Page: -3}, 1, 5, 1, defaultSearchPageSize, 5, false, 0, nil},
+		{"blank query", SearchParams{Query: "   "}, 1, 5, 0, 0, 0, false, 0, ErrInvalidSearch},
+		{"query too long", SearchParams{Query: strings.Repeat("ü", maxSearchQueryLength+1)}, 1, 5, 0, 0, 0, false, 0, ErrInvalidSearch},
+		{"unknown type", SearchParams{Query: "launch", Types: []string{"invoice"}}, 1, 5, 0, 0, 0, false, 0, ErrInvalidSearch},
+		{"not a member", SearchParams{Query: "launch"}, 2, 5, 0, 0, 0, false, 0, ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &memSearchRepo{total: tt.total}
+			service := &SearchService{
+				searchRepo: repo,
+				userRepo:   memUserRepo{users: map[uint]models.User{1: {Language: "de-AT"}}},
+				access:     accessChecker{orgRepo: memMemberRepo{}},
+			}
+			results, err := service.Search(context.Background(), tt.userID, 1, tt.params)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Search error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if results.Page != tt.page || results.PageSize != tt.pageSize || len(results.Results) != tt.hits || results.HasMore != tt.hasMore {
+				t.Errorf("page %d of %d with %d hits, more %v; want page %d of %d with %d hits, more %v",
+					results.Page, results.PageSize, len(results.Results), results.HasMore, tt.page, tt.pageSize, tt.hits, tt.hasMore)
+			}
+			if repo.query.Offset != tt.offset || repo.query.Limit != tt.pageSize+1 || repo.query.Config != "german" || repo.query.OrganizationID != 1 {
+				t.Errorf("query = %+v", repo.query)
+			}
+		})
+	}
+}
+
+func TestSearchHighlightsEscapedSnippets(t *testing.T) {
+	service := &SearchService{
+		searchRepo: &memSearchRepo{total: 1},
+		userRepo:   memUserRepo{},
+		access:     accessChecker{orgRepo: memMemberRepo{}},
+	}
+	results, err := service.Search(context.Background(), 1, 1, SearchParams{Query: "launch"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "&lt;b&gt;<mark>launch</mark>&lt;/b&gt; plan 1"; results.Results[0].Snippet != want {
+		t.Errorf("snippet = %q, want %q", results.Results[0].Snippet, want)
+	}
+}
@@ -0,0 +1,566 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrSprintNotFound         = errors.New("sprint not found")
+	ErrSprintNotPlanned       = errors.New("sprint has already been started")
+	ErrSprintNotActive        = errors.New("sprint is not active")
+	ErrSprintCompleted        = errors.New("sprint is already completed")
+	ErrActiveSprintExists     = errors.New("project already has an active sprint")
+	ErrTaskNotInProject       = errors.New("task does not belong to the sprint's project")
+	ErrInvalidCarryOverSprint = errors.New("carry-over sprint must be another open sprint in the same project")
+)
+
+// SprintInput holds the editable fields of a sprint
+type SprintInput struct {
+	Name           string
+	Goal           string
+	StartDate      time.Time
+	EndDate        time.Time
+	TeamID         *uint
+	EstimationUnit models.EstimationUnit
+}
+
+// BurndownPoint is the state of a sprint at the end of one day
+type BurndownPoint struct {
+	Date      string  `json:"date"`
+	Scope     float64 `json:"scope"`
+	Completed float64 `json:"completed"`
+	Remaining float64 `json:"remaining"`
+	Ideal     float64 `json:"ideal"`
+}
+
+// BurndownReport holds daily burndown and burnup series for a sprint
+type BurndownReport struct {
+	SprintID  uint                  `json:"sprint_id"`
+	Unit      models.EstimationUnit `json:"unit"`
+	StartDate time.Time             `json:"start_date"`
+	EndDate   time.Time             `json:"end_date"`
+	Committed float64               `json:"committed"`
+	Points    []BurndownPoint       `json:"points"`
+}
+
+// VelocityEntry is the committed and completed work of one completed sprint
+type VelocityEntry struct {
+	SprintID  uint      `json:"sprint_id"`
+	Name      string    `json:"name"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Committed float64   `json:"committed"`
+	Completed float64   `json:"completed"`
+}
+
+// VelocityReport holds a team's historical velocity
+type VelocityReport struct {
+	TeamID          uint                  `json:"team_id"`
+	Unit            models.EstimationUnit `json:"unit"`
+	Sprints         []VelocityEntry       `json:"sprints"`
+	AverageVelocity float64               `json:"average_velocity"`
+}
+
+type SprintService struct {
+	sprintRepo repositories.SprintRepository
+	taskRepo   repositories.TaskRepository
+	teamRepo   repositories.TeamRepository
+	access     accessChecker
+}
+
+func NewSprintService(
+	sprintRepo repositories.SprintRepository,
+	taskRepo repositories.TaskRepository,
+	teamRepo repositories.TeamRepository,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *SprintService {
+	return &SprintService{
+		sprintRepo: sprintRepo,
+		taskRepo:   taskRepo,
+		teamRepo:   teamRepo,
+		access:     accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+	}
+}
+
+func (s *SprintService) CreateSprint(ctx context.Context, userID, projectID uint, input SprintInput) (*models.Sprint, error) {
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTeam(ctx, project, input.TeamID); err != nil {
+		return nil, err
+	}
+
+	sprint := &models.Sprint{
+		Name:           input.Name,
+		Goal:           input.Goal,
+		Status:         models.SprintStatusPlanned,
+		EstimationUnit: input.EstimationUnit,
+		StartDate:      input.StartDate,
+		EndDate:        input.EndDate,
+		ProjectID:      project.ID,
+		TeamID:         input.TeamID,
+	}
+	if sprint.EstimationUnit == "" {
+		sprint.EstimationUnit = models.EstimationUnitStoryPoints
+	}
+
+	if err := s.sprintRepo.Create(ctx, sprint); err != nil {
+		return nil, err
+	}
+	return sprint, nil
+}
+
+func (s *SprintService) ListSprints(ctx context.Context, userID, projectID uint) ([]models.Sprint, error) {
+	if _, err := s.access.project(ctx, projectID, userID); err != nil {
+		return nil, err
+	}
+	return s.sprintRepo.ListByProject(ctx, projectID)
+}
+
+func (s *SprintService) GetSprint(ctx context.Context, userID, sprintID uint) (*models.Sprint, error) {
+	sprint, _, err := s.loadSprint(ctx, userID, sprintID)
+	return sprint, err
+}
+
+func (s *SprintService) UpdateSprint(ctx context.Context, userID, sprintID uint, input SprintInput) (*models.Sprint, error) {
+	sprint, project, err := s.loadSprint(ctx, userID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.IsCompleted() {
+		return nil, ErrSprintCompleted
+	}
+	if err := s.checkTeam(ctx, project, input.TeamID); err != nil {
+		return nil, err
+	}
+
+	sprint.Name = input.Name
+	sprint.Goal = input.Goal
+	sprint.StartDate = input.StartDate
+	sprint.EndDate = input.EndDate
+	sprint.TeamID = input.TeamID
+
+	// The unit cannot change once scope has been committed
+	if input.EstimationUnit != "" && !sprint.IsActive() {
+		sprint.EstimationUnit = input.EstimationUnit
+	}
+
+	if err := s.sprintRepo.Update(ctx, sprint); err != nil {
+		return nil, err
+	}
+	return sprint, nil
+}
+
+func (s *SprintService) DeleteSprint(ctx context.Context, userID, sprintID uint) error {
+	sprint, _, err := s.loadSprint(ctx, userID, sprintID)
+	if err != nil {
+		return err
+	}
+	if sprint.Status != models.SprintStatusPlanned {
+		return ErrSprintNotPlanned
+	}
+	return s.sprintRepo.Delete(ctx, sprint.ID)
+}
+
+// AddTasks adds tasks to the sprint. Tasks added to an active sprint count as scope creep.
+func (s *SprintService) AddTasks(ctx context.Context, userID, sprintID uint, taskIDs []uint) (*models.Sprint, error) {
+	sprint, _, err := s.loadSprint(ctx, userID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.IsCompleted() {
+		return nil, ErrSprintCompleted
+	}
+
+	tasks, err := s.taskRepo.FindByIDs(ctx, taskIDs)
+	if err != nil {
+		return nil, err
+	}
+	if len(tasks) != len(uniqueIDs(taskIDs)) {
+		return nil, ErrTaskNotFound
+	}
+
+	var newIDs []uint
+	for _, task := range tasks {
+		if task.ProjectID != sprint.ProjectID {
+			return nil, ErrTaskNotInProject
+		}
+		if task.SprintID != nil && *task.SprintID == sprint.ID {
+			continue
+		}
+		newIDs = append(newIDs, task.ID)
+	}
+
+	if len(newIDs) > 0 {
+		if err := s.sprintRepo.AddTasks(ctx, sprint, newIDs, false); err != nil {
+			return nil, err
+		}
+	}
+	return s.sprintRepo.FindByID(ctx, sprint.ID)
+}
+
+func (s *SprintService) RemoveTask(ctx context.Context, userID, sprintID, taskID uint) error {
+	sprint, _, err := s.loadSprint(ctx, userID, sprintID)
+	if err != nil {
+		return err
+	}
+	if sprint.IsCompleted() {
+		return ErrSprintCompleted
+	}
+	return s.sprintRepo.RemoveTask(ctx, sprint.ID, taskID)
+}
+
+// StartSprint activates a planned sprint and snapshots its committed scope
+func (s *SprintService) StartSprint(ctx context.Context, userID, sprintID uint) (*models.Sprint, error) {
+	sprint, _, err := s.loadSprint(ctx, userID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if sprint.Status != models.SprintStatusPlanned {
+		return nil, ErrSprintNotPlanned
+	}
+
+	active, err := s.sprintRepo.FindActiveByProject(ctx, sprint.ProjectID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if active != nil {
+		return nil, ErrActiveSprintExists
+	}
+
+	committed := 0.0
+	for _, task := range sprint.Tasks {
+		committed += task.Estimate(sprint.Unit())
+	}
+
+	now := time.Now()
+	sprint.Status = models.SprintStatusActive
+	sprint.StartedAt = &now
+	sprint.CommittedPoints = committed
+
+	started, err := s.sprintRepo.Start(ctx, sprint)
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, ErrActiveSprintExists
+	}
+	return sprint, nil
+}
+
+// CompleteSprint closes an active sprint. Unfinished tasks are carried over to
+// nextSprintID when given, or returned to the project backlog otherwise.
+func (s *SprintService) CompleteSprint(ctx context.Context, userID, sprintID uint, nextSprintID *uint) (*models.Sprint, error) {
+	sprint, _, err := s.loadSprint(ctx, userID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+	if !sprint.IsActive() {
+		return nil, ErrSprintNotActive
+	}
+
+	var next *models.Sprint
+	if nextSprintID != nil {
+		next, err = s.sprintRepo.FindByID(ctx, *nextSprintID)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, ErrInvalidCarryOverSprint
+			}
+			return nil, err
+		}
+		if next.ID == sprint.ID || next.ProjectID != sprint.ProjectID || next.IsCompleted() {
+			return nil, ErrInvalidCarryOverSprint
+		}
+	}
+
+	completed := 0.0
+	var unfinished []uint
+	for _, task := range sprint.Tasks {
+		if task.IsComplete() {
+			completed += task.Estimate(sprint.Unit())
+			continue
+		}
+		unfinished = append(unfinished, task.ID)
+	}
+
+	now := time.Now()
+	sprint.Status = models.SprintStatusCompleted
+	sprint.CompletedAt = &now
+	sprint.CompletedPoints = completed
+
+	if err := s.sprintRepo.Complete(ctx, sprint, unfinished, next); err != nil {
+		return nil, err
+	}
+	return s.sprintRepo.FindByID(ctx, sprint.ID)
+}
+
+// Burndown builds daily burndown and burnup series from task status history
+func (s *SprintService) Burndown(ctx context.Context, userID, sprintID uint) (*BurndownReport, error) {
+	sprint, _, err := s.loadSprint(ctx, userID, sprintID)
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := s.sprintRepo.ListScope(ctx, sprint.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	last := sprint.EndDate
+	if sprint.CompletedAt != nil && sprint.CompletedAt.Before(last) {
+		last = *sprint.CompletedAt
+	}
+	if now := time.Now(); now.Before(last) {
+		last = now
+	}
+
+	history, err := s.statusHistory(ctx, scope, last)
+	if err != nil {
+		return nil, err
+	}
+
+	unit := sprint.Unit()
+	days := sprintDays(sprint.StartDate, sprint.EndDate)
+
+	committed := sprint.CommittedPoints
+	if sprint.StartedAt == nil {
+		committed = scopeAt(scope, unit, history, sprint.StartDate).scope
+	}
+
+	report := &BurndownReport{
+		SprintID:  sprint.ID,
+		Unit:      unit,
+		StartDate: sprint.StartDate,
+		EndDate:   sprint.EndDate,
+		Committed: committed,
+		Points:    []BurndownPoint{},
+	}
+
+	for i, day := range days {
+		if day.After(last) {
+			break
+		}
+		dayEnd := day.AddDate(0, 0, 1)
+		if dayEnd.After(last) {
+			dayEnd = last
+		}
+
+		totals := scopeAt(scope, unit, history, dayEnd)
+		ideal := committed
+		if len(days) > 1 {
+			ideal = committed * (1 - float64(i)/float64(len(days)-1))
+		}
+
+		report.Points = append(report.Points, BurndownPoint{
+			Date:      day.Format("2006-01-02"),
+			Scope:     totals.scope,
+			Completed: totals.completed,
+			Remaining: totals.scope - totals.completed,
+			Ideal:     ideal,
+		})
+	}
+
+	return report, nil
+}
+
+// TeamVelocity computes committed and completed work for a team's most recent
+// completed sprints using the given estimation unit
+func (s *SprintService) TeamVelocity(ctx context.Context, userID, teamID uint, unit models.EstimationUnit, limit int) (*VelocityReport, error) {
+	team, err := s.teamRepo.FindByID(ctx, teamID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTeamNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.organization(ctx, team.OrganizationID, userID); err != nil {
+		return nil, err
+	}
+
+	if unit == "" {
+		unit = models.EstimationUnitStoryPoints
+	}
+
+	sprints, err := s.sprintRepo.ListCompletedByTeam(ctx, team.ID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &VelocityReport{
+		TeamID:  team.ID,
+		Unit:    unit,
+		Sprints: []VelocityEntry{},
+	}
+
+	total := 0.0
+	for _, sprint := range sprints {
+		if sprint.Unit() != unit {
+			continue
+		}
+
+		scope, err := s.sprintRepo.ListScope(ctx, sprint.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		closedAt := sprint.EndDate
+		if sprint.CompletedAt != nil {
+			closedAt = *sprint.CompletedAt
+		}
+
+		history, err := s.statusHistory(ctx, scope, closedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		totals := scopeAt(scope, unit, history, closedAt)
+		report.Sprints = append(report.Sprints, VelocityEntry{
+			SprintID:  sprint.ID,
+			Name:      sprint.Name,
+			StartDate: sprint.StartDate,
+			EndDate:   sprint.EndDate,
+			Committed: sprint.CommittedPoints,
+			Completed: totals.completed,
+		})
+		total += totals.completed
+	}
+
+	if len(report.Sprints) > 0 {
+		report.AverageVelocity = total / float64(len(report.Sprints))
+	}
+	return report, nil
+}
+
+// loadSprint loads a sprint and checks the user's access to its project
+func (s *SprintService) loadSprint(ctx context.Context, userID, sprintID uint) (*models.Sprint, *models.Project, error) {
+	sprint, err := s.sprintRepo.FindByID(ctx, sprintID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, ErrSprintNotFound
+		}
+		return nil, nil, err
+	}
+	project, err := s.access.project(ctx, sprint.ProjectID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sprint, project, nil
+}
+
+// checkTeam ensures the team, if any, belongs to the project's organization
+func (s *SprintService) checkTeam(ctx context.Context, project *models.Project, teamID *uint) error {
+	if teamID == nil {
+		return nil
+	}
+	team, err := s.teamRepo.FindByID(ctx, *teamID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrTeamNotFound
+		}
+		return err
+	}
+	if team.OrganizationID != project.OrganizationID {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// statusHistory loads status changes for the scope's tasks grouped by task ID
+func (s *SprintService) statusHistory(ctx context.Context, scope []models.SprintTask, until time.Time) (map[uint][]models.TaskStatusChange, error) {
+	taskIDs := make([]uint, 0, len(scope))
+	for _, entry := range scope {
+		taskIDs = append(taskIDs, entry.TaskID)
+	}
+
+	changes, err := s.taskRepo.FindStatusChanges(ctx, uniqueIDs(taskIDs), until)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make(map[uint][]models.TaskStatusChange)
+	for _, change := range changes {
+		history[change.TaskID] = append(history[change.TaskID], change)
+	}
+	return history, nil
+}
+
+type scopeTotals struct {
+	scope     float64
+	completed float64
+}
+
+// scopeAt sums the size of the tasks in scope at the given time and of those done by then
+func scopeAt(scope []models.SprintTask, unit models.EstimationUnit, history map[uint][]models.TaskStatusChange, at time.Time) scopeTotals {
+	var totals scopeTotals
+	seen := make(map[uint]bool)
+	for _, entry := range scope {
+		if seen[entry.TaskID] || !entry.InScopeAt(at) {
+			continue
+		}
+		seen[entry.TaskID] = true
+
+		estimate := entry.Task.Estimate(unit)
+		totals.scope += estimate
+		if statusAt(&entry.Task, history[entry.TaskID], at) == models.TaskStatusDone {
+			totals.completed += estimate
+		}
+	}
+	return totals
+}
+
+// statusAt replays a task's status changes to find its status at the given time.
+// Tasks without recorded history fall back to their completion timestamp.
+func statusAt(task *models.Task, changes []models.TaskStatusChange, at time.Time) models.TaskStatus {
+	if len(changes) == 0 {
+		if task.CompletedAt != nil && !task.CompletedAt.After(at) {
+			return models.TaskStatusDone
+		}
+		return models.TaskStatusTodo
+	}
+
+	status := changes[0].FromStatus
+	for _, change := range changes {
+		if change.ChangedAt.After(at) {
+			break
+		}
+		status = change.ToStatus
+	}
+	return status
+}
+
+// sprintDays returns the midnight (UTC) of every calendar day the sprint spans
+func sprintDays(start, end time.Time) []time.Time {
+	start = start.UTC()
+	end = end.UTC()
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+
+	var days []time.Time
+	for !day.After(end) {
+		days = append(days, day)
+		day = day.AddDate(0, 0, 1)
+	}
+	return days
+}
+
+// uniqueIDs removes duplicate IDs while keeping their order
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
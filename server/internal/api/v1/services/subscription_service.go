@@ -0,0 +1,586 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/billing"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoSubscription        = errors.New("organization has no subscription")
+	ErrAlreadySubscribed     = errors.New("organization already has an active subscription")
+	ErrTrialUnavailable      = errors.New("this plan has no trial")
+	ErrTrialUsed             = errors.New("organization has already had a trial")
+	ErrPaymentMethodRequired = errors.New("a payment method is required for paid plans")
+	ErrSamePlan              = errors.New("organization is already on this plan")
+	ErrNotAnUpgrade          = errors.New("plan costs less than the current one; downgrade instead")
+	ErrNotADowngrade         = errors.New("plan does not cost less than the current one; upgrade instead")
+	ErrSubscriptionNotActive = errors.New("subscription is not active")
+	ErrNotCancelling         = errors.New("subscription is not set to cancel")
+	ErrSeatLimit             = errors.New("the plan does not allow this many seats")
+)
+
+// SubscribeInput chooses a plan, the seats to pay for and how they will be
+// paid for. Seats default to those the plan includes, and at least one.
+type SubscribeInput struct {
+	PlanID        uint
+	Seats         int
+	PaymentMethod string
+}
+
+// SubscriptionOverview shows an organization's current subscription and the
+// one it is scheduled to switch to at the end of the current period
+type SubscriptionOverview struct {
+	Current   *models.Subscription `json:"current"`
+	Scheduled *models.Subscription `json:"scheduled"`
+}
+
+type SubscriptionService struct {
+	subscriptionRepo repositories.SubscriptionRepository
+	planRepo         repositories.PlanRepository
+	access           accessChecker
+	now              func() time.Time
+}
+
+func NewSubscriptionService(
+	subscriptionRepo repositories.SubscriptionRepository,
+	planRepo repositories.PlanRepository,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *SubscriptionService {
+	return &SubscriptionService{
+		subscriptionRepo: subscriptionRepo,
+		planRepo:         planRepo,
+		access:           accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+		now:              time.Now,
+	}
+}
+
+// GetSubscription returns the organization's current and scheduled subscriptions
+func (s *SubscriptionService) GetSubscription(ctx context.Context, userID, orgID uint) (*SubscriptionOverview, error) {
+	if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.overview(ctx, orgID)
+}
+
+// ListSubscriptions lists every subscription the organization has had, newest first
+func (s *SubscriptionService) ListSubscriptions(ctx context.Context, userID, orgID uint) ([]models.Subscription, error) {
+	if err := s.access.billing(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	return s.subscriptionRepo.ListByOrganization(ctx, orgID)
+}
+
+// StartTrial starts the plan's free trial. Each organization gets one trial.
+func (s *SubscriptionService) StartTrial(ctx context.Context, userID, orgID, planID uint) (*SubscriptionOverview, error) {
+	if err := s.access.billing(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	plan, err := s.availablePlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.TrialDays == 0 {
+		return nil, ErrTrialUnavailable
+	}
+
+	current, err := s.findCurrent(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.Status == models.SubscriptionStatusActive {
+		return nil, ErrAlreadySubscribed
+	}
+	trialed, err := s.subscriptionRepo.HasTrialed(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if trialed {
+		return nil, ErrTrialUsed
+	}
+
+	// The first paid period starts when the trial ends
+	now := s.now().UTC()
+	trialEnd := now.AddDate(0, 0, plan.TrialDays)
+	trial := &models.Subscription{
+		OrganizationID:     orgID,
+		PlanID:             plan.ID,
+		Status:             models.SubscriptionStatusActive,
+		Seats:              defaultSeats(plan),
+		StartDate:          now,
+		EndDate:            trialEnd,
+		TrialEndsAt:        &trialEnd,
+		NextBillingAt:      &trialEnd,
+		BillingAnchor:      trialEnd,
+		CurrentPeriodStart: now,
+	}
+	if err := s.replace(ctx, current, trial, now); err != nil {
+		return nil, err
+	}
+	return s.overview(ctx, orgID)
+}
+
+// Subscribe puts the organization on a plan. During a trial the plan and
+// payment method are recorded and billing starts when the trial ends.
+func (s *SubscriptionService) Subscribe(ctx context.Context, userID, orgID uint, input SubscribeInput) (*SubscriptionOverview, error) {
+	if err := s.access.billing(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	plan, err := s.availablePlan(ctx, input.PlanID)
+	if err != nil {
+		return nil, err
+	}
+	if plan.Price.IsPositive() && input.PaymentMethod == "" {
+		return nil, ErrPaymentMethodRequired
+	}
+	seats := input.Seats
+	if seats == 0 {
+		seats = defaultSeats(plan)
+	}
+	if seats < 1 {
+		return nil, models.ErrInvalidSeats
+	}
+	if !plan.AllowsSeats(seats) {
+		return nil, ErrSeatLimit
+	}
+
+	current, err := s.findCurrent(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC()
+	if current != nil && current.Status == models.SubscriptionStatusActive {
+		if !trialing(current, now) {
+			return nil, ErrAlreadySubscribed
+		}
+		current.PlanID = plan.ID
+		current.Seats = seats
+		current.PaymentMethod = input.PaymentMethod
+		current.CancelAtPeriodEnd = false
+		renewAt := current.EndDate
+		current.NextBillingAt = &renewAt
+		if err := s.subscriptionRepo.Save(ctx, current); err != nil {
+			return nil, err
+		}
+		return s.overview(ctx, orgID)
+	}
+
+	subscription := &models.Subscription{
+		OrganizationID: orgID,
+		PlanID:         plan.ID,
+		Status:         models.SubscriptionStatusActive,
+		Seats:          seats,
+		StartDate:      now,
+		BillingAnchor:  now,
+		PaymentMethod:  input.PaymentMethod,
+	}
+	subscription.StartPeriod(now, plan.BillingInterval)
+	if err := s.replace(ctx, current, subscription, now); err != nil {
+		return nil, err
+	}
+	return s.overview(ctx, orgID)
+}
+
+// Upgrade moves the organization to a plan that costs at least as much per
+// year. The change applies straight away.
+func (s *SubscriptionService) Upgrade(ctx context.Context, userID, orgID, planID uint) (*SubscriptionOverview, error) {
+	return s.changePlan(ctx, userID, orgID, planID, true)
+}
+
+// Downgrade moves the organization to a plan that costs less per year. The
+// change is scheduled for the end of the current period, except during a
+// trial when it applies straight away.
+func (s *SubscriptionService) Downgrade(ctx context.Context, userID, orgID, planID uint) (*SubscriptionOverview, error) {
+	return s.changePlan(ctx, userID, orgID, planID, false)
+}
+
+func (s *SubscriptionService) changePlan(ctx context.Context, userID, orgID, planID uint, upgrade bool) (*SubscriptionOverview, error) {
+	if err := s.access.billing(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	plan, err := s.availablePlan(ctx, planID)
+	if err != nil {
+		return nil, err
+	}
+	current, err := s.activeSubscription(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	if current.PlanID == plan.ID {
+		return nil, ErrSamePlan
+	}
+	if !plan.AllowsSeats(current.Seats) {
+		return nil, ErrSeatLimit
+	}
+	if !current.Plan.Price.SameCurrency(plan.Price) {
+		return nil, money.ErrCurrencyMismatch
+	}
+	cheaper := plan.AnnualPrice() < current.Plan.AnnualPrice()
+	if upgrade && cheaper {
+		return nil, ErrNotAnUpgrade
+	}
+	if !upgrade && !cheaper {
+		return nil, ErrNotADowngrade
+	}
+	now := s.now().UTC()
+	if plan.Price.IsPositive() && current.PaymentMethod == "" && !trialing(current, now) {
+		return nil, ErrPaymentMethodRequired
+	}
+
+	// A new change replaces any change scheduled before
+	var changes []*models.Subscription
+	scheduled, err := s.findScheduled(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled != nil {
+		if err := scheduled.TransitionTo(models.SubscriptionStatusCancelled, now); err != nil {
+			return nil, err
+		}
+		changes = append(changes, scheduled)
+	}
+
+	var items []models.InvoiceItem
+	if upgrade || trialing(current, now) {
+		// The time left in a period that has been paid for is credited, and
+		// charged again on the new plan
+		if current.IsPeriodBilled() && !trialing(current, now) {
+			items, err = billing.PlanChangeItems(&current.Plan, current.Seats, plan, current.Seats, currentPeriod(current), now)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		intervalChanged := current.Plan.BillingInterval != plan.BillingInterval
+		current.PlanID = plan.ID
+		current.CancelAtPeriodEnd = false
+		if intervalChanged && !trialing(current, now) {
+			// A new interval starts a new period, counted from now
+			current.BillingAnchor = now
+			current.StartPeriod(now, plan.BillingInterval)
+		} else {
+			renewAt := current.EndDate
+			current.NextBillingAt = &renewAt
+		}
+		changes = append(changes, current)
+	} else {
+		next := &models.Subscription{
+			OrganizationID: orgID,
+			PlanID:         plan.ID,
+			Status:         models.SubscriptionStatusPending,
+			Seats:          current.Seats,
+			StartDate:      current.EndDate,
+			BillingAnchor:  current.EndDate,
+			PaymentMethod:  current.PaymentMethod,
+		}
+		next.StartPeriod(current.EndDate, plan.BillingInterval)
+		changes = append(changes, next)
+	}
+
+	if err := s.subscriptionRepo.SaveWithItems(ctx, subscriptionItems(current, items), changes...); err != nil {
+		return nil, err
+	}
+	return s.overview(ctx, orgID)
+}
+
+// SetSeats changes the number of seats paid for. Seats added or removed
+// partway through a paid period are charged or credited for the time left in
+// it; a scheduled plan change takes the new seats too.
+func (s *SubscriptionService) SetSeats(ctx context.Context, userID, orgID uint, seats int) (*SubscriptionOverview, error) {
+	if err := s.access.billing(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	if seats < 1 {
+		return nil, models.ErrInvalidSeats
+	}
+	current, err := s.activeSubscription(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !current.Plan.AllowsSeats(seats) {
+		return nil, ErrSeatLimit
+	}
+
+	now := s.now().UTC()
+	var items []models.InvoiceItem
+	if current.IsPeriodBilled() && !trialing(current, now) {
+		items, err = billing.SeatChangeItems(&current.Plan, current.Seats, seats, currentPeriod(current), now)
+		if err != nil {
+			return nil, err
+		}
+	}
+	current.Seats = seats
+	changes := []*models.Subscription{current}
+
+	scheduled, err := s.findScheduled(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled != nil {
+		if !scheduled.Plan.AllowsSeats(seats) {
+			return nil, ErrSeatLimit
+		}
+		scheduled.Seats = seats
+		changes = append(changes, scheduled)
+	}
+
+	if err := s.subscriptionRepo.SaveWithItems(ctx, subscriptionItems(current, items), changes...); err != nil {
+		return nil, err
+	}
+	return s.overview(ctx, orgID)
+}
+
+// Cancel ends the subscription at the end of its current period and drops
+// any scheduled plan change. A lapsed subscription is cancelled straight away.
+func (s *SubscriptionService) Cancel(ctx context.Context, userID, orgID uint) (*SubscriptionOverview, error) {
+	if err := s.access.billing(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	current, err := s.findCurrent(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrNoSubscription
+	}
+
+	now := s.now().UTC()
+	var changes []*models.Subscription
+	scheduled, err := s.findScheduled(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled != nil {
+		if err := scheduled.TransitionTo(models.SubscriptionStatusCancelled, now); err != nil {
+			return nil, err
+		}
+		changes = append(changes, scheduled)
+	}
+
+	if current.Status == models.SubscriptionStatusInactive {
+		if err := current.TransitionTo(models.SubscriptionStatusCancelled, now); err != nil {
+			return nil, err
+		}
+	} else {
+		current.CancelAtPeriodEnd = true
+		current.NextBillingAt = nil
+	}
+	changes = append(changes, current)
+
+	if err := s.subscriptionRepo.Save(ctx, changes...); err != nil {
+		return nil, err
+	}
+	return s.overview(ctx, orgID)
+}
+
+// Resume keeps a subscription that was set to cancel at the end of its period
+func (s *SubscriptionService) Resume(ctx context.Context, userID, orgID uint) (*SubscriptionOverview, error) {
+	if err := s.access.billing(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	current, err := s.activeSubscription(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if !current.CancelAtPeriodEnd {
+		return nil, ErrNotCancelling
+	}
+
+	current.CancelAtPeriodEnd = false
+	renewAt := current.EndDate
+	current.NextBillingAt = &renewAt
+	if err := s.subscriptionRepo.Save(ctx, current); err != nil {
+		return nil, err
+	}
+	return s.overview(ctx, orgID)
+}
+
+// ProcessRenewals starts scheduled subscriptions whose date has come and
+// renews, lapses or ends active subscriptions whose period is over. It
+// returns the number of subscriptions changed. A failure on one subscription
+// does not stop the others; it is retried on the next run.
+func (s *SubscriptionService) ProcessRenewals(ctx context.Context) (int, error) {
+	now := s.now().UTC()
+	processed := 0
+	var errs []error
+
+	starts, err := s.subscriptionRepo.ListDueStarts(ctx, now)
+	if err != nil {
+		return 0, err
+	}
+	for i := range starts {
+		if err := s.start(ctx, &starts[i], now); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		processed++
+	}
+
+	renewals, err := s.subscriptionRepo.ListDueRenewals(ctx, now)
+	if err != nil {
+		return processed, err
+	}
+	for i := range renewals {
+		if err := s.renew(ctx, &renewals[i], now); err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		processed++
+	}
+	return processed, errors.Join(errs...)
+}
+
+// ReconcileUsage corrects the usage counts of subscriptions that drifted
+// from the members, projects and files their organizations actually have,
+// and returns the number corrected
+func (s *SubscriptionService) ReconcileUsage(ctx context.Context) (int, error) {
+	drifted, err := s.subscriptionRepo.ReconcileUsage(ctx)
+	return int(drifted), err
+}
+
+// start makes a scheduled subscription current in place of the one it replaces
+func (s *SubscriptionService) start(ctx context.Context, scheduled *models.Subscription, now time.Time) error {
+	current, err := s.findCurrent(ctx, scheduled.OrganizationID)
+	if err != nil {
+		return err
+	}
+	if err := scheduled.TransitionTo(models.SubscriptionStatusActive, now); err != nil {
+		return err
+	}
+	return s.replace(ctx, current, scheduled, now)
+}
+
+// renew ends a subscription's period. Subscriptions set to cancel end, paid
+// ones without a payment method lapse, and the others move on to the period
+// that contains now.
+func (s *SubscriptionService) renew(ctx context.Context, subscription *models.Subscription, now time.Time) error {
+	switch {
+	case subscription.CancelAtPeriodEnd:
+		if err := subscription.TransitionTo(models.SubscriptionStatusCancelled, subscription.EndDate); err != nil {
+			return err
+		}
+	case subscription.Plan.Price.IsPositive() && subscription.PaymentMethod == "":
+		if err := subscription.TransitionTo(models.SubscriptionStatusInactive, now); err != nil {
+			return err
+		}
+	default:
+		for !subscription.EndDate.After(now) {
+			subscription.StartPeriod(subscription.EndDate, subscription.Plan.BillingInterval)
+		}
+	}
+	return s.subscriptionRepo.Save(ctx, subscription)
+}
+
+// replace cancels the organization's current subscription, if any, and saves
+// the one taking its place with the same usage counts
+func (s *SubscriptionService) replace(ctx context.Context, current, next *models.Subscription, now time.Time) error {
+	if current == nil {
+		return s.subscriptionRepo.Save(ctx, next)
+	}
+	if err := current.TransitionTo(models.SubscriptionStatusCancelled, now); err != nil {
+		return err
+	}
+	next.CurrentUsers = current.CurrentUsers
+	next.CurrentProjects = current.CurrentProjects
+	next.CurrentStorage = current.CurrentStorage
+	return s.subscriptionRepo.Save(ctx, current, next)
+}
+
+func (s *SubscriptionService) overview(ctx context.Context, orgID uint) (*SubscriptionOverview, error) {
+	current, err := s.findCurrent(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	scheduled, err := s.findScheduled(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return &SubscriptionOverview{Current: current, Scheduled: scheduled}, nil
+}
+
+// activeSubscription returns the organization's current subscription when it is active
+func (s *SubscriptionService) activeSubscription(ctx context.Context, orgID uint) (*models.Subscription, error) {
+	current, err := s.findCurrent(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrNoSubscription
+	}
+	if current.Status != models.SubscriptionStatusActive {
+		return nil, ErrSubscriptionNotActive
+	}
+	return current, nil
+}
+
+func (s *SubscriptionService) findCurrent(ctx context.Context, orgID uint) (*models.Subscription, error) {
+	current, err := s.subscriptionRepo.FindCurrent(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return current, nil
+}
+
+func (s *SubscriptionService) findScheduled(ctx context.Context, orgID uint) (*models.Subscription, error) {
+	scheduled, err := s.subscriptionRepo.FindScheduled(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+// availablePlan loads a plan that can still be chosen
+func (s *SubscriptionService) availablePlan(ctx context.Context, planID uint) (*models.Plan, error) {
+	plan, err := s.planRepo.FindByID(ctx, planID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrPlanNotFound
+		}
+		return nil, err
+	}
+	if plan.IsArchived() {
+		return nil, ErrPlanArchived
+	}
+	return plan, nil
+}
+
+// trialing checks if the subscription is in its trial at the given time
+func trialing(subscription *models.Subscription, now time.Time) bool {
+	return subscription.TrialEndsAt != nil && now.Before(*subscription.TrialEndsAt)
+}
+
+// defaultSeats returns the seats a new subscription to the plan starts with
+func defaultSeats(plan *models.Plan) int {
+	if plan.IncludedSeats > 1 {
+		return plan.IncludedSeats
+	}
+	return 1
+}
+
+// currentPeriod returns the subscription's current billing period
+func currentPeriod(subscription *models.Subscription) billing.Period {
+	return billing.Period{Start: subscription.CurrentPeriodStart, End: subscription.EndDate}
+}
+
+// subscriptionItems assigns invoice items to the subscription they were charged on
+func subscriptionItems(subscription *models.Subscription, items []models.InvoiceItem) []models.InvoiceItem {
+	for i := range items {
+		items[i].OrganizationID = subscription.OrganizationID
+		items[i].SubscriptionID = &subscription.ID
+	}
+	return items
+}
@@ -0,0 +1,528 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/taskql"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	defaultTaskPageSize = 50
+	maxTaskPageSize     = 200
+	boardColumnSize     = 100
+)
+
+var ErrInvalidTaskFilter = errors.New("invalid task filter")
+
+// CustomFieldParam is a custom field condition taken from the request, such as cf.amount.gte=100
+type CustomFieldParam struct {
+	Key      string
+	Operator string
+	Value    string
+}
+
+// TaskListParams holds the filters, sorting and paging of a task listing
+type TaskListParams struct {
+	Query        string // task query such as "assignee = me AND due < +7d"
+	Statuses     []string
+	Priorities   []string
+	AssigneeID   *uint
+	Labels       []string
+	CustomFields []CustomFieldParam
+	Sort         []string // column or cf.<key>, prefixed with - for descending order
+	Page         int
+	PageSize     int
+}
+
+// BoardColumn holds the tasks of one status on a board
+type BoardColumn struct {
+	Status models.TaskStatus `json:"status"`
+	Tasks  []models.Task     `json:"tasks"`
+	Total  int64             `json:"total"`
+}
+
+// TaskPage is one page of a task listing
+type TaskPage struct {
+	Tasks    []models.Task `json:"tasks"`
+	Total    int64         `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
+type TaskService struct {
+	taskRepo  repositories.TaskRepository
+	labelRepo repositories.LabelRepository
+	fieldRepo repositories.CustomFieldRepository
+	access    accessChecker
+}
+
+func NewTaskService(
+	taskRepo repositories.TaskRepository,
+	labelRepo repositories.LabelRepository,
+	fieldRepo repositories.CustomFieldRepository,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *TaskService {
+	return &TaskService{
+		taskRepo:  taskRepo,
+		labelRepo: labelRepo,
+		fieldRepo: fieldRepo,
+		access:    accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+	}
+}
+
+// ListTasks returns one page of a project's tasks
+func (s *TaskService) ListTasks(ctx context.Context, userID, projectID uint, params TaskListParams) (*TaskPage, error) {
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := s.projectFields(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := s.listOptions(ctx, userID, project.OrganizationID, fields, params)
+	if err != nil {
+		return nil, err
+	}
+	opts.ProjectID = project.ID
+
+	return s.listPage(ctx, opts, params)
+}
+
+// SearchTasks returns one page of the tasks matching the filters across all
+// projects of an organization. Custom fields are project-specific and cannot
+// be used here.
+func (s *TaskService) SearchTasks(ctx context.Context, userID, orgID uint, params TaskListParams) (*TaskPage, error) {
+	if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	opts, err := s.listOptions(ctx, userID, orgID, nil, params)
+	if err != nil {
+		return nil, err
+	}
+	opts.OrganizationID = orgID
+
+	return s.listPage(ctx, opts, params)
+}
+
+// Board returns a project's tasks matching the filters grouped into one
+// column per status. Each column holds at most boardColumnSize tasks.
+func (s *TaskService) Board(ctx context.Context, userID, projectID uint, params TaskListParams) ([]BoardColumn, error) {
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := s.projectFields(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := s.listOptions(ctx, userID, project.OrganizationID, fields, params)
+	if err != nil {
+		return nil, err
+	}
+	opts.ProjectID = project.ID
+	opts.Limit = boardColumnSize
+
+	statuses := []models.TaskStatus{
+		models.TaskStatusTodo,
+		models.TaskStatusInProgress,
+		models.TaskStatusInReview,
+		models.TaskStatusDone,
+	}
+	columns := make([]BoardColumn, 0, len(statuses))
+	conditions := opts.Conditions
+	for _, status := range statuses {
+		opts.Conditions = append(conditions[:len(conditions):len(conditions)], clause.Eq{
+			Column: clause.Column{Table: "tasks", Name: "status"},
+			Value:  status,
+		})
+		tasks, total, err := s.taskRepo.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, BoardColumn{Status: status, Tasks: tasks, Total: total})
+	}
+	return columns, nil
+}
+
+// Report summarizes a project's tasks matching the filters, grouped by
+// status, priority, assignee_id or sprint_id
+func (s *TaskService) Report(ctx context.Context, userID, projectID uint, params TaskListParams, groupBy string) ([]repositories.TaskGroupSummary, error) {
+	if !repositories.IsGroupableTaskColumn(groupBy) {
+		return nil, fmt.Errorf("%w: cannot group by %q", ErrInvalidTaskFilter, groupBy)
+	}
+
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := s.projectFields(ctx, project.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := s.listOptions(ctx, userID, project.OrganizationID, fields, params)
+	if err != nil {
+		return nil, err
+	}
+	opts.ProjectID = project.ID
+	opts.Sort = nil
+
+	return s.taskRepo.Summarize(ctx, opts, groupBy)
+}
+
+// ParseQuery parses and validates a task query for an organization or, when
+// projectID is set, for one of its projects, where custom fields are available
+func (s *TaskService) ParseQuery(ctx context.Context, userID, orgID uint, projectID *uint, query string) (taskql.Node, error) {
+	var fields []models.CustomFieldDefinition
+	if projectID != nil {
+		project, err := s.access.project(ctx, *projectID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if project.OrganizationID != orgID {
+			return nil, ErrProjectNotFound
+		}
+		if fields, err = s.projectFields(ctx, project.ID); err != nil {
+			return nil, err
+		}
+	} else if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+
+	node, err := taskql.Parse(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTaskFilter, err)
+	}
+	if _, err := taskql.Compile(node, queryEnv(userID, fields)); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidTaskFilter, err)
+	}
+	return node, nil
+}
+
+// projectFields loads a project's custom fields. The result is never nil,
+// which tells the query compiler that custom fields are in scope.
+func (s *TaskService) projectFields(ctx context.Context, projectID uint) ([]models.CustomFieldDefinition, error) {
+	fields, err := s.fieldRepo.ListByProject(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+	if fields == nil {
+		fields = []models.CustomFieldDefinition{}
+	}
+	return fields, nil
+}
+
+// listPage applies paging to opts and loads the page
+func (s *TaskService) listPage(ctx context.Context, opts repositories.TaskListOptions, params TaskListParams) (*TaskPage, error) {
+	page := params.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := params.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultTaskPageSize
+	}
+	if pageSize > maxTaskPageSize {
+		pageSize = maxTaskPageSize
+	}
+	opts.Limit = pageSize
+	opts.Offset = (page - 1) * pageSize
+
+	tasks, total, err := s.taskRepo.List(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TaskPage{
+		Tasks:    tasks,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}, nil
+}
+
+// ExportTasks writes all of a project's tasks matching the filters as CSV,
+// with one column per custom field
+func (s *TaskService) ExportTasks(ctx context.Context, userID, projectID uint, params TaskListParams, w io.Writer) error {
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return err
+	}
+
+	fields, err := s.projectFields(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+
+	opts, err := s.listOptions(ctx, userID, project.OrganizationID, fields, params)
+	if err != nil {
+		return err
+	}
+	opts.ProjectID = project.ID
+
+	tasks, _, err := s.taskRepo.List(ctx, opts)
+	if err != nil {
+		return err
+	}
+
+	return writeTasksCSV(w, tasks, fields)
+}
+
+// listOptions compiles the query and resolves label names and custom field
+// keys into repository options. fields is nil for organization-wide listings.
+func (s *TaskService) listOptions(ctx context.Context, userID, orgID uint, fields []models.CustomFieldDefinition, params TaskListParams) (repositories.TaskListOptions, error) {
+	opts := repositories.TaskListOptions{
+		AssigneeID: params.AssigneeID,
+	}
+
+	if strings.TrimSpace(params.Query) != "" {
+		condition, err := taskql.CompileString(params.Query, queryEnv(userID, fields))
+		if err != nil {
+			return opts, fmt.Errorf("%w: %v", ErrInvalidTaskFilter, err)
+		}
+		if condition != nil {
+			opts.Conditions = append(opts.Conditions, condition)
+		}
+	}
+
+	for _, status := range params.Statuses {
+		opts.Statuses = append(opts.Statuses, models.TaskStatus(status))
+	}
+	for _, priority := range params.Priorities {
+		opts.Priorities = append(opts.Priorities, models.TaskPriority(priority))
+	}
+
+	if len(params.Labels) > 0 {
+		labels, err := s.labelRepo.FindByNames(ctx, orgID, params.Labels)
+		if err != nil {
+			return opts, err
+		}
+		if len(labels) == 0 {
+			// No label matches, so no task can match either
+			opts.LabelIDs = []uint{0}
+		}
+		for _, label := range labels {
+			opts.LabelIDs = append(opts.LabelIDs, label.ID)
+		}
+	}
+
+	byKey := make(map[string]*models.CustomFieldDefinition, len(fields))
+	for i := range fields {
+		byKey[fields[i].Key] = &fields[i]
+	}
+
+	for _, param := range params.CustomFields {
+		field, ok := byKey[param.Key]
+		if !ok {
+			return opts, fmt.Errorf("%w: unknown custom field %q", ErrInvalidTaskFilter, param.Key)
+		}
+		filter, err := customFieldFilter(field, param)
+		if err != nil {
+			return opts, err
+		}
+		opts.CustomFields = append(opts.CustomFields, filter)
+	}
+
+	for _, sort := range params.Sort {
+		desc := strings.HasPrefix(sort, "-")
+		name := strings.TrimPrefix(sort, "-")
+
+		if key, ok := strings.CutPrefix(name, "cf."); ok {
+			field, ok := byKey[key]
+			if !ok {
+				return opts, fmt.Errorf("%w: unknown custom field %q", ErrInvalidTaskFilter, key)
+			}
+			if field.Type == models.CustomFieldTypeMultiSelect {
+				return opts, fmt.Errorf("%w: cannot sort by multi-select field %q", ErrInvalidTaskFilter, key)
+			}
+			opts.Sort = append(opts.Sort, repositories.TaskSort{Field: field, Desc: desc})
+			continue
+		}
+
+		if !repositories.IsSortableTaskColumn(name) {
+			return opts, fmt.Errorf("%w: cannot sort by %q", ErrInvalidTaskFilter, name)
+		}
+		opts.Sort = append(opts.Sort, repositories.TaskSort{Column: name, Desc: desc})
+	}
+
+	return opts, nil
+}
+
+// queryEnv is the environment task queries of a user are compiled in
+func queryEnv(userID uint, fields []models.CustomFieldDefinition) taskql.Env {
+	return taskql.Env{UserID: userID, Now: time.Now(), CustomFields: fields}
+}
+
+// customFieldFilter converts a request condition into a typed filter for the field
+func customFieldFilter(field *models.CustomFieldDefinition, param CustomFieldParam) (repositories.CustomFieldFilter, error) {
+	filter := repositories.CustomFieldFilter{Field: field, Operator: param.Operator}
+	if filter.Operator == "" {
+		filter.Operator = "eq"
+		if field.Type == models.CustomFieldTypeMultiSelect {
+			filter.Operator = "contains"
+		}
+	}
+
+	invalid := fmt.Errorf("%w: invalid value %q for custom field %q", ErrInvalidTaskFilter, param.Value, field.Key)
+	switch field.Type {
+	case models.CustomFieldTypeNumber, models.CustomFieldTypeCurrency:
+		n, err := strconv.ParseFloat(param.Value, 64)
+		if err != nil {
+			return filter, invalid
+		}
+		filter.Value = n
+	case models.CustomFieldTypeDate:
+		date, err := models.ParseFieldDate(param.Value)
+		if err != nil {
+			return filter, invalid
+		}
+		filter.Value = date
+	case models.CustomFieldTypeUser:
+		id, err := strconv.ParseUint(param.Value, 10, 64)
+		if err != nil {
+			return filter, invalid
+		}
+		filter.Value = uint(id)
+	case models.CustomFieldTypeMultiSelect:
+		if filter.Operator != "contains" {
+			return filter, fmt.Errorf("%w: multi-select fields only support contains", ErrInvalidTaskFilter)
+		}
+		filter.Value = strings.Split(param.Value, ",")
+	default:
+		if filter.Operator == "contains" {
+			return filter, fmt.Errorf("%w: contains is only supported for multi-select fields", ErrInvalidTaskFilter)
+		}
+		filter.Value = param.Value
+	}
+
+	switch filter.Operator {
+	case "eq", "ne", "lt", "lte", "gt", "gte", "contains":
+	default:
+		return filter, fmt.Errorf("%w: unknown operator %q", ErrInvalidTaskFilter, filter.Operator)
+	}
+
+	return filter, nil
+}
+
+// writeTasksCSV writes tasks as CSV with one column per custom field
+func writeTasksCSV(w io.Writer, tasks []models.Task, fields []models.CustomFieldDefinition) error {
+	writer := csv.NewWriter(w)
+
+	header := []string{"id", "title", "status", "priority", "assignee", "due_date", "story_points", "estimated_hours", "actual_hours", "labels"}
+	for _, field := range fields {
+		header = append(header, field.Name)
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, task := range tasks {
+		assignee := ""
+		if task.Assignee != nil {
+			assignee = task.Assignee.Email
+		}
+		dueDate := ""
+		if task.DueDate != nil {
+			dueDate = task.DueDate.Format("2006-01-02")
+		}
+		labels := make([]string, 0, len(task.Labels))
+		for _, label := range task.Labels {
+			labels = append(labels, label.Name)
+		}
+
+		row := []string{
+			strconv.FormatUint(uint64(task.ID), 10),
+			task.Title,
+			string(task.Status),
+			string(task.Priority),
+			assignee,
+			dueDate,
+			strconv.FormatFloat(float64(task.StoryPoints), 'f', -1, 32),
+			strconv.FormatFloat(float64(task.EstimatedHours), 'f', -1, 32),
+			strconv.FormatFloat(float64(task.ActualHours), 'f', -1, 32),
+			strings.Join(labels, ", "),
+		}
+
+		values := make(map[uint]*models.CustomFieldValue, len(task.CustomFields))
+		for i := range task.CustomFields {
+			values[task.CustomFields[i].FieldID] = &task.CustomFields[i]
+		}
+		for i := range fields {
+			cell := ""
+			if value, ok := values[fields[i].ID]; ok {
+				cell = value.Display(&fields[i])
+			}
+			row = append(row, cell)
+		}
+
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// WatchTask makes the user watch a task, so its new comments are in their digest
+func (s *TaskService) WatchTask(ctx context.Context, userID, taskID uint) error {
+	task, err := s.findTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	if _, err := s.access.project(ctx, task.ProjectID, userID); err != nil {
+		return err
+	}
+	return s.taskRepo.Watch(ctx, task.ID, userID)
+}
+
+// UnwatchTask stops the user watching a task
+func (s *TaskService) UnwatchTask(ctx context.Context, userID, taskID uint) error {
+	task, err := s.findTask(ctx, taskID)
+	if err != nil {
+		return err
+	}
+	return s.taskRepo.Unwatch(ctx, task.ID, userID)
+}
+
+// IsWatching checks if the user watches a task
+func (s *TaskService) IsWatching(ctx context.Context, userID, taskID uint) (bool, error) {
+	task, err := s.findTask(ctx, taskID)
+	if err != nil {
+		return false, err
+	}
+	if _, err := s.access.project(ctx, task.ProjectID, userID); err != nil {
+		return false, err
+	}
+	return s.taskRepo.IsWatching(ctx, task.ID, userID)
+}
+
+func (s *TaskService) findTask(ctx context.Context, taskID uint) (*models.Task, error) {
+	task, err := s.taskRepo.FindByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	return task, nil
+}
@@ -0,0 +1,90 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+)
+
+func TestCustomFieldFilter(t *testing.T) {
+	field := func(fieldType models.CustomFieldType) *models.CustomFieldDefinition {
+		return &models.CustomFieldDefinition{Key: "f", Type: fieldType}
+	}
+	date := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		field    *models.CustomFieldDefinition
+		param    CustomFieldParam
+		operator string
+		value    interface{}
+		err      error
+	}{
+		{"text equals by default", field(models.CustomFieldTypeText), CustomFieldParam{Value: "north"}, "eq", "north", nil},
+		{"number", field(models.CustomFieldTypeNumber), CustomFieldParam{Operator: "gte", Value: "12.5"}, "gte", 12.5, nil},
+		{"currency", field(models.CustomFieldTypeCurrency), CustomFieldParam{Operator: "lt", Value: "100"}, "lt", 100.0, nil},
+		{"date", field(models.CustomFieldTypeDate), CustomFieldParam{Operator: "gt", Value: "2026-04-01"}, "gt", date, nil},
+		{"user", field(models.CustomFieldTypeUser), CustomFieldParam{Value: "42"}, "eq", uint(42), nil},
+		{"multi select contains by default", field(models.CustomFieldTypeMultiSelect), CustomFieldParam{Value: "a,b"}, "contains", []string{"a", "b"}, nil},
+		{"malformed number", field(models.CustomFieldTypeNumber), CustomFieldParam{Value: "twelve"}, "", nil, ErrInvalidTaskFilter},
+		{"malformed date", field(models.CustomFieldTypeDate), CustomFieldParam{Value: "April"}, "", nil, ErrInvalidTaskFilter},
+		{"malformed user", field(models.CustomFieldTypeUser), CustomFieldParam{Value: "-1"}, "", nil, ErrInvalidTaskFilter},
+		{"multi select compared", field(models.CustomFieldTypeMultiSelect), CustomFieldParam{Operator: "eq", Value: "a"}, "", nil, ErrInvalidTaskFilter},
+		{"text contains", field(models.CustomFieldTypeText), CustomFieldParam{Operator: "contains", Value: "a"}, "", nil, ErrInvalidTaskFilter},
+		{"unknown operator", field(models.CustomFieldTypeNumber), CustomFieldParam{Operator: "like", Value: "1"}, "", nil, ErrInvalidTaskFilter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := customFieldFilter(tt.field, tt.param)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("customFieldFilter error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if filter.Operator != tt.operator || !reflect.DeepEqual(filter.Value, tt.value) || filter.Field != tt.field {
+				t.Errorf("filter = %s %#v, want %s %#v", filter.Operator, filter.Value, tt.operator, tt.value)
+			}
+		})
+	}
+}
+
+func TestWriteTasksCSV(t *testing.T) {
+	costCenter := models.CustomFieldDefinition{Name: "Cost Center", Type: models.CustomFieldTypeText}
+	costCenter.ID = 1
+	amount := models.CustomFieldDefinition{Name: "Amount", Type: models.CustomFieldTypeCurrency, Currency: "USD"}
+	amount.ID = 2
+	due := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+	center, spent := "CC-12", 99.5
+
+	task := models.Task{
+		Title:       "Close the books",
+		Status:      models.TaskStatusTodo,
+		Priority:    models.TaskPriorityHigh,
+		DueDate:     &due,
+		StoryPoints: 3,
+		Labels:      []models.Label{{Name: "finance"}, {Name: "q1"}},
+		CustomFields: []models.CustomFieldValue{
+			{FieldID: 2, NumberValue: &spent},
+			{FieldID: 1, TextValue: &center},
+		},
+	}
+	task.ID = 5
+	untouched := models.Task{Title: "Plan"}
+	untouched.ID = 6
+
+	var out bytes.Buffer
+	if err := writeTasksCSV(&out, []models.Task{task, untouched}, []models.CustomFieldDefinition{costCenter, amount}); err != nil {
+		t.Fatal(err)
+	}
+	want := "id,title,status,priority,assignee,due_date,story_points,estimated_hours,actual_hours,labels,Cost Center,Amount\n" +
+		"5,Close the books,todo,high,,2026-04-01,3,0,0,\"finance, q1\",CC-12,99.50 USD\n" +
+		"6,Plan,,,,,0,0,0,,,\n"
+	if out.String() != want {
+		t.Errorf("CSV =\n%s\nwant\n%s", out.String(), want)
+	}
+}
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/tax"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var ErrTaxIDNeedsCountry = errors.New("a tax ID needs the billing country it was issued in")
+
+// BillingDetailsInput holds the billing details an organization can set
+type BillingDetailsInput struct {
+	Name    string
+	Email   string
+	Address string
+	Country string
+	TaxID   string
+}
+
+// BillingDetails are the details invoices are issued to, with how they are taxed
+type BillingDetails struct {
+	Name    string        `json:"billing_name"`
+	Email   string        `json:"billing_email"`
+	Address string        `json:"billing_address"`
+	Country string        `json:"billing_country"`
+	TaxID   string        `json:"tax_id"`
+	Tax     tax.Treatment `json:"tax"`
+}
+
+type TaxService struct {
+	orgRepo       repositories.OrganizationRepository
+	access        accessChecker
+	rules         *tax.Rules
+	sellerCountry string
+}
+
+func NewTaxService(
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+	rules *tax.Rules,
+	sellerCountry string,
+) *TaxService {
+	return &TaxService{
+		orgRepo:       orgRepo,
+		access:        accessChecker{orgRepo: orgRepo, projectRepo: projectRepo},
+		rules:         rules,
+		sellerCountry: sellerCountry,
+	}
+}
+
+// ListJurisdictions lists the countries tax is charged in, with their rates
+// and tax ID formats
+func (s *TaxService) ListJurisdictions() []tax.Jurisdiction {
+	return s.rules.Jurisdictions()
+}
+
+// ValidateTaxID checks a tax ID against the format of its country, returning
+// it in canonical form and how a customer with it would be taxed
+func (s *TaxService) ValidateTaxID(country, taxID string) (string, tax.Treatment, error) {
+	if err := tax.ValidateCountry(country); err != nil {
+		return "", tax.Treatment{}, err
+	}
+	normalized, err := s.rules.NormalizeTaxID(country, taxID)
+	if err != nil {
+		return "", tax.Treatment{}, err
+	}
+	return normalized, s.rules.Determine(s.sellerCountry, country, normalized), nil
+}
+
+// GetBillingDetails returns the organization's billing details
+func (s *TaxService) GetBillingDetails(ctx context.Context, userID, orgID uint) (*BillingDetails, error) {
+	if err := s.access.admin(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	org, err := s.loadOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+	return s.billingDetails(org), nil
+}
+
+// UpdateBillingDetails sets the details the organization's next invoices are
+// issued to. The tax ID must match the format of the billing country. Read-only
+// organizations can still update them, so they can settle their invoices.
+func (s *TaxService) UpdateBillingDetails(ctx context.Context, userID, orgID uint, input BillingDetailsInput) (*BillingDetails, error) {
+	if err := s.access.billing(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	org, err := s.loadOrganization(ctx, orgID)
+	if err != nil {
+		return nil, err
+	}
+
+	country := tax.NormalizeCountry(input.Country)
+	if country != "" {
+		if err := tax.ValidateCountry(country); err != nil {
+			return nil, err
+		}
+	}
+	taxID := strings.TrimSpace(input.TaxID)
+	if taxID != "" {
+		if country == "" {
+			return nil, ErrTaxIDNeedsCountry
+		}
+		if taxID, err = s.rules.NormalizeTaxID(country, taxID); err != nil {
+			return nil, err
+		}
+	}
+
+	org.BillingName = strings.TrimSpace(input.Name)
+	org.BillingEmail = strings.TrimSpace(input.Email)
+	org.BillingAddress = strings.TrimSpace(input.Address)
+	org.BillingCountry = country
+	org.TaxID = taxID
+	if err := s.orgRepo.UpdateBillingDetails(ctx, org); err != nil {
+		return nil, err
+	}
+	return s.billingDetails(org), nil
+}
+
+func (s *TaxService) loadOrganization(ctx context.Context, orgID uint) (*models.Organization, error) {
+	org, err := s.orgRepo.FindByID(ctx, orgID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrOrganizationNotFound
+		}
+		return nil, err
+	}
+	return org, nil
+}
+
+func (s *TaxService) billingDetails(org *models.Organization) *BillingDetails {
+	return &BillingDetails{
+		Name:    org.BillingName,
+		Email:   org.BillingEmail,
+		Address: org.BillingAddress,
+		Country: org.BillingCountry,
+		TaxID:   org.TaxID,
+		Tax:     s.rules.Determine(s.sellerCountry, org.BillingCountry, org.TaxID),
+	}
+}
@@ -0,0 +1,549 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrTimeEntryNotFound     = errors.New("time entry not found")
+	ErrTimesheetNotFound     = errors.New("timesheet not found")
+	ErrNoRunningTimer        = errors.New("no timer is running")
+	ErrNotEntryOwner         = errors.New("only the owner can change this time entry")
+	ErrEntryInFuture         = errors.New("time entries cannot end in the future")
+	ErrTimesheetLocked       = errors.New("the timesheet for this week has been submitted")
+	ErrTimerRunning          = errors.New("stop the running timer before submitting this week")
+	ErrEmptyTimesheet        = errors.New("the timesheet has no time entries")
+	ErrTimesheetNotSubmitted = errors.New("only submitted timesheets can be reviewed")
+	ErrOwnTimesheet          = errors.New("you cannot review your own timesheet")
+)
+
+// TimeEntryInput holds the editable fields of a time entry. EndedAt is
+// required for manual entries and ignored when starting a timer.
+type TimeEntryInput struct {
+	StartedAt time.Time
+	EndedAt   *time.Time
+	Billable  bool
+	Notes     string
+}
+
+type TimeService struct {
+	entryRepo     repositories.TimeEntryRepository
+	timesheetRepo repositories.TimesheetRepository
+	taskRepo      repositories.TaskRepository
+	projectRepo   repositories.ProjectRepository
+	budgetService *BudgetService
+	access        accessChecker
+	now           func() time.Time
+}
+
+func NewTimeService(
+	entryRepo repositories.TimeEntryRepository,
+	timesheetRepo repositories.TimesheetRepository,
+	taskRepo repositories.TaskRepository,
+	budgetService *BudgetService,
+	entitlements *EntitlementService,
+	projectRepo repositories.ProjectRepository,
+	orgRepo repositories.OrganizationRepository,
+) *TimeService {
+	return &TimeService{
+		entryRepo:     entryRepo,
+		timesheetRepo: timesheetRepo,
+		taskRepo:      taskRepo,
+		projectRepo:   projectRepo,
+		budgetService: budgetService,
+		access:        accessChecker{orgRepo: orgRepo, projectRepo: projectRepo, feature: featureGate{entitlements, models.FeatureTimeTracking}},
+		now:           time.Now,
+	}
+}
+
+// StartTimer starts tracking time on a task. A timer already running on any
+// task is stopped first, so a user never has two running timers.
+func (s *TimeService) StartTimer(ctx context.Context, userID, taskID uint, billable bool, notes string) (*models.TimeEntry, error) {
+	entry, err := s.newEntry(ctx, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	now := s.now().UTC().Truncate(time.Second)
+	if err := s.ensureWeekOpen(ctx, entry.OrganizationID, userID, now); err != nil {
+		return nil, err
+	}
+
+	running, err := s.RunningTimer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if running != nil {
+		stop(running, now)
+	}
+
+	entry.StartedAt = now
+	entry.Billable = billable
+	entry.Notes = notes
+	if err := s.entryRepo.StartTimer(ctx, running, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// StopTimer stops the user's running timer
+func (s *TimeService) StopTimer(ctx context.Context, userID uint) (*models.TimeEntry, error) {
+	running, err := s.RunningTimer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if running == nil {
+		return nil, ErrNoRunningTimer
+	}
+
+	stop(running, s.now().UTC().Truncate(time.Second))
+	if err := s.entryRepo.Update(ctx, running); err != nil {
+		return nil, err
+	}
+	return running, nil
+}
+
+// RunningTimer returns the user's running timer, or nil when none is running
+func (s *TimeService) RunningTimer(ctx context.Context, userID uint) (*models.TimeEntry, error) {
+	running, err := s.entryRepo.FindRunning(ctx, userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return running, nil
+}
+
+// CreateEntry records time spent on a task without a timer
+func (s *TimeService) CreateEntry(ctx context.Context, userID, taskID uint, input TimeEntryInput) (*models.TimeEntry, error) {
+	entry, err := s.newEntry(ctx, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	entry.Manual = true
+	if err := s.apply(ctx, entry, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.entryRepo.Create(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// ListTaskEntries lists the time tracked on a task by all users
+func (s *TimeService) ListTaskEntries(ctx context.Context, userID, taskID uint) ([]models.TimeEntry, error) {
+	task, err := s.loadTask(ctx, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return s.entryRepo.List(ctx, repositories.TimeEntryFilter{TaskID: task.ID})
+}
+
+// UpdateEntry changes one of the user's time entries. A running timer keeps
+// running unless an end is given.
+func (s *TimeService) UpdateEntry(ctx context.Context, userID, entryID uint, input TimeEntryInput) (*models.TimeEntry, error) {
+	entry, err := s.loadOwnEntry(ctx, userID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.IsRunning() && input.EndedAt == nil {
+		if input.StartedAt.After(s.now()) {
+			return nil, ErrEntryInFuture
+		}
+		entry.StartedAt = input.StartedAt.UTC()
+		entry.Billable = input.Billable
+		entry.Notes = input.Notes
+		if err := s.ensureWeekOpen(ctx, entry.OrganizationID, userID, entry.StartedAt); err != nil {
+			return nil, err
+		}
+	} else if err := s.apply(ctx, entry, input); err != nil {
+		return nil, err
+	}
+
+	if err := s.entryRepo.Update(ctx, entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+func (s *TimeService) DeleteEntry(ctx context.Context, userID, entryID uint) error {
+	entry, err := s.loadOwnEntry(ctx, userID, entryID)
+	if err != nil {
+		return err
+	}
+	return s.entryRepo.Delete(ctx, entry)
+}
+
+// GetTimesheet returns the user's timesheet for the week containing week,
+// with its entries. Weeks that were never submitted are returned as unsaved
+// drafts with live totals.
+func (s *TimeService) GetTimesheet(ctx context.Context, userID, orgID uint, week time.Time) (*models.Timesheet, error) {
+	if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	timesheet, err := s.findTimesheet(ctx, orgID, userID, models.WeekStartOf(week))
+	if err != nil {
+		return nil, err
+	}
+	return timesheet, s.loadEntries(ctx, timesheet)
+}
+
+// GetTimesheetByID returns a saved timesheet to its owner or to a user who may review it
+func (s *TimeService) GetTimesheetByID(ctx context.Context, userID, timesheetID uint) (*models.Timesheet, error) {
+	timesheet, err := s.loadTimesheet(ctx, userID, timesheetID)
+	if err != nil {
+		return nil, err
+	}
+	if timesheet.UserID != userID {
+		if err := s.canReview(ctx, userID, timesheet); err != nil {
+			return nil, err
+		}
+	}
+	return timesheet, s.loadEntries(ctx, timesheet)
+}
+
+// SubmitTimesheet submits the user's week for approval, which locks its entries
+func (s *TimeService) SubmitTimesheet(ctx context.Context, userID, orgID uint, week time.Time) (*models.Timesheet, error) {
+	if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	timesheet, err := s.findTimesheet(ctx, orgID, userID, models.WeekStartOf(week))
+	if err != nil {
+		return nil, err
+	}
+	if timesheet.IsLocked() {
+		return nil, ErrTimesheetLocked
+	}
+
+	running, err := s.RunningTimer(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if running != nil && running.OrganizationID == orgID &&
+		!running.StartedAt.Before(timesheet.WeekStart) && running.StartedAt.Before(timesheet.WeekEnd()) {
+		return nil, ErrTimerRunning
+	}
+
+	if err := s.loadEntries(ctx, timesheet); err != nil {
+		return nil, err
+	}
+	if len(timesheet.Entries) == 0 {
+		return nil, ErrEmptyTimesheet
+	}
+
+	now := s.now()
+	timesheet.Status = models.TimesheetStatusSubmitted
+	timesheet.SubmittedAt = &now
+	timesheet.ReviewedByID = nil
+	timesheet.ReviewedAt = nil
+	timesheet.ReviewNote = ""
+	if err := s.timesheetRepo.Submit(ctx, timesheet); err != nil {
+		return nil, err
+	}
+	return timesheet, s.loadEntries(ctx, timesheet)
+}
+
+// ListPendingTimesheets lists submitted timesheets the user may review: all of
+// the organization's for admins, otherwise those covering only projects the
+// user manages
+func (s *TimeService) ListPendingTimesheets(ctx context.Context, userID, orgID uint) ([]models.Timesheet, error) {
+	if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	isAdmin, err := s.access.isOrganizationAdmin(ctx, orgID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var managerID *uint
+	if !isAdmin {
+		managerID = &userID
+	}
+	timesheets, err := s.timesheetRepo.ListSubmitted(ctx, orgID, managerID)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := timesheets[:0]
+	for _, timesheet := range timesheets {
+		if timesheet.UserID != userID {
+			pending = append(pending, timesheet)
+		}
+	}
+	return pending, nil
+}
+
+// ApproveTimesheet approves a submitted timesheet, which counts its labor as
+// spent on its projects and may raise budget alerts
+func (s *TimeService) ApproveTimesheet(ctx context.Context, userID, timesheetID uint, note string) (*models.Timesheet, error) {
+	timesheet, err := s.review(ctx, userID, timesheetID, models.TimesheetStatusApproved, note)
+	if err != nil {
+		return nil, err
+	}
+
+	projectIDs, err := s.timesheetRepo.ProjectIDs(ctx, timesheet.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, projectID := range projectIDs {
+		if err := s.budgetService.CheckThresholds(ctx, projectID); err != nil {
+			return nil, err
+		}
+	}
+	return timesheet, nil
+}
+
+// RejectTimesheet sends a timesheet back to its owner, unlocking its entries
+func (s *TimeService) RejectTimesheet(ctx context.Context, userID, timesheetID uint, note string) (*models.Timesheet, error) {
+	return s.review(ctx, userID, timesheetID, models.TimesheetStatusRejected, note)
+}
+
+// ExportUserEntries returns a user's finished entries in an organization for
+// payroll. Users may export their own time; admins may export anyone's, or
+// everyone's when memberID is 0.
+func (s *TimeService) ExportUserEntries(ctx context.Context, userID, orgID, memberID uint, from, to time.Time) ([]repositories.TimeEntryExportRow, error) {
+	if err := s.access.organization(ctx, orgID, userID); err != nil {
+		return nil, err
+	}
+	if memberID != userID {
+		isAdmin, err := s.access.isOrganizationAdmin(ctx, orgID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !isAdmin {
+			return nil, ErrForbidden
+		}
+	}
+
+	return s.entryRepo.Export(ctx, repositories.TimeEntryFilter{
+		OrganizationID: orgID,
+		UserID:         memberID,
+		From:           from,
+		To:             to,
+	})
+}
+
+// ExportProjectEntries returns a project's finished entries for its managers
+// and organization admins
+func (s *TimeService) ExportProjectEntries(ctx context.Context, userID, projectID uint, from, to time.Time) ([]repositories.TimeEntryExportRow, error) {
+	project, err := s.access.project(ctx, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.access.manager(ctx, project.OrganizationID, userID, []uint{project.ID}); err != nil {
+		return nil, err
+	}
+
+	return s.entryRepo.Export(ctx, repositories.TimeEntryFilter{
+		ProjectID: project.ID,
+		From:      from,
+		To:        to,
+	})
+}
+
+func (s *TimeService) review(ctx context.Context, userID, timesheetID uint, status models.TimesheetStatus, note string) (*models.Timesheet, error) {
+	timesheet, err := s.loadTimesheet(ctx, userID, timesheetID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.canReview(ctx, userID, timesheet); err != nil {
+		return nil, err
+	}
+	if timesheet.Status != models.TimesheetStatusSubmitted {
+		return nil, ErrTimesheetNotSubmitted
+	}
+
+	now := s.now()
+	timesheet.Status = status
+	timesheet.ReviewedByID = &userID
+	timesheet.ReviewedAt = &now
+	timesheet.ReviewNote = note
+	if err := s.timesheetRepo.Review(ctx, timesheet); err != nil {
+		return nil, err
+	}
+	return timesheet, s.loadEntries(ctx, timesheet)
+}
+
+// canReview checks that the user may approve or reject a timesheet: an
+// organization admin, or a manager of every project it has time on, but
+// never its owner
+func (s *TimeService) canReview(ctx context.Context, userID uint, timesheet *models.Timesheet) error {
+	if timesheet.UserID == userID {
+		return ErrOwnTimesheet
+	}
+	projectIDs, err := s.timesheetRepo.ProjectIDs(ctx, timesheet.ID)
+	if err != nil {
+		return err
+	}
+	return s.access.manager(ctx, timesheet.OrganizationID, userID, projectIDs)
+}
+
+// apply sets the times, billable flag and notes of a finished entry after
+// checking that its weeks are open
+func (s *TimeService) apply(ctx context.Context, entry *models.TimeEntry, input TimeEntryInput) error {
+	if input.EndedAt == nil {
+		return models.ErrInvalidEntryTimes
+	}
+	if input.EndedAt.After(s.now()) {
+		return ErrEntryInFuture
+	}
+	if entry.ID != 0 {
+		// the entry is moving out of its current week as well as into the new one
+		if err := s.ensureWeekOpen(ctx, entry.OrganizationID, entry.UserID, entry.StartedAt); err != nil {
+			return err
+		}
+	}
+	if err := s.ensureWeekOpen(ctx, entry.OrganizationID, entry.UserID, input.StartedAt); err != nil {
+		return err
+	}
+
+	endedAt := input.EndedAt.UTC()
+	entry.StartedAt = input.StartedAt.UTC()
+	entry.EndedAt = &endedAt
+	entry.Billable = input.Billable
+	entry.Notes = input.Notes
+	return entry.Validate()
+}
+
+// ensureWeekOpen checks that the user's timesheet for the week containing at
+// has not been submitted or approved
+func (s *TimeService) ensureWeekOpen(ctx context.Context, orgID, userID uint, at time.Time) error {
+	timesheet, err := s.findTimesheet(ctx, orgID, userID, models.WeekStartOf(at))
+	if err != nil {
+		return err
+	}
+	if timesheet.IsLocked() {
+		return ErrTimesheetLocked
+	}
+	return nil
+}
+
+// findTimesheet returns the saved timesheet for a week or a new draft
+func (s *TimeService) findTimesheet(ctx context.Context, orgID, userID uint, weekStart time.Time) (*models.Timesheet, error) {
+	timesheet, err := s.timesheetRepo.FindForWeek(ctx, orgID, userID, weekStart)
+	if err == nil {
+		return timesheet, nil
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	return &models.Timesheet{
+		OrganizationID: orgID,
+		UserID:         userID,
+		WeekStart:      weekStart,
+		Status:         models.TimesheetStatusDraft,
+	}, nil
+}
+
+// loadEntries loads a timesheet's entries. Locked timesheets keep the totals
+// they were submitted with; the others are totalled from their finished entries.
+func (s *TimeService) loadEntries(ctx context.Context, timesheet *models.Timesheet) error {
+	filter := repositories.TimeEntryFilter{
+		OrganizationID: timesheet.OrganizationID,
+		UserID:         timesheet.UserID,
+		From:           timesheet.WeekStart,
+		To:             timesheet.WeekEnd(),
+	}
+	entries, err := s.entryRepo.List(ctx, filter)
+	if err != nil {
+		return err
+	}
+	timesheet.Entries = entries
+
+	if timesheet.IsLocked() {
+		return nil
+	}
+	timesheet.TotalSeconds, timesheet.BillableSeconds = 0, 0
+	for _, entry := range entries {
+		if entry.IsRunning() {
+			continue
+		}
+		timesheet.TotalSeconds += entry.Duration
+		if entry.Billable {
+			timesheet.BillableSeconds += entry.Duration
+		}
+	}
+	return nil
+}
+
+func (s *TimeService) loadTimesheet(ctx context.Context, userID, timesheetID uint) (*models.Timesheet, error) {
+	timesheet, err := s.timesheetRepo.FindByID(ctx, timesheetID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTimesheetNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.organization(ctx, timesheet.OrganizationID, userID); err != nil {
+		return nil, err
+	}
+	return timesheet, nil
+}
+
+// newEntry returns an unsaved entry of the user on a task they can access
+func (s *TimeService) newEntry(ctx context.Context, userID, taskID uint) (*models.TimeEntry, error) {
+	task, err := s.loadTask(ctx, userID, taskID)
+	if err != nil {
+		return nil, err
+	}
+	project, err := s.projectRepo.FindByID(ctx, task.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	return &models.TimeEntry{
+		OrganizationID: project.OrganizationID,
+		TaskID:         task.ID,
+		ProjectID:      task.ProjectID,
+		UserID:         userID,
+	}, nil
+}
+
+func (s *TimeService) loadTask(ctx context.Context, userID, taskID uint) (*models.Task, error) {
+	task, err := s.taskRepo.FindByID(ctx, taskID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTaskNotFound
+		}
+		return nil, err
+	}
+	if _, err := s.access.project(ctx, task.ProjectID, userID); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// loadOwnEntry loads one of the user's entries that is not locked by a timesheet
+func (s *TimeService) loadOwnEntry(ctx context.Context, userID, entryID uint) (*models.TimeEntry, error) {
+	entry, err := s.entryRepo.FindByID(ctx, entryID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrTimeEntryNotFound
+		}
+		return nil, err
+	}
+	if err := s.access.organization(ctx, entry.OrganizationID, userID); err != nil {
+		return nil, err
+	}
+	if entry.UserID != userID {
+		return nil, ErrNotEntryOwner
+	}
+	if entry.TimesheetID != nil {
+		return nil, ErrTimesheetLocked
+	}
+	return entry, nil
+}
+
+// stop ends a running entry, at least a second after it started
+func stop(entry *models.TimeEntry, at time.Time) {
+	if !at.After(entry.StartedAt) {
+		at = entry.StartedAt.Add(time.Second)
+	}
+	entry.EndedAt = &at
+}
@@ -0,0 +1,140 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/repositories"
+	"gorm.io/gorm"
+)
+
+// memTimeEntryRepo lists the entries that start within the filter's week
+type memTimeEntryRepo struct {
+	repositories.TimeEntryRepository
+	entries []models.TimeEntry
+}
+
+func (r memTimeEntryRepo) List(ctx context.Context, filter repositories.TimeEntryFilter) ([]models.TimeEntry, error) {
+	var entries []models.TimeEntry
+	for _, entry := range r.entries {
+		if !entry.StartedAt.Before(filter.From) && entry.StartedAt.Before(filter.To) {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// memTimesheetRepo holds at most one saved timesheet
+type memTimesheetRepo struct {
+	repositories.TimesheetRepository
+	timesheet *models.Timesheet
+}
+
+func (r memTimesheetRepo) FindForWeek(ctx context.Context, orgID, userID uint, weekStart time.Time) (*models.Timesheet, error) {
+	if r.timesheet == nil || !r.timesheet.WeekStart.Equal(weekStart) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	saved := *r.timesheet
+	return &saved, nil
+}
+
+func TestGetTimesheetTotals(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	entry := func(day int, seconds int64, billable bool) models.TimeEntry {
+		started := monday.AddDate(0, 0, day).Add(9 * time.Hour)
+		ended := started.Add(time.Duration(seconds) * time.Second)
+		return models.TimeEntry{StartedAt: started, EndedAt: &ended, Duration: seconds, Billable: billable}
+	}
+	running := models.TimeEntry{StartedAt: monday.AddDate(0, 0, 4).Add(9 * time.Hour), Billable: true}
+	entries := []models.TimeEntry{
+		entry(0, 3600, true),
+		entry(2, 1800, false),
+		entry(6, 900, true),
+		running,
+		entry(7, 7200, true), // next week
+	}
+
+	tests := []struct {
+		name      string
+		timesheet *models.Timesheet
+		userID    uint
+		entries   int
+		total     int64
+		billable  int64
+		err       error
+	}{
+		{"unsaved draft", nil, 1, 4, 6300, 4500, nil},
+		{"rejected", &models.Timesheet{WeekStart: monday, Status: models.TimesheetStatusRejected, TotalSeconds: 60}, 1, 4, 6300, 4500, nil},
+		{"submitted keeps its totals", &models.Timesheet{WeekStart: monday, Status: models.TimesheetStatusSubmitted, TotalSeconds: 5400, BillableSeconds: 3600}, 1, 4, 5400, 3600, nil},
+		{"approved keeps its totals", &models.Timesheet{WeekStart: monday, Status: models.TimesheetStatusApproved, TotalSeconds: 5400, BillableSeconds: 3600}, 1, 4, 5400, 3600, nil},
+		{"not a member", nil, 2, 0, 0, 0, ErrForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &TimeService{
+				entryRepo:     memTimeEntryRepo{entries: entries},
+				timesheetRepo: memTimesheetRepo{timesheet: tt.timesheet},
+				access:        accessChecker{orgRepo: memMemberRepo{}},
+				now:           func() time.Time { return monday.AddDate(0, 0, 5) },
+			}
+			// any time in the week finds its timesheet
+			timesheet, err := service.GetTimesheet(context.Background(), tt.userID, 1, monday.AddDate(0, 0, 3).Add(17*time.Hour))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("GetTimesheet() error = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if !timesheet.WeekStart.Equal(monday) {
+				t.Errorf("WeekStart = %v, want %v", timesheet.WeekStart, monday)
+			}
+			if len(timesheet.Entries) != tt.entries {
+				t.Errorf("got %d entries, want %d", len(timesheet.Entries), tt.entries)
+			}
+			if timesheet.TotalSeconds != tt.total || timesheet.BillableSeconds != tt.billable {
+				t.Errorf("totals = %d/%d, want %d/%d", timesheet.TotalSeconds, timesheet.BillableSeconds, tt.total, tt.billable)
+			}
+		})
+	}
+}
+
+func TestApplyTimeEntry(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	now := monday.AddDate(0, 0, 9).Add(12 * time.Hour)
+	at := func(days int, hours time.Duration) *time.Time {
+		t := monday.AddDate(0, 0, days).Add(hours)
+		return &t
+	}
+	submitted := &models.Timesheet{WeekStart: monday, Status: models.TimesheetStatusSubmitted}
+
+	tests := []struct {
+		name      string
+		entry     models.TimeEntry
+		timesheet *models.Timesheet
+		input     TimeEntryInput
+		err       error
+	}{
+		{"open week", models.TimeEntry{}, nil, TimeEntryInput{StartedAt: *at(7, 9*time.Hour), EndedAt: at(7, 10*time.Hour)}, nil},
+		{"missing end", models.TimeEntry{}, nil, TimeEntryInput{StartedAt: *at(7, 9*time.Hour)}, models.ErrInvalidEntryTimes},
+		{"ends before it starts", models.TimeEntry{}, nil, TimeEntryInput{StartedAt: *at(7, 10*time.Hour), EndedAt: at(7, 9*time.Hour)}, models.ErrInvalidEntryTimes},
+		{"ends in the future", models.TimeEntry{}, nil, TimeEntryInput{StartedAt: *at(9, 9*time.Hour), EndedAt: at(9, 13*time.Hour)}, ErrEntryInFuture},
+		{"into a submitted week", models.TimeEntry{}, submitted, TimeEntryInput{StartedAt: *at(1, 9*time.Hour), EndedAt: at(1, 10*time.Hour)}, ErrTimesheetLocked},
+		{"out of a submitted week", models.TimeEntry{Model: gorm.Model{ID: 1}, StartedAt: *at(1, 9*time.Hour)}, submitted, TimeEntryInput{StartedAt: *at(7, 9*time.Hour), EndedAt: at(7, 10*time.Hour)}, ErrTimesheetLocked},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &TimeService{
+				timesheetRepo: memTimesheetRepo{timesheet: tt.timesheet},
+				now:           func() time.Time { return now },
+			}
+			entry := tt.entry
+			entry.TaskID, entry.UserID = 1, 1
+			if err := service.apply(context.Background(), &entry, tt.input); !errors.Is(err, tt.err) {
+				t.Errorf("apply() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
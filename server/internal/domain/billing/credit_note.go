@@ -0,0 +1,209 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+var ErrInvalidCreditAmount = errors.New("a credit note must credit a positive amount no larger than the invoice")
+
+// DefaultCreditNoteFormat numbers credit notes CN-000001, CN-000002 and so on
+const DefaultCreditNoteFormat NumberFormat = "CN-{SEQ:6}"
+
+// CreditNoteScope names the numbering sequence a credit note takes its number
+// from. Credit notes are numbered apart from invoices, in the same way.
+func CreditNoteScope(perOrganization bool, orgID uint) string {
+	return "credit-note:" + SequenceScope(perOrganization, orgID)
+}
+
+// NewCreditNote drafts the credit note for a refund of the invoice. The tax
+// given back is the share of the invoice's tax that the refund is of its
+// total, so that refunds adding up to the whole invoice give back all of it
+// give or take rounding.
+func NewCreditNote(invoice *models.Invoice, transaction *models.PaymentTransaction, issuedAt time.Time) (*models.CreditNote, error) {
+	amount := transaction.Amount
+	if !amount.SameCurrency(invoice.Amount) || !amount.IsPositive() || amount.Minor > invoice.Amount.Minor {
+		return nil, fmt.Errorf("%w: %s of %s", ErrInvalidCreditAmount, amount, invoice.Amount)
+	}
+
+	taxAmount := money.Zero(amount.Currency)
+	if invoice.TaxAmount.SameCurrency(amount) && invoice.TaxAmount.IsPositive() {
+		var err error
+		if taxAmount, err = invoice.TaxAmount.MulRat(amount.Minor, invoice.Amount.Minor); err != nil {
+			return nil, err
+		}
+	}
+	subtotal, err := amount.Sub(taxAmount)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.CreditNote{
+		OrganizationID: invoice.OrganizationID,
+		InvoiceID:      invoice.ID,
+		InvoiceNumber:  invoice.InvoiceNumber,
+		TransactionID:  transaction.ID,
+		Reason:         transaction.Reason,
+		IssuedAt:       issuedAt,
+		Subtotal:       subtotal,
+		TaxAmount:      taxAmount,
+		Amount:         amount,
+		BillingName:    invoice.BillingName,
+		BillingEmail:   invoice.BillingEmail,
+		BillingAddress: invoice.BillingAddress,
+		BillingCountry: invoice.BillingCountry,
+		TaxID:          invoice.TaxID,
+		TaxNote:        invoice.TaxNote,
+	}, nil
+}
+
+// CreditNoteDocument is an issued credit note together with the seller
+// issuing it, ready to render
+type CreditNoteDocument struct {
+	Note   *models.CreditNote
+	Seller Party
+}
+
+// Customer returns the billing details the credit note was issued to
+func (d CreditNoteDocument) Customer() Party {
+	return Party{
+		Name:    d.Note.BillingName,
+		Email:   d.Note.BillingEmail,
+		Address: d.Note.BillingAddress,
+		TaxID:   d.Note.TaxID,
+	}
+}
+
+// VoidNotice says since when the credit note is void, and why
+func (d CreditNoteDocument) VoidNotice() string {
+	notice := "Void since " + d.Note.VoidedAt.UTC().Format(dateLayout)
+	if d.Note.VoidReason != "" {
+		notice += ": " + d.Note.VoidReason
+	}
+	return notice
+}
+
+var creditNoteTemplate = template.Must(template.New("credit-note").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.UTC().Format(dateLayout) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Credit note {{.Note.CreditNoteNumber}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; max-width: 800px; margin: 40px auto; }
+table { width: 100%; border-collapse: collapse; }
+th, td { padding: 6px 8px; border-bottom: 1px solid #ddd; text-align: left; }
+.num { text-align: right; white-space: nowrap; }
+.parties { display: flex; justify-content: space-between; margin: 24px 0; }
+.parties p { margin: 0; line-height: 1.4; }
+</style>
+</head>
+<body>
+<h1>Credit note {{.Note.CreditNoteNumber}}</h1>
+<p>Issued {{date .Note.IssuedAt}}<br>
+Credits invoice {{.Note.InvoiceNumber}}</p>
+{{if .Note.IsVoid}}<p><strong>{{.VoidNotice}}</strong></p>
+{{end}}<div class="parties">
+<div><h2>From</h2><p>{{range .Seller.Lines}}{{.}}<br>{{end}}</p></div>
+<div><h2>Credit to</h2><p>{{range .Customer.Lines}}{{.}}<br>{{end}}</p></div>
+</div>
+<table>
+<thead><tr><th>Description</th><th class="num">Amount</th></tr></thead>
+<tbody>
+<tr><td>Refund of invoice {{.Note.InvoiceNumber}}{{if .Note.Reason}}: {{.Note.Reason}}{{end}}</td><td class="num">{{.Note.Subtotal}}</td></tr>
+</tbody>
+<tfoot>
+{{if .Note.TaxAmount.IsPositive}}<tr><th class="num">Tax</th><td class="num">{{.Note.TaxAmount}}</td></tr>
+{{end}}<tr><th class="num">Total credited</th><th class="num">{{.Note.Amount}}</th></tr>
+</tfoot>
+</table>
+{{if .Note.TaxNote}}<p>{{.Note.TaxNote}}</p>
+{{end}}</body>
+</html>
+`))
+
+// RenderCreditNoteHTML writes the credit note as an HTML page
+func RenderCreditNoteHTML(w io.Writer, doc CreditNoteDocument) error {
+	return creditNoteTemplate.Execute(w, doc)
+}
+
+// RenderCreditNotePDF writes the credit note as a PDF document
+func RenderCreditNotePDF(w io.Writer, doc CreditNoteDocument) error {
+	note := doc.Note
+	pdf := newPDFWriter()
+
+	pdf.text(fontBold, 20, pageMargin, "Credit note "+note.CreditNoteNumber)
+	pdf.advance(28)
+	pdf.text(fontRegular, 10, pageMargin, "Issued "+note.IssuedAt.UTC().Format(dateLayout))
+	pdf.advance(14)
+	pdf.text(fontRegular, 10, pageMargin, "Credits invoice "+note.InvoiceNumber)
+	if note.IsVoid() {
+		pdf.advance(14)
+		pdf.text(fontBold, 10, pageMargin, doc.VoidNotice())
+	}
+	pdf.advance(32)
+
+	seller, customer := doc.Seller.Lines(), doc.Customer().Lines()
+	pdf.text(fontBold, 12, pageMargin, "From")
+	pdf.text(fontBold, 12, pageWidth/2, "Credit to")
+	pdf.advance(16)
+	for i := 0; i < len(seller) || i < len(customer); i++ {
+		pdf.ensureSpace(14)
+		if i < len(seller) {
+			pdf.text(fontRegular, 10, pageMargin, seller[i])
+		}
+		if i < len(customer) {
+			pdf.text(fontRegular, 10, pageWidth/2, customer[i])
+		}
+		pdf.advance(14)
+	}
+	pdf.advance(20)
+
+	pdf.text(fontBold, 10, pageMargin, "Description")
+	pdf.rightText(fontBold, 10, amountRight, "Amount")
+	pdf.advance(6)
+	pdf.rule()
+	pdf.advance(14)
+
+	description := "Refund of invoice " + note.InvoiceNumber
+	if note.Reason != "" {
+		description += ": " + note.Reason
+	}
+	lines := wrapText(description, descriptionWidth)
+	pdf.rightText(fontMono, 9, amountRight, note.Subtotal.String())
+	for _, line := range lines {
+		pdf.ensureSpace(13)
+		pdf.text(fontRegular, 10, pageMargin, line)
+		pdf.advance(13)
+	}
+	pdf.advance(3)
+
+	pdf.ensureSpace(40)
+	pdf.rule()
+	pdf.advance(16)
+	if note.TaxAmount.IsPositive() {
+		pdf.rightText(fontRegular, 10, unitPriceRight, "Tax")
+		pdf.rightText(fontMono, 10, amountRight, note.TaxAmount.String())
+		pdf.advance(18)
+	}
+	pdf.rightText(fontBold, 11, unitPriceRight, "Total credited")
+	pdf.rightText(fontMono, 10, amountRight, note.Amount.String())
+	pdf.advance(28)
+	if note.TaxNote != "" {
+		for _, line := range wrapText(note.TaxNote, 90) {
+			pdf.ensureSpace(13)
+			pdf.text(fontRegular, 9, pageMargin, line)
+			pdf.advance(13)
+		}
+	}
+
+	_, err := w.Write(pdf.bytes())
+	return err
+}
@@ -0,0 +1,43 @@
+package billing
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+func TestRenderVoidCreditNote(t *testing.T) {
+	issuedAt := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	note := &models.CreditNote{
+		CreditNoteNumber: "CN-0001",
+		InvoiceNumber:    "INV-0001",
+		IssuedAt:         issuedAt,
+		Subtotal:         money.New(7500, "USD"),
+		TaxAmount:        money.Zero("USD"),
+		Amount:           money.New(7500, "USD"),
+	}
+
+	var active bytes.Buffer
+	if err := RenderCreditNoteHTML(&active, CreditNoteDocument{Note: note}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(active.String(), "Void") {
+		t.Error("active credit note is rendered as void")
+	}
+
+	note.Void(issuedAt.AddDate(0, 0, 2), "The refund was reversed")
+	var void bytes.Buffer
+	if err := RenderCreditNoteHTML(&void, CreditNoteDocument{Note: note}); err != nil {
+		t.Fatal(err)
+	}
+	if want := "Void since 2026-03-03: The refund was reversed"; !strings.Contains(void.String(), want) {
+		t.Errorf("void credit note does not say %q:\n%s", want, void.String())
+	}
+	if err := RenderCreditNotePDF(&bytes.Buffer{}, CreditNoteDocument{Note: note}); err != nil {
+		t.Errorf("RenderCreditNotePDF: %v", err)
+	}
+}
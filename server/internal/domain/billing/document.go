@@ -0,0 +1,101 @@
+package billing
+
+import (
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+)
+
+// Party is the seller or the customer named on an invoice
+type Party struct {
+	Name    string
+	Email   string
+	Address string
+	TaxID   string
+}
+
+// Lines returns the party's details as separate lines, skipping empty ones
+func (p Party) Lines() []string {
+	var lines []string
+	if p.Name != "" {
+		lines = append(lines, p.Name)
+	}
+	for _, line := range strings.Split(p.Address, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if p.Email != "" {
+		lines = append(lines, p.Email)
+	}
+	if p.TaxID != "" {
+		lines = append(lines, "Tax ID: "+p.TaxID)
+	}
+	return lines
+}
+
+// Document is an issued invoice together with the seller issuing it, ready to
+// render. The invoice's Items must be loaded.
+type Document struct {
+	Invoice *models.Invoice
+	Seller  Party
+}
+
+// Customer returns the billing details the invoice was issued to
+func (d Document) Customer() Party {
+	return Party{
+		Name:    d.Invoice.BillingName,
+		Email:   d.Invoice.BillingEmail,
+		Address: d.Invoice.BillingAddress,
+		TaxID:   d.Invoice.TaxID,
+	}
+}
+
+var invoiceTemplate = template.Must(template.New("invoice").Funcs(template.FuncMap{
+	"date": func(t time.Time) string { return t.UTC().Format(dateLayout) },
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Invoice {{.Invoice.InvoiceNumber}}</title>
+<style>
+body { font-family: Helvetica, Arial, sans-serif; color: #222; max-width: 800px; margin: 40px auto; }
+table { width: 100%; border-collapse: collapse; }
+th, td { padding: 6px 8px; border-bottom: 1px solid #ddd; text-align: left; }
+.num { text-align: right; white-space: nowrap; }
+.parties { display: flex; justify-content: space-between; margin: 24px 0; }
+.parties p { margin: 0; line-height: 1.4; }
+</style>
+</head>
+<body>
+<h1>Invoice {{.Invoice.InvoiceNumber}}</h1>
+<p>Issued {{date .Invoice.IssuedAt}}, due {{date .Invoice.DueDate}}<br>
+Billing period {{date .Invoice.PeriodStart}} to {{date .Invoice.PeriodEnd}}</p>
+<div class="parties">
+<div><h2>From</h2><p>{{range .Seller.Lines}}{{.}}<br>{{end}}</p></div>
+<div><h2>Bill to</h2><p>{{range .Customer.Lines}}{{.}}<br>{{end}}</p></div>
+</div>
+<table>
+<thead><tr><th>Description</th><th class="num">Quantity</th><th class="num">Unit price</th><th class="num">Amount</th></tr></thead>
+<tbody>
+{{range .Invoice.Items}}<tr><td>{{.Description}}</td><td class="num">{{.Quantity}}</td><td class="num">{{.UnitPrice}}</td><td class="num">{{.Amount}}</td></tr>
+{{end}}</tbody>
+<tfoot>
+{{if .Invoice.Taxes}}<tr><th colspan="3" class="num">Subtotal</th><td class="num">{{.Invoice.Subtotal}}</td></tr>
+{{range .Invoice.Taxes}}<tr><th colspan="3" class="num">{{if $.Invoice.TaxInclusive}}Includes {{end}}{{.Label}} on {{.Taxable}}</th><td class="num">{{.Amount}}</td></tr>
+{{end}}{{end}}<tr><th colspan="3" class="num">Total</th><th class="num">{{.Invoice.Amount}}</th></tr>
+</tfoot>
+</table>
+{{if .Invoice.TaxNote}}<p>{{.Invoice.TaxNote}}</p>
+{{end}}<p>Status: {{.Invoice.Status}}</p>
+</body>
+</html>
+`))
+
+// RenderHTML writes the invoice as an HTML page
+func RenderHTML(w io.Writer, doc Document) error {
+	return invoiceTemplate.Execute(w, doc)
+}
@@ -0,0 +1,71 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+var ErrInvalidDunningPolicy = errors.New("invalid dunning policy")
+
+// DefaultDunningPolicy retries a failed payment 1, 3 and 7 days after the
+// invoice was due, reminds the organization on the due date and 3, 7 and 13
+// days after, and makes it read-only after 14 days
+var DefaultDunningPolicy = DunningPolicy{
+	RetryDays:    []int{1, 3, 7},
+	ReminderDays: []int{0, 3, 7, 13},
+	GraceDays:    14,
+}
+
+// DunningPolicy schedules the recovery of an overdue invoice. Days are counted
+// from the invoice's due date. Once the grace period is over the organization
+// is read-only until the invoice is paid.
+type DunningPolicy struct {
+	RetryDays    []int // when to charge the payment method again
+	ReminderDays []int // when to email a payment reminder
+	GraceDays    int
+}
+
+// Validate checks that the schedules are in order and end within the grace period
+func (p DunningPolicy) Validate() error {
+	if p.GraceDays < 0 {
+		return fmt.Errorf("%w: grace period cannot be negative", ErrInvalidDunningPolicy)
+	}
+	for name, days := range map[string][]int{"retry": p.RetryDays, "reminder": p.ReminderDays} {
+		if !sort.IntsAreSorted(days) {
+			return fmt.Errorf("%w: %s days must be in ascending order", ErrInvalidDunningPolicy, name)
+		}
+		if len(days) > 0 && (days[0] < 0 || days[len(days)-1] > p.GraceDays) {
+			return fmt.Errorf("%w: %s days must be within the grace period", ErrInvalidDunningPolicy, name)
+		}
+	}
+	return nil
+}
+
+// NextRetry returns when to charge an invoice again after the given number
+// of retries, or false once the retries are used up
+func (p DunningPolicy) NextRetry(dueDate time.Time, retries int) (time.Time, bool) {
+	if retries < 0 || retries >= len(p.RetryDays) {
+		return time.Time{}, false
+	}
+	return dueDate.AddDate(0, 0, p.RetryDays[retries]), true
+}
+
+// RemindersDue returns how many reminders should have been sent by now. A
+// job that fell behind sends one reminder and skips the ones it missed.
+func (p DunningPolicy) RemindersDue(dueDate, now time.Time) int {
+	due := 0
+	for _, days := range p.ReminderDays {
+		if now.Before(dueDate.AddDate(0, 0, days)) {
+			break
+		}
+		due++
+	}
+	return due
+}
+
+// GraceEnds returns when an organization with the invoice unpaid becomes read-only
+func (p DunningPolicy) GraceEnds(dueDate time.Time) time.Time {
+	return dueDate.AddDate(0, 0, p.GraceDays)
+}
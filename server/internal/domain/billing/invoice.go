@@ -0,0 +1,117 @@
+package billing
+
+import (
+	"fmt"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+// AddOnItems returns the items charged for a full period of the organization's
+// add-ons. Add-ons priced in another currency than the invoice are left out.
+func AddOnItems(addOns []models.OrganizationAddOn, currency string, period Period) ([]models.InvoiceItem, error) {
+	var items []models.InvoiceItem
+	for _, addOn := range addOns {
+		if addOn.Quantity <= 0 || addOn.AddOn.Price.Currency != currency {
+			continue
+		}
+		amount, err := addOn.AddOn.Price.Mul(int64(addOn.Quantity))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, models.InvoiceItem{
+			Kind:        models.InvoiceItemKindAddOn,
+			Description: addOn.AddOn.Name,
+			Quantity:    addOn.Quantity,
+			UnitPrice:   addOn.AddOn.Price,
+			Amount:      amount,
+			PeriodStart: period.Start,
+			PeriodEnd:   period.End,
+		})
+	}
+	return items, nil
+}
+
+// CarryCredit settles an invoice whose items add up to less than zero. It
+// returns the line that brings the invoice to zero and the item that credits
+// the rest on the next invoice. Other totals need neither and return nil.
+func CarryCredit(total money.Money, period Period) (*models.InvoiceItem, *models.InvoiceItem) {
+	if !total.IsNegative() {
+		return nil, nil
+	}
+	line := &models.InvoiceItem{
+		Kind:        models.InvoiceItemKindCredit,
+		Description: "Credit carried forward to the next invoice",
+		Quantity:    1,
+		UnitPrice:   total.Neg(),
+		Amount:      total.Neg(),
+		TaxAmount:   money.Zero(total.Currency),
+		PeriodStart: period.Start,
+		PeriodEnd:   period.End,
+	}
+	carried := &models.InvoiceItem{
+		Kind: models.InvoiceItemKindCredit,
+		Description: fmt.Sprintf("Credit carried forward from %s to %s",
+			period.Start.UTC().Format(dateLayout), period.End.UTC().Format(dateLayout)),
+		Quantity:    1,
+		UnitPrice:   total,
+		Amount:      total,
+		TaxAmount:   money.Zero(total.Currency),
+		PeriodStart: period.Start,
+		PeriodEnd:   period.End,
+	}
+	return line, carried
+}
+
+// DiscountItem returns the line giving a redeemed coupon's discount on an
+// invoice's items. The discount applies to the charges, not to credits, and
+// never takes them below zero. It returns nil when there is nothing to
+// discount, or when a fixed discount is in another currency than the invoice.
+func DiscountItem(redemption *models.CouponRedemption, items []models.InvoiceItem, currency string, period Period) (*models.InvoiceItem, error) {
+	var charges []money.Money
+	for _, item := range items {
+		if item.Kind != models.InvoiceItemKindCredit && item.Kind != models.InvoiceItemKindDiscount {
+			charges = append(charges, item.Amount)
+		}
+	}
+	base, err := money.Sum(currency, charges...)
+	if err != nil || !base.IsPositive() {
+		return nil, err
+	}
+
+	coupon := &redemption.Coupon
+	var discount money.Money
+	var description string
+	switch coupon.DiscountType {
+	case models.DiscountTypePercent:
+		if discount, err = base.MulRat(int64(coupon.PercentOff), 100); err != nil {
+			return nil, err
+		}
+		description = fmt.Sprintf("Discount %s (%d%% off)", coupon.Code, coupon.PercentOff)
+	case models.DiscountTypeFixed:
+		if coupon.AmountOff.Currency != currency {
+			return nil, nil
+		}
+		discount = coupon.AmountOff
+		if cmp, _ := discount.Cmp(base); cmp > 0 {
+			discount = base
+		}
+		description = fmt.Sprintf("Discount %s (%s off)", coupon.Code, coupon.AmountOff)
+	default:
+		return nil, models.ErrInvalidDiscountType
+	}
+	if !discount.IsPositive() {
+		return nil, nil
+	}
+
+	return &models.InvoiceItem{
+		Kind:               models.InvoiceItemKindDiscount,
+		Description:        description,
+		Quantity:           1,
+		UnitPrice:          discount.Neg(),
+		Amount:             discount.Neg(),
+		PeriodStart:        period.Start,
+		PeriodEnd:          period.End,
+		CouponRedemptionID: &redemption.ID,
+	}, nil
+}
@@ -0,0 +1,178 @@
+package billing
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+func TestAddOnItems(t *testing.T) {
+	march := Period{Start: utc(2026, 3, 1, 0, 0, 0), End: utc(2026, 4, 1, 0, 0, 0)}
+	addOn := func(name string, price money.Money, quantity int) models.OrganizationAddOn {
+		return models.OrganizationAddOn{AddOn: models.AddOn{Name: name, Price: price}, Quantity: quantity}
+	}
+
+	items, err := AddOnItems([]models.OrganizationAddOn{
+		addOn("Extra storage", money.New(500, "USD"), 3),
+		addOn("Priority support", money.New(4900, "USD"), 1),
+		addOn("Audit log", money.New(900, "EUR"), 1),
+		addOn("Cancelled seats", money.New(1000, "USD"), 0),
+	}, "USD", march)
+	if err != nil {
+		t.Fatalf("AddOnItems() error = %v", err)
+	}
+
+	want := []models.InvoiceItem{
+		{Kind: models.InvoiceItemKindAddOn, Description: "Extra storage", Quantity: 3, UnitPrice: money.New(500, "USD"), Amount: money.New(1500, "USD"), PeriodStart: march.Start, PeriodEnd: march.End},
+		{Kind: models.InvoiceItemKindAddOn, Description: "Priority support", Quantity: 1, UnitPrice: money.New(4900, "USD"), Amount: money.New(4900, "USD"), PeriodStart: march.Start, PeriodEnd: march.End},
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("AddOnItems() = %+v, want %+v", items, want)
+	}
+}
+
+func TestCarryCredit(t *testing.T) {
+	march := Period{Start: utc(2026, 3, 1, 0, 0, 0), End: utc(2026, 4, 1, 0, 0, 0)}
+
+	tests := []struct {
+		name  string
+		total money.Money
+		carry bool
+	}{
+		{"owed", money.New(2500, "USD"), false},
+		{"settled", money.Zero("USD"), false},
+		{"in credit", money.New(-2500, "USD"), true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line, carried := CarryCredit(tt.total, march)
+			if !tt.carry {
+				if line != nil || carried != nil {
+					t.Errorf("CarryCredit(%v) = %+v, %+v, want nothing", tt.total, line, carried)
+				}
+				return
+			}
+			if line == nil || carried == nil {
+				t.Fatalf("CarryCredit(%v) carried nothing", tt.total)
+			}
+			if settled, _ := tt.total.Add(line.Amount); !settled.IsZero() {
+				t.Errorf("invoice total after the line = %v, want zero", settled)
+			}
+			if carried.Amount != tt.total || carried.Kind != models.InvoiceItemKindCredit {
+				t.Errorf("carried %s item of %v, want a credit of %v", carried.Kind, carried.Amount, tt.total)
+			}
+			if want := "Credit carried forward from 2026-03-01 to 2026-04-01"; carried.Description != want {
+				t.Errorf("carried description = %q, want %q", carried.Description, want)
+			}
+		})
+	}
+}
+
+func TestDiscountItem(t *testing.T) {
+	march := Period{Start: utc(2026, 3, 1, 0, 0, 0), End: utc(2026, 4, 1, 0, 0, 0)}
+	items := []models.InvoiceItem{
+		{Kind: models.InvoiceItemKindPlan, Amount: money.New(4900, "USD")},
+		{Kind: models.InvoiceItemKindSeats, Amount: money.New(2100, "USD")},
+		{Kind: models.InvoiceItemKindCredit, Amount: money.New(-3000, "USD")},
+	}
+	percent := func(off int) models.Coupon {
+		return models.Coupon{Code: "SPRING", DiscountType: models.DiscountTypePercent, PercentOff: off}
+	}
+	fixed := func(off money.Money) models.Coupon {
+		return models.Coupon{Code: "WELCOME", DiscountType: models.DiscountTypeFixed, AmountOff: off}
+	}
+
+	tests := []struct {
+		name        string
+		coupon      models.Coupon
+		items       []models.InvoiceItem
+		discount    int64 // 0 for no discount line
+		description string
+		err         error
+	}{
+		{"percent of the charges", percent(25), items, 1750, "Discount SPRING (25% off)", nil},
+		{"percent of one charge", percent(15), items[:1], 735, "Discount SPRING (15% off)", nil},
+		{"full percent", percent(100), items, 7000, "Discount SPRING (100% off)", nil},
+		{"fixed", fixed(money.New(1000, "USD")), items, 1000, "Discount WELCOME (10.00 USD off)", nil},
+		{"fixed above the charges", fixed(money.New(10000, "USD")), items, 7000, "Discount WELCOME (100.00 USD off)", nil},
+		{"fixed in another currency", fixed(money.New(1000, "EUR")), items, 0, "", nil},
+		{"only credits", percent(25), items[2:], 0, "", nil},
+		{"no items", percent(25), nil, 0, "", nil},
+		{"unknown discount type", models.Coupon{Code: "ODD", DiscountType: "bogo"}, items, 0, "", models.ErrInvalidDiscountType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			redemption := &models.CouponRedemption{ID: 7, Coupon: tt.coupon}
+			item, err := DiscountItem(redemption, tt.items, "USD", march)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("DiscountItem() error = %v, want %v", err, tt.err)
+			}
+			if tt.discount == 0 {
+				if item != nil {
+					t.Errorf("DiscountItem() = %+v, want no discount", item)
+				}
+				return
+			}
+			if item == nil {
+				t.Fatal("DiscountItem() gave no discount")
+			}
+			if want := money.New(-tt.discount, "USD"); item.Amount != want || item.UnitPrice != want {
+				t.Errorf("discount = %v, want %v", item.Amount, want)
+			}
+			if item.Description != tt.description {
+				t.Errorf("Description = %q, want %q", item.Description, tt.description)
+			}
+			if item.Kind != models.InvoiceItemKindDiscount || item.CouponRedemptionID == nil || *item.CouponRedemptionID != 7 {
+				t.Errorf("item is a %s line of redemption %v, want a discount of redemption 7", item.Kind, item.CouponRedemptionID)
+			}
+		})
+	}
+}
+
+func TestRenderInvoice(t *testing.T) {
+	invoice := &models.Invoice{
+		InvoiceNumber:  "INV-000042",
+		IssuedAt:       utc(2026, 3, 1, 12, 0, 0),
+		DueDate:        utc(2026, 3, 15, 12, 0, 0),
+		PeriodStart:    utc(2026, 3, 1, 0, 0, 0),
+		PeriodEnd:      utc(2026, 4, 1, 0, 0, 0),
+		BillingName:    "Acme <Labs>",
+		BillingAddress: "1 Main St\n\nSpringfield",
+		Amount:         money.New(6400, "USD"),
+		Status:         models.PaymentStatusPending,
+		Items: []models.InvoiceItem{
+			{Description: "Team plan", Quantity: 1, UnitPrice: money.New(4900, "USD"), Amount: money.New(4900, "USD")},
+			{Description: "Extra storage", Quantity: 3, UnitPrice: money.New(500, "USD"), Amount: money.New(1500, "USD")},
+		},
+	}
+	doc := Document{Invoice: invoice, Seller: Party{Name: "Chorvo", TaxID: "EU123"}}
+
+	var html bytes.Buffer
+	if err := RenderHTML(&html, doc); err != nil {
+		t.Fatalf("RenderHTML() error = %v", err)
+	}
+	for _, want := range []string{
+		"Invoice INV-000042",
+		"Issued 2026-03-01, due 2026-03-15",
+		"Acme &lt;Labs&gt;<br>1 Main St<br>Springfield<br>",
+		"Tax ID: EU123",
+		"Extra storage",
+	} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("invoice HTML does not contain %q", want)
+		}
+	}
+
+	var pdf bytes.Buffer
+	if err := RenderPDF(&pdf, doc); err != nil {
+		t.Fatalf("RenderPDF() error = %v", err)
+	}
+	if !bytes.HasPrefix(pdf.Bytes(), []byte("%PDF-")) {
+		t.Errorf("RenderPDF() wrote %q, want a PDF", pdf.Bytes()[:min(pdf.Len(), 16)])
+	}
+}
@@ -0,0 +1,79 @@
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+var ErrInvalidNumberFormat = errors.New("invalid invoice number format")
+
+// DefaultNumberFormat numbers invoices INV-000001, INV-000002 and so on
+const DefaultNumberFormat NumberFormat = "INV-{SEQ:6}"
+
+// numberToken matches a placeholder in a number format, such as {SEQ:6}
+var numberToken = regexp.MustCompile(`\{([A-Z]+)(?::(\d+))?\}`)
+
+// NumberFormat is a template for invoice numbers. {SEQ} is replaced by the
+// sequence number and {SEQ:n} by the number padded with zeros to n digits;
+// {YYYY}, {YY} and {MM} by the issue date and {ORG} by the organization ID.
+type NumberFormat string
+
+// Validate checks that the format only uses known placeholders and includes
+// the sequence number. Numbers are unique across organizations, so a format
+// numbered per organization must include {ORG} too.
+func (f NumberFormat) Validate(perOrganization bool) error {
+	hasSeq, hasOrg := false, false
+	for _, match := range numberToken.FindAllStringSubmatch(string(f), -1) {
+		switch match[1] {
+		case "SEQ":
+			hasSeq = true
+		case "ORG":
+			hasOrg = true
+		case "YYYY", "YY", "MM":
+		default:
+			return fmt.Errorf("%w: unknown placeholder {%s}", ErrInvalidNumberFormat, match[1])
+		}
+		if match[2] != "" && match[1] != "SEQ" {
+			return fmt.Errorf("%w: only {SEQ} takes a width", ErrInvalidNumberFormat)
+		}
+	}
+	if !hasSeq {
+		return fmt.Errorf("%w: {SEQ} is required", ErrInvalidNumberFormat)
+	}
+	if perOrganization && !hasOrg {
+		return fmt.Errorf("%w: {ORG} is required when numbering per organization", ErrInvalidNumberFormat)
+	}
+	return nil
+}
+
+// Format builds the invoice number for a sequence number
+func (f NumberFormat) Format(seq int64, orgID uint, issuedAt time.Time) string {
+	return numberToken.ReplaceAllStringFunc(string(f), func(token string) string {
+		match := numberToken.FindStringSubmatch(token)
+		switch match[1] {
+		case "SEQ":
+			width, _ := strconv.Atoi(match[2])
+			return fmt.Sprintf("%0*d", width, seq)
+		case "ORG":
+			return strconv.FormatUint(uint64(orgID), 10)
+		case "YYYY":
+			return issuedAt.Format("2006")
+		case "YY":
+			return issuedAt.Format("06")
+		case "MM":
+			return issuedAt.Format("01")
+		}
+		return token
+	})
+}
+
+// SequenceScope names the numbering sequence an invoice takes its number from
+func SequenceScope(perOrganization bool, orgID uint) string {
+	if perOrganization {
+		return fmt.Sprintf("organization:%d", orgID)
+	}
+	return "global"
+}
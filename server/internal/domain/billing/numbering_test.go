@@ -0,0 +1,65 @@
+package billing
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNumberFormatValidate(t *testing.T) {
+	tests := []struct {
+		name            string
+		format          NumberFormat
+		perOrganization bool
+		err             error
+	}{
+		{"default", DefaultNumberFormat, false, nil},
+		{"dated", "INV-{YYYY}{MM}-{SEQ:4}", false, nil},
+		{"per organization", "{ORG}-{YY}-{SEQ}", true, nil},
+		{"per organization without {ORG}", DefaultNumberFormat, true, ErrInvalidNumberFormat},
+		{"without {SEQ}", "INV-{YYYY}", false, ErrInvalidNumberFormat},
+		{"unknown placeholder", "INV-{DD}-{SEQ}", false, ErrInvalidNumberFormat},
+		{"width on a date", "INV-{YYYY:2}-{SEQ}", false, ErrInvalidNumberFormat},
+		{"empty", "", false, ErrInvalidNumberFormat},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.format.Validate(tt.perOrganization); !errors.Is(err, tt.err) {
+				t.Errorf("Validate(%v) error = %v, want %v", tt.perOrganization, err, tt.err)
+			}
+		})
+	}
+}
+
+func TestNumberFormatFormat(t *testing.T) {
+	issuedAt := utc(2026, 3, 1, 12, 0, 0)
+
+	tests := []struct {
+		format NumberFormat
+		seq    int64
+		want   string
+	}{
+		{DefaultNumberFormat, 42, "INV-000042"},
+		{DefaultNumberFormat, 1234567, "INV-1234567"},
+		{"INV-{SEQ}", 7, "INV-7"},
+		{"{YYYY}/{MM}/{SEQ:3}", 7, "2026/03/007"},
+		{"{ORG}-{YY}-{SEQ:2}", 7, "17-26-07"},
+		{"{SEQ}-{SEQ:4}", 7, "7-0007"},
+		{"INV {literal}-{SEQ}", 7, "INV {literal}-7"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			if got := tt.format.Format(tt.seq, 17, issuedAt); got != tt.want {
+				t.Errorf("Format(%d) = %q, want %q", tt.seq, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSequenceScope(t *testing.T) {
+	if got := SequenceScope(false, 17); got != "global" {
+		t.Errorf("SequenceScope(false) = %q, want global", got)
+	}
+	if a, b := SequenceScope(true, 17), SequenceScope(true, 18); a != "organization:17" || a == b {
+		t.Errorf("SequenceScope(true) = %q and %q, want a scope per organization", a, b)
+	}
+}
@@ -0,0 +1,271 @@
+package billing
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// A4 page size and margins, in points
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+	pageMargin = 50.0
+)
+
+// Fonts are the standard Type 1 fonts every PDF reader has, so nothing needs
+// embedding. Figures use Courier, whose fixed width lets them be right-aligned
+// without font metrics.
+const (
+	fontRegular = "F1"
+	fontBold    = "F2"
+	fontMono    = "F3"
+)
+
+var pdfFonts = []struct{ name, base string }{
+	{fontRegular, "Helvetica"},
+	{fontBold, "Helvetica-Bold"},
+	{fontMono, "Courier"},
+}
+
+// Column positions of the line item table
+const (
+	descriptionWidth = 48 // characters
+	quantityRight    = 370.0
+	unitPriceRight   = 465.0
+	amountRight      = pageWidth - pageMargin
+)
+
+// RenderPDF writes the invoice as a PDF document
+func RenderPDF(w io.Writer, doc Document) error {
+	invoice := doc.Invoice
+	pdf := newPDFWriter()
+
+	pdf.text(fontBold, 20, pageMargin, "Invoice "+invoice.InvoiceNumber)
+	pdf.advance(28)
+	pdf.text(fontRegular, 10, pageMargin, fmt.Sprintf("Issued %s, due %s",
+		invoice.IssuedAt.UTC().Format(dateLayout), invoice.DueDate.UTC().Format(dateLayout)))
+	pdf.advance(14)
+	pdf.text(fontRegular, 10, pageMargin, fmt.Sprintf("Billing period %s to %s",
+		invoice.PeriodStart.UTC().Format(dateLayout), invoice.PeriodEnd.UTC().Format(dateLayout)))
+	pdf.advance(32)
+
+	seller, customer := doc.Seller.Lines(), doc.Customer().Lines()
+	pdf.text(fontBold, 12, pageMargin, "From")
+	pdf.text(fontBold, 12, pageWidth/2, "Bill to")
+	pdf.advance(16)
+	for i := 0; i < len(seller) || i < len(customer); i++ {
+		pdf.ensureSpace(14)
+		if i < len(seller) {
+			pdf.text(fontRegular, 10, pageMargin, seller[i])
+		}
+		if i < len(customer) {
+			pdf.text(fontRegular, 10, pageWidth/2, customer[i])
+		}
+		pdf.advance(14)
+	}
+	pdf.advance(20)
+
+	pdf.text(fontBold, 10, pageMargin, "Description")
+	pdf.rightText(fontBold, 10, quantityRight, "Quantity")
+	pdf.rightText(fontBold, 10, unitPriceRight, "Unit price")
+	pdf.rightText(fontBold, 10, amountRight, "Amount")
+	pdf.advance(6)
+	pdf.rule()
+	pdf.advance(14)
+
+	for _, item := range invoice.Items {
+		lines := wrapText(item.Description, descriptionWidth)
+		pdf.ensureSpace(float64(len(lines)) * 13)
+		pdf.rightText(fontMono, 9, quantityRight, strconv.Itoa(item.Quantity))
+		pdf.rightText(fontMono, 9, unitPriceRight, item.UnitPrice.String())
+		pdf.rightText(fontMono, 9, amountRight, item.Amount.String())
+		for _, line := range lines {
+			pdf.text(fontRegular, 10, pageMargin, line)
+			pdf.advance(13)
+		}
+		pdf.advance(3)
+	}
+
+	pdf.ensureSpace(40)
+	pdf.rule()
+	pdf.advance(16)
+	if len(invoice.Taxes) > 0 {
+		pdf.rightText(fontRegular, 10, unitPriceRight, "Subtotal")
+		pdf.rightText(fontMono, 10, amountRight, invoice.Subtotal.String())
+		pdf.advance(14)
+		for _, line := range invoice.Taxes {
+			label := line.Label() + " on " + line.Taxable.String()
+			if invoice.TaxInclusive {
+				label = "Includes " + label
+			}
+			pdf.ensureSpace(14)
+			pdf.rightText(fontRegular, 10, unitPriceRight, label)
+			pdf.rightText(fontMono, 10, amountRight, line.Amount.String())
+			pdf.advance(14)
+		}
+		pdf.advance(4)
+	}
+	pdf.rightText(fontBold, 11, unitPriceRight, "Total")
+	pdf.rightText(fontMono, 10, amountRight, invoice.Amount.String())
+	pdf.advance(28)
+	if invoice.TaxNote != "" {
+		for _, line := range wrapText(invoice.TaxNote, 90) {
+			pdf.ensureSpace(13)
+			pdf.text(fontRegular, 9, pageMargin, line)
+			pdf.advance(13)
+		}
+		pdf.advance(8)
+	}
+	pdf.text(fontRegular, 10, pageMargin, "Status: "+string(invoice.Status))
+
+	_, err := w.Write(pdf.bytes())
+	return err
+}
+
+// pdfWriter lays out text top to bottom on as many pages as it needs
+type pdfWriter struct {
+	pages []*bytes.Buffer
+	y     float64 // baseline of the current line
+}
+
+func newPDFWriter() *pdfWriter {
+	w := &pdfWriter{}
+	w.newPage()
+	return w
+}
+
+func (w *pdfWriter) newPage() {
+	w.pages = append(w.pages, &bytes.Buffer{})
+	w.y = pageHeight - pageMargin
+}
+
+func (w *pdfWriter) page() *bytes.Buffer {
+	return w.pages[len(w.pages)-1]
+}
+
+// advance moves down by the given height
+func (w *pdfWriter) advance(height float64) {
+	w.y -= height
+}
+
+// ensureSpace starts a new page unless height fits above the bottom margin
+func (w *pdfWriter) ensureSpace(height float64) {
+	if w.y-height < pageMargin {
+		w.newPage()
+	}
+}
+
+func (w *pdfWriter) text(font string, size, x float64, s string) {
+	fmt.Fprintf(w.page(), "BT /%s %g Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, w.y, pdfString(s))
+}
+
+// rightText writes text ending at x. Widths are only known for Courier, whose
+// characters are 0.6 em wide; other fonts are treated as roughly that wide.
+func (w *pdfWriter) rightText(font string, size, right float64, s string) {
+	width := float64(utf8.RuneCountInString(s)) * size * 0.6
+	if font == fontBold {
+		width *= 0.95
+	}
+	w.text(font, size, right-width, s)
+}
+
+// rule draws a light horizontal line across the page
+func (w *pdfWriter) rule() {
+	fmt.Fprintf(w.page(), "0.75 G %.2f %.2f m %.2f %.2f l S 0 G\n", pageMargin, w.y, pageWidth-pageMargin, w.y)
+}
+
+// bytes assembles the document: catalog, page tree, fonts, then each page
+// with its content stream, followed by the cross-reference table
+func (w *pdfWriter) bytes() []byte {
+	var out bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	firstPage := 3 + len(pdfFonts)
+	kids := make([]string, len(w.pages))
+	for i := range w.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+	fonts := make([]string, len(pdfFonts))
+	for i, font := range pdfFonts {
+		fonts[i] = fmt.Sprintf("/%s %d 0 R", font.name, 3+i)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(w.pages)))
+	for _, font := range pdfFonts {
+		object(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", font.base))
+	}
+	for i, content := range w.pages {
+		object(fmt.Sprintf(
+			"<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %g %g] /Resources << /Font << %s >> >> /Contents %d 0 R >>",
+			pageWidth, pageHeight, strings.Join(fonts, " "), firstPage+2*i+1,
+		))
+		object(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+	return out.Bytes()
+}
+
+// winAnsi maps characters outside Latin-1 that WinAnsiEncoding can show
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// pdfString encodes text as the body of a PDF literal string. Characters the
+// standard fonts cannot show are replaced with a question mark.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			b.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&b, "\\%03o", r)
+		case winAnsi[r] != 0:
+			fmt.Fprintf(&b, "\\%03o", winAnsi[r])
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// wrapText breaks text into lines of at most width characters at spaces
+func wrapText(text string, width int) []string {
+	var lines []string
+	line := ""
+	for _, word := range strings.Fields(text) {
+		switch {
+		case line == "":
+			line = word
+		case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+			line += " " + word
+		default:
+			lines = append(lines, line)
+			line = word
+		}
+	}
+	if line != "" || len(lines) == 0 {
+		lines = append(lines, line)
+	}
+	return lines
+}
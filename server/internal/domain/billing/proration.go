@@ -0,0 +1,189 @@
+// Package billing computes what subscriptions are charged: the items of a
+// billing period, coupon discounts, the tax on them, and the prorated credits
+// and charges of changes made partway through one. It also numbers invoices,
+// renders them as HTML and PDF, and schedules the recovery of overdue ones.
+package billing
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+var ErrEmptyPeriod = errors.New("billing period must end after it starts")
+
+// dateLayout formats period dates in item descriptions
+const dateLayout = "2006-01-02"
+
+// Period is a billing period from Start up to, but not including, End
+type Period struct {
+	Start time.Time
+	End   time.Time
+}
+
+// Seconds returns the length of the period in whole seconds. Lengths are
+// taken from Unix times, so they do not depend on time zones or daylight
+// saving changes.
+func (p Period) Seconds() int64 {
+	return p.End.Unix() - p.Start.Unix()
+}
+
+// RemainingSeconds returns the whole seconds left in the period at t, which is
+// the full period before it starts and none after it ends
+func (p Period) RemainingSeconds(t time.Time) int64 {
+	switch {
+	case !t.After(p.Start):
+		return p.Seconds()
+	case !t.Before(p.End):
+		return 0
+	}
+	return p.End.Unix() - t.Unix()
+}
+
+// Prorate returns the share of amount for the time left in the period at t,
+// rounded half away from zero to the currency's minor unit
+func Prorate(amount money.Money, period Period, t time.Time) (money.Money, error) {
+	total := period.Seconds()
+	if total <= 0 {
+		return money.Money{}, ErrEmptyPeriod
+	}
+	return amount.MulRat(period.RemainingSeconds(t), total)
+}
+
+// PeriodItems returns the items charged for a full period of a plan: the plan
+// itself and any seats beyond those it includes. Free items are left out.
+func PeriodItems(plan *models.Plan, seats int, period Period) ([]models.InvoiceItem, error) {
+	var items []models.InvoiceItem
+	if plan.Price.IsPositive() {
+		items = append(items, models.InvoiceItem{
+			Kind:        models.InvoiceItemKindPlan,
+			Description: fmt.Sprintf("%s plan (%s)", plan.Name, plan.BillingInterval),
+			Quantity:    1,
+			UnitPrice:   plan.Price,
+			Amount:      plan.Price,
+			PeriodStart: period.Start,
+			PeriodEnd:   period.End,
+		})
+	}
+
+	if extra := plan.ExtraSeats(seats); extra > 0 && plan.SeatPrice.IsPositive() {
+		amount, err := plan.SeatPrice.Mul(int64(extra))
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, models.InvoiceItem{
+			Kind:        models.InvoiceItemKindSeats,
+			Description: fmt.Sprintf("%s plan seats", plan.Name),
+			Quantity:    extra,
+			UnitPrice:   plan.SeatPrice,
+			Amount:      amount,
+			PeriodStart: period.Start,
+			PeriodEnd:   period.End,
+		})
+	}
+	return items, nil
+}
+
+// PlanChangeItems returns the prorated items for moving from one plan and seat
+// count to another at t, partway through a period that has been billed: a
+// credit for the unused time on the old plan and a charge for the rest of the
+// period on the new one. When the new plan has another billing interval a new
+// period starts at t and is billed in full on its own, so only the credit is
+// returned.
+func PlanChangeItems(from *models.Plan, fromSeats int, to *models.Plan, toSeats int, period Period, t time.Time) ([]models.InvoiceItem, error) {
+	old, err := PeriodItems(from, fromSeats, period)
+	if err != nil {
+		return nil, err
+	}
+	items, err := prorateItems(old, period, t, true)
+	if err != nil {
+		return nil, err
+	}
+	if from.BillingInterval != to.BillingInterval {
+		return items, nil
+	}
+
+	next, err := PeriodItems(to, toSeats, period)
+	if err != nil {
+		return nil, err
+	}
+	charges, err := prorateItems(next, period, t, false)
+	if err != nil {
+		return nil, err
+	}
+	return append(items, charges...), nil
+}
+
+// SeatChangeItems returns the prorated charge for seats added at t, partway
+// through a billed period, or the credit for seats removed
+func SeatChangeItems(plan *models.Plan, fromSeats, toSeats int, period Period, t time.Time) ([]models.InvoiceItem, error) {
+	added := plan.ExtraSeats(toSeats) - plan.ExtraSeats(fromSeats)
+	if added == 0 || !plan.SeatPrice.IsPositive() {
+		return nil, nil
+	}
+
+	credit := added < 0
+	if credit {
+		added = -added
+	}
+	amount, err := plan.SeatPrice.Mul(int64(added))
+	if err != nil {
+		return nil, err
+	}
+	return prorateItems([]models.InvoiceItem{{
+		Kind:        models.InvoiceItemKindSeats,
+		Description: fmt.Sprintf("%s plan seats", plan.Name),
+		Quantity:    added,
+		UnitPrice:   plan.SeatPrice,
+		Amount:      amount,
+	}}, period, t, credit)
+}
+
+// prorateItems turns full-period items into items for the rest of the period
+// from t, negated when they are credits. Each item's unit price is prorated
+// and then multiplied by its quantity, so that every line adds up exactly.
+// Items that round to nothing are left out.
+func prorateItems(items []models.InvoiceItem, period Period, t time.Time, credit bool) ([]models.InvoiceItem, error) {
+	if period.Seconds() <= 0 {
+		return nil, ErrEmptyPeriod
+	}
+	from := t
+	if from.Before(period.Start) {
+		from = period.Start
+	}
+
+	prorated := make([]models.InvoiceItem, 0, len(items))
+	for _, item := range items {
+		unitPrice, err := Prorate(item.UnitPrice, period, t)
+		if err != nil {
+			return nil, err
+		}
+		if unitPrice.IsZero() {
+			continue
+		}
+		if credit {
+			unitPrice = unitPrice.Neg()
+		}
+		amount, err := unitPrice.Mul(int64(item.Quantity))
+		if err != nil {
+			return nil, err
+		}
+
+		description := "Remaining time on " + item.Description
+		if credit {
+			description = "Unused time on " + item.Description
+		}
+		item.Description = fmt.Sprintf("%s from %s to %s",
+			description, from.UTC().Format(dateLayout), period.End.UTC().Format(dateLayout))
+		item.UnitPrice = unitPrice
+		item.Amount = amount
+		item.PeriodStart = from
+		item.PeriodEnd = period.End
+		item.Proration = true
+		prorated = append(prorated, item)
+	}
+	return prorated, nil
+}
@@ -0,0 +1,413 @@
+package billing
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata" // periods in named zones must not depend on the host's zone database
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+func utc(year int, month time.Month, day, hour, min, sec int) time.Time {
+	return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+}
+
+func newYork(t *testing.T) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("loading zone: %v", err)
+	}
+	return loc
+}
+
+func TestProrate(t *testing.T) {
+	ny := newYork(t)
+	january := Period{Start: utc(2024, 1, 1, 0, 0, 0), End: utc(2024, 2, 1, 0, 0, 0)}
+	leapFebruary := Period{Start: utc(2024, 2, 1, 0, 0, 0), End: utc(2024, 3, 1, 0, 0, 0)}
+
+	tests := []struct {
+		name   string
+		amount money.Money
+		period Period
+		at     time.Time
+		want   money.Money
+	}{
+		{
+			name:   "last day of a leap February",
+			amount: money.New(2900, "USD"),
+			period: leapFebruary,
+			at:     utc(2024, 2, 29, 0, 0, 0),
+			want:   money.New(100, "USD"), // 1 of 29 days
+		},
+		{
+			name:   "period starting on February 29",
+			amount: money.New(2900, "USD"),
+			period: Period{Start: utc(2024, 2, 29, 0, 0, 0), End: utc(2024, 3, 29, 0, 0, 0)},
+			at:     utc(2024, 3, 15, 0, 0, 0),
+			want:   money.New(1400, "USD"), // 14 of 29 days
+		},
+		{
+			name:   "yearly period from February 29",
+			amount: money.New(12000, "USD"),
+			period: Period{Start: utc(2024, 2, 29, 0, 0, 0), End: utc(2025, 2, 28, 0, 0, 0)},
+			at:     utc(2024, 8, 29, 0, 0, 0),
+			want:   money.New(6016, "USD"), // 183 of 365 days is 6016.44
+		},
+		{
+			name:   "last second of a month",
+			amount: money.New(99999999, "USD"),
+			period: january,
+			at:     utc(2024, 1, 31, 23, 59, 59),
+			want:   money.New(37, "USD"), // 1 of 2678400 seconds is 37.34
+		},
+		{
+			name:   "last second of a month rounds a small amount to nothing",
+			amount: money.New(3100, "USD"),
+			period: january,
+			at:     utc(2024, 1, 31, 23, 59, 59),
+			want:   money.New(0, "USD"),
+		},
+		{
+			name:   "spring forward in New York",
+			amount: money.New(3100, "USD"),
+			period: Period{Start: time.Date(2024, 3, 1, 0, 0, 0, 0, ny), End: time.Date(2024, 4, 1, 0, 0, 0, 0, ny)},
+			at:     time.Date(2024, 3, 10, 3, 0, 0, 0, ny),
+			want:   money.New(2190, "USD"), // 1890000 of 2674800 seconds, the month being an hour short
+		},
+		{
+			name:   "fall back in New York",
+			amount: money.New(3000, "USD"),
+			period: Period{Start: time.Date(2024, 11, 1, 0, 0, 0, 0, ny), End: time.Date(2024, 12, 1, 0, 0, 0, 0, ny)},
+			at:     time.Date(2024, 11, 3, 12, 0, 0, 0, ny),
+			want:   money.New(2746, "USD"), // 2376000 of 2595600 seconds, the month being an hour long
+		},
+		{
+			name:   "zone of the change does not matter",
+			amount: money.New(3100, "USD"),
+			period: Period{Start: time.Date(2024, 3, 1, 0, 0, 0, 0, ny), End: time.Date(2024, 4, 1, 0, 0, 0, 0, ny)},
+			at:     time.Date(2024, 3, 10, 7, 0, 0, 0, time.UTC),
+			want:   money.New(2190, "USD"),
+		},
+		{
+			name:   "half a minor unit rounds up",
+			amount: money.New(1, "USD"),
+			period: Period{Start: utc(2024, 4, 1, 0, 0, 0), End: utc(2024, 4, 3, 0, 0, 0)},
+			at:     utc(2024, 4, 2, 0, 0, 0),
+			want:   money.New(1, "USD"),
+		},
+		{
+			name:   "negative half a minor unit rounds away from zero",
+			amount: money.New(-5, "USD"),
+			period: Period{Start: utc(2024, 4, 1, 0, 0, 0), End: utc(2024, 4, 3, 0, 0, 0)},
+			at:     utc(2024, 4, 2, 0, 0, 0),
+			want:   money.New(-3, "USD"),
+		},
+		{
+			name:   "zero-decimal currency",
+			amount: money.New(1000, "JPY"),
+			period: Period{Start: utc(2024, 4, 1, 0, 0, 0), End: utc(2024, 4, 4, 0, 0, 0)},
+			at:     utc(2024, 4, 3, 0, 0, 0),
+			want:   money.New(333, "JPY"),
+		},
+		{
+			name:   "three-decimal currency",
+			amount: money.New(10000, "BHD"),
+			period: Period{Start: utc(2024, 4, 1, 0, 0, 0), End: utc(2024, 4, 4, 0, 0, 0)},
+			at:     utc(2024, 4, 2, 0, 0, 0),
+			want:   money.New(6667, "BHD"),
+		},
+		{
+			name:   "before the period",
+			amount: money.New(2900, "USD"),
+			period: leapFebruary,
+			at:     utc(2024, 1, 15, 0, 0, 0),
+			want:   money.New(2900, "USD"),
+		},
+		{
+			name:   "at the end of the period",
+			amount: money.New(2900, "USD"),
+			period: leapFebruary,
+			at:     leapFebruary.End,
+			want:   money.New(0, "USD"),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Prorate(tt.amount, tt.period, tt.at)
+			if err != nil {
+				t.Fatalf("Prorate error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Prorate = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProrateEmptyPeriod(t *testing.T) {
+	start := utc(2024, 2, 29, 0, 0, 0)
+	for _, period := range []Period{{Start: start, End: start}, {Start: start, End: start.Add(-time.Hour)}} {
+		if _, err := Prorate(money.New(100, "USD"), period, start); !errors.Is(err, ErrEmptyPeriod) {
+			t.Errorf("Prorate over %v error = %v, want ErrEmptyPeriod", period, err)
+		}
+		if _, err := SeatChangeItems(seatPlan(), 1, 9, period, start); !errors.Is(err, ErrEmptyPeriod) {
+			t.Errorf("SeatChangeItems over %v error = %v, want ErrEmptyPeriod", period, err)
+		}
+	}
+}
+
+func basicPlan() *models.Plan {
+	return &models.Plan{
+		Name:            "Basic",
+		Price:           money.New(1000, "USD"),
+		SeatPrice:       money.New(500, "USD"),
+		IncludedSeats:   3,
+		BillingInterval: models.BillingIntervalMonthly,
+	}
+}
+
+func proPlan(interval models.BillingInterval) *models.Plan {
+	return &models.Plan{
+		Name:            "Pro",
+		Price:           money.New(3000, "USD"),
+		SeatPrice:       money.New(800, "USD"),
+		IncludedSeats:   5,
+		BillingInterval: interval,
+	}
+}
+
+func seatPlan() *models.Plan {
+	return proPlan(models.BillingIntervalMonthly)
+}
+
+// wantItem is the part of a prorated invoice item the tests check
+type wantItem struct {
+	kind        models.InvoiceItemKind
+	description string
+	quantity    int
+	unitPrice   int64
+	amount      int64
+}
+
+func checkItems(t *testing.T, items []models.InvoiceItem, want []wantItem, start, end time.Time) {
+	t.Helper()
+	if len(items) != len(want) {
+		t.Fatalf("got %d items, want %d: %+v", len(items), len(want), items)
+	}
+	for i, item := range items {
+		w := want[i]
+		if item.Kind != w.kind || item.Description != w.description || item.Quantity != w.quantity ||
+			item.UnitPrice != money.New(w.unitPrice, "USD") || item.Amount != money.New(w.amount, "USD") {
+			t.Errorf("item %d = %s %q %d x %v = %v, want %s %q %d x %d = %d",
+				i, item.Kind, item.Description, item.Quantity, item.UnitPrice, item.Amount,
+				w.kind, w.description, w.quantity, w.unitPrice, w.amount)
+		}
+		if !item.Proration || !item.PeriodStart.Equal(start) || !item.PeriodEnd.Equal(end) {
+			t.Errorf("item %d covers %v to %v (proration %v), want %v to %v", i, item.PeriodStart, item.PeriodEnd, item.Proration, start, end)
+		}
+		if product, _ := item.UnitPrice.Mul(int64(item.Quantity)); product != item.Amount {
+			t.Errorf("item %d amount %v is not %d x %v", i, item.Amount, item.Quantity, item.UnitPrice)
+		}
+	}
+}
+
+func TestPlanChangeItems(t *testing.T) {
+	april := Period{Start: utc(2024, 4, 1, 0, 0, 0), End: utc(2024, 5, 1, 0, 0, 0)}
+	leapFebruary := Period{Start: utc(2024, 2, 1, 0, 0, 0), End: utc(2024, 3, 1, 0, 0, 0)}
+	january := Period{Start: utc(2024, 1, 1, 0, 0, 0), End: utc(2024, 2, 1, 0, 0, 0)}
+
+	tests := []struct {
+		name      string
+		from      *models.Plan
+		to        *models.Plan
+		fromSeats int
+		toSeats   int
+		period    Period
+		at        time.Time
+		start     time.Time
+		want      []wantItem
+	}{
+		{
+			name:      "upgrade with a third of the period left",
+			from:      basicPlan(),
+			to:        proPlan(models.BillingIntervalMonthly),
+			fromSeats: 5,
+			toSeats:   8,
+			period:    april,
+			at:        utc(2024, 4, 21, 0, 0, 0),
+			start:     utc(2024, 4, 21, 0, 0, 0),
+			want: []wantItem{
+				{models.InvoiceItemKindPlan, "Unused time on Basic plan (monthly) from 2024-04-21 to 2024-05-01", 1, -333, -333},
+				{models.InvoiceItemKindSeats, "Unused time on Basic plan seats from 2024-04-21 to 2024-05-01", 2, -167, -334},
+				{models.InvoiceItemKindPlan, "Remaining time on Pro plan (monthly) from 2024-04-21 to 2024-05-01", 1, 1000, 1000},
+				{models.InvoiceItemKindSeats, "Remaining time on Pro plan seats from 2024-04-21 to 2024-05-01", 3, 267, 801},
+			},
+		},
+		{
+			name:      "downgrade on February 29",
+			from:      proPlan(models.BillingIntervalMonthly),
+			to:        basicPlan(),
+			fromSeats: 8,
+			toSeats:   3,
+			period:    leapFebruary,
+			at:        utc(2024, 2, 29, 0, 0, 0),
+			start:     utc(2024, 2, 29, 0, 0, 0),
+			want: []wantItem{
+				{models.InvoiceItemKindPlan, "Unused time on Pro plan (monthly) from 2024-02-29 to 2024-03-01", 1, -103, -103},
+				{models.InvoiceItemKindSeats, "Unused time on Pro plan seats from 2024-02-29 to 2024-03-01", 3, -28, -84},
+				{models.InvoiceItemKindPlan, "Remaining time on Basic plan (monthly) from 2024-02-29 to 2024-03-01", 1, 34, 34},
+			},
+		},
+		{
+			name:      "change at the last second of the month rounds to nothing",
+			from:      basicPlan(),
+			to:        proPlan(models.BillingIntervalMonthly),
+			fromSeats: 5,
+			toSeats:   8,
+			period:    january,
+			at:        utc(2024, 1, 31, 23, 59, 59),
+			start:     utc(2024, 1, 31, 23, 59, 59),
+			want:      []wantItem{},
+		},
+		{
+			name:      "change of interval only credits the old plan",
+			from:      basicPlan(),
+			to:        proPlan(models.BillingIntervalYearly),
+			fromSeats: 3,
+			toSeats:   8,
+			period:    april,
+			at:        utc(2024, 4, 16, 0, 0, 0),
+			start:     utc(2024, 4, 16, 0, 0, 0),
+			want: []wantItem{
+				{models.InvoiceItemKindPlan, "Unused time on Basic plan (monthly) from 2024-04-16 to 2024-05-01", 1, -500, -500},
+			},
+		},
+		{
+			name:      "change before the period starts covers all of it",
+			from:      basicPlan(),
+			to:        proPlan(models.BillingIntervalMonthly),
+			fromSeats: 3,
+			toSeats:   5,
+			period:    april,
+			at:        utc(2024, 3, 30, 12, 0, 0),
+			start:     april.Start,
+			want: []wantItem{
+				{models.InvoiceItemKindPlan, "Unused time on Basic plan (monthly) from 2024-04-01 to 2024-05-01", 1, -1000, -1000},
+				{models.InvoiceItemKindPlan, "Remaining time on Pro plan (monthly) from 2024-04-01 to 2024-05-01", 1, 3000, 3000},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := PlanChangeItems(tt.from, tt.fromSeats, tt.to, tt.toSeats, tt.period, tt.at)
+			if err != nil {
+				t.Fatalf("PlanChangeItems error: %v", err)
+			}
+			checkItems(t, items, tt.want, tt.start, tt.period.End)
+		})
+	}
+}
+
+func TestPlanChangeItemsAcrossDST(t *testing.T) {
+	ny := newYork(t)
+	period := Period{Start: time.Date(2024, 3, 1, 0, 0, 0, 0, ny), End: time.Date(2024, 4, 1, 0, 0, 0, 0, ny)}
+	at := time.Date(2024, 3, 10, 3, 0, 0, 0, ny)
+
+	items, err := PlanChangeItems(basicPlan(), 3, proPlan(models.BillingIntervalMonthly), 5, period, at)
+	if err != nil {
+		t.Fatalf("PlanChangeItems error: %v", err)
+	}
+	// 1890000 of 2674800 seconds: 706.61 of 10.00 and 2119.84 of 30.00.
+	// Descriptions give the dates in UTC.
+	checkItems(t, items, []wantItem{
+		{models.InvoiceItemKindPlan, "Unused time on Basic plan (monthly) from 2024-03-10 to 2024-04-01", 1, -707, -707},
+		{models.InvoiceItemKindPlan, "Remaining time on Pro plan (monthly) from 2024-03-10 to 2024-04-01", 1, 2120, 2120},
+	}, at, period.End)
+}
+
+func TestSeatChangeItems(t *testing.T) {
+	january := Period{Start: utc(2024, 1, 1, 0, 0, 0), End: utc(2024, 2, 1, 0, 0, 0)}
+	mid := utc(2024, 1, 16, 0, 0, 0) // 16 of 31 days left
+
+	tests := []struct {
+		name      string
+		fromSeats int
+		toSeats   int
+		at        time.Time
+		want      []wantItem
+	}{
+		{
+			name:      "seats added",
+			fromSeats: 7,
+			toSeats:   10,
+			at:        mid,
+			want: []wantItem{
+				{models.InvoiceItemKindSeats, "Remaining time on Pro plan seats from 2024-01-16 to 2024-02-01", 3, 413, 1239},
+			},
+		},
+		{
+			name:      "seats removed are credited",
+			fromSeats: 10,
+			toSeats:   7,
+			at:        mid,
+			want: []wantItem{
+				{models.InvoiceItemKindSeats, "Unused time on Pro plan seats from 2024-01-16 to 2024-02-01", 3, -413, -1239},
+			},
+		},
+		{
+			name:      "removal into the included seats credits only extra seats",
+			fromSeats: 9,
+			toSeats:   2,
+			at:        mid,
+			want: []wantItem{
+				{models.InvoiceItemKindSeats, "Unused time on Pro plan seats from 2024-01-16 to 2024-02-01", 4, -413, -1652},
+			},
+		},
+		{
+			name:      "removal within the included seats",
+			fromSeats: 5,
+			toSeats:   2,
+			at:        mid,
+			want:      []wantItem{},
+		},
+		{
+			name:      "removal at the last second of the month",
+			fromSeats: 9,
+			toSeats:   6,
+			at:        utc(2024, 1, 31, 23, 59, 59),
+			want:      []wantItem{},
+		},
+		{
+			name:      "unit price is rounded before multiplying",
+			fromSeats: 5,
+			toSeats:   12,
+			at:        utc(2024, 1, 21, 16, 0, 0), // 10 days 8 hours left, 800 x 248/744 is 266.67
+			want: []wantItem{
+				{models.InvoiceItemKindSeats, "Remaining time on Pro plan seats from 2024-01-21 to 2024-02-01", 7, 267, 1869},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := SeatChangeItems(seatPlan(), tt.fromSeats, tt.toSeats, january, tt.at)
+			if err != nil {
+				t.Fatalf("SeatChangeItems error: %v", err)
+			}
+			checkItems(t, items, tt.want, tt.at, january.End)
+		})
+	}
+}
+
+func TestSeatChangeItemsFreeSeats(t *testing.T) {
+	plan := seatPlan()
+	plan.SeatPrice = money.Zero("USD")
+	january := Period{Start: utc(2024, 1, 1, 0, 0, 0), End: utc(2024, 2, 1, 0, 0, 0)}
+
+	items, err := SeatChangeItems(plan, 10, 6, january, utc(2024, 1, 16, 0, 0, 0))
+	if err != nil || items != nil {
+		t.Errorf("SeatChangeItems with free seats = %v, %v, want nil, nil", items, err)
+	}
+}
@@ -0,0 +1,67 @@
+package billing
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/tax"
+)
+
+// Taxes is the tax on an invoice's charges
+type Taxes struct {
+	Subtotal  money.Money         // the charges, before account credit
+	Tax       money.Money         // included in Subtotal when prices include tax
+	Breakdown []models.InvoiceTax // one line per rate
+}
+
+// ApplyTax sets the tax of each item and adds it up. Tax is worked out line
+// by line, so each line's tax is rounded on its own. Account credit is not
+// taxed: it was either paid already or is a promotion taken off the total.
+func ApplyTax(items []models.InvoiceItem, treatment tax.Treatment, currency string) (*Taxes, error) {
+	taxes := &Taxes{Subtotal: money.Zero(currency), Tax: money.Zero(currency)}
+	taxable := money.Zero(currency)
+	for i := range items {
+		item := &items[i]
+		item.TaxRate = 0
+		item.TaxAmount = money.Zero(currency)
+		if item.Kind == models.InvoiceItemKindCredit {
+			continue
+		}
+
+		net, amount, err := treatment.Split(item.Amount)
+		if err != nil {
+			return nil, err
+		}
+		item.TaxRate = treatment.Rate
+		item.TaxAmount = amount
+		if taxes.Subtotal, err = taxes.Subtotal.Add(item.Amount); err != nil {
+			return nil, err
+		}
+		if taxes.Tax, err = taxes.Tax.Add(amount); err != nil {
+			return nil, err
+		}
+		if taxable, err = taxable.Add(net); err != nil {
+			return nil, err
+		}
+	}
+
+	if treatment.Country != "" {
+		taxes.Breakdown = []models.InvoiceTax{{
+			Country:       treatment.Country,
+			TaxName:       treatment.TaxName,
+			Rate:          treatment.Rate,
+			ReverseCharge: treatment.ReverseCharge,
+			Taxable:       taxable,
+			Amount:        taxes.Tax,
+		}}
+	}
+	return taxes, nil
+}
+
+// Total returns what the customer pays for the charges: the subtotal, with the
+// tax added on top unless prices include it
+func (t *Taxes) Total(inclusive bool) (money.Money, error) {
+	if inclusive {
+		return t.Subtotal, nil
+	}
+	return t.Subtotal.Add(t.Tax)
+}
@@ -0,0 +1,213 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ActivityAction describes what happened to an entity
+type ActivityAction string
+
+const (
+	ActivityActionCreated ActivityAction = "created"
+	ActivityActionUpdated ActivityAction = "updated"
+	ActivityActionDeleted ActivityAction = "deleted"
+)
+
+// ActivityEntity identifies the kind of entity an activity event refers to
+type ActivityEntity string
+
+const (
+	ActivityEntityTask               ActivityEntity = "task"
+	ActivityEntityProject            ActivityEntity = "project"
+	ActivityEntityComment            ActivityEntity = "comment"
+	ActivityEntityOrganizationMember ActivityEntity = "organization_member"
+	ActivityEntityProjectMember      ActivityEntity = "project_member"
+	ActivityEntityTeamMember         ActivityEntity = "team_member"
+)
+
+// FieldChange holds the before and after value of a single field
+type FieldChange struct {
+	Field  string      `json:"field"`
+	Before interface{} `json:"before"`
+	After  interface{} `json:"after"`
+}
+
+// FieldChanges is stored as a JSON document
+type FieldChanges []FieldChange
+
+// Value implements driver.Valuer
+func (fc FieldChanges) Value() (driver.Value, error) {
+	if fc == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(fc)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (fc *FieldChanges) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*fc = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, fc)
+	case string:
+		return json.Unmarshal([]byte(v), fc)
+	default:
+		return errors.New("unsupported type for field changes")
+	}
+}
+
+// ActivityEvent is an immutable record of a change to a tracked entity.
+// Events are written by GORM hooks in the same transaction as the change.
+type ActivityEvent struct {
+	ID             uint           `json:"id" gorm:"primaryKey"`
+	OrganizationID uint           `json:"organization_id" gorm:"not null;index:idx_activity_org,priority:1"`
+	ProjectID      *uint          `json:"project_id" gorm:"index:idx_activity_project,priority:1"`
+	TaskID         *uint          `json:"task_id" gorm:"index:idx_activity_task,priority:1"`
+	EntityType     ActivityEntity `json:"entity_type" gorm:"type:varchar(40);not null"`
+	EntityID       uint           `json:"entity_id" gorm:"not null"`
+	Action         ActivityAction `json:"action" gorm:"type:varchar(20);not null"`
+	ActorID        *uint          `json:"actor_id"`
+	Changes        FieldChanges   `json:"changes" gorm:"type:jsonb"`
+	CreatedAt      time.Time      `json:"created_at" gorm:"not null"`
+}
+
+// recordActivity writes an activity event using the transaction of the
+// triggering write, attributing it to the actor stored in the context, and
+// announces it to the clients following the organization live
+func recordActivity(tx *gorm.DB, event *ActivityEvent) error {
+	event.ActorID = ActorFromContext(tx.Statement.Context)
+	event.CreatedAt = time.Now()
+	if err := tx.Session(&gorm.Session{NewDB: true}).Create(event).Error; err != nil {
+		return err
+	}
+	return publishRealtime(tx, &RealtimeEvent{
+		Type:           string(event.EntityType),
+		Action:         event.Action,
+		OrganizationID: event.OrganizationID,
+		ProjectID:      event.ProjectID,
+		TaskID:         event.TaskID,
+		EntityID:       event.EntityID,
+		ActorID:        event.ActorID,
+		Changes:        event.Changes,
+		At:             event.CreatedAt,
+	})
+}
+
+// projectOrganizationID looks up the organization owning a project
+func projectOrganizationID(tx *gorm.DB, projectID uint) (uint, error) {
+	var orgID uint
+	err := tx.Session(&gorm.Session{NewDB: true}).
+		Model(&Project{}).
+		Unscoped().
+		Select("organization_id").
+		Where("id = ?", projectID).
+		Scan(&orgID).Error
+	return orgID, err
+}
+
+var (
+	timeType  = reflect.TypeOf(time.Time{})
+	moneyType = reflect.TypeOf(money.Money{})
+)
+
+// diffFields compares the scalar columns of two values of the same struct type.
+// Relationships and embedded structs such as gorm.Model are ignored. A nil
+// before value is treated as the zero value, which is how creations are diffed.
+func diffFields(before, after interface{}) FieldChanges {
+	afterVal := reflect.Indirect(reflect.ValueOf(after))
+	beforeVal := reflect.New(afterVal.Type()).Elem()
+	if before != nil {
+		beforeVal = reflect.Indirect(reflect.ValueOf(before))
+	}
+
+	var changes FieldChanges
+	for i := 0; i < afterVal.NumField(); i++ {
+		field := afterVal.Type().Field(i)
+		if field.Anonymous || !field.IsExported() || !isTrackedType(field.Type) {
+			continue
+		}
+		// Fields hidden from the API are internal bookkeeping
+		if field.Tag.Get("json") == "-" {
+			continue
+		}
+
+		b := beforeVal.Field(i).Interface()
+		a := afterVal.Field(i).Interface()
+		if valuesEqual(b, a) {
+			continue
+		}
+
+		changes = append(changes, FieldChange{
+			Field:  fieldName(field),
+			Before: b,
+			After:  a,
+		})
+	}
+	return changes
+}
+
+// deletionChanges records the final values of a deleted entity as before values
+func deletionChanges(v interface{}) FieldChanges {
+	changes := diffFields(nil, v)
+	for i := range changes {
+		changes[i].Before, changes[i].After = changes[i].After, nil
+	}
+	return changes
+}
+
+// isTrackedType reports whether a field holds a column value rather than a relationship
+func isTrackedType(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == timeType || t == moneyType {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// valuesEqual compares two field values, treating times with the same instant as equal
+func valuesEqual(a, b interface{}) bool {
+	switch at := a.(type) {
+	case time.Time:
+		return at.Equal(b.(time.Time))
+	case *time.Time:
+		bt := b.(*time.Time)
+		if at == nil || bt == nil {
+			return at == nil && bt == nil
+		}
+		return at.Equal(*bt)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// fieldName returns the JSON name of a struct field, falling back to its column name
+func fieldName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" || name == "-" {
+		return schema.NamingStrategy{}.ColumnName("", field.Name)
+	}
+	return name
+}
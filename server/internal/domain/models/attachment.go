@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Common validation errors
+var (
+	ErrEmptyFileName          = errors.New("file name cannot be empty")
+	ErrMissingAttachmentOwner = errors.New("attachment must belong to exactly one task, comment or expense")
+	ErrInvalidFileSize        = errors.New("file size must be positive")
+)
+
+// BytesPerGB converts byte counts into the GB units of storage limits and usage
+const BytesPerGB = 1 << 30
+
+// AttachmentStatus represents the upload and scan state of an attachment
+type AttachmentStatus string
+
+const (
+	// AttachmentStatusPending means a presigned upload was issued but not completed
+	AttachmentStatusPending AttachmentStatus = "pending"
+	// AttachmentStatusAvailable means the file was stored and passed the virus scan
+	AttachmentStatusAvailable AttachmentStatus = "available"
+	// AttachmentStatusQuarantined means the virus scan found a threat and the file was removed
+	AttachmentStatusQuarantined AttachmentStatus = "quarantined"
+)
+
+// Attachment is a file attached to a task, a comment or, as a receipt, an expense. The content lives in
+// the storage backend under StorageKey.
+type Attachment struct {
+	gorm.Model
+	OrganizationID uint             `json:"organization_id" gorm:"not null;index"`
+	TaskID         *uint            `json:"task_id" gorm:"index"`
+	CommentID      *uint            `json:"comment_id" gorm:"index"`
+	ExpenseID      *uint            `json:"expense_id" gorm:"index"`
+	UploadedByID   uint             `json:"uploaded_by_id" gorm:"not null"`
+	UploadedBy     User             `json:"-" gorm:"foreignKey:UploadedByID"`
+	FileName       string           `json:"file_name" gorm:"not null"`
+	ContentType    string           `json:"content_type"`
+	Size           int64            `json:"size" gorm:"not null"`                       // in bytes
+	Checksum       string           `json:"checksum,omitempty" gorm:"type:varchar(64)"` // hex SHA-256
+	StorageKey     string           `json:"-" gorm:"not null;uniqueIndex"`
+	Status         AttachmentStatus `json:"status" gorm:"type:varchar(20);default:'pending'"`
+	ScanResult     string           `json:"scan_result,omitempty"`
+	ScannedAt      *time.Time       `json:"scanned_at"`
+}
+
+// Validate performs validation on the Attachment model
+func (a *Attachment) Validate() error {
+	if strings.TrimSpace(a.FileName) == "" {
+		return ErrEmptyFileName
+	}
+
+	if a.OrganizationID == 0 {
+		return ErrMissingOrganization
+	}
+
+	owners := 0
+	for _, owner := range []*uint{a.TaskID, a.CommentID, a.ExpenseID} {
+		if owner != nil {
+			owners++
+		}
+	}
+	if owners != 1 {
+		return ErrMissingAttachmentOwner
+	}
+
+	if a.Size <= 0 {
+		return ErrInvalidFileSize
+	}
+
+	return nil
+}
+
+// IsAvailable checks if the attachment can be downloaded
+func (a *Attachment) IsAvailable() bool {
+	return a.Status == AttachmentStatusAvailable
+}
+
+// BeforeCreate is a GORM hook that runs before creating a new attachment
+func (a *Attachment) BeforeCreate(tx *gorm.DB) error {
+	return a.Validate()
+}
+
+// BeforeUpdate is a GORM hook that runs before updating an attachment
+func (a *Attachment) BeforeUpdate(tx *gorm.DB) error {
+	return a.Validate()
+}
@@ -2,11 +2,25 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/tax"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Add-on validation errors
+var (
+    ErrEmptyAddOnName = errors.New("add-on name is required")
+    ErrInvalidAddOnPrice = errors.New("add-on price must be positive")
+)
+
+var ErrInvalidPaymentTransition = errors.New("invoice cannot move to this payment status")
+
+var ErrRefundNotRecorded = errors.New("refund is more than the invoice has refunded")
+
 // PaymentStatus represents the current status of a payment
 type PaymentStatus string
 
@@ -15,8 +29,32 @@ const (
     PaymentStatusSucceeded PaymentStatus = "succeeded"
     PaymentStatusFailed    PaymentStatus = "failed"
     PaymentStatusRefunded  PaymentStatus = "refunded"
+    PaymentStatusDisputed  PaymentStatus = "disputed" // the customer disputed a paid invoice with their bank
 )
 
+// paymentTransitions lists the statuses an invoice can move to from each
+// status. A failed invoice can be retried; a pending one can be settled by a
+// payment that completes later. A lost dispute takes the payment back, which
+// leaves the invoice failed, and a refund that fails after succeeding leaves
+// it paid again.
+var paymentTransitions = map[PaymentStatus][]PaymentStatus{
+    PaymentStatusPending:   {PaymentStatusSucceeded, PaymentStatusFailed},
+    PaymentStatusFailed:    {PaymentStatusPending, PaymentStatusSucceeded, PaymentStatusFailed},
+    PaymentStatusSucceeded: {PaymentStatusRefunded, PaymentStatusDisputed},
+    PaymentStatusDisputed:  {PaymentStatusSucceeded, PaymentStatusFailed},
+    PaymentStatusRefunded:  {PaymentStatusSucceeded},
+}
+
+// CanTransitionTo checks if an invoice in this status can move to another
+func (s PaymentStatus) CanTransitionTo(to PaymentStatus) bool {
+    for _, next := range paymentTransitions[s] {
+        if next == to {
+            return true
+        }
+    }
+    return false
+}
+
 // PaymentMethod represents the method used for payment
 type PaymentMethod string
 
@@ -26,6 +64,14 @@ const (
     PaymentMethodBank    PaymentMethod = "bank_transfer"
 )
 
+// TransactionKind tells charges and refunds apart
+type TransactionKind string
+
+const (
+    TransactionKindCharge TransactionKind = "charge"
+    TransactionKindRefund TransactionKind = "refund"
+)
+
 // Invoice represents a billing invoice
 type Invoice struct {
     gorm.Model
@@ -35,54 +81,220 @@ type Invoice struct {
     Subscription    Subscription  `json:"-" gorm:"foreignKey:SubscriptionID"`
     
     InvoiceNumber   string        `json:"invoice_number" gorm:"unique;not null"`
-    Amount          float64       `json:"amount" gorm:"not null"`
-    Currency        string        `json:"currency" gorm:"default:USD"`
+    SequenceNumber  int64         `json:"sequence_number" gorm:"not null;default:0"` // position in its numbering sequence
+    Amount          money.Money   `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+    IssuedAt        time.Time     `json:"issued_at"`
+    PeriodStart     time.Time     `json:"period_start"`
+    PeriodEnd       time.Time     `json:"period_end"`
     DueDate         time.Time     `json:"due_date"`
     PaidAt         *time.Time     `json:"paid_at"`
     Status         PaymentStatus  `json:"status" gorm:"type:varchar(20);default:'pending'"`
     
-    // Billing details
+    // Billing details, copied from the organization when the invoice is issued
     BillingName    string        `json:"billing_name"`
     BillingEmail   string        `json:"billing_email"`
     BillingAddress string        `json:"billing_address"`
+    BillingCountry string        `json:"billing_country" gorm:"type:varchar(2)"`
+    TaxID          string        `json:"tax_id"`
+    
+    // Tax, worked out when the invoice is issued. Subtotal is the charges
+    // before account credit; Amount adds the tax to it unless prices include
+    // tax, then takes off the credit.
+    Subtotal       money.Money   `json:"subtotal" gorm:"embedded;embeddedPrefix:subtotal_"`
+    TaxAmount      money.Money   `json:"tax_amount" gorm:"embedded;embeddedPrefix:tax_amount_"`
+    TaxCountry     string        `json:"tax_country" gorm:"type:varchar(2)"`
+    TaxInclusive   bool          `json:"tax_inclusive" gorm:"default:false"`
+    ReverseCharge  bool          `json:"reverse_charge" gorm:"default:false"`
+    TaxNote        string        `json:"tax_note"`
+    Taxes          []InvoiceTax  `json:"taxes" gorm:"foreignKey:InvoiceID"`
+    
+    // Refunds, added up as they succeed. NetAmount is what the customer has
+    // paid once refunds are taken off; it is not stored.
+    AmountRefunded money.Money   `json:"amount_refunded" gorm:"embedded;embeddedPrefix:amount_refunded_"`
+    NetAmount      money.Money   `json:"net_amount" gorm:"-"`
+    CreditNotes    []CreditNote  `json:"credit_notes,omitempty" gorm:"foreignKey:InvoiceID"`
     
     // Payment details
     PaymentMethod  PaymentMethod `json:"payment_method" gorm:"type:varchar(20)"`
-    PaymentID      string        `json:"payment_id"` // External payment reference
+    PaymentID      string        `json:"payment_id" gorm:"index"` // External payment reference
+    PaymentEventAt *time.Time    `json:"-"` // when the latest provider event about the payment happened
+    
+    // Dunning of an overdue invoice
+    DunningRetries int           `json:"dunning_retries" gorm:"not null;default:0"`
+    RemindersSent  int           `json:"reminders_sent" gorm:"not null;default:0"`
+    NextRetryAt    *time.Time    `json:"next_retry_at"`
     
     // Line items
     Items         []InvoiceItem `json:"items" gorm:"foreignKey:InvoiceID"`
 }
 
-// InvoiceItem represents a line item in an invoice
+// InvoiceItemKind describes what an invoice line charges for
+type InvoiceItemKind string
+
+const (
+    InvoiceItemKindPlan   InvoiceItemKind = "plan"
+    InvoiceItemKindSeats  InvoiceItemKind = "seats"
+    InvoiceItemKindAddOn  InvoiceItemKind = "add_on"
+    InvoiceItemKindCredit InvoiceItemKind = "credit" // account credit, granted or carried between invoices
+    InvoiceItemKindDiscount InvoiceItemKind = "discount" // a redeemed coupon's discount
+)
+
+// InvoiceSequence hands out gap-free invoice numbers for a numbering scope,
+// either all invoices or those of one organization. The row is locked while
+// an invoice is issued, so a number is only used once the invoice is stored.
+type InvoiceSequence struct {
+    Scope      string `gorm:"primaryKey;type:varchar(40)"`
+    LastNumber int64  `gorm:"not null;default:0"`
+}
+
+// AddOn is an extra that organizations can buy on top of their plan, charged
+// per unit every billing period
+type AddOn struct {
+    gorm.Model
+    Name        string      `json:"name" gorm:"not null"`
+    Description string      `json:"description"`
+    Price       money.Money `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+    ArchivedAt  *time.Time  `json:"archived_at"`
+}
+
+// OrganizationAddOn records how many units of an add-on an organization buys.
+// It belongs to the organization rather than a subscription, so it carries
+// over plan changes.
+type OrganizationAddOn struct {
+    ID             uint      `json:"id" gorm:"primaryKey"`
+    OrganizationID uint      `json:"organization_id" gorm:"not null;uniqueIndex:idx_organization_add_on,priority:1"`
+    AddOnID        uint      `json:"add_on_id" gorm:"not null;uniqueIndex:idx_organization_add_on,priority:2"`
+    AddOn          AddOn     `json:"add_on" gorm:"foreignKey:AddOnID"`
+    Quantity       int       `json:"quantity" gorm:"not null"`
+    CreatedAt      time.Time `json:"created_at"`
+    UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// InvoiceItem represents a line item in an invoice. Items such as prorated
+// charges and credits are created before the invoice they are billed on, and
+// have no InvoiceID until then.
 type InvoiceItem struct {
     gorm.Model
-    InvoiceID     uint    `json:"invoice_id" gorm:"not null"`
+    InvoiceID     *uint   `json:"invoice_id" gorm:"index"`
+    OrganizationID uint   `json:"organization_id" gorm:"not null;index"`
+    SubscriptionID *uint  `json:"subscription_id"`
+    Kind          InvoiceItemKind `json:"kind" gorm:"type:varchar(20);not null;default:'plan'"`
     Description   string  `json:"description" gorm:"not null"`
     Quantity      int     `json:"quantity" gorm:"not null"`
-    UnitPrice     float64 `json:"unit_price" gorm:"not null"`
-    Amount        float64 `json:"amount" gorm:"not null"`
+    UnitPrice     money.Money `json:"unit_price" gorm:"embedded;embeddedPrefix:unit_price_"`
+    Amount        money.Money `json:"amount" gorm:"embedded;embeddedPrefix:amount_"` // UnitPrice times Quantity
+
+    // The part of a billing period the item covers
+    PeriodStart   time.Time `json:"period_start"`
+    PeriodEnd     time.Time `json:"period_end"`
+    Proration     bool      `json:"proration" gorm:"default:false"`
+
+    // Tax on the line, included in Amount when the invoice's prices include tax
+    TaxRate       tax.Rate    `json:"tax_rate" gorm:"not null;default:0"`
+    TaxAmount     money.Money `json:"tax_amount" gorm:"embedded;embeddedPrefix:tax_amount_"`
+
+    // The coupon redemption a discount line gives
+    CouponRedemptionID *uint `json:"coupon_redemption_id,omitempty" gorm:"index"`
 }
 
-// PaymentTransaction represents a payment transaction
+// InvoiceTax is a line of an invoice's tax breakdown: the tax at one rate and
+// the amount before tax it applies to
+type InvoiceTax struct {
+    ID            uint        `json:"id" gorm:"primaryKey"`
+    InvoiceID     uint        `json:"invoice_id" gorm:"not null;index"`
+    Country       string      `json:"country" gorm:"type:varchar(2);not null"`
+    TaxName       string      `json:"tax_name" gorm:"not null"`
+    Rate          tax.Rate    `json:"rate" gorm:"not null;default:0"`
+    ReverseCharge bool        `json:"reverse_charge" gorm:"default:false"`
+    Taxable       money.Money `json:"taxable" gorm:"embedded;embeddedPrefix:taxable_"`
+    Amount        money.Money `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+    CreatedAt     time.Time   `json:"created_at"`
+}
+
+// PaymentTransaction represents a payment transaction. Every attempt to
+// charge or refund an invoice is recorded, before the provider is called.
 type PaymentTransaction struct {
     gorm.Model
-    InvoiceID     uint          `json:"invoice_id" gorm:"not null"`
+    InvoiceID     uint          `json:"invoice_id" gorm:"not null;index"`
     Invoice       Invoice       `json:"-" gorm:"foreignKey:InvoiceID"`
-    Amount        float64       `json:"amount" gorm:"not null"`
-    Currency      string        `json:"currency" gorm:"default:USD"`
+    Kind          TransactionKind `json:"kind" gorm:"type:varchar(20);not null;default:'charge'"`
+    Amount        money.Money   `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
     Status        PaymentStatus `json:"status" gorm:"type:varchar(20)"`
     PaymentMethod PaymentMethod `json:"payment_method" gorm:"type:varchar(20)"`
+    Reason        string        `json:"reason,omitempty"` // why a refund was made
     
     // Payment provider details
+    Provider      string        `json:"provider"`
     ProviderID    string        `json:"provider_id"` // Payment provider's transaction ID
-    ProviderFee   float64       `json:"provider_fee"`
+    ProviderFee   money.Money   `json:"provider_fee" gorm:"embedded;embeddedPrefix:provider_fee_"`
+    IdempotencyKey string       `json:"-" gorm:"uniqueIndex:idx_payment_transaction_idempotency,where:idempotency_key <> ''"`
     
     // Error handling
     ErrorCode     string        `json:"error_code"`
     ErrorMessage  string        `json:"error_message"`
 }
 
+// PaymentEventStatus is how far a provider event has been processed
+type PaymentEventStatus string
+
+const (
+    PaymentEventReceived  PaymentEventStatus = "received"
+    PaymentEventProcessed PaymentEventStatus = "processed"
+    PaymentEventIgnored   PaymentEventStatus = "ignored" // a duplicate, superseded or unused event
+    PaymentEventFailed    PaymentEventStatus = "failed"  // retried until it succeeds or runs out of attempts
+)
+
+// IsValid checks if the status is a known event status
+func (s PaymentEventStatus) IsValid() bool {
+    switch s {
+    case PaymentEventReceived, PaymentEventProcessed, PaymentEventIgnored, PaymentEventFailed:
+        return true
+    }
+    return false
+}
+
+// PaymentEvent is a webhook received from the payment provider. The raw
+// payload is kept for audit and so that the event can be processed again.
+type PaymentEvent struct {
+    ID           uint               `json:"id" gorm:"primaryKey"`
+    Provider     string             `json:"provider" gorm:"not null;uniqueIndex:idx_payment_event,priority:1"`
+    EventID      string             `json:"event_id" gorm:"not null;uniqueIndex:idx_payment_event,priority:2"`
+    Type         string             `json:"type" gorm:"not null"` // the provider's event type
+    PaymentID    string             `json:"payment_id" gorm:"index"`
+    InvoiceID    *uint              `json:"invoice_id" gorm:"index"`
+    OccurredAt   time.Time          `json:"occurred_at"`
+    Payload      string             `json:"payload" gorm:"type:text;not null"`
+    Status       PaymentEventStatus `json:"status" gorm:"type:varchar(20);not null;default:'received';index"`
+    Error        string             `json:"error"`
+    Attempts     int                `json:"attempts" gorm:"not null;default:0"`
+    ProcessedAt  *time.Time         `json:"processed_at"`
+    CreatedAt    time.Time          `json:"created_at"`
+    UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// PaymentProfile links an organization to its customer record at the payment
+// provider and the payment method invoices are charged to. Only the
+// provider's references and display details are stored, never card numbers.
+type PaymentProfile struct {
+    ID              uint          `json:"id" gorm:"primaryKey"`
+    OrganizationID  uint          `json:"organization_id" gorm:"not null;uniqueIndex"`
+    Provider        string        `json:"provider" gorm:"not null"`
+    CustomerID      string        `json:"-" gorm:"not null"`
+    PaymentMethodID string        `json:"-"`
+    PaymentMethod   PaymentMethod `json:"payment_method" gorm:"type:varchar(20)"`
+    Brand           string        `json:"brand"`
+    Last4           string        `json:"last4" gorm:"type:varchar(4)"`
+    ExpMonth        int           `json:"exp_month"`
+    ExpYear         int           `json:"exp_year"`
+    CreatedAt       time.Time     `json:"created_at"`
+    UpdatedAt       time.Time     `json:"updated_at"`
+}
+
+// HasPaymentMethod checks if invoices can be charged to the profile
+func (p *PaymentProfile) HasPaymentMethod() bool {
+    return p.PaymentMethodID != ""
+}
+
 // Validate performs validation on the Invoice model
 func (i *Invoice) Validate() error {
     if i.OrganizationID == 0 {
@@ -93,7 +305,11 @@ func (i *Invoice) Validate() error {
         return errors.New("subscription ID is required")
     }
 
-    if i.Amount < 0 {
+    if err := i.Amount.Validate(); err != nil {
+        return err
+    }
+
+    if i.Amount.IsNegative() {
         return errors.New("amount must be non-negative")
     }
 
@@ -109,18 +325,156 @@ func (i *Invoice) IsPaid() bool {
     return i.Status == PaymentStatusSucceeded && i.PaidAt != nil
 }
 
-// IsOverdue checks if the invoice is overdue
-func (i *Invoice) IsOverdue() bool {
-    return !i.IsPaid() && time.Now().After(i.DueDate)
+// payableStatuses are the statuses of invoices that have not been paid yet
+var payableStatuses = []PaymentStatus{PaymentStatusPending, PaymentStatusFailed}
+
+// IsPayable checks if the invoice still has an amount to collect
+func (i *Invoice) IsPayable() bool {
+    for _, status := range payableStatuses {
+        if i.Status == status {
+            return i.Amount.IsPositive()
+        }
+    }
+    return false
+}
+
+// IsOverdue checks if the invoice still has an amount to collect after its
+// due date. OverdueInvoices is the same rule for queries.
+func (i *Invoice) IsOverdue(now time.Time) bool {
+    return i.IsPayable() && i.DueDate.Before(now)
+}
+
+// OverdueInvoices selects the invoices IsOverdue reports as overdue
+func OverdueInvoices(now time.Time) clause.Expr {
+    return clause.Expr{
+        SQL:  "invoices.status IN ? AND invoices.amount_minor > 0 AND invoices.due_date < ?",
+        Vars: []interface{}{payableStatuses, now},
+    }
+}
+
+// TransitionTo moves the invoice to a new payment status, recording when it
+// was paid
+func (i *Invoice) TransitionTo(status PaymentStatus, at time.Time) error {
+    if !i.Status.CanTransitionTo(status) {
+        return fmt.Errorf("%w: %s to %s", ErrInvalidPaymentTransition, i.Status, status)
+    }
+    i.Status = status
+    switch status {
+    case PaymentStatusSucceeded:
+        if i.PaidAt == nil {
+            i.PaidAt = &at
+        }
+    case PaymentStatusFailed:
+        i.PaidAt = nil
+    }
+    return nil
+}
+
+// Refunded returns the amount refunded so far
+func (i *Invoice) Refunded() money.Money {
+    if i.AmountRefunded.Currency == "" {
+        return money.Zero(i.Amount.Currency)
+    }
+    return i.AmountRefunded
 }
 
-// CalculateTotal calculates the total amount for the invoice
-func (i *Invoice) CalculateTotal() float64 {
-    var total float64
+// AddRefund records a refund that succeeded. An invoice refunded in full
+// moves to refunded; a partial refund leaves it paid.
+func (i *Invoice) AddRefund(amount money.Money, at time.Time) error {
+    refunded, err := i.Refunded().Add(amount)
+    if err != nil {
+        return err
+    }
+    i.AmountRefunded = refunded
+    if err := i.setNetAmount(); err != nil {
+        return err
+    }
+    if i.Status == PaymentStatusSucceeded && !i.NetAmount.IsPositive() {
+        return i.TransitionTo(PaymentStatusRefunded, at)
+    }
+    return nil
+}
+
+// RemoveRefund takes back a refund that failed after it succeeded. An
+// invoice refunded in full is paid again.
+func (i *Invoice) RemoveRefund(amount money.Money, at time.Time) error {
+    refunded, err := i.Refunded().Sub(amount)
+    if err != nil {
+        return err
+    }
+    if refunded.IsNegative() {
+        return fmt.Errorf("%w: %s of %s", ErrRefundNotRecorded, amount, i.Refunded())
+    }
+    i.AmountRefunded = refunded
+    if err := i.setNetAmount(); err != nil {
+        return err
+    }
+    if i.Status == PaymentStatusRefunded && i.NetAmount.IsPositive() {
+        return i.TransitionTo(PaymentStatusSucceeded, at)
+    }
+    return nil
+}
+
+// setNetAmount works out what the customer has paid net of refunds
+func (i *Invoice) setNetAmount() error {
+    net, err := i.Amount.Sub(i.Refunded())
+    if err != nil {
+        return err
+    }
+    i.NetAmount = net
+    return nil
+}
+
+// CalculateTotal calculates the total amount for the invoice in its currency
+func (i *Invoice) CalculateTotal() (money.Money, error) {
+    total := money.Zero(i.Amount.Currency)
     for _, item := range i.Items {
-        total += item.Amount
+        var err error
+        if total, err = total.Add(item.Amount); err != nil {
+            return money.Money{}, err
+        }
     }
-    return total
+    return total, nil
+}
+
+// Label names the tax line on invoices, such as "VAT 19.00%"
+func (t *InvoiceTax) Label() string {
+    if t.ReverseCharge {
+        return t.TaxName + " reverse charge"
+    }
+    return fmt.Sprintf("%s %s%%", t.TaxName, t.Rate)
+}
+
+// CalculateAmount calculates the amount of a line item from its unit price and quantity
+func (item *InvoiceItem) CalculateAmount() (money.Money, error) {
+    return item.UnitPrice.Mul(int64(item.Quantity))
+}
+
+// Validate performs validation on the AddOn model
+func (a *AddOn) Validate() error {
+    if a.Name == "" {
+        return ErrEmptyAddOnName
+    }
+
+    if err := a.Price.Validate(); err != nil {
+        return err
+    }
+
+    if !a.Price.IsPositive() {
+        return ErrInvalidAddOnPrice
+    }
+
+    return nil
+}
+
+// IsArchived checks if the add-on has been withdrawn from the catalog
+func (a *AddOn) IsArchived() bool {
+    return a.ArchivedAt != nil
+}
+
+// BeforeSave is a GORM hook that validates an add-on before it is stored
+func (a *AddOn) BeforeSave(tx *gorm.DB) error {
+    return a.Validate()
 }
 
 // BeforeCreate is a GORM hook that runs before creating a new invoice
@@ -131,4 +485,30 @@ func (i *Invoice) BeforeCreate(tx *gorm.DB) error {
 // BeforeUpdate is a GORM hook that runs before updating an invoice
 func (i *Invoice) BeforeUpdate(tx *gorm.DB) error {
     return i.Validate()
+}
+
+// AfterCreate is a GORM hook that counts a discount line toward the cycles of
+// its coupon redemption
+func (item *InvoiceItem) AfterCreate(tx *gorm.DB) error {
+    if item.Kind != InvoiceItemKindDiscount || item.CouponRedemptionID == nil || item.InvoiceID == nil {
+        return nil
+    }
+    return consumeRedemption(tx, *item.CouponRedemptionID, time.Now())
+}
+
+// AfterSave is a GORM hook that lifts the dunning of the invoice's
+// subscription as soon as its last overdue invoice is settled
+func (i *Invoice) AfterSave(tx *gorm.DB) error {
+    if err := i.setNetAmount(); err != nil {
+        return err
+    }
+    if i.SubscriptionID == 0 || i.IsPayable() {
+        return nil
+    }
+    return liftDunning(tx, i.SubscriptionID, time.Now())
+}
+
+// AfterFind is a GORM hook that works out the invoice's net amount
+func (i *Invoice) AfterFind(tx *gorm.DB) error {
+    return i.setNetAmount()
 } 
\ No newline at end of file
@@ -0,0 +1,102 @@
+package models
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var overdueNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// overdueCases are invoices around overdueNow and whether they are overdue
+var overdueCases = []struct {
+	name    string
+	status  PaymentStatus
+	amount  int64
+	due     time.Time
+	overdue bool
+}{
+	{"pending past due", PaymentStatusPending, 4900, overdueNow.Add(-time.Hour), true},
+	{"failed past due", PaymentStatusFailed, 4900, overdueNow.AddDate(0, 0, -10), true},
+	{"pending due now", PaymentStatusPending, 4900, overdueNow, false},
+	{"pending not yet due", PaymentStatusPending, 4900, overdueNow.Add(time.Hour), false},
+	{"paid past due", PaymentStatusSucceeded, 4900, overdueNow.Add(-time.Hour), false},
+	{"refunded past due", PaymentStatusRefunded, 4900, overdueNow.Add(-time.Hour), false},
+	{"nothing to collect", PaymentStatusPending, 0, overdueNow.Add(-time.Hour), false},
+}
+
+func TestInvoiceIsOverdue(t *testing.T) {
+	for _, tt := range overdueCases {
+		t.Run(tt.name, func(t *testing.T) {
+			invoice := Invoice{Status: tt.status, Amount: money.New(tt.amount, "USD"), DueDate: tt.due}
+			if got := invoice.IsOverdue(overdueNow); got != tt.overdue {
+				t.Errorf("IsOverdue() = %v, want %v", got, tt.overdue)
+			}
+		})
+	}
+}
+
+// scratchDB connects to a new schema of the database in TEST_DATABASE_DSN,
+// dropped when the test ends, and skips the test when no database is set
+func scratchDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	random := make([]byte, 6)
+	rand.Read(random)
+	schema := "models_test_" + hex.EncodeToString(random)
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+	db, err := gorm.Open(postgres.Open(dsn+" search_path="+schema), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connecting to schema: %v", err)
+	}
+	return db
+}
+
+// TestOverdueInvoicesMatchesIsOverdue runs OverdueInvoices against a scratch
+// schema of the database in TEST_DATABASE_DSN, checking that it selects the
+// invoices IsOverdue reports
+func TestOverdueInvoicesMatchesIsOverdue(t *testing.T) {
+	db := scratchDB(t)
+	if err := db.Exec("CREATE TABLE invoices (id bigint PRIMARY KEY, status varchar(20), amount_minor bigint, due_date timestamptz)").Error; err != nil {
+		t.Fatalf("creating invoices: %v", err)
+	}
+
+	var want []int64
+	for i, tt := range overdueCases {
+		id := int64(i + 1)
+		if err := db.Exec("INSERT INTO invoices VALUES (?, ?, ?, ?)", id, tt.status, tt.amount, tt.due).Error; err != nil {
+			t.Fatalf("inserting %s: %v", tt.name, err)
+		}
+		invoice := Invoice{Status: tt.status, Amount: money.New(tt.amount, "USD"), DueDate: tt.due}
+		if invoice.IsOverdue(overdueNow) {
+			want = append(want, id)
+		}
+	}
+
+	var got []int64
+	if err := db.Table("invoices").Where(OverdueInvoices(overdueNow)).Order("id").Pluck("id", &got).Error; err != nil {
+		t.Fatalf("listing overdue invoices: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("OverdueInvoices selected %v, IsOverdue reports %v", got, want)
+	}
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"gorm.io/gorm"
+)
+
+// Common validation errors
+var (
+	ErrEmptyBudgetCategory = errors.New("budget category cannot be empty")
+	ErrInvalidBudgetAmount = errors.New("budget amount must be non-negative")
+	ErrInvalidHourlyRate   = errors.New("hourly rate must be non-negative")
+	ErrMissingRateUser     = errors.New("hourly rate user ID is required")
+)
+
+// BudgetCategoryLabor is the category labor cost from time entries is reported under
+const BudgetCategoryLabor = "labor"
+
+// BudgetLine is the amount planned for one spending category of a project.
+// The project's Budget is kept at the sum of its lines.
+type BudgetLine struct {
+	gorm.Model
+	ProjectID   uint        `json:"project_id" gorm:"not null;uniqueIndex:idx_budget_line_category,where:deleted_at IS NULL"`
+	Category    string      `json:"category" gorm:"type:varchar(50);not null;uniqueIndex:idx_budget_line_category,where:deleted_at IS NULL"`
+	Description string      `json:"description"`
+	Amount      money.Money `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+}
+
+// HourlyRate is the cost of an hour of a member's time from EffectiveFrom
+// until the member's next rate takes effect
+type HourlyRate struct {
+	ID             uint        `json:"id" gorm:"primaryKey"`
+	OrganizationID uint        `json:"organization_id" gorm:"not null;uniqueIndex:idx_hourly_rate_effective"`
+	UserID         uint        `json:"user_id" gorm:"not null;uniqueIndex:idx_hourly_rate_effective"`
+	Rate           money.Money `json:"rate" gorm:"embedded;embeddedPrefix:rate_"`
+	EffectiveFrom  time.Time   `json:"effective_from" gorm:"type:date;not null;uniqueIndex:idx_hourly_rate_effective"`
+	CreatedAt      time.Time   `json:"created_at"`
+}
+
+// BudgetAlert records a project's spending crossing a percentage of its
+// budget. Each threshold alerts at most once per project.
+type BudgetAlert struct {
+	ID        uint        `json:"id" gorm:"primaryKey"`
+	ProjectID uint        `json:"project_id" gorm:"not null;uniqueIndex:idx_budget_alert_threshold"`
+	Threshold int         `json:"threshold" gorm:"not null;uniqueIndex:idx_budget_alert_threshold"` // percent of the budget
+	Budget    money.Money `json:"budget" gorm:"embedded;embeddedPrefix:budget_"`
+	Spent     money.Money `json:"spent" gorm:"embedded;embeddedPrefix:spent_"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// NormalizeBudgetCategory returns the canonical form of a category name
+func NormalizeBudgetCategory(category string) string {
+	return strings.ToLower(strings.TrimSpace(category))
+}
+
+// Validate performs validation on the BudgetLine model
+func (l *BudgetLine) Validate() error {
+	if l.ProjectID == 0 {
+		return ErrMissingProject
+	}
+
+	if NormalizeBudgetCategory(l.Category) == "" {
+		return ErrEmptyBudgetCategory
+	}
+
+	if err := l.Amount.Validate(); err != nil {
+		return err
+	}
+
+	if l.Amount.IsNegative() {
+		return ErrInvalidBudgetAmount
+	}
+
+	return nil
+}
+
+// BeforeSave is a GORM hook that validates the line and normalizes its category
+func (l *BudgetLine) BeforeSave(tx *gorm.DB) error {
+	if err := l.Validate(); err != nil {
+		return err
+	}
+	l.Category = NormalizeBudgetCategory(l.Category)
+	return nil
+}
+
+// AfterSave is a GORM hook that keeps the project's budget in sync
+func (l *BudgetLine) AfterSave(tx *gorm.DB) error {
+	return syncProjectBudget(tx, l.ProjectID)
+}
+
+// AfterDelete is a GORM hook that keeps the project's budget in sync
+func (l *BudgetLine) AfterDelete(tx *gorm.DB) error {
+	return syncProjectBudget(tx, l.ProjectID)
+}
+
+// syncProjectBudget sets a project's budget to the total of its budget lines,
+// which all share the currency of the budget
+func syncProjectBudget(tx *gorm.DB, projectID uint) error {
+	return tx.Session(&gorm.Session{NewDB: true}).Exec(
+		`UPDATE projects SET
+			budget_minor = (
+				SELECT COALESCE(SUM(amount_minor), 0) FROM budget_lines
+				WHERE project_id = @project AND deleted_at IS NULL
+			),
+			budget_currency = COALESCE((
+				SELECT amount_currency FROM budget_lines
+				WHERE project_id = @project AND deleted_at IS NULL
+				LIMIT 1
+			), budget_currency)
+		WHERE id = @project`,
+		map[string]interface{}{"project": projectID},
+	).Error
+}
+
+// Validate performs validation on the HourlyRate model
+func (r *HourlyRate) Validate() error {
+	if r.OrganizationID == 0 {
+		return ErrMissingOrganization
+	}
+
+	if r.UserID == 0 {
+		return ErrMissingRateUser
+	}
+
+	if err := r.Rate.Validate(); err != nil {
+		return err
+	}
+
+	if r.Rate.IsNegative() {
+		return ErrInvalidHourlyRate
+	}
+
+	return nil
+}
+
+// BeforeSave is a GORM hook that runs before creating or updating a rate
+func (r *HourlyRate) BeforeSave(tx *gorm.DB) error {
+	return r.Validate()
+}
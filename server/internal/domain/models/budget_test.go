@@ -0,0 +1,93 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+func TestBudgetLineBeforeSave(t *testing.T) {
+	tests := []struct {
+		name     string
+		line     BudgetLine
+		err      error
+		category string
+	}{
+		{"valid", BudgetLine{ProjectID: 1, Category: "  Travel ", Amount: money.New(50000, "USD")}, nil, "travel"},
+		{"zero amount", BudgetLine{ProjectID: 1, Category: "travel", Amount: money.New(0, "USD")}, nil, "travel"},
+		{"missing project", BudgetLine{Category: "travel", Amount: money.New(50000, "USD")}, ErrMissingProject, ""},
+		{"blank category", BudgetLine{ProjectID: 1, Category: "  ", Amount: money.New(50000, "USD")}, ErrEmptyBudgetCategory, ""},
+		{"negative amount", BudgetLine{ProjectID: 1, Category: "travel", Amount: money.New(-1, "USD")}, ErrInvalidBudgetAmount, ""},
+		{"invalid currency", BudgetLine{ProjectID: 1, Category: "travel", Amount: money.New(50000, "XX")}, money.ErrInvalidCurrency, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			line := tt.line
+			if err := line.BeforeSave(nil); !errors.Is(err, tt.err) {
+				t.Fatalf("BeforeSave() error = %v, want %v", err, tt.err)
+			}
+			if tt.err == nil && line.Category != tt.category {
+				t.Errorf("Category = %q, want %q", line.Category, tt.category)
+			}
+		})
+	}
+}
+
+func TestHourlyRateValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		rate HourlyRate
+		err  error
+	}{
+		{"valid", HourlyRate{OrganizationID: 1, UserID: 1, Rate: money.New(7500, "USD")}, nil},
+		{"unpaid", HourlyRate{OrganizationID: 1, UserID: 1, Rate: money.New(0, "USD")}, nil},
+		{"missing organization", HourlyRate{UserID: 1, Rate: money.New(7500, "USD")}, ErrMissingOrganization},
+		{"missing user", HourlyRate{OrganizationID: 1, Rate: money.New(7500, "USD")}, ErrMissingRateUser},
+		{"negative", HourlyRate{OrganizationID: 1, UserID: 1, Rate: money.New(-7500, "USD")}, ErrInvalidHourlyRate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rate.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestExpenseValidate(t *testing.T) {
+	valid := Expense{
+		ProjectID:   1,
+		Category:    "travel",
+		Description: "Train to the client",
+		Amount:      money.New(4200, "EUR"),
+		IncurredOn:  time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC),
+	}
+	with := func(change func(*Expense)) Expense {
+		expense := valid
+		change(&expense)
+		return expense
+	}
+
+	tests := []struct {
+		name    string
+		expense Expense
+		err     error
+	}{
+		{"valid", valid, nil},
+		{"missing project", with(func(e *Expense) { e.ProjectID = 0 }), ErrMissingProject},
+		{"blank category", with(func(e *Expense) { e.Category = " " }), ErrEmptyBudgetCategory},
+		{"blank description", with(func(e *Expense) { e.Description = "\t" }), ErrEmptyExpenseDescription},
+		{"zero amount", with(func(e *Expense) { e.Amount = money.New(0, "EUR") }), ErrInvalidExpenseAmount},
+		{"refund", with(func(e *Expense) { e.Amount = money.New(-4200, "EUR") }), ErrInvalidExpenseAmount},
+		{"missing date", with(func(e *Expense) { e.IncurredOn = time.Time{} }), ErrMissingExpenseDate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.expense.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 
 	"gorm.io/gorm"
@@ -25,6 +26,13 @@ type Comment struct {
     ParentID *uint  `json:"parent_id"`
     Parent   *Comment `json:"-" gorm:"foreignKey:ParentID"`
     Replies  []Comment `json:"replies" gorm:"foreignKey:ParentID"`
+    Attachments []Attachment `json:"attachments,omitempty" gorm:"foreignKey:CommentID"`
+
+    // Text search configuration the content is indexed with
+    SearchLanguage string `json:"-" gorm:"type:varchar(32);default:'simple'"`
+
+    // original is a copy of the comment as it was read from the database
+    original *Comment
 }
 
 // Validate performs validation on the Comment model
@@ -51,10 +59,178 @@ func (c *Comment) IsReply() bool {
 
 // BeforeCreate is a GORM hook that runs before creating a new comment
 func (c *Comment) BeforeCreate(tx *gorm.DB) error {
+    if c.SearchLanguage == "" {
+        c.SearchLanguage = actorSearchConfig(tx)
+    }
     return c.Validate()
 }
 
 // BeforeUpdate is a GORM hook that runs before updating a comment
 func (c *Comment) BeforeUpdate(tx *gorm.DB) error {
     return c.Validate()
-} 
\ No newline at end of file
+} 
+
+// AfterFind is a GORM hook that keeps a copy of the stored comment so that
+// edits can be recorded on the next update
+func (c *Comment) AfterFind(tx *gorm.DB) error {
+    c.remember()
+    return nil
+}
+
+// AfterCreate is a GORM hook that records the creation of a comment and has
+// its author watch the task
+func (c *Comment) AfterCreate(tx *gorm.DB) error {
+    if err := c.recordActivity(tx, ActivityActionCreated, diffFields(nil, c)); err != nil {
+        return err
+    }
+    if err := watchTask(tx, c.TaskID, c.UserID); err != nil {
+        return err
+    }
+    if err := c.notify(tx); err != nil {
+        return err
+    }
+    c.remember()
+    return nil
+}
+
+// AfterUpdate is a GORM hook that records edits in the same transaction as the update
+func (c *Comment) AfterUpdate(tx *gorm.DB) error {
+    if c.original == nil {
+        return nil
+    }
+    if changes := diffFields(c.original, c); len(changes) > 0 {
+        if err := c.recordActivity(tx, ActivityActionUpdated, changes); err != nil {
+            return err
+        }
+        if c.original.Content != c.Content {
+            if err := c.notify(tx); err != nil {
+                return err
+            }
+        }
+    }
+    c.remember()
+    return nil
+}
+
+// AfterDelete is a GORM hook that records the deletion of a comment
+func (c *Comment) AfterDelete(tx *gorm.DB) error {
+    if c.ID == 0 {
+        return nil
+    }
+    return c.recordActivity(tx, ActivityActionDeleted, deletionChanges(c))
+}
+
+// remember stores a copy of the comment's current column values
+func (c *Comment) remember() {
+    original := *c
+    original.original = nil
+    c.original = &original
+}
+
+// notify tells the author of the comment replied to, and the members of the
+// organization mentioned in the comment. Each user is only told once about
+// a comment, so editing it only notifies users mentioned for the first time.
+func (c *Comment) notify(tx *gorm.DB) error {
+    var task Task
+    err := tx.Session(&gorm.Session{NewDB: true}).
+        Unscoped().
+        Select("id", "title", "project_id").
+        Where("id = ?", c.TaskID).
+        Take(&task).Error
+    if err != nil {
+        return err
+    }
+    orgID, err := projectOrganizationID(tx, task.ProjectID)
+    if err != nil {
+        return err
+    }
+
+    notification := func(userID uint, t NotificationType, message string) *Notification {
+        projectID, taskID, commentID := task.ProjectID, task.ID, c.ID
+        return &Notification{
+            UserID:         userID,
+            Type:           t,
+            OrganizationID: &orgID,
+            ProjectID:      &projectID,
+            TaskID:         &taskID,
+            CommentID:      &commentID,
+            Message:        message,
+            DedupeKey:      fmt.Sprintf("comment:%d:user:%d", c.ID, userID),
+        }
+    }
+
+    mentioned := Mentions(c.Content)
+    if len(mentioned) > 0 {
+        var members []uint
+        err := tx.Session(&gorm.Session{NewDB: true}).
+            Model(&OrganizationUser{}).
+            Where("organization_id = ? AND user_id IN ?", orgID, mentioned).
+            Pluck("user_id", &members).Error
+        if err != nil {
+            return err
+        }
+        message, err := notificationMessage(tx, "mentioned you on \"%s\"", "You were mentioned on \"%s\"", task.Title)
+        if err != nil {
+            return err
+        }
+        for _, userID := range members {
+            if err := Notify(tx, notification(userID, NotificationTypeMentioned, message)); err != nil {
+                return err
+            }
+        }
+    }
+
+    if c.ParentID != nil {
+        var authorID uint
+        err := tx.Session(&gorm.Session{NewDB: true}).
+            Model(&Comment{}).
+            Unscoped().
+            Select("user_id").
+            Where("id = ?", *c.ParentID).
+            Scan(&authorID).Error
+        if err != nil {
+            return err
+        }
+        if authorID != 0 {
+            message, err := notificationMessage(tx, "replied to your comment on \"%s\"", "Your comment on \"%s\" has a reply", task.Title)
+            if err != nil {
+                return err
+            }
+            // A reply that mentions the author has told them already
+            if err := Notify(tx, notification(authorID, NotificationTypeCommentReply, message)); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
+// recordActivity stores an activity event for the comment on its task's feed
+func (c *Comment) recordActivity(tx *gorm.DB, action ActivityAction, changes FieldChanges) error {
+    var projectID uint
+    err := tx.Session(&gorm.Session{NewDB: true}).
+        Model(&Task{}).
+        Unscoped().
+        Select("project_id").
+        Where("id = ?", c.TaskID).
+        Scan(&projectID).Error
+    if err != nil {
+        return err
+    }
+
+    orgID, err := projectOrganizationID(tx, projectID)
+    if err != nil {
+        return err
+    }
+
+    taskID := c.TaskID
+    return recordActivity(tx, &ActivityEvent{
+        OrganizationID: orgID,
+        ProjectID:      &projectID,
+        TaskID:         &taskID,
+        EntityType:     ActivityEntityComment,
+        EntityID:       c.ID,
+        Action:         action,
+        Changes:        changes,
+    })
+}
@@ -0,0 +1,40 @@
+package models
+
+import "context"
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx carrying the ID of the user performing a change.
+// GORM hooks read it back to attribute history records.
+func WithActor(ctx context.Context, userID uint) context.Context {
+	return context.WithValue(ctx, actorKey{}, userID)
+}
+
+// ActorFromContext returns the ID of the acting user stored in ctx, if any
+func ActorFromContext(ctx context.Context) *uint {
+	if ctx == nil {
+		return nil
+	}
+	userID, ok := ctx.Value(actorKey{}).(uint)
+	if !ok || userID == 0 {
+		return nil
+	}
+	return &userID
+}
+
+type writeKey struct{}
+
+// WithWrite returns a copy of ctx marking it as a request that changes data,
+// which organizations that are read-only may not make
+func WithWrite(ctx context.Context) context.Context {
+	return context.WithValue(ctx, writeKey{}, true)
+}
+
+// IsWrite reports whether ctx belongs to a request that changes data
+func IsWrite(ctx context.Context) bool {
+	if ctx == nil {
+		return false
+	}
+	write, _ := ctx.Value(writeKey{}).(bool)
+	return write
+}
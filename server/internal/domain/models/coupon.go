@@ -0,0 +1,152 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"gorm.io/gorm"
+)
+
+// Coupon validation errors
+var (
+	ErrInvalidCouponCode     = errors.New("coupon code must be 3 to 40 letters, digits, dashes or underscores")
+	ErrInvalidDiscountType   = errors.New("discount type must be percent or fixed")
+	ErrInvalidPercentOff     = errors.New("percent off must be between 1 and 100")
+	ErrInvalidAmountOff      = errors.New("amount off must be positive")
+	ErrInvalidCouponDuration = errors.New("duration must be once, repeating or forever")
+	ErrInvalidDurationCycles = errors.New("a repeating coupon must last at least one billing cycle, and only a repeating one can set cycles")
+	ErrInvalidMaxRedemptions = errors.New("max redemptions cannot be negative")
+)
+
+var couponCodePattern = regexp.MustCompile(`^[A-Z0-9_-]{3,40}$`)
+
+// DiscountType is how a coupon reduces an invoice
+type DiscountType string
+
+const (
+	DiscountTypePercent DiscountType = "percent"
+	DiscountTypeFixed   DiscountType = "fixed"
+)
+
+// CouponDuration is for how many invoices a redeemed coupon applies
+type CouponDuration string
+
+const (
+	CouponDurationOnce      CouponDuration = "once"
+	CouponDurationRepeating CouponDuration = "repeating" // for DurationCycles invoices
+	CouponDurationForever   CouponDuration = "forever"
+)
+
+// Coupon is a discount code organizations redeem. The discount applies to the
+// charges of their next invoices and shows on them as a line of its own.
+type Coupon struct {
+	gorm.Model
+	Code           string         `json:"code" gorm:"type:varchar(40);not null;uniqueIndex"`
+	Name           string         `json:"name"`
+	DiscountType   DiscountType   `json:"discount_type" gorm:"type:varchar(20);not null"`
+	PercentOff     int            `json:"percent_off"`
+	AmountOff      money.Money    `json:"amount_off" gorm:"embedded;embeddedPrefix:amount_off_"`
+	Duration       CouponDuration `json:"duration" gorm:"type:varchar(20);not null"`
+	DurationCycles int            `json:"duration_cycles" gorm:"not null;default:0"`
+	MaxRedemptions int            `json:"max_redemptions" gorm:"not null;default:0"` // 0 means no limit
+	TimesRedeemed  int            `json:"times_redeemed" gorm:"not null;default:0"`
+	ExpiresAt      *time.Time     `json:"expires_at"` // last time the coupon can be redeemed
+	ArchivedAt     *time.Time     `json:"archived_at"`
+}
+
+// CouponRedemption records an organization redeeming a coupon. The discount
+// applies until it has been given on Cycles invoices, or forever when Cycles
+// is 0. An organization has at most one discount running at a time.
+type CouponRedemption struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	CouponID       uint       `json:"coupon_id" gorm:"not null;uniqueIndex:idx_coupon_redemption,priority:1"`
+	Coupon         Coupon     `json:"coupon" gorm:"foreignKey:CouponID"`
+	OrganizationID uint       `json:"organization_id" gorm:"not null;uniqueIndex:idx_coupon_redemption,priority:2;uniqueIndex:idx_coupon_redemption_active,where:ended_at IS NULL"`
+	RedeemedByID   uint       `json:"redeemed_by_id" gorm:"not null"`
+	Cycles         int        `json:"cycles" gorm:"not null;default:0"`
+	CyclesApplied  int        `json:"cycles_applied" gorm:"not null;default:0"`
+	EndedAt        *time.Time `json:"ended_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// Validate performs validation on the Coupon model
+func (c *Coupon) Validate() error {
+	if !couponCodePattern.MatchString(c.Code) {
+		return ErrInvalidCouponCode
+	}
+
+	switch c.DiscountType {
+	case DiscountTypePercent:
+		if c.PercentOff < 1 || c.PercentOff > 100 {
+			return ErrInvalidPercentOff
+		}
+	case DiscountTypeFixed:
+		if err := c.AmountOff.Validate(); err != nil {
+			return err
+		}
+		if !c.AmountOff.IsPositive() {
+			return ErrInvalidAmountOff
+		}
+	default:
+		return ErrInvalidDiscountType
+	}
+
+	switch c.Duration {
+	case CouponDurationRepeating:
+		if c.DurationCycles < 1 {
+			return ErrInvalidDurationCycles
+		}
+	case CouponDurationOnce, CouponDurationForever:
+		if c.DurationCycles != 0 {
+			return ErrInvalidDurationCycles
+		}
+	default:
+		return ErrInvalidCouponDuration
+	}
+
+	if c.MaxRedemptions < 0 {
+		return ErrInvalidMaxRedemptions
+	}
+	return nil
+}
+
+// Cycles returns on how many invoices the coupon's discount is given, where
+// 0 means every invoice
+func (c *Coupon) Cycles() int {
+	switch c.Duration {
+	case CouponDurationOnce:
+		return 1
+	case CouponDurationRepeating:
+		return c.DurationCycles
+	}
+	return 0
+}
+
+// IsRedeemable checks if the coupon can still be redeemed at the given time
+func (c *Coupon) IsRedeemable(now time.Time) bool {
+	return c.ArchivedAt == nil &&
+		(c.ExpiresAt == nil || !now.After(*c.ExpiresAt)) &&
+		(c.MaxRedemptions == 0 || c.TimesRedeemed < c.MaxRedemptions)
+}
+
+// BeforeSave is a GORM hook that validates a coupon before it is stored
+func (c *Coupon) BeforeSave(tx *gorm.DB) error {
+	return c.Validate()
+}
+
+// IsActive checks if the redemption's discount still applies
+func (r *CouponRedemption) IsActive() bool {
+	return r.EndedAt == nil
+}
+
+// consumeRedemption counts an invoice the redemption's discount was given on,
+// ending the redemption when it has run its cycles
+func consumeRedemption(tx *gorm.DB, redemptionID uint, now time.Time) error {
+	return tx.Exec(`
+		UPDATE coupon_redemptions
+		SET cycles_applied = cycles_applied + 1,
+		    ended_at = CASE WHEN cycles > 0 AND cycles_applied + 1 >= cycles THEN ? ELSE ended_at END
+		WHERE id = ?`, now, redemptionID).Error
+}
@@ -0,0 +1,141 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+func TestCouponValidate(t *testing.T) {
+	percent := Coupon{Code: "SPRING-25", DiscountType: DiscountTypePercent, PercentOff: 25, Duration: CouponDurationOnce}
+	with := func(change func(*Coupon)) Coupon {
+		coupon := percent
+		change(&coupon)
+		return coupon
+	}
+
+	tests := []struct {
+		name   string
+		coupon Coupon
+		err    error
+	}{
+		{"percent once", percent, nil},
+		{"fixed forever", with(func(c *Coupon) {
+			c.DiscountType, c.PercentOff, c.AmountOff, c.Duration = DiscountTypeFixed, 0, money.New(1000, "USD"), CouponDurationForever
+		}), nil},
+		{"repeating", with(func(c *Coupon) { c.Duration, c.DurationCycles = CouponDurationRepeating, 3 }), nil},
+		{"limited", with(func(c *Coupon) { c.MaxRedemptions = 100 }), nil},
+		{"lowercase code", with(func(c *Coupon) { c.Code = "spring-25" }), ErrInvalidCouponCode},
+		{"short code", with(func(c *Coupon) { c.Code = "AB" }), ErrInvalidCouponCode},
+		{"code with spaces", with(func(c *Coupon) { c.Code = "SPRING 25" }), ErrInvalidCouponCode},
+		{"unknown discount type", with(func(c *Coupon) { c.DiscountType = "bogo" }), ErrInvalidDiscountType},
+		{"no percent off", with(func(c *Coupon) { c.PercentOff = 0 }), ErrInvalidPercentOff},
+		{"over 100 percent off", with(func(c *Coupon) { c.PercentOff = 101 }), ErrInvalidPercentOff},
+		{"no amount off", with(func(c *Coupon) { c.DiscountType, c.AmountOff = DiscountTypeFixed, money.Zero("USD") }), ErrInvalidAmountOff},
+		{"amount off without a currency", with(func(c *Coupon) { c.DiscountType, c.AmountOff = DiscountTypeFixed, money.New(1000, "") }), money.ErrInvalidCurrency},
+		{"unknown duration", with(func(c *Coupon) { c.Duration = "weekly" }), ErrInvalidCouponDuration},
+		{"repeating without cycles", with(func(c *Coupon) { c.Duration = CouponDurationRepeating }), ErrInvalidDurationCycles},
+		{"once with cycles", with(func(c *Coupon) { c.DurationCycles = 2 }), ErrInvalidDurationCycles},
+		{"forever with cycles", with(func(c *Coupon) { c.Duration, c.DurationCycles = CouponDurationForever, 2 }), ErrInvalidDurationCycles},
+		{"negative max redemptions", with(func(c *Coupon) { c.MaxRedemptions = -1 }), ErrInvalidMaxRedemptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.coupon.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCouponCycles(t *testing.T) {
+	tests := []struct {
+		duration CouponDuration
+		cycles   int
+		want     int
+	}{
+		{CouponDurationOnce, 0, 1},
+		{CouponDurationRepeating, 3, 3},
+		{CouponDurationForever, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.duration), func(t *testing.T) {
+			coupon := Coupon{Duration: tt.duration, DurationCycles: tt.cycles}
+			if got := coupon.Cycles(); got != tt.want {
+				t.Errorf("Cycles() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCouponIsRedeemable(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(time.Hour), now.Add(-time.Hour)
+
+	tests := []struct {
+		name       string
+		coupon     Coupon
+		redeemable bool
+	}{
+		{"open", Coupon{}, true},
+		{"expires later", Coupon{ExpiresAt: &later}, true},
+		{"expires now", Coupon{ExpiresAt: &now}, true},
+		{"expired", Coupon{ExpiresAt: &earlier}, false},
+		{"archived", Coupon{ArchivedAt: &earlier}, false},
+		{"redemptions left", Coupon{MaxRedemptions: 10, TimesRedeemed: 9}, true},
+		{"redemptions used up", Coupon{MaxRedemptions: 10, TimesRedeemed: 10}, false},
+		{"unlimited redemptions", Coupon{TimesRedeemed: 1000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.coupon.IsRedeemable(now); got != tt.redeemable {
+				t.Errorf("IsRedeemable() = %v, want %v", got, tt.redeemable)
+			}
+		})
+	}
+}
+
+// TestConsumeRedemption runs consumeRedemption against a scratch schema of
+// the database in TEST_DATABASE_DSN
+func TestConsumeRedemption(t *testing.T) {
+	db := scratchDB(t)
+	if err := db.Exec("CREATE TABLE coupon_redemptions (id bigint PRIMARY KEY, cycles bigint, cycles_applied bigint, ended_at timestamptz)").Error; err != nil {
+		t.Fatalf("creating coupon_redemptions: %v", err)
+	}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		cycles   int
+		invoices int
+		ended    bool
+	}{
+		{"once", 1, 1, true},
+		{"repeating, cycles left", 3, 2, false},
+		{"repeating, last cycle", 3, 3, true},
+		{"forever", 0, 12, false},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uint(i + 1)
+			db.Exec("INSERT INTO coupon_redemptions VALUES (?, ?, 0, NULL)", id, tt.cycles)
+			for n := 0; n < tt.invoices; n++ {
+				if err := consumeRedemption(db, id, now); err != nil {
+					t.Fatalf("consumeRedemption() error = %v", err)
+				}
+			}
+			var redemption CouponRedemption
+			if err := db.First(&redemption, id).Error; err != nil {
+				t.Fatalf("loading redemption: %v", err)
+			}
+			if redemption.CyclesApplied != tt.invoices {
+				t.Errorf("CyclesApplied = %d, want %d", redemption.CyclesApplied, tt.invoices)
+			}
+			if redemption.IsActive() == tt.ended {
+				t.Errorf("IsActive() = %v after %d invoices, want %v", redemption.IsActive(), tt.invoices, !tt.ended)
+			}
+		})
+	}
+}
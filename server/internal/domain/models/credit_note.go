@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"gorm.io/gorm"
+)
+
+// CreditNote is the document issued for a refund. It reverses part or all of
+// the invoice it refers to, and is numbered in a sequence of its own. The
+// customer's billing details and tax treatment are copied from the invoice.
+// A credit note whose refund is reversed is voided rather than deleted, so
+// that its number stays accounted for.
+type CreditNote struct {
+	gorm.Model
+	OrganizationID   uint       `json:"organization_id" gorm:"not null;index"`
+	InvoiceID        uint       `json:"invoice_id" gorm:"not null;index"`
+	Invoice          Invoice    `json:"-" gorm:"foreignKey:InvoiceID"`
+	InvoiceNumber    string     `json:"invoice_number" gorm:"not null"`
+	TransactionID    uint       `json:"transaction_id" gorm:"not null;uniqueIndex"` // the refund it records
+	CreditNoteNumber string     `json:"credit_note_number" gorm:"unique;not null"`
+	SequenceNumber   int64      `json:"sequence_number" gorm:"not null;default:0"`
+	Reason           string     `json:"reason"`
+	IssuedAt         time.Time  `json:"issued_at"`
+	VoidedAt         *time.Time `json:"voided_at"`
+	VoidReason       string     `json:"void_reason,omitempty"`
+
+	// What is credited: Amount is the refund, of which TaxAmount is the tax
+	// it gives back and Subtotal the rest
+	Subtotal  money.Money `json:"subtotal" gorm:"embedded;embeddedPrefix:subtotal_"`
+	TaxAmount money.Money `json:"tax_amount" gorm:"embedded;embeddedPrefix:tax_amount_"`
+	Amount    money.Money `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+
+	BillingName    string `json:"billing_name"`
+	BillingEmail   string `json:"billing_email"`
+	BillingAddress string `json:"billing_address"`
+	BillingCountry string `json:"billing_country" gorm:"type:varchar(2)"`
+	TaxID          string `json:"tax_id"`
+	TaxNote        string `json:"tax_note"`
+}
+
+// IsVoid checks if the credit note has been voided
+func (n *CreditNote) IsVoid() bool {
+	return n.VoidedAt != nil
+}
+
+// Void voids the credit note, reporting false if it was void already
+func (n *CreditNote) Void(at time.Time, reason string) bool {
+	if n.IsVoid() {
+		return false
+	}
+	n.VoidedAt = &at
+	n.VoidReason = reason
+	return true
+}
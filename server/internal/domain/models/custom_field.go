@@ -0,0 +1,309 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Common validation errors
+var (
+	ErrEmptyFieldName      = errors.New("custom field name cannot be empty")
+	ErrInvalidFieldKey     = errors.New("custom field key must be lowercase letters, digits and underscores")
+	ErrInvalidFieldType    = errors.New("invalid custom field type")
+	ErrMissingFieldOptions = errors.New("select fields need at least one option")
+	ErrInvalidCurrency     = errors.New("currency must be a 3-letter ISO 4217 code")
+	ErrInvalidFieldValue   = errors.New("invalid value for custom field")
+	ErrUnknownFieldOption  = errors.New("value is not one of the field's options")
+	ErrFieldValueRequired  = errors.New("custom field value is required")
+)
+
+var (
+	fieldKeyRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,62}$`)
+	currencyRegex = regexp.MustCompile(`^[A-Z]{3}$`)
+)
+
+// CustomFieldType is the data type of a custom field
+type CustomFieldType string
+
+const (
+	CustomFieldTypeText         CustomFieldType = "text"
+	CustomFieldTypeNumber       CustomFieldType = "number"
+	CustomFieldTypeDate         CustomFieldType = "date"
+	CustomFieldTypeSingleSelect CustomFieldType = "single_select"
+	CustomFieldTypeMultiSelect  CustomFieldType = "multi_select"
+	CustomFieldTypeUser         CustomFieldType = "user"
+	CustomFieldTypeCurrency     CustomFieldType = "currency"
+)
+
+// StringList is a list of strings stored as a JSON array
+type StringList []string
+
+// Value implements driver.Valuer
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	data, err := json.Marshal(l)
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// Scan implements sql.Scanner
+func (l *StringList) Scan(value interface{}) error {
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		return json.Unmarshal(v, l)
+	case string:
+		return json.Unmarshal([]byte(v), l)
+	default:
+		return errors.New("unsupported type for string list")
+	}
+}
+
+// Contains checks if the list contains the given string
+func (l StringList) Contains(s string) bool {
+	for _, item := range l {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// CustomFieldDefinition describes a custom field available on a project's tasks
+type CustomFieldDefinition struct {
+	gorm.Model
+	ProjectID uint            `json:"project_id" gorm:"not null;uniqueIndex:idx_custom_field_project_key"`
+	Project   Project         `json:"-" gorm:"foreignKey:ProjectID"`
+	Name      string          `json:"name" gorm:"not null"`
+	Key       string          `json:"key" gorm:"not null;uniqueIndex:idx_custom_field_project_key"`
+	Type      CustomFieldType `json:"type" gorm:"type:varchar(20);not null"`
+	Options   StringList      `json:"options" gorm:"type:jsonb"`
+	Currency  string          `json:"currency,omitempty" gorm:"type:varchar(3)"`
+	Required  bool            `json:"required" gorm:"default:false"`
+	Position  int             `json:"position" gorm:"default:0"`
+}
+
+// CustomFieldValue holds a task's value for a custom field. Only the column
+// matching the field type is set, so each type can be indexed and sorted natively.
+type CustomFieldValue struct {
+	ID           uint                  `json:"id" gorm:"primaryKey"`
+	TaskID       uint                  `json:"task_id" gorm:"not null;uniqueIndex:idx_custom_value_task_field"`
+	FieldID      uint                  `json:"field_id" gorm:"not null;uniqueIndex:idx_custom_value_task_field;index:idx_custom_value_text,priority:1;index:idx_custom_value_number,priority:1;index:idx_custom_value_date,priority:1;index:idx_custom_value_user,priority:1"`
+	Field        CustomFieldDefinition `json:"-" gorm:"foreignKey:FieldID"`
+	TextValue    *string               `json:"text_value,omitempty" gorm:"index:idx_custom_value_text,priority:2"`
+	NumberValue  *float64              `json:"number_value,omitempty" gorm:"index:idx_custom_value_number,priority:2"`
+	DateValue    *time.Time            `json:"date_value,omitempty" gorm:"index:idx_custom_value_date,priority:2"`
+	UserID       *uint                 `json:"user_id,omitempty" gorm:"index:idx_custom_value_user,priority:2"`
+	OptionValues StringList            `json:"option_values,omitempty" gorm:"type:jsonb;index:idx_custom_value_options,type:gin"`
+	CreatedAt    time.Time             `json:"created_at"`
+	UpdatedAt    time.Time             `json:"updated_at"`
+}
+
+// Validate performs validation on the CustomFieldDefinition model
+func (d *CustomFieldDefinition) Validate() error {
+	if strings.TrimSpace(d.Name) == "" {
+		return ErrEmptyFieldName
+	}
+
+	if d.ProjectID == 0 {
+		return ErrMissingProject
+	}
+
+	if !fieldKeyRegex.MatchString(d.Key) {
+		return ErrInvalidFieldKey
+	}
+
+	switch d.Type {
+	case CustomFieldTypeText, CustomFieldTypeNumber, CustomFieldTypeDate, CustomFieldTypeUser:
+	case CustomFieldTypeSingleSelect, CustomFieldTypeMultiSelect:
+		if len(d.Options) == 0 {
+			return ErrMissingFieldOptions
+		}
+	case CustomFieldTypeCurrency:
+		if !currencyRegex.MatchString(d.Currency) {
+			return ErrInvalidCurrency
+		}
+	default:
+		return ErrInvalidFieldType
+	}
+
+	return nil
+}
+
+// IsSelect checks if the field takes values from its options
+func (d *CustomFieldDefinition) IsSelect() bool {
+	return d.Type == CustomFieldTypeSingleSelect || d.Type == CustomFieldTypeMultiSelect
+}
+
+// ValueColumn returns the CustomFieldValue column that stores values of this field
+func (d *CustomFieldDefinition) ValueColumn() string {
+	switch d.Type {
+	case CustomFieldTypeNumber, CustomFieldTypeCurrency:
+		return "number_value"
+	case CustomFieldTypeDate:
+		return "date_value"
+	case CustomFieldTypeUser:
+		return "user_id"
+	case CustomFieldTypeMultiSelect:
+		return "option_values"
+	default:
+		return "text_value"
+	}
+}
+
+// ParseValue converts a raw JSON value into a typed CustomFieldValue for this
+// field. A JSON null yields nil, meaning the value should be cleared.
+func (d *CustomFieldDefinition) ParseValue(raw json.RawMessage) (*CustomFieldValue, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		if d.Required {
+			return nil, ErrFieldValueRequired
+		}
+		return nil, nil
+	}
+
+	value := &CustomFieldValue{FieldID: d.ID}
+	switch d.Type {
+	case CustomFieldTypeText:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, ErrInvalidFieldValue
+		}
+		value.TextValue = &s
+
+	case CustomFieldTypeNumber, CustomFieldTypeCurrency:
+		var n float64
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return nil, ErrInvalidFieldValue
+		}
+		value.NumberValue = &n
+
+	case CustomFieldTypeDate:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, ErrInvalidFieldValue
+		}
+		date, err := ParseFieldDate(s)
+		if err != nil {
+			return nil, ErrInvalidFieldValue
+		}
+		value.DateValue = &date
+
+	case CustomFieldTypeUser:
+		var id uint
+		if err := json.Unmarshal(raw, &id); err != nil || id == 0 {
+			return nil, ErrInvalidFieldValue
+		}
+		value.UserID = &id
+
+	case CustomFieldTypeSingleSelect:
+		var s string
+		if err := json.Unmarshal(raw, &s); err != nil {
+			return nil, ErrInvalidFieldValue
+		}
+		if !d.Options.Contains(s) {
+			return nil, ErrUnknownFieldOption
+		}
+		value.TextValue = &s
+
+	case CustomFieldTypeMultiSelect:
+		var items []string
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil, ErrInvalidFieldValue
+		}
+		for _, item := range items {
+			if !d.Options.Contains(item) {
+				return nil, ErrUnknownFieldOption
+			}
+		}
+		value.OptionValues = items
+
+	default:
+		return nil, ErrInvalidFieldType
+	}
+
+	return value, nil
+}
+
+// ParseFieldDate parses a date custom field value given as YYYY-MM-DD or RFC 3339
+func ParseFieldDate(s string) (time.Time, error) {
+	if date, err := time.Parse("2006-01-02", s); err == nil {
+		return date, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+// Display formats the value for exports
+func (v *CustomFieldValue) Display(d *CustomFieldDefinition) string {
+	switch d.Type {
+	case CustomFieldTypeNumber:
+		if v.NumberValue != nil {
+			return strconv.FormatFloat(*v.NumberValue, 'f', -1, 64)
+		}
+	case CustomFieldTypeCurrency:
+		if v.NumberValue != nil {
+			return fmt.Sprintf("%.2f %s", *v.NumberValue, d.Currency)
+		}
+	case CustomFieldTypeDate:
+		if v.DateValue != nil {
+			return v.DateValue.Format("2006-01-02")
+		}
+	case CustomFieldTypeUser:
+		if v.UserID != nil {
+			return strconv.FormatUint(uint64(*v.UserID), 10)
+		}
+	case CustomFieldTypeMultiSelect:
+		return strings.Join(v.OptionValues, ", ")
+	default:
+		if v.TextValue != nil {
+			return *v.TextValue
+		}
+	}
+	return ""
+}
+
+// BeforeCreate is a GORM hook that runs before creating a new custom field
+func (d *CustomFieldDefinition) BeforeCreate(tx *gorm.DB) error {
+	return d.Validate()
+}
+
+// BeforeUpdate is a GORM hook that runs before updating a custom field
+func (d *CustomFieldDefinition) BeforeUpdate(tx *gorm.DB) error {
+	return d.Validate()
+}
+
+// customFieldTemplates are the field sets offered to projects by category
+var customFieldTemplates = map[string][]CustomFieldDefinition{
+	"finance": {
+		{Name: "Cost Center", Key: "cost_center", Type: CustomFieldTypeText, Required: true},
+		{Name: "Amount", Key: "amount", Type: CustomFieldTypeCurrency, Currency: "USD"},
+	},
+	"hr": {
+		{Name: "Employee", Key: "employee", Type: CustomFieldTypeUser, Required: true},
+		{Name: "Start Date", Key: "start_date", Type: CustomFieldTypeDate},
+	},
+}
+
+// CustomFieldTemplate returns copies of the custom fields preset for a project category
+func CustomFieldTemplate(category string) ([]CustomFieldDefinition, bool) {
+	template, ok := customFieldTemplates[strings.ToLower(category)]
+	if !ok {
+		return nil, false
+	}
+	fields := make([]CustomFieldDefinition, len(template))
+	copy(fields, template)
+	return fields, true
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCustomFieldDefinitionValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		field CustomFieldDefinition
+		err   error
+	}{
+		{"text", CustomFieldDefinition{ProjectID: 1, Name: "Cost Center", Key: "cost_center", Type: CustomFieldTypeText}, nil},
+		{"select with options", CustomFieldDefinition{ProjectID: 1, Name: "Tier", Key: "tier", Type: CustomFieldTypeSingleSelect, Options: StringList{"gold"}}, nil},
+		{"currency", CustomFieldDefinition{ProjectID: 1, Name: "Amount", Key: "amount", Type: CustomFieldTypeCurrency, Currency: "EUR"}, nil},
+		{"blank name", CustomFieldDefinition{ProjectID: 1, Name: " ", Key: "tier", Type: CustomFieldTypeText}, ErrEmptyFieldName},
+		{"no project", CustomFieldDefinition{Name: "Tier", Key: "tier", Type: CustomFieldTypeText}, ErrMissingProject},
+		{"key with capitals", CustomFieldDefinition{ProjectID: 1, Name: "Tier", Key: "Tier", Type: CustomFieldTypeText}, ErrInvalidFieldKey},
+		{"key starting with a digit", CustomFieldDefinition{ProjectID: 1, Name: "Tier", Key: "1tier", Type: CustomFieldTypeText}, ErrInvalidFieldKey},
+		{"select without options", CustomFieldDefinition{ProjectID: 1, Name: "Tags", Key: "tags", Type: CustomFieldTypeMultiSelect}, ErrMissingFieldOptions},
+		{"lowercase currency", CustomFieldDefinition{ProjectID: 1, Name: "Amount", Key: "amount", Type: CustomFieldTypeCurrency, Currency: "eur"}, ErrInvalidCurrency},
+		{"unknown type", CustomFieldDefinition{ProjectID: 1, Name: "Tier", Key: "tier", Type: "color"}, ErrInvalidFieldType},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.field.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("Validate() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestCustomFieldParseValue(t *testing.T) {
+	field := func(fieldType CustomFieldType, options ...string) CustomFieldDefinition {
+		return CustomFieldDefinition{Type: fieldType, Options: options, Currency: "USD"}
+	}
+	text, number, user := "north", 12.5, uint(42)
+	date := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		field CustomFieldDefinition
+		raw   string
+		want  *CustomFieldValue
+		err   error
+	}{
+		{"text", field(CustomFieldTypeText), `"north"`, &CustomFieldValue{TextValue: &text}, nil},
+		{"number", field(CustomFieldTypeNumber), `12.5`, &CustomFieldValue{NumberValue: &number}, nil},
+		{"currency", field(CustomFieldTypeCurrency), `12.5`, &CustomFieldValue{NumberValue: &number}, nil},
+		{"date", field(CustomFieldTypeDate), `"2026-04-01"`, &CustomFieldValue{DateValue: &date}, nil},
+		{"date and time", field(CustomFieldTypeDate), `"2026-04-01T00:00:00Z"`, &CustomFieldValue{DateValue: &date}, nil},
+		{"user", field(CustomFieldTypeUser), `42`, &CustomFieldValue{UserID: &user}, nil},
+		{"single select", field(CustomFieldTypeSingleSelect, "north", "south"), `"north"`, &CustomFieldValue{TextValue: &text}, nil},
+		{"multi select", field(CustomFieldTypeMultiSelect, "a", "b", "c"), `["a","c"]`, &CustomFieldValue{OptionValues: StringList{"a", "c"}}, nil},
+		{"cleared", field(CustomFieldTypeText), `null`, nil, nil},
+		{"cleared when required", CustomFieldDefinition{Type: CustomFieldTypeText, Required: true}, `null`, nil, ErrFieldValueRequired},
+		{"number as text", field(CustomFieldTypeNumber), `"12"`, nil, ErrInvalidFieldValue},
+		{"malformed date", field(CustomFieldTypeDate), `"01/04/2026"`, nil, ErrInvalidFieldValue},
+		{"no user", field(CustomFieldTypeUser), `0`, nil, ErrInvalidFieldValue},
+		{"unknown option", field(CustomFieldTypeSingleSelect, "north"), `"east"`, nil, ErrUnknownFieldOption},
+		{"one unknown option", field(CustomFieldTypeMultiSelect, "a", "b"), `["a","z"]`, nil, ErrUnknownFieldOption},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.field.ParseValue(json.RawMessage(tt.raw))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("ParseValue(%s) error = %v, want %v", tt.raw, err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseValue(%s) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomFieldValueDisplay(t *testing.T) {
+	amount, user := 1234.5, uint(7)
+	date := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		field CustomFieldDefinition
+		value CustomFieldValue
+		want  string
+	}{
+		{"currency", CustomFieldDefinition{Type: CustomFieldTypeCurrency, Currency: "EUR"}, CustomFieldValue{NumberValue: &amount}, "1234.50 EUR"},
+		{"number", CustomFieldDefinition{Type: CustomFieldTypeNumber}, CustomFieldValue{NumberValue: &amount}, "1234.5"},
+		{"date", CustomFieldDefinition{Type: CustomFieldTypeDate}, CustomFieldValue{DateValue: &date}, "2026-04-01"},
+		{"user", CustomFieldDefinition{Type: CustomFieldTypeUser}, CustomFieldValue{UserID: &user}, "7"},
+		{"multi select", CustomFieldDefinition{Type: CustomFieldTypeMultiSelect}, CustomFieldValue{OptionValues: StringList{"a", "b"}}, "a, b"},
+		{"empty", CustomFieldDefinition{Type: CustomFieldTypeText}, CustomFieldValue{}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.value.Display(&tt.field); got != tt.want {
+				t.Errorf("Display() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCustomFieldTemplate(t *testing.T) {
+	tests := []struct {
+		category string
+		keys     []string
+	}{
+		{"finance", []string{"cost_center", "amount"}},
+		{"HR", []string{"employee", "start_date"}},
+		{"legal", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.category, func(t *testing.T) {
+			fields, ok := CustomFieldTemplate(tt.category)
+			if ok != (tt.keys != nil) {
+				t.Fatalf("CustomFieldTemplate(%q) found = %v", tt.category, ok)
+			}
+			var keys []string
+			for _, field := range fields {
+				keys = append(keys, field.Key)
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("keys = %v, want %v", keys, tt.keys)
+			}
+		})
+	}
+
+	// Callers get copies they can change without changing the template
+	fields, _ := CustomFieldTemplate("finance")
+	fields[0].ProjectID = 9
+	if again, _ := CustomFieldTemplate("finance"); again[0].ProjectID != 0 {
+		t.Error("CustomFieldTemplate returned the template itself")
+	}
+}
+
+func TestLabelValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		label Label
+		err   error
+	}{
+		{"valid", Label{OrganizationID: 1, Name: "bug", Color: "#1f6feb"}, nil},
+		{"default color", Label{OrganizationID: 1, Name: "bug"}, nil},
+		{"blank name", Label{OrganizationID: 1, Name: "  "}, ErrEmptyLabelName},
+		{"no organization", Label{Name: "bug"}, ErrMissingOrganization},
+		{"named color", Label{OrganizationID: 1, Name: "bug", Color: "red"}, ErrInvalidLabelColor},
+		{"short hex", Label{OrganizationID: 1, Name: "bug", Color: "#fff"}, ErrInvalidLabelColor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.label.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("Validate() = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidDigestFrequency = errors.New("digest frequency must be off, daily or weekly")
+	ErrInvalidDigestHour      = errors.New("digest hour must be between 0 and 23")
+	ErrInvalidDigestWeekday   = errors.New("digest weekday must be between 0 (Sunday) and 6 (Saturday)")
+)
+
+// DigestFrequency is how often a user is emailed a digest
+type DigestFrequency string
+
+const (
+	DigestFrequencyOff    DigestFrequency = "off"
+	DigestFrequencyDaily  DigestFrequency = "daily"
+	DigestFrequencyWeekly DigestFrequency = "weekly"
+)
+
+// DefaultDigestHour is the local hour digests are sent at unless the user
+// picks another
+const DefaultDigestHour = 8
+
+// DigestSetting is a user's choice of email digest: a summary of their tasks
+// due soon and overdue, new comments on the tasks they watch and status
+// changes of their projects, sent at a local hour in their time zone
+type DigestSetting struct {
+	UserID     uint            `json:"-" gorm:"primaryKey"`
+	Frequency  DigestFrequency `json:"frequency" gorm:"type:varchar(10);not null;default:'off'"`
+	Hour       int             `json:"hour" gorm:"not null"`
+	Weekday    time.Weekday    `json:"weekday" gorm:"not null"` // the day weekly digests are sent
+	LastSentAt *time.Time      `json:"last_sent_at"`            // a digest covers what happened since the last
+	UpdatedAt  time.Time       `json:"updated_at"`
+}
+
+// Validate checks the frequency and schedule of the digest
+func (d *DigestSetting) Validate() error {
+	switch d.Frequency {
+	case DigestFrequencyOff, DigestFrequencyDaily, DigestFrequencyWeekly:
+	default:
+		return ErrInvalidDigestFrequency
+	}
+	if d.Hour < 0 || d.Hour > 23 {
+		return ErrInvalidDigestHour
+	}
+	if d.Weekday < time.Sunday || d.Weekday > time.Saturday {
+		return ErrInvalidDigestWeekday
+	}
+	return nil
+}
+
+// BeforeSave is a GORM hook that validates a digest setting before it is stored
+func (d *DigestSetting) BeforeSave(tx *gorm.DB) error {
+	return d.Validate()
+}
+
+// IsEnabled checks if the user gets a digest
+func (d *DigestSetting) IsEnabled() bool {
+	return d.Frequency == DigestFrequencyDaily || d.Frequency == DigestFrequencyWeekly
+}
+
+// LastSlot returns the latest time at or before now that a digest is
+// scheduled for, at the setting's hour in the given time zone
+func (d *DigestSetting) LastSlot(now time.Time, loc *time.Location) time.Time {
+	local := now.In(loc)
+	slot := time.Date(local.Year(), local.Month(), local.Day(), d.Hour, 0, 0, 0, loc)
+	if slot.After(local) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+	if d.Frequency == DigestFrequencyWeekly {
+		slot = slot.AddDate(0, 0, -((int(slot.Weekday()) - int(d.Weekday) + 7) % 7))
+	}
+	return slot
+}
+
+// NextSlot returns the first time after now that a digest is scheduled for
+func (d *DigestSetting) NextSlot(now time.Time, loc *time.Location) time.Time {
+	if d.Frequency == DigestFrequencyWeekly {
+		return d.LastSlot(now, loc).AddDate(0, 0, 7)
+	}
+	return d.LastSlot(now, loc).AddDate(0, 0, 1)
+}
+
+// Due checks if a digest is to be sent: it is enabled and a scheduled time
+// has passed since the last one was sent
+func (d *DigestSetting) Due(now time.Time, loc *time.Location) bool {
+	if !d.IsEnabled() {
+		return false
+	}
+	return d.LastSentAt == nil || d.LastSentAt.Before(d.LastSlot(now, loc))
+}
+
+// Horizon is how far ahead the digest looks for tasks coming due: until the
+// next digest
+func (d *DigestSetting) Horizon() time.Duration {
+	if d.Frequency == DigestFrequencyWeekly {
+		return 7 * 24 * time.Hour
+	}
+	return 24 * time.Hour
+}
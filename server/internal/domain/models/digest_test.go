@@ -0,0 +1,132 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+	_ "time/tzdata" // digest slots in named zones must not depend on the host's zone database
+)
+
+func loadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("loading zone: %v", err)
+	}
+	return loc
+}
+
+func TestDigestSettingValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		setting DigestSetting
+		err     error
+	}{
+		{"off", DigestSetting{Frequency: DigestFrequencyOff}, nil},
+		{"daily at 23", DigestSetting{Frequency: DigestFrequencyDaily, Hour: 23}, nil},
+		{"weekly on saturday", DigestSetting{Frequency: DigestFrequencyWeekly, Weekday: time.Saturday}, nil},
+		{"unset frequency", DigestSetting{}, ErrInvalidDigestFrequency},
+		{"hourly", DigestSetting{Frequency: "hourly"}, ErrInvalidDigestFrequency},
+		{"hour 24", DigestSetting{Frequency: DigestFrequencyDaily, Hour: 24}, ErrInvalidDigestHour},
+		{"negative hour", DigestSetting{Frequency: DigestFrequencyDaily, Hour: -1}, ErrInvalidDigestHour},
+		{"weekday 7", DigestSetting{Frequency: DigestFrequencyWeekly, Weekday: 7}, ErrInvalidDigestWeekday},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.setting.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestDigestSettingSlots(t *testing.T) {
+	// Monday, the day after New York moved to daylight saving time
+	monday := time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)
+	daily := func(hour int) DigestSetting {
+		return DigestSetting{Frequency: DigestFrequencyDaily, Hour: hour}
+	}
+	weekly := func(day time.Weekday, hour int) DigestSetting {
+		return DigestSetting{Frequency: DigestFrequencyWeekly, Hour: hour, Weekday: day}
+	}
+
+	tests := []struct {
+		name    string
+		setting DigestSetting
+		zone    string
+		now     time.Time
+		last    time.Time
+		next    time.Time
+	}{
+		{"daily, sent earlier today", daily(8), "UTC", monday,
+			time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC), time.Date(2026, 3, 10, 8, 0, 0, 0, time.UTC)},
+		{"daily, due later today", daily(12), "UTC", monday,
+			time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)},
+		{"daily, at the hour", daily(10), "UTC", monday,
+			monday, time.Date(2026, 3, 10, 10, 0, 0, 0, time.UTC)},
+		{"weekly, sent earlier today", weekly(time.Monday, 8), "UTC", monday,
+			time.Date(2026, 3, 9, 8, 0, 0, 0, time.UTC), time.Date(2026, 3, 16, 8, 0, 0, 0, time.UTC)},
+		{"weekly, due later today", weekly(time.Monday, 12), "UTC", monday,
+			time.Date(2026, 3, 2, 12, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)},
+		{"weekly, on friday", weekly(time.Friday, 8), "UTC", monday,
+			time.Date(2026, 3, 6, 8, 0, 0, 0, time.UTC), time.Date(2026, 3, 13, 8, 0, 0, 0, time.UTC)},
+		{"behind UTC", daily(8), "America/New_York", monday,
+			time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 12, 0, 0, 0, time.UTC)},
+		{"across daylight saving time", daily(8), "America/New_York", time.Date(2026, 3, 8, 10, 0, 0, 0, time.UTC),
+			time.Date(2026, 3, 7, 13, 0, 0, 0, time.UTC), time.Date(2026, 3, 8, 12, 0, 0, 0, time.UTC)},
+		{"half-hour zone", daily(8), "Asia/Kolkata", monday,
+			time.Date(2026, 3, 9, 2, 30, 0, 0, time.UTC), time.Date(2026, 3, 10, 2, 30, 0, 0, time.UTC)},
+		{"a day ahead of UTC", daily(8), "Asia/Tokyo", time.Date(2026, 3, 8, 23, 30, 0, 0, time.UTC),
+			time.Date(2026, 3, 8, 23, 0, 0, 0, time.UTC), time.Date(2026, 3, 9, 23, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := loadLocation(t, tt.zone)
+			if got := tt.setting.LastSlot(tt.now, loc); !got.Equal(tt.last) {
+				t.Errorf("LastSlot() = %v, want %v", got.UTC(), tt.last)
+			}
+			if got := tt.setting.NextSlot(tt.now, loc); !got.Equal(tt.next) {
+				t.Errorf("NextSlot() = %v, want %v", got.UTC(), tt.next)
+			}
+		})
+	}
+}
+
+func TestDigestSettingDue(t *testing.T) {
+	now := time.Date(2026, 3, 9, 10, 0, 0, 0, time.UTC)
+	at := func(day, hour, min int) *time.Time {
+		t := time.Date(2026, 3, day, hour, min, 0, 0, time.UTC)
+		return &t
+	}
+
+	tests := []struct {
+		name    string
+		setting DigestSetting
+		due     bool
+	}{
+		{"off", DigestSetting{Frequency: DigestFrequencyOff, Hour: 8}, false},
+		{"never sent", DigestSetting{Frequency: DigestFrequencyDaily, Hour: 8}, true},
+		{"sent after today's slot", DigestSetting{Frequency: DigestFrequencyDaily, Hour: 8, LastSentAt: at(9, 8, 5)}, false},
+		{"sent at today's slot", DigestSetting{Frequency: DigestFrequencyDaily, Hour: 8, LastSentAt: at(9, 8, 0)}, false},
+		{"sent before today's slot", DigestSetting{Frequency: DigestFrequencyDaily, Hour: 8, LastSentAt: at(9, 7, 59)}, true},
+		{"weekly, sent this week", DigestSetting{Frequency: DigestFrequencyWeekly, Hour: 8, Weekday: time.Friday, LastSentAt: at(6, 9, 0)}, false},
+		{"weekly, sent last week", DigestSetting{Frequency: DigestFrequencyWeekly, Hour: 8, Weekday: time.Friday, LastSentAt: at(5, 9, 0)}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.setting.Due(now, time.UTC); got != tt.due {
+				t.Errorf("Due() = %v, want %v", got, tt.due)
+			}
+		})
+	}
+}
+
+func TestDigestSettingHorizon(t *testing.T) {
+	daily, weekly := DigestSetting{Frequency: DigestFrequencyDaily}, DigestSetting{Frequency: DigestFrequencyWeekly}
+	if got := daily.Horizon(); got != 24*time.Hour {
+		t.Errorf("daily Horizon() = %v, want 24h", got)
+	}
+	if got := weekly.Horizon(); got != 7*24*time.Hour {
+		t.Errorf("weekly Horizon() = %v, want 168h", got)
+	}
+}
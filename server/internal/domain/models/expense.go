@@ -0,0 +1,90 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"gorm.io/gorm"
+)
+
+// Common validation errors
+var (
+	ErrEmptyExpenseDescription = errors.New("expense description cannot be empty")
+	ErrInvalidExpenseAmount    = errors.New("expense amount must be positive")
+	ErrMissingExpenseDate      = errors.New("expense date is required")
+)
+
+// ExpenseStatus represents the approval state of an expense
+type ExpenseStatus string
+
+const (
+	ExpenseStatusPending  ExpenseStatus = "pending"
+	ExpenseStatusApproved ExpenseStatus = "approved"
+	ExpenseStatusRejected ExpenseStatus = "rejected"
+)
+
+// Expense is money spent on a project, submitted by a member and approved by
+// a project manager. Receipts are attachments of the expense.
+type Expense struct {
+	gorm.Model
+	OrganizationID uint          `json:"organization_id" gorm:"not null;index"`
+	ProjectID      uint          `json:"project_id" gorm:"not null;index"`
+	TaskID         *uint         `json:"task_id" gorm:"index"`
+	SubmittedByID  uint          `json:"submitted_by_id" gorm:"not null;index"`
+	SubmittedBy    User          `json:"-" gorm:"foreignKey:SubmittedByID"`
+	Category       string        `json:"category" gorm:"type:varchar(50);not null"`
+	Description    string        `json:"description" gorm:"not null"`
+	Amount         money.Money   `json:"amount" gorm:"embedded;embeddedPrefix:amount_"`
+	IncurredOn     time.Time     `json:"incurred_on" gorm:"type:date;not null"`
+	Status         ExpenseStatus `json:"status" gorm:"type:varchar(20);default:'pending';index"`
+	ReviewedByID   *uint         `json:"reviewed_by_id"`
+	ReviewedAt     *time.Time    `json:"reviewed_at"`
+	ReviewNote     string        `json:"review_note"`
+
+	Receipts []Attachment `json:"receipts,omitempty" gorm:"foreignKey:ExpenseID"`
+}
+
+// Validate performs validation on the Expense model
+func (e *Expense) Validate() error {
+	if e.ProjectID == 0 {
+		return ErrMissingProject
+	}
+
+	if NormalizeBudgetCategory(e.Category) == "" {
+		return ErrEmptyBudgetCategory
+	}
+
+	if strings.TrimSpace(e.Description) == "" {
+		return ErrEmptyExpenseDescription
+	}
+
+	if err := e.Amount.Validate(); err != nil {
+		return err
+	}
+
+	if !e.Amount.IsPositive() {
+		return ErrInvalidExpenseAmount
+	}
+
+	if e.IncurredOn.IsZero() {
+		return ErrMissingExpenseDate
+	}
+
+	return nil
+}
+
+// IsPending checks if the expense is awaiting review
+func (e *Expense) IsPending() bool {
+	return e.Status == ExpenseStatusPending
+}
+
+// BeforeSave is a GORM hook that validates the expense and normalizes its category
+func (e *Expense) BeforeSave(tx *gorm.DB) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	e.Category = NormalizeBudgetCategory(e.Category)
+	return nil
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownFeature   = errors.New("unknown feature")
+	ErrDuplicateFeature = errors.New("a plan can list each feature only once")
+)
+
+// Feature keys the code recognizes. Plans grant them through PlanFeature
+// entries with the same key, or through their flags for the features that
+// have one; support staff can grant or withhold them per organization.
+const (
+	FeatureCustomDomain    = "custom_domain"
+	FeatureAPIAccess       = "api_access"
+	FeaturePrioritySupport = "priority_support"
+	FeatureGantt           = "gantt"
+	FeatureTimeTracking    = "time_tracking"
+	FeatureBudgets         = "budgets"
+	FeatureCustomFields    = "custom_fields"
+	FeatureSavedFilters    = "saved_filters"
+)
+
+// FeatureInfo describes a feature in the registry
+type FeatureInfo struct {
+	Key         string `json:"key"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Features is the registry of feature keys, in the order clients list them
+var Features = []FeatureInfo{
+	{FeatureCustomDomain, "Custom domain", "Serve the workspace from the organization's own domain"},
+	{FeatureAPIAccess, "API access", "Use the API with personal access tokens"},
+	{FeaturePrioritySupport, "Priority support", "Support requests are answered first"},
+	{FeatureGantt, "Gantt charts", "Plan projects on a timeline"},
+	{FeatureTimeTracking, "Time tracking", "Timers, time entries and timesheets"},
+	{FeatureBudgets, "Budgets", "Project budgets, hourly rates and expenses"},
+	{FeatureCustomFields, "Custom fields", "Extra fields on tasks"},
+	{FeatureSavedFilters, "Saved filters", "Saved task queries and their email digests"},
+}
+
+// IsKnownFeature checks if the key is in the feature registry
+func IsKnownFeature(key string) bool {
+	for _, feature := range Features {
+		if feature.Key == key {
+			return true
+		}
+	}
+	return false
+}
+
+// FeatureOverride grants or withholds a feature for one organization
+// regardless of its plan, until it expires
+type FeatureOverride struct {
+	ID             uint       `json:"id" gorm:"primaryKey"`
+	OrganizationID uint       `json:"organization_id" gorm:"not null;uniqueIndex:idx_feature_override"`
+	Feature        string     `json:"feature" gorm:"type:varchar(50);not null;uniqueIndex:idx_feature_override"`
+	Enabled        bool       `json:"enabled" gorm:"not null"`
+	Reason         string     `json:"reason"`
+	SetByID        uint       `json:"set_by_id" gorm:"not null"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// IsActive checks if the override still applies at the given time
+func (o *FeatureOverride) IsActive(now time.Time) bool {
+	return o.ExpiresAt == nil || now.Before(*o.ExpiresAt)
+}
+
+// AfterSave is a GORM hook that applies the override to the organization's
+// feature flags
+func (o *FeatureOverride) AfterSave(tx *gorm.DB) error {
+	return syncOrganizationFeatures(tx, "organizations.id = @org", map[string]interface{}{"org": o.OrganizationID})
+}
+
+// AfterDelete is a GORM hook that hands the organization's feature flags
+// back to its plan. Deletes must name the organization on the model.
+func (o *FeatureOverride) AfterDelete(tx *gorm.DB) error {
+	return syncOrganizationFeatures(tx, "organizations.id = @org", map[string]interface{}{"org": o.OrganizationID})
+}
+
+// SyncExpiredFeatureOverrides hands the feature flags of organizations whose
+// overrides expired after since and by now back to their plan
+func SyncExpiredFeatureOverrides(tx *gorm.DB, since, now time.Time) error {
+	return syncOrganizationFeatures(tx,
+		"organizations.id IN (SELECT organization_id FROM feature_overrides WHERE expires_at > @since AND expires_at <= @now)",
+		map[string]interface{}{"since": since, "now": now})
+}
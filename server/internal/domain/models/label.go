@@ -0,0 +1,60 @@
+package models
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Common validation errors
+var (
+	ErrEmptyLabelName    = errors.New("label name cannot be empty")
+	ErrInvalidLabelColor = errors.New("label color must be a hex color such as #1f6feb")
+)
+
+var labelColorRegex = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// Label is an organization-wide tag that can be attached to tasks
+type Label struct {
+	gorm.Model
+	OrganizationID uint         `json:"organization_id" gorm:"not null;uniqueIndex:idx_label_org_name"`
+	Organization   Organization `json:"-" gorm:"foreignKey:OrganizationID"`
+	Name           string       `json:"name" gorm:"not null;uniqueIndex:idx_label_org_name"`
+	Color          string       `json:"color" gorm:"type:varchar(7);default:'#6e7781'"`
+	Description    string       `json:"description"`
+}
+
+// TaskLabel represents the many-to-many relationship between tasks and labels
+type TaskLabel struct {
+	TaskID  uint `gorm:"primaryKey"`
+	LabelID uint `gorm:"primaryKey;index"`
+}
+
+// Validate performs validation on the Label model
+func (l *Label) Validate() error {
+	if strings.TrimSpace(l.Name) == "" {
+		return ErrEmptyLabelName
+	}
+
+	if l.OrganizationID == 0 {
+		return ErrMissingOrganization
+	}
+
+	if l.Color != "" && !labelColorRegex.MatchString(l.Color) {
+		return ErrInvalidLabelColor
+	}
+
+	return nil
+}
+
+// BeforeCreate is a GORM hook that runs before creating a new label
+func (l *Label) BeforeCreate(tx *gorm.DB) error {
+	return l.Validate()
+}
+
+// BeforeUpdate is a GORM hook that runs before updating a label
+func (l *Label) BeforeUpdate(tx *gorm.DB) error {
+	return l.Validate()
+}
@@ -0,0 +1,277 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUnknownNotificationType    = errors.New("unknown notification type")
+	ErrUnknownNotificationChannel = errors.New("unknown notification channel")
+)
+
+// NotificationType is the kind of event a user is notified of
+type NotificationType string
+
+const (
+	NotificationTypeAssigned     NotificationType = "assigned"      // a task was assigned to the user
+	NotificationTypeMentioned    NotificationType = "mentioned"     // a comment mentions the user
+	NotificationTypeCommentReply NotificationType = "comment_reply" // someone replied to the user's comment
+	NotificationTypeDueSoon      NotificationType = "due_soon"      // a task assigned to the user is due soon
+	NotificationTypeInvited      NotificationType = "invited"       // the user was added to an organization, project or team
+)
+
+// NotificationChannel is a way notifications reach a user
+type NotificationChannel string
+
+const (
+	NotificationChannelInApp NotificationChannel = "in_app"
+	NotificationChannelEmail NotificationChannel = "email"
+	NotificationChannelPush  NotificationChannel = "push"
+)
+
+// NotificationTypeInfo describes a notification type for the preferences page
+type NotificationTypeInfo struct {
+	Type        NotificationType `json:"type"`
+	Description string           `json:"description"`
+}
+
+// NotificationTypes lists the events users can be notified of
+var NotificationTypes = []NotificationTypeInfo{
+	{NotificationTypeAssigned, "A task is assigned to you"},
+	{NotificationTypeMentioned, "Someone mentions you in a comment"},
+	{NotificationTypeCommentReply, "Someone replies to your comment"},
+	{NotificationTypeDueSoon, "A task assigned to you is due within a day"},
+	{NotificationTypeInvited, "You are added to an organization, project or team"},
+}
+
+// NotificationChannels lists the channels notifications are sent on
+var NotificationChannels = []NotificationChannel{
+	NotificationChannelInApp,
+	NotificationChannelEmail,
+	NotificationChannelPush,
+}
+
+// IsValid checks if the type is a known notification type
+func (t NotificationType) IsValid() bool {
+	for _, info := range NotificationTypes {
+		if info.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValid checks if the channel is a known notification channel
+func (c NotificationChannel) IsValid() bool {
+	for _, channel := range NotificationChannels {
+		if channel == c {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is an event a user is notified of. It is kept in the user's
+// inbox if they get notifications of its type in the app; Email and Push
+// record the other channels it is to be sent on.
+type Notification struct {
+	ID             uint             `json:"id" gorm:"primaryKey"`
+	UserID         uint             `json:"user_id" gorm:"not null;index:idx_notification_inbox,priority:1"`
+	Type           NotificationType `json:"type" gorm:"type:varchar(30);not null"`
+	ActorID        *uint            `json:"actor_id"`
+	OrganizationID *uint            `json:"organization_id"`
+	ProjectID      *uint            `json:"project_id"`
+	TaskID         *uint            `json:"task_id" gorm:"index"`
+	CommentID      *uint            `json:"comment_id"`
+	Message        string           `json:"message" gorm:"not null"`
+	DedupeKey      string           `json:"-" gorm:"uniqueIndex:idx_notification_dedupe,where:dedupe_key <> ''"` // an event only notifies once
+	InApp          bool             `json:"-" gorm:"not null;default:false"`
+	Email          bool             `json:"-" gorm:"not null;default:false"`
+	Push           bool             `json:"-" gorm:"not null;default:false"`
+	EmailQueuedAt  *time.Time       `json:"-" gorm:"index:idx_notification_email,where:email AND email_queued_at IS NULL"` // when it was put in the email outbox
+	ReadAt         *time.Time       `json:"read_at"`
+	CreatedAt      time.Time        `json:"created_at" gorm:"not null;index:idx_notification_inbox,priority:2"`
+}
+
+// IsRead checks if the user has read the notification
+func (n *Notification) IsRead() bool {
+	return n.ReadAt != nil
+}
+
+// NotificationPreference turns one type of notification on or off on one
+// channel for a user. Types and channels without a preference are on.
+type NotificationPreference struct {
+	UserID    uint                `json:"-" gorm:"primaryKey"`
+	Type      NotificationType    `json:"type" gorm:"primaryKey;type:varchar(30)"`
+	Channel   NotificationChannel `json:"channel" gorm:"primaryKey;type:varchar(20)"`
+	Enabled   bool                `json:"enabled" gorm:"not null"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// Validate checks the preference names a known type and channel
+func (p *NotificationPreference) Validate() error {
+	if !p.Type.IsValid() {
+		return fmt.Errorf("%w: %q", ErrUnknownNotificationType, p.Type)
+	}
+	if !p.Channel.IsValid() {
+		return fmt.Errorf("%w: %q", ErrUnknownNotificationChannel, p.Channel)
+	}
+	return nil
+}
+
+// BeforeSave is a GORM hook that validates a preference before it is stored
+func (p *NotificationPreference) BeforeSave(tx *gorm.DB) error {
+	return p.Validate()
+}
+
+// ChannelsEnabled works out the channels a type of notification is sent on,
+// given a user's preferences
+func ChannelsEnabled(preferences []NotificationPreference, t NotificationType) map[NotificationChannel]bool {
+	enabled := make(map[NotificationChannel]bool, len(NotificationChannels))
+	for _, channel := range NotificationChannels {
+		enabled[channel] = true
+	}
+	for _, preference := range preferences {
+		if preference.Type == t {
+			enabled[preference.Channel] = preference.Enabled
+		}
+	}
+	return enabled
+}
+
+// mentionPattern matches the markup the editor inserts for a mention, <@42>
+var mentionPattern = regexp.MustCompile(`<@(\d+)>`)
+
+// Mentions returns the IDs of the users mentioned in a text, once each
+func Mentions(text string) []uint {
+	var ids []uint
+	seen := make(map[uint]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(text, -1) {
+		id, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || id == 0 || seen[uint(id)] {
+			continue
+		}
+		seen[uint(id)] = true
+		ids = append(ids, uint(id))
+	}
+	return ids
+}
+
+// ReplaceMentions rewrites the mentions in a text, such as <@42>, with what
+// name returns for the mentioned user's ID
+func ReplaceMentions(text string, name func(id uint) string) string {
+	return mentionPattern.ReplaceAllStringFunc(text, func(mention string) string {
+		id, err := strconv.ParseUint(mentionPattern.FindStringSubmatch(mention)[1], 10, 64)
+		if err != nil {
+			return mention
+		}
+		return name(uint(id))
+	})
+}
+
+// Notify stores a notification for the channels its user gets notifications
+// of its type on. The actor stored in the context is not notified of their
+// own changes. A notification with a dedupe key is only stored once. Users
+// are told of the notifications in their inbox live.
+func Notify(tx *gorm.DB, n *Notification) error {
+	db := tx.Session(&gorm.Session{NewDB: true})
+	n.ActorID = ActorFromContext(tx.Statement.Context)
+	if n.ActorID != nil && *n.ActorID == n.UserID {
+		return nil
+	}
+
+	var preferences []NotificationPreference
+	if err := db.Where("user_id = ? AND type = ?", n.UserID, n.Type).Find(&preferences).Error; err != nil {
+		return err
+	}
+	enabled := ChannelsEnabled(preferences, n.Type)
+	n.InApp = enabled[NotificationChannelInApp]
+	n.Email = enabled[NotificationChannelEmail]
+	n.Push = enabled[NotificationChannelPush]
+	if !n.InApp && !n.Email && !n.Push {
+		return nil
+	}
+
+	n.CreatedAt = time.Now()
+	if n.DedupeKey != "" {
+		db = db.Clauses(clause.OnConflict{DoNothing: true})
+	}
+	result := db.Create(n)
+	if result.Error != nil || result.RowsAffected == 0 || !n.InApp {
+		return result.Error
+	}
+	// Tell the user's open clients, which show the inbox
+	return publishRealtime(tx, &RealtimeEvent{
+		Type:         RealtimeEventNotification,
+		Action:       ActivityActionCreated,
+		EntityID:     n.ID,
+		UserID:       &n.UserID,
+		ActorID:      n.ActorID,
+		Notification: n,
+		At:           n.CreatedAt,
+	})
+}
+
+// notificationActor names the user making a change in notification messages,
+// or returns "" for changes made by the system
+func notificationActor(tx *gorm.DB) (string, error) {
+	actorID := ActorFromContext(tx.Statement.Context)
+	if actorID == nil {
+		return "", nil
+	}
+	var actor User
+	err := tx.Session(&gorm.Session{NewDB: true}).
+		Unscoped().
+		Select("first_name", "last_name").
+		Where("id = ?", *actorID).
+		Take(&actor).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", nil
+		}
+		return "", err
+	}
+	return actor.FullName(), nil
+}
+
+// notificationMessage builds a message from the actor's name and what they
+// did, or from the passive form when the system made the change
+func notificationMessage(tx *gorm.DB, active, passive string, args ...interface{}) (string, error) {
+	actor, err := notificationActor(tx)
+	if err != nil {
+		return "", err
+	}
+	if actor == "" {
+		return fmt.Sprintf(passive, args...), nil
+	}
+	return actor + " " + fmt.Sprintf(active, args...), nil
+}
+
+// notifyInvited tells a user they were added to an organization, project or
+// team, named by kind and the table and ID it is stored under
+func notifyInvited(tx *gorm.DB, userID uint, kind, table string, id uint, n *Notification) error {
+	var name string
+	err := tx.Session(&gorm.Session{NewDB: true}).
+		Table(table).
+		Select("name").
+		Where("id = ?", id).
+		Scan(&name).Error
+	if err != nil {
+		return err
+	}
+	message, err := notificationMessage(tx, "added you to the %s \"%s\"", "You were added to the %s \"%s\"", kind, name)
+	if err != nil {
+		return err
+	}
+	n.UserID = userID
+	n.Type = NotificationTypeInvited
+	n.Message = message
+	return Notify(tx, n)
+}
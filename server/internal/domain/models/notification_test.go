@@ -0,0 +1,86 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestChannelsEnabled(t *testing.T) {
+	preferences := []NotificationPreference{
+		{Type: NotificationTypeMentioned, Channel: NotificationChannelEmail, Enabled: false},
+		{Type: NotificationTypeMentioned, Channel: NotificationChannelPush, Enabled: true},
+		{Type: NotificationTypeDueSoon, Channel: NotificationChannelInApp, Enabled: false},
+		{Type: NotificationTypeDueSoon, Channel: NotificationChannelEmail, Enabled: false},
+		{Type: NotificationTypeDueSoon, Channel: NotificationChannelPush, Enabled: false},
+	}
+	all := map[NotificationChannel]bool{NotificationChannelInApp: true, NotificationChannelEmail: true, NotificationChannelPush: true}
+
+	tests := []struct {
+		name        string
+		preferences []NotificationPreference
+		t           NotificationType
+		want        map[NotificationChannel]bool
+	}{
+		{"no preferences", nil, NotificationTypeAssigned, all},
+		{"preferences of other types", preferences, NotificationTypeAssigned, all},
+		{"one channel off", preferences, NotificationTypeMentioned,
+			map[NotificationChannel]bool{NotificationChannelInApp: true, NotificationChannelEmail: false, NotificationChannelPush: true}},
+		{"every channel off", preferences, NotificationTypeDueSoon,
+			map[NotificationChannel]bool{NotificationChannelInApp: false, NotificationChannelEmail: false, NotificationChannelPush: false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ChannelsEnabled(tt.preferences, tt.t); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ChannelsEnabled(%s) = %v, want %v", tt.t, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotificationPreferenceValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		preference NotificationPreference
+		err        error
+	}{
+		{"valid", NotificationPreference{Type: NotificationTypeInvited, Channel: NotificationChannelPush}, nil},
+		{"unknown type", NotificationPreference{Type: "birthday", Channel: NotificationChannelEmail}, ErrUnknownNotificationType},
+		{"unknown channel", NotificationPreference{Type: NotificationTypeAssigned, Channel: "sms"}, ErrUnknownNotificationChannel},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.preference.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestMentions(t *testing.T) {
+	tests := []struct {
+		text string
+		want []uint
+	}{
+		{"no mentions", nil},
+		{"thanks <@42>", []uint{42}},
+		{"<@7> and <@42>, then <@7> again", []uint{7, 42}},
+		{"not mentions: <@0> <@x> <42> @42", nil},
+		{"<@99999999999999999999999>", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := Mentions(tt.text); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Mentions() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplaceMentions(t *testing.T) {
+	name := func(id uint) string { return fmt.Sprintf("@user%d", id) }
+	if got, want := ReplaceMentions("<@7>, see <@42> and <@7>", name), "@user7, see @user42 and @user7"; got != want {
+		t.Errorf("ReplaceMentions() = %q, want %q", got, want)
+	}
+}
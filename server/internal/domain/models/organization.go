@@ -37,12 +37,14 @@ type Organization struct {
 	BillingEmail   string `json:"billing_email"`
 	BillingName    string `json:"billing_name"`
 	BillingAddress string `json:"billing_address"`
+	BillingCountry string `json:"billing_country" gorm:"type:varchar(2)"` // ISO 3166 code, which decides the tax charged
 	TaxID          string `json:"tax_id"`
 	
 	// Feature flags based on subscription
 	CustomDomainEnabled bool `json:"custom_domain_enabled" gorm:"default:false"`
 	APIAccessEnabled    bool `json:"api_access_enabled" gorm:"default:false"`
 	StorageLimit       int  `json:"storage_limit" gorm:"default:5"` // in GB
+	ReadOnly           bool `json:"read_only" gorm:"default:false"` // an invoice is unpaid past its grace period
 }
 
 // OrganizationUser represents the many-to-many relationship between
@@ -51,6 +53,9 @@ type OrganizationUser struct {
 	OrganizationID uint   `gorm:"primaryKey"`                    // Foreign key to Organization
 	UserID         uint   `gorm:"primaryKey"`                    // Foreign key to User
 	Role          string `json:"role" gorm:"default:'member'"` // User's role: admin or member
+
+	// original is a copy of the membership as it was read from the database
+	original *OrganizationUser
 }
 
 // Validate performs validation on the Organization model
@@ -87,12 +92,13 @@ func (o *Organization) IsTrialing() bool {
 	return o.CurrentSubscription != nil && o.CurrentSubscription.IsTrialing()
 }
 
-// HasFeature checks if the organization has access to a specific feature
-func (o *Organization) HasFeature(featureName string) bool {
+// HasFeature checks if the plan of the organization's subscription includes
+// a feature, given by its registry key. Overrides are not taken into account.
+func (o *Organization) HasFeature(key string) bool {
 	if o.CurrentSubscription == nil {
 		return false
 	}
-	return o.CurrentSubscription.HasFeature(featureName)
+	return o.CurrentSubscription.HasFeature(key)
 }
 
 // WithinLimits checks if the organization is within its subscription limits
@@ -111,4 +117,68 @@ func (o *Organization) BeforeCreate(tx *gorm.DB) error {
 // BeforeUpdate is a GORM hook that runs before updating an organization
 func (o *Organization) BeforeUpdate(tx *gorm.DB) error {
 	return o.Validate()
-} 
\ No newline at end of file
+} 
+
+// AfterFind is a GORM hook that keeps a copy of the stored membership
+func (m *OrganizationUser) AfterFind(tx *gorm.DB) error {
+	original := *m
+	original.original = nil
+	m.original = &original
+	return nil
+}
+
+// BeforeCreate is a GORM hook that keeps the organization within its plan's
+// user limit
+func (m *OrganizationUser) BeforeCreate(tx *gorm.DB) error {
+	return checkUsageLimit(tx, m.OrganizationID, UsageLimitUsers)
+}
+
+// AfterCreate is a GORM hook that records a user joining the organization
+// and counts them toward its usage
+func (m *OrganizationUser) AfterCreate(tx *gorm.DB) error {
+	if err := m.recordActivity(tx, ActivityActionCreated, diffFields(nil, m)); err != nil {
+		return err
+	}
+	orgID := m.OrganizationID
+	if err := notifyInvited(tx, m.UserID, "organization", "organizations", orgID, &Notification{OrganizationID: &orgID}); err != nil {
+		return err
+	}
+	return syncOrganizationUsage(tx, m.OrganizationID)
+}
+
+// AfterUpdate is a GORM hook that records role changes
+func (m *OrganizationUser) AfterUpdate(tx *gorm.DB) error {
+	if m.original == nil {
+		return nil
+	}
+	changes := diffFields(m.original, m)
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := m.recordActivity(tx, ActivityActionUpdated, changes); err != nil {
+		return err
+	}
+	return m.AfterFind(tx)
+}
+
+// AfterDelete is a GORM hook that records a user leaving the organization
+func (m *OrganizationUser) AfterDelete(tx *gorm.DB) error {
+	if m.OrganizationID == 0 || m.UserID == 0 {
+		return nil
+	}
+	if err := m.recordActivity(tx, ActivityActionDeleted, deletionChanges(m)); err != nil {
+		return err
+	}
+	return syncOrganizationUsage(tx, m.OrganizationID)
+}
+
+// recordActivity stores an activity event for the membership
+func (m *OrganizationUser) recordActivity(tx *gorm.DB, action ActivityAction, changes FieldChanges) error {
+	return recordActivity(tx, &ActivityEvent{
+		OrganizationID: m.OrganizationID,
+		EntityType:     ActivityEntityOrganizationMember,
+		EntityID:       m.UserID,
+		Action:         action,
+		Changes:        changes,
+	})
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// OutboxStatus is where an email is in the outbox
+type OutboxStatus string
+
+const (
+	OutboxStatusPending OutboxStatus = "pending" // waiting to be sent, or to be retried
+	OutboxStatusSent    OutboxStatus = "sent"
+	OutboxStatusFailed  OutboxStatus = "failed" // gave up after a permanent failure or too many attempts
+)
+
+// OutboxEmail is a rendered email waiting in the outbox. Emails are queued
+// here instead of being sent while a request waits, and a background worker
+// sends them, retrying failures with backoff.
+type OutboxEmail struct {
+	ID             uint         `json:"id" gorm:"primaryKey"`
+	To             string       `json:"to" gorm:"not null"`
+	Template       string       `json:"template" gorm:"type:varchar(50);not null"`
+	Subject        string       `json:"subject" gorm:"not null"`
+	TextBody       string       `json:"-" gorm:"type:text;not null"`
+	HTMLBody       string       `json:"-" gorm:"type:text;not null"`
+	UnsubscribeURL string       `json:"-"` // offered to mail clients for one-click unsubscribing
+	Status         OutboxStatus `json:"status" gorm:"type:varchar(20);not null;default:'pending';index:idx_outbox_due,priority:1"`
+	Attempts       int          `json:"attempts" gorm:"not null;default:0"`
+	NextAttemptAt  time.Time    `json:"next_attempt_at" gorm:"not null;index:idx_outbox_due,priority:2"`
+	LastError      string       `json:"last_error,omitempty"`
+	SentAt         *time.Time   `json:"sent_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
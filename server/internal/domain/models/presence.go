@@ -0,0 +1,127 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrInvalidPresenceResource = errors.New("presence resource must be task or board")
+	ErrInvalidPresenceActivity = errors.New("presence activity must be viewing, typing or editing")
+)
+
+// PresenceResource is the kind of page users are present on
+type PresenceResource string
+
+const (
+	PresenceResourceTask  PresenceResource = "task"  // a task, by task ID
+	PresenceResourceBoard PresenceResource = "board" // a project's board, by project ID
+)
+
+// PresenceActivity is what a user present on a page is doing
+type PresenceActivity string
+
+const (
+	PresenceActivityViewing PresenceActivity = "viewing"
+	PresenceActivityTyping  PresenceActivity = "typing"  // writing a comment
+	PresenceActivityEditing PresenceActivity = "editing" // changing the task itself, such as its description
+)
+
+// RealtimeEventPresence is the type of event sent when a user arrives on a
+// page, leaves it or starts or stops typing or editing there
+const RealtimeEventPresence = "presence"
+
+// Presence records that a user has a page open on one connection. It lasts
+// until ExpiresAt unless the connection renews it, so that the presence of
+// users whose server went away lapses on its own. Typing and editing lapse
+// sooner, at ActivityUntil.
+type Presence struct {
+	ConnectionID   string           `json:"connection_id" gorm:"primaryKey;type:varchar(40)"` // tells apart a user's tabs
+	ResourceType   PresenceResource `json:"resource_type" gorm:"primaryKey;type:varchar(20);index:idx_presence_resource,priority:1"`
+	ResourceID     uint             `json:"resource_id" gorm:"primaryKey;index:idx_presence_resource,priority:2"`
+	UserID         uint             `json:"user_id" gorm:"not null"`
+	OrganizationID uint             `json:"organization_id" gorm:"not null"`
+	ProjectID      uint             `json:"project_id" gorm:"not null"`
+	Activity       PresenceActivity `json:"activity" gorm:"type:varchar(20);not null"`
+	ActivityUntil  *time.Time       `json:"activity_until"` // when typing or editing lapses
+	ExpiresAt      time.Time        `json:"-" gorm:"not null;index"`
+	CreatedAt      time.Time        `json:"since"`
+}
+
+// Validate checks the presence names a known resource and activity
+func (p *Presence) Validate() error {
+	switch p.ResourceType {
+	case PresenceResourceTask, PresenceResourceBoard:
+	default:
+		return ErrInvalidPresenceResource
+	}
+	switch p.Activity {
+	case PresenceActivityViewing, PresenceActivityTyping, PresenceActivityEditing:
+	default:
+		return ErrInvalidPresenceActivity
+	}
+	return nil
+}
+
+// CurrentActivity returns what the user is doing at a time, which is viewing
+// once typing or editing has lapsed
+func (p *Presence) CurrentActivity(now time.Time) PresenceActivity {
+	if p.ActivityUntil != nil && !p.ActivityUntil.After(now) {
+		return PresenceActivityViewing
+	}
+	return p.Activity
+}
+
+// BeforeSave is a GORM hook that validates a presence before it is stored
+func (p *Presence) BeforeSave(tx *gorm.DB) error {
+	return p.Validate()
+}
+
+// AfterCreate is a GORM hook that announces a user arriving on a page, or
+// changing what they do there when the presence is stored again
+func (p *Presence) AfterCreate(tx *gorm.DB) error {
+	return p.announce(tx, ActivityActionUpdated)
+}
+
+// AfterDelete is a GORM hook that announces a user leaving a page
+func (p *Presence) AfterDelete(tx *gorm.DB) error {
+	if p.ConnectionID == "" {
+		return nil
+	}
+	return p.announce(tx, ActivityActionDeleted)
+}
+
+// announce tells the clients following the presence's project, naming the
+// user so that they can show who is there
+func (p *Presence) announce(tx *gorm.DB, action ActivityAction) error {
+	var user User
+	err := tx.Session(&gorm.Session{NewDB: true}).
+		Unscoped().
+		Select("first_name", "last_name").
+		Where("id = ?", p.UserID).
+		Take(&user).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	projectID, userID := p.ProjectID, p.UserID
+	event := &RealtimeEvent{
+		Type:           RealtimeEventPresence,
+		Action:         action,
+		OrganizationID: p.OrganizationID,
+		ProjectID:      &projectID,
+		EntityID:       p.ResourceID,
+		ActorID:        &userID,
+		ActorName:      strings.TrimSpace(user.FullName()),
+		Presence:       p,
+		At:             time.Now(),
+	}
+	if p.ResourceType == PresenceResourceTask {
+		taskID := p.ResourceID
+		event.TaskID = &taskID
+	}
+	return publishRealtime(tx, event)
+}
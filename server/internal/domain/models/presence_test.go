@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPresenceValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		presence Presence
+		err      error
+	}{
+		{"viewing a task", Presence{ResourceType: PresenceResourceTask, Activity: PresenceActivityViewing}, nil},
+		{"typing on a board", Presence{ResourceType: PresenceResourceBoard, Activity: PresenceActivityTyping}, nil},
+		{"editing a task", Presence{ResourceType: PresenceResourceTask, Activity: PresenceActivityEditing}, nil},
+		{"unknown resource", Presence{ResourceType: "sprint", Activity: PresenceActivityViewing}, ErrInvalidPresenceResource},
+		{"unknown activity", Presence{ResourceType: PresenceResourceTask, Activity: "dancing"}, ErrInvalidPresenceActivity},
+		{"no activity", Presence{ResourceType: PresenceResourceTask}, ErrInvalidPresenceActivity},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.presence.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestPresenceCurrentActivity(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	later, earlier := now.Add(time.Second), now.Add(-time.Second)
+
+	tests := []struct {
+		name     string
+		activity PresenceActivity
+		until    *time.Time
+		want     PresenceActivity
+	}{
+		{"viewing", PresenceActivityViewing, nil, PresenceActivityViewing},
+		{"typing", PresenceActivityTyping, &later, PresenceActivityTyping},
+		{"typing lapsed", PresenceActivityTyping, &earlier, PresenceActivityViewing},
+		{"typing lapsing now", PresenceActivityTyping, &now, PresenceActivityViewing},
+		{"editing", PresenceActivityEditing, &later, PresenceActivityEditing},
+		{"editing lapsed", PresenceActivityEditing, &earlier, PresenceActivityViewing},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			presence := Presence{Activity: tt.activity, ActivityUntil: tt.until}
+			if got := presence.CurrentActivity(now); got != tt.want {
+				t.Errorf("CurrentActivity() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,8 +2,10 @@ package models
 
 import (
 	"errors"
+	"strings"
 	"time"
 
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
 	"gorm.io/gorm"
 )
 
@@ -34,12 +36,18 @@ type Project struct {
 	Status        ProjectStatus `json:"status" gorm:"type:varchar(20);default:'planning'"`
 	StartDate     *time.Time    `json:"start_date"`
 	EndDate       *time.Time    `json:"end_date"`
-	Budget        float64       `json:"budget"`
+	Budget        money.Money   `json:"budget" gorm:"embedded;embeddedPrefix:budget_"`
 	OrganizationID uint         `json:"organization_id" gorm:"not null"`
 	Organization   Organization  `json:"-" gorm:"foreignKey:OrganizationID"`
 	Teams          []Team       `json:"teams" gorm:"many2many:team_projects;"`
 	Tasks          []Task       `json:"tasks" gorm:"foreignKey:ProjectID"`
 	Members        []User       `json:"members" gorm:"many2many:project_members;"`
+
+	// Text search configuration the name and description are indexed with
+	SearchLanguage string       `json:"-" gorm:"type:varchar(32);default:'simple'"`
+
+	// original is a copy of the project as it was read from the database
+	original *Project
 }
 
 // ProjectMember represents the many-to-many relationship between projects and users
@@ -47,7 +55,181 @@ type ProjectMember struct {
 	ProjectID uint   `gorm:"primaryKey"`
 	UserID    uint   `gorm:"primaryKey"`
 	Role      string `json:"role" gorm:"default:'member'"` // manager, member
+
+	// original is a copy of the membership as it was read from the database
+	original *ProjectMember
 }
 
 // Validate performs validation on the Project model
- 
\ No newline at end of file
+ 
+func (p *Project) Validate() error {
+	if strings.TrimSpace(p.Name) == "" {
+		return ErrEmptyProjectName
+	}
+
+	if p.OrganizationID == 0 {
+		return ErrMissingOrganization
+	}
+
+	if p.Budget.IsNegative() {
+		return ErrInvalidBudget
+	}
+
+	if p.Budget.Currency != "" && p.Budget.Validate() != nil {
+		return money.ErrInvalidCurrency
+	}
+
+	if p.StartDate != nil && p.EndDate != nil && p.EndDate.Before(*p.StartDate) {
+		return ErrInvalidDateRange
+	}
+
+	return nil
+}
+
+// IsActive checks if the project is currently active
+func (p *Project) IsActive() bool {
+	return p.Status == ProjectStatusActive
+}
+
+// BeforeCreate is a GORM hook that runs before creating a new project
+func (p *Project) BeforeCreate(tx *gorm.DB) error {
+	if p.SearchLanguage == "" {
+		p.SearchLanguage = actorSearchConfig(tx)
+	}
+	if p.Budget.Currency == "" {
+		p.Budget.Currency = money.DefaultCurrency
+	}
+	if err := p.Validate(); err != nil {
+		return err
+	}
+	return checkUsageLimit(tx, p.OrganizationID, UsageLimitProjects)
+}
+
+// BeforeUpdate is a GORM hook that runs before updating a project
+func (p *Project) BeforeUpdate(tx *gorm.DB) error {
+	return p.Validate()
+}
+
+// AfterFind is a GORM hook that keeps a copy of the stored project so that
+// changes can be recorded on the next update
+func (p *Project) AfterFind(tx *gorm.DB) error {
+	p.remember()
+	return nil
+}
+
+// AfterCreate is a GORM hook that records the creation of a project and
+// counts it toward the organization's usage
+func (p *Project) AfterCreate(tx *gorm.DB) error {
+	if err := p.recordActivity(tx, ActivityActionCreated, diffFields(nil, p)); err != nil {
+		return err
+	}
+	p.remember()
+	return syncOrganizationUsage(tx, p.OrganizationID)
+}
+
+// AfterUpdate is a GORM hook that records field changes in the same
+// transaction as the update
+func (p *Project) AfterUpdate(tx *gorm.DB) error {
+	if p.original == nil {
+		return nil
+	}
+	if changes := diffFields(p.original, p); len(changes) > 0 {
+		if err := p.recordActivity(tx, ActivityActionUpdated, changes); err != nil {
+			return err
+		}
+	}
+	p.remember()
+	return nil
+}
+
+// AfterDelete is a GORM hook that records the deletion of a project
+func (p *Project) AfterDelete(tx *gorm.DB) error {
+	if p.ID == 0 {
+		return nil
+	}
+	if err := p.recordActivity(tx, ActivityActionDeleted, deletionChanges(p)); err != nil {
+		return err
+	}
+	return syncOrganizationUsage(tx, p.OrganizationID)
+}
+
+// remember stores a copy of the project's current column values
+func (p *Project) remember() {
+	original := *p
+	original.original = nil
+	p.original = &original
+}
+
+// recordActivity stores an activity event for the project
+func (p *Project) recordActivity(tx *gorm.DB, action ActivityAction, changes FieldChanges) error {
+	projectID := p.ID
+	return recordActivity(tx, &ActivityEvent{
+		OrganizationID: p.OrganizationID,
+		ProjectID:      &projectID,
+		EntityType:     ActivityEntityProject,
+		EntityID:       p.ID,
+		Action:         action,
+		Changes:        changes,
+	})
+}
+
+// AfterFind is a GORM hook that keeps a copy of the stored membership
+func (m *ProjectMember) AfterFind(tx *gorm.DB) error {
+	original := *m
+	original.original = nil
+	m.original = &original
+	return nil
+}
+
+// AfterCreate is a GORM hook that records a user being added to the project
+func (m *ProjectMember) AfterCreate(tx *gorm.DB) error {
+	if err := m.recordActivity(tx, ActivityActionCreated, diffFields(nil, m)); err != nil {
+		return err
+	}
+	orgID, err := projectOrganizationID(tx, m.ProjectID)
+	if err != nil {
+		return err
+	}
+	projectID := m.ProjectID
+	return notifyInvited(tx, m.UserID, "project", "projects", projectID, &Notification{OrganizationID: &orgID, ProjectID: &projectID})
+}
+
+// AfterUpdate is a GORM hook that records role changes
+func (m *ProjectMember) AfterUpdate(tx *gorm.DB) error {
+	if m.original == nil {
+		return nil
+	}
+	changes := diffFields(m.original, m)
+	if len(changes) == 0 {
+		return nil
+	}
+	if err := m.recordActivity(tx, ActivityActionUpdated, changes); err != nil {
+		return err
+	}
+	return m.AfterFind(tx)
+}
+
+// AfterDelete is a GORM hook that records a user being removed from the project
+func (m *ProjectMember) AfterDelete(tx *gorm.DB) error {
+	if m.ProjectID == 0 || m.UserID == 0 {
+		return nil
+	}
+	return m.recordActivity(tx, ActivityActionDeleted, deletionChanges(m))
+}
+
+// recordActivity stores an activity event for the membership
+func (m *ProjectMember) recordActivity(tx *gorm.DB, action ActivityAction, changes FieldChanges) error {
+	orgID, err := projectOrganizationID(tx, m.ProjectID)
+	if err != nil {
+		return err
+	}
+	projectID := m.ProjectID
+	return recordActivity(tx, &ActivityEvent{
+		OrganizationID: orgID,
+		ProjectID:      &projectID,
+		EntityType:     ActivityEntityProjectMember,
+		EntityID:       m.UserID,
+		Action:         action,
+		Changes:        changes,
+	})
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RealtimeChannel is the Postgres channel changes are announced on, so that
+// every server can pass them on to its connected clients
+const RealtimeChannel = "realtime_events"
+
+// realtimePayloadLimit keeps announcements under Postgres' limit of 8000
+// bytes for a notification payload
+const realtimePayloadLimit = 7500
+
+// RealtimeEventNotification is the type of event sent when a notification
+// lands in a user's inbox. Other events take the type of the entity that
+// changed, such as "task" or "comment".
+const RealtimeEventNotification = "notification"
+
+// RealtimeEvent announces a change to the clients following it live. Events
+// with a UserID are only sent to that user; others go to the clients
+// following the event's organization or project.
+type RealtimeEvent struct {
+	Type           string         `json:"type"`
+	Action         ActivityAction `json:"action"`
+	OrganizationID uint           `json:"organization_id,omitempty"`
+	ProjectID      *uint          `json:"project_id,omitempty"`
+	TaskID         *uint          `json:"task_id,omitempty"`
+	EntityID       uint           `json:"entity_id"`
+	UserID         *uint          `json:"user_id,omitempty"`
+	ActorID        *uint          `json:"actor_id,omitempty"`
+	ActorName      string         `json:"actor_name,omitempty"`
+	Changes        FieldChanges   `json:"changes,omitempty"`
+	Notification   *Notification  `json:"notification,omitempty"`
+	Presence       *Presence      `json:"presence,omitempty"`
+	Truncated      bool           `json:"truncated,omitempty"` // the changes were too large to send; fetch the entity instead
+	At             time.Time      `json:"at"`
+}
+
+// publishRealtime announces an event on RealtimeChannel. Postgres delivers
+// the announcement when the transaction of the change commits, and drops it
+// if the change rolls back.
+func publishRealtime(tx *gorm.DB, event *RealtimeEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(payload) > realtimePayloadLimit {
+		trimmed := *event
+		trimmed.Changes = nil
+		trimmed.Truncated = true
+		if payload, err = json.Marshal(&trimmed); err != nil {
+			return err
+		}
+	}
+	return tx.Session(&gorm.Session{NewDB: true}).
+		Exec("SELECT pg_notify(?, ?)", RealtimeChannel, string(payload)).Error
+}
@@ -0,0 +1,113 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Common validation errors
+var (
+	ErrEmptyFilterName           = errors.New("filter name cannot be empty")
+	ErrMissingFilterOwner        = errors.New("filter owner ID is required")
+	ErrInvalidFilterVisibility   = errors.New("filter visibility must be private or organization")
+	ErrInvalidFilterSubscription = errors.New("subscription frequency must be daily or weekly")
+)
+
+// FilterVisibility controls who can see a saved filter
+type FilterVisibility string
+
+const (
+	FilterVisibilityPrivate      FilterVisibility = "private"
+	FilterVisibilityOrganization FilterVisibility = "organization"
+)
+
+// SubscriptionFrequency is how often subscribers are sent a filter's results
+type SubscriptionFrequency string
+
+const (
+	SubscriptionFrequencyDaily  SubscriptionFrequency = "daily"
+	SubscriptionFrequencyWeekly SubscriptionFrequency = "weekly"
+)
+
+// SavedFilter is a named task query. Filters bound to a project may use the
+// project's custom fields; the others search all projects of the organization.
+type SavedFilter struct {
+	gorm.Model
+	OrganizationID uint             `json:"organization_id" gorm:"not null;index"`
+	Organization   Organization     `json:"-" gorm:"foreignKey:OrganizationID"`
+	OwnerID        uint             `json:"owner_id" gorm:"not null;index"`
+	Owner          User             `json:"-" gorm:"foreignKey:OwnerID"`
+	ProjectID      *uint            `json:"project_id" gorm:"index"`
+	Project        *Project         `json:"-" gorm:"foreignKey:ProjectID"`
+	Name           string           `json:"name" gorm:"not null"`
+	Description    string           `json:"description"`
+	Query          string           `json:"query" gorm:"type:text;not null"`
+	Sort           string           `json:"sort"` // comma-separated, as in the sort query parameter
+	Visibility     FilterVisibility `json:"visibility" gorm:"type:varchar(20);default:'private'"`
+}
+
+// FilterSubscription subscribes a user to periodic results of a saved filter
+type FilterSubscription struct {
+	ID         uint                  `json:"id" gorm:"primaryKey"`
+	FilterID   uint                  `json:"filter_id" gorm:"not null;uniqueIndex:idx_filter_subscription"`
+	Filter     SavedFilter           `json:"-" gorm:"foreignKey:FilterID"`
+	UserID     uint                  `json:"user_id" gorm:"not null;uniqueIndex:idx_filter_subscription"`
+	Frequency  SubscriptionFrequency `json:"frequency" gorm:"type:varchar(20);default:'daily'"`
+	LastSentAt *time.Time            `json:"last_sent_at"`
+	CreatedAt  time.Time             `json:"created_at"`
+}
+
+// Validate performs validation on the SavedFilter model
+func (f *SavedFilter) Validate() error {
+	if strings.TrimSpace(f.Name) == "" {
+		return ErrEmptyFilterName
+	}
+
+	if f.OrganizationID == 0 {
+		return ErrMissingOrganization
+	}
+
+	if f.OwnerID == 0 {
+		return ErrMissingFilterOwner
+	}
+
+	switch f.Visibility {
+	case "", FilterVisibilityPrivate, FilterVisibilityOrganization:
+	default:
+		return ErrInvalidFilterVisibility
+	}
+
+	return nil
+}
+
+// IsVisibleTo checks if a member of the filter's organization can see the filter
+func (f *SavedFilter) IsVisibleTo(userID uint) bool {
+	return f.OwnerID == userID || f.Visibility == FilterVisibilityOrganization
+}
+
+// BeforeCreate is a GORM hook that runs before creating a new saved filter
+func (f *SavedFilter) BeforeCreate(tx *gorm.DB) error {
+	return f.Validate()
+}
+
+// BeforeUpdate is a GORM hook that runs before updating a saved filter
+func (f *SavedFilter) BeforeUpdate(tx *gorm.DB) error {
+	return f.Validate()
+}
+
+// Validate performs validation on the FilterSubscription model
+func (s *FilterSubscription) Validate() error {
+	switch s.Frequency {
+	case "", SubscriptionFrequencyDaily, SubscriptionFrequencyWeekly:
+		return nil
+	}
+	return ErrInvalidFilterSubscription
+}
+
+// BeforeSave is a GORM hook that runs before creating or updating a subscription
+func (s *FilterSubscription) BeforeSave(tx *gorm.DB) error {
+	return s.Validate()
+}
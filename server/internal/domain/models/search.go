@@ -0,0 +1,70 @@
+package models
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// DefaultSearchConfig is the text search configuration used when a language
+// has no stemmer. It lowercases words without stemming them.
+const DefaultSearchConfig = "simple"
+
+// searchConfigs maps ISO 639-1 language codes to the Postgres text search
+// configurations shipped with every installation
+var searchConfigs = map[string]string{
+	"ar": "arabic",
+	"da": "danish",
+	"de": "german",
+	"el": "greek",
+	"en": "english",
+	"es": "spanish",
+	"fi": "finnish",
+	"fr": "french",
+	"hu": "hungarian",
+	"id": "indonesian",
+	"it": "italian",
+	"lt": "lithuanian",
+	"nb": "norwegian",
+	"nl": "dutch",
+	"no": "norwegian",
+	"pt": "portuguese",
+	"ro": "romanian",
+	"ru": "russian",
+	"sv": "swedish",
+	"ta": "tamil",
+	"tr": "turkish",
+}
+
+// SearchConfig returns the text search configuration for a language such as
+// "de" or "pt-BR"
+func SearchConfig(language string) string {
+	code := strings.ToLower(language)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	if config, ok := searchConfigs[code]; ok {
+		return config
+	}
+	return DefaultSearchConfig
+}
+
+// actorSearchConfig returns the text search configuration for the language of
+// the user making the change, so content is stemmed in the language it was
+// most likely written in
+func actorSearchConfig(tx *gorm.DB) string {
+	actorID := ActorFromContext(tx.Statement.Context)
+	if actorID == nil {
+		return DefaultSearchConfig
+	}
+
+	var languages []string
+	err := tx.Session(&gorm.Session{NewDB: true}).
+		Model(&User{}).
+		Where("id = ?", *actorID).
+		Pluck("language", &languages).Error
+	if err != nil || len(languages) == 0 {
+		return DefaultSearchConfig
+	}
+	return SearchConfig(languages[0])
+}
@@ -0,0 +1,23 @@
+package models
+
+import "testing"
+
+func TestSearchConfig(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{"en", "english"},
+		{"de", "german"},
+		{"pt-BR", "portuguese"},
+		{"nb_NO", "norwegian"},
+		{"FR", "french"},
+		{"ja", DefaultSearchConfig},
+		{"", DefaultSearchConfig},
+	}
+	for _, tt := range tests {
+		if got := SearchConfig(tt.language); got != tt.want {
+			t.Errorf("SearchConfig(%q) = %q, want %q", tt.language, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Common validation errors
+var (
+	ErrEmptySprintName       = errors.New("sprint name cannot be empty")
+	ErrInvalidSprintDates    = errors.New("sprint end date must be after start date")
+	ErrInvalidEstimationUnit = errors.New("estimation unit must be story_points or hours")
+)
+
+// SprintStatus represents the lifecycle state of a sprint
+type SprintStatus string
+
+const (
+	SprintStatusPlanned   SprintStatus = "planned"
+	SprintStatusActive    SprintStatus = "active"
+	SprintStatusCompleted SprintStatus = "completed"
+)
+
+// EstimationUnit selects which task field is used to size sprint scope
+type EstimationUnit string
+
+const (
+	EstimationUnitStoryPoints EstimationUnit = "story_points"
+	EstimationUnitHours       EstimationUnit = "hours"
+)
+
+// Sprint represents a time-boxed iteration of a project
+type Sprint struct {
+	gorm.Model
+	Name           string         `json:"name" gorm:"not null"`
+	Goal           string         `json:"goal"`
+	Status         SprintStatus   `json:"status" gorm:"type:varchar(20);default:'planned'"`
+	EstimationUnit EstimationUnit `json:"estimation_unit" gorm:"type:varchar(20);default:'story_points'"`
+	StartDate      time.Time      `json:"start_date" gorm:"not null"`
+	EndDate        time.Time      `json:"end_date" gorm:"not null"`
+
+	// Project and team relationship
+	ProjectID uint    `json:"project_id" gorm:"not null;index;uniqueIndex:idx_sprint_active_project,where:status = 'active' AND deleted_at IS NULL"` // one active sprint per project
+	Project   Project `json:"-" gorm:"foreignKey:ProjectID"`
+	TeamID    *uint   `json:"team_id" gorm:"index"`
+	Team      *Team   `json:"-" gorm:"foreignKey:TeamID"`
+
+	// Scope snapshots taken when the sprint starts and completes
+	StartedAt       *time.Time `json:"started_at"`
+	CompletedAt     *time.Time `json:"completed_at"`
+	CommittedPoints float64    `json:"committed_points"`
+	CompletedPoints float64    `json:"completed_points"`
+
+	Tasks []Task       `json:"tasks,omitempty" gorm:"foreignKey:SprintID"`
+	Scope []SprintTask `json:"-" gorm:"foreignKey:SprintID"`
+}
+
+// SprintTask tracks a task's membership in a sprint over time, including
+// whether it was part of the committed scope and whether it was carried over
+type SprintTask struct {
+	ID                uint       `json:"id" gorm:"primaryKey"`
+	SprintID          uint       `json:"sprint_id" gorm:"not null;index"`
+	TaskID            uint       `json:"task_id" gorm:"not null;index"`
+	Task              Task       `json:"-" gorm:"foreignKey:TaskID"`
+	AddedAt           time.Time  `json:"added_at" gorm:"not null"`
+	RemovedAt         *time.Time `json:"removed_at"`
+	Committed         bool       `json:"committed" gorm:"default:false"`
+	CarriedOver       bool       `json:"carried_over" gorm:"default:false"`
+	CarriedOverFromID *uint      `json:"carried_over_from_id"`
+}
+
+// Validate performs validation on the Sprint model
+func (s *Sprint) Validate() error {
+	if strings.TrimSpace(s.Name) == "" {
+		return ErrEmptySprintName
+	}
+
+	if s.ProjectID == 0 {
+		return ErrMissingProject
+	}
+
+	if !s.EndDate.After(s.StartDate) {
+		return ErrInvalidSprintDates
+	}
+
+	switch s.EstimationUnit {
+	case "", EstimationUnitStoryPoints, EstimationUnitHours:
+	default:
+		return ErrInvalidEstimationUnit
+	}
+
+	return nil
+}
+
+// IsActive checks if the sprint is currently running
+func (s *Sprint) IsActive() bool {
+	return s.Status == SprintStatusActive
+}
+
+// IsCompleted checks if the sprint has been closed
+func (s *Sprint) IsCompleted() bool {
+	return s.Status == SprintStatusCompleted
+}
+
+// Unit returns the sprint's estimation unit, defaulting to story points
+func (s *Sprint) Unit() EstimationUnit {
+	if s.EstimationUnit == "" {
+		return EstimationUnitStoryPoints
+	}
+	return s.EstimationUnit
+}
+
+// InScopeAt checks if the task was part of the sprint at the given time
+func (st *SprintTask) InScopeAt(at time.Time) bool {
+	if st.AddedAt.After(at) {
+		return false
+	}
+	return st.RemovedAt == nil || st.RemovedAt.After(at)
+}
+
+// BeforeCreate is a GORM hook that runs before creating a new sprint
+func (s *Sprint) BeforeCreate(tx *gorm.DB) error {
+	return s.Validate()
+}
+
+// BeforeUpdate is a GORM hook that runs before updating a sprint
+func (s *Sprint) BeforeUpdate(tx *gorm.DB) error {
+	return s.Validate()
+}
@@ -2,8 +2,10 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"time"
 
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
 	"gorm.io/gorm"
 )
 
@@ -12,8 +14,19 @@ var (
     ErrInvalidPlanID = errors.New("invalid plan ID")
     ErrInvalidPrice = errors.New("price must be non-negative")
     ErrInvalidDuration = errors.New("invalid subscription duration")
+    ErrEmptyPlanName = errors.New("plan name cannot be empty")
+    ErrInvalidBillingInterval = errors.New("billing interval must be monthly or yearly")
+    ErrInvalidPlanLimits = errors.New("plan limits and trial days must be non-negative")
+    ErrInvalidSubscriptionStatus = errors.New("invalid subscription status")
+    ErrInvalidPaymentMethod = errors.New("invalid payment method")
+    ErrInvalidStatusTransition = errors.New("subscription cannot move to this status")
+    ErrInvalidSeats = errors.New("a subscription needs at least one seat")
+    ErrInvalidDunningStatus = errors.New("invalid dunning status")
 )
 
+// FreeStorageLimit is the storage, in GB, of an organization without an active subscription
+const FreeStorageLimit = 5
+
 // SubscriptionStatus represents the current status of a subscription
 type SubscriptionStatus string
 
@@ -24,6 +37,48 @@ const (
     SubscriptionStatusPending   SubscriptionStatus = "pending"
 )
 
+// subscriptionTransitions lists the statuses each status can move to. A
+// pending subscription is scheduled to start at its StartDate; an inactive one
+// has lapsed, for instance after a trial ended without a payment method.
+// Cancelled subscriptions are kept as history.
+var subscriptionTransitions = map[SubscriptionStatus][]SubscriptionStatus{
+    SubscriptionStatusPending:   {SubscriptionStatusActive, SubscriptionStatusCancelled},
+    SubscriptionStatusActive:    {SubscriptionStatusInactive, SubscriptionStatusCancelled},
+    SubscriptionStatusInactive:  {SubscriptionStatusActive, SubscriptionStatusCancelled},
+    SubscriptionStatusCancelled: {},
+}
+
+// IsValid checks if the status is a known subscription status
+func (s SubscriptionStatus) IsValid() bool {
+    _, ok := subscriptionTransitions[s]
+    return ok
+}
+
+// CanTransitionTo checks if a subscription can move from this status to another
+func (s SubscriptionStatus) CanTransitionTo(to SubscriptionStatus) bool {
+    for _, next := range subscriptionTransitions[s] {
+        if next == to {
+            return true
+        }
+    }
+    return false
+}
+
+// DunningStatus tells how far the recovery of an active subscription's
+// overdue invoices has gone
+type DunningStatus string
+
+const (
+    DunningStatusNone     DunningStatus = "none"
+    DunningStatusPastDue  DunningStatus = "past_due"  // an invoice is overdue; payment is retried and reminders sent
+    DunningStatusReadOnly DunningStatus = "read_only" // the grace period is over; the organization cannot make changes
+)
+
+// IsValid checks if the status is a known dunning status
+func (s DunningStatus) IsValid() bool {
+    return s == DunningStatusNone || s == DunningStatusPastDue || s == DunningStatusReadOnly
+}
+
 // BillingInterval represents the billing frequency
 type BillingInterval string
 
@@ -32,12 +87,41 @@ const (
     BillingIntervalYearly  BillingInterval = "yearly"
 )
 
+// IsValid checks if the interval is a known billing interval
+func (i BillingInterval) IsValid() bool {
+    return i == BillingIntervalMonthly || i == BillingIntervalYearly
+}
+
+// Months returns the length of the interval in months
+func (i BillingInterval) Months() int {
+    if i == BillingIntervalYearly {
+        return 12
+    }
+    return 1
+}
+
+// AddBillingPeriods returns the time n billing periods after anchor. Days
+// past the end of a shorter month are clamped to its last day, so a
+// subscription anchored on January 31 renews on February 28 (or 29 in a leap
+// year) and then on March 31.
+func AddBillingPeriods(anchor time.Time, interval BillingInterval, n int) time.Time {
+    year, month, day := anchor.Date()
+    first := time.Date(year, month+time.Month(n*interval.Months()), 1, 0, 0, 0, 0, anchor.Location())
+    if last := first.AddDate(0, 1, -1).Day(); day > last {
+        day = last
+    }
+    return time.Date(first.Year(), first.Month(), day,
+        anchor.Hour(), anchor.Minute(), anchor.Second(), anchor.Nanosecond(), anchor.Location())
+}
+
 // Plan represents a subscription plan with its features
 type Plan struct {
     gorm.Model
     Name           string  `json:"name" gorm:"not null"`
     Description    string  `json:"description"`
-    Price         float64 `json:"price" gorm:"not null"`
+    Price         money.Money `json:"price" gorm:"embedded;embeddedPrefix:price_"`
+    SeatPrice     money.Money `json:"seat_price" gorm:"embedded;embeddedPrefix:seat_price_"` // per seat beyond IncludedSeats, per interval
+    IncludedSeats int         `json:"included_seats" gorm:"not null;default:0"`
     BillingInterval BillingInterval `json:"billing_interval" gorm:"type:varchar(20);default:'monthly'"`
     Features      []PlanFeature `json:"features" gorm:"foreignKey:PlanID"`
     
@@ -50,12 +134,19 @@ type Plan struct {
     CustomDomain  bool `json:"custom_domain" gorm:"default:false"`
     APIAccess     bool `json:"api_access" gorm:"default:false"`
     Priority      bool `json:"priority_support" gorm:"default:false"`
+
+    // Catalog settings
+    TrialDays     int        `json:"trial_days" gorm:"not null;default:0"` // 0 means no trial
+    ArchivedAt    *time.Time `json:"archived_at"` // archived plans cannot be chosen but keep their subscribers
 }
 
-// PlanFeature represents a feature available in a plan
+// PlanFeature represents a feature available in a plan. Features with a Key
+// from the feature registry grant the organization that feature; the others
+// only describe the plan.
 type PlanFeature struct {
     gorm.Model
     PlanID      uint   `json:"plan_id" gorm:"not null"`
+    Key         string `json:"key" gorm:"type:varchar(50)"`
     Name        string `json:"name" gorm:"not null"`
     Description string `json:"description"`
     Included    bool   `json:"included" gorm:"default:true"`
@@ -64,7 +155,8 @@ type PlanFeature struct {
 // Subscription represents an organization's subscription to a plan
 type Subscription struct {
     gorm.Model
-    OrganizationID uint              `json:"organization_id" gorm:"not null"`
+    // An organization has at most one current (active or inactive) and one scheduled subscription
+    OrganizationID uint              `json:"organization_id" gorm:"not null;uniqueIndex:idx_subscription_current,where:status <> 'pending' AND status <> 'cancelled' AND deleted_at IS NULL;uniqueIndex:idx_subscription_scheduled,where:status = 'pending' AND deleted_at IS NULL"`
     Organization   Organization      `json:"-" gorm:"foreignKey:OrganizationID"`
     PlanID         uint              `json:"plan_id" gorm:"not null"`
     Plan           Plan              `json:"plan" gorm:"foreignKey:PlanID"`
@@ -75,6 +167,9 @@ type Subscription struct {
     EndDate        time.Time         `json:"end_date"`
     TrialEndsAt    *time.Time        `json:"trial_ends_at"`
     
+    // Seats billed on the subscription
+    Seats          int               `json:"seats" gorm:"not null;default:1"`
+
     // Payment info
     PaymentMethod  string            `json:"payment_method"`
     LastBilledAt   *time.Time        `json:"last_billed_at"`
@@ -84,6 +179,106 @@ type Subscription struct {
     CurrentUsers   int               `json:"current_users"`
     CurrentProjects int             `json:"current_projects"`
     CurrentStorage  float64         `json:"current_storage"` // in GB
+
+    // Renewal. Periods are counted from BillingAnchor; EndDate is the end of
+    // the current period. NextBillingAt is unset once the subscription will
+    // not renew.
+    BillingAnchor      time.Time  `json:"billing_anchor"`
+    CurrentPeriodStart time.Time  `json:"current_period_start"`
+    CancelAtPeriodEnd  bool       `json:"cancel_at_period_end" gorm:"default:false"`
+    CancelledAt        *time.Time `json:"cancelled_at"`
+
+    // Dunning. PastDueSince is the due date of the oldest overdue invoice and
+    // GraceEndsAt when the organization becomes read-only unless it is paid.
+    DunningStatus      DunningStatus `json:"dunning_status" gorm:"type:varchar(20);not null;default:'none'"`
+    PastDueSince       *time.Time    `json:"past_due_since"`
+    GraceEndsAt        *time.Time    `json:"grace_ends_at"`
+    NextPaymentRetryAt *time.Time    `json:"next_payment_retry_at"`
+}
+
+// Validate performs validation on the Plan model
+func (p *Plan) Validate() error {
+    if p.Name == "" {
+        return ErrEmptyPlanName
+    }
+
+    if err := p.Price.Validate(); err != nil {
+        return err
+    }
+
+    if p.Price.IsNegative() {
+        return ErrInvalidPrice
+    }
+
+    if !p.BillingInterval.IsValid() {
+        return ErrInvalidBillingInterval
+    }
+
+    if !p.SeatPrice.IsZero() {
+        if !p.SeatPrice.SameCurrency(p.Price) {
+            return money.ErrCurrencyMismatch
+        }
+        if p.SeatPrice.IsNegative() {
+            return ErrInvalidPrice
+        }
+    }
+
+    if p.MaxUsers < 0 || p.MaxProjects < 0 || p.MaxStorage < 0 || p.TrialDays < 0 || p.IncludedSeats < 0 {
+        return ErrInvalidPlanLimits
+    }
+
+    keys := make(map[string]bool)
+    for _, feature := range p.Features {
+        if feature.Key == "" {
+            continue
+        }
+        if !IsKnownFeature(feature.Key) {
+            return fmt.Errorf("%w: %s", ErrUnknownFeature, feature.Key)
+        }
+        if keys[feature.Key] {
+            return fmt.Errorf("%w: %s", ErrDuplicateFeature, feature.Key)
+        }
+        keys[feature.Key] = true
+    }
+
+    return nil
+}
+
+// ExtraSeats returns how many of the seats are billed beyond those the plan includes
+func (p *Plan) ExtraSeats(seats int) int {
+    if seats <= p.IncludedSeats {
+        return 0
+    }
+    return seats - p.IncludedSeats
+}
+
+// AllowsSeats checks that the seats fit within the plan's user limit, where 0 means no limit
+func (p *Plan) AllowsSeats(seats int) bool {
+    return p.MaxUsers == 0 || seats <= p.MaxUsers
+}
+
+// IsArchived checks if the plan has been withdrawn from the catalog
+func (p *Plan) IsArchived() bool {
+    return p.ArchivedAt != nil
+}
+
+// AnnualPrice returns the price of a year on the plan in minor units, for
+// comparing plans with different billing intervals
+func (p *Plan) AnnualPrice() int64 {
+    return p.Price.Minor * int64(12/p.BillingInterval.Months())
+}
+
+// BeforeSave is a GORM hook that validates a plan before it is stored
+func (p *Plan) BeforeSave(tx *gorm.DB) error {
+    return p.Validate()
+}
+
+// AfterUpdate is a GORM hook that updates the feature flags of organizations
+// subscribed to the plan
+func (p *Plan) AfterUpdate(tx *gorm.DB) error {
+    return syncOrganizationFeatures(tx,
+        "organizations.id IN (SELECT organization_id FROM subscriptions WHERE plan_id = @plan AND status = 'active' AND deleted_at IS NULL)",
+        map[string]interface{}{"plan": p.ID})
 }
 
 // Validate performs validation on the Subscription model
@@ -100,9 +295,85 @@ func (s *Subscription) Validate() error {
         return ErrInvalidDuration
     }
 
+    if !s.Status.IsValid() {
+        return ErrInvalidSubscriptionStatus
+    }
+
+    if s.Seats < 1 {
+        return ErrInvalidSeats
+    }
+
+    if !s.DunningStatus.IsValid() {
+        return ErrInvalidDunningStatus
+    }
+
+    switch PaymentMethod(s.PaymentMethod) {
+    case "", PaymentMethodCard, PaymentMethodPayPal, PaymentMethodBank:
+    default:
+        return ErrInvalidPaymentMethod
+    }
+
     return nil
 }
 
+// IsPeriodBilled checks if the current period has been invoiced
+func (s *Subscription) IsPeriodBilled() bool {
+    return s.LastBilledAt != nil && !s.LastBilledAt.Before(s.CurrentPeriodStart)
+}
+
+// TransitionTo moves the subscription to another status, recording when it was cancelled
+func (s *Subscription) TransitionTo(status SubscriptionStatus, at time.Time) error {
+    if !s.Status.CanTransitionTo(status) {
+        return fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, s.Status, status)
+    }
+    s.Status = status
+    switch status {
+    case SubscriptionStatusCancelled:
+        s.CancelledAt = &at
+        s.NextBillingAt = nil
+    case SubscriptionStatusInactive:
+        s.NextBillingAt = nil
+    }
+    return nil
+}
+
+// StartPeriod makes the billing period that begins at start current and
+// schedules its renewal, unless the subscription is set to end with it
+func (s *Subscription) StartPeriod(start time.Time, interval BillingInterval) {
+    s.CurrentPeriodStart = start
+    s.EndDate = s.PeriodEndAfter(start, interval)
+    s.NextBillingAt = nil
+    if !s.CancelAtPeriodEnd {
+        next := s.EndDate
+        s.NextBillingAt = &next
+    }
+}
+
+// PeriodEndAfter returns the first period boundary, counted from the billing
+// anchor, that comes after t
+func (s *Subscription) PeriodEndAfter(t time.Time, interval BillingInterval) time.Time {
+    anchor := s.BillingAnchor
+    if anchor.IsZero() {
+        anchor = t
+    }
+    // Start close to t rather than walking every period since the anchor
+    n := 1
+    if elapsed := monthsBetween(anchor, t) / interval.Months(); elapsed > 1 {
+        n = elapsed - 1
+    }
+    end := AddBillingPeriods(anchor, interval, n)
+    for !end.After(t) {
+        n++
+        end = AddBillingPeriods(anchor, interval, n)
+    }
+    return end
+}
+
+// monthsBetween counts the calendar months from a to b, ignoring days
+func monthsBetween(a, b time.Time) int {
+    return (b.Year()-a.Year())*12 + int(b.Month()) - int(a.Month())
+}
+
 // IsActive checks if the subscription is currently active
 func (s *Subscription) IsActive() bool {
     return s.Status == SubscriptionStatusActive && time.Now().Before(s.EndDate)
@@ -113,29 +384,143 @@ func (s *Subscription) IsTrialing() bool {
     return s.TrialEndsAt != nil && time.Now().Before(*s.TrialEndsAt)
 }
 
-// HasFeature checks if the subscription's plan includes a specific feature
-func (s *Subscription) HasFeature(featureName string) bool {
+// HasFeature checks if the subscription's plan includes a feature, given by
+// its registry key. The features with a plan flag are granted by the flag.
+func (s *Subscription) HasFeature(key string) bool {
+    switch key {
+    case FeatureCustomDomain:
+        return s.Plan.CustomDomain
+    case FeatureAPIAccess:
+        return s.Plan.APIAccess
+    case FeaturePrioritySupport:
+        return s.Plan.Priority
+    }
     for _, feature := range s.Plan.Features {
-        if feature.Name == featureName && feature.Included {
+        if feature.Key == key && feature.Included {
             return true
         }
     }
     return false
 }
 
-// WithinLimits checks if the organization is within the plan's limits
+// WithinLimits checks if the organization is within the plan's limits, where
+// a user or project limit of 0 means no limit
 func (s *Subscription) WithinLimits() bool {
-    return s.CurrentUsers <= s.Plan.MaxUsers &&
-           s.CurrentProjects <= s.Plan.MaxProjects &&
+    return (s.Plan.MaxUsers == 0 || s.CurrentUsers <= s.Plan.MaxUsers) &&
+           (s.Plan.MaxProjects == 0 || s.CurrentProjects <= s.Plan.MaxProjects) &&
            s.CurrentStorage <= float64(s.Plan.MaxStorage)
 }
 
 // BeforeCreate is a GORM hook that runs before creating a new subscription
 func (s *Subscription) BeforeCreate(tx *gorm.DB) error {
+    if s.DunningStatus == "" {
+        s.DunningStatus = DunningStatusNone
+    }
     return s.Validate()
 }
 
 // BeforeUpdate is a GORM hook that runs before updating a subscription
 func (s *Subscription) BeforeUpdate(tx *gorm.DB) error {
     return s.Validate()
+}
+
+// AfterSave is a GORM hook that keeps the organization's feature flags in
+// line with the plan of its active subscription, and its usage counts, which
+// a new subscription starts without, up to date
+func (s *Subscription) AfterSave(tx *gorm.DB) error {
+    if err := syncOrganizationFeatures(tx, "organizations.id = @org", map[string]interface{}{"org": s.OrganizationID}); err != nil {
+        return err
+    }
+    return syncOrganizationUsage(tx, s.OrganizationID)
+}
+
+// IsReadOnly checks if the grace period for the subscription's overdue
+// invoices is over
+func (s *Subscription) IsReadOnly() bool {
+    return s.DunningStatus == DunningStatusReadOnly
+}
+
+// SetDunning records that the subscription's oldest overdue invoice was due
+// at pastDueSince. The subscription is read-only once graceEnds has passed.
+func (s *Subscription) SetDunning(pastDueSince, graceEnds time.Time, nextRetry *time.Time, now time.Time) {
+    s.DunningStatus = DunningStatusPastDue
+    if !now.Before(graceEnds) {
+        s.DunningStatus = DunningStatusReadOnly
+    }
+    s.PastDueSince = &pastDueSince
+    s.GraceEndsAt = &graceEnds
+    s.NextPaymentRetryAt = nextRetry
+}
+
+// liftDunning ends the dunning of a subscription once none of its invoices
+// is overdue any more, and makes its organization writable again
+func liftDunning(tx *gorm.DB, subscriptionID uint, now time.Time) error {
+    args := map[string]interface{}{
+        "subscription": subscriptionID,
+        "none":         DunningStatusNone,
+        "unpaid":       payableStatuses,
+        "now":          now,
+    }
+    result := tx.Session(&gorm.Session{NewDB: true}).Exec(`
+UPDATE subscriptions SET
+    dunning_status = @none,
+    past_due_since = NULL,
+    grace_ends_at = NULL,
+    next_payment_retry_at = NULL
+WHERE id = @subscription AND dunning_status <> @none AND NOT EXISTS (
+    SELECT 1 FROM invoices
+    WHERE invoices.subscription_id = subscriptions.id
+        AND invoices.status IN @unpaid
+        AND invoices.amount_minor > 0
+        AND invoices.due_date < @now
+        AND invoices.deleted_at IS NULL
+)`, args)
+    if result.Error != nil || result.RowsAffected == 0 {
+        return result.Error
+    }
+    return syncOrganizationFeatures(tx,
+        "organizations.id = (SELECT organization_id FROM subscriptions WHERE id = @subscription)",
+        map[string]interface{}{"subscription": subscriptionID})
+}
+
+// syncOrganizationFeatures sets the feature flags and storage limit of the
+// matching organizations from the plan of their active subscription, or to
+// the free defaults when they have none. A feature override active at
+// args["now"], or at the current time when it is not set, decides its flag
+// over the plan. Organizations whose subscription is read-only for an unpaid
+// invoice are marked read-only.
+func syncOrganizationFeatures(tx *gorm.DB, where string, args map[string]interface{}) error {
+    args["free_storage"] = FreeStorageLimit
+    args["custom_domain"] = FeatureCustomDomain
+    args["api_access"] = FeatureAPIAccess
+    if _, ok := args["now"]; !ok {
+        args["now"] = time.Now()
+    }
+    return tx.Session(&gorm.Session{NewDB: true}).Exec(`
+UPDATE organizations SET
+    custom_domain_enabled = COALESCE(override.custom_domain, plan.custom_domain, false),
+    api_access_enabled = COALESCE(override.api_access, plan.api_access, false),
+    storage_limit = COALESCE(plan.max_storage, @free_storage),
+    read_only = COALESCE(plan.read_only, false)
+FROM organizations AS org
+LEFT JOIN LATERAL (
+    SELECT plans.custom_domain, plans.api_access, plans.max_storage,
+        subscriptions.dunning_status = 'read_only' AS read_only
+    FROM subscriptions
+    JOIN plans ON plans.id = subscriptions.plan_id
+    WHERE subscriptions.organization_id = org.id
+        AND subscriptions.status = 'active'
+        AND subscriptions.deleted_at IS NULL
+    ORDER BY subscriptions.start_date DESC
+    LIMIT 1
+) AS plan ON true
+LEFT JOIN LATERAL (
+    SELECT
+        bool_or(feature_overrides.enabled) FILTER (WHERE feature_overrides.feature = @custom_domain) AS custom_domain,
+        bool_or(feature_overrides.enabled) FILTER (WHERE feature_overrides.feature = @api_access) AS api_access
+    FROM feature_overrides
+    WHERE feature_overrides.organization_id = org.id
+        AND (feature_overrides.expires_at IS NULL OR feature_overrides.expires_at > @now)
+) AS override ON true
+WHERE org.id = organizations.id AND `+where, args).Error
 } 
\ No newline at end of file
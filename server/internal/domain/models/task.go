@@ -15,6 +15,7 @@ var (
 	ErrMissingCreator = errors.New("creator ID is required")
 	ErrInvalidHours = errors.New("hours must be non-negative")
 	ErrInvalidTaskDates = errors.New("completion date must be after start date")
+	ErrInvalidStoryPoints = errors.New("story points must be non-negative")
 )
 
 // TaskPriority represents the priority level of a task
@@ -62,13 +63,27 @@ type Task struct {
 	Subtasks    []Task       `json:"subtasks" gorm:"foreignKey:ParentID"`
 	
 	// Related entities
-	Comments    []Comment    `json:"comments" gorm:"foreignKey:TaskID"`
+	Comments     []Comment          `json:"comments" gorm:"foreignKey:TaskID"`
+	Labels       []Label            `json:"labels" gorm:"many2many:task_labels;"`
+	CustomFields []CustomFieldValue `json:"custom_fields" gorm:"foreignKey:TaskID"`
+	Attachments  []Attachment       `json:"attachments,omitempty" gorm:"foreignKey:TaskID"`
+	
+	// Sprint planning
+	SprintID    *uint        `json:"sprint_id" gorm:"index"`
+	Sprint      *Sprint      `json:"-" gorm:"foreignKey:SprintID"`
+	StoryPoints float32      `json:"story_points"`
 	
 	// Time tracking
 	EstimatedHours float32   `json:"estimated_hours"`
-	ActualHours    float32   `json:"actual_hours"`
+	ActualHours    float32   `json:"actual_hours" gorm:"<-:false"` // derived from time entries
 	StartedAt      *time.Time `json:"started_at"`
 	CompletedAt    *time.Time `json:"completed_at"`
+	
+	// Text search configuration the title and description are indexed with
+	SearchLanguage string    `json:"-" gorm:"type:varchar(32);default:'simple'"`
+
+	// original is a copy of the task as it was read from the database
+	original *Task
 }
 
 // Validate performs validation on the Task model
@@ -89,6 +104,10 @@ func (t *Task) Validate() error {
 		return ErrInvalidHours
 	}
 
+	if t.StoryPoints < 0 {
+		return ErrInvalidStoryPoints
+	}
+
 	if err := t.validateDates(); err != nil {
 		return err
 	}
@@ -118,10 +137,148 @@ func (t *Task) IsInProgress() bool {
 
 // BeforeCreate is a GORM hook that runs before creating a new task
 func (t *Task) BeforeCreate(tx *gorm.DB) error {
+	if t.SearchLanguage == "" {
+		t.SearchLanguage = actorSearchConfig(tx)
+	}
 	return t.Validate()
 }
 
+// Estimate returns the task's size in the given estimation unit
+func (t *Task) Estimate(unit EstimationUnit) float64 {
+	if unit == EstimationUnitHours {
+		return float64(t.EstimatedHours)
+	}
+	return float64(t.StoryPoints)
+}
+
 // BeforeUpdate is a GORM hook that runs before updating a task
 func (t *Task) BeforeUpdate(tx *gorm.DB) error {
 	return t.Validate()
-} 
\ No newline at end of file
+}
+
+// AfterFind is a GORM hook that keeps a copy of the stored task so that
+// changes can be recorded on the next update
+func (t *Task) AfterFind(tx *gorm.DB) error {
+	t.remember()
+	return nil
+}
+
+// AfterCreate is a GORM hook that records the task's initial status and a
+// creation event, and has its creator watch it
+func (t *Task) AfterCreate(tx *gorm.DB) error {
+	if err := t.recordStatusChange(tx, ""); err != nil {
+		return err
+	}
+	if err := t.recordActivity(tx, ActivityActionCreated, diffFields(nil, t)); err != nil {
+		return err
+	}
+	if err := watchTask(tx, t.ID, t.CreatedByID); err != nil {
+		return err
+	}
+	if t.AssigneeID != nil {
+		if err := t.notifyAssignee(tx); err != nil {
+			return err
+		}
+	}
+	t.remember()
+	return nil
+}
+
+// AfterUpdate is a GORM hook that records status transitions and field
+// changes in the same transaction as the update
+func (t *Task) AfterUpdate(tx *gorm.DB) error {
+	if t.original == nil {
+		return nil
+	}
+
+	if t.original.Status != t.Status {
+		if err := t.recordStatusChange(tx, t.original.Status); err != nil {
+			return err
+		}
+	}
+
+	if changes := diffFields(t.original, t); len(changes) > 0 {
+		if err := t.recordActivity(tx, ActivityActionUpdated, changes); err != nil {
+			return err
+		}
+	}
+
+	if t.AssigneeID != nil && (t.original.AssigneeID == nil || *t.original.AssigneeID != *t.AssigneeID) {
+		if err := t.notifyAssignee(tx); err != nil {
+			return err
+		}
+	}
+
+	t.remember()
+	return nil
+}
+
+// AfterDelete is a GORM hook that records the deletion of a task
+func (t *Task) AfterDelete(tx *gorm.DB) error {
+	if t.ID == 0 {
+		return nil
+	}
+	return t.recordActivity(tx, ActivityActionDeleted, deletionChanges(t))
+}
+
+// remember stores a copy of the task's current column values
+func (t *Task) remember() {
+	original := *t
+	original.original = nil
+	t.original = &original
+}
+
+// recordStatusChange stores a TaskStatusChange from the given status to the current one
+func (t *Task) recordStatusChange(tx *gorm.DB, from TaskStatus) error {
+	change := &TaskStatusChange{
+		TaskID:      t.ID,
+		FromStatus:  from,
+		ToStatus:    t.Status,
+		ChangedByID: ActorFromContext(tx.Statement.Context),
+		ChangedAt:   time.Now(),
+	}
+	return tx.Session(&gorm.Session{NewDB: true}).Create(change).Error
+}
+
+// notifyAssignee tells the task's assignee it was assigned to them, and has
+// them watch it
+func (t *Task) notifyAssignee(tx *gorm.DB) error {
+	if err := watchTask(tx, t.ID, *t.AssigneeID); err != nil {
+		return err
+	}
+	orgID, err := projectOrganizationID(tx, t.ProjectID)
+	if err != nil {
+		return err
+	}
+	message, err := notificationMessage(tx, "assigned you to \"%s\"", "You were assigned to \"%s\"", t.Title)
+	if err != nil {
+		return err
+	}
+	projectID, taskID := t.ProjectID, t.ID
+	return Notify(tx, &Notification{
+		UserID:         *t.AssigneeID,
+		Type:           NotificationTypeAssigned,
+		OrganizationID: &orgID,
+		ProjectID:      &projectID,
+		TaskID:         &taskID,
+		Message:        message,
+	})
+}
+
+// recordActivity stores an activity event for the task
+func (t *Task) recordActivity(tx *gorm.DB, action ActivityAction, changes FieldChanges) error {
+	orgID, err := projectOrganizationID(tx, t.ProjectID)
+	if err != nil {
+		return err
+	}
+	projectID, taskID := t.ProjectID, t.ID
+	return recordActivity(tx, &ActivityEvent{
+		OrganizationID: orgID,
+		ProjectID:      &projectID,
+		TaskID:         &taskID,
+		EntityType:     ActivityEntityTask,
+		EntityID:       t.ID,
+		Action:         action,
+		Changes:        changes,
+	})
+}
@@ -0,0 +1,14 @@
+package models
+
+import "time"
+
+// TaskStatusChange is an immutable record of a task moving between statuses.
+// It is written by the Task hooks and drives burndown and velocity reports.
+type TaskStatusChange struct {
+	ID          uint       `json:"id" gorm:"primaryKey"`
+	TaskID      uint       `json:"task_id" gorm:"not null;index"`
+	FromStatus  TaskStatus `json:"from_status" gorm:"type:varchar(20)"`
+	ToStatus    TaskStatus `json:"to_status" gorm:"type:varchar(20);not null"`
+	ChangedByID *uint      `json:"changed_by_id"`
+	ChangedAt   time.Time  `json:"changed_at" gorm:"not null;index"`
+}
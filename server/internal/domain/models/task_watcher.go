@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TaskWatcher follows a task, for the comments on it in their digest. Users
+// watch the tasks they create, are assigned or comment on, and can watch
+// or stop watching any task they can see.
+type TaskWatcher struct {
+	TaskID    uint      `json:"task_id" gorm:"primaryKey"`
+	UserID    uint      `json:"user_id" gorm:"primaryKey;index"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// watchTask makes a user watch a task, if they do not already
+func watchTask(tx *gorm.DB, taskID, userID uint) error {
+	if taskID == 0 || userID == 0 {
+		return nil
+	}
+	return tx.Session(&gorm.Session{NewDB: true}).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&TaskWatcher{TaskID: taskID, UserID: userID, CreatedAt: time.Now()}).Error
+}
@@ -18,10 +18,84 @@ type TeamMember struct {
     TeamID  uint   `gorm:"primaryKey"`
     UserID  uint   `gorm:"primaryKey"`
     Role    string `json:"role" gorm:"default:'member'"` // lead, member
+
+    // original is a copy of the membership as it was read from the database
+    original *TeamMember
 }
 
 // TeamProject represents the many-to-many relationship between teams and projects
 type TeamProject struct {
     TeamID    uint `gorm:"primaryKey"`
     ProjectID uint `gorm:"primaryKey"`
-} 
\ No newline at end of file
+} 
+
+// AfterFind is a GORM hook that keeps a copy of the stored membership
+func (m *TeamMember) AfterFind(tx *gorm.DB) error {
+    original := *m
+    original.original = nil
+    m.original = &original
+    return nil
+}
+
+// AfterCreate is a GORM hook that records a user joining the team
+func (m *TeamMember) AfterCreate(tx *gorm.DB) error {
+    if err := m.recordActivity(tx, ActivityActionCreated, diffFields(nil, m)); err != nil {
+        return err
+    }
+    var orgID uint
+    err := tx.Session(&gorm.Session{NewDB: true}).
+        Model(&Team{}).
+        Unscoped().
+        Select("organization_id").
+        Where("id = ?", m.TeamID).
+        Scan(&orgID).Error
+    if err != nil {
+        return err
+    }
+    return notifyInvited(tx, m.UserID, "team", "teams", m.TeamID, &Notification{OrganizationID: &orgID})
+}
+
+// AfterUpdate is a GORM hook that records role changes
+func (m *TeamMember) AfterUpdate(tx *gorm.DB) error {
+    if m.original == nil {
+        return nil
+    }
+    changes := diffFields(m.original, m)
+    if len(changes) == 0 {
+        return nil
+    }
+    if err := m.recordActivity(tx, ActivityActionUpdated, changes); err != nil {
+        return err
+    }
+    return m.AfterFind(tx)
+}
+
+// AfterDelete is a GORM hook that records a user leaving the team
+func (m *TeamMember) AfterDelete(tx *gorm.DB) error {
+    if m.TeamID == 0 || m.UserID == 0 {
+        return nil
+    }
+    return m.recordActivity(tx, ActivityActionDeleted, deletionChanges(m))
+}
+
+// recordActivity stores an activity event for the membership on the team's organization feed
+func (m *TeamMember) recordActivity(tx *gorm.DB, action ActivityAction, changes FieldChanges) error {
+    var orgID uint
+    err := tx.Session(&gorm.Session{NewDB: true}).
+        Model(&Team{}).
+        Unscoped().
+        Select("organization_id").
+        Where("id = ?", m.TeamID).
+        Scan(&orgID).Error
+    if err != nil {
+        return err
+    }
+
+    return recordActivity(tx, &ActivityEvent{
+        OrganizationID: orgID,
+        EntityType:     ActivityEntityTeamMember,
+        EntityID:       m.UserID,
+        Action:         action,
+        Changes:        changes,
+    })
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Common validation errors
+var (
+	ErrInvalidEntryTimes = errors.New("time entry must end after it starts")
+	ErrMissingEntryUser  = errors.New("time entry user ID is required")
+)
+
+// TimeEntry is time a user spent on a task, either tracked with a timer or
+// entered manually. An entry without an end is a running timer.
+type TimeEntry struct {
+	gorm.Model
+	OrganizationID uint       `json:"organization_id" gorm:"not null;index"`
+	TaskID         uint       `json:"task_id" gorm:"not null;index"`
+	Task           Task       `json:"-" gorm:"foreignKey:TaskID"`
+	ProjectID      uint       `json:"project_id" gorm:"not null;index"`
+	UserID         uint       `json:"user_id" gorm:"not null;index:idx_time_entry_user_start,priority:1;uniqueIndex:idx_time_entry_running,where:ended_at IS NULL AND deleted_at IS NULL"`
+	User           User       `json:"-" gorm:"foreignKey:UserID"`
+	StartedAt      time.Time  `json:"started_at" gorm:"not null;index:idx_time_entry_user_start,priority:2"`
+	EndedAt        *time.Time `json:"ended_at"`
+	Duration       int64      `json:"duration"` // in seconds, set when the entry ends
+	Billable       bool       `json:"billable" gorm:"default:false"`
+	Notes          string     `json:"notes"`
+	Manual         bool       `json:"manual" gorm:"default:false"`
+
+	// TimesheetID is set while the entry belongs to a submitted or approved timesheet
+	TimesheetID *uint `json:"timesheet_id" gorm:"index"`
+}
+
+// Validate performs validation on the TimeEntry model
+func (e *TimeEntry) Validate() error {
+	if e.TaskID == 0 {
+		return ErrMissingTask
+	}
+
+	if e.UserID == 0 {
+		return ErrMissingEntryUser
+	}
+
+	if e.EndedAt != nil && !e.EndedAt.After(e.StartedAt) {
+		return ErrInvalidEntryTimes
+	}
+
+	return nil
+}
+
+// IsRunning checks if the entry is a running timer
+func (e *TimeEntry) IsRunning() bool {
+	return e.EndedAt == nil
+}
+
+// Hours returns the tracked duration in hours
+func (e *TimeEntry) Hours() float64 {
+	return float64(e.Duration) / 3600
+}
+
+// BeforeSave is a GORM hook that validates the entry and derives its duration
+func (e *TimeEntry) BeforeSave(tx *gorm.DB) error {
+	if err := e.Validate(); err != nil {
+		return err
+	}
+	e.Duration = 0
+	if e.EndedAt != nil {
+		e.Duration = int64(e.EndedAt.Sub(e.StartedAt).Seconds())
+	}
+	return nil
+}
+
+// AfterSave is a GORM hook that keeps the task's actual hours in sync
+func (e *TimeEntry) AfterSave(tx *gorm.DB) error {
+	return syncActualHours(tx, e.TaskID)
+}
+
+// AfterDelete is a GORM hook that keeps the task's actual hours in sync
+func (e *TimeEntry) AfterDelete(tx *gorm.DB) error {
+	return syncActualHours(tx, e.TaskID)
+}
+
+// syncActualHours sets a task's actual hours to the total of its finished time entries
+func syncActualHours(tx *gorm.DB, taskID uint) error {
+	return tx.Session(&gorm.Session{NewDB: true}).Exec(
+		`UPDATE tasks SET actual_hours = (
+			SELECT COALESCE(SUM(duration), 0) / 3600.0 FROM time_entries
+			WHERE task_id = ? AND ended_at IS NOT NULL AND deleted_at IS NULL
+		) WHERE id = ?`,
+		taskID, taskID,
+	).Error
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTimeEntryBeforeSave(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		end := start.Add(d)
+		return &end
+	}
+
+	tests := []struct {
+		name     string
+		entry    TimeEntry
+		err      error
+		duration int64
+		hours    float64
+	}{
+		{"finished", TimeEntry{TaskID: 1, UserID: 1, StartedAt: start, EndedAt: at(90 * time.Minute)}, nil, 5400, 1.5},
+		{"running", TimeEntry{TaskID: 1, UserID: 1, StartedAt: start, Duration: 600}, nil, 0, 0},
+		{"sub-second remainder", TimeEntry{TaskID: 1, UserID: 1, StartedAt: start, EndedAt: at(time.Second + 900*time.Millisecond)}, nil, 1, 1.0 / 3600},
+		{"ends at its start", TimeEntry{TaskID: 1, UserID: 1, StartedAt: start, EndedAt: at(0)}, ErrInvalidEntryTimes, 0, 0},
+		{"ends before its start", TimeEntry{TaskID: 1, UserID: 1, StartedAt: start, EndedAt: at(-time.Hour)}, ErrInvalidEntryTimes, 0, 0},
+		{"missing task", TimeEntry{UserID: 1, StartedAt: start}, ErrMissingTask, 0, 0},
+		{"missing user", TimeEntry{TaskID: 1, StartedAt: start}, ErrMissingEntryUser, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := tt.entry
+			if err := entry.BeforeSave(nil); !errors.Is(err, tt.err) {
+				t.Fatalf("BeforeSave() error = %v, want %v", err, tt.err)
+			}
+			if tt.err != nil {
+				return
+			}
+			if entry.Duration != tt.duration {
+				t.Errorf("Duration = %d, want %d", entry.Duration, tt.duration)
+			}
+			if got := entry.Hours(); got != tt.hours {
+				t.Errorf("Hours() = %v, want %v", got, tt.hours)
+			}
+		})
+	}
+}
+
+func TestWeekStartOf(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+	kolkata := time.FixedZone("IST", 5*3600+1800)
+	honolulu := time.FixedZone("HST", -10*3600)
+
+	tests := []struct {
+		name string
+		at   time.Time
+		want time.Time
+	}{
+		{"monday midnight", monday, monday},
+		{"monday evening", monday.Add(23 * time.Hour), monday},
+		{"wednesday", time.Date(2026, 3, 4, 15, 30, 0, 0, time.UTC), monday},
+		{"sunday before midnight", time.Date(2026, 3, 8, 23, 59, 59, 0, time.UTC), monday},
+		{"next monday", time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC), monday.AddDate(0, 0, 7)},
+		{"monday morning ahead of UTC", time.Date(2026, 3, 2, 3, 0, 0, 0, kolkata), monday.AddDate(0, 0, -7)},
+		{"sunday evening behind UTC", time.Date(2026, 3, 1, 20, 0, 0, 0, honolulu), monday},
+		{"across a year", time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC), time.Date(2025, 12, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WeekStartOf(tt.at); !got.Equal(tt.want) {
+				t.Errorf("WeekStartOf(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimesheetValidate(t *testing.T) {
+	monday := time.Date(2026, 3, 2, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		timesheet Timesheet
+		err       error
+	}{
+		{"valid", Timesheet{OrganizationID: 1, UserID: 1, WeekStart: monday}, nil},
+		{"missing organization", Timesheet{UserID: 1, WeekStart: monday}, ErrMissingOrganization},
+		{"missing user", Timesheet{OrganizationID: 1, WeekStart: monday}, ErrMissingEntryUser},
+		{"starts on a tuesday", Timesheet{OrganizationID: 1, UserID: 1, WeekStart: monday.AddDate(0, 0, 1)}, ErrInvalidWeekStart},
+		{"starts after midnight", Timesheet{OrganizationID: 1, UserID: 1, WeekStart: monday.Add(time.Hour)}, ErrInvalidWeekStart},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.timesheet.Validate(); !errors.Is(err, tt.err) {
+				t.Errorf("Validate() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestTimesheetIsLocked(t *testing.T) {
+	tests := []struct {
+		status TimesheetStatus
+		locked bool
+	}{
+		{TimesheetStatusDraft, false},
+		{TimesheetStatusSubmitted, true},
+		{TimesheetStatusApproved, true},
+		{TimesheetStatusRejected, false},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.status), func(t *testing.T) {
+			timesheet := Timesheet{Status: tt.status}
+			if got := timesheet.IsLocked(); got != tt.locked {
+				t.Errorf("IsLocked() = %v, want %v", got, tt.locked)
+			}
+		})
+	}
+}
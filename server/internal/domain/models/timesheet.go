@@ -0,0 +1,87 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Common validation errors
+var (
+	ErrInvalidWeekStart = errors.New("timesheet week must start on a Monday")
+)
+
+// TimesheetStatus represents the approval state of a timesheet
+type TimesheetStatus string
+
+const (
+	TimesheetStatusDraft     TimesheetStatus = "draft"
+	TimesheetStatusSubmitted TimesheetStatus = "submitted"
+	TimesheetStatusApproved  TimesheetStatus = "approved"
+	TimesheetStatusRejected  TimesheetStatus = "rejected"
+)
+
+// Timesheet collects a user's time entries in an organization for one week,
+// from Monday 00:00 UTC, for approval by project managers
+type Timesheet struct {
+	gorm.Model
+	OrganizationID  uint            `json:"organization_id" gorm:"not null;uniqueIndex:idx_timesheet_user_week"`
+	UserID          uint            `json:"user_id" gorm:"not null;uniqueIndex:idx_timesheet_user_week"`
+	User            User            `json:"-" gorm:"foreignKey:UserID"`
+	WeekStart       time.Time       `json:"week_start" gorm:"type:date;not null;uniqueIndex:idx_timesheet_user_week"`
+	Status          TimesheetStatus `json:"status" gorm:"type:varchar(20);default:'draft'"`
+	TotalSeconds    int64           `json:"total_seconds"`
+	BillableSeconds int64           `json:"billable_seconds"`
+	SubmittedAt     *time.Time      `json:"submitted_at"`
+	ReviewedByID    *uint           `json:"reviewed_by_id"`
+	ReviewedAt      *time.Time      `json:"reviewed_at"`
+	ReviewNote      string          `json:"review_note"`
+
+	Entries []TimeEntry `json:"entries,omitempty" gorm:"-"`
+}
+
+// WeekStartOf returns the Monday 00:00 UTC starting the week that contains t
+func WeekStartOf(t time.Time) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	offset := (int(day.Weekday()) + 6) % 7
+	return day.AddDate(0, 0, -offset)
+}
+
+// WeekEnd returns the exclusive end of the timesheet's week
+func (t *Timesheet) WeekEnd() time.Time {
+	return t.WeekStart.AddDate(0, 0, 7)
+}
+
+// IsLocked checks if the timesheet's entries may no longer be changed
+func (t *Timesheet) IsLocked() bool {
+	return t.Status == TimesheetStatusSubmitted || t.Status == TimesheetStatusApproved
+}
+
+// Validate performs validation on the Timesheet model
+func (t *Timesheet) Validate() error {
+	if t.OrganizationID == 0 {
+		return ErrMissingOrganization
+	}
+
+	if t.UserID == 0 {
+		return ErrMissingEntryUser
+	}
+
+	if !WeekStartOf(t.WeekStart).Equal(t.WeekStart.UTC()) {
+		return ErrInvalidWeekStart
+	}
+
+	return nil
+}
+
+// BeforeCreate is a GORM hook that runs before creating a new timesheet
+func (t *Timesheet) BeforeCreate(tx *gorm.DB) error {
+	return t.Validate()
+}
+
+// BeforeUpdate is a GORM hook that runs before updating a timesheet
+func (t *Timesheet) BeforeUpdate(tx *gorm.DB) error {
+	return t.Validate()
+}
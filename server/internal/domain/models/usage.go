@@ -0,0 +1,139 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUsageLimitReached is wrapped by every LimitError
+var ErrUsageLimitReached = errors.New("plan limit reached")
+
+// UsageLimit names a limit set by an organization's plan
+type UsageLimit string
+
+const (
+	UsageLimitUsers    UsageLimit = "users"
+	UsageLimitProjects UsageLimit = "projects"
+	UsageLimitStorage  UsageLimit = "storage" // in GB
+)
+
+// LimitError reports that a change would take an organization over a limit
+// of its plan
+type LimitError struct {
+	Limit UsageLimit `json:"limit"`
+	Max   float64    `json:"max"`
+	Used  float64    `json:"used"`
+}
+
+func (e *LimitError) Error() string {
+	if e.Limit == UsageLimitStorage {
+		return fmt.Sprintf("%s: the plan allows %g GB of storage and %.2f GB are in use", ErrUsageLimitReached, e.Max, e.Used)
+	}
+	return fmt.Sprintf("%s: the plan allows %g %s and %g are in use", ErrUsageLimitReached, e.Max, e.Limit, e.Used)
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrUsageLimitReached
+}
+
+// usageCounts selects the number of members and projects of an organization
+var usageCounts = map[UsageLimit]string{
+	UsageLimitUsers:    "SELECT COUNT(*) FROM organization_users WHERE organization_id = ?",
+	UsageLimitProjects: "SELECT COUNT(*) FROM projects WHERE organization_id = ? AND deleted_at IS NULL",
+}
+
+// planLimits selects the columns of the plan holding each limit
+var planLimits = map[UsageLimit]string{
+	UsageLimitUsers:    "max_users",
+	UsageLimitProjects: "max_projects",
+}
+
+// checkUsageLimit checks that the organization can add one more member or
+// project under the plan of its active subscription, where 0 means no limit.
+// Organizations without an active subscription are not limited. The
+// organization row stays locked until the transaction ends, so that
+// concurrent additions cannot overshoot the limit together.
+func checkUsageLimit(tx *gorm.DB, orgID uint, limit UsageLimit) error {
+	db := tx.Session(&gorm.Session{NewDB: true})
+	var org Organization
+	if err := db.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").First(&org, orgID).Error; err != nil {
+		return err
+	}
+
+	var max int64
+	err := db.Raw(`
+SELECT plans.`+planLimits[limit]+`
+FROM subscriptions
+JOIN plans ON plans.id = subscriptions.plan_id
+WHERE subscriptions.organization_id = ?
+    AND subscriptions.status = 'active'
+    AND subscriptions.deleted_at IS NULL
+ORDER BY subscriptions.start_date DESC
+LIMIT 1`, orgID).Scan(&max).Error
+	if err != nil || max == 0 {
+		return err
+	}
+
+	var used int64
+	if err := db.Raw(usageCounts[limit], orgID).Scan(&used).Error; err != nil {
+		return err
+	}
+	if used >= max {
+		return &LimitError{Limit: limit, Max: float64(max), Used: float64(used)}
+	}
+	return nil
+}
+
+// SyncUsage recounts the members, projects and stored files of the given
+// organizations, or of all of them when none are given, and records them on
+// their current and scheduled subscriptions. It returns the number of
+// subscriptions whose counts had drifted.
+func SyncUsage(tx *gorm.DB, orgIDs ...uint) (int64, error) {
+	where := []string{"sub.status <> @cancelled", "sub.deleted_at IS NULL"}
+	args := map[string]interface{}{
+		"cancelled":    SubscriptionStatusCancelled,
+		"quarantined":  AttachmentStatusQuarantined,
+		"bytes_per_gb": BytesPerGB,
+	}
+	if len(orgIDs) > 0 {
+		where = append(where, "sub.organization_id IN @orgs")
+		args["orgs"] = orgIDs
+	}
+
+	result := tx.Session(&gorm.Session{NewDB: true}).Exec(`
+UPDATE subscriptions SET
+    current_users = usage.users,
+    current_projects = usage.projects,
+    current_storage = usage.storage
+FROM subscriptions AS sub
+CROSS JOIN LATERAL (
+    SELECT
+        (SELECT COUNT(*) FROM organization_users
+            WHERE organization_users.organization_id = sub.organization_id) AS users,
+        (SELECT COUNT(*) FROM projects
+            WHERE projects.organization_id = sub.organization_id AND projects.deleted_at IS NULL) AS projects,
+        (SELECT COALESCE(SUM(size), 0) FROM attachments
+            WHERE attachments.organization_id = sub.organization_id
+                AND attachments.status <> @quarantined
+                AND attachments.deleted_at IS NULL)::float8 / @bytes_per_gb AS storage
+) AS usage
+WHERE sub.id = subscriptions.id
+    AND (subscriptions.current_users <> usage.users
+        OR subscriptions.current_projects <> usage.projects
+        OR subscriptions.current_storage <> usage.storage)
+    AND `+strings.Join(where, " AND "), args)
+	return result.RowsAffected, result.Error
+}
+
+// syncOrganizationUsage recounts the usage of one organization
+func syncOrganizationUsage(tx *gorm.DB, orgID uint) error {
+	if orgID == 0 {
+		return nil
+	}
+	_, err := SyncUsage(tx, orgID)
+	return err
+}
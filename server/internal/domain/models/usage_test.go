@@ -0,0 +1,96 @@
+package models
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+)
+
+func TestLimitError(t *testing.T) {
+	tests := []struct {
+		err  *LimitError
+		want string
+	}{
+		{&LimitError{Limit: UsageLimitUsers, Max: 5, Used: 5}, "plan limit reached: the plan allows 5 users and 5 are in use"},
+		{&LimitError{Limit: UsageLimitProjects, Max: 10, Used: 12}, "plan limit reached: the plan allows 10 projects and 12 are in use"},
+		{&LimitError{Limit: UsageLimitStorage, Max: 5, Used: 4.99999}, "plan limit reached: the plan allows 5 GB of storage and 5.00 GB are in use"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.err.Limit), func(t *testing.T) {
+			if got := tt.err.Error(); got != tt.want {
+				t.Errorf("Error() = %q, want %q", got, tt.want)
+			}
+			var err error = tt.err
+			if !errors.Is(err, ErrUsageLimitReached) {
+				t.Error("LimitError does not wrap ErrUsageLimitReached")
+			}
+			var limit *LimitError
+			if !errors.As(err, &limit) || limit.Limit != tt.err.Limit {
+				t.Errorf("errors.As() = %v, want the %s limit", limit, tt.err.Limit)
+			}
+		})
+	}
+}
+
+// TestCheckUsageLimit runs checkUsageLimit against a scratch schema of the
+// database in TEST_DATABASE_DSN
+func TestCheckUsageLimit(t *testing.T) {
+	db := scratchDB(t)
+	for _, table := range []string{
+		"CREATE TABLE organizations (id bigint PRIMARY KEY, deleted_at timestamptz)",
+		"CREATE TABLE plans (id bigint PRIMARY KEY, max_users bigint, max_projects bigint)",
+		"CREATE TABLE subscriptions (id bigserial PRIMARY KEY, organization_id bigint, plan_id bigint, status varchar(20), start_date timestamptz, deleted_at timestamptz)",
+		"CREATE TABLE organization_users (organization_id bigint, user_id bigint)",
+		"CREATE TABLE projects (id bigserial PRIMARY KEY, organization_id bigint, deleted_at timestamptz)",
+		"INSERT INTO plans VALUES (1, 3, 2), (2, 0, 0)",
+	} {
+		if err := db.Exec(table).Error; err != nil {
+			t.Fatalf("%s: %v", table, err)
+		}
+	}
+
+	tests := []struct {
+		name     string
+		plan     int
+		status   SubscriptionStatus
+		users    int
+		projects int
+		deleted  int // deleted projects
+		limit    UsageLimit
+		err      error
+	}{
+		{"users under the limit", 1, SubscriptionStatusActive, 2, 0, 0, UsageLimitUsers, nil},
+		{"users at the limit", 1, SubscriptionStatusActive, 3, 0, 0, UsageLimitUsers, ErrUsageLimitReached},
+		{"projects at the limit", 1, SubscriptionStatusActive, 0, 2, 0, UsageLimitProjects, ErrUsageLimitReached},
+		{"deleted projects do not count", 1, SubscriptionStatusActive, 0, 1, 3, UsageLimitProjects, nil},
+		{"unlimited plan", 2, SubscriptionStatusActive, 50, 50, 0, UsageLimitUsers, nil},
+		{"cancelled subscription", 1, SubscriptionStatusCancelled, 3, 2, 0, UsageLimitUsers, nil},
+		{"no subscription", 0, "", 3, 2, 0, UsageLimitProjects, nil},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			orgID := uint(i + 1)
+			db.Exec("INSERT INTO organizations (id) VALUES (?)", orgID)
+			if tt.plan != 0 {
+				db.Exec("INSERT INTO subscriptions (organization_id, plan_id, status, start_date) VALUES (?, ?, ?, now())", orgID, tt.plan, tt.status)
+			}
+			for u := 0; u < tt.users; u++ {
+				db.Exec("INSERT INTO organization_users VALUES (?, ?)", orgID, u+1)
+			}
+			for p := 0; p < tt.projects+tt.deleted; p++ {
+				deletedAt := "NULL"
+				if p >= tt.projects {
+					deletedAt = "now()"
+				}
+				db.Exec("INSERT INTO projects (organization_id, deleted_at) VALUES (?, "+deletedAt+")", orgID)
+			}
+			err := db.Transaction(func(tx *gorm.DB) error {
+				return checkUsageLimit(tx, orgID, tt.limit)
+			})
+			if !errors.Is(err, tt.err) {
+				t.Errorf("checkUsageLimit() error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -47,6 +47,7 @@ type User struct {
 	// Account Status
 	Status       UserStatus `json:"status" gorm:"type:varchar(20);default:'inactive'"`
 	LastLoginAt  *time.Time `json:"last_login_at"`
+	IsPlatformAdmin bool    `json:"-" gorm:"default:false"` // operators who manage the plan catalog
 	
 	// Email Verification
 	VerificationCode string     `json:"-" gorm:"size:6"`
@@ -70,9 +71,8 @@ type User struct {
 	CreatedTasks  []Task     `json:"created_tasks" gorm:"foreignKey:CreatedByID"`
 	Comments      []Comment  `json:"comments" gorm:"foreignKey:UserID"`
 	
-	// Notifications
-	EmailNotifications bool `json:"email_notifications" gorm:"default:true"`
-	PushNotifications  bool `json:"push_notifications" gorm:"default:true"`
+	// Notifications, chosen per event and channel
+	NotificationPreferences []NotificationPreference `json:"-" gorm:"foreignKey:UserID"`
 }
 
 // TableName specifies the table name for the User model
@@ -85,6 +85,16 @@ func (u *User) FullName() string {
 	return u.FirstName + " " + u.LastName
 }
 
+// Location returns the user's time zone, or UTC if it is unset or unknown
+func (u *User) Location() *time.Location {
+	if u.TimeZone != "" {
+		if loc, err := time.LoadLocation(u.TimeZone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
 // Validate performs validation on the User model
 func (u *User) Validate() error {
 	if strings.TrimSpace(u.FirstName) == "" {
@@ -0,0 +1,392 @@
+// Package money represents amounts of money exactly, as integer minor units
+// (cents for USD) of an ISO 4217 currency.
+//
+// Operations that can produce fractions of a minor unit, such as scaling by a
+// ratio, round half away from zero. Operations on two amounts fail with
+// ErrCurrencyMismatch when their currencies differ.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidCurrency  = errors.New("currency must be a three-letter ISO 4217 code")
+	ErrCurrencyMismatch = errors.New("amounts are in different currencies")
+	ErrInvalidAmount    = errors.New("invalid amount")
+	ErrTooPrecise       = errors.New("amount has more decimal places than the currency allows")
+	ErrOverflow         = errors.New("amount is too large")
+)
+
+// DefaultCurrency is used where no currency has been chosen
+const DefaultCurrency = "USD"
+
+// exponents lists currencies whose minor unit is not a hundredth of the major unit
+var exponents = map[string]int{
+	"BHD": 3, "BIF": 0, "CLF": 4, "CLP": 0, "DJF": 0, "GNF": 0, "IQD": 3,
+	"ISK": 0, "JOD": 3, "JPY": 0, "KMF": 0, "KRW": 0, "KWD": 3, "LYD": 3,
+	"OMR": 3, "PYG": 0, "RWF": 0, "TND": 3, "UGX": 0, "UYI": 0, "UYW": 4,
+	"VND": 0, "VUV": 0, "XAF": 0, "XOF": 0, "XPF": 0,
+}
+
+// Exponent returns the number of decimal places of a currency's minor unit
+func Exponent(currency string) int {
+	if exp, ok := exponents[currency]; ok {
+		return exp
+	}
+	return 2
+}
+
+// Exponents returns the currencies whose exponent differs from the default of 2
+func Exponents() map[string]int {
+	copied := make(map[string]int, len(exponents))
+	for currency, exp := range exponents {
+		copied[currency] = exp
+	}
+	return copied
+}
+
+// IsValidCurrency checks that a code has the form of an ISO 4217 code
+func IsValidCurrency(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// NormalizeCurrency upper-cases a currency code and checks its form
+func NormalizeCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !IsValidCurrency(currency) {
+		return "", ErrInvalidCurrency
+	}
+	return currency, nil
+}
+
+// Money is an amount in minor units of a currency. It is stored as two
+// columns, <prefix>minor and <prefix>currency, when embedded in a model with
+// gorm:"embedded;embeddedPrefix:<prefix>".
+type Money struct {
+	Minor    int64  `gorm:"column:minor;not null;default:0"`
+	Currency string `gorm:"column:currency;type:varchar(3)"`
+}
+
+// New returns an amount of minor units of a currency
+func New(minor int64, currency string) Money {
+	return Money{Minor: minor, Currency: currency}
+}
+
+// Zero returns no money in a currency
+func Zero(currency string) Money {
+	return Money{Currency: currency}
+}
+
+// Parse reads a decimal amount in major units, such as "12.34" or "-5", of a
+// currency. Amounts more precise than the currency's minor unit are rejected
+// rather than rounded.
+func Parse(amount, currency string) (Money, error) {
+	currency, err := NormalizeCurrency(currency)
+	if err != nil {
+		return Money{}, err
+	}
+
+	amount = strings.TrimSpace(amount)
+	negative := false
+	switch {
+	case strings.HasPrefix(amount, "-"):
+		negative = true
+		amount = amount[1:]
+	case strings.HasPrefix(amount, "+"):
+		amount = amount[1:]
+	}
+
+	whole, fraction, _ := strings.Cut(amount, ".")
+	if whole == "" && fraction == "" || !isDigits(whole) || !isDigits(fraction) {
+		return Money{}, ErrInvalidAmount
+	}
+	exp := Exponent(currency)
+	fraction = strings.TrimRight(fraction, "0")
+	if len(fraction) > exp {
+		return Money{}, ErrTooPrecise
+	}
+	fraction += strings.Repeat("0", exp-len(fraction))
+
+	minor, err := strconv.ParseInt(whole+fraction, 10, 64)
+	if err != nil {
+		return Money{}, ErrOverflow
+	}
+	if negative {
+		minor = -minor
+	}
+	return Money{Minor: minor, Currency: currency}, nil
+}
+
+// MustParse is like Parse but panics on invalid input. It is meant for constants.
+func MustParse(amount, currency string) Money {
+	m, err := Parse(amount, currency)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Validate checks that the amount has a valid currency
+func (m Money) Validate() error {
+	if !IsValidCurrency(m.Currency) {
+		return ErrInvalidCurrency
+	}
+	return nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Minor == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Minor < 0
+}
+
+func (m Money) IsPositive() bool {
+	return m.Minor > 0
+}
+
+// Neg returns the amount with its sign flipped
+func (m Money) Neg() Money {
+	return Money{Minor: -m.Minor, Currency: m.Currency}
+}
+
+// Abs returns the amount without its sign
+func (m Money) Abs() Money {
+	if m.Minor < 0 {
+		return m.Neg()
+	}
+	return m
+}
+
+// SameCurrency checks if two amounts are in the same currency
+func (m Money) SameCurrency(other Money) bool {
+	return m.Currency == other.Currency
+}
+
+// Add returns the sum of two amounts of the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if !m.SameCurrency(other) {
+		return Money{}, ErrCurrencyMismatch
+	}
+	sum := m.Minor + other.Minor
+	if (sum > m.Minor) != (other.Minor > 0) {
+		return Money{}, ErrOverflow
+	}
+	return Money{Minor: sum, Currency: m.Currency}, nil
+}
+
+// Sub returns the difference of two amounts of the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	if other.Minor == math.MinInt64 {
+		return Money{}, ErrOverflow
+	}
+	return m.Add(other.Neg())
+}
+
+// Mul returns the amount multiplied by a whole quantity
+func (m Money) Mul(quantity int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Minor), big.NewInt(quantity))
+	if !product.IsInt64() {
+		return Money{}, ErrOverflow
+	}
+	return Money{Minor: product.Int64(), Currency: m.Currency}, nil
+}
+
+// MulRat returns the amount multiplied by num/den, rounded half away from zero
+func (m Money) MulRat(num, den int64) (Money, error) {
+	if den == 0 {
+		return Money{}, ErrInvalidAmount
+	}
+	product := new(big.Int).Mul(big.NewInt(m.Minor), big.NewInt(num))
+	quotient, ok := divRound(product, big.NewInt(den))
+	if !ok {
+		return Money{}, ErrOverflow
+	}
+	return Money{Minor: quotient, Currency: m.Currency}, nil
+}
+
+// Allocate splits the amount in proportion to weights without losing or
+// creating minor units: the shares always add up to the amount. Units left
+// over after rounding down go to the shares with the largest remainders,
+// earlier shares first on ties.
+func (m Money) Allocate(weights ...int64) ([]Money, error) {
+	total := new(big.Int)
+	for _, weight := range weights {
+		if weight < 0 {
+			return nil, ErrInvalidAmount
+		}
+		total.Add(total, big.NewInt(weight))
+	}
+	if total.Sign() == 0 {
+		return nil, ErrInvalidAmount
+	}
+
+	amount := big.NewInt(m.Minor)
+	sign := int64(1)
+	if m.Minor < 0 {
+		sign = -1
+		amount.Neg(amount)
+	}
+
+	shares := make([]Money, len(weights))
+	remainders := make([]*big.Int, len(weights))
+	allocated := new(big.Int)
+	for i, weight := range weights {
+		share, remainder := new(big.Int).QuoRem(new(big.Int).Mul(amount, big.NewInt(weight)), total, new(big.Int))
+		shares[i] = Money{Minor: share.Int64(), Currency: m.Currency}
+		remainders[i] = remainder
+		allocated.Add(allocated, share)
+	}
+
+	left := new(big.Int).Sub(amount, allocated).Int64()
+	for ; left > 0; left-- {
+		best := -1
+		for i, remainder := range remainders {
+			if remainder.Sign() > 0 && (best < 0 || remainder.Cmp(remainders[best]) > 0) {
+				best = i
+			}
+		}
+		shares[best].Minor++
+		remainders[best].SetInt64(0)
+	}
+
+	for i := range shares {
+		shares[i].Minor *= sign
+	}
+	return shares, nil
+}
+
+// Cmp compares two amounts of the same currency, returning -1, 0 or 1
+func (m Money) Cmp(other Money) (int, error) {
+	if !m.SameCurrency(other) {
+		return 0, ErrCurrencyMismatch
+	}
+	switch {
+	case m.Minor < other.Minor:
+		return -1, nil
+	case m.Minor > other.Minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Sum adds amounts of a currency. The sum of no amounts is zero.
+func Sum(currency string, amounts ...Money) (Money, error) {
+	total := Zero(currency)
+	for _, amount := range amounts {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
+
+// Decimal formats the amount in major units, such as "-12.34"
+func (m Money) Decimal() string {
+	exp := Exponent(m.Currency)
+	digits := new(big.Int).Abs(big.NewInt(m.Minor)).String()
+	sign := ""
+	if m.Minor < 0 {
+		sign = "-"
+	}
+	if exp == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exp] + "." + digits[len(digits)-exp:]
+}
+
+// String formats the amount with its currency, such as "12.34 USD"
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+// jsonMoney is the JSON form of an amount. The decimal amount is a string so
+// that clients never see it as a float.
+type jsonMoney struct {
+	Amount   *json.Number `json:"amount,omitempty"`
+	Minor    *int64       `json:"minor,omitempty"`
+	Currency string       `json:"currency"`
+}
+
+// MarshalJSON encodes the amount as {"amount": "12.34", "minor": 1234, "currency": "USD"}
+func (m Money) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Amount   string `json:"amount"`
+		Minor    int64  `json:"minor"`
+		Currency string `json:"currency"`
+	}{m.Decimal(), m.Minor, m.Currency})
+}
+
+// UnmarshalJSON decodes an object with a currency and either a decimal
+// amount, as a string or number, or minor units
+func (m *Money) UnmarshalJSON(data []byte) error {
+	var decoded jsonMoney
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAmount, err)
+	}
+
+	currency, err := NormalizeCurrency(decoded.Currency)
+	if err != nil {
+		return err
+	}
+	switch {
+	case decoded.Amount != nil:
+		parsed, err := Parse(decoded.Amount.String(), currency)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case decoded.Minor != nil:
+		*m = Money{Minor: *decoded.Minor, Currency: currency}
+	default:
+		return ErrInvalidAmount
+	}
+	return nil
+}
+
+// divRound divides, rounding half away from zero
+func divRound(num, den *big.Int) (int64, bool) {
+	quotient, remainder := new(big.Int).QuoRem(num, den, new(big.Int))
+	twice := new(big.Int).Abs(remainder)
+	twice.Lsh(twice, 1)
+	if twice.Cmp(new(big.Int).Abs(den)) >= 0 {
+		if (num.Sign() < 0) != (den.Sign() < 0) {
+			quotient.Sub(quotient, big.NewInt(1))
+		} else {
+			quotient.Add(quotient, big.NewInt(1))
+		}
+	}
+	if !quotient.IsInt64() {
+		return 0, false
+	}
+	return quotient.Int64(), true
+}
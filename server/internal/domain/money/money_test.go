@@ -0,0 +1,281 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		amount   string
+		currency string
+		want     Money
+		err      error
+	}{
+		{"12.34", "USD", New(1234, "USD"), nil},
+		{" 12.34 ", "usd", New(1234, "USD"), nil},
+		{"-12.34", "USD", New(-1234, "USD"), nil},
+		{"+12.34", "USD", New(1234, "USD"), nil},
+		{"-0.01", "EUR", New(-1, "EUR"), nil},
+		{"-0", "USD", New(0, "USD"), nil},
+		{"5", "USD", New(500, "USD"), nil},
+		{"5.", "USD", New(500, "USD"), nil},
+		{".5", "USD", New(50, "USD"), nil},
+		{"1.230", "USD", New(123, "USD"), nil},
+		{"1.234", "USD", Money{}, ErrTooPrecise},
+		{"0.001", "USD", Money{}, ErrTooPrecise},
+		{"-1.239", "USD", Money{}, ErrTooPrecise},
+		{"1500", "JPY", New(1500, "JPY"), nil},
+		{"1500.00", "JPY", New(1500, "JPY"), nil},
+		{"1500.5", "JPY", Money{}, ErrTooPrecise},
+		{"-250", "KRW", New(-250, "KRW"), nil},
+		{"0.5", "CLP", Money{}, ErrTooPrecise},
+		{"1.234", "BHD", New(1234, "BHD"), nil},
+		{"1.2345", "BHD", Money{}, ErrTooPrecise},
+		{"0.0001", "CLF", New(1, "CLF"), nil},
+		{"92233720368547758.07", "USD", New(math.MaxInt64, "USD"), nil},
+		{"92233720368547758.08", "USD", Money{}, ErrOverflow},
+		{"9223372036854775807", "JPY", New(math.MaxInt64, "JPY"), nil},
+		{"9223372036854775808", "JPY", Money{}, ErrOverflow},
+		{"", "USD", Money{}, ErrInvalidAmount},
+		{"-", "USD", Money{}, ErrInvalidAmount},
+		{".", "USD", Money{}, ErrInvalidAmount},
+		{"--5", "USD", Money{}, ErrInvalidAmount},
+		{"- 5", "USD", Money{}, ErrInvalidAmount},
+		{"1,000.00", "USD", Money{}, ErrInvalidAmount},
+		{"1e3", "USD", Money{}, ErrInvalidAmount},
+		{"1.2.3", "USD", Money{}, ErrInvalidAmount},
+		{"12.34", "US", Money{}, ErrInvalidCurrency},
+		{"12.34", "US1", Money{}, ErrInvalidCurrency},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.amount, tt.currency)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q, %q) = %v, %v, want %v, %v", tt.amount, tt.currency, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		money Money
+		want  string
+	}{
+		{New(1234, "USD"), "12.34"},
+		{New(-1234, "USD"), "-12.34"},
+		{New(5, "USD"), "0.05"},
+		{New(-5, "USD"), "-0.05"},
+		{New(0, "USD"), "0.00"},
+		{New(1500, "JPY"), "1500"},
+		{New(-7, "JPY"), "-7"},
+		{New(1, "BHD"), "0.001"},
+		{New(math.MinInt64, "USD"), "-92233720368547758.08"},
+	}
+	for _, tt := range tests {
+		if got := tt.money.Decimal(); got != tt.want {
+			t.Errorf("%d %s Decimal() = %s, want %s", tt.money.Minor, tt.money.Currency, got, tt.want)
+		}
+		if tt.money.Minor == math.MinInt64 {
+			continue
+		}
+		parsed, err := Parse(tt.want, tt.money.Currency)
+		if err != nil || parsed != tt.money {
+			t.Errorf("Parse(%s) = %v, %v, want %v", tt.want, parsed, err, tt.money)
+		}
+	}
+}
+
+func TestArithmeticCurrencyMismatch(t *testing.T) {
+	usd, eur := New(100, "USD"), New(100, "EUR")
+
+	if _, err := usd.Add(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add error = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := usd.Sub(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub error = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := usd.Cmp(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Cmp error = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := Sum("USD", usd, eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sum error = %v, want ErrCurrencyMismatch", err)
+	}
+	if _, err := Sum("EUR", usd); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sum into another currency error = %v, want ErrCurrencyMismatch", err)
+	}
+}
+
+func TestAddSubOverflow(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func() (Money, error)
+		want int64
+		err  error
+	}{
+		{"add", func() (Money, error) { return New(150, "USD").Add(New(-275, "USD")) }, -125, nil},
+		{"sub", func() (Money, error) { return New(150, "USD").Sub(New(275, "USD")) }, -125, nil},
+		{"add zero to max", func() (Money, error) { return New(math.MaxInt64, "USD").Add(New(0, "USD")) }, math.MaxInt64, nil},
+		{"add past max", func() (Money, error) { return New(math.MaxInt64, "USD").Add(New(1, "USD")) }, 0, ErrOverflow},
+		{"add past min", func() (Money, error) { return New(math.MinInt64, "USD").Add(New(-1, "USD")) }, 0, ErrOverflow},
+		{"sub past max", func() (Money, error) { return New(math.MaxInt64, "USD").Sub(New(-1, "USD")) }, 0, ErrOverflow},
+		{"sub min", func() (Money, error) { return New(0, "USD").Sub(New(math.MinInt64, "USD")) }, 0, ErrOverflow},
+		{"sub to min", func() (Money, error) { return New(math.MinInt64+1, "USD").Sub(New(1, "USD")) }, math.MinInt64, nil},
+	}
+	for _, tt := range tests {
+		got, err := tt.op()
+		if !errors.Is(err, tt.err) || (err == nil && got.Minor != tt.want) {
+			t.Errorf("%s = %v, %v, want %d, %v", tt.name, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		minor    int64
+		quantity int64
+		want     int64
+		err      error
+	}{
+		{1999, 3, 5997, nil},
+		{1999, -3, -5997, nil},
+		{-1999, 0, 0, nil},
+		{math.MaxInt64, 1, math.MaxInt64, nil},
+		{math.MaxInt64, 2, 0, ErrOverflow},
+		{math.MaxInt64 / 2, 3, 0, ErrOverflow},
+		{math.MinInt64, -1, 0, ErrOverflow},
+		{math.MinInt64 / 2, 2, math.MinInt64, nil},
+		{1 << 32, 1 << 32, 0, ErrOverflow},
+	}
+	for _, tt := range tests {
+		got, err := New(tt.minor, "USD").Mul(tt.quantity)
+		if !errors.Is(err, tt.err) || (err == nil && got != New(tt.want, "USD")) {
+			t.Errorf("%d.Mul(%d) = %v, %v, want %d, %v", tt.minor, tt.quantity, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestMulRatRounding(t *testing.T) {
+	tests := []struct {
+		minor    int64
+		num, den int64
+		want     int64
+		err      error
+	}{
+		{1000, 1, 3, 333, nil},
+		{1000, 2, 3, 667, nil},
+		{-1000, 2, 3, -667, nil},
+		{1000, -2, 3, -667, nil},
+		{1000, 2, -3, -667, nil},
+		{5, 1, 2, 3, nil},   // 2.5 rounds away from zero
+		{-5, 1, 2, -3, nil}, // -2.5 rounds away from zero
+		{7, 1, 2, 4, nil},
+		{3, 1, 2, 2, nil},
+		{1, 1, 3, 0, nil},
+		{2999, 19, 100, 570, nil}, // 19% of 29.99 is 5.6981
+		{math.MaxInt64, 2, 2, math.MaxInt64, nil},
+		{math.MaxInt64, 3, 2, 0, ErrOverflow},
+		{math.MaxInt64, math.MaxInt64, math.MaxInt64, math.MaxInt64, nil},
+		{100, 1, 0, 0, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		got, err := New(tt.minor, "USD").MulRat(tt.num, tt.den)
+		if !errors.Is(err, tt.err) || (err == nil && got.Minor != tt.want) {
+			t.Errorf("%d.MulRat(%d, %d) = %v, %v, want %d, %v", tt.minor, tt.num, tt.den, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		minor   int64
+		weights []int64
+		want    []int64
+	}{
+		{100, []int64{1, 1, 1}, []int64{34, 33, 33}},
+		{-100, []int64{1, 1, 1}, []int64{-34, -33, -33}},
+		{5, []int64{3, 7}, []int64{2, 3}}, // 1.5 and 3.5, the tie goes to the earlier share
+		{1, []int64{1, 1, 1}, []int64{1, 0, 0}},
+		{0, []int64{1, 2}, []int64{0, 0}},
+		{1000, []int64{0, 1}, []int64{0, 1000}},
+		{100, []int64{1, 2, 2}, []int64{20, 40, 40}},
+		{10, []int64{2, 3, 5}, []int64{2, 3, 5}},
+		{11, []int64{2, 3, 5}, []int64{2, 3, 6}}, // remainders 0.2, 0.3 and 0.5
+		{math.MaxInt64, []int64{1, 1}, []int64{math.MaxInt64/2 + 1, math.MaxInt64 / 2}},
+		{math.MinInt64, []int64{1}, []int64{math.MinInt64}},
+		{math.MinInt64, []int64{1, 1}, []int64{math.MinInt64 / 2, math.MinInt64 / 2}},
+		{math.MaxInt64, []int64{math.MaxInt64, math.MaxInt64, 1}, []int64{math.MaxInt64 / 2, math.MaxInt64 / 2, 1}},
+	}
+	for _, tt := range tests {
+		shares, err := New(tt.minor, "EUR").Allocate(tt.weights...)
+		if err != nil {
+			t.Errorf("%d.Allocate(%v) error: %v", tt.minor, tt.weights, err)
+			continue
+		}
+		got := make([]int64, len(shares))
+		for i, share := range shares {
+			got[i] = share.Minor
+			if share.Currency != "EUR" {
+				t.Errorf("share %d currency = %s, want EUR", i, share.Currency)
+			}
+		}
+		if !equalInts(got, tt.want) {
+			t.Errorf("%d.Allocate(%v) = %v, want %v", tt.minor, tt.weights, got, tt.want)
+		}
+		total, err := Sum("EUR", shares...)
+		if err != nil || total.Minor != tt.minor {
+			t.Errorf("%d.Allocate(%v) shares add up to %v, %v", tt.minor, tt.weights, total, err)
+		}
+	}
+}
+
+func TestAllocateInvalidWeights(t *testing.T) {
+	for _, weights := range [][]int64{nil, {0}, {0, 0}, {1, -1}} {
+		if _, err := New(100, "USD").Allocate(weights...); !errors.Is(err, ErrInvalidAmount) {
+			t.Errorf("Allocate(%v) error = %v, want ErrInvalidAmount", weights, err)
+		}
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(New(-1234, "USD"))
+	if err != nil || string(data) != `{"amount":"-12.34","minor":-1234,"currency":"USD"}` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+
+	tests := []struct {
+		input string
+		want  Money
+		err   error
+	}{
+		{`{"amount":"19.99","currency":"usd"}`, New(1999, "USD"), nil},
+		{`{"amount":19.99,"currency":"USD"}`, New(1999, "USD"), nil},
+		{`{"amount":1500,"currency":"JPY"}`, New(1500, "JPY"), nil},
+		{`{"minor":1999,"currency":"USD"}`, New(1999, "USD"), nil},
+		{`{"amount":"19.999","currency":"USD"}`, Money{}, ErrTooPrecise},
+		{`{"amount":"1500.5","currency":"JPY"}`, Money{}, ErrTooPrecise},
+		{`{"amount":"19.99"}`, Money{}, ErrInvalidCurrency},
+		{`{"currency":"USD"}`, Money{}, ErrInvalidAmount},
+		{`"19.99"`, Money{}, ErrInvalidAmount},
+	}
+	for _, tt := range tests {
+		var got Money
+		err := json.Unmarshal([]byte(tt.input), &got)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %v, %v", tt.input, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func equalInts(a, b []int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
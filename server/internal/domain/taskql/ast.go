@@ -0,0 +1,86 @@
+// Package taskql implements the task filter language, for example
+//
+//	assignee = me AND status != done AND due < +7d AND label in (bug, urgent)
+//
+// Queries are parsed into an AST, validated against the task model and
+// compiled into parameterized GORM expressions.
+package taskql
+
+import "fmt"
+
+// Node is a node of a parsed query
+type Node interface {
+	node()
+}
+
+// LogicalExpr joins two expressions with AND or OR
+type LogicalExpr struct {
+	Op    string `json:"op"`
+	Left  Node   `json:"left"`
+	Right Node   `json:"right"`
+}
+
+// NotExpr negates an expression
+type NotExpr struct {
+	Expr Node `json:"expr"`
+}
+
+// Comparison compares a field with one or more values
+type Comparison struct {
+	Field  string  `json:"field"`
+	Op     string  `json:"op"`
+	Values []Value `json:"values,omitempty"`
+	Pos    int     `json:"pos"`
+}
+
+// ValueKind is the lexical kind of a literal value
+type ValueKind string
+
+const (
+	ValueIdent        ValueKind = "ident"
+	ValueString       ValueKind = "string"
+	ValueNumber       ValueKind = "number"
+	ValueDate         ValueKind = "date"
+	ValueRelativeDate ValueKind = "relative_date"
+)
+
+// Value is a literal in a comparison
+type Value struct {
+	Kind ValueKind `json:"kind"`
+	Text string    `json:"text"`
+	Pos  int       `json:"pos"`
+}
+
+func (*LogicalExpr) node() {}
+func (*NotExpr) node()     {}
+func (*Comparison) node()  {}
+
+// Comparison operators
+const (
+	OpEq         = "="
+	OpNe         = "!="
+	OpLt         = "<"
+	OpLte        = "<="
+	OpGt         = ">"
+	OpGte        = ">="
+	OpContains   = "~"
+	OpNotContain = "!~"
+	OpIn         = "IN"
+	OpNotIn      = "NOT IN"
+	OpIsEmpty    = "IS EMPTY"
+	OpIsNotEmpty = "IS NOT EMPTY"
+)
+
+// Error is a parse or validation error at a position in the query
+type Error struct {
+	Pos int
+	Msg string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Msg, e.Pos+1)
+}
+
+func errorf(pos int, format string, args ...interface{}) *Error {
+	return &Error{Pos: pos, Msg: fmt.Sprintf(format, args...)}
+}
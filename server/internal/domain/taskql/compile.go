@@ -0,0 +1,556 @@
+package taskql
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm/clause"
+)
+
+// Env is the context a query is compiled in
+type Env struct {
+	// UserID is the user that "me" refers to
+	UserID uint
+	// Now anchors relative dates such as +7d and today
+	Now time.Time
+	// CustomFields are the fields cf.<key> may refer to. Custom fields are
+	// project-scoped, so they are unavailable when this is nil.
+	CustomFields []models.CustomFieldDefinition
+}
+
+type fieldKind int
+
+const (
+	kindEnum fieldKind = iota
+	kindText
+	kindUser
+	kindDate
+	kindNumber
+	kindID
+	kindLabel
+)
+
+type field struct {
+	column   string
+	kind     fieldKind
+	nullable bool
+	enum     []string
+}
+
+// fields are the task attributes a query can refer to
+var fields = map[string]field{
+	"id":          {column: "tasks.id", kind: kindID},
+	"project":     {column: "tasks.project_id", kind: kindID},
+	"sprint":      {column: "tasks.sprint_id", kind: kindID, nullable: true},
+	"parent":      {column: "tasks.parent_id", kind: kindID, nullable: true},
+	"title":       {column: "tasks.title", kind: kindText},
+	"description": {column: "tasks.description", kind: kindText, nullable: true},
+	"assignee":    {column: "tasks.assignee_id", kind: kindUser, nullable: true},
+	"creator":     {column: "tasks.created_by_id", kind: kindUser},
+	"due":         {column: "tasks.due_date", kind: kindDate, nullable: true},
+	"created":     {column: "tasks.created_at", kind: kindDate},
+	"updated":     {column: "tasks.updated_at", kind: kindDate},
+	"started":     {column: "tasks.started_at", kind: kindDate, nullable: true},
+	"completed":   {column: "tasks.completed_at", kind: kindDate, nullable: true},
+	"estimate":    {column: "tasks.estimated_hours", kind: kindNumber},
+	"actual":      {column: "tasks.actual_hours", kind: kindNumber},
+	"points":      {column: "tasks.story_points", kind: kindNumber},
+	"label":       {kind: kindLabel},
+	"status": {column: "tasks.status", kind: kindEnum, enum: []string{
+		string(models.TaskStatusTodo),
+		string(models.TaskStatusInProgress),
+		string(models.TaskStatusInReview),
+		string(models.TaskStatusDone),
+	}},
+	"priority": {column: "tasks.priority", kind: kindEnum, enum: []string{
+		string(models.TaskPriorityLow),
+		string(models.TaskPriorityMedium),
+		string(models.TaskPriorityHigh),
+		string(models.TaskPriorityCritical),
+	}},
+}
+
+// allowedOps lists the operators each kind of field supports. IS EMPTY and
+// IS NOT EMPTY are additionally allowed on nullable fields.
+var allowedOps = map[fieldKind][]string{
+	kindEnum:   {OpEq, OpNe, OpIn, OpNotIn},
+	kindText:   {OpEq, OpNe, OpContains, OpNotContain, OpIn, OpNotIn, OpIsEmpty, OpIsNotEmpty},
+	kindUser:   {OpEq, OpNe, OpIn, OpNotIn},
+	kindDate:   {OpEq, OpNe, OpLt, OpLte, OpGt, OpGte},
+	kindNumber: {OpEq, OpNe, OpLt, OpLte, OpGt, OpGte, OpIn, OpNotIn},
+	kindID:     {OpEq, OpNe, OpLt, OpLte, OpGt, OpGte, OpIn, OpNotIn},
+	kindLabel:  {OpEq, OpNe, OpIn, OpNotIn, OpIsEmpty, OpIsNotEmpty},
+}
+
+const labelExists = "EXISTS (SELECT 1 FROM task_labels JOIN labels ON labels.id = task_labels.label_id WHERE task_labels.task_id = tasks.id"
+
+// Compile turns a parsed query into a parameterized WHERE expression on the
+// tasks table. A nil node compiles to a nil expression.
+func Compile(node Node, env Env) (clause.Expression, error) {
+	if node == nil {
+		return nil, nil
+	}
+	if env.Now.IsZero() {
+		env.Now = time.Now()
+	}
+
+	c := &compiler{env: env}
+	sql, err := c.compile(node)
+	if err != nil {
+		return nil, err
+	}
+	return clause.Expr{SQL: sql, Vars: c.vars}, nil
+}
+
+// CompileString parses and compiles a query
+func CompileString(query string, env Env) (clause.Expression, error) {
+	node, err := Parse(query)
+	if err != nil {
+		return nil, err
+	}
+	return Compile(node, env)
+}
+
+type compiler struct {
+	env  Env
+	vars []interface{}
+}
+
+func (c *compiler) compile(node Node) (string, error) {
+	switch n := node.(type) {
+	case *LogicalExpr:
+		left, err := c.compile(n.Left)
+		if err != nil {
+			return "", err
+		}
+		right, err := c.compile(n.Right)
+		if err != nil {
+			return "", err
+		}
+		op := " AND "
+		if n.Op == "OR" {
+			op = " OR "
+		}
+		return "(" + left + op + right + ")", nil
+
+	case *NotExpr:
+		expr, err := c.compile(n.Expr)
+		if err != nil {
+			return "", err
+		}
+		// A comparison with an empty column is NULL, which NOT keeps NULL;
+		// it counts as false so that NOT (assignee = me) matches the same
+		// tasks as assignee != me
+		return "NOT COALESCE(" + expr + ", FALSE)", nil
+
+	case *Comparison:
+		return c.comparison(n)
+	}
+	return "", errorf(0, "unsupported expression")
+}
+
+func (c *compiler) comparison(cmp *Comparison) (string, error) {
+	if key, ok := strings.CutPrefix(cmp.Field, "cf."); ok {
+		return c.customField(cmp, key)
+	}
+
+	f, ok := fields[cmp.Field]
+	if !ok {
+		return "", errorf(cmp.Pos, "unknown field %q", cmp.Field)
+	}
+	if err := checkOp(cmp, f.kind, f.nullable); err != nil {
+		return "", err
+	}
+
+	switch f.kind {
+	case kindLabel:
+		return c.label(cmp)
+	case kindDate:
+		return c.date(f.column, f.nullable, cmp)
+	}
+
+	if cmp.Op == OpIsEmpty || cmp.Op == OpIsNotEmpty {
+		return emptiness(f.column, f.kind == kindText, cmp.Op == OpIsEmpty), nil
+	}
+
+	values := make([]interface{}, 0, len(cmp.Values))
+	for _, v := range cmp.Values {
+		value, err := c.scalar(f, v)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, value)
+	}
+	return c.scalarComparison(f.column, f.nullable, cmp.Op, values), nil
+}
+
+// scalar converts a literal into a value of the field's type
+func (c *compiler) scalar(f field, v Value) (interface{}, error) {
+	switch f.kind {
+	case kindEnum:
+		if v.Kind != ValueIdent && v.Kind != ValueString {
+			return nil, errorf(v.Pos, "expected one of %s", strings.Join(f.enum, ", "))
+		}
+		text := strings.ToLower(v.Text)
+		for _, option := range f.enum {
+			if option == text {
+				return text, nil
+			}
+		}
+		return nil, errorf(v.Pos, "%q is not one of %s", v.Text, strings.Join(f.enum, ", "))
+
+	case kindText:
+		return v.Text, nil
+
+	case kindUser:
+		if v.Kind == ValueIdent && strings.EqualFold(v.Text, "me") {
+			return c.env.UserID, nil
+		}
+		return parseID(v)
+
+	case kindID:
+		return parseID(v)
+
+	case kindNumber:
+		if v.Kind != ValueNumber {
+			return nil, errorf(v.Pos, "expected a number")
+		}
+		n, err := strconv.ParseFloat(v.Text, 64)
+		if err != nil {
+			return nil, errorf(v.Pos, "invalid number %q", v.Text)
+		}
+		return n, nil
+	}
+	return nil, errorf(v.Pos, "unsupported value")
+}
+
+// scalarComparison compares a column with already converted values. Negative
+// comparisons on nullable columns also match rows where the column is empty.
+func (c *compiler) scalarComparison(column string, nullable bool, op string, values []interface{}) string {
+	var sql string
+	switch op {
+	case OpEq:
+		sql = column + " = ?"
+	case OpNe:
+		sql = column + " <> ?"
+	case OpLt, OpLte, OpGt, OpGte:
+		sql = column + " " + op + " ?"
+	case OpContains:
+		sql = column + " ILIKE ?"
+	case OpNotContain:
+		sql = column + " NOT ILIKE ?"
+	case OpIn:
+		sql = column + " IN ?"
+	case OpNotIn:
+		sql = column + " NOT IN ?"
+	}
+
+	switch op {
+	case OpContains, OpNotContain:
+		c.vars = append(c.vars, "%"+escapeLike(values[0].(string))+"%")
+	case OpIn, OpNotIn:
+		c.vars = append(c.vars, values)
+	default:
+		c.vars = append(c.vars, values[0])
+	}
+
+	if nullable && (op == OpNe || op == OpNotIn || op == OpNotContain) {
+		return "(" + sql + " OR " + column + " IS NULL)"
+	}
+	return sql
+}
+
+// dateRange is the span a date literal covers. Calendar dates cover a whole
+// day while instants such as now or +3h cover a single point in time.
+type dateRange struct {
+	start   time.Time
+	end     time.Time
+	instant bool
+}
+
+func (c *compiler) date(column string, nullable bool, cmp *Comparison) (string, error) {
+	if cmp.Op == OpIsEmpty || cmp.Op == OpIsNotEmpty {
+		return emptiness(column, false, cmp.Op == OpIsEmpty), nil
+	}
+
+	r, err := c.dateValue(cmp.Values[0])
+	if err != nil {
+		return "", err
+	}
+
+	if r.instant {
+		op := cmp.Op
+		if op == OpNe {
+			op = "<>"
+		}
+		c.vars = append(c.vars, r.start)
+		sql := column + " " + op + " ?"
+		if nullable && cmp.Op == OpNe {
+			sql = "(" + sql + " OR " + column + " IS NULL)"
+		}
+		return sql, nil
+	}
+
+	switch cmp.Op {
+	case OpEq:
+		c.vars = append(c.vars, r.start, r.end)
+		return "(" + column + " >= ? AND " + column + " < ?)", nil
+	case OpNe:
+		c.vars = append(c.vars, r.start, r.end)
+		sql := "(" + column + " < ? OR " + column + " >= ?"
+		if nullable {
+			sql += " OR " + column + " IS NULL"
+		}
+		return sql + ")", nil
+	case OpLt:
+		c.vars = append(c.vars, r.start)
+		return column + " < ?", nil
+	case OpLte:
+		c.vars = append(c.vars, r.end)
+		return column + " < ?", nil
+	case OpGt:
+		c.vars = append(c.vars, r.end)
+		return column + " >= ?", nil
+	default:
+		c.vars = append(c.vars, r.start)
+		return column + " >= ?", nil
+	}
+}
+
+// dateValue resolves a date literal, today, now or a relative date such as -2w
+func (c *compiler) dateValue(v Value) (dateRange, error) {
+	now := c.env.Now
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	day := func(t time.Time) dateRange {
+		return dateRange{start: t, end: t.AddDate(0, 0, 1)}
+	}
+
+	switch v.Kind {
+	case ValueDate:
+		t, err := time.ParseInLocation("2006-01-02", v.Text, now.Location())
+		if err != nil {
+			return dateRange{}, errorf(v.Pos, "invalid date %q", v.Text)
+		}
+		return day(t), nil
+
+	case ValueRelativeDate:
+		amount, err := strconv.Atoi(v.Text[:len(v.Text)-1])
+		if err != nil {
+			return dateRange{}, errorf(v.Pos, "invalid relative date %q", v.Text)
+		}
+		switch v.Text[len(v.Text)-1] {
+		case 'h':
+			t := now.Add(time.Duration(amount) * time.Hour)
+			return dateRange{start: t, end: t, instant: true}, nil
+		case 'd':
+			return day(today.AddDate(0, 0, amount)), nil
+		case 'w':
+			return day(today.AddDate(0, 0, 7*amount)), nil
+		case 'm':
+			return day(today.AddDate(0, amount, 0)), nil
+		default:
+			return day(today.AddDate(amount, 0, 0)), nil
+		}
+
+	case ValueIdent:
+		switch strings.ToLower(v.Text) {
+		case "today":
+			return day(today), nil
+		case "now":
+			return dateRange{start: now, end: now, instant: true}, nil
+		}
+	}
+	return dateRange{}, errorf(v.Pos, "expected a date such as 2024-05-01, today, now or +7d")
+}
+
+func (c *compiler) label(cmp *Comparison) (string, error) {
+	switch cmp.Op {
+	case OpIsEmpty:
+		return "NOT " + labelExists + ")", nil
+	case OpIsNotEmpty:
+		return labelExists + ")", nil
+	}
+
+	names := make([]string, 0, len(cmp.Values))
+	for _, v := range cmp.Values {
+		if v.Kind != ValueIdent && v.Kind != ValueString {
+			return "", errorf(v.Pos, "expected a label name")
+		}
+		names = append(names, strings.ToLower(v.Text))
+	}
+	c.vars = append(c.vars, names)
+
+	sql := labelExists + " AND LOWER(labels.name) IN ?)"
+	if cmp.Op == OpNe || cmp.Op == OpNotIn {
+		return "NOT " + sql, nil
+	}
+	return sql, nil
+}
+
+// customField compiles a condition on a custom field into an EXISTS
+// subquery on the value column matching the field's type
+func (c *compiler) customField(cmp *Comparison, key string) (string, error) {
+	if c.env.CustomFields == nil {
+		return "", errorf(cmp.Pos, "custom fields can only be used in project filters")
+	}
+
+	var def *models.CustomFieldDefinition
+	for i := range c.env.CustomFields {
+		if c.env.CustomFields[i].Key == key {
+			def = &c.env.CustomFields[i]
+			break
+		}
+	}
+	if def == nil {
+		return "", errorf(cmp.Pos, "unknown custom field %q", key)
+	}
+
+	// Cleared values are deleted, so a missing row means the field is empty
+	exists := "EXISTS (SELECT 1 FROM custom_field_values cfv WHERE cfv.task_id = tasks.id AND cfv.field_id = ?"
+	column := "cfv." + def.ValueColumn()
+
+	var kind fieldKind
+	switch def.Type {
+	case models.CustomFieldTypeNumber, models.CustomFieldTypeCurrency:
+		kind = kindNumber
+	case models.CustomFieldTypeDate:
+		kind = kindDate
+	case models.CustomFieldTypeUser:
+		kind = kindUser
+	case models.CustomFieldTypeSingleSelect, models.CustomFieldTypeMultiSelect:
+		kind = kindEnum
+	default:
+		kind = kindText
+	}
+	if err := checkOp(cmp, kind, true); err != nil {
+		return "", err
+	}
+
+	switch cmp.Op {
+	case OpIsEmpty:
+		c.vars = append(c.vars, def.ID)
+		return "NOT " + exists + ")", nil
+	case OpIsNotEmpty:
+		c.vars = append(c.vars, def.ID)
+		return exists + ")", nil
+	}
+
+	// Negative conditions match tasks without a matching value, including
+	// tasks where the field is empty
+	negate := cmp.Op == OpNe || cmp.Op == OpNotIn || cmp.Op == OpNotContain
+	positive := *cmp
+	switch cmp.Op {
+	case OpNe:
+		positive.Op = OpEq
+	case OpNotIn:
+		positive.Op = OpIn
+	case OpNotContain:
+		positive.Op = OpContains
+	}
+
+	c.vars = append(c.vars, def.ID)
+	var condition string
+	switch {
+	case def.Type == models.CustomFieldTypeMultiSelect:
+		var parts []string
+		for _, v := range positive.Values {
+			option, err := c.option(def, v)
+			if err != nil {
+				return "", err
+			}
+			encoded, err := json.Marshal([]string{option})
+			if err != nil {
+				return "", err
+			}
+			c.vars = append(c.vars, string(encoded))
+			parts = append(parts, column+" @> ?::jsonb")
+		}
+		condition = "(" + strings.Join(parts, " OR ") + ")"
+
+	case kind == kindDate:
+		sql, err := c.date(column, false, &positive)
+		if err != nil {
+			return "", err
+		}
+		condition = sql
+
+	default:
+		f := field{column: column, kind: kind}
+		values := make([]interface{}, 0, len(positive.Values))
+		for _, v := range positive.Values {
+			var value interface{}
+			var err error
+			if def.Type == models.CustomFieldTypeSingleSelect {
+				value, err = c.option(def, v)
+			} else {
+				value, err = c.scalar(f, v)
+			}
+			if err != nil {
+				return "", err
+			}
+			values = append(values, value)
+		}
+		condition = c.scalarComparison(column, false, positive.Op, values)
+	}
+
+	sql := exists + " AND " + condition + ")"
+	if negate {
+		return "NOT " + sql, nil
+	}
+	return sql, nil
+}
+
+// option matches a literal against a select field's options, ignoring case
+func (c *compiler) option(def *models.CustomFieldDefinition, v Value) (string, error) {
+	for _, option := range def.Options {
+		if strings.EqualFold(option, v.Text) {
+			return option, nil
+		}
+	}
+	return "", errorf(v.Pos, "%q is not an option of %s", v.Text, def.Key)
+}
+
+func checkOp(cmp *Comparison, kind fieldKind, nullable bool) error {
+	if nullable && (cmp.Op == OpIsEmpty || cmp.Op == OpIsNotEmpty) {
+		return nil
+	}
+	for _, op := range allowedOps[kind] {
+		if op == cmp.Op {
+			return nil
+		}
+	}
+	return errorf(cmp.Pos, "operator %s is not supported for %s", cmp.Op, cmp.Field)
+}
+
+// emptiness compiles IS EMPTY and IS NOT EMPTY. Empty text counts as empty.
+func emptiness(column string, text, empty bool) string {
+	if empty {
+		if text {
+			return "(" + column + " IS NULL OR " + column + " = '')"
+		}
+		return column + " IS NULL"
+	}
+	if text {
+		return "(" + column + " IS NOT NULL AND " + column + " <> '')"
+	}
+	return column + " IS NOT NULL"
+}
+
+func parseID(v Value) (uint, error) {
+	if v.Kind != ValueNumber {
+		return 0, errorf(v.Pos, "expected an ID")
+	}
+	id, err := strconv.ParseUint(v.Text, 10, 64)
+	if err != nil {
+		return 0, errorf(v.Pos, "invalid ID %q", v.Text)
+	}
+	return uint(id), nil
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
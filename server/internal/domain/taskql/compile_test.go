@@ -0,0 +1,292 @@
+package taskql
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+)
+
+func TestCompile(t *testing.T) {
+	now := time.Date(2024, time.May, 10, 15, 30, 0, 0, time.UTC)
+	day := func(y int, m time.Month, d int) time.Time {
+		return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	}
+	team := models.CustomFieldDefinition{Model: gorm.Model{ID: 7}, Key: "team", Type: models.CustomFieldTypeSingleSelect, Options: models.StringList{"Core", "Growth"}}
+	env := Env{UserID: 42, Now: now, CustomFields: []models.CustomFieldDefinition{team}}
+
+	tests := []struct {
+		name  string
+		query string
+		sql   string
+		vars  []interface{}
+	}{
+		{
+			name:  "me is the current user",
+			query: "assignee = me",
+			sql:   "tasks.assignee_id = ?",
+			vars:  []interface{}{uint(42)},
+		},
+		{
+			name:  "me in a list",
+			query: "creator in (me, 3)",
+			sql:   "tasks.created_by_id IN ?",
+			vars:  []interface{}{[]interface{}{uint(42), uint(3)}},
+		},
+		{
+			name:  "negation on nullable field matches empty",
+			query: "assignee != me",
+			sql:   "(tasks.assignee_id <> ? OR tasks.assignee_id IS NULL)",
+			vars:  []interface{}{uint(42)},
+		},
+		{
+			name:  "before a relative day",
+			query: "due < +7d",
+			sql:   "tasks.due_date < ?",
+			vars:  []interface{}{day(2024, time.May, 17)},
+		},
+		{
+			name:  "on or before a relative day includes the whole day",
+			query: "due <= +7d",
+			sql:   "tasks.due_date < ?",
+			vars:  []interface{}{day(2024, time.May, 18)},
+		},
+		{
+			name:  "relative weeks and months",
+			query: "created >= -2w AND created < +1m",
+			sql:   "(tasks.created_at >= ? AND tasks.created_at < ?)",
+			vars:  []interface{}{day(2024, time.April, 26), day(2024, time.June, 10)},
+		},
+		{
+			name:  "relative hours are instants",
+			query: "updated > -3h",
+			sql:   "tasks.updated_at > ?",
+			vars:  []interface{}{now.Add(-3 * time.Hour)},
+		},
+		{
+			name:  "equal to a date covers the day",
+			query: "due = 2024-05-01",
+			sql:   "(tasks.due_date >= ? AND tasks.due_date < ?)",
+			vars:  []interface{}{day(2024, time.May, 1), day(2024, time.May, 2)},
+		},
+		{
+			name:  "enum in list",
+			query: "status in (todo, DONE)",
+			sql:   "tasks.status IN ?",
+			vars:  []interface{}{[]interface{}{"todo", "done"}},
+		},
+		{
+			name:  "label in list",
+			query: "label in (bug, Urgent)",
+			sql:   labelExists + " AND LOWER(labels.name) IN ?)",
+			vars:  []interface{}{[]string{"bug", "urgent"}},
+		},
+		{
+			name:  "label not in list",
+			query: "label not in (bug)",
+			sql:   "NOT " + labelExists + " AND LOWER(labels.name) IN ?)",
+			vars:  []interface{}{[]string{"bug"}},
+		},
+		{
+			name:  "contains escapes wildcards",
+			query: `title ~ "50%_off"`,
+			sql:   "tasks.title ILIKE ?",
+			vars:  []interface{}{`%50\%\_off%`},
+		},
+		{
+			name:  "empty text",
+			query: "description is empty",
+			sql:   "(tasks.description IS NULL OR tasks.description = '')",
+		},
+		{
+			name:  "custom select field",
+			query: "cf.team = core",
+			sql:   "EXISTS (SELECT 1 FROM custom_field_values cfv WHERE cfv.task_id = tasks.id AND cfv.field_id = ? AND cfv.text_value = ?)",
+			vars:  []interface{}{uint(7), "Core"},
+		},
+		{
+			name:  "documented example",
+			query: "assignee = me AND status != done AND due < +7d AND label in (bug, urgent)",
+			sql:   "(((tasks.assignee_id = ? AND tasks.status <> ?) AND tasks.due_date < ?) AND " + labelExists + " AND LOWER(labels.name) IN ?))",
+			vars:  []interface{}{uint(42), "done", day(2024, time.May, 17), []string{"bug", "urgent"}},
+		},
+		{
+			name:  "not and or",
+			query: "NOT (priority = high OR points > 3)",
+			sql:   "NOT COALESCE((tasks.priority = ? OR tasks.story_points > ?), FALSE)",
+			vars:  []interface{}{"high", float64(3)},
+		},
+		{
+			name:  "negated comparison on nullable field matches empty",
+			query: "NOT (assignee = me)",
+			sql:   "NOT COALESCE(tasks.assignee_id = ?, FALSE)",
+			vars:  []interface{}{uint(42)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := CompileString(tt.query, env)
+			if err != nil {
+				t.Fatalf("CompileString(%q) error: %v", tt.query, err)
+			}
+			got, ok := expr.(clause.Expr)
+			if !ok {
+				t.Fatalf("CompileString(%q) = %T, want clause.Expr", tt.query, expr)
+			}
+			if got.SQL != tt.sql {
+				t.Errorf("SQL = %s\nwant  %s", got.SQL, tt.sql)
+			}
+			if !reflect.DeepEqual(got.Vars, tt.vars) {
+				t.Errorf("Vars = %#v\nwant   %#v", got.Vars, tt.vars)
+			}
+		})
+	}
+}
+
+// TestNegationMatchesEmptyFields runs negated queries against tasks in a
+// scratch schema of the database in TEST_DATABASE_DSN, checking that NOT of a
+// comparison matches the same tasks as the negative comparison, including
+// tasks where the field is empty
+func TestNegationMatchesEmptyFields(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	random := make([]byte, 6)
+	rand.Read(random)
+	schema := "taskql_test_" + hex.EncodeToString(random)
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connecting: %v", err)
+	}
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("creating schema: %v", err)
+	}
+	t.Cleanup(func() { admin.Exec("DROP SCHEMA " + schema + " CASCADE") })
+	db, err := gorm.Open(postgres.Open(dsn+" search_path="+schema), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connecting to schema: %v", err)
+	}
+
+	setup := []string{
+		"CREATE TABLE tasks (id bigint PRIMARY KEY, assignee_id bigint, due_date timestamptz, priority varchar(20), story_points double precision)",
+		"INSERT INTO tasks VALUES (1, 42, '2024-05-12', 'high', 5), (2, 7, NULL, 'low', 1), (3, NULL, '2024-06-01', 'high', 2), (4, NULL, NULL, 'low', 8)",
+	}
+	for _, stmt := range setup {
+		if err := db.Exec(stmt).Error; err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+
+	env := Env{UserID: 42, Now: time.Date(2024, time.May, 10, 15, 30, 0, 0, time.UTC)}
+	ids := func(query string) []int64 {
+		t.Helper()
+		expr, err := CompileString(query, env)
+		if err != nil {
+			t.Fatalf("CompileString(%q): %v", query, err)
+		}
+		var ids []int64
+		if err := db.Table("tasks").Where(expr).Order("id").Pluck("id", &ids).Error; err != nil {
+			t.Fatalf("running %q: %v", query, err)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		negated, negative string
+		want              []int64
+	}{
+		{"NOT (assignee = me)", "assignee != me", []int64{2, 3, 4}},
+		{"NOT (assignee in (me, 7))", "assignee not in (me, 7)", []int64{3, 4}},
+		{"NOT (due = 2024-05-12)", "due != 2024-05-12", []int64{2, 3, 4}},
+		{"NOT (assignee = me AND priority = high)", "assignee != me OR priority != high", []int64{2, 3, 4}},
+		{"NOT (assignee = me OR due < +7d)", "assignee != me AND NOT (due < +7d)", []int64{2, 3, 4}},
+	}
+	for _, tt := range tests {
+		negated, negative := ids(tt.negated), ids(tt.negative)
+		if !reflect.DeepEqual(negated, tt.want) || !reflect.DeepEqual(negative, tt.want) {
+			t.Errorf("%q matched %v and %q matched %v, want %v", tt.negated, negated, tt.negative, negative, tt.want)
+		}
+	}
+}
+
+func TestCompileEmptyQuery(t *testing.T) {
+	expr, err := CompileString("", Env{})
+	if err != nil || expr != nil {
+		t.Errorf("CompileString(\"\") = %v, %v, want nil, nil", expr, err)
+	}
+}
+
+// TestCompileKeepsInputOutOfSQL checks that literals only ever reach the
+// database as bound parameters
+func TestCompileKeepsInputOutOfSQL(t *testing.T) {
+	env := Env{UserID: 1, Now: time.Now()}
+	tests := []struct {
+		query string
+		sql   string
+		value interface{}
+	}{
+		{`title = "x'; DROP TABLE tasks; --"`, "tasks.title = ?", "x'; DROP TABLE tasks; --"},
+		{`title != 'a" OR 1=1 --'`, "tasks.title <> ?", `a" OR 1=1 --`},
+		{`description ~ "') OR ('1'='1"`, "tasks.description ILIKE ?", "%') OR ('1'='1%"},
+	}
+	for _, tt := range tests {
+		expr, err := CompileString(tt.query, env)
+		if err != nil {
+			t.Fatalf("CompileString(%q) error: %v", tt.query, err)
+		}
+		got := expr.(clause.Expr)
+		if got.SQL != tt.sql {
+			t.Errorf("CompileString(%q) SQL = %s, want %s", tt.query, got.SQL, tt.sql)
+		}
+		if !reflect.DeepEqual(got.Vars, []interface{}{tt.value}) {
+			t.Errorf("CompileString(%q) Vars = %#v, want %#v", tt.query, got.Vars, []interface{}{tt.value})
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	env := Env{UserID: 1, Now: time.Now()}
+	tests := []struct {
+		name  string
+		query string
+		env   Env
+		pos   int
+		msg   string
+	}{
+		{"unknown field", "owner = me", env, 0, `unknown field "owner"`},
+		{"unknown field after valid one", "status = done AND tasks.id = 1", env, 18, `unknown field "tasks.id"`},
+		{"column injection", "id; = 1", env, 2, "unexpected character ';'"},
+		{"quoted field", `"status" = done`, env, 0, "expected a field name"},
+		{"enum injection", `status = "done' OR '1'='1"`, env, 9, `"done' OR '1'='1" is not one of todo, in_progress, in_review, done`},
+		{"id injection", `id = "1 OR 1=1"`, env, 5, "expected an ID"},
+		{"number expected", "points > high", env, 9, "expected a number"},
+		{"unsupported operator", "status < done", env, 0, "operator < is not supported for status"},
+		{"invalid date", "due < 2024-13-40", env, 6, `invalid date "2024-13-40"`},
+		{"date expected", "due < tomorrow", env, 6, "expected a date such as 2024-05-01, today, now or +7d"},
+		{"custom field outside project", "cf.team = core", env, 0, "custom fields can only be used in project filters"},
+		{"unknown custom field", "cf.team = core", Env{CustomFields: []models.CustomFieldDefinition{}}, 0, `unknown custom field "team"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CompileString(tt.query, tt.env)
+			var qerr *Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("CompileString(%q) error = %v, want *Error", tt.query, err)
+			}
+			if qerr.Pos != tt.pos || qerr.Msg != tt.msg {
+				t.Errorf("CompileString(%q) error = %q at %d, want %q at %d", tt.query, qerr.Msg, qerr.Pos, tt.msg, tt.pos)
+			}
+		})
+	}
+}
@@ -0,0 +1,122 @@
+package taskql
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenDate
+	tokenRelativeDate
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+var (
+	dateRegex         = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	relativeDateRegex = regexp.MustCompile(`^[+-]\d+[hdwmy]$`)
+	numberRegex       = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+)
+
+// keyword reports whether an identifier token is the given keyword
+func (t token) keyword(word string) bool {
+	return t.kind == tokenIdent && strings.EqualFold(t.text, word)
+}
+
+// lex splits a query into tokens
+func lex(input string) ([]token, error) {
+	var tokens []token
+	runes := []rune(input)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+
+		case r == '"' || r == '\'':
+			start := i
+			var sb strings.Builder
+			i++
+			for i < len(runes) && runes[i] != r {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, errorf(start, "unterminated string")
+			}
+			i++
+			tokens = append(tokens, token{kind: tokenString, text: sb.String(), pos: start})
+
+		case r == '=' || r == '<' || r == '>' || r == '!' || r == '~':
+			start := i
+			op := string(r)
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '!' && runes[i+1] == '~')) {
+				op += string(runes[i+1])
+			}
+			if op == "!" {
+				return nil, errorf(start, "unexpected character %q", r)
+			}
+			i += len(op)
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: start})
+
+		case r == '+' || r == '-' || unicode.IsDigit(r):
+			start := i
+			i++
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '-' || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			switch {
+			case dateRegex.MatchString(text):
+				tokens = append(tokens, token{kind: tokenDate, text: text, pos: start})
+			case relativeDateRegex.MatchString(text):
+				tokens = append(tokens, token{kind: tokenRelativeDate, text: text, pos: start})
+			case numberRegex.MatchString(text):
+				tokens = append(tokens, token{kind: tokenNumber, text: text, pos: start})
+			default:
+				return nil, errorf(start, "invalid literal %q", text)
+			}
+
+		case unicode.IsLetter(r) || r == '_':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '_' || runes[i] == '.' || runes[i] == '-') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: string(runes[start:i]), pos: start})
+
+		default:
+			return nil, errorf(i, "unexpected character %q", r)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes)}), nil
+}
@@ -0,0 +1,85 @@
+package taskql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLex(t *testing.T) {
+	tokens, err := lex(`due < +7d AND due >= 2024-05-01 AND points != -1.5 AND title ~ "say \"hi\"" AND label in (a, b)`)
+	if err != nil {
+		t.Fatalf("lex error: %v", err)
+	}
+
+	want := []token{
+		{tokenIdent, "due", 0},
+		{tokenOperator, "<", 4},
+		{tokenRelativeDate, "+7d", 6},
+		{tokenIdent, "AND", 10},
+		{tokenIdent, "due", 14},
+		{tokenOperator, ">=", 18},
+		{tokenDate, "2024-05-01", 21},
+		{tokenIdent, "AND", 32},
+		{tokenIdent, "points", 36},
+		{tokenOperator, "!=", 43},
+		{tokenNumber, "-1.5", 46},
+		{tokenIdent, "AND", 51},
+		{tokenIdent, "title", 55},
+		{tokenOperator, "~", 61},
+		{tokenString, `say "hi"`, 63},
+		{tokenIdent, "AND", 76},
+		{tokenIdent, "label", 80},
+		{tokenIdent, "in", 86},
+		{tokenLParen, "(", 89},
+		{tokenIdent, "a", 90},
+		{tokenComma, ",", 91},
+		{tokenIdent, "b", 93},
+		{tokenRParen, ")", 94},
+		{tokenEOF, "", 95},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("lex returned %d tokens, want %d: %v", len(tokens), len(want), tokens)
+	}
+	for i := range want {
+		if tokens[i] != want[i] {
+			t.Errorf("token %d = %+v, want %+v", i, tokens[i], want[i])
+		}
+	}
+}
+
+func TestLexErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		pos   int
+		msg   string
+	}{
+		{"unterminated string", `title = "open`, 8, "unterminated string"},
+		{"unterminated single quoted string", `title = 'it\'s`, 8, "unterminated string"},
+		{"lone bang", "status ! done", 7, `unexpected character '!'`},
+		{"unknown character", "title = @x", 8, `unexpected character '@'`},
+		{"statement separator", "id = 1; DROP TABLE tasks", 6, `unexpected character ';'`},
+		{"invalid literal", "due < 7x", 6, `invalid literal "7x"`},
+		{"invalid relative unit", "due < +7q", 6, `invalid literal "+7q"`},
+		{"position counts runes", "title = é @", 10, `unexpected character '@'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := lex(tt.input)
+			var qerr *Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("lex(%q) error = %v, want *Error", tt.input, err)
+			}
+			if qerr.Pos != tt.pos || qerr.Msg != tt.msg {
+				t.Errorf("lex(%q) error = %q at %d, want %q at %d", tt.input, qerr.Msg, qerr.Pos, tt.msg, tt.pos)
+			}
+		})
+	}
+}
+
+func TestErrorReportsOneBasedPosition(t *testing.T) {
+	_, err := Parse(`title = "open`)
+	if err == nil || err.Error() != "unterminated string at position 9" {
+		t.Errorf("error = %v, want unterminated string at position 9", err)
+	}
+}
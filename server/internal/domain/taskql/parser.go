@@ -0,0 +1,218 @@
+package taskql
+
+import "strings"
+
+// maxDepth bounds nesting so hostile queries cannot exhaust the stack
+const maxDepth = 32
+
+// Parse parses a query into an AST. An empty query yields a nil node, which matches every task.
+func Parse(input string) (Node, error) {
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, nil
+	}
+
+	node, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, errorf(tok.pos, "unexpected %q", tok.text)
+	}
+	return node, nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+// parseOr parses: and ("OR" and)*
+func (p *parser) parseOr(depth int) (Node, error) {
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword("OR") {
+		p.next()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &LogicalExpr{Op: "OR", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseAnd parses: unary ("AND" unary)*
+func (p *parser) parseAnd(depth int) (Node, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.peek().keyword("AND") {
+		p.next()
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &LogicalExpr{Op: "AND", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+// parseUnary parses: "NOT" unary | "(" or ")" | comparison
+func (p *parser) parseUnary(depth int) (Node, error) {
+	if depth > maxDepth {
+		return nil, errorf(p.peek().pos, "query is nested too deeply")
+	}
+
+	tok := p.peek()
+	switch {
+	case tok.keyword("NOT"):
+		p.next()
+		expr, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &NotExpr{Expr: expr}, nil
+
+	case tok.kind == tokenLParen:
+		p.next()
+		expr, err := p.parseOr(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, errorf(closing.pos, "expected )")
+		}
+		return expr, nil
+	}
+
+	return p.parseComparison()
+}
+
+// parseComparison parses: field op value | field [NOT] IN (values) | field IS [NOT] EMPTY
+func (p *parser) parseComparison() (Node, error) {
+	field := p.next()
+	if field.kind != tokenIdent || isReserved(field.text) {
+		return nil, errorf(field.pos, "expected a field name")
+	}
+	cmp := &Comparison{Field: strings.ToLower(field.text), Pos: field.pos}
+
+	tok := p.next()
+	switch {
+	case tok.kind == tokenOperator:
+		cmp.Op = tok.text
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		cmp.Values = []Value{value}
+
+	case tok.keyword("IN"):
+		cmp.Op = OpIn
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		cmp.Values = values
+
+	case tok.keyword("NOT"):
+		if in := p.next(); !in.keyword("IN") {
+			return nil, errorf(in.pos, "expected IN after NOT")
+		}
+		cmp.Op = OpNotIn
+		values, err := p.parseList()
+		if err != nil {
+			return nil, err
+		}
+		cmp.Values = values
+
+	case tok.keyword("IS"):
+		cmp.Op = OpIsEmpty
+		next := p.next()
+		if next.keyword("NOT") {
+			cmp.Op = OpIsNotEmpty
+			next = p.next()
+		}
+		if !next.keyword("EMPTY") {
+			return nil, errorf(next.pos, "expected EMPTY")
+		}
+
+	default:
+		return nil, errorf(tok.pos, "expected an operator after %q", field.text)
+	}
+
+	return cmp, nil
+}
+
+// parseList parses: "(" value ("," value)* ")"
+func (p *parser) parseList() ([]Value, error) {
+	if open := p.next(); open.kind != tokenLParen {
+		return nil, errorf(open.pos, "expected (")
+	}
+
+	var values []Value
+	for {
+		value, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+
+		tok := p.next()
+		if tok.kind == tokenRParen {
+			return values, nil
+		}
+		if tok.kind != tokenComma {
+			return nil, errorf(tok.pos, "expected , or )")
+		}
+	}
+}
+
+func (p *parser) parseValue() (Value, error) {
+	tok := p.next()
+	switch tok.kind {
+	case tokenIdent:
+		if isReserved(tok.text) {
+			return Value{}, errorf(tok.pos, "expected a value, got %q", tok.text)
+		}
+		return Value{Kind: ValueIdent, Text: tok.text, Pos: tok.pos}, nil
+	case tokenString:
+		return Value{Kind: ValueString, Text: tok.text, Pos: tok.pos}, nil
+	case tokenNumber:
+		return Value{Kind: ValueNumber, Text: tok.text, Pos: tok.pos}, nil
+	case tokenDate:
+		return Value{Kind: ValueDate, Text: tok.text, Pos: tok.pos}, nil
+	case tokenRelativeDate:
+		return Value{Kind: ValueRelativeDate, Text: tok.text, Pos: tok.pos}, nil
+	}
+	return Value{}, errorf(tok.pos, "expected a value")
+}
+
+// isReserved reports whether an identifier is a keyword of the language
+func isReserved(word string) bool {
+	switch strings.ToUpper(word) {
+	case "AND", "OR", "NOT", "IN", "IS", "EMPTY":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package taskql
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// render prints a parsed query with every logical expression parenthesized,
+// so that tests can assert how the parser grouped it
+func render(node Node) string {
+	switch n := node.(type) {
+	case nil:
+		return ""
+	case *LogicalExpr:
+		return "(" + render(n.Left) + " " + n.Op + " " + render(n.Right) + ")"
+	case *NotExpr:
+		return "NOT " + render(n.Expr)
+	case *Comparison:
+		texts := make([]string, 0, len(n.Values))
+		for _, v := range n.Values {
+			texts = append(texts, v.Text)
+		}
+		switch n.Op {
+		case OpIsEmpty, OpIsNotEmpty:
+			return n.Field + " " + n.Op
+		case OpIn, OpNotIn:
+			return n.Field + " " + n.Op + " [" + strings.Join(texts, ",") + "]"
+		}
+		return n.Field + " " + n.Op + " " + texts[0]
+	}
+	return "?"
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{"empty", "   ", ""},
+		{"comparison", "status = done", "status = done"},
+		{"field is case insensitive", "Status = done", "status = done"},
+		{"and binds tighter than or", "a = 1 OR b = 2 AND c = 3", "(a = 1 OR (b = 2 AND c = 3))"},
+		{"and before or", "a = 1 AND b = 2 OR c = 3", "((a = 1 AND b = 2) OR c = 3)"},
+		{"left associative", "a = 1 AND b = 2 AND c = 3", "((a = 1 AND b = 2) AND c = 3)"},
+		{"parentheses override precedence", "(a = 1 OR b = 2) AND c = 3", "((a = 1 OR b = 2) AND c = 3)"},
+		{"not binds tightest", "NOT a = 1 AND b = 2", "(NOT a = 1 AND b = 2)"},
+		{"not of group", "NOT (a = 1 OR b = 2)", "NOT (a = 1 OR b = 2)"},
+		{"keywords are case insensitive", "a = 1 and not b = 2 or c = 3", "((a = 1 AND NOT b = 2) OR c = 3)"},
+		{"in list", "label in (bug, 'needs review')", "label IN [bug,needs review]"},
+		{"not in list", "label NOT IN (bug)", "label NOT IN [bug]"},
+		{"is empty", "due IS EMPTY", "due IS EMPTY"},
+		{"is not empty", "due is not empty", "due IS NOT EMPTY"},
+		{"two character operators", "a <= 1 AND b >= 2 AND c != 3 AND d !~ x", "(((a <= 1 AND b >= 2) AND c != 3) AND d !~ x)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			node, err := Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error: %v", tt.query, err)
+			}
+			if got := render(node); got != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		pos   int
+		msg   string
+	}{
+		{"missing operator", "status done", 7, `expected an operator after "status"`},
+		{"missing value", "status =", 8, "expected a value"},
+		{"keyword as value", "status = AND", 9, `expected a value, got "AND"`},
+		{"keyword as field", "AND = 1", 0, "expected a field name"},
+		{"unclosed group", "(a = 1", 6, "expected )"},
+		{"unopened list", "label in bug", 9, "expected ("},
+		{"unclosed list", "label in (bug urgent)", 14, "expected , or )"},
+		{"not without in", "label not bug", 10, "expected IN after NOT"},
+		{"is without empty", "due is null", 7, "expected EMPTY"},
+		{"trailing tokens", "a = 1 b = 2", 6, `unexpected "b"`},
+		{"dangling and", "a = 1 AND", 9, "expected a field name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.query)
+			var qerr *Error
+			if !errors.As(err, &qerr) {
+				t.Fatalf("Parse(%q) error = %v, want *Error", tt.query, err)
+			}
+			if qerr.Pos != tt.pos || qerr.Msg != tt.msg {
+				t.Errorf("Parse(%q) error = %q at %d, want %q at %d", tt.query, qerr.Msg, qerr.Pos, tt.msg, tt.pos)
+			}
+		})
+	}
+}
+
+func TestParseDepthLimit(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat("(", depth) + "a = 1" + strings.Repeat(")", depth)
+	}
+
+	if _, err := Parse(nested(maxDepth)); err != nil {
+		t.Fatalf("Parse at depth %d error: %v", maxDepth, err)
+	}
+
+	_, err := Parse(nested(maxDepth + 1))
+	var qerr *Error
+	if !errors.As(err, &qerr) || qerr.Msg != "query is nested too deeply" {
+		t.Fatalf("Parse at depth %d error = %v, want nesting error", maxDepth+1, err)
+	}
+	if qerr.Pos != maxDepth+1 {
+		t.Errorf("nesting error at %d, want %d", qerr.Pos, maxDepth+1)
+	}
+
+	// NOT nests as deeply as parentheses do
+	if _, err := Parse(strings.Repeat("NOT ", maxDepth+1) + "a = 1"); err == nil {
+		t.Error("Parse of deeply negated query succeeded, want nesting error")
+	}
+
+	// A hostile query fails fast instead of exhausting the stack
+	if _, err := Parse(nested(100000)); err == nil {
+		t.Error("Parse of hostile query succeeded, want nesting error")
+	}
+}
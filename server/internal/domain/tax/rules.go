@@ -0,0 +1,218 @@
+// Package tax works out the VAT, GST and similar taxes on invoices. Rates and
+// tax ID formats come from a rules file listing the jurisdictions taxes are
+// charged in; the default one is embedded and can be replaced by a file of
+// the same format.
+package tax
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrInvalidRules   = errors.New("invalid tax rules")
+	ErrInvalidRate    = errors.New("tax rate must be a percentage between 0 and 100 with at most two decimals")
+	ErrInvalidCountry = errors.New("country must be a two-letter ISO 3166 code")
+	ErrInvalidTaxID   = errors.New("tax ID does not match the format of the country")
+)
+
+//go:embed rules.json
+var defaultRules []byte
+
+var countryPattern = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// Rate is a tax rate in hundredths of a percent, so 19% is 1900
+type Rate int64
+
+// ParseRate reads a percentage such as "19" or "25.5"
+func ParseRate(s string) (Rate, error) {
+	whole, frac, _ := strings.Cut(strings.TrimSpace(s), ".")
+	if len(frac) > 2 {
+		return 0, ErrInvalidRate
+	}
+	frac += strings.Repeat("0", 2-len(frac))
+	n, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil || n < 0 || n > 10000 {
+		return 0, ErrInvalidRate
+	}
+	return Rate(n), nil
+}
+
+// String formats the rate as a percentage, such as "25.50"
+func (r Rate) String() string {
+	return fmt.Sprintf("%d.%02d", r/100, r%100)
+}
+
+// MarshalJSON encodes the rate as a decimal string, such as "19.00"
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return json.Marshal(r.String())
+}
+
+// Jurisdiction is a country that taxes the services sold to customers in it
+type Jurisdiction struct {
+	Country          string `json:"country"`
+	Name             string `json:"name"`
+	TaxName          string `json:"tax_name"` // VAT, GST...
+	Rate             Rate   `json:"rate"`
+	EU               bool   `json:"eu"`                 // part of the EU VAT area
+	PricesIncludeTax bool   `json:"prices_include_tax"` // prices shown to customers include the tax
+	TaxIDPrefix      string `json:"tax_id_prefix,omitempty"`
+	TaxIDExample     string `json:"tax_id_example,omitempty"`
+
+	taxIDPattern *regexp.Regexp
+}
+
+// Rules are the jurisdictions taxes are charged in. Customers elsewhere are
+// not charged tax.
+type Rules struct {
+	jurisdictions map[string]*Jurisdiction
+}
+
+// rulesFile is the format of a rules file
+type rulesFile struct {
+	PricesIncludeTax bool `json:"prices_include_tax"` // the default for jurisdictions that do not say
+	Jurisdictions    []struct {
+		Country          string `json:"country"`
+		Name             string `json:"name"`
+		TaxName          string `json:"tax_name"`
+		Rate             string `json:"rate"`
+		EU               bool   `json:"eu"`
+		PricesIncludeTax *bool  `json:"prices_include_tax"`
+		TaxIDPrefix      string `json:"tax_id_prefix"`
+		TaxIDPattern     string `json:"tax_id_pattern"`
+		TaxIDExample     string `json:"tax_id_example"`
+	} `json:"jurisdictions"`
+}
+
+// DefaultRules returns the embedded rules
+func DefaultRules() *Rules {
+	rules, err := LoadRules(strings.NewReader(string(defaultRules)))
+	if err != nil {
+		panic(err)
+	}
+	return rules
+}
+
+// LoadRulesFile reads rules from a JSON file
+func LoadRulesFile(path string) (*Rules, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadRules(f)
+}
+
+// LoadRules reads rules in JSON format
+func LoadRules(r io.Reader) (*Rules, error) {
+	var file rulesFile
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRules, err)
+	}
+
+	rules := &Rules{jurisdictions: make(map[string]*Jurisdiction, len(file.Jurisdictions))}
+	for _, entry := range file.Jurisdictions {
+		if !countryPattern.MatchString(entry.Country) {
+			return nil, fmt.Errorf("%w: %q: %v", ErrInvalidRules, entry.Country, ErrInvalidCountry)
+		}
+		if _, ok := rules.jurisdictions[entry.Country]; ok {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrInvalidRules, entry.Country)
+		}
+		rate, err := ParseRate(entry.Rate)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidRules, entry.Country, err)
+		}
+
+		jurisdiction := &Jurisdiction{
+			Country:          entry.Country,
+			Name:             entry.Name,
+			TaxName:          entry.TaxName,
+			Rate:             rate,
+			EU:               entry.EU,
+			PricesIncludeTax: file.PricesIncludeTax,
+			TaxIDPrefix:      entry.TaxIDPrefix,
+			TaxIDExample:     entry.TaxIDExample,
+		}
+		if entry.PricesIncludeTax != nil {
+			jurisdiction.PricesIncludeTax = *entry.PricesIncludeTax
+		}
+		if jurisdiction.TaxName == "" {
+			jurisdiction.TaxName = "Tax"
+		}
+		if entry.TaxIDPattern != "" {
+			if jurisdiction.taxIDPattern, err = regexp.Compile(entry.TaxIDPattern); err != nil {
+				return nil, fmt.Errorf("%w: %s tax ID pattern: %v", ErrInvalidRules, entry.Country, err)
+			}
+		}
+		rules.jurisdictions[entry.Country] = jurisdiction
+	}
+	return rules, nil
+}
+
+// Jurisdiction returns the rules of a country, or nil if it charges no tax
+func (r *Rules) Jurisdiction(country string) *Jurisdiction {
+	return r.jurisdictions[NormalizeCountry(country)]
+}
+
+// Jurisdictions lists the jurisdictions by country code
+func (r *Rules) Jurisdictions() []Jurisdiction {
+	list := make([]Jurisdiction, 0, len(r.jurisdictions))
+	for _, jurisdiction := range r.jurisdictions {
+		list = append(list, *jurisdiction)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Country < list[j].Country })
+	return list
+}
+
+// NormalizeCountry upper-cases a country code
+func NormalizeCountry(country string) string {
+	return strings.ToUpper(strings.TrimSpace(country))
+}
+
+// ValidateCountry checks that a country is a two-letter code. Countries
+// without rules are valid; they are just not taxed.
+func ValidateCountry(country string) error {
+	if !countryPattern.MatchString(NormalizeCountry(country)) {
+		return ErrInvalidCountry
+	}
+	return nil
+}
+
+// NormalizeTaxID returns the tax ID in the canonical form of the country:
+// upper case, without spaces, dots or dashes, and with the country's prefix.
+// An ID written with the country code where the prefix differs, such as
+// GR for Greece's EL, gets the prefix instead. It fails if the ID does not
+// match the country's format. IDs of countries without a known format are
+// only cleaned up.
+func (r *Rules) NormalizeTaxID(country, taxID string) (string, error) {
+	id := strings.ToUpper(taxID)
+	id = strings.NewReplacer(" ", "", ".", "", "-", "", "/", "").Replace(id)
+	if id == "" {
+		return "", ErrInvalidTaxID
+	}
+
+	jurisdiction := r.Jurisdiction(country)
+	if jurisdiction == nil || jurisdiction.taxIDPattern == nil {
+		return id, nil
+	}
+	if prefix := jurisdiction.TaxIDPrefix; prefix != "" && !strings.HasPrefix(id, prefix) {
+		id = prefix + strings.TrimPrefix(id, jurisdiction.Country)
+	}
+	if !jurisdiction.taxIDPattern.MatchString(id) {
+		if jurisdiction.TaxIDExample != "" {
+			return "", fmt.Errorf("%w (such as %s)", ErrInvalidTaxID, jurisdiction.TaxIDExample)
+		}
+		return "", ErrInvalidTaxID
+	}
+	return id, nil
+}
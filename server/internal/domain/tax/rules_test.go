@@ -0,0 +1,98 @@
+package tax
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestNormalizeTaxID(t *testing.T) {
+	rules := DefaultRules()
+	tests := []struct {
+		country string
+		taxID   string
+		want    string
+		err     error
+	}{
+		{"DE", "DE123456789", "DE123456789", nil},
+		{"de", "de 123.456.789", "DE123456789", nil},
+		{"DE", "123456789", "DE123456789", nil},
+		{"AT", "U12345678", "ATU12345678", nil},
+		{"FR", "FR-12-345678901", "FR12345678901", nil},
+		// Greek VAT IDs use EL rather than the ISO code GR
+		{"GR", "EL123456789", "EL123456789", nil},
+		{"GR", "GR123456789", "EL123456789", nil},
+		{"GR", "gr 123 456 789", "EL123456789", nil},
+		{"GR", "123456789", "EL123456789", nil},
+		{"GR", "GR12345678", "", ErrInvalidTaxID},
+		{"JP", "1234567890123", "T1234567890123", nil},
+		{"IN", "22aaaaa0000a1z5", "22AAAAA0000A1Z5", nil},
+		{"DE", "DE12345678", "", ErrInvalidTaxID},
+		{"DE", "FR12345678901", "", ErrInvalidTaxID},
+		{"DE", " - ", "", ErrInvalidTaxID},
+		// Countries without rules are only cleaned up
+		{"US", "12-3456789", "123456789", nil},
+	}
+	for _, tt := range tests {
+		got, err := rules.NormalizeTaxID(tt.country, tt.taxID)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("NormalizeTaxID(%q, %q) = %q, %v, want %q, %v", tt.country, tt.taxID, got, err, tt.want, tt.err)
+		}
+	}
+
+	if _, err := rules.NormalizeTaxID("GR", "EL1"); err == nil || !strings.Contains(err.Error(), "EL123456789") {
+		t.Errorf("invalid Greek ID error = %v, want the example ID", err)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		s    string
+		want Rate
+		err  error
+	}{
+		{"19", 1900, nil},
+		{"25.5", 2550, nil},
+		{"7.75", 775, nil},
+		{" 0 ", 0, nil},
+		{"100", 10000, nil},
+		{"100.01", 0, ErrInvalidRate},
+		{"-1", 0, ErrInvalidRate},
+		{"1.234", 0, ErrInvalidRate},
+		{"abc", 0, ErrInvalidRate},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.s)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("ParseRate(%q) = %v, %v, want %v, %v", tt.s, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestLoadRules(t *testing.T) {
+	rules, err := LoadRules(strings.NewReader(`{"prices_include_tax": true, "jurisdictions": [
+		{"country": "NZ", "name": "New Zealand", "tax_name": "GST", "rate": "15"},
+		{"country": "XX", "name": "Exclusive", "rate": "5", "prices_include_tax": false}
+	]}`))
+	if err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+	if nz := rules.Jurisdiction("nz"); nz == nil || nz.Rate != 1500 || !nz.PricesIncludeTax {
+		t.Errorf("NZ = %+v", nz)
+	}
+	if xx := rules.Jurisdiction("XX"); xx == nil || xx.TaxName != "Tax" || xx.PricesIncludeTax {
+		t.Errorf("XX = %+v", xx)
+	}
+
+	for _, invalid := range []string{
+		`{"jurisdictions": [{"country": "nz", "rate": "15"}]}`,
+		`{"jurisdictions": [{"country": "NZ", "rate": "15"}, {"country": "NZ", "rate": "15"}]}`,
+		`{"jurisdictions": [{"country": "NZ", "rate": "fifteen"}]}`,
+		`{"jurisdictions": [{"country": "NZ", "rate": "15", "tax_id_pattern": "("}]}`,
+		`{"jurisdictions": [{"country": "NZ", "rate": "15", "unknown": true}]}`,
+	} {
+		if _, err := LoadRules(strings.NewReader(invalid)); !errors.Is(err, ErrInvalidRules) {
+			t.Errorf("LoadRules(%s) error = %v, want ErrInvalidRules", invalid, err)
+		}
+	}
+}
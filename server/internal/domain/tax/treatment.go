@@ -0,0 +1,73 @@
+package tax
+
+import (
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+// reverseChargeNote is printed on invoices the customer accounts for the VAT of
+const reverseChargeNote = "Reverse charge: VAT to be accounted for by the recipient (Article 196, Directive 2006/112/EC)"
+
+// Treatment is how an invoice to a customer is taxed. The zero Treatment
+// charges no tax.
+type Treatment struct {
+	Country       string `json:"country,omitempty"` // where the tax is due
+	TaxName       string `json:"tax_name,omitempty"`
+	Rate          Rate   `json:"rate"`
+	Inclusive     bool   `json:"inclusive"` // amounts already include the tax
+	ReverseCharge bool   `json:"reverse_charge"`
+	Note          string `json:"note,omitempty"`
+}
+
+// Determine works out how a customer is taxed. Tax is due where the customer
+// is, at the rate of their country. EU businesses with a valid VAT ID buying
+// from another country account for the VAT themselves, so none is charged.
+// Customers in countries without rules are not charged tax.
+func (r *Rules) Determine(sellerCountry, customerCountry, customerTaxID string) Treatment {
+	jurisdiction := r.Jurisdiction(customerCountry)
+	if jurisdiction == nil {
+		return Treatment{}
+	}
+
+	if jurisdiction.EU && customerTaxID != "" && NormalizeCountry(sellerCountry) != jurisdiction.Country {
+		if _, err := r.NormalizeTaxID(jurisdiction.Country, customerTaxID); err == nil {
+			return Treatment{
+				Country:       jurisdiction.Country,
+				TaxName:       jurisdiction.TaxName,
+				Inclusive:     jurisdiction.PricesIncludeTax,
+				ReverseCharge: true,
+				Note:          reverseChargeNote,
+			}
+		}
+	}
+
+	return Treatment{
+		Country:   jurisdiction.Country,
+		TaxName:   jurisdiction.TaxName,
+		Rate:      jurisdiction.Rate,
+		Inclusive: jurisdiction.PricesIncludeTax,
+	}
+}
+
+// IsTaxed checks if the treatment charges any tax
+func (t Treatment) IsTaxed() bool {
+	return t.Rate > 0
+}
+
+// Split returns the part of an amount before tax and the tax on it, rounded
+// half away from zero to the currency's minor unit. An inclusive amount is
+// split into the two; an exclusive one is all before tax, and the tax is
+// added on top.
+func (t Treatment) Split(amount money.Money) (net, tax money.Money, err error) {
+	if !t.IsTaxed() {
+		return amount, money.Zero(amount.Currency), nil
+	}
+	if !t.Inclusive {
+		tax, err = amount.MulRat(int64(t.Rate), 10000)
+		return amount, tax, err
+	}
+	if tax, err = amount.MulRat(int64(t.Rate), 10000+int64(t.Rate)); err != nil {
+		return money.Money{}, money.Money{}, err
+	}
+	net, err = amount.Sub(tax)
+	return net, tax, err
+}
@@ -0,0 +1,85 @@
+package tax
+
+import (
+	"testing"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+func TestDetermine(t *testing.T) {
+	rules := DefaultRules()
+	tests := []struct {
+		name     string
+		seller   string
+		customer string
+		taxID    string
+		want     Treatment
+	}{
+		{
+			name: "EU business in another country", seller: "DE", customer: "FR", taxID: "FR12345678901",
+			want: Treatment{Country: "FR", TaxName: "VAT", ReverseCharge: true, Note: reverseChargeNote},
+		},
+		{
+			name: "Greek business with a GR-prefixed ID", seller: "DE", customer: "GR", taxID: "GR123456789",
+			want: Treatment{Country: "GR", TaxName: "VAT", ReverseCharge: true, Note: reverseChargeNote},
+		},
+		{
+			name: "business in the seller's country", seller: "DE", customer: "DE", taxID: "DE123456789",
+			want: Treatment{Country: "DE", TaxName: "VAT", Rate: 1900},
+		},
+		{
+			name: "domestic consumer", seller: "de", customer: "de",
+			want: Treatment{Country: "DE", TaxName: "VAT", Rate: 1900},
+		},
+		{
+			name: "EU consumer in another country", seller: "DE", customer: "FR",
+			want: Treatment{Country: "FR", TaxName: "VAT", Rate: 2000},
+		},
+		{
+			name: "EU business with an invalid ID", seller: "DE", customer: "FR", taxID: "FR123",
+			want: Treatment{Country: "FR", TaxName: "VAT", Rate: 2000},
+		},
+		{
+			name: "business outside the EU", seller: "DE", customer: "GB", taxID: "GB123456789",
+			want: Treatment{Country: "GB", TaxName: "VAT", Rate: 2000},
+		},
+		{
+			name: "prices including tax", seller: "US", customer: "JP",
+			want: Treatment{Country: "JP", TaxName: "Consumption tax", Rate: 1000, Inclusive: true},
+		},
+		{
+			name: "country without rules", seller: "DE", customer: "US", taxID: "123456789",
+			want: Treatment{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := rules.Determine(tt.seller, tt.customer, tt.taxID); got != tt.want {
+				t.Errorf("Determine(%q, %q, %q) = %+v, want %+v", tt.seller, tt.customer, tt.taxID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		treatment Treatment
+		amount    int64
+		net, tax  int64
+	}{
+		{Treatment{Rate: 1900}, 10000, 10000, 1900},
+		{Treatment{Rate: 1900}, 1, 1, 0},
+		{Treatment{Rate: 1900}, 3, 3, 1},
+		{Treatment{Rate: 1900}, -10000, -10000, -1900},
+		{Treatment{Rate: 1900, Inclusive: true}, 11900, 10000, 1900},
+		{Treatment{Rate: 1000, Inclusive: true}, 1000, 909, 91},
+		{Treatment{ReverseCharge: true}, 10000, 10000, 0},
+		{Treatment{}, 10000, 10000, 0},
+	}
+	for _, tt := range tests {
+		net, tax, err := tt.treatment.Split(money.New(tt.amount, "EUR"))
+		if err != nil || net != money.New(tt.net, "EUR") || tax != money.New(tt.tax, "EUR") {
+			t.Errorf("%+v.Split(%d) = %v, %v, %v, want %d, %d", tt.treatment, tt.amount, net, tax, err, tt.net, tt.tax)
+		}
+	}
+}
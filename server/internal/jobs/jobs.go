@@ -0,0 +1,103 @@
+// Package jobs runs periodic background work such as subscription renewals
+// and nightly maintenance.
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Func is the work done on each run of a job
+type Func func(ctx context.Context) error
+
+// Every runs fn every interval until ctx is done, starting straight away. Each
+// run holds a Postgres advisory lock named after the job, so that when several
+// servers share a database only one of them runs the job at a time. Failures
+// are logged and retried on the next run.
+func Every(ctx context.Context, db *gorm.DB, name string, interval time.Duration, fn Func) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := runLocked(ctx, db, name, fn); err != nil {
+			log.Printf("Job %s failed: %v", name, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Daily runs fn once a day at the given time after midnight UTC until ctx is
+// done, holding the same lock as Every
+func Daily(ctx context.Context, db *gorm.DB, name string, at time.Duration, fn Func) {
+	for {
+		now := time.Now().UTC()
+		next := now.Truncate(24 * time.Hour).Add(at)
+		if !next.After(now) {
+			next = next.Add(24 * time.Hour)
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		if err := runLocked(ctx, db, name, fn); err != nil {
+			log.Printf("Job %s failed: %v", name, err)
+		}
+	}
+}
+
+// runLocked runs fn unless another server holds the job's lock. The lock is
+// a session lock taken on a connection of its own, so that no transaction is
+// held open while the job runs.
+func runLocked(ctx context.Context, db *gorm.DB, name string, fn Func) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	var locked bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock(hashtext($1))", name).Scan(&locked); err != nil {
+		return err
+	}
+	if !locked {
+		return nil
+	}
+	defer unlock(conn, name)
+
+	return fn(ctx)
+}
+
+// unlock releases a job's lock. It does not use the job's context, which may
+// be done by now. A connection whose lock cannot be released is discarded
+// rather than returned to the pool, which ends its session and the lock.
+func unlock(conn *sql.Conn, name string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var unlocked bool
+	err := conn.QueryRowContext(ctx, "SELECT pg_advisory_unlock(hashtext($1))", name).Scan(&unlocked)
+	if err == nil && unlocked {
+		return
+	}
+	log.Printf("Job %s could not release its lock: %v", name, err)
+	conn.Raw(func(interface{}) error {
+		return driver.ErrBadConn
+	})
+}
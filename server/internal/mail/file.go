@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer writes each message to a .eml file in a directory instead of
+// sending it, for development
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer creates a mailer writing to dir, creating it if needed
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg *Message) error {
+	now := time.Now()
+	data, err := Compose(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	name := fmt.Sprintf("%s-%s.eml", now.UTC().Format("20060102T150405.000000000"), hex.EncodeToString(suffix))
+	return os.WriteFile(filepath.Join(m.dir, name), data, 0o644)
+}
+
+// LogMailer logs the plain text of each message instead of sending it, for
+// development
+type LogMailer struct{}
+
+func (LogMailer) Send(ctx context.Context, msg *Message) error {
+	log.Printf("Mail to %s: %s\n%s", msg.To, msg.Subject, msg.Text)
+	return nil
+}
@@ -0,0 +1,61 @@
+package mail
+
+import (
+	"fmt"
+	"time"
+)
+
+// locale formats values in email templates the way a language writes them
+type locale struct {
+	months     [12]string
+	formatDate func(months [12]string, t time.Time) string
+	statuses   map[string]string // project statuses
+}
+
+// locales has a locale for every language with templates
+var locales = map[string]locale{
+	"en": {
+		months: [12]string{"January", "February", "March", "April", "May", "June",
+			"July", "August", "September", "October", "November", "December"},
+		formatDate: func(months [12]string, t time.Time) string {
+			return fmt.Sprintf("%s %d, %d", months[t.Month()-1], t.Day(), t.Year())
+		},
+		statuses: map[string]string{"planning": "Planning", "active": "Active", "on_hold": "On hold",
+			"completed": "Completed", "cancelled": "Cancelled"},
+	},
+	"es": {
+		months: [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio",
+			"julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		formatDate: func(months [12]string, t time.Time) string {
+			return fmt.Sprintf("%d de %s de %d", t.Day(), months[t.Month()-1], t.Year())
+		},
+		statuses: map[string]string{"planning": "En planificación", "active": "Activo", "on_hold": "En pausa",
+			"completed": "Completado", "cancelled": "Cancelado"},
+	},
+	"de": {
+		months: [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni",
+			"Juli", "August", "September", "Oktober", "November", "Dezember"},
+		formatDate: func(months [12]string, t time.Time) string {
+			return fmt.Sprintf("%d. %s %d", t.Day(), months[t.Month()-1], t.Year())
+		},
+		statuses: map[string]string{"planning": "In Planung", "active": "Aktiv", "on_hold": "Pausiert",
+			"completed": "Abgeschlossen", "cancelled": "Abgebrochen"},
+	},
+}
+
+// funcs returns the functions templates in the locale's language can call
+func (l locale) funcs() map[string]interface{} {
+	return map[string]interface{}{
+		// date writes a day in UTC, such as "March 5, 2025"
+		"date": func(t time.Time) string { return l.formatDate(l.months, t.UTC()) },
+		// localdate writes a day in the time's own time zone
+		"localdate": func(t time.Time) string { return l.formatDate(l.months, t) },
+		// status names a project status
+		"status": func(status string) string {
+			if name, ok := l.statuses[status]; ok {
+				return name
+			}
+			return status
+		},
+	}
+}
@@ -0,0 +1,122 @@
+// Package mail sends email through a pluggable backend: an SMTP server, or
+// files or the log in development. Messages are rendered from HTML and plain
+// text templates in the recipient's language.
+package mail
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+)
+
+var ErrInvalidAddress = errors.New("invalid email address")
+
+// Message is an email to one recipient, with a plain text body and an HTML
+// alternative
+type Message struct {
+	To             string
+	Subject        string
+	Text           string
+	HTML           string
+	UnsubscribeURL string // offered to mail clients for one-click unsubscribing, if set
+}
+
+// Mailer is a backend that sends email
+type Mailer interface {
+	// Send sends a message. An error wrapping a PermanentError will fail
+	// again if retried.
+	Send(ctx context.Context, msg *Message) error
+}
+
+// PermanentError is a failure that retrying will not fix, such as a
+// rejected recipient
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// IsPermanent checks if retrying a send that failed with err is pointless
+func IsPermanent(err error) bool {
+	var permanent *PermanentError
+	return errors.As(err, &permanent)
+}
+
+// Compose builds the message as a MIME document sent from the given address
+func Compose(from string, msg *Message, date time.Time) ([]byte, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, fmt.Errorf("%w: sender %q", ErrInvalidAddress, from)
+	}
+	recipient, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return nil, &PermanentError{fmt.Errorf("%w: %q", ErrInvalidAddress, msg.To)}
+	}
+
+	var buf bytes.Buffer
+	parts := multipart.NewWriter(&buf)
+	header := func(name, value string) {
+		fmt.Fprintf(&buf, "%s: %s\r\n", name, value)
+	}
+	header("From", sender.String())
+	header("To", recipient.String())
+	header("Subject", mime.QEncoding.Encode("utf-8", strings.Join(strings.Fields(msg.Subject), " ")))
+	header("Date", date.Format(time.RFC1123Z))
+	header("Message-ID", messageID(sender.Address))
+	if msg.UnsubscribeURL != "" {
+		if strings.ContainsAny(msg.UnsubscribeURL, "\r\n<>") {
+			return nil, fmt.Errorf("invalid unsubscribe URL %q", msg.UnsubscribeURL)
+		}
+		header("List-Unsubscribe", "<"+msg.UnsubscribeURL+">")
+		header("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+	header("MIME-Version", "1.0")
+	header("Content-Type", `multipart/alternative; boundary="`+parts.Boundary()+`"`)
+	buf.WriteString("\r\n")
+
+	for _, body := range []struct{ contentType, content string }{
+		{"text/plain; charset=utf-8", msg.Text},
+		{"text/html; charset=utf-8", msg.HTML},
+	} {
+		part, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {body.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(part)
+		if _, err := qp.Write([]byte(body.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// messageID makes a unique Message-ID in the sender's domain
+func messageID(sender string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(sender, "@"); i >= 0 {
+		domain = sender[i+1:]
+	}
+	b := make([]byte, 16)
+	rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
@@ -0,0 +1,124 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"time"
+)
+
+// SMTPConfig holds the settings of an SMTP server
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // empty to send without authenticating
+	Password string
+	From     string
+}
+
+// SMTPMailer sends email through an SMTP server. It upgrades the connection
+// with STARTTLS when the server offers it, and uses TLS from the start on
+// port 465.
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer creates a mailer for the SMTP server
+func NewSMTPMailer(config SMTPConfig) (*SMTPMailer, error) {
+	if config.Host == "" {
+		return nil, errors.New("SMTP host is required")
+	}
+	if config.Port == "" {
+		config.Port = "587"
+	}
+	if _, err := mail.ParseAddress(config.From); err != nil {
+		return nil, fmt.Errorf("%w: sender %q", ErrInvalidAddress, config.From)
+	}
+	return &SMTPMailer{config: config}, nil
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg *Message) error {
+	data, err := Compose(m.config.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	from, _ := mail.ParseAddress(m.config.From)
+	to, _ := mail.ParseAddress(msg.To)
+
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(time.Minute))
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: m.config.Host}); err != nil {
+			return err
+		}
+	}
+	if m.config.Username != "" {
+		if ok, _ := client.Extension("AUTH"); ok {
+			auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+			if err := client.Auth(auth); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := client.Mail(from.Address); err != nil {
+		return classify(err)
+	}
+	if err := client.Rcpt(to.Address); err != nil {
+		return classify(err)
+	}
+	w, err := client.Data()
+	if err != nil {
+		return classify(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return classify(err)
+	}
+	// The server has taken the message once the data is accepted, so a
+	// failure to say goodbye is no reason to send it again
+	client.Quit()
+	return nil
+}
+
+// dial connects to the server, with TLS on the implicit TLS port
+func (m *SMTPMailer) dial(ctx context.Context) (net.Conn, error) {
+	addr := net.JoinHostPort(m.config.Host, m.config.Port)
+	if m.config.Port == "465" {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: m.config.Host}}
+		return dialer.DialContext(ctx, "tcp", addr)
+	}
+	var dialer net.Dialer
+	return dialer.DialContext(ctx, "tcp", addr)
+}
+
+// classify marks the server's 5xx replies as permanent failures
+func classify(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return &PermanentError{err}
+	}
+	return err
+}
@@ -0,0 +1,236 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+// DefaultLanguage is used for recipients whose language has no templates
+const DefaultLanguage = "en"
+
+var ErrUnknownTemplate = errors.New("unknown email template")
+
+// Template names. Each is rendered with the data type named beside it.
+const (
+	TemplateVerification    = "verification"     // VerificationData
+	TemplatePasswordReset   = "password_reset"   // PasswordResetData
+	TemplatePaymentReminder = "payment_reminder" // PaymentReminderData
+	TemplateNotification    = "notification"     // NotificationData
+	TemplateDigest          = "digest"           // DigestData
+)
+
+// VerificationData fills in the email verification template
+type VerificationData struct {
+	Name string
+	Code string
+}
+
+// PasswordResetData fills in the password reset template
+type PasswordResetData struct {
+	Name string
+	Link string
+}
+
+// PaymentReminderData fills in the overdue invoice reminder. Before the
+// grace period ends it says when the organization will become read-only;
+// after, that it is read-only until the invoice is paid.
+type PaymentReminderData struct {
+	InvoiceNumber string
+	Amount        string
+	DueDate       time.Time
+	GraceEnds     time.Time
+	ReadOnly      bool
+	Link          string
+}
+
+// NotificationData fills in the template for notifications sent by email
+type NotificationData struct {
+	Name    string
+	Message string
+	Link    string
+}
+
+// DigestData fills in the daily or weekly digest. Times are in the
+// recipient's time zone.
+type DigestData struct {
+	Name            string
+	Weekly          bool
+	Overdue         []DigestTask
+	DueSoon         []DigestTask
+	Comments        []DigestComment
+	MoreComments    int // comments left out of a long digest
+	StatusChanges   []DigestStatusChange
+	Link            string // to the web app
+	UnsubscribeLink string
+}
+
+// DigestTask is a task due soon or overdue in a digest
+type DigestTask struct {
+	Title   string
+	DueDate time.Time
+	Link    string
+}
+
+// DigestComment is a new comment on a watched task in a digest
+type DigestComment struct {
+	TaskTitle string
+	Author    string
+	Excerpt   string
+	Link      string
+}
+
+// DigestStatusChange is a project's change of status in a digest
+type DigestStatusChange struct {
+	ProjectName string
+	From        string
+	To          string
+	Link        string
+}
+
+//go:embed templates
+var templateFiles embed.FS
+
+// Templates renders emails from the embedded templates. Each language has a
+// directory with a file per template defining its "subject", "text" and
+// "html"; the HTML is wrapped in the shared layout.
+type Templates struct {
+	sets map[string]map[string]*templateSet // by language, then name
+}
+
+type templateSet struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+}
+
+// layoutData is what the HTML layout is rendered with
+type layoutData struct {
+	Language string
+	Data     interface{}
+}
+
+// LoadTemplates parses the embedded templates. Every template must exist in
+// the default language.
+func LoadTemplates() (*Templates, error) {
+	layout, err := fs.ReadFile(templateFiles, "templates/layout.tmpl")
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Templates{sets: make(map[string]map[string]*templateSet)}
+	files, err := fs.Glob(templateFiles, "templates/*/*.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		language := path.Base(path.Dir(file))
+		name := strings.TrimSuffix(path.Base(file), ".tmpl")
+		loc, ok := locales[language]
+		if !ok {
+			return nil, fmt.Errorf("email templates for %s: no locale", language)
+		}
+		content, err := fs.ReadFile(templateFiles, file)
+		if err != nil {
+			return nil, err
+		}
+
+		set, err := parseTemplateSet(file, string(layout), string(content), loc.funcs())
+		if err != nil {
+			return nil, err
+		}
+		if t.sets[language] == nil {
+			t.sets[language] = make(map[string]*templateSet)
+		}
+		t.sets[language][name] = set
+	}
+
+	for _, name := range []string{TemplateVerification, TemplatePasswordReset, TemplatePaymentReminder, TemplateNotification, TemplateDigest} {
+		if t.sets[DefaultLanguage][name] == nil {
+			return nil, fmt.Errorf("%w: %s has no %s template", ErrUnknownTemplate, name, DefaultLanguage)
+		}
+	}
+	return t, nil
+}
+
+// parseTemplateSet parses a template file as text, and as HTML within the layout
+func parseTemplateSet(file, layout, content string, funcs map[string]interface{}) (*templateSet, error) {
+	text, err := texttemplate.New(file).Funcs(funcs).Parse(content)
+	if err != nil {
+		return nil, err
+	}
+	html, err := htmltemplate.New(file).Funcs(funcs).Parse(layout)
+	if err == nil {
+		html, err = html.Parse(content)
+	}
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range []string{"subject", "text"} {
+		if text.Lookup(name) == nil {
+			return nil, fmt.Errorf("%s: no %q template", file, name)
+		}
+	}
+	if html.Lookup("html") == nil {
+		return nil, fmt.Errorf("%s: no \"html\" template", file)
+	}
+	return &templateSet{text: text, html: html}, nil
+}
+
+// Languages lists the languages there are templates in
+func (t *Templates) Languages() []string {
+	languages := make([]string, 0, len(t.sets))
+	for language := range t.sets {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// Render renders a template in a language such as "de" or "pt-BR", falling
+// back to the default language. The message is returned without a recipient.
+func (t *Templates) Render(name, language string, data interface{}) (*Message, error) {
+	language = t.match(language)
+	set := t.sets[language][name]
+	if set == nil {
+		language = DefaultLanguage
+		if set = t.sets[language][name]; set == nil {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownTemplate, name)
+		}
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := set.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+	if err := set.text.ExecuteTemplate(&text, "text", data); err != nil {
+		return nil, err
+	}
+	if err := set.html.ExecuteTemplate(&html, "layout", layoutData{Language: language, Data: data}); err != nil {
+		return nil, err
+	}
+	return &Message{
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+		HTML:    html.String(),
+	}, nil
+}
+
+// match returns the language with templates closest to the one asked for
+func (t *Templates) match(language string) string {
+	code := strings.ToLower(language)
+	if i := strings.IndexAny(code, "-_"); i >= 0 {
+		code = code[:i]
+	}
+	if _, ok := t.sets[code]; ok {
+		return code
+	}
+	return DefaultLanguage
+}
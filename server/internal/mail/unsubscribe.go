@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidUnsubscribeToken = errors.New("invalid unsubscribe link")
+
+// UnsubscribeToken signs a token that unsubscribes a user from digests
+// without them logging in. It does not expire, as emails are read late.
+func UnsubscribeToken(key []byte, userID uint) string {
+	id := strconv.FormatUint(uint64(userID), 10)
+	return id + "." + base64.RawURLEncoding.EncodeToString(unsubscribeMAC(key, id))
+}
+
+// ParseUnsubscribeToken checks an unsubscribe token's signature and returns
+// the user it unsubscribes
+func ParseUnsubscribeToken(key []byte, token string) (uint, error) {
+	id, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return 0, ErrInvalidUnsubscribeToken
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(mac, unsubscribeMAC(key, id)) {
+		return 0, ErrInvalidUnsubscribeToken
+	}
+	userID, err := strconv.ParseUint(id, 10, 64)
+	if err != nil || userID == 0 {
+		return 0, ErrInvalidUnsubscribeToken
+	}
+	return uint(userID), nil
+}
+
+func unsubscribeMAC(key []byte, id string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("unsubscribe:" + id))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,38 @@
+package mail
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestUnsubscribeToken(t *testing.T) {
+	key := []byte("digest-secret")
+	token := UnsubscribeToken(key, 42)
+	id, signature, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name   string
+		key    []byte
+		token  string
+		userID uint
+		err    error
+	}{
+		{"valid", key, token, 42, nil},
+		{"another user's signature", key, "43." + signature, 0, ErrInvalidUnsubscribeToken},
+		{"tampered signature", key, id + "." + strings.Repeat("A", len(signature)), 0, ErrInvalidUnsubscribeToken},
+		{"other key", []byte("rotated-secret"), token, 0, ErrInvalidUnsubscribeToken},
+		{"no signature", key, id, 0, ErrInvalidUnsubscribeToken},
+		{"signature not base64", key, id + ".!!", 0, ErrInvalidUnsubscribeToken},
+		{"empty", key, "", 0, ErrInvalidUnsubscribeToken},
+		{"user zero", key, UnsubscribeToken(key, 0), 0, ErrInvalidUnsubscribeToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID, err := ParseUnsubscribeToken(tt.key, tt.token)
+			if !errors.Is(err, tt.err) || userID != tt.userID {
+				t.Errorf("ParseUnsubscribeToken() = %d, %v, want %d, %v", userID, err, tt.userID, tt.err)
+			}
+		})
+	}
+}
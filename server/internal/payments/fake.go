@@ -0,0 +1,246 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+// Test tokens understood by FakeProvider. Any other token is a card that
+// always succeeds.
+const (
+	FakeTokenDeclined          = "tok_chargeDeclined"
+	FakeTokenInsufficientFunds = "tok_insufficientFunds"
+	FakeTokenPending           = "tok_bankTransfer" // charges stay pending
+	FakeTokenPayPal            = "tok_paypal"
+)
+
+// FakeProvider is an in-memory PaymentProvider for tests and local
+// development. The outcome of a charge depends on the token the payment
+// method was attached with, and the fee is 2.9% plus 30 minor units. Its
+// webhooks use Stripe's format and signing scheme.
+type FakeProvider struct {
+	webhooks  WebhookSigner
+	mu        sync.Mutex
+	nextID    int
+	customers map[string]Customer
+	methods   map[string]fakeMethod
+	charges   map[string]*Charge
+	refunded  map[string]money.Money // by charge ID
+	results   map[string]interface{} // by idempotency key
+}
+
+type fakeMethod struct {
+	PaymentMethod
+	customerID string
+	token      string
+}
+
+// NewFakeProvider creates an empty fake provider that signs webhooks with the secret
+func NewFakeProvider(webhookSecret string) *FakeProvider {
+	return &FakeProvider{
+		webhooks:  WebhookSigner{Secret: webhookSecret},
+		customers: make(map[string]Customer),
+		methods:   make(map[string]fakeMethod),
+		charges:   make(map[string]*Charge),
+		refunded:  make(map[string]money.Money),
+		results:   make(map[string]interface{}),
+	}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) CreateCustomer(ctx context.Context, customer Customer) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	id := p.newID("cus")
+	p.customers[id] = customer
+	return id, nil
+}
+
+func (p *FakeProvider) AttachPaymentMethod(ctx context.Context, customerID, token string) (*PaymentMethod, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.customers[customerID]; !ok {
+		return nil, ErrCustomerNotFound
+	}
+	method := PaymentMethod{ID: p.newID("pm"), Type: "card", Brand: "visa", Last4: "4242", ExpMonth: 12, ExpYear: 2099}
+	switch token {
+	case FakeTokenDeclined:
+		method.Last4 = "0002"
+	case FakeTokenInsufficientFunds:
+		method.Last4 = "9995"
+	case FakeTokenPending:
+		method = PaymentMethod{ID: method.ID, Type: "bank_transfer", Last4: "6789"}
+	case FakeTokenPayPal:
+		method = PaymentMethod{ID: method.ID, Type: "paypal"}
+	}
+	p.methods[method.ID] = fakeMethod{PaymentMethod: method, customerID: customerID, token: token}
+	return &method, nil
+}
+
+func (p *FakeProvider) DetachPaymentMethod(ctx context.Context, paymentMethodID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.methods[paymentMethodID]; !ok {
+		return ErrPaymentMethodNotFound
+	}
+	delete(p.methods, paymentMethodID)
+	return nil
+}
+
+func (p *FakeProvider) Charge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if result, ok := p.results[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		if charge, ok := result.(*Charge); ok {
+			copied := *charge
+			return &copied, nil
+		}
+		return nil, &Error{StatusCode: http.StatusBadRequest, Code: "idempotency_key_in_use", Message: "idempotency key was used for another request"}
+	}
+	method, ok := p.methods[req.PaymentMethodID]
+	if !ok || method.customerID != req.CustomerID {
+		return nil, ErrPaymentMethodNotFound
+	}
+	if !req.Amount.IsPositive() {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Code: "amount_too_small", Message: "amount must be positive"}
+	}
+
+	charge := &Charge{ID: p.newID("pi"), Status: ChargeStatusSucceeded, Amount: req.Amount, Fee: money.Zero(req.Amount.Currency)}
+	switch method.token {
+	case FakeTokenDeclined:
+		charge.Status = ChargeStatusFailed
+		charge.FailureCode = "card_declined"
+		charge.FailureMessage = "Your card was declined."
+	case FakeTokenInsufficientFunds:
+		charge.Status = ChargeStatusFailed
+		charge.FailureCode = "insufficient_funds"
+		charge.FailureMessage = "Your card has insufficient funds."
+	case FakeTokenPending:
+		charge.Status = ChargeStatusPending
+	}
+	if charge.Status != ChargeStatusFailed {
+		fee, err := req.Amount.MulRat(29, 1000)
+		if err != nil {
+			return nil, err
+		}
+		if charge.Fee, err = fee.Add(money.New(30, req.Amount.Currency)); err != nil {
+			return nil, err
+		}
+	}
+
+	p.charges[charge.ID] = charge
+	if req.IdempotencyKey != "" {
+		p.results[req.IdempotencyKey] = charge
+	}
+	copied := *charge
+	return &copied, nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if result, ok := p.results[req.IdempotencyKey]; ok && req.IdempotencyKey != "" {
+		if refund, ok := result.(*Refund); ok {
+			copied := *refund
+			return &copied, nil
+		}
+		return nil, &Error{StatusCode: http.StatusBadRequest, Code: "idempotency_key_in_use", Message: "idempotency key was used for another request"}
+	}
+	charge, ok := p.charges[req.ChargeID]
+	if !ok {
+		return nil, ErrChargeNotFound
+	}
+	if charge.Status != ChargeStatusSucceeded {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Code: "charge_not_refundable", Message: "only successful charges can be refunded"}
+	}
+	refunded, ok := p.refunded[charge.ID]
+	if !ok {
+		refunded = money.Zero(charge.Amount.Currency)
+	}
+	total, err := refunded.Add(req.Amount)
+	if err != nil {
+		return nil, err
+	}
+	if !req.Amount.IsPositive() || total.Minor > charge.Amount.Minor {
+		return nil, &Error{StatusCode: http.StatusBadRequest, Code: "amount_too_large", Message: "refund exceeds the amount left to refund"}
+	}
+
+	refund := &Refund{ID: p.newID("re"), ChargeID: charge.ID, Status: ChargeStatusSucceeded, Amount: req.Amount}
+	p.refunded[charge.ID] = total
+	if req.IdempotencyKey != "" {
+		p.results[req.IdempotencyKey] = refund
+	}
+	copied := *refund
+	return &copied, nil
+}
+
+// SettleCharge completes a pending charge, as a bank transfer clearing or
+// bouncing would. It returns false if there is no such pending charge.
+func (p *FakeProvider) SettleCharge(chargeID string, succeeded bool) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	charge, ok := p.charges[chargeID]
+	if !ok || charge.Status != ChargeStatusPending {
+		return false
+	}
+	charge.Status = ChargeStatusSucceeded
+	if !succeeded {
+		charge.Status = ChargeStatusFailed
+		charge.FailureCode = "payment_failed"
+		charge.FailureMessage = "The bank transfer was returned."
+		charge.Fee = money.Zero(charge.Amount.Currency)
+	}
+	return true
+}
+
+func (p *FakeProvider) ParseWebhook(payload []byte, header http.Header, now time.Time) (*Event, error) {
+	if err := p.webhooks.Verify(payload, header, now); err != nil {
+		return nil, err
+	}
+	return ParseStripeEvent(payload)
+}
+
+func (p *FakeProvider) ParseEvent(payload []byte) (*Event, error) {
+	return ParseStripeEvent(payload)
+}
+
+// SignedEvent builds a webhook as the provider would send it: the payload of
+// an event of the given type about object, and the value of its signature
+// header
+func (p *FakeProvider) SignedEvent(eventType string, object map[string]interface{}, at time.Time) ([]byte, string, error) {
+	p.mu.Lock()
+	id := p.newID("evt")
+	p.mu.Unlock()
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"id":      id,
+		"type":    eventType,
+		"created": at.Unix(),
+		"data":    map[string]interface{}{"object": object},
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, p.webhooks.Sign(payload, at), nil
+}
+
+// newID returns a new ID with the given prefix. Callers hold the lock.
+func (p *FakeProvider) newID(prefix string) string {
+	p.nextID++
+	return fmt.Sprintf("%s_fake_%d", prefix, p.nextID)
+}
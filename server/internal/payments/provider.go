@@ -0,0 +1,126 @@
+// Package payments charges customers through a pluggable payment provider: a
+// Stripe-style HTTP API or an in-process fake for tests and local development.
+package payments
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+var (
+	ErrCustomerNotFound      = errors.New("customer not found at the payment provider")
+	ErrPaymentMethodNotFound = errors.New("payment method not found at the payment provider")
+	ErrChargeNotFound        = errors.New("charge not found at the payment provider")
+)
+
+// PaymentProvider charges and refunds customers. Card and bank details never
+// reach the server: clients collect them with the provider's own library,
+// which hands back a token to attach to the customer.
+type PaymentProvider interface {
+	// Name identifies the provider on recorded transactions
+	Name() string
+	// CreateCustomer registers a customer and returns the provider's ID for it
+	CreateCustomer(ctx context.Context, customer Customer) (string, error)
+	// AttachPaymentMethod saves a payment method token to a customer as its
+	// default payment method
+	AttachPaymentMethod(ctx context.Context, customerID, token string) (*PaymentMethod, error)
+	// DetachPaymentMethod removes a saved payment method from its customer
+	DetachPaymentMethod(ctx context.Context, paymentMethodID string) error
+	// Charge takes a payment from a customer's saved payment method. A
+	// declined payment is not an error: it returns a failed charge.
+	Charge(ctx context.Context, req ChargeRequest) (*Charge, error)
+	// Refund returns all or part of a successful charge
+	Refund(ctx context.Context, req RefundRequest) (*Refund, error)
+	// ParseWebhook verifies a webhook's signature and decodes its event
+	ParseWebhook(payload []byte, header http.Header, now time.Time) (*Event, error)
+	// ParseEvent decodes the payload of a webhook verified when it arrived
+	ParseEvent(payload []byte) (*Event, error)
+}
+
+// Customer is who is billed at the provider
+type Customer struct {
+	Name           string
+	Email          string
+	OrganizationID uint
+}
+
+// PaymentMethod is a saved card, PayPal account or bank account. Type is
+// card, paypal or bank_transfer.
+type PaymentMethod struct {
+	ID       string
+	Type     string
+	Brand    string
+	Last4    string
+	ExpMonth int
+	ExpYear  int
+}
+
+// ChargeRequest asks for a payment. Retrying a request with the same
+// IdempotencyKey returns the first result instead of charging again.
+type ChargeRequest struct {
+	CustomerID      string
+	PaymentMethodID string
+	Amount          money.Money
+	Description     string
+	IdempotencyKey  string
+	Metadata        map[string]string
+}
+
+// ChargeStatus is the outcome of a charge
+type ChargeStatus string
+
+const (
+	ChargeStatusSucceeded ChargeStatus = "succeeded"
+	ChargeStatusPending   ChargeStatus = "pending" // settles later, as bank transfers do
+	ChargeStatusFailed    ChargeStatus = "failed"
+)
+
+// Charge is the result of a charge. Fee is what the provider keeps; failed
+// charges carry the provider's reason.
+type Charge struct {
+	ID             string
+	Status         ChargeStatus
+	Amount         money.Money
+	Fee            money.Money
+	FailureCode    string
+	FailureMessage string
+}
+
+// RefundRequest returns Amount of a charge. IdempotencyKey works as it does
+// for charges.
+type RefundRequest struct {
+	ChargeID       string
+	Amount         money.Money
+	Reason         string
+	IdempotencyKey string
+}
+
+// Refund is the result of a refund
+type Refund struct {
+	ID       string
+	ChargeID string
+	Status   ChargeStatus
+	Amount   money.Money
+}
+
+// Error is an error reported by the provider or on the way to it. Declined
+// payments are reported as failed charges instead.
+type Error struct {
+	StatusCode int    // HTTP status, if any
+	Type       string // the provider's error category
+	Code       string
+	Message    string
+	PaymentID  string // the payment the error is about, if any
+}
+
+func (e *Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("payment provider error: %s", e.Message)
+	}
+	return fmt.Sprintf("payment provider error: %s (%s)", e.Message, e.Code)
+}
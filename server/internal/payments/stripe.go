@@ -0,0 +1,321 @@
+package payments
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+const defaultStripeURL = "https://api.stripe.com"
+
+// StripeConfig holds the settings of a Stripe-compatible API
+type StripeConfig struct {
+	APIURL        string // defaults to Stripe's own API
+	SecretKey     string
+	WebhookSecret string // signs the webhooks Stripe sends
+}
+
+// StripeProvider is a PaymentProvider speaking Stripe's HTTP API: customers,
+// payment methods, payment intents confirmed off-session, and refunds
+type StripeProvider struct {
+	baseURL   string
+	secretKey string
+	webhooks  WebhookSigner
+	client    *http.Client
+}
+
+// NewStripeProvider creates a provider for the configured API
+func NewStripeProvider(config StripeConfig) (*StripeProvider, error) {
+	if config.SecretKey == "" {
+		return nil, errors.New("stripe secret key is required")
+	}
+	baseURL := config.APIURL
+	if baseURL == "" {
+		baseURL = defaultStripeURL
+	}
+	return &StripeProvider{
+		baseURL:   strings.TrimSuffix(baseURL, "/"),
+		secretKey: config.SecretKey,
+		webhooks:  WebhookSigner{Secret: config.WebhookSecret},
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (p *StripeProvider) Name() string {
+	return "stripe"
+}
+
+func (p *StripeProvider) CreateCustomer(ctx context.Context, customer Customer) (string, error) {
+	form := url.Values{}
+	form.Set("name", customer.Name)
+	if customer.Email != "" {
+		form.Set("email", customer.Email)
+	}
+	form.Set("metadata[organization_id]", strconv.FormatUint(uint64(customer.OrganizationID), 10))
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := p.post(ctx, "/v1/customers", form, "", &created); err != nil {
+		return "", err
+	}
+	return created.ID, nil
+}
+
+func (p *StripeProvider) AttachPaymentMethod(ctx context.Context, customerID, token string) (*PaymentMethod, error) {
+	form := url.Values{}
+	form.Set("customer", customerID)
+	var method stripePaymentMethod
+	if err := p.post(ctx, "/v1/payment_methods/"+url.PathEscape(token)+"/attach", form, "", &method); err != nil {
+		return nil, notFound(err, ErrPaymentMethodNotFound)
+	}
+
+	form = url.Values{}
+	form.Set("invoice_settings[default_payment_method]", method.ID)
+	if err := p.post(ctx, "/v1/customers/"+url.PathEscape(customerID), form, "", nil); err != nil {
+		return nil, notFound(err, ErrCustomerNotFound)
+	}
+	return method.toPaymentMethod(), nil
+}
+
+func (p *StripeProvider) DetachPaymentMethod(ctx context.Context, paymentMethodID string) error {
+	err := p.post(ctx, "/v1/payment_methods/"+url.PathEscape(paymentMethodID)+"/detach", url.Values{}, "", nil)
+	return notFound(err, ErrPaymentMethodNotFound)
+}
+
+// Charge creates and confirms a payment intent without the customer present.
+// Intents still processing count as pending; those needing the customer to
+// act, such as for 3-D Secure, count as failed.
+func (p *StripeProvider) Charge(ctx context.Context, req ChargeRequest) (*Charge, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(req.Amount.Minor, 10))
+	form.Set("currency", strings.ToLower(req.Amount.Currency))
+	form.Set("customer", req.CustomerID)
+	form.Set("payment_method", req.PaymentMethodID)
+	form.Set("confirm", "true")
+	form.Set("off_session", "true")
+	if req.Description != "" {
+		form.Set("description", req.Description)
+	}
+	form.Add("expand[]", "latest_charge.balance_transaction")
+	for key, value := range req.Metadata {
+		form.Set("metadata["+key+"]", value)
+	}
+
+	var intent stripePaymentIntent
+	err := p.post(ctx, "/v1/payment_intents", form, req.IdempotencyKey, &intent)
+	var providerErr *Error
+	if errors.As(err, &providerErr) && providerErr.Type == "card_error" {
+		// Declines come back as errors carrying the failed intent
+		return &Charge{
+			ID:             providerErr.PaymentID,
+			Status:         ChargeStatusFailed,
+			Amount:         req.Amount,
+			Fee:            money.Zero(req.Amount.Currency),
+			FailureCode:    providerErr.Code,
+			FailureMessage: providerErr.Message,
+		}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return intent.toCharge(req.Amount), nil
+}
+
+func (p *StripeProvider) Refund(ctx context.Context, req RefundRequest) (*Refund, error) {
+	form := url.Values{}
+	form.Set("payment_intent", req.ChargeID)
+	form.Set("amount", strconv.FormatInt(req.Amount.Minor, 10))
+	if req.Reason != "" {
+		form.Set("reason", req.Reason)
+	}
+
+	var refund struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+		Amount int64  `json:"amount"`
+	}
+	if err := p.post(ctx, "/v1/refunds", form, req.IdempotencyKey, &refund); err != nil {
+		return nil, notFound(err, ErrChargeNotFound)
+	}
+	return &Refund{
+		ID:       refund.ID,
+		ChargeID: req.ChargeID,
+		Status:   chargeStatus(refund.Status),
+		Amount:   money.New(refund.Amount, req.Amount.Currency),
+	}, nil
+}
+
+func (p *StripeProvider) ParseWebhook(payload []byte, header http.Header, now time.Time) (*Event, error) {
+	if err := p.webhooks.Verify(payload, header, now); err != nil {
+		return nil, err
+	}
+	return ParseStripeEvent(payload)
+}
+
+func (p *StripeProvider) ParseEvent(payload []byte) (*Event, error) {
+	return ParseStripeEvent(payload)
+}
+
+// post sends a form-encoded request and decodes the JSON response into out
+func (p *StripeProvider) post(ctx context.Context, path string, form url.Values, idempotencyKey string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.secretKey)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if idempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", idempotencyKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return &Error{Message: err.Error()}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var body struct {
+			Error stripeError `json:"error"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil || body.Error.Message == "" {
+			return &Error{StatusCode: resp.StatusCode, Message: resp.Status}
+		}
+		return body.Error.toError(resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return &Error{StatusCode: resp.StatusCode, Message: fmt.Sprintf("invalid response: %v", err)}
+	}
+	return nil
+}
+
+// stripeError is the error object of Stripe's API
+type stripeError struct {
+	Type          string `json:"type"`
+	Code          string `json:"code"`
+	DeclineCode   string `json:"decline_code"`
+	Message       string `json:"message"`
+	PaymentIntent struct {
+		ID string `json:"id"`
+	} `json:"payment_intent"`
+}
+
+// toError converts the error, preferring the decline code of a declined card
+func (e stripeError) toError(statusCode int) *Error {
+	code := e.Code
+	if e.DeclineCode != "" {
+		code = e.DeclineCode
+	}
+	return &Error{
+		StatusCode: statusCode,
+		Type:       e.Type,
+		Code:       code,
+		Message:    e.Message,
+		PaymentID:  e.PaymentIntent.ID,
+	}
+}
+
+// notFound replaces a 404 from the provider with the given error
+func notFound(err, replacement error) error {
+	var providerErr *Error
+	if errors.As(err, &providerErr) && (providerErr.StatusCode == http.StatusNotFound || providerErr.Code == "resource_missing") {
+		return replacement
+	}
+	return err
+}
+
+type stripePaymentMethod struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Card struct {
+		Brand    string `json:"brand"`
+		Last4    string `json:"last4"`
+		ExpMonth int    `json:"exp_month"`
+		ExpYear  int    `json:"exp_year"`
+	} `json:"card"`
+	USBankAccount struct {
+		Last4 string `json:"last4"`
+	} `json:"us_bank_account"`
+	SEPADebit struct {
+		Last4 string `json:"last4"`
+	} `json:"sepa_debit"`
+}
+
+func (m stripePaymentMethod) toPaymentMethod() *PaymentMethod {
+	method := &PaymentMethod{ID: m.ID}
+	switch m.Type {
+	case "card":
+		method.Type = "card"
+		method.Brand = m.Card.Brand
+		method.Last4 = m.Card.Last4
+		method.ExpMonth = m.Card.ExpMonth
+		method.ExpYear = m.Card.ExpYear
+	case "paypal":
+		method.Type = "paypal"
+	default:
+		method.Type = "bank_transfer"
+		method.Last4 = m.USBankAccount.Last4
+		if method.Last4 == "" {
+			method.Last4 = m.SEPADebit.Last4
+		}
+	}
+	return method
+}
+
+type stripePaymentIntent struct {
+	ID               string `json:"id"`
+	Status           string `json:"status"`
+	LastPaymentError *struct {
+		Code        string `json:"code"`
+		DeclineCode string `json:"decline_code"`
+		Message     string `json:"message"`
+	} `json:"last_payment_error"`
+	LatestCharge struct {
+		BalanceTransaction struct {
+			Fee int64 `json:"fee"`
+		} `json:"balance_transaction"`
+	} `json:"latest_charge"`
+}
+
+func (i stripePaymentIntent) toCharge(amount money.Money) *Charge {
+	charge := &Charge{
+		ID:     i.ID,
+		Status: chargeStatus(i.Status),
+		Amount: amount,
+		Fee:    money.New(i.LatestCharge.BalanceTransaction.Fee, amount.Currency),
+	}
+	if charge.Status == ChargeStatusFailed {
+		charge.FailureCode = i.Status
+		if i.LastPaymentError != nil {
+			charge.FailureCode = i.LastPaymentError.Code
+			if i.LastPaymentError.DeclineCode != "" {
+				charge.FailureCode = i.LastPaymentError.DeclineCode
+			}
+			charge.FailureMessage = i.LastPaymentError.Message
+		}
+	}
+	return charge
+}
+
+// chargeStatus maps the status of a payment intent or refund
+func chargeStatus(status string) ChargeStatus {
+	switch status {
+	case "succeeded":
+		return ChargeStatusSucceeded
+	case "processing", "pending":
+		return ChargeStatusPending
+	}
+	return ChargeStatusFailed
+}
@@ -0,0 +1,206 @@
+package payments
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+	ErrStaleWebhook     = errors.New("webhook timestamp is outside the allowed window")
+	ErrInvalidEvent     = errors.New("invalid webhook event")
+)
+
+// SignatureHeader carries a webhook's signature, as "t=<unix time>,v1=<hex HMAC>"
+const SignatureHeader = "Stripe-Signature"
+
+// webhookTolerance is how old a signed webhook may be before it is refused as a replay
+const webhookTolerance = 5 * time.Minute
+
+// EventType is the kind of a payment event, independent of the provider
+type EventType string
+
+const (
+	EventPaymentSucceeded EventType = "payment.succeeded"
+	EventPaymentFailed    EventType = "payment.failed"
+	EventRefundSucceeded  EventType = "refund.succeeded"
+	EventRefundFailed     EventType = "refund.failed"
+	EventDisputeOpened    EventType = "dispute.opened"
+	EventDisputeWon       EventType = "dispute.won"
+	EventDisputeLost      EventType = "dispute.lost"
+	EventIgnored          EventType = "ignored" // an event type nothing listens to
+)
+
+// Event is a payment event reported by the provider. PaymentID is the
+// payment the event is about; RefundID and DisputeID are set for refund and
+// dispute events.
+type Event struct {
+	ID             string
+	Type           EventType
+	ProviderType   string // the provider's own name for the event
+	OccurredAt     time.Time
+	PaymentID      string
+	RefundID       string
+	DisputeID      string
+	Amount         money.Money
+	FailureCode    string
+	FailureMessage string
+	Metadata       map[string]string
+}
+
+// WebhookSigner signs and verifies webhooks with a shared secret, the way
+// Stripe does: an HMAC-SHA256 of the timestamp and the payload
+type WebhookSigner struct {
+	Secret string
+}
+
+// Sign returns the signature header value for a payload sent at t
+func (s WebhookSigner) Sign(payload []byte, t time.Time) string {
+	timestamp := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + timestamp + ",v1=" + s.signature(timestamp, payload)
+}
+
+// Verify checks a payload's signature and that it was signed recently
+func (s WebhookSigner) Verify(payload []byte, header http.Header, now time.Time) error {
+	if s.Secret == "" {
+		return fmt.Errorf("%w: no webhook secret configured", ErrInvalidSignature)
+	}
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header.Get(SignatureHeader), ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+
+	expected := s.signature(timestamp, payload)
+	valid := false
+	for _, signature := range signatures {
+		if hmac.Equal([]byte(signature), []byte(expected)) {
+			valid = true
+		}
+	}
+	if !valid {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > webhookTolerance || age < -webhookTolerance {
+		return ErrStaleWebhook
+	}
+	return nil
+}
+
+func (s WebhookSigner) signature(timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(s.Secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// ParseStripeEvent decodes an event in Stripe's format
+func ParseStripeEvent(payload []byte) (*Event, error) {
+	var raw struct {
+		ID      string `json:"id"`
+		Type    string `json:"type"`
+		Created int64  `json:"created"`
+		Data    struct {
+			Object struct {
+				ID               string            `json:"id"`
+				Object           string            `json:"object"`
+				PaymentIntent    string            `json:"payment_intent"`
+				Amount           int64             `json:"amount"`
+				Currency         string            `json:"currency"`
+				Status           string            `json:"status"`
+				Metadata         map[string]string `json:"metadata"`
+				LastPaymentError *struct {
+					Code        string `json:"code"`
+					DeclineCode string `json:"decline_code"`
+					Message     string `json:"message"`
+				} `json:"last_payment_error"`
+				FailureReason string `json:"failure_reason"`
+			} `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(payload, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidEvent, err)
+	}
+	if raw.ID == "" || raw.Type == "" {
+		return nil, fmt.Errorf("%w: missing id or type", ErrInvalidEvent)
+	}
+
+	object := raw.Data.Object
+	event := &Event{
+		ID:           raw.ID,
+		ProviderType: raw.Type,
+		OccurredAt:   time.Unix(raw.Created, 0).UTC(),
+		PaymentID:    object.PaymentIntent,
+		Metadata:     object.Metadata,
+	}
+	if object.Currency != "" {
+		event.Amount = money.New(object.Amount, strings.ToUpper(object.Currency))
+	}
+
+	switch raw.Type {
+	case "payment_intent.succeeded":
+		event.Type = EventPaymentSucceeded
+		event.PaymentID = object.ID
+	case "payment_intent.payment_failed", "payment_intent.canceled":
+		event.Type = EventPaymentFailed
+		event.PaymentID = object.ID
+		if e := object.LastPaymentError; e != nil {
+			event.FailureCode = e.Code
+			if e.DeclineCode != "" {
+				event.FailureCode = e.DeclineCode
+			}
+			event.FailureMessage = e.Message
+		}
+	case "refund.created", "refund.updated", "refund.failed", "charge.refund.updated":
+		event.RefundID = object.ID
+		switch chargeStatus(object.Status) {
+		case ChargeStatusSucceeded:
+			event.Type = EventRefundSucceeded
+		case ChargeStatusFailed:
+			event.Type = EventRefundFailed
+			event.FailureCode = object.FailureReason
+		default:
+			event.Type = EventIgnored
+		}
+	case "charge.dispute.created":
+		event.Type = EventDisputeOpened
+		event.DisputeID = object.ID
+	case "charge.dispute.closed":
+		event.DisputeID = object.ID
+		switch object.Status {
+		case "won", "warning_closed":
+			event.Type = EventDisputeWon
+		case "lost":
+			event.Type = EventDisputeLost
+		default:
+			event.Type = EventIgnored
+		}
+	default:
+		event.Type = EventIgnored
+	}
+	if event.Type != EventIgnored && event.PaymentID == "" {
+		return nil, fmt.Errorf("%w: %s event names no payment", ErrInvalidEvent, raw.Type)
+	}
+	return event, nil
+}
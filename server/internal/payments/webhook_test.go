@@ -0,0 +1,108 @@
+package payments
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+)
+
+func TestWebhookRoundTrip(t *testing.T) {
+	provider := NewFakeProvider("whsec_test")
+	sent := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	payload, signature, err := provider.SignedEvent("payment_intent.succeeded", map[string]interface{}{
+		"id":       "pi_fake_1",
+		"object":   "payment_intent",
+		"amount":   4900,
+		"currency": "usd",
+		"metadata": map[string]string{"invoice_id": "7"},
+	}, sent)
+	if err != nil {
+		t.Fatalf("SignedEvent: %v", err)
+	}
+	header := http.Header{}
+	header.Set(SignatureHeader, signature)
+
+	event, err := provider.ParseWebhook(payload, header, sent.Add(time.Second))
+	if err != nil {
+		t.Fatalf("ParseWebhook: %v", err)
+	}
+	if event.Type != EventPaymentSucceeded || event.PaymentID != "pi_fake_1" || event.Amount != money.New(4900, "USD") ||
+		!event.OccurredAt.Equal(sent) || event.Metadata["invoice_id"] != "7" {
+		t.Errorf("event = %+v", event)
+	}
+}
+
+func TestWebhookVerify(t *testing.T) {
+	signer := WebhookSigner{Secret: "whsec_test"}
+	payload := []byte(`{"id":"evt_1","type":"payment_intent.succeeded"}`)
+	sent := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	signed := func(signature string) http.Header {
+		header := http.Header{}
+		header.Set(SignatureHeader, signature)
+		return header
+	}
+	valid := signed(signer.Sign(payload, sent))
+
+	tests := []struct {
+		name    string
+		signer  WebhookSigner
+		payload []byte
+		header  http.Header
+		now     time.Time
+		err     error
+	}{
+		{"fresh", signer, payload, valid, sent, nil},
+		{"at the tolerance", signer, payload, valid, sent.Add(webhookTolerance), nil},
+		{"clock behind the sender", signer, payload, valid, sent.Add(-webhookTolerance), nil},
+		{"too old", signer, payload, valid, sent.Add(webhookTolerance + time.Second), ErrStaleWebhook},
+		{"too far ahead", signer, payload, valid, sent.Add(-webhookTolerance - time.Second), ErrStaleWebhook},
+		{"tampered payload", signer, bytes.Replace(payload, []byte("evt_1"), []byte("evt_2"), 1), valid, sent, ErrInvalidSignature},
+		{"another secret", WebhookSigner{Secret: "whsec_other"}, payload, valid, sent, ErrInvalidSignature},
+		{"no secret", WebhookSigner{}, payload, valid, sent, ErrInvalidSignature},
+		{"no header", signer, payload, http.Header{}, sent, ErrInvalidSignature},
+		{"no signature", signer, payload, signed("t=1772366400"), sent, ErrInvalidSignature},
+		{"no timestamp", signer, payload, signed("v1=" + signer.signature("1772366400", payload)), sent, ErrInvalidSignature},
+		{"timestamp changed", signer, payload, signed("t=1772366401,v1=" + signer.signature("1772366400", payload)), sent, ErrInvalidSignature},
+		{"one of several signatures", signer, payload, signed("t=1772366400,v1=00,v1=" + signer.signature("1772366400", payload)), sent, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.signer.Verify(tt.payload, tt.header, tt.now); !errors.Is(err, tt.err) {
+				t.Errorf("Verify error = %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestFakeChargeIdempotency(t *testing.T) {
+	provider := NewFakeProvider("whsec_test")
+	ctx := context.Background()
+	customerID, _ := provider.CreateCustomer(ctx, Customer{Name: "Acme"})
+	method, err := provider.AttachPaymentMethod(ctx, customerID, "tok_visa")
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := ChargeRequest{CustomerID: customerID, PaymentMethodID: method.ID, Amount: money.New(1000, "USD"), IdempotencyKey: "invoice-1-charge-1"}
+
+	first, err := provider.Charge(ctx, req)
+	if err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+	replayed, err := provider.Charge(ctx, req)
+	if err != nil {
+		t.Fatalf("replayed Charge: %v", err)
+	}
+	if replayed.ID != first.ID || len(provider.charges) != 1 {
+		t.Errorf("replay = %s with %d charges, want %s once", replayed.ID, len(provider.charges), first.ID)
+	}
+
+	req.IdempotencyKey = "invoice-1-charge-2"
+	if second, err := provider.Charge(ctx, req); err != nil || second.ID == first.ID {
+		t.Errorf("Charge with a new key = %+v, %v", second, err)
+	}
+}
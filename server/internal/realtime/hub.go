@@ -0,0 +1,157 @@
+// Package realtime passes changes on to the clients following them live.
+// Changes are announced through Postgres, so that every server sharing the
+// database hears of them whichever server made them.
+package realtime
+
+import (
+	"sync"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+)
+
+// EventResync tells clients that changes may have been missed, such as while
+// the server was reconnecting to the database, and to fetch what they show
+// again
+const EventResync = "resync"
+
+// subscriptionBuffer is how many events a subscriber may fall behind by
+// before it is dropped
+const subscriptionBuffer = 64
+
+// Hub hands the events this server hears of to its subscribers
+type Hub struct {
+	mu            sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+}
+
+// NewHub creates a hub without subscribers
+func NewHub() *Hub {
+	return &Hub{
+		subscriptions: make(map[*Subscription]struct{}),
+	}
+}
+
+// Subscription receives the events of some organizations and projects, and
+// those addressed to its user
+type Subscription struct {
+	UserID        uint
+	Organizations []uint
+	Projects      []uint
+
+	organizations map[uint]bool
+	projects      map[uint]bool
+	events        chan models.RealtimeEvent
+	done          chan struct{}
+	dropOnce      sync.Once
+	hub           *Hub
+}
+
+// Subscribe starts passing a user the events of the organizations and
+// projects. The caller checks the user may see them, and closes the
+// subscription when done.
+func (h *Hub) Subscribe(userID uint, orgIDs, projectIDs []uint) *Subscription {
+	s := &Subscription{
+		UserID:        userID,
+		Organizations: orgIDs,
+		Projects:      projectIDs,
+		organizations: make(map[uint]bool, len(orgIDs)),
+		projects:      make(map[uint]bool, len(projectIDs)),
+		events:        make(chan models.RealtimeEvent, subscriptionBuffer),
+		done:          make(chan struct{}),
+		hub:           h,
+	}
+	for _, id := range orgIDs {
+		s.organizations[id] = true
+	}
+	for _, id := range projectIDs {
+		s.projects[id] = true
+	}
+
+	h.mu.Lock()
+	h.subscriptions[s] = struct{}{}
+	h.mu.Unlock()
+	return s
+}
+
+// Publish hands an event to the subscribers it concerns. Subscribers too far
+// behind to take it are dropped rather than holding up the others.
+func (h *Hub) Publish(event models.RealtimeEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subscriptions {
+		if s.wants(&event) {
+			s.send(event)
+		}
+	}
+}
+
+// Resync tells every subscriber that events may have been missed
+func (h *Hub) Resync() {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	for s := range h.subscriptions {
+		s.send(models.RealtimeEvent{Type: EventResync})
+	}
+}
+
+// Follow changes the organizations and projects the subscription follows.
+// The caller checks the user may see them.
+func (s *Subscription) Follow(orgIDs, projectIDs []uint) {
+	organizations := make(map[uint]bool, len(orgIDs))
+	for _, id := range orgIDs {
+		organizations[id] = true
+	}
+	projects := make(map[uint]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		projects[id] = true
+	}
+
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.Organizations, s.Projects = orgIDs, projectIDs
+	s.organizations, s.projects = organizations, projects
+}
+
+// Events delivers the subscription's events
+func (s *Subscription) Events() <-chan models.RealtimeEvent {
+	return s.events
+}
+
+// Dropped is closed when the hub drops the subscription for falling behind.
+// The client is to reconnect and fetch what it shows again.
+func (s *Subscription) Dropped() <-chan struct{} {
+	return s.done
+}
+
+// Close stops the subscription
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	delete(s.hub.subscriptions, s)
+	s.hub.mu.Unlock()
+	s.drop()
+}
+
+// wants checks if the event concerns the subscription. Events addressed to
+// a user only concern that user.
+func (s *Subscription) wants(event *models.RealtimeEvent) bool {
+	if event.UserID != nil {
+		return *event.UserID == s.UserID
+	}
+	if event.ProjectID != nil && s.projects[*event.ProjectID] {
+		return true
+	}
+	return event.OrganizationID != 0 && s.organizations[event.OrganizationID]
+}
+
+func (s *Subscription) send(event models.RealtimeEvent) {
+	select {
+	case <-s.done:
+	case s.events <- event:
+	default:
+		s.drop()
+	}
+}
+
+func (s *Subscription) drop() {
+	s.dropOnce.Do(func() { close(s.done) })
+}
@@ -0,0 +1,136 @@
+package realtime
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+)
+
+// received drains the events waiting on a subscription
+func received(s *Subscription) []uint {
+	var ids []uint
+	for {
+		select {
+		case event := <-s.Events():
+			ids = append(ids, event.EntityID)
+		default:
+			return ids
+		}
+	}
+}
+
+func idPtr(id uint) *uint {
+	return &id
+}
+
+func TestHubPublish(t *testing.T) {
+	hub := NewHub()
+	alice := hub.Subscribe(1, []uint{10}, nil)
+	bob := hub.Subscribe(2, nil, []uint{100})
+	carol := hub.Subscribe(3, []uint{20}, []uint{100, 200})
+	defer alice.Close()
+	defer bob.Close()
+	defer carol.Close()
+
+	tests := []struct {
+		name    string
+		event   models.RealtimeEvent
+		wantFor []*Subscription
+	}{
+		{"organization", models.RealtimeEvent{OrganizationID: 10}, []*Subscription{alice}},
+		{"followed project", models.RealtimeEvent{OrganizationID: 30, ProjectID: idPtr(100)}, []*Subscription{bob, carol}},
+		{"project of a followed organization", models.RealtimeEvent{OrganizationID: 10, ProjectID: idPtr(300)}, []*Subscription{alice}},
+		{"project and its organization", models.RealtimeEvent{OrganizationID: 20, ProjectID: idPtr(200)}, []*Subscription{carol}},
+		{"addressed to a user", models.RealtimeEvent{OrganizationID: 10, ProjectID: idPtr(100), UserID: idPtr(2)}, []*Subscription{bob}},
+		{"addressed to a user without a subscription", models.RealtimeEvent{OrganizationID: 10, UserID: idPtr(4)}, nil},
+		{"unfollowed", models.RealtimeEvent{OrganizationID: 30, ProjectID: idPtr(300)}, nil},
+		{"no topic", models.RealtimeEvent{}, nil},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.event.EntityID = uint(i + 1)
+			hub.Publish(tt.event)
+			for _, s := range []*Subscription{alice, bob, carol} {
+				var want []uint
+				for _, w := range tt.wantFor {
+					if w == s {
+						want = []uint{tt.event.EntityID}
+					}
+				}
+				if got := received(s); !reflect.DeepEqual(got, want) {
+					t.Errorf("user %d received %v, want %v", s.UserID, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSubscriptionFollow(t *testing.T) {
+	hub := NewHub()
+	s := hub.Subscribe(1, []uint{10}, nil)
+	defer s.Close()
+
+	s.Follow([]uint{20}, []uint{100})
+	hub.Publish(models.RealtimeEvent{EntityID: 1, OrganizationID: 10})
+	hub.Publish(models.RealtimeEvent{EntityID: 2, OrganizationID: 20})
+	hub.Publish(models.RealtimeEvent{EntityID: 3, OrganizationID: 30, ProjectID: idPtr(100)})
+	if got, want := received(s), []uint{2, 3}; !reflect.DeepEqual(got, want) {
+		t.Errorf("received %v after following, want %v", got, want)
+	}
+	if !reflect.DeepEqual(s.Organizations, []uint{20}) || !reflect.DeepEqual(s.Projects, []uint{100}) {
+		t.Errorf("following %v and %v, want [20] and [100]", s.Organizations, s.Projects)
+	}
+}
+
+func TestHubDropsSlowSubscribers(t *testing.T) {
+	hub := NewHub()
+	slow := hub.Subscribe(1, []uint{10}, nil)
+	fast := hub.Subscribe(2, []uint{10}, nil)
+	defer slow.Close()
+	defer fast.Close()
+
+	for i := 0; i <= subscriptionBuffer; i++ {
+		hub.Publish(models.RealtimeEvent{EntityID: uint(i), OrganizationID: 10})
+		received(fast)
+	}
+	select {
+	case <-slow.Dropped():
+	default:
+		t.Error("subscriber that fell behind was not dropped")
+	}
+	select {
+	case <-fast.Dropped():
+		t.Error("subscriber keeping up was dropped")
+	default:
+	}
+}
+
+func TestHubResyncAndClose(t *testing.T) {
+	hub := NewHub()
+	open := hub.Subscribe(1, nil, nil)
+	closed := hub.Subscribe(2, []uint{10}, nil)
+	defer open.Close()
+
+	closed.Close()
+	closed.Close() // closing twice is harmless
+	select {
+	case <-closed.Dropped():
+	default:
+		t.Error("closed subscription is not done")
+	}
+
+	hub.Resync()
+	hub.Publish(models.RealtimeEvent{OrganizationID: 10})
+	select {
+	case event := <-open.Events():
+		if event.Type != EventResync {
+			t.Errorf("got a %q event, want %q", event.Type, EventResync)
+		}
+	default:
+		t.Error("open subscription was not told to resync")
+	}
+	if got := received(closed); got != nil {
+		t.Errorf("closed subscription received %v", got)
+	}
+}
@@ -0,0 +1,67 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	listenRetryMin = time.Second
+	listenRetryMax = 30 * time.Second
+)
+
+// Listen hands the events announced on models.RealtimeChannel to the hub
+// until ctx is done. It listens on a connection of its own, outside the
+// pool, and reconnects when the connection is lost.
+func Listen(ctx context.Context, dsn string, hub *Hub) {
+	retry := listenRetryMin
+	for {
+		err := listen(ctx, dsn, hub, func() { retry = listenRetryMin })
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("Realtime listener failed, reconnecting in %s: %v", retry, err)
+
+		timer := time.NewTimer(retry)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		retry = min(retry*2, listenRetryMax)
+	}
+}
+
+func listen(ctx context.Context, dsn string, hub *Hub, connected func()) error {
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{models.RealtimeChannel}.Sanitize()); err != nil {
+		return err
+	}
+	connected()
+	// Changes made while the connection was down were not heard of
+	hub.Resync()
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		var event models.RealtimeEvent
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			log.Printf("Ignoring malformed realtime event: %v", err)
+			continue
+		}
+		hub.Publish(event)
+	}
+}
@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// ActivityFilter scopes an activity feed. Unset fields are not filtered on.
+type ActivityFilter struct {
+	OrganizationID uint
+	ProjectID      uint
+	TaskID         uint
+	BeforeID       uint // cursor: only events with a lower ID are returned
+	Limit          int
+}
+
+// ActivityRepository defines the interface for activity event data access.
+// Events are written by model hooks, so the repository is read-only.
+type ActivityRepository interface {
+	List(ctx context.Context, filter ActivityFilter) ([]models.ActivityEvent, error)
+}
+
+// NewActivityRepository creates a new instance of ActivityRepository
+func NewActivityRepository(db *gorm.DB) ActivityRepository {
+	return &activityRepository{
+		db: db,
+	}
+}
+
+type activityRepository struct {
+	db *gorm.DB
+}
+
+func (r *activityRepository) List(ctx context.Context, filter ActivityFilter) ([]models.ActivityEvent, error) {
+	query := r.db.WithContext(ctx).Model(&models.ActivityEvent{})
+	if filter.OrganizationID != 0 {
+		query = query.Where("organization_id = ?", filter.OrganizationID)
+	}
+	if filter.ProjectID != 0 {
+		query = query.Where("project_id = ?", filter.ProjectID)
+	}
+	if filter.TaskID != 0 {
+		query = query.Where("task_id = ?", filter.TaskID)
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var events []models.ActivityEvent
+	if err := query.Order("id DESC").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AddOnRepository defines the interface for add-on data access: the catalog
+// and the add-ons organizations buy
+type AddOnRepository interface {
+	Create(ctx context.Context, addOn *models.AddOn) error
+	FindByID(ctx context.Context, id uint) (*models.AddOn, error)
+	Update(ctx context.Context, addOn *models.AddOn) error
+	List(ctx context.Context, includeArchived bool) ([]models.AddOn, error)
+	ListByOrganization(ctx context.Context, orgID uint) ([]models.OrganizationAddOn, error)
+	SetQuantity(ctx context.Context, orgID, addOnID uint, quantity int) error
+}
+
+// NewAddOnRepository creates a new instance of AddOnRepository
+func NewAddOnRepository(db *gorm.DB) AddOnRepository {
+	return &addOnRepository{
+		db: db,
+	}
+}
+
+type addOnRepository struct {
+	db *gorm.DB
+}
+
+func (r *addOnRepository) Create(ctx context.Context, addOn *models.AddOn) error {
+	return r.db.WithContext(ctx).Create(addOn).Error
+}
+
+func (r *addOnRepository) FindByID(ctx context.Context, id uint) (*models.AddOn, error) {
+	var addOn models.AddOn
+	if err := r.db.WithContext(ctx).First(&addOn, id).Error; err != nil {
+		return nil, err
+	}
+	return &addOn, nil
+}
+
+func (r *addOnRepository) Update(ctx context.Context, addOn *models.AddOn) error {
+	return r.db.WithContext(ctx).Save(addOn).Error
+}
+
+func (r *addOnRepository) List(ctx context.Context, includeArchived bool) ([]models.AddOn, error) {
+	query := r.db.WithContext(ctx)
+	if !includeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+
+	var addOns []models.AddOn
+	if err := query.Order("name ASC, id ASC").Find(&addOns).Error; err != nil {
+		return nil, err
+	}
+	return addOns, nil
+}
+
+func (r *addOnRepository) ListByOrganization(ctx context.Context, orgID uint) ([]models.OrganizationAddOn, error) {
+	var addOns []models.OrganizationAddOn
+	err := r.db.WithContext(ctx).
+		Preload("AddOn").
+		Where("organization_id = ?", orgID).
+		Order("id ASC").
+		Find(&addOns).Error
+	if err != nil {
+		return nil, err
+	}
+	return addOns, nil
+}
+
+// SetQuantity sets how many units of the add-on the organization buys,
+// removing the add-on when quantity is zero
+func (r *addOnRepository) SetQuantity(ctx context.Context, orgID, addOnID uint, quantity int) error {
+	if quantity == 0 {
+		return r.db.WithContext(ctx).
+			Where("organization_id = ? AND add_on_id = ?", orgID, addOnID).
+			Delete(&models.OrganizationAddOn{}).Error
+	}
+
+	addOn := models.OrganizationAddOn{OrganizationID: orgID, AddOnID: addOnID, Quantity: quantity}
+	return r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "organization_id"}, {Name: "add_on_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"quantity", "updated_at"}),
+		}).
+		Create(&addOn).Error
+}
@@ -0,0 +1,149 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AttachmentRepository defines the interface for attachment data access
+type AttachmentRepository interface {
+	SaveWithinLimit(ctx context.Context, attachment *models.Attachment, limitBytes int64) (bool, error)
+	FindByID(ctx context.Context, id uint) (*models.Attachment, error)
+	ListByTask(ctx context.Context, taskID uint) ([]models.Attachment, error)
+	ListByComment(ctx context.Context, commentID uint) ([]models.Attachment, error)
+	ListByExpense(ctx context.Context, expenseID uint) ([]models.Attachment, error)
+	Delete(ctx context.Context, attachment *models.Attachment) error
+	StorageUsed(ctx context.Context, orgID uint) (int64, error)
+}
+
+// NewAttachmentRepository creates a new instance of AttachmentRepository
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{
+		db: db,
+	}
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+// SaveWithinLimit creates or updates an attachment unless the organization's
+// storage, including this attachment, would exceed limitBytes. It reports
+// whether the attachment was saved. The organization row is locked so that
+// concurrent uploads cannot overshoot the limit together.
+func (r *attachmentRepository) SaveWithinLimit(ctx context.Context, attachment *models.Attachment, limitBytes int64) (bool, error) {
+	saved := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var org models.Organization
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&org, attachment.OrganizationID).Error
+		if err != nil {
+			return err
+		}
+
+		var others int64
+		err = countedAttachments(tx, attachment.OrganizationID).
+			Where("id <> ?", attachment.ID).
+			Select("COALESCE(SUM(size), 0)").
+			Scan(&others).Error
+		if err != nil {
+			return err
+		}
+		if attachment.Status != models.AttachmentStatusQuarantined && others+attachment.Size > limitBytes {
+			return nil
+		}
+
+		if err := tx.Omit(clause.Associations).Save(attachment).Error; err != nil {
+			return err
+		}
+		saved = true
+		return syncStorageUsage(tx, attachment.OrganizationID)
+	})
+	return saved, err
+}
+
+func (r *attachmentRepository) FindByID(ctx context.Context, id uint) (*models.Attachment, error) {
+	var attachment models.Attachment
+	if err := r.db.WithContext(ctx).First(&attachment, id).Error; err != nil {
+		return nil, err
+	}
+	return &attachment, nil
+}
+
+func (r *attachmentRepository) ListByTask(ctx context.Context, taskID uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.WithContext(ctx).
+		Where("task_id = ?", taskID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	if err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *attachmentRepository) ListByComment(ctx context.Context, commentID uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.WithContext(ctx).
+		Where("comment_id = ?", commentID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	if err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+func (r *attachmentRepository) ListByExpense(ctx context.Context, expenseID uint) ([]models.Attachment, error) {
+	var attachments []models.Attachment
+	err := r.db.WithContext(ctx).
+		Where("expense_id = ?", expenseID).
+		Order("created_at ASC").
+		Find(&attachments).Error
+	if err != nil {
+		return nil, err
+	}
+	return attachments, nil
+}
+
+// Delete removes an attachment for good, since its content is deleted from storage too
+func (r *attachmentRepository) Delete(ctx context.Context, attachment *models.Attachment) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Delete(&models.Attachment{}, attachment.ID).Error; err != nil {
+			return err
+		}
+		return syncStorageUsage(tx, attachment.OrganizationID)
+	})
+}
+
+func (r *attachmentRepository) StorageUsed(ctx context.Context, orgID uint) (int64, error) {
+	var used int64
+	err := countedAttachments(r.db.WithContext(ctx), orgID).
+		Select("COALESCE(SUM(size), 0)").
+		Scan(&used).Error
+	return used, err
+}
+
+// countedAttachments selects the attachments that count toward an
+// organization's storage. Pending uploads count so their space is reserved.
+func countedAttachments(tx *gorm.DB, orgID uint) *gorm.DB {
+	return tx.Model(&models.Attachment{}).
+		Where("organization_id = ? AND status <> ?", orgID, models.AttachmentStatusQuarantined)
+}
+
+// syncStorageUsage records the organization's storage in GB on its current
+// and scheduled subscriptions
+func syncStorageUsage(tx *gorm.DB, orgID uint) error {
+	var used int64
+	if err := countedAttachments(tx, orgID).Select("COALESCE(SUM(size), 0)").Scan(&used).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Subscription{}).
+		Where("organization_id = ? AND status <> ?", orgID, models.SubscriptionStatusCancelled).
+		UpdateColumn("current_storage", float64(used)/models.BytesPerGB).Error
+}
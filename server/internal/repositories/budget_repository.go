@@ -0,0 +1,228 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/money"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LaborCost is the cost of a project's finished time entries at the hourly
+// rates in effect when each entry started. Approved covers entries of approved
+// timesheets; pending covers the others.
+type LaborCost struct {
+	ApprovedHours float64     `json:"approved_hours"`
+	ApprovedCost  money.Money `json:"approved_cost"`
+	PendingHours  float64     `json:"pending_hours"`
+	PendingCost   money.Money `json:"pending_cost"`
+	UnratedHours  float64     `json:"unrated_hours"` // hours without a rate in the project's currency, costed at zero
+}
+
+// ExpenseTotal is the sum of a project's expenses in one category and status
+type ExpenseTotal struct {
+	Category string
+	Status   models.ExpenseStatus
+	Amount   money.Money `gorm:"embedded;embeddedPrefix:amount_"`
+}
+
+// ProjectProgress counts a project's tasks and estimated hours, in total and done
+type ProjectProgress struct {
+	TotalTasks    int64
+	DoneTasks     int64
+	TotalEstimate float64
+	DoneEstimate  float64
+}
+
+// BudgetRepository defines the interface for budget, rate and cost data access
+type BudgetRepository interface {
+	CreateLine(ctx context.Context, line *models.BudgetLine) error
+	FindLine(ctx context.Context, id uint) (*models.BudgetLine, error)
+	UpdateLine(ctx context.Context, line *models.BudgetLine) error
+	DeleteLine(ctx context.Context, line *models.BudgetLine) error
+	ListLines(ctx context.Context, projectID uint) ([]models.BudgetLine, error)
+	SaveRate(ctx context.Context, rate *models.HourlyRate) error
+	ListRates(ctx context.Context, orgID uint) ([]models.HourlyRate, error)
+	LaborCost(ctx context.Context, projectID uint, currency string) (*LaborCost, error)
+	ExpenseTotals(ctx context.Context, projectID uint) ([]ExpenseTotal, error)
+	Progress(ctx context.Context, projectID uint) (*ProjectProgress, error)
+	CreateAlert(ctx context.Context, alert *models.BudgetAlert) (bool, error)
+	ListAlerts(ctx context.Context, projectID uint) ([]models.BudgetAlert, error)
+}
+
+// NewBudgetRepository creates a new instance of BudgetRepository
+func NewBudgetRepository(db *gorm.DB) BudgetRepository {
+	return &budgetRepository{
+		db: db,
+	}
+}
+
+type budgetRepository struct {
+	db *gorm.DB
+}
+
+func (r *budgetRepository) CreateLine(ctx context.Context, line *models.BudgetLine) error {
+	return r.db.WithContext(ctx).Create(line).Error
+}
+
+func (r *budgetRepository) FindLine(ctx context.Context, id uint) (*models.BudgetLine, error) {
+	var line models.BudgetLine
+	if err := r.db.WithContext(ctx).First(&line, id).Error; err != nil {
+		return nil, err
+	}
+	return &line, nil
+}
+
+func (r *budgetRepository) UpdateLine(ctx context.Context, line *models.BudgetLine) error {
+	return r.db.WithContext(ctx).Save(line).Error
+}
+
+func (r *budgetRepository) DeleteLine(ctx context.Context, line *models.BudgetLine) error {
+	return r.db.WithContext(ctx).Delete(line).Error
+}
+
+func (r *budgetRepository) ListLines(ctx context.Context, projectID uint) ([]models.BudgetLine, error) {
+	var lines []models.BudgetLine
+	err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("category ASC").
+		Find(&lines).Error
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// SaveRate creates a rate, replacing the member's rate with the same effective date
+func (r *budgetRepository) SaveRate(ctx context.Context, rate *models.HourlyRate) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "organization_id"}, {Name: "user_id"}, {Name: "effective_from"}},
+			DoUpdates: clause.AssignmentColumns([]string{"rate"}),
+		}).
+		Create(rate).Error
+}
+
+func (r *budgetRepository) ListRates(ctx context.Context, orgID uint) ([]models.HourlyRate, error) {
+	var rates []models.HourlyRate
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", orgID).
+		Order("user_id ASC, effective_from DESC").
+		Find(&rates).Error
+	if err != nil {
+		return nil, err
+	}
+	return rates, nil
+}
+
+// LaborCost costs each entry in minor units of currency, rounded half away
+// from zero, before adding them up
+func (r *budgetRepository) LaborCost(ctx context.Context, projectID uint, currency string) (*LaborCost, error) {
+	var totals struct {
+		ApprovedHours float64
+		ApprovedCost  int64
+		PendingHours  float64
+		PendingCost   int64
+		UnratedHours  float64
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT
+			COALESCE(SUM(hours) FILTER (WHERE approved), 0) AS approved_hours,
+			COALESCE(SUM(cost) FILTER (WHERE approved), 0) AS approved_cost,
+			COALESCE(SUM(hours) FILTER (WHERE NOT approved), 0) AS pending_hours,
+			COALESCE(SUM(cost) FILTER (WHERE NOT approved), 0) AS pending_cost,
+			COALESCE(SUM(hours) FILTER (WHERE cost IS NULL), 0) AS unrated_hours
+		FROM (
+			SELECT
+				time_entries.duration / 3600.0 AS hours,
+				COALESCE(timesheets.status = @approved, false) AS approved,
+				CASE WHEN rates.rate_currency = @currency
+					THEN ROUND(time_entries.duration::numeric * rates.rate_minor / 3600)
+				END AS cost
+			FROM time_entries
+			LEFT JOIN timesheets ON timesheets.id = time_entries.timesheet_id AND timesheets.deleted_at IS NULL
+			LEFT JOIN LATERAL (
+				SELECT hourly_rates.rate_minor, hourly_rates.rate_currency FROM hourly_rates
+				WHERE hourly_rates.organization_id = time_entries.organization_id
+				AND hourly_rates.user_id = time_entries.user_id
+				AND hourly_rates.effective_from <= time_entries.started_at::date
+				ORDER BY hourly_rates.effective_from DESC
+				LIMIT 1
+			) AS rates ON true
+			WHERE time_entries.project_id = @project
+			AND time_entries.ended_at IS NOT NULL
+			AND time_entries.deleted_at IS NULL
+		) AS entries
+	`, map[string]interface{}{
+		"project":  projectID,
+		"currency": currency,
+		"approved": models.TimesheetStatusApproved,
+	}).Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &LaborCost{
+		ApprovedHours: totals.ApprovedHours,
+		ApprovedCost:  money.New(totals.ApprovedCost, currency),
+		PendingHours:  totals.PendingHours,
+		PendingCost:   money.New(totals.PendingCost, currency),
+		UnratedHours:  totals.UnratedHours,
+	}, nil
+}
+
+func (r *budgetRepository) ExpenseTotals(ctx context.Context, projectID uint) ([]ExpenseTotal, error) {
+	var totals []ExpenseTotal
+	err := r.db.WithContext(ctx).
+		Model(&models.Expense{}).
+		Select("category, status, amount_currency, SUM(amount_minor) AS amount_minor").
+		Where("project_id = ?", projectID).
+		Group("category, status, amount_currency").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return totals, nil
+}
+
+func (r *budgetRepository) Progress(ctx context.Context, projectID uint) (*ProjectProgress, error) {
+	var progress ProjectProgress
+	err := r.db.WithContext(ctx).
+		Model(&models.Task{}).
+		Select(`COUNT(*) AS total_tasks,
+			COUNT(*) FILTER (WHERE status = ?) AS done_tasks,
+			COALESCE(SUM(estimated_hours), 0) AS total_estimate,
+			COALESCE(SUM(estimated_hours) FILTER (WHERE status = ?), 0) AS done_estimate`,
+			models.TaskStatusDone, models.TaskStatusDone).
+		Where("project_id = ?", projectID).
+		Scan(&progress).Error
+	if err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// CreateAlert stores an alert unless the project already has one for the
+// threshold, reporting whether it was created
+func (r *budgetRepository) CreateAlert(ctx context.Context, alert *models.BudgetAlert) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(alert)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *budgetRepository) ListAlerts(ctx context.Context, projectID uint) ([]models.BudgetAlert, error) {
+	var alerts []models.BudgetAlert
+	err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("threshold ASC").
+		Find(&alerts).Error
+	if err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// CommentRepository defines the interface for comment data access
+type CommentRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.Comment, error)
+}
+
+// NewCommentRepository creates a new instance of CommentRepository
+func NewCommentRepository(db *gorm.DB) CommentRepository {
+	return &commentRepository{
+		db: db,
+	}
+}
+
+type commentRepository struct {
+	db *gorm.DB
+}
+
+func (r *commentRepository) FindByID(ctx context.Context, id uint) (*models.Comment, error) {
+	var comment models.Comment
+	if err := r.db.WithContext(ctx).First(&comment, id).Error; err != nil {
+		return nil, err
+	}
+	return &comment, nil
+}
@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// CouponRepository defines the interface for coupon data access: the coupons
+// on offer and the organizations redeeming them
+type CouponRepository interface {
+	Create(ctx context.Context, coupon *models.Coupon) error
+	FindByID(ctx context.Context, id uint) (*models.Coupon, error)
+	FindByCode(ctx context.Context, code string) (*models.Coupon, error)
+	Update(ctx context.Context, coupon *models.Coupon) error
+	List(ctx context.Context, includeArchived bool) ([]models.Coupon, error)
+	FindActiveRedemption(ctx context.Context, orgID uint) (*models.CouponRedemption, error)
+	HasRedeemed(ctx context.Context, couponID, orgID uint) (bool, error)
+	Redeem(ctx context.Context, redemption *models.CouponRedemption, now time.Time) (bool, error)
+	EndRedemption(ctx context.Context, orgID uint, now time.Time) (bool, error)
+}
+
+// NewCouponRepository creates a new instance of CouponRepository
+func NewCouponRepository(db *gorm.DB) CouponRepository {
+	return &couponRepository{
+		db: db,
+	}
+}
+
+type couponRepository struct {
+	db *gorm.DB
+}
+
+func (r *couponRepository) Create(ctx context.Context, coupon *models.Coupon) error {
+	return r.db.WithContext(ctx).Create(coupon).Error
+}
+
+func (r *couponRepository) FindByID(ctx context.Context, id uint) (*models.Coupon, error) {
+	var coupon models.Coupon
+	if err := r.db.WithContext(ctx).First(&coupon, id).Error; err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+func (r *couponRepository) FindByCode(ctx context.Context, code string) (*models.Coupon, error) {
+	var coupon models.Coupon
+	if err := r.db.WithContext(ctx).Where("code = ?", code).First(&coupon).Error; err != nil {
+		return nil, err
+	}
+	return &coupon, nil
+}
+
+func (r *couponRepository) Update(ctx context.Context, coupon *models.Coupon) error {
+	return r.db.WithContext(ctx).Save(coupon).Error
+}
+
+func (r *couponRepository) List(ctx context.Context, includeArchived bool) ([]models.Coupon, error) {
+	query := r.db.WithContext(ctx)
+	if !includeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+
+	var coupons []models.Coupon
+	if err := query.Order("code ASC").Find(&coupons).Error; err != nil {
+		return nil, err
+	}
+	return coupons, nil
+}
+
+// FindActiveRedemption returns the organization's running discount with its coupon
+func (r *couponRepository) FindActiveRedemption(ctx context.Context, orgID uint) (*models.CouponRedemption, error) {
+	var redemption models.CouponRedemption
+	err := r.db.WithContext(ctx).
+		Preload("Coupon").
+		Where("organization_id = ? AND ended_at IS NULL", orgID).
+		First(&redemption).Error
+	if err != nil {
+		return nil, err
+	}
+	return &redemption, nil
+}
+
+func (r *couponRepository) HasRedeemed(ctx context.Context, couponID, orgID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.CouponRedemption{}).
+		Where("coupon_id = ? AND organization_id = ?", couponID, orgID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Redeem stores the redemption and counts it against its coupon, in one
+// transaction. It reports false, storing nothing, if the coupon has been
+// archived, has expired or has run out of redemptions in the meantime.
+func (r *couponRepository) Redeem(ctx context.Context, redemption *models.CouponRedemption, now time.Time) (bool, error) {
+	redeemed := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// UpdateColumn skips the coupon hooks, which validate a whole coupon
+		result := tx.Model(&models.Coupon{}).
+			Where("id = ? AND archived_at IS NULL", redemption.CouponID).
+			Where("expires_at IS NULL OR expires_at >= ?", now).
+			Where("max_redemptions = 0 OR times_redeemed < max_redemptions").
+			UpdateColumn("times_redeemed", gorm.Expr("times_redeemed + 1"))
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+		if err := tx.Omit("Coupon").Create(redemption).Error; err != nil {
+			return err
+		}
+		redeemed = true
+		return nil
+	})
+	return redeemed, err
+}
+
+// EndRedemption stops the organization's running discount, reporting false if
+// it had none
+func (r *couponRepository) EndRedemption(ctx context.Context, orgID uint, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.CouponRedemption{}).
+		Where("organization_id = ? AND ended_at IS NULL", orgID).
+		Update("ended_at", now)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
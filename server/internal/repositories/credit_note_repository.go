@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// errCreditNoteIssued rolls back the numbering of a credit note that was
+// issued in the meantime
+var errCreditNoteIssued = errors.New("credit note already issued")
+
+// CreditNoteRepository defines the interface for credit note data access
+type CreditNoteRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.CreditNote, error)
+	FindByTransaction(ctx context.Context, transactionID uint) (*models.CreditNote, error)
+	ListByOrganization(ctx context.Context, orgID uint) ([]models.CreditNote, error)
+	Issue(ctx context.Context, note *models.CreditNote, scope string, number func(seq int64) string) (bool, error)
+	ListUncredited(ctx context.Context) ([]models.PaymentTransaction, error)
+	ListReversed(ctx context.Context) ([]models.CreditNote, error)
+	Void(ctx context.Context, note *models.CreditNote) error
+}
+
+// NewCreditNoteRepository creates a new instance of CreditNoteRepository
+func NewCreditNoteRepository(db *gorm.DB) CreditNoteRepository {
+	return &creditNoteRepository{
+		db: db,
+	}
+}
+
+type creditNoteRepository struct {
+	db *gorm.DB
+}
+
+func (r *creditNoteRepository) FindByID(ctx context.Context, id uint) (*models.CreditNote, error) {
+	var note models.CreditNote
+	if err := r.db.WithContext(ctx).First(&note, id).Error; err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+func (r *creditNoteRepository) FindByTransaction(ctx context.Context, transactionID uint) (*models.CreditNote, error) {
+	var note models.CreditNote
+	if err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).First(&note).Error; err != nil {
+		return nil, err
+	}
+	return &note, nil
+}
+
+func (r *creditNoteRepository) ListByOrganization(ctx context.Context, orgID uint) ([]models.CreditNote, error) {
+	var notes []models.CreditNote
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", orgID).
+		Order("issued_at DESC, id DESC").
+		Find(&notes).Error
+	if err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// Issue stores a credit note with the next number in the scope's sequence.
+// A refund only gets one credit note: Issue reports false, storing nothing
+// and using up no number, if the note's refund already has one.
+func (r *creditNoteRepository) Issue(ctx context.Context, note *models.CreditNote, scope string, number func(seq int64) string) (bool, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		seq, err := nextSequenceNumber(tx, scope)
+		if err != nil {
+			return err
+		}
+
+		// Issuers of the same refund share a scope, so the sequence lock
+		// makes them check one at a time
+		var count int64
+		err = tx.Model(&models.CreditNote{}).Where("transaction_id = ?", note.TransactionID).Count(&count).Error
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			return errCreditNoteIssued
+		}
+
+		note.SequenceNumber = seq
+		note.CreditNoteNumber = number(seq)
+		return tx.Omit(clause.Associations).Create(note).Error
+	})
+	if errors.Is(err, errCreditNoteIssued) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// ListUncredited lists the refunds that succeeded without a credit note being
+// issued for them, oldest first
+func (r *creditNoteRepository) ListUncredited(ctx context.Context) ([]models.PaymentTransaction, error) {
+	var transactions []models.PaymentTransaction
+	err := r.db.WithContext(ctx).
+		Where("kind = ? AND status = ?", models.TransactionKindRefund, models.PaymentStatusSucceeded).
+		Where("NOT EXISTS (SELECT 1 FROM credit_notes n WHERE n.transaction_id = payment_transactions.id AND n.deleted_at IS NULL)").
+		Order("updated_at ASC, id ASC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// ListReversed lists the credit notes that are not void although their refund
+// failed, oldest first
+func (r *creditNoteRepository) ListReversed(ctx context.Context) ([]models.CreditNote, error) {
+	var notes []models.CreditNote
+	err := r.db.WithContext(ctx).
+		Where("voided_at IS NULL").
+		Where("EXISTS (SELECT 1 FROM payment_transactions t WHERE t.id = credit_notes.transaction_id AND t.status = ?)", models.PaymentStatusFailed).
+		Order("issued_at ASC, id ASC").
+		Find(&notes).Error
+	if err != nil {
+		return nil, err
+	}
+	return notes, nil
+}
+
+// Void stores that the credit note was voided, unless it was voided before
+func (r *creditNoteRepository) Void(ctx context.Context, note *models.CreditNote) error {
+	return r.db.WithContext(ctx).
+		Model(note).
+		Where("voided_at IS NULL").
+		Updates(map[string]interface{}{"voided_at": note.VoidedAt, "void_reason": note.VoidReason}).Error
+}
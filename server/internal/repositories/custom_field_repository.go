@@ -0,0 +1,98 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CustomFieldRepository defines the interface for custom field data access
+type CustomFieldRepository interface {
+	Create(ctx context.Context, field *models.CustomFieldDefinition) error
+	CreateMany(ctx context.Context, fields []models.CustomFieldDefinition) error
+	FindByID(ctx context.Context, id uint) (*models.CustomFieldDefinition, error)
+	Update(ctx context.Context, field *models.CustomFieldDefinition) error
+	Delete(ctx context.Context, id uint) error
+	ListByProject(ctx context.Context, projectID uint) ([]models.CustomFieldDefinition, error)
+	SetTaskValues(ctx context.Context, taskID uint, values []models.CustomFieldValue, clearFieldIDs []uint) error
+}
+
+// NewCustomFieldRepository creates a new instance of CustomFieldRepository
+func NewCustomFieldRepository(db *gorm.DB) CustomFieldRepository {
+	return &customFieldRepository{
+		db: db,
+	}
+}
+
+type customFieldRepository struct {
+	db *gorm.DB
+}
+
+func (r *customFieldRepository) Create(ctx context.Context, field *models.CustomFieldDefinition) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(field).Error
+}
+
+func (r *customFieldRepository) CreateMany(ctx context.Context, fields []models.CustomFieldDefinition) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(&fields).Error
+}
+
+func (r *customFieldRepository) FindByID(ctx context.Context, id uint) (*models.CustomFieldDefinition, error) {
+	var field models.CustomFieldDefinition
+	if err := r.db.WithContext(ctx).First(&field, id).Error; err != nil {
+		return nil, err
+	}
+	return &field, nil
+}
+
+func (r *customFieldRepository) Update(ctx context.Context, field *models.CustomFieldDefinition) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(field).Error
+}
+
+func (r *customFieldRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("field_id = ?", id).Delete(&models.CustomFieldValue{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.CustomFieldDefinition{}, id).Error
+	})
+}
+
+func (r *customFieldRepository) ListByProject(ctx context.Context, projectID uint) ([]models.CustomFieldDefinition, error) {
+	var fields []models.CustomFieldDefinition
+	err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("position ASC, id ASC").
+		Find(&fields).Error
+	if err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+func (r *customFieldRepository) SetTaskValues(ctx context.Context, taskID uint, values []models.CustomFieldValue, clearFieldIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if len(clearFieldIDs) > 0 {
+			err := tx.Where("task_id = ? AND field_id IN ?", taskID, clearFieldIDs).
+				Delete(&models.CustomFieldValue{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		for i := range values {
+			values[i].TaskID = taskID
+			err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "task_id"}, {Name: "field_id"}},
+				DoUpdates: clause.AssignmentColumns([]string{"text_value", "number_value", "date_value", "user_id", "option_values", "updated_at"}),
+			}).Create(&values[i]).Error
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
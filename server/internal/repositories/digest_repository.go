@@ -0,0 +1,187 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// DigestRecipient is a user who gets a digest, with what is needed to send it
+type DigestRecipient struct {
+	models.DigestSetting
+	Email     string
+	FirstName string
+	Language  string
+	TimeZone  string
+}
+
+// DigestComment is a comment on a task the digest's recipient watches
+type DigestComment struct {
+	TaskID          uint      `json:"task_id"`
+	TaskTitle       string    `json:"task_title"`
+	AuthorFirstName string    `json:"author_first_name"`
+	AuthorLastName  string    `json:"author_last_name"`
+	Content         string    `json:"content"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// DigestStatusChange is a change of status of one of the recipient's projects
+type DigestStatusChange struct {
+	ProjectID   uint                `json:"project_id"`
+	ProjectName string              `json:"project_name"`
+	Changes     models.FieldChanges `json:"changes"`
+	CreatedAt   time.Time           `json:"created_at"`
+}
+
+// DigestRepository defines the interface for digest settings and the data
+// digests summarize
+type DigestRepository interface {
+	FindSetting(ctx context.Context, userID uint) (*models.DigestSetting, error)
+	SaveSetting(ctx context.Context, setting *models.DigestSetting) error
+	Unsubscribe(ctx context.Context, userID uint) error
+	ListEnabled(ctx context.Context) ([]DigestRecipient, error)
+	QueueDigest(ctx context.Context, userID uint, email *models.OutboxEmail, at time.Time) error
+	ListAssignedDue(ctx context.Context, userID uint, until time.Time) ([]models.Task, error)
+	ListWatchedComments(ctx context.Context, userID uint, since, until time.Time, limit int) ([]DigestComment, int64, error)
+	ListProjectStatusChanges(ctx context.Context, userID uint, since, until time.Time) ([]DigestStatusChange, error)
+}
+
+// NewDigestRepository creates a new instance of DigestRepository
+func NewDigestRepository(db *gorm.DB) DigestRepository {
+	return &digestRepository{
+		db: db,
+	}
+}
+
+type digestRepository struct {
+	db *gorm.DB
+}
+
+func (r *digestRepository) FindSetting(ctx context.Context, userID uint) (*models.DigestSetting, error) {
+	var setting models.DigestSetting
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).First(&setting).Error; err != nil {
+		return nil, err
+	}
+	return &setting, nil
+}
+
+// SaveSetting creates or replaces the user's digest setting
+func (r *digestRepository) SaveSetting(ctx context.Context, setting *models.DigestSetting) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"frequency", "hour", "weekday", "last_sent_at", "updated_at"}),
+		}).
+		Create(setting).Error
+}
+
+// Unsubscribe turns the user's digest off. A user without a digest is left as is.
+func (r *digestRepository) Unsubscribe(ctx context.Context, userID uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.DigestSetting{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]interface{}{"frequency": models.DigestFrequencyOff, "updated_at": time.Now()}).Error
+}
+
+// ListEnabled lists the users who get a digest
+func (r *digestRepository) ListEnabled(ctx context.Context) ([]DigestRecipient, error) {
+	var recipients []DigestRecipient
+	err := r.db.WithContext(ctx).
+		Table("digest_settings").
+		Select("digest_settings.*, users.email, users.first_name, users.language, users.time_zone").
+		Joins("JOIN users ON users.id = digest_settings.user_id AND users.deleted_at IS NULL").
+		Where("digest_settings.frequency <> ?", models.DigestFrequencyOff).
+		Order("digest_settings.user_id").
+		Scan(&recipients).Error
+	if err != nil {
+		return nil, err
+	}
+	return recipients, nil
+}
+
+// QueueDigest puts the user's digest in the email outbox and records it as
+// sent. A nil email records a digest with nothing in it, which is not sent.
+func (r *digestRepository) QueueDigest(ctx context.Context, userID uint, email *models.OutboxEmail, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.DigestSetting{}).
+			Where("user_id = ?", userID).
+			Update("last_sent_at", at).Error
+		if err != nil || email == nil {
+			return err
+		}
+		return tx.Create(email).Error
+	})
+}
+
+// ListAssignedDue lists the unfinished tasks assigned to the user that are
+// due by a time, overdue ones included, soonest first
+func (r *digestRepository) ListAssignedDue(ctx context.Context, userID uint, until time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.WithContext(ctx).
+		Where("assignee_id = ? AND status <> ?", userID, models.TaskStatusDone).
+		Where("due_date <= ?", until).
+		Order("due_date ASC, id ASC").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+// ListWatchedComments lists up to limit of the comments others made in a
+// period on the tasks the user watches in organizations they still belong
+// to, oldest first, and counts them all
+func (r *digestRepository) ListWatchedComments(ctx context.Context, userID uint, since, until time.Time, limit int) ([]DigestComment, int64, error) {
+	query := r.db.WithContext(ctx).
+		Table("comments").
+		Joins("JOIN task_watchers ON task_watchers.task_id = comments.task_id AND task_watchers.user_id = ?", userID).
+		Joins("JOIN tasks ON tasks.id = comments.task_id AND tasks.deleted_at IS NULL").
+		Joins("JOIN projects ON projects.id = tasks.project_id").
+		Joins("JOIN organization_users ON organization_users.organization_id = projects.organization_id AND organization_users.user_id = ?", userID).
+		Where("comments.deleted_at IS NULL AND comments.user_id <> ?", userID).
+		Where("comments.created_at > ? AND comments.created_at <= ?", since, until)
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	var comments []DigestComment
+	err := query.
+		Select("comments.task_id, tasks.title AS task_title, users.first_name AS author_first_name, users.last_name AS author_last_name, comments.content, comments.created_at").
+		Joins("JOIN users ON users.id = comments.user_id").
+		Order("comments.created_at ASC, comments.id ASC").
+		Limit(limit).
+		Scan(&comments).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return comments, total, nil
+}
+
+// ListProjectStatusChanges lists the status changes others made in a period
+// to the projects the user is a member of, oldest first
+func (r *digestRepository) ListProjectStatusChanges(ctx context.Context, userID uint, since, until time.Time) ([]DigestStatusChange, error) {
+	var changes []DigestStatusChange
+	err := r.db.WithContext(ctx).
+		Table("activity_events").
+		Select("activity_events.project_id, projects.name AS project_name, activity_events.changes, activity_events.created_at").
+		Joins("JOIN projects ON projects.id = activity_events.project_id AND projects.deleted_at IS NULL").
+		Joins("JOIN project_members ON project_members.project_id = projects.id AND project_members.user_id = ?", userID).
+		Where("activity_events.entity_type = ? AND activity_events.action = ?", models.ActivityEntityProject, models.ActivityActionUpdated).
+		Where(`activity_events.changes @> '[{"field": "status"}]'`).
+		Where("activity_events.actor_id IS NULL OR activity_events.actor_id <> ?", userID).
+		Where("activity_events.created_at > ? AND activity_events.created_at <= ?", since, until).
+		Order("activity_events.created_at ASC, activity_events.id ASC").
+		Scan(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
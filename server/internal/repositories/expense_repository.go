@@ -0,0 +1,75 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExpenseFilter narrows expense listings. Zero values are ignored.
+type ExpenseFilter struct {
+	ProjectID     uint
+	SubmittedByID uint
+	Status        models.ExpenseStatus
+}
+
+// ExpenseRepository defines the interface for expense data access
+type ExpenseRepository interface {
+	Create(ctx context.Context, expense *models.Expense) error
+	FindByID(ctx context.Context, id uint) (*models.Expense, error)
+	Update(ctx context.Context, expense *models.Expense) error
+	Delete(ctx context.Context, expense *models.Expense) error
+	List(ctx context.Context, filter ExpenseFilter) ([]models.Expense, error)
+}
+
+// NewExpenseRepository creates a new instance of ExpenseRepository
+func NewExpenseRepository(db *gorm.DB) ExpenseRepository {
+	return &expenseRepository{
+		db: db,
+	}
+}
+
+type expenseRepository struct {
+	db *gorm.DB
+}
+
+func (r *expenseRepository) Create(ctx context.Context, expense *models.Expense) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(expense).Error
+}
+
+func (r *expenseRepository) FindByID(ctx context.Context, id uint) (*models.Expense, error) {
+	var expense models.Expense
+	if err := r.db.WithContext(ctx).Preload("Receipts").First(&expense, id).Error; err != nil {
+		return nil, err
+	}
+	return &expense, nil
+}
+
+func (r *expenseRepository) Update(ctx context.Context, expense *models.Expense) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(expense).Error
+}
+
+func (r *expenseRepository) Delete(ctx context.Context, expense *models.Expense) error {
+	return r.db.WithContext(ctx).Delete(expense).Error
+}
+
+func (r *expenseRepository) List(ctx context.Context, filter ExpenseFilter) ([]models.Expense, error) {
+	query := r.db.WithContext(ctx).Preload("Receipts")
+	if filter.ProjectID != 0 {
+		query = query.Where("project_id = ?", filter.ProjectID)
+	}
+	if filter.SubmittedByID != 0 {
+		query = query.Where("submitted_by_id = ?", filter.SubmittedByID)
+	}
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	var expenses []models.Expense
+	if err := query.Order("incurred_on DESC, id DESC").Find(&expenses).Error; err != nil {
+		return nil, err
+	}
+	return expenses, nil
+}
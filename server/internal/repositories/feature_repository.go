@@ -0,0 +1,70 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// FeatureRepository defines the interface for feature override data access
+type FeatureRepository interface {
+	ListOverrides(ctx context.Context, orgID uint) ([]models.FeatureOverride, error)
+	SaveOverride(ctx context.Context, override *models.FeatureOverride) error
+	DeleteOverride(ctx context.Context, orgID uint, feature string) (bool, error)
+	SyncExpiredOverrides(ctx context.Context, since, now time.Time) error
+}
+
+// NewFeatureRepository creates a new instance of FeatureRepository
+func NewFeatureRepository(db *gorm.DB) FeatureRepository {
+	return &featureRepository{
+		db: db,
+	}
+}
+
+type featureRepository struct {
+	db *gorm.DB
+}
+
+func (r *featureRepository) ListOverrides(ctx context.Context, orgID uint) ([]models.FeatureOverride, error) {
+	var overrides []models.FeatureOverride
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", orgID).
+		Order("feature ASC").
+		Find(&overrides).Error
+	if err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
+// SaveOverride creates the organization's override for the feature, or
+// replaces the one it has
+func (r *featureRepository) SaveOverride(ctx context.Context, override *models.FeatureOverride) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "organization_id"}, {Name: "feature"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "reason", "set_by_id", "expires_at", "updated_at"}),
+		}).
+		Create(override).Error
+}
+
+// DeleteOverride removes the organization's override for the feature. The
+// model names the organization for its AfterDelete hook.
+func (r *featureRepository) DeleteOverride(ctx context.Context, orgID uint, feature string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("organization_id = ? AND feature = ?", orgID, feature).
+		Delete(&models.FeatureOverride{OrganizationID: orgID})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// SyncExpiredOverrides resets the feature flags of organizations whose
+// overrides expired after since and by now
+func (r *featureRepository) SyncExpiredOverrides(ctx context.Context, since, now time.Time) error {
+	return models.SyncExpiredFeatureOverrides(r.db.WithContext(ctx), since, now)
+}
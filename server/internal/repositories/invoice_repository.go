@@ -0,0 +1,249 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InvoiceRepository defines the interface for invoice data access
+type InvoiceRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.Invoice, error)
+	ListByOrganization(ctx context.Context, orgID uint) ([]models.Invoice, error)
+	ListPendingItems(ctx context.Context, orgID uint, currency string) ([]models.InvoiceItem, error)
+	ListDueSubscriptions(ctx context.Context, now time.Time) ([]models.Subscription, error)
+	MarkBilled(ctx context.Context, subscriptionID uint, periodStart, at time.Time) (bool, error)
+	Issue(ctx context.Context, invoice *models.Invoice, scope string, number func(seq int64) string, carried []models.InvoiceItem) (bool, error)
+	ListOverdue(ctx context.Context, now time.Time) ([]models.Invoice, error)
+	SaveDunning(ctx context.Context, invoice *models.Invoice) error
+	CreatePendingItem(ctx context.Context, item *models.InvoiceItem) error
+	ListPendingCredits(ctx context.Context, orgID uint) ([]models.InvoiceItem, error)
+}
+
+// NewInvoiceRepository creates a new instance of InvoiceRepository
+func NewInvoiceRepository(db *gorm.DB) InvoiceRepository {
+	return &invoiceRepository{
+		db: db,
+	}
+}
+
+type invoiceRepository struct {
+	db *gorm.DB
+}
+
+func (r *invoiceRepository) FindByID(ctx context.Context, id uint) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.WithContext(ctx).
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Taxes", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("CreditNotes", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		First(&invoice, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+func (r *invoiceRepository) ListByOrganization(ctx context.Context, orgID uint) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", orgID).
+		Order("issued_at DESC, id DESC").
+		Find(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+// ListPendingItems lists the organization's items in the currency that have
+// not been billed yet, such as prorated charges and carried credits
+func (r *invoiceRepository) ListPendingItems(ctx context.Context, orgID uint, currency string) ([]models.InvoiceItem, error) {
+	var items []models.InvoiceItem
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND invoice_id IS NULL AND amount_currency = ?", orgID, currency).
+		Order("created_at ASC, id ASC").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// CreatePendingItem stores an item to be billed on the organization's next invoice
+func (r *invoiceRepository) CreatePendingItem(ctx context.Context, item *models.InvoiceItem) error {
+	item.InvoiceID = nil
+	return r.db.WithContext(ctx).Create(item).Error
+}
+
+// ListPendingCredits lists the organization's credits, in every currency,
+// that have not been applied to an invoice yet
+func (r *invoiceRepository) ListPendingCredits(ctx context.Context, orgID uint) ([]models.InvoiceItem, error) {
+	var items []models.InvoiceItem
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND invoice_id IS NULL AND kind = ?", orgID, models.InvoiceItemKindCredit).
+		Order("created_at ASC, id ASC").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// ListDueSubscriptions lists active subscriptions whose current period has
+// started and not been billed. Trials are not billed, and periods that are
+// over are left for renewal to move on first.
+func (r *invoiceRepository) ListDueSubscriptions(ctx context.Context, now time.Time) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	err := r.db.WithContext(ctx).
+		Preload("Plan").
+		Where("status = ?", models.SubscriptionStatusActive).
+		Where("current_period_start <= ? AND end_date > ?", now, now).
+		Where("last_billed_at IS NULL OR last_billed_at < current_period_start").
+		Where("trial_ends_at IS NULL OR trial_ends_at <= ?", now).
+		Order("current_period_start ASC, id ASC").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// ListOverdue lists the invoices of active subscriptions that are overdue, as
+// Invoice.IsOverdue decides, grouped by subscription and oldest first
+func (r *invoiceRepository) ListOverdue(ctx context.Context, now time.Time) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.WithContext(ctx).
+		Where(models.OverdueInvoices(now)).
+		Where("EXISTS (SELECT 1 FROM subscriptions s WHERE s.id = invoices.subscription_id AND s.status = ? AND s.deleted_at IS NULL)",
+			models.SubscriptionStatusActive).
+		Order("subscription_id ASC, due_date ASC, id ASC").
+		Find(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+// SaveDunning stores how far the dunning of an invoice has gone, leaving its
+// payment status to the payment flow
+func (r *invoiceRepository) SaveDunning(ctx context.Context, invoice *models.Invoice) error {
+	return r.db.WithContext(ctx).
+		Model(invoice).
+		UpdateColumns(map[string]interface{}{
+			"dunning_retries": invoice.DunningRetries,
+			"reminders_sent":  invoice.RemindersSent,
+			"next_retry_at":   invoice.NextRetryAt,
+		}).Error
+}
+
+// MarkBilled records that the subscription's period starting at periodStart
+// has been billed, reporting false if it already was
+func (r *invoiceRepository) MarkBilled(ctx context.Context, subscriptionID uint, periodStart, at time.Time) (bool, error) {
+	return markBilled(r.db.WithContext(ctx), subscriptionID, periodStart, at)
+}
+
+// Issue stores an invoice for its subscription's period and marks the period
+// billed, all in one transaction. The invoice takes the next number in the
+// scope's sequence, so numbers have no gaps. Items of the invoice that are
+// already stored are pending items being billed, which take the invoice and
+// their tax; the others are created, as is the tax breakdown.
+// Carried items are created without an invoice, to be billed next time. Issue
+// reports false, storing nothing, if the period was already billed.
+func (r *invoiceRepository) Issue(ctx context.Context, invoice *models.Invoice, scope string, number func(seq int64) string, carried []models.InvoiceItem) (bool, error) {
+	issued := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		billed, err := markBilled(tx, invoice.SubscriptionID, invoice.PeriodStart, invoice.IssuedAt)
+		if err != nil || !billed {
+			return err
+		}
+
+		seq, err := nextSequenceNumber(tx, scope)
+		if err != nil {
+			return err
+		}
+		invoice.SequenceNumber = seq
+		invoice.InvoiceNumber = number(seq)
+
+		if err := tx.Omit(clause.Associations).Create(invoice).Error; err != nil {
+			return err
+		}
+
+		for i := range invoice.Items {
+			item := &invoice.Items[i]
+			item.InvoiceID = &invoice.ID
+			if item.ID == 0 {
+				if err := tx.Create(item).Error; err != nil {
+					return err
+				}
+				continue
+			}
+			result := tx.Model(&models.InvoiceItem{}).
+				Where("id = ? AND invoice_id IS NULL", item.ID).
+				UpdateColumns(map[string]interface{}{
+					"invoice_id":          invoice.ID,
+					"tax_rate":            item.TaxRate,
+					"tax_amount_minor":    item.TaxAmount.Minor,
+					"tax_amount_currency": item.TaxAmount.Currency,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected != 1 {
+				return errors.New("pending invoice items were billed on another invoice")
+			}
+		}
+		for i := range invoice.Taxes {
+			invoice.Taxes[i].InvoiceID = invoice.ID
+		}
+		if len(invoice.Taxes) > 0 {
+			if err := tx.Create(&invoice.Taxes).Error; err != nil {
+				return err
+			}
+		}
+		if len(carried) > 0 {
+			if err := tx.Create(&carried).Error; err != nil {
+				return err
+			}
+		}
+
+		issued = true
+		return nil
+	})
+	return issued, err
+}
+
+// nextSequenceNumber takes the next number in the scope's sequence. The
+// sequence row stays locked until the transaction ends, so documents in the
+// same scope are numbered one at a time, and a number is given back if the
+// transaction rolls back.
+func nextSequenceNumber(tx *gorm.DB, scope string) (int64, error) {
+	sequence := models.InvoiceSequence{Scope: scope, LastNumber: 1}
+	err := tx.Clauses(
+		clause.OnConflict{
+			Columns:   []clause.Column{{Name: "scope"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"last_number": gorm.Expr("invoice_sequences.last_number + 1")}),
+		},
+		clause.Returning{Columns: []clause.Column{{Name: "last_number"}}},
+	).Create(&sequence).Error
+	return sequence.LastNumber, err
+}
+
+// markBilled sets the subscription's last billing time unless the period
+// starting at periodStart has been billed already
+func markBilled(tx *gorm.DB, subscriptionID uint, periodStart, at time.Time) (bool, error) {
+	// UpdateColumn skips the subscription hooks, which validate and sync a
+	// whole subscription rather than one column
+	result := tx.Model(&models.Subscription{}).
+		Where("id = ? AND current_period_start = ?", subscriptionID, periodStart).
+		Where("last_billed_at IS NULL OR last_billed_at < current_period_start").
+		UpdateColumn("last_billed_at", at)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
@@ -0,0 +1,117 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// LabelRepository defines the interface for label data access
+type LabelRepository interface {
+	Create(ctx context.Context, label *models.Label) error
+	FindByID(ctx context.Context, id uint) (*models.Label, error)
+	FindByIDs(ctx context.Context, ids []uint) ([]models.Label, error)
+	Update(ctx context.Context, label *models.Label) error
+	Delete(ctx context.Context, id uint) error
+	ListByOrganization(ctx context.Context, orgID uint) ([]models.Label, error)
+	FindByNames(ctx context.Context, orgID uint, names []string) ([]models.Label, error)
+	ReplaceTaskLabels(ctx context.Context, taskID uint, labelIDs []uint) error
+}
+
+// NewLabelRepository creates a new instance of LabelRepository
+func NewLabelRepository(db *gorm.DB) LabelRepository {
+	return &labelRepository{
+		db: db,
+	}
+}
+
+type labelRepository struct {
+	db *gorm.DB
+}
+
+func (r *labelRepository) Create(ctx context.Context, label *models.Label) error {
+	return r.db.WithContext(ctx).Create(label).Error
+}
+
+func (r *labelRepository) FindByID(ctx context.Context, id uint) (*models.Label, error) {
+	var label models.Label
+	if err := r.db.WithContext(ctx).First(&label, id).Error; err != nil {
+		return nil, err
+	}
+	return &label, nil
+}
+
+func (r *labelRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.Label, error) {
+	var labels []models.Label
+	if len(ids) == 0 {
+		return labels, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&labels).Error; err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (r *labelRepository) Update(ctx context.Context, label *models.Label) error {
+	return r.db.WithContext(ctx).Save(label).Error
+}
+
+func (r *labelRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("label_id = ?", id).Delete(&models.TaskLabel{}).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&models.Label{}, id).Error
+	})
+}
+
+func (r *labelRepository) ListByOrganization(ctx context.Context, orgID uint) ([]models.Label, error) {
+	var labels []models.Label
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ?", orgID).
+		Order("name ASC").
+		Find(&labels).Error
+	if err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (r *labelRepository) FindByNames(ctx context.Context, orgID uint, names []string) ([]models.Label, error) {
+	var labels []models.Label
+	if len(names) == 0 {
+		return labels, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND LOWER(name) IN ?", orgID, lowerAll(names)).
+		Find(&labels).Error
+	if err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (r *labelRepository) ReplaceTaskLabels(ctx context.Context, taskID uint, labelIDs []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("task_id = ?", taskID).Delete(&models.TaskLabel{}).Error; err != nil {
+			return err
+		}
+		for _, labelID := range labelIDs {
+			if err := tx.Create(&models.TaskLabel{TaskID: taskID, LabelID: labelID}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// lowerAll returns the strings converted to lower case
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(v)
+	}
+	return lowered
+}
@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NotificationFilter scopes a user's inbox
+type NotificationFilter struct {
+	UserID     uint
+	UnreadOnly bool
+	BeforeID   uint // cursor: only notifications with a lower ID are returned
+	Limit      int
+}
+
+// NotificationRepository defines the interface for notification and
+// notification preference data access. Most notifications are written by
+// model hooks, in the same transaction as the change they are about.
+type NotificationRepository interface {
+	List(ctx context.Context, filter NotificationFilter) ([]models.Notification, error)
+	CountUnread(ctx context.Context, userID uint) (int64, error)
+	FindByID(ctx context.Context, userID, id uint) (*models.Notification, error)
+	MarkRead(ctx context.Context, userID uint, ids []uint, at time.Time) (int64, error)
+	MarkUnread(ctx context.Context, userID, id uint) error
+	Notify(ctx context.Context, notification *models.Notification) error
+	ListUnemailed(ctx context.Context, limit int) ([]models.Notification, error)
+	QueueEmail(ctx context.Context, notificationID uint, email *models.OutboxEmail, at time.Time) error
+	ListDueSoon(ctx context.Context, now, until time.Time) ([]models.Task, error)
+	ListPreferences(ctx context.Context, userID uint) ([]models.NotificationPreference, error)
+	SavePreferences(ctx context.Context, preferences []models.NotificationPreference) error
+}
+
+// NewNotificationRepository creates a new instance of NotificationRepository
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{
+		db: db,
+	}
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+// List lists the notifications in the user's inbox, newest first
+func (r *notificationRepository) List(ctx context.Context, filter NotificationFilter) ([]models.Notification, error) {
+	query := r.db.WithContext(ctx).Where("user_id = ? AND in_app", filter.UserID)
+	if filter.UnreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	if filter.BeforeID != 0 {
+		query = query.Where("id < ?", filter.BeforeID)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	var notifications []models.Notification
+	if err := query.Order("id DESC").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("user_id = ? AND in_app AND read_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) FindByID(ctx context.Context, userID, id uint) (*models.Notification, error) {
+	var notification models.Notification
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND in_app", userID).
+		First(&notification, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// MarkRead marks unread notifications in the user's inbox read, either those
+// with the given IDs or, when there are none, all of them. It returns the
+// number marked.
+func (r *notificationRepository) MarkRead(ctx context.Context, userID uint, ids []uint, at time.Time) (int64, error) {
+	query := r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("user_id = ? AND in_app AND read_at IS NULL", userID)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	result := query.Update("read_at", at)
+	return result.RowsAffected, result.Error
+}
+
+func (r *notificationRepository) MarkUnread(ctx context.Context, userID, id uint) error {
+	return r.db.WithContext(ctx).
+		Model(&models.Notification{}).
+		Where("id = ? AND user_id = ?", id, userID).
+		Update("read_at", nil).Error
+}
+
+// Notify stores a notification according to its user's preferences. A
+// notification with a dedupe key that was stored before is left with no ID.
+func (r *notificationRepository) Notify(ctx context.Context, notification *models.Notification) error {
+	return models.Notify(r.db.WithContext(ctx), notification)
+}
+
+// ListUnemailed lists the notifications to be sent by email that are not yet
+// in the outbox, oldest first
+func (r *notificationRepository) ListUnemailed(ctx context.Context, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+	err := r.db.WithContext(ctx).
+		Where("email AND email_queued_at IS NULL").
+		Order("id ASC").
+		Limit(limit).
+		Find(&notifications).Error
+	if err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// QueueEmail puts the email for a notification in the outbox, unless it was
+// put there already. A nil email marks the notification as dealt with
+// without sending anything.
+func (r *notificationRepository) QueueEmail(ctx context.Context, notificationID uint, email *models.OutboxEmail, at time.Time) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Notification{}).
+			Where("id = ? AND email_queued_at IS NULL", notificationID).
+			Update("email_queued_at", at)
+		if result.Error != nil || result.RowsAffected == 0 || email == nil {
+			return result.Error
+		}
+		return tx.Create(email).Error
+	})
+}
+
+// ListDueSoon lists the unfinished, assigned tasks due between now and until
+func (r *notificationRepository) ListDueSoon(ctx context.Context, now, until time.Time) ([]models.Task, error) {
+	var tasks []models.Task
+	err := r.db.WithContext(ctx).
+		Where("assignee_id IS NOT NULL AND status <> ?", models.TaskStatusDone).
+		Where("due_date > ? AND due_date <= ?", now, until).
+		Order("due_date ASC, id ASC").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *notificationRepository) ListPreferences(ctx context.Context, userID uint) ([]models.NotificationPreference, error) {
+	var preferences []models.NotificationPreference
+	if err := r.db.WithContext(ctx).Where("user_id = ?", userID).Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// SavePreferences creates or replaces preferences, all or none of them
+func (r *notificationRepository) SavePreferences(ctx context.Context, preferences []models.NotificationPreference) error {
+	if len(preferences) == 0 {
+		return nil
+	}
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "type"}, {Name: "channel"}},
+			DoUpdates: clause.AssignmentColumns([]string{"enabled", "updated_at"}),
+		}).
+		Create(&preferences).Error
+}
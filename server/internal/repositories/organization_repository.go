@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// OrganizationRepository defines the interface for organization data access
+type OrganizationRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.Organization, error)
+	IsMember(ctx context.Context, orgID, userID uint) (bool, error)
+	FindMember(ctx context.Context, orgID, userID uint) (*models.OrganizationUser, error)
+	IsReadOnly(ctx context.Context, orgID uint) (bool, error)
+	ListAdmins(ctx context.Context, orgID uint) ([]models.User, error)
+	UpdateBillingDetails(ctx context.Context, org *models.Organization) error
+}
+
+// NewOrganizationRepository creates a new instance of OrganizationRepository
+func NewOrganizationRepository(db *gorm.DB) OrganizationRepository {
+	return &organizationRepository{
+		db: db,
+	}
+}
+
+type organizationRepository struct {
+	db *gorm.DB
+}
+
+func (r *organizationRepository) FindByID(ctx context.Context, id uint) (*models.Organization, error) {
+	var org models.Organization
+	if err := r.db.WithContext(ctx).First(&org, id).Error; err != nil {
+		return nil, err
+	}
+	return &org, nil
+}
+
+// UpdateBillingDetails stores the organization's billing details, leaving its
+// other fields alone
+func (r *organizationRepository) UpdateBillingDetails(ctx context.Context, org *models.Organization) error {
+	return r.db.WithContext(ctx).
+		Model(org).
+		Select("billing_name", "billing_email", "billing_address", "billing_country", "tax_id").
+		Updates(org).Error
+}
+
+func (r *organizationRepository) IsMember(ctx context.Context, orgID, userID uint) (bool, error) {
+	_, err := r.FindMember(ctx, orgID, userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+func (r *organizationRepository) FindMember(ctx context.Context, orgID, userID uint) (*models.OrganizationUser, error) {
+	var member models.OrganizationUser
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ?", orgID, userID).
+		First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
+
+func (r *organizationRepository) IsReadOnly(ctx context.Context, orgID uint) (bool, error) {
+	var readOnly []bool
+	err := r.db.WithContext(ctx).
+		Model(&models.Organization{}).
+		Where("id = ?", orgID).
+		Pluck("read_only", &readOnly).Error
+	if err != nil {
+		return false, err
+	}
+	return len(readOnly) > 0 && readOnly[0], nil
+}
+
+// ListAdmins lists the organization's admins
+func (r *organizationRepository) ListAdmins(ctx context.Context, orgID uint) ([]models.User, error) {
+	var admins []models.User
+	err := r.db.WithContext(ctx).
+		Joins("JOIN organization_users ON organization_users.user_id = users.id").
+		Where("organization_users.organization_id = ? AND organization_users.role = ?", orgID, "admin").
+		Order("users.id").
+		Find(&admins).Error
+	if err != nil {
+		return nil, err
+	}
+	return admins, nil
+}
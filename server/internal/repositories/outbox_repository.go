@@ -0,0 +1,59 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// OutboxRepository defines the interface for email outbox data access
+type OutboxRepository interface {
+	Enqueue(ctx context.Context, email *models.OutboxEmail) error
+	ListDue(ctx context.Context, now time.Time, limit int) ([]models.OutboxEmail, error)
+	Save(ctx context.Context, email *models.OutboxEmail) error
+	DeleteSentBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// NewOutboxRepository creates a new instance of OutboxRepository
+func NewOutboxRepository(db *gorm.DB) OutboxRepository {
+	return &outboxRepository{
+		db: db,
+	}
+}
+
+type outboxRepository struct {
+	db *gorm.DB
+}
+
+func (r *outboxRepository) Enqueue(ctx context.Context, email *models.OutboxEmail) error {
+	return r.db.WithContext(ctx).Create(email).Error
+}
+
+// ListDue lists the pending emails whose next attempt is due, oldest first
+func (r *outboxRepository) ListDue(ctx context.Context, now time.Time, limit int) ([]models.OutboxEmail, error) {
+	var emails []models.OutboxEmail
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", models.OutboxStatusPending, now).
+		Order("next_attempt_at ASC, id ASC").
+		Limit(limit).
+		Find(&emails).Error
+	if err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+func (r *outboxRepository) Save(ctx context.Context, email *models.OutboxEmail) error {
+	return r.db.WithContext(ctx).Save(email).Error
+}
+
+// DeleteSentBefore deletes the emails sent before a time and returns the
+// number deleted
+func (r *outboxRepository) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Where("status = ? AND sent_at < ?", models.OutboxStatusSent, before).
+		Delete(&models.OutboxEmail{})
+	return result.RowsAffected, result.Error
+}
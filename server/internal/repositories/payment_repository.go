@@ -0,0 +1,227 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PaymentRepository defines the interface for payment profile, transaction
+// and provider event data access
+type PaymentRepository interface {
+	FindProfile(ctx context.Context, orgID uint) (*models.PaymentProfile, error)
+	SaveProfile(ctx context.Context, profile *models.PaymentProfile) error
+	CreateTransaction(ctx context.Context, transaction *models.PaymentTransaction) error
+	CompleteTransaction(ctx context.Context, transaction *models.PaymentTransaction, invoice *models.Invoice) error
+	ListTransactions(ctx context.Context, invoiceID uint) ([]models.PaymentTransaction, error)
+	CountAttempts(ctx context.Context, invoiceID uint) (int64, error)
+	FindLastAttempt(ctx context.Context, invoiceID uint) (*models.PaymentTransaction, error)
+	ListUncollected(ctx context.Context) ([]models.Invoice, error)
+	FindTransaction(ctx context.Context, provider, providerID string) (*models.PaymentTransaction, error)
+	FindInvoiceByPayment(ctx context.Context, paymentID string) (*models.Invoice, error)
+	CreateEvent(ctx context.Context, event *models.PaymentEvent) (bool, error)
+	FindEvent(ctx context.Context, id uint) (*models.PaymentEvent, error)
+	SaveEvent(ctx context.Context, event *models.PaymentEvent) error
+	ListEvents(ctx context.Context, status models.PaymentEventStatus, limit int) ([]models.PaymentEvent, error)
+	ListRetryableEvents(ctx context.Context, before time.Time, maxAttempts int) ([]models.PaymentEvent, error)
+	ApplyEvent(ctx context.Context, event *models.PaymentEvent, invoice *models.Invoice, transaction *models.PaymentTransaction, subscription *models.Subscription) error
+}
+
+// NewPaymentRepository creates a new instance of PaymentRepository
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{
+		db: db,
+	}
+}
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func (r *paymentRepository) FindProfile(ctx context.Context, orgID uint) (*models.PaymentProfile, error) {
+	var profile models.PaymentProfile
+	if err := r.db.WithContext(ctx).Where("organization_id = ?", orgID).First(&profile).Error; err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *paymentRepository) SaveProfile(ctx context.Context, profile *models.PaymentProfile) error {
+	return r.db.WithContext(ctx).Save(profile).Error
+}
+
+// CreateTransaction records an attempt before the provider is called
+func (r *paymentRepository) CreateTransaction(ctx context.Context, transaction *models.PaymentTransaction) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(transaction).Error
+}
+
+// CompleteTransaction stores the outcome of an attempt together with the
+// invoice status it leads to
+func (r *paymentRepository) CompleteTransaction(ctx context.Context, transaction *models.PaymentTransaction, invoice *models.Invoice) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(transaction).Error; err != nil {
+			return err
+		}
+		return tx.Omit(clause.Associations).Save(invoice).Error
+	})
+}
+
+func (r *paymentRepository) ListTransactions(ctx context.Context, invoiceID uint) ([]models.PaymentTransaction, error) {
+	var transactions []models.PaymentTransaction
+	err := r.db.WithContext(ctx).
+		Where("invoice_id = ?", invoiceID).
+		Order("created_at ASC, id ASC").
+		Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// CountAttempts counts the charges attempted for the invoice
+func (r *paymentRepository) CountAttempts(ctx context.Context, invoiceID uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.PaymentTransaction{}).
+		Where("invoice_id = ? AND kind = ?", invoiceID, models.TransactionKindCharge).
+		Count(&count).Error
+	return count, err
+}
+
+// FindLastAttempt finds the most recent charge attempted for the invoice
+func (r *paymentRepository) FindLastAttempt(ctx context.Context, invoiceID uint) (*models.PaymentTransaction, error) {
+	var transaction models.PaymentTransaction
+	err := r.db.WithContext(ctx).
+		Where("invoice_id = ? AND kind = ?", invoiceID, models.TransactionKindCharge).
+		Order("id DESC").
+		First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// ListUncollected lists pending invoices that have not been charged yet and
+// belong to organizations with a payment method
+func (r *paymentRepository) ListUncollected(ctx context.Context) ([]models.Invoice, error) {
+	var invoices []models.Invoice
+	err := r.db.WithContext(ctx).
+		Where("status = ? AND amount_minor > 0", models.PaymentStatusPending).
+		Where("NOT EXISTS (SELECT 1 FROM payment_transactions t WHERE t.invoice_id = invoices.id AND t.deleted_at IS NULL)").
+		Where("EXISTS (SELECT 1 FROM payment_profiles p WHERE p.organization_id = invoices.organization_id AND p.payment_method_id <> '')").
+		Order("issued_at ASC, id ASC").
+		Find(&invoices).Error
+	if err != nil {
+		return nil, err
+	}
+	return invoices, nil
+}
+
+func (r *paymentRepository) FindTransaction(ctx context.Context, provider, providerID string) (*models.PaymentTransaction, error) {
+	var transaction models.PaymentTransaction
+	err := r.db.WithContext(ctx).
+		Where("provider = ? AND provider_id = ?", provider, providerID).
+		Order("id DESC").
+		First(&transaction).Error
+	if err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// FindInvoiceByPayment finds the invoice a provider payment was made for,
+// from the invoice itself or from the attempt that started the payment
+func (r *paymentRepository) FindInvoiceByPayment(ctx context.Context, paymentID string) (*models.Invoice, error) {
+	var invoice models.Invoice
+	err := r.db.WithContext(ctx).
+		Where("payment_id = ?", paymentID).
+		Or("id IN (SELECT invoice_id FROM payment_transactions WHERE provider_id = ? AND kind = ? AND deleted_at IS NULL)",
+			paymentID, models.TransactionKindCharge).
+		First(&invoice).Error
+	if err != nil {
+		return nil, err
+	}
+	return &invoice, nil
+}
+
+// CreateEvent stores an event unless one with the same provider event ID was
+// stored before, reporting whether it was created
+func (r *paymentRepository) CreateEvent(ctx context.Context, event *models.PaymentEvent) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(event)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *paymentRepository) FindEvent(ctx context.Context, id uint) (*models.PaymentEvent, error) {
+	var event models.PaymentEvent
+	if err := r.db.WithContext(ctx).First(&event, id).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *paymentRepository) SaveEvent(ctx context.Context, event *models.PaymentEvent) error {
+	return r.db.WithContext(ctx).Save(event).Error
+}
+
+// ListEvents lists the latest events, optionally only those with a status
+func (r *paymentRepository) ListEvents(ctx context.Context, status models.PaymentEventStatus, limit int) ([]models.PaymentEvent, error) {
+	query := r.db.WithContext(ctx)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var events []models.PaymentEvent
+	if err := query.Order("created_at DESC, id DESC").Limit(limit).Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ListRetryableEvents lists events last tried before the given time that
+// failed, or were left unprocessed, and have attempts left. They are listed
+// in the order they happened.
+func (r *paymentRepository) ListRetryableEvents(ctx context.Context, before time.Time, maxAttempts int) ([]models.PaymentEvent, error) {
+	var events []models.PaymentEvent
+	err := r.db.WithContext(ctx).
+		Where("status IN ?", []models.PaymentEventStatus{models.PaymentEventReceived, models.PaymentEventFailed}).
+		Where("updated_at < ? AND attempts < ?", before, maxAttempts).
+		Order("occurred_at ASC, id ASC").
+		Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// ApplyEvent stores the changes an event made, together with the event
+// itself. The invoice, transaction and subscription are optional.
+func (r *paymentRepository) ApplyEvent(ctx context.Context, event *models.PaymentEvent, invoice *models.Invoice, transaction *models.PaymentTransaction, subscription *models.Subscription) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The subscription goes first, so that saving a paid invoice can
+		// lift its dunning afterwards
+		if subscription != nil {
+			if err := tx.Omit(clause.Associations).Save(subscription).Error; err != nil {
+				return err
+			}
+		}
+		if transaction != nil {
+			if err := tx.Omit(clause.Associations).Save(transaction).Error; err != nil {
+				return err
+			}
+		}
+		if invoice != nil {
+			if err := tx.Omit(clause.Associations).Save(invoice).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Save(event).Error
+	})
+}
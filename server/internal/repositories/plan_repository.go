@@ -0,0 +1,85 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PlanRepository defines the interface for plan catalog data access
+type PlanRepository interface {
+	Create(ctx context.Context, plan *models.Plan) error
+	FindByID(ctx context.Context, id uint) (*models.Plan, error)
+	Update(ctx context.Context, plan *models.Plan) error
+	List(ctx context.Context, includeArchived bool) ([]models.Plan, error)
+}
+
+// NewPlanRepository creates a new instance of PlanRepository
+func NewPlanRepository(db *gorm.DB) PlanRepository {
+	return &planRepository{
+		db: db,
+	}
+}
+
+type planRepository struct {
+	db *gorm.DB
+}
+
+func (r *planRepository) Create(ctx context.Context, plan *models.Plan) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(plan).Error; err != nil {
+			return err
+		}
+		return createPlanFeatures(tx, plan)
+	})
+}
+
+func (r *planRepository) FindByID(ctx context.Context, id uint) (*models.Plan, error) {
+	var plan models.Plan
+	if err := r.db.WithContext(ctx).Preload("Features").First(&plan, id).Error; err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+// Update saves a plan and replaces its features with the plan's Features
+func (r *planRepository) Update(ctx context.Context, plan *models.Plan) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(plan).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("plan_id = ?", plan.ID).Delete(&models.PlanFeature{}).Error; err != nil {
+			return err
+		}
+		return createPlanFeatures(tx, plan)
+	})
+}
+
+func (r *planRepository) List(ctx context.Context, includeArchived bool) ([]models.Plan, error) {
+	query := r.db.WithContext(ctx).Preload("Features")
+	if !includeArchived {
+		query = query.Where("archived_at IS NULL")
+	}
+
+	var plans []models.Plan
+	if err := query.Order("price_minor ASC, id ASC").Find(&plans).Error; err != nil {
+		return nil, err
+	}
+	return plans, nil
+}
+
+// createPlanFeatures stores the plan's features. Included is always written,
+// since its column default would otherwise replace a false value.
+func createPlanFeatures(tx *gorm.DB, plan *models.Plan) error {
+	if len(plan.Features) == 0 {
+		return nil
+	}
+	for i := range plan.Features {
+		plan.Features[i].ID = 0
+		plan.Features[i].PlanID = plan.ID
+	}
+	return tx.Select("PlanID", "Name", "Description", "Included", "CreatedAt", "UpdatedAt").
+		Create(&plan.Features).Error
+}
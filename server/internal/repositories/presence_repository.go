@@ -0,0 +1,129 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PresenceViewer is a presence on a page together with the user's name
+type PresenceViewer struct {
+	models.Presence
+	FirstName string `json:"first_name"`
+	LastName  string `json:"last_name"`
+}
+
+// PresenceRepository defines the interface for presence data access
+type PresenceRepository interface {
+	Save(ctx context.Context, presence *models.Presence) error
+	Renew(ctx context.Context, connectionID string, expiresAt time.Time) (int64, error)
+	Leave(ctx context.Context, connectionID string, resource models.PresenceResource, resourceID uint) error
+	LeaveAll(ctx context.Context, connectionID string) error
+	DeleteExpired(ctx context.Context, now time.Time) (int, error)
+	ListViewers(ctx context.Context, resource models.PresenceResource, resourceID uint, now time.Time) ([]PresenceViewer, error)
+}
+
+// NewPresenceRepository creates a new instance of PresenceRepository
+func NewPresenceRepository(db *gorm.DB) PresenceRepository {
+	return &presenceRepository{
+		db: db,
+	}
+}
+
+type presenceRepository struct {
+	db *gorm.DB
+}
+
+// Save stores a connection's presence on a page, or replaces it. The
+// presence is kept from when the connection first arrived.
+func (r *presenceRepository) Save(ctx context.Context, presence *models.Presence) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "connection_id"}, {Name: "resource_type"}, {Name: "resource_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"activity", "activity_until", "expires_at"}),
+		}, clause.Returning{}).
+		Create(presence).Error
+}
+
+// Renew keeps the presences of a connection until a later time, and returns
+// how many it has
+func (r *presenceRepository) Renew(ctx context.Context, connectionID string, expiresAt time.Time) (int64, error) {
+	result := r.db.WithContext(ctx).
+		Model(&models.Presence{}).
+		Where("connection_id = ?", connectionID).
+		UpdateColumn("expires_at", expiresAt)
+	return result.RowsAffected, result.Error
+}
+
+// Leave removes a connection's presence on a page
+func (r *presenceRepository) Leave(ctx context.Context, connectionID string, resource models.PresenceResource, resourceID uint) error {
+	return r.delete(ctx, "connection_id = ? AND resource_type = ? AND resource_id = ?", connectionID, resource, resourceID)
+}
+
+// LeaveAll removes the presences of a connection that closed
+func (r *presenceRepository) LeaveAll(ctx context.Context, connectionID string) error {
+	return r.delete(ctx, "connection_id = ?", connectionID)
+}
+
+// DeleteExpired removes the presences no connection renewed in time, such as
+// those of a server that went away, and returns how many there were
+func (r *presenceRepository) DeleteExpired(ctx context.Context, now time.Time) (int, error) {
+	var count int
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var presences []models.Presence
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("expires_at <= ?", now).
+			Find(&presences).Error
+		if err != nil {
+			return err
+		}
+		for i := range presences {
+			if err := tx.Delete(&presences[i]).Error; err != nil {
+				return err
+			}
+		}
+		count = len(presences)
+		return nil
+	})
+	return count, err
+}
+
+// delete removes the presences matching a condition one at a time, so that
+// each departure is announced
+func (r *presenceRepository) delete(ctx context.Context, query string, args ...interface{}) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var presences []models.Presence
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(query, args...).
+			Find(&presences).Error
+		if err != nil {
+			return err
+		}
+		for i := range presences {
+			if err := tx.Delete(&presences[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// ListViewers lists the unexpired presences on a page, earliest arrival first
+func (r *presenceRepository) ListViewers(ctx context.Context, resource models.PresenceResource, resourceID uint, now time.Time) ([]PresenceViewer, error) {
+	var viewers []PresenceViewer
+	err := r.db.WithContext(ctx).
+		Table("presences").
+		Select("presences.*, users.first_name, users.last_name").
+		Joins("JOIN users ON users.id = presences.user_id AND users.deleted_at IS NULL").
+		Where("presences.resource_type = ? AND presences.resource_id = ?", resource, resourceID).
+		Where("presences.expires_at > ?", now).
+		Order("presences.created_at ASC, presences.user_id ASC").
+		Scan(&viewers).Error
+	if err != nil {
+		return nil, err
+	}
+	return viewers, nil
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// ProjectRepository defines the interface for project data access
+type ProjectRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.Project, error)
+	FindMember(ctx context.Context, projectID, userID uint) (*models.ProjectMember, error)
+}
+
+// NewProjectRepository creates a new instance of ProjectRepository
+func NewProjectRepository(db *gorm.DB) ProjectRepository {
+	return &projectRepository{
+		db: db,
+	}
+}
+
+type projectRepository struct {
+	db *gorm.DB
+}
+
+func (r *projectRepository) FindByID(ctx context.Context, id uint) (*models.Project, error) {
+	var project models.Project
+	if err := r.db.WithContext(ctx).First(&project, id).Error; err != nil {
+		return nil, err
+	}
+	return &project, nil
+}
+
+func (r *projectRepository) FindMember(ctx context.Context, projectID, userID uint) (*models.ProjectMember, error) {
+	var member models.ProjectMember
+	err := r.db.WithContext(ctx).
+		Where("project_id = ? AND user_id = ?", projectID, userID).
+		First(&member).Error
+	if err != nil {
+		return nil, err
+	}
+	return &member, nil
+}
@@ -0,0 +1,110 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SavedFilterRepository defines the interface for saved filter data access
+type SavedFilterRepository interface {
+	Create(ctx context.Context, filter *models.SavedFilter) error
+	FindByID(ctx context.Context, id uint) (*models.SavedFilter, error)
+	Update(ctx context.Context, filter *models.SavedFilter) error
+	Delete(ctx context.Context, id uint) error
+	ListVisible(ctx context.Context, orgID, userID uint) ([]models.SavedFilter, error)
+	FindSubscription(ctx context.Context, filterID, userID uint) (*models.FilterSubscription, error)
+	Subscribe(ctx context.Context, subscription *models.FilterSubscription) error
+	Unsubscribe(ctx context.Context, filterID, userID uint) error
+	ListSubscriptions(ctx context.Context, frequency models.SubscriptionFrequency) ([]models.FilterSubscription, error)
+}
+
+// NewSavedFilterRepository creates a new instance of SavedFilterRepository
+func NewSavedFilterRepository(db *gorm.DB) SavedFilterRepository {
+	return &savedFilterRepository{
+		db: db,
+	}
+}
+
+type savedFilterRepository struct {
+	db *gorm.DB
+}
+
+func (r *savedFilterRepository) Create(ctx context.Context, filter *models.SavedFilter) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(filter).Error
+}
+
+func (r *savedFilterRepository) FindByID(ctx context.Context, id uint) (*models.SavedFilter, error) {
+	var filter models.SavedFilter
+	if err := r.db.WithContext(ctx).First(&filter, id).Error; err != nil {
+		return nil, err
+	}
+	return &filter, nil
+}
+
+func (r *savedFilterRepository) Update(ctx context.Context, filter *models.SavedFilter) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(filter).Error
+}
+
+func (r *savedFilterRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("filter_id = ?", id).Delete(&models.FilterSubscription{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.SavedFilter{}, id).Error
+	})
+}
+
+func (r *savedFilterRepository) ListVisible(ctx context.Context, orgID, userID uint) ([]models.SavedFilter, error) {
+	var filters []models.SavedFilter
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND (owner_id = ? OR visibility = ?)", orgID, userID, models.FilterVisibilityOrganization).
+		Order("name ASC").
+		Find(&filters).Error
+	if err != nil {
+		return nil, err
+	}
+	return filters, nil
+}
+
+func (r *savedFilterRepository) FindSubscription(ctx context.Context, filterID, userID uint) (*models.FilterSubscription, error) {
+	var subscription models.FilterSubscription
+	err := r.db.WithContext(ctx).
+		Where("filter_id = ? AND user_id = ?", filterID, userID).
+		First(&subscription).Error
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *savedFilterRepository) Subscribe(ctx context.Context, subscription *models.FilterSubscription) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "filter_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"frequency"}),
+		}).
+		Create(subscription).Error
+}
+
+func (r *savedFilterRepository) Unsubscribe(ctx context.Context, filterID, userID uint) error {
+	return r.db.WithContext(ctx).
+		Where("filter_id = ? AND user_id = ?", filterID, userID).
+		Delete(&models.FilterSubscription{}).Error
+}
+
+func (r *savedFilterRepository) ListSubscriptions(ctx context.Context, frequency models.SubscriptionFrequency) ([]models.FilterSubscription, error) {
+	var subscriptions []models.FilterSubscription
+	err := r.db.WithContext(ctx).
+		Preload("Filter").
+		Joins("JOIN saved_filters ON saved_filters.id = filter_subscriptions.filter_id AND saved_filters.deleted_at IS NULL").
+		Where("filter_subscriptions.frequency = ?", frequency).
+		Order("filter_subscriptions.id ASC").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
@@ -0,0 +1,172 @@
+package repositories
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Kinds of search results
+const (
+	SearchTypeTask    = "task"
+	SearchTypeComment = "comment"
+	SearchTypeProject = "project"
+)
+
+// Snippet markers wrapped around matched words. They are control characters
+// so they cannot collide with user content and are replaced before display.
+const (
+	SnippetStart = "\x01"
+	SnippetStop  = "\x02"
+)
+
+const snippetOptions = "StartSel=\"\x01\", StopSel=\"\x02\", MaxWords=35, MinWords=15, MaxFragments=2, FragmentDelimiter=\" … \""
+
+// SearchQuery describes a full-text search within one organization
+type SearchQuery struct {
+	OrganizationID uint
+	ProjectID      *uint
+	Types          []string // task, comment or project; all when empty
+	Text           string
+	Config         string // text search configuration of the searching user
+	Limit          int
+	Offset         int
+}
+
+// SearchHit is a ranked search result
+type SearchHit struct {
+	Type      string    `json:"type"`
+	ID        uint      `json:"id"`
+	TaskID    *uint     `json:"task_id,omitempty"`
+	ProjectID uint      `json:"project_id"`
+	Title     string    `json:"title"`
+	Snippet   string    `json:"snippet"`
+	Rank      float64   `json:"rank"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// searchSources holds the ranked subquery for each kind of result. Each one
+// must be scoped to @org and, when filtering by project, to @project.
+var searchSources = map[string]struct {
+	query   string
+	project string
+}{
+	SearchTypeTask: {
+		query: `SELECT 'task' AS type, t.id, t.id AS task_id, t.project_id, t.title,
+			t.title || ' ' || coalesce(t.description, '') AS body,
+			ts_rank_cd(t.search_vector, q.query) AS rank, t.updated_at
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		CROSS JOIN q
+		WHERE p.organization_id = @org AND p.deleted_at IS NULL AND t.deleted_at IS NULL
+			AND t.search_vector @@ q.query`,
+		project: " AND t.project_id = @project",
+	},
+	SearchTypeComment: {
+		query: `SELECT 'comment' AS type, c.id, c.task_id, t.project_id, t.title,
+			c.content AS body,
+			ts_rank_cd(c.search_vector, q.query) AS rank, c.updated_at
+		FROM comments c
+		JOIN tasks t ON t.id = c.task_id
+		JOIN projects p ON p.id = t.project_id
+		CROSS JOIN q
+		WHERE p.organization_id = @org AND p.deleted_at IS NULL AND t.deleted_at IS NULL AND c.deleted_at IS NULL
+			AND c.search_vector @@ q.query`,
+		project: " AND t.project_id = @project",
+	},
+	SearchTypeProject: {
+		query: `SELECT 'project' AS type, p.id, NULL::bigint AS task_id, p.id AS project_id, p.name AS title,
+			p.name || ' ' || coalesce(p.description, '') AS body,
+			ts_rank_cd(p.search_vector, q.query) AS rank, p.updated_at
+		FROM projects p
+		CROSS JOIN q
+		WHERE p.organization_id = @org AND p.deleted_at IS NULL
+			AND p.search_vector @@ q.query`,
+		project: " AND p.id = @project",
+	},
+}
+
+// searchTypes is the order results of equal rank are merged in
+var searchTypes = []string{SearchTypeTask, SearchTypeComment, SearchTypeProject}
+
+// IsSearchType checks if a kind of result can be searched for
+func IsSearchType(kind string) bool {
+	_, ok := searchSources[kind]
+	return ok
+}
+
+// SearchRepository defines the interface for full-text search
+type SearchRepository interface {
+	Search(ctx context.Context, query SearchQuery) ([]SearchHit, error)
+}
+
+// NewSearchRepository creates a new instance of SearchRepository
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepository{
+		db: db,
+	}
+}
+
+type searchRepository struct {
+	db *gorm.DB
+}
+
+// Search ranks matching rows across the requested kinds. The query is parsed
+// with websearch syntax both stemmed in the user's language and unstemmed,
+// and snippets are only built for the returned page.
+func (r *searchRepository) Search(ctx context.Context, query SearchQuery) ([]SearchHit, error) {
+	types := query.Types
+	if len(types) == 0 {
+		types = searchTypes
+	}
+
+	var parts []string
+	for _, kind := range types {
+		source, ok := searchSources[kind]
+		if !ok {
+			continue
+		}
+		part := source.query
+		if query.ProjectID != nil {
+			part += source.project
+		}
+		parts = append(parts, part)
+	}
+
+	var hits []SearchHit
+	if len(parts) == 0 {
+		return hits, nil
+	}
+
+	args := map[string]interface{}{
+		"config":  query.Config,
+		"text":    query.Text,
+		"org":     query.OrganizationID,
+		"limit":   query.Limit,
+		"offset":  query.Offset,
+		"options": snippetOptions,
+	}
+	if query.ProjectID != nil {
+		args["project"] = *query.ProjectID
+	}
+
+	sql := `WITH q AS (
+		SELECT websearch_to_tsquery(CAST(@config AS regconfig), @text) || websearch_to_tsquery('simple', @text) AS query
+	),
+	hits AS (
+		` + strings.Join(parts, "\n\t\tUNION ALL\n\t\t") + `
+		ORDER BY rank DESC, updated_at DESC, id DESC
+		LIMIT @limit OFFSET @offset
+	)
+	SELECT hits.type, hits.id, hits.task_id, hits.project_id, hits.title, hits.rank, hits.updated_at,
+		ts_headline(CAST(@config AS regconfig), hits.body, q.query, @options) AS snippet
+	FROM hits CROSS JOIN q
+	ORDER BY hits.rank DESC, hits.updated_at DESC, hits.id DESC`
+
+	if err := r.db.WithContext(ctx).Raw(sql, args).Scan(&hits).Error; err != nil {
+		return nil, err
+	}
+	return hits, nil
+}
@@ -0,0 +1,260 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SprintRepository defines the interface for sprint data access
+type SprintRepository interface {
+	Create(ctx context.Context, sprint *models.Sprint) error
+	FindByID(ctx context.Context, id uint) (*models.Sprint, error)
+	Update(ctx context.Context, sprint *models.Sprint) error
+	Delete(ctx context.Context, id uint) error
+	ListByProject(ctx context.Context, projectID uint) ([]models.Sprint, error)
+	FindActiveByProject(ctx context.Context, projectID uint) (*models.Sprint, error)
+	ListCompletedByTeam(ctx context.Context, teamID uint, limit int) ([]models.Sprint, error)
+
+	// Scope management
+	ListScope(ctx context.Context, sprintID uint) ([]models.SprintTask, error)
+	AddTasks(ctx context.Context, sprint *models.Sprint, taskIDs []uint, committed bool) error
+	RemoveTask(ctx context.Context, sprintID, taskID uint) error
+	Start(ctx context.Context, sprint *models.Sprint) (bool, error)
+	Complete(ctx context.Context, sprint *models.Sprint, unfinishedTaskIDs []uint, next *models.Sprint) error
+}
+
+// NewSprintRepository creates a new instance of SprintRepository
+func NewSprintRepository(db *gorm.DB) SprintRepository {
+	return &sprintRepository{
+		db: db,
+	}
+}
+
+type sprintRepository struct {
+	db *gorm.DB
+}
+
+func (r *sprintRepository) Create(ctx context.Context, sprint *models.Sprint) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(sprint).Error
+}
+
+func (r *sprintRepository) FindByID(ctx context.Context, id uint) (*models.Sprint, error) {
+	var sprint models.Sprint
+	if err := r.db.WithContext(ctx).Preload("Tasks").First(&sprint, id).Error; err != nil {
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+func (r *sprintRepository) Update(ctx context.Context, sprint *models.Sprint) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(sprint).Error
+}
+
+func (r *sprintRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var taskIDs []uint
+		if err := tx.Model(&models.Task{}).Where("sprint_id = ?", id).Pluck("id", &taskIDs).Error; err != nil {
+			return err
+		}
+		if err := assignSprint(tx, taskIDs, nil); err != nil {
+			return err
+		}
+		if err := tx.Where("sprint_id = ?", id).Delete(&models.SprintTask{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Sprint{}, id).Error
+	})
+}
+
+func (r *sprintRepository) ListByProject(ctx context.Context, projectID uint) ([]models.Sprint, error) {
+	var sprints []models.Sprint
+	err := r.db.WithContext(ctx).
+		Where("project_id = ?", projectID).
+		Order("start_date ASC").
+		Find(&sprints).Error
+	if err != nil {
+		return nil, err
+	}
+	return sprints, nil
+}
+
+func (r *sprintRepository) FindActiveByProject(ctx context.Context, projectID uint) (*models.Sprint, error) {
+	var sprint models.Sprint
+	err := r.db.WithContext(ctx).
+		Where("project_id = ? AND status = ?", projectID, models.SprintStatusActive).
+		First(&sprint).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sprint, nil
+}
+
+func (r *sprintRepository) ListCompletedByTeam(ctx context.Context, teamID uint, limit int) ([]models.Sprint, error) {
+	var sprints []models.Sprint
+	query := r.db.WithContext(ctx).
+		Where("team_id = ? AND status = ?", teamID, models.SprintStatusCompleted).
+		Order("end_date DESC")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	if err := query.Find(&sprints).Error; err != nil {
+		return nil, err
+	}
+	return sprints, nil
+}
+
+func (r *sprintRepository) ListScope(ctx context.Context, sprintID uint) ([]models.SprintTask, error) {
+	var scope []models.SprintTask
+	err := r.db.WithContext(ctx).
+		Preload("Task").
+		Where("sprint_id = ?", sprintID).
+		Order("added_at ASC").
+		Find(&scope).Error
+	if err != nil {
+		return nil, err
+	}
+	return scope, nil
+}
+
+func (r *sprintRepository) AddTasks(ctx context.Context, sprint *models.Sprint, taskIDs []uint, committed bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		for _, taskID := range taskIDs {
+			if err := removeOpenScope(tx, taskID, now); err != nil {
+				return err
+			}
+			entry := &models.SprintTask{
+				SprintID:  sprint.ID,
+				TaskID:    taskID,
+				AddedAt:   now,
+				Committed: committed,
+			}
+			if err := tx.Create(entry).Error; err != nil {
+				return err
+			}
+		}
+		return assignSprint(tx, taskIDs, &sprint.ID)
+	})
+}
+
+func (r *sprintRepository) RemoveTask(ctx context.Context, sprintID, taskID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.SprintTask{}).
+			Where("sprint_id = ? AND task_id = ? AND removed_at IS NULL", sprintID, taskID).
+			Update("removed_at", time.Now()).Error
+		if err != nil {
+			return err
+		}
+		var taskIDs []uint
+		err = tx.Model(&models.Task{}).
+			Where("id = ? AND sprint_id = ?", taskID, sprintID).
+			Pluck("id", &taskIDs).Error
+		if err != nil {
+			return err
+		}
+		return assignSprint(tx, taskIDs, nil)
+	})
+}
+
+// Start saves a sprint being started and marks its scope committed, unless
+// another sprint of the project is active. It reports whether the sprint was
+// started. The project row is locked so that two sprints of a project cannot
+// be started together.
+func (r *sprintRepository) Start(ctx context.Context, sprint *models.Sprint) (bool, error) {
+	started := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var project models.Project
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id").
+			First(&project, sprint.ProjectID).Error
+		if err != nil {
+			return err
+		}
+
+		var active int64
+		err = tx.Model(&models.Sprint{}).
+			Where("project_id = ? AND status = ? AND id <> ?", sprint.ProjectID, models.SprintStatusActive, sprint.ID).
+			Count(&active).Error
+		if err != nil || active > 0 {
+			return err
+		}
+
+		if err := tx.Omit(clause.Associations).Save(sprint).Error; err != nil {
+			return err
+		}
+		started = true
+		return tx.Model(&models.SprintTask{}).
+			Where("sprint_id = ? AND removed_at IS NULL", sprint.ID).
+			Update("committed", true).Error
+	})
+	return started, err
+}
+
+func (r *sprintRepository) Complete(ctx context.Context, sprint *models.Sprint, unfinishedTaskIDs []uint, next *models.Sprint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(sprint).Error; err != nil {
+			return err
+		}
+		if len(unfinishedTaskIDs) == 0 {
+			return nil
+		}
+
+		err := tx.Model(&models.SprintTask{}).
+			Where("sprint_id = ? AND task_id IN ? AND removed_at IS NULL", sprint.ID, unfinishedTaskIDs).
+			Update("carried_over", true).Error
+		if err != nil {
+			return err
+		}
+
+		// Without a next sprint, unfinished work goes back to the backlog
+		if next == nil {
+			return assignSprint(tx, unfinishedTaskIDs, nil)
+		}
+
+		now := time.Now()
+		for _, taskID := range unfinishedTaskIDs {
+			entry := &models.SprintTask{
+				SprintID:          next.ID,
+				TaskID:            taskID,
+				AddedAt:           now,
+				Committed:         next.IsActive(),
+				CarriedOverFromID: &sprint.ID,
+			}
+			if err := tx.Create(entry).Error; err != nil {
+				return err
+			}
+		}
+		return assignSprint(tx, unfinishedTaskIDs, &next.ID)
+	})
+}
+
+// removeOpenScope closes the task's membership in any sprint that is not completed
+func removeOpenScope(tx *gorm.DB, taskID uint, at time.Time) error {
+	return tx.Model(&models.SprintTask{}).
+		Where("task_id = ? AND removed_at IS NULL", taskID).
+		Where("sprint_id IN (?)", tx.Model(&models.Sprint{}).Select("id").Where("status <> ?", models.SprintStatusCompleted)).
+		Update("removed_at", at).Error
+}
+
+// assignSprint moves tasks to a sprint, or to the backlog when sprintID is nil.
+// Tasks are saved individually so their change history is recorded.
+func assignSprint(tx *gorm.DB, taskIDs []uint, sprintID *uint) error {
+	if len(taskIDs) == 0 {
+		return nil
+	}
+	var tasks []models.Task
+	if err := tx.Where("id IN ?", taskIDs).Find(&tasks).Error; err != nil {
+		return err
+	}
+	for i := range tasks {
+		tasks[i].SprintID = sprintID
+		if err := tx.Omit(clause.Associations).Save(&tasks[i]).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
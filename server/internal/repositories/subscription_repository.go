@@ -0,0 +1,148 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// SubscriptionRepository defines the interface for subscription data access
+type SubscriptionRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.Subscription, error)
+	FindCurrent(ctx context.Context, orgID uint) (*models.Subscription, error)
+	FindScheduled(ctx context.Context, orgID uint) (*models.Subscription, error)
+	ListByOrganization(ctx context.Context, orgID uint) ([]models.Subscription, error)
+	HasTrialed(ctx context.Context, orgID uint) (bool, error)
+	Save(ctx context.Context, subscriptions ...*models.Subscription) error
+	SaveWithItems(ctx context.Context, items []models.InvoiceItem, subscriptions ...*models.Subscription) error
+	ListDueRenewals(ctx context.Context, now time.Time) ([]models.Subscription, error)
+	ListDueStarts(ctx context.Context, now time.Time) ([]models.Subscription, error)
+	ReconcileUsage(ctx context.Context) (int64, error)
+}
+
+// NewSubscriptionRepository creates a new instance of SubscriptionRepository
+func NewSubscriptionRepository(db *gorm.DB) SubscriptionRepository {
+	return &subscriptionRepository{
+		db: db,
+	}
+}
+
+type subscriptionRepository struct {
+	db *gorm.DB
+}
+
+func (r *subscriptionRepository) FindByID(ctx context.Context, id uint) (*models.Subscription, error) {
+	var subscription models.Subscription
+	if err := r.db.WithContext(ctx).Preload("Plan.Features").First(&subscription, id).Error; err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+// FindCurrent returns the organization's active or lapsed subscription
+func (r *subscriptionRepository) FindCurrent(ctx context.Context, orgID uint) (*models.Subscription, error) {
+	return r.findByStatus(ctx, orgID, models.SubscriptionStatusActive, models.SubscriptionStatusInactive)
+}
+
+// FindScheduled returns the subscription the organization will switch to at the end of its period
+func (r *subscriptionRepository) FindScheduled(ctx context.Context, orgID uint) (*models.Subscription, error) {
+	return r.findByStatus(ctx, orgID, models.SubscriptionStatusPending)
+}
+
+func (r *subscriptionRepository) findByStatus(ctx context.Context, orgID uint, statuses ...models.SubscriptionStatus) (*models.Subscription, error) {
+	var subscription models.Subscription
+	err := r.db.WithContext(ctx).
+		Preload("Plan.Features").
+		Where("organization_id = ? AND status IN ?", orgID, statuses).
+		Order("start_date DESC").
+		First(&subscription).Error
+	if err != nil {
+		return nil, err
+	}
+	return &subscription, nil
+}
+
+func (r *subscriptionRepository) ListByOrganization(ctx context.Context, orgID uint) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	err := r.db.WithContext(ctx).
+		Preload("Plan").
+		Where("organization_id = ?", orgID).
+		Order("start_date DESC, id DESC").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// HasTrialed reports whether the organization has ever had a trial
+func (r *subscriptionRepository) HasTrialed(ctx context.Context, orgID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Unscoped().
+		Model(&models.Subscription{}).
+		Where("organization_id = ? AND trial_ends_at IS NOT NULL", orgID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// Save stores the subscriptions in order in one transaction, so that one can
+// be cancelled before the subscription replacing it becomes current
+func (r *subscriptionRepository) Save(ctx context.Context, subscriptions ...*models.Subscription) error {
+	return r.SaveWithItems(ctx, nil, subscriptions...)
+}
+
+// SaveWithItems stores the subscriptions like Save, together with invoice
+// items they were charged or credited, which are billed on the next invoice
+func (r *subscriptionRepository) SaveWithItems(ctx context.Context, items []models.InvoiceItem, subscriptions ...*models.Subscription) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, subscription := range subscriptions {
+			if err := tx.Omit(clause.Associations).Save(subscription).Error; err != nil {
+				return err
+			}
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Create(&items).Error
+	})
+}
+
+// ListDueRenewals lists active subscriptions whose period has ended, either
+// to renew them or to end them
+func (r *subscriptionRepository) ListDueRenewals(ctx context.Context, now time.Time) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	err := r.db.WithContext(ctx).
+		Preload("Plan").
+		Where("status = ?", models.SubscriptionStatusActive).
+		Where("next_billing_at <= ? OR (next_billing_at IS NULL AND end_date <= ?)", now, now).
+		Order("end_date ASC").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// ListDueStarts lists scheduled subscriptions whose start date has come
+func (r *subscriptionRepository) ListDueStarts(ctx context.Context, now time.Time) ([]models.Subscription, error) {
+	var subscriptions []models.Subscription
+	err := r.db.WithContext(ctx).
+		Preload("Plan").
+		Where("status = ? AND start_date <= ?", models.SubscriptionStatusPending, now).
+		Order("start_date ASC").
+		Find(&subscriptions).Error
+	if err != nil {
+		return nil, err
+	}
+	return subscriptions, nil
+}
+
+// ReconcileUsage recounts every organization's usage, returning the number
+// of subscriptions whose counts had drifted
+func (r *subscriptionRepository) ReconcileUsage(ctx context.Context) (int64, error) {
+	return models.SyncUsage(r.db.WithContext(ctx))
+}
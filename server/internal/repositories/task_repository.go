@@ -0,0 +1,319 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CustomFieldFilter restricts tasks by the value of a custom field
+type CustomFieldFilter struct {
+	Field    *models.CustomFieldDefinition
+	Operator string // eq, ne, lt, lte, gt, gte or contains
+	Value    interface{}
+}
+
+// TaskSort orders tasks by a task column or, when Field is set, a custom field
+type TaskSort struct {
+	Column string
+	Field  *models.CustomFieldDefinition
+	Desc   bool
+}
+
+// TaskListOptions describes a filtered, sorted and paginated task listing
+type TaskListOptions struct {
+	ProjectID      uint
+	OrganizationID uint // scopes the listing to all projects of an organization when ProjectID is 0
+	Statuses       []models.TaskStatus
+	Priorities     []models.TaskPriority
+	AssigneeID     *uint
+	LabelIDs       []uint // tasks carrying any of these labels
+	CustomFields   []CustomFieldFilter
+	Conditions     []clause.Expression // compiled task queries
+	Sort           []TaskSort
+	Limit          int
+	Offset         int
+}
+
+// sortableTaskColumns are the task columns tasks can be ordered by
+var sortableTaskColumns = map[string]bool{
+	"id":              true,
+	"title":           true,
+	"status":          true,
+	"priority":        true,
+	"due_date":        true,
+	"created_at":      true,
+	"updated_at":      true,
+	"estimated_hours": true,
+	"story_points":    true,
+}
+
+// TaskGroupSummary aggregates the tasks sharing a value of the grouping column
+type TaskGroupSummary struct {
+	Key            *string `json:"key"`
+	Count          int64   `json:"count"`
+	StoryPoints    float64 `json:"story_points"`
+	EstimatedHours float64 `json:"estimated_hours"`
+	ActualHours    float64 `json:"actual_hours"`
+}
+
+// groupableTaskColumns are the task columns summaries can be grouped by
+var groupableTaskColumns = map[string]bool{
+	"status":      true,
+	"priority":    true,
+	"assignee_id": true,
+	"sprint_id":   true,
+}
+
+// IsGroupableTaskColumn checks if task summaries can be grouped by the given column
+func IsGroupableTaskColumn(column string) bool {
+	return groupableTaskColumns[column]
+}
+
+// IsSortableTaskColumn checks if tasks can be ordered by the given column
+func IsSortableTaskColumn(column string) bool {
+	return sortableTaskColumns[column]
+}
+
+var customFieldOperators = map[string]string{
+	"eq":  "=",
+	"ne":  "<>",
+	"lt":  "<",
+	"lte": "<=",
+	"gt":  ">",
+	"gte": ">=",
+}
+
+// TaskRepository defines the interface for task data access
+type TaskRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.Task, error)
+	List(ctx context.Context, opts TaskListOptions) ([]models.Task, int64, error)
+	Summarize(ctx context.Context, opts TaskListOptions, groupBy string) ([]TaskGroupSummary, error)
+	FindByIDs(ctx context.Context, ids []uint) ([]models.Task, error)
+	Update(ctx context.Context, task *models.Task) error
+	FindStatusChanges(ctx context.Context, taskIDs []uint, until time.Time) ([]models.TaskStatusChange, error)
+	Watch(ctx context.Context, taskID, userID uint) error
+	Unwatch(ctx context.Context, taskID, userID uint) error
+	IsWatching(ctx context.Context, taskID, userID uint) (bool, error)
+}
+
+// NewTaskRepository creates a new instance of TaskRepository
+func NewTaskRepository(db *gorm.DB) TaskRepository {
+	return &taskRepository{
+		db: db,
+	}
+}
+
+type taskRepository struct {
+	db *gorm.DB
+}
+
+func (r *taskRepository) FindByID(ctx context.Context, id uint) (*models.Task, error) {
+	var task models.Task
+	err := r.db.WithContext(ctx).
+		Preload("Labels").
+		Preload("CustomFields").
+		First(&task, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (r *taskRepository) List(ctx context.Context, opts TaskListOptions) ([]models.Task, int64, error) {
+	query, err := r.filtered(ctx, opts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	if err := query.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	for i, sort := range opts.Sort {
+		if sort.Field == nil {
+			if !sortableTaskColumns[sort.Column] {
+				return nil, 0, fmt.Errorf("cannot sort tasks by %q", sort.Column)
+			}
+			query = query.Order(clause.OrderByColumn{Column: clause.Column{Table: "tasks", Name: sort.Column}, Desc: sort.Desc})
+			continue
+		}
+
+		alias := fmt.Sprintf("cf_sort_%d", i)
+		query = query.Joins(
+			fmt.Sprintf("LEFT JOIN custom_field_values AS %s ON %s.task_id = tasks.id AND %s.field_id = ?", alias, alias, alias),
+			sort.Field.ID,
+		)
+		direction := "ASC"
+		if sort.Desc {
+			direction = "DESC"
+		}
+		query = query.Order(fmt.Sprintf("%s.%s %s NULLS LAST", alias, sort.Field.ValueColumn(), direction))
+	}
+	query = query.Order("tasks.id ASC")
+
+	if opts.Limit > 0 {
+		query = query.Limit(opts.Limit)
+	}
+	if opts.Offset > 0 {
+		query = query.Offset(opts.Offset)
+	}
+
+	var tasks []models.Task
+	err = query.
+		Preload("Labels").
+		Preload("CustomFields").
+		Preload("Assignee").
+		Find(&tasks).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return tasks, total, nil
+}
+
+func (r *taskRepository) Summarize(ctx context.Context, opts TaskListOptions, groupBy string) ([]TaskGroupSummary, error) {
+	if !groupableTaskColumns[groupBy] {
+		return nil, fmt.Errorf("cannot group tasks by %q", groupBy)
+	}
+
+	query, err := r.filtered(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	column := "tasks." + groupBy
+	var summaries []TaskGroupSummary
+	err = query.
+		Select(fmt.Sprintf(
+			"CAST(%s AS TEXT) AS key, COUNT(*) AS count, "+
+				"COALESCE(SUM(tasks.story_points), 0) AS story_points, "+
+				"COALESCE(SUM(tasks.estimated_hours), 0) AS estimated_hours, "+
+				"COALESCE(SUM(tasks.actual_hours), 0) AS actual_hours",
+			column,
+		)).
+		Group(column).
+		Order(column + " ASC NULLS LAST").
+		Scan(&summaries).Error
+	if err != nil {
+		return nil, err
+	}
+	return summaries, nil
+}
+
+// filtered builds the task query for the filters in opts
+func (r *taskRepository) filtered(ctx context.Context, opts TaskListOptions) (*gorm.DB, error) {
+	query := r.db.WithContext(ctx).Model(&models.Task{})
+	switch {
+	case opts.ProjectID != 0:
+		query = query.Where("tasks.project_id = ?", opts.ProjectID)
+	case opts.OrganizationID != 0:
+		query = query.Where("tasks.project_id IN (SELECT id FROM projects WHERE organization_id = ? AND deleted_at IS NULL)", opts.OrganizationID)
+	default:
+		return nil, errors.New("task listing needs a project or organization scope")
+	}
+
+	if len(opts.Statuses) > 0 {
+		query = query.Where("tasks.status IN ?", opts.Statuses)
+	}
+	if len(opts.Priorities) > 0 {
+		query = query.Where("tasks.priority IN ?", opts.Priorities)
+	}
+	if opts.AssigneeID != nil {
+		query = query.Where("tasks.assignee_id = ?", *opts.AssigneeID)
+	}
+	if len(opts.LabelIDs) > 0 {
+		query = query.Where("EXISTS (SELECT 1 FROM task_labels WHERE task_labels.task_id = tasks.id AND task_labels.label_id IN ?)", opts.LabelIDs)
+	}
+
+	for _, condition := range opts.Conditions {
+		query = query.Where(condition)
+	}
+
+	for _, filter := range opts.CustomFields {
+		column := filter.Field.ValueColumn()
+		if filter.Operator == "contains" {
+			if column != "option_values" {
+				return nil, fmt.Errorf("operator contains is not supported for %s fields", filter.Field.Type)
+			}
+			value, err := json.Marshal(filter.Value)
+			if err != nil {
+				return nil, err
+			}
+			query = query.Where(
+				"EXISTS (SELECT 1 FROM custom_field_values cfv WHERE cfv.task_id = tasks.id AND cfv.field_id = ? AND cfv.option_values @> ?::jsonb)",
+				filter.Field.ID, string(value),
+			)
+			continue
+		}
+
+		op, ok := customFieldOperators[filter.Operator]
+		if !ok || column == "option_values" {
+			return nil, fmt.Errorf("operator %s is not supported for %s fields", filter.Operator, filter.Field.Type)
+		}
+		query = query.Where(
+			fmt.Sprintf("EXISTS (SELECT 1 FROM custom_field_values cfv WHERE cfv.task_id = tasks.id AND cfv.field_id = ? AND cfv.%s %s ?)", column, op),
+			filter.Field.ID, filter.Value,
+		)
+	}
+
+	return query, nil
+}
+
+func (r *taskRepository) FindByIDs(ctx context.Context, ids []uint) ([]models.Task, error) {
+	var tasks []models.Task
+	if len(ids) == 0 {
+		return tasks, nil
+	}
+	if err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&tasks).Error; err != nil {
+		return nil, err
+	}
+	return tasks, nil
+}
+
+func (r *taskRepository) Update(ctx context.Context, task *models.Task) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(task).Error
+}
+
+func (r *taskRepository) FindStatusChanges(ctx context.Context, taskIDs []uint, until time.Time) ([]models.TaskStatusChange, error) {
+	var changes []models.TaskStatusChange
+	if len(taskIDs) == 0 {
+		return changes, nil
+	}
+	err := r.db.WithContext(ctx).
+		Where("task_id IN ? AND changed_at <= ?", taskIDs, until).
+		Order("changed_at ASC, id ASC").
+		Find(&changes).Error
+	if err != nil {
+		return nil, err
+	}
+	return changes, nil
+}
+
+// Watch makes the user watch the task. Watching a task twice is not an error.
+func (r *taskRepository) Watch(ctx context.Context, taskID, userID uint) error {
+	return r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.TaskWatcher{TaskID: taskID, UserID: userID}).Error
+}
+
+func (r *taskRepository) Unwatch(ctx context.Context, taskID, userID uint) error {
+	return r.db.WithContext(ctx).
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Delete(&models.TaskWatcher{}).Error
+}
+
+func (r *taskRepository) IsWatching(ctx context.Context, taskID, userID uint) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&models.TaskWatcher{}).
+		Where("task_id = ? AND user_id = ?", taskID, userID).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+)
+
+// TeamRepository defines the interface for team data access
+type TeamRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.Team, error)
+}
+
+// NewTeamRepository creates a new instance of TeamRepository
+func NewTeamRepository(db *gorm.DB) TeamRepository {
+	return &teamRepository{
+		db: db,
+	}
+}
+
+type teamRepository struct {
+	db *gorm.DB
+}
+
+func (r *teamRepository) FindByID(ctx context.Context, id uint) (*models.Team, error) {
+	var team models.Team
+	if err := r.db.WithContext(ctx).First(&team, id).Error; err != nil {
+		return nil, err
+	}
+	return &team, nil
+}
@@ -0,0 +1,168 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TimeEntryFilter narrows time entry listings. Zero values are ignored; From
+// and To bound the start of the entry, To being exclusive.
+type TimeEntryFilter struct {
+	OrganizationID uint
+	ProjectID      uint
+	TaskID         uint
+	UserID         uint
+	From           time.Time
+	To             time.Time
+	FinishedOnly   bool
+}
+
+// TimeEntryExportRow is a finished time entry with the names needed for payroll exports
+type TimeEntryExportRow struct {
+	EntryID         uint
+	UserID          uint
+	UserEmail       string
+	UserFirstName   string
+	UserLastName    string
+	ProjectID       uint
+	ProjectName     string
+	TaskID          uint
+	TaskTitle       string
+	StartedAt       time.Time
+	EndedAt         time.Time
+	Duration        int64
+	Billable        bool
+	Notes           string
+	TimesheetStatus *string
+}
+
+// TimeEntryRepository defines the interface for time entry data access
+type TimeEntryRepository interface {
+	Create(ctx context.Context, entry *models.TimeEntry) error
+	FindByID(ctx context.Context, id uint) (*models.TimeEntry, error)
+	FindRunning(ctx context.Context, userID uint) (*models.TimeEntry, error)
+	StartTimer(ctx context.Context, running, entry *models.TimeEntry) error
+	Update(ctx context.Context, entry *models.TimeEntry) error
+	Delete(ctx context.Context, entry *models.TimeEntry) error
+	List(ctx context.Context, filter TimeEntryFilter) ([]models.TimeEntry, error)
+	Export(ctx context.Context, filter TimeEntryFilter) ([]TimeEntryExportRow, error)
+}
+
+// NewTimeEntryRepository creates a new instance of TimeEntryRepository
+func NewTimeEntryRepository(db *gorm.DB) TimeEntryRepository {
+	return &timeEntryRepository{
+		db: db,
+	}
+}
+
+type timeEntryRepository struct {
+	db *gorm.DB
+}
+
+func (r *timeEntryRepository) Create(ctx context.Context, entry *models.TimeEntry) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Create(entry).Error
+}
+
+func (r *timeEntryRepository) FindByID(ctx context.Context, id uint) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	if err := r.db.WithContext(ctx).First(&entry, id).Error; err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+func (r *timeEntryRepository) FindRunning(ctx context.Context, userID uint) (*models.TimeEntry, error) {
+	var entry models.TimeEntry
+	err := r.db.WithContext(ctx).
+		Where("user_id = ? AND ended_at IS NULL", userID).
+		First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// StartTimer stops the running timer, if any, and starts entry in one transaction
+func (r *timeEntryRepository) StartTimer(ctx context.Context, running, entry *models.TimeEntry) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if running != nil {
+			if err := tx.Omit(clause.Associations).Save(running).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Omit(clause.Associations).Create(entry).Error
+	})
+}
+
+func (r *timeEntryRepository) Update(ctx context.Context, entry *models.TimeEntry) error {
+	return r.db.WithContext(ctx).Omit(clause.Associations).Save(entry).Error
+}
+
+func (r *timeEntryRepository) Delete(ctx context.Context, entry *models.TimeEntry) error {
+	return r.db.WithContext(ctx).Delete(entry).Error
+}
+
+func (r *timeEntryRepository) List(ctx context.Context, filter TimeEntryFilter) ([]models.TimeEntry, error) {
+	var entries []models.TimeEntry
+	err := r.filtered(ctx, filter).
+		Order("time_entries.started_at ASC, time_entries.id ASC").
+		Find(&entries).Error
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (r *timeEntryRepository) Export(ctx context.Context, filter TimeEntryFilter) ([]TimeEntryExportRow, error) {
+	filter.FinishedOnly = true
+
+	var rows []TimeEntryExportRow
+	err := r.filtered(ctx, filter).
+		Select(`time_entries.id AS entry_id, time_entries.user_id, users.email AS user_email,
+			users.first_name AS user_first_name, users.last_name AS user_last_name,
+			time_entries.project_id, projects.name AS project_name, time_entries.task_id,
+			tasks.title AS task_title, time_entries.started_at, time_entries.ended_at,
+			time_entries.duration, time_entries.billable, time_entries.notes,
+			timesheets.status AS timesheet_status`).
+		Joins("JOIN users ON users.id = time_entries.user_id").
+		Joins("JOIN projects ON projects.id = time_entries.project_id").
+		Joins("JOIN tasks ON tasks.id = time_entries.task_id").
+		Joins("LEFT JOIN timesheets ON timesheets.id = time_entries.timesheet_id AND timesheets.deleted_at IS NULL").
+		Order("users.last_name ASC, users.first_name ASC, time_entries.user_id ASC, time_entries.started_at ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// filtered builds the query shared by List and Export
+func (r *timeEntryRepository) filtered(ctx context.Context, filter TimeEntryFilter) *gorm.DB {
+	query := r.db.WithContext(ctx).Model(&models.TimeEntry{})
+	if filter.OrganizationID != 0 {
+		query = query.Where("time_entries.organization_id = ?", filter.OrganizationID)
+	}
+	if filter.ProjectID != 0 {
+		query = query.Where("time_entries.project_id = ?", filter.ProjectID)
+	}
+	if filter.TaskID != 0 {
+		query = query.Where("time_entries.task_id = ?", filter.TaskID)
+	}
+	if filter.UserID != 0 {
+		query = query.Where("time_entries.user_id = ?", filter.UserID)
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("time_entries.started_at >= ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("time_entries.started_at < ?", filter.To)
+	}
+	if filter.FinishedOnly {
+		query = query.Where("time_entries.ended_at IS NOT NULL")
+	}
+	return query
+}
@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/0-jagadeesh-0/chorvo/internal/domain/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TimesheetRepository defines the interface for timesheet data access
+type TimesheetRepository interface {
+	FindByID(ctx context.Context, id uint) (*models.Timesheet, error)
+	FindForWeek(ctx context.Context, orgID, userID uint, weekStart time.Time) (*models.Timesheet, error)
+	Submit(ctx context.Context, timesheet *models.Timesheet) error
+	Review(ctx context.Context, timesheet *models.Timesheet) error
+	ListSubmitted(ctx context.Context, orgID uint, managerID *uint) ([]models.Timesheet, error)
+	ProjectIDs(ctx context.Context, timesheetID uint) ([]uint, error)
+}
+
+// NewTimesheetRepository creates a new instance of TimesheetRepository
+func NewTimesheetRepository(db *gorm.DB) TimesheetRepository {
+	return &timesheetRepository{
+		db: db,
+	}
+}
+
+type timesheetRepository struct {
+	db *gorm.DB
+}
+
+func (r *timesheetRepository) FindByID(ctx context.Context, id uint) (*models.Timesheet, error) {
+	var timesheet models.Timesheet
+	if err := r.db.WithContext(ctx).First(&timesheet, id).Error; err != nil {
+		return nil, err
+	}
+	return &timesheet, nil
+}
+
+func (r *timesheetRepository) FindForWeek(ctx context.Context, orgID, userID uint, weekStart time.Time) (*models.Timesheet, error) {
+	var timesheet models.Timesheet
+	err := r.db.WithContext(ctx).
+		Where("organization_id = ? AND user_id = ? AND week_start = ?", orgID, userID, weekStart).
+		First(&timesheet).Error
+	if err != nil {
+		return nil, err
+	}
+	return &timesheet, nil
+}
+
+// Submit saves a submitted timesheet and attaches the user's finished entries
+// of that week to it, which locks them. The totals are computed from the
+// attached entries.
+func (r *timesheetRepository) Submit(ctx context.Context, timesheet *models.Timesheet) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(timesheet).Error; err != nil {
+			return err
+		}
+
+		err := tx.Model(&models.TimeEntry{}).
+			Where("organization_id = ? AND user_id = ? AND started_at >= ? AND started_at < ?",
+				timesheet.OrganizationID, timesheet.UserID, timesheet.WeekStart, timesheet.WeekEnd()).
+			Where("ended_at IS NOT NULL AND (timesheet_id IS NULL OR timesheet_id = ?)", timesheet.ID).
+			UpdateColumn("timesheet_id", timesheet.ID).Error
+		if err != nil {
+			return err
+		}
+
+		var totals struct {
+			Total    int64
+			Billable int64
+		}
+		err = tx.Model(&models.TimeEntry{}).
+			Select("COALESCE(SUM(duration), 0) AS total, COALESCE(SUM(CASE WHEN billable THEN duration ELSE 0 END), 0) AS billable").
+			Where("timesheet_id = ?", timesheet.ID).
+			Scan(&totals).Error
+		if err != nil {
+			return err
+		}
+		timesheet.TotalSeconds = totals.Total
+		timesheet.BillableSeconds = totals.Billable
+		return tx.Model(timesheet).UpdateColumns(map[string]interface{}{
+			"total_seconds":    totals.Total,
+			"billable_seconds": totals.Billable,
+		}).Error
+	})
+}
+
+// Review saves an approved or rejected timesheet. Rejection releases the
+// entries so the user can correct them and submit again.
+func (r *timesheetRepository) Review(ctx context.Context, timesheet *models.Timesheet) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Save(timesheet).Error; err != nil {
+			return err
+		}
+		if timesheet.IsLocked() {
+			return nil
+		}
+		return tx.Model(&models.TimeEntry{}).
+			Where("timesheet_id = ?", timesheet.ID).
+			UpdateColumn("timesheet_id", nil).Error
+	})
+}
+
+// ListSubmitted lists the organization's timesheets awaiting review. With a
+// manager ID, only timesheets of other users whose entries all belong to
+// projects that user manages are listed.
+func (r *timesheetRepository) ListSubmitted(ctx context.Context, orgID uint, managerID *uint) ([]models.Timesheet, error) {
+	query := r.db.WithContext(ctx).
+		Where("organization_id = ? AND status = ?", orgID, models.TimesheetStatusSubmitted)
+	if managerID != nil {
+		query = query.
+			Where("user_id <> ?", *managerID).
+			Where(`NOT EXISTS (
+				SELECT 1 FROM time_entries
+				WHERE time_entries.timesheet_id = timesheets.id AND time_entries.deleted_at IS NULL
+				AND NOT EXISTS (
+					SELECT 1 FROM project_members
+					WHERE project_members.project_id = time_entries.project_id
+					AND project_members.user_id = ? AND project_members.role = 'manager'
+				)
+			)`, *managerID)
+	}
+
+	var timesheets []models.Timesheet
+	if err := query.Order("week_start ASC, id ASC").Find(&timesheets).Error; err != nil {
+		return nil, err
+	}
+	return timesheets, nil
+}
+
+// ProjectIDs returns the projects a timesheet's entries were tracked on
+func (r *timesheetRepository) ProjectIDs(ctx context.Context, timesheetID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.WithContext(ctx).
+		Model(&models.TimeEntry{}).
+		Where("timesheet_id = ?", timesheetID).
+		Distinct().
+		Pluck("project_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -10,6 +10,7 @@ import (
 // UserRepository defines the interface for user data access
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
+	CreateWithEmail(ctx context.Context, user *models.User, email *models.OutboxEmail) error
 	FindByID(ctx context.Context, id uint) (*models.User, error)
 	Update(ctx context.Context, user *models.User) error
 	Delete(ctx context.Context, id uint) error
@@ -31,6 +32,17 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 	return r.db.WithContext(ctx).Create(user).Error
 }
 
+// CreateWithEmail stores a new user together with an email to them in the
+// outbox, such as their verification code, so that neither is stored alone
+func (r *userRepository) CreateWithEmail(ctx context.Context, user *models.User, email *models.OutboxEmail) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(user).Error; err != nil {
+			return err
+		}
+		return tx.Create(email).Error
+	})
+}
+
 func (r *userRepository) FindByID(ctx context.Context, id uint) (*models.User, error) {
 	var user models.User
 	if err := r.db.WithContext(ctx).First(&user, id).Error; err != nil {
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// LocalStorage stores objects as files under a root directory. Presigned
+// URLs point at the API's own /files routes and carry an HMAC signature.
+type LocalStorage struct {
+	root    string
+	baseURL string
+	secret  []byte
+}
+
+// SignedParams are the verified parameters of a presigned local URL
+type SignedParams struct {
+	MaxSize     int64
+	ContentType string
+	FileName    string
+}
+
+// NewLocalStorage creates a LocalStorage rooted at dir. baseURL is the public
+// URL the file routes are served under, such as http://localhost:8080/files.
+func NewLocalStorage(dir, baseURL string, secret []byte) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{
+		root:    dir,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		secret:  secret,
+	}, nil
+}
+
+func (s *LocalStorage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0o750); err != nil {
+		return err
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(file), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	written, err := io.Copy(tmp, io.LimitReader(r, size+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	if written > size {
+		return ErrTooLarge
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+func (s *LocalStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	file, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStorage) Delete(ctx context.Context, key string) error {
+	file, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStorage) PresignPut(ctx context.Context, key, contentType string, maxSize int64, expires time.Duration) (string, error) {
+	params := url.Values{}
+	params.Set("max_size", strconv.FormatInt(maxSize, 10))
+	if contentType != "" {
+		params.Set("content_type", contentType)
+	}
+	return s.presign("PUT", key, params, expires)
+}
+
+func (s *LocalStorage) PresignGet(ctx context.Context, key, fileName string, expires time.Duration) (string, error) {
+	params := url.Values{}
+	if fileName != "" {
+		params.Set("file_name", fileName)
+	}
+	return s.presign("GET", key, params, expires)
+}
+
+// Verify checks the signature and expiry of a presigned URL's query for the
+// given method and key
+func (s *LocalStorage) Verify(method, key string, query url.Values) (*SignedParams, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := hex.DecodeString(query.Get("signature"))
+	if err != nil || len(signature) == 0 {
+		return nil, ErrInvalidURL
+	}
+	if !hmac.Equal(signature, s.sign(method, key, query)) {
+		return nil, ErrInvalidURL
+	}
+
+	expiresAt, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expiresAt {
+		return nil, ErrInvalidURL
+	}
+
+	signed := &SignedParams{
+		ContentType: query.Get("content_type"),
+		FileName:    query.Get("file_name"),
+	}
+	if raw := query.Get("max_size"); raw != "" {
+		if signed.MaxSize, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			return nil, ErrInvalidURL
+		}
+	}
+	return signed, nil
+}
+
+// ContentDisposition returns the Content-Disposition header for downloading a file
+func (s *LocalStorage) ContentDisposition(fileName string) string {
+	return contentDisposition(fileName)
+}
+
+func (s *LocalStorage) presign(method, key string, params url.Values, expires time.Duration) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	params.Set("expires", strconv.FormatInt(time.Now().Add(expires).Unix(), 10))
+	params.Set("signature", hex.EncodeToString(s.sign(method, key, params)))
+
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return s.baseURL + "/" + strings.Join(segments, "/") + "?" + params.Encode(), nil
+}
+
+// sign computes the signature of a method, key and the query parameters
+// other than the signature itself
+func (s *LocalStorage) sign(method, key string, params url.Values) []byte {
+	unsigned := url.Values{}
+	for name, values := range params {
+		if name != "signature" {
+			unsigned[name] = values
+		}
+	}
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(method + "\n" + key + "\n" + unsigned.Encode()))
+	return mac.Sum(nil)
+}
+
+// path maps a key to a file under the root directory
+func (s *LocalStorage) path(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(s.root, filepath.FromSlash(key)), nil
+}
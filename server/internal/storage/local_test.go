@@ -0,0 +1,229 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCleanKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want string
+		err  error
+	}{
+		{"orgs/1/file.txt", "orgs/1/file.txt", nil},
+		{"file..txt", "file..txt", nil},
+		{"a/..b/c", "a/..b/c", nil},
+		{"", "", ErrInvalidKey},
+		{"/etc/passwd", "", ErrInvalidKey},
+		{"../secret", "", ErrInvalidKey},
+		{"orgs/../../secret", "", ErrInvalidKey},
+		{"orgs/1/..", "", ErrInvalidKey},
+		{"orgs/./1", "", ErrInvalidKey},
+		{"orgs//1", "", ErrInvalidKey},
+		{"orgs/1/", "", ErrInvalidKey},
+		{`orgs\..\secret`, "", ErrInvalidKey},
+		{`..\secret`, "", ErrInvalidKey},
+	}
+	for _, tt := range tests {
+		got, err := CleanKey(tt.key)
+		if got != tt.want || !errors.Is(err, tt.err) {
+			t.Errorf("CleanKey(%q) = %q, %v, want %q, %v", tt.key, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func newTestLocal(t *testing.T) (*LocalStorage, string) {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "files")
+	store, err := NewLocalStorage(dir, "http://localhost:8080/files/", []byte("test-secret"))
+	if err != nil {
+		t.Fatalf("NewLocalStorage: %v", err)
+	}
+	return store, dir
+}
+
+func TestLocalRejectsTraversal(t *testing.T) {
+	store, dir := newTestLocal(t)
+	ctx := context.Background()
+	secret := filepath.Join(filepath.Dir(dir), "secret.txt")
+	if err := os.WriteFile(secret, []byte("outside the root"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"../secret.txt", "a/../../secret.txt", "/secret.txt", `..\secret.txt`} {
+		if err := store.Put(ctx, key, strings.NewReader("overwritten"), 11, ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := store.Get(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Get(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if err := store.Delete(ctx, key); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Delete(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := store.PresignPut(ctx, key, "", 10, time.Minute); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("PresignPut(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := store.Verify("GET", key, url.Values{}); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Verify(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+
+	if data, _ := os.ReadFile(secret); string(data) != "outside the root" {
+		t.Errorf("file outside the root was changed to %q", data)
+	}
+}
+
+func TestLocalPutGetDelete(t *testing.T) {
+	store, dir := newTestLocal(t)
+	ctx := context.Background()
+	content := []byte("local content")
+
+	if err := store.Put(ctx, "orgs/1/a.txt", bytes.NewReader(content), int64(len(content)), "text/plain"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "orgs", "1", "a.txt")); err != nil {
+		t.Fatalf("stored file: %v", err)
+	}
+
+	r, err := store.Get(ctx, "orgs/1/a.txt")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, _ := io.ReadAll(r)
+	r.Close()
+	if !bytes.Equal(got, content) {
+		t.Errorf("Get = %q, want %q", got, content)
+	}
+
+	if err := store.Put(ctx, "orgs/1/big.txt", strings.NewReader("too large"), 3, ""); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Put of oversized content error = %v, want ErrTooLarge", err)
+	}
+	if _, err := store.Get(ctx, "orgs/1/big.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("oversized content was stored: %v", err)
+	}
+
+	if err := store.Delete(ctx, "orgs/1/a.txt"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, "orgs/1/a.txt"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, "orgs/1/a.txt"); err != nil {
+		t.Errorf("Delete of missing file: %v", err)
+	}
+}
+
+// signedRequest splits a presigned URL into the key and query the file
+// routes would verify
+func signedRequest(t *testing.T, rawURL string) (string, url.Values) {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("parsing %s: %v", rawURL, err)
+	}
+	key, ok := strings.CutPrefix(u.Path, "/files/")
+	if !ok {
+		t.Fatalf("URL %s is not under /files/", rawURL)
+	}
+	return key, u.Query()
+}
+
+func TestLocalSignedURLs(t *testing.T) {
+	store, _ := newTestLocal(t)
+	ctx := context.Background()
+
+	putURL, err := store.PresignPut(ctx, "orgs/1/my file.txt", "text/plain", 2048, time.Minute)
+	if err != nil {
+		t.Fatalf("PresignPut: %v", err)
+	}
+	key, query := signedRequest(t, putURL)
+	if key != "orgs/1/my file.txt" {
+		t.Fatalf("signed key = %q", key)
+	}
+	params, err := store.Verify("PUT", key, query)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if params.MaxSize != 2048 || params.ContentType != "text/plain" {
+		t.Errorf("signed params = %+v", params)
+	}
+
+	getURL, err := store.PresignGet(ctx, "orgs/1/my file.txt", "report.txt", time.Minute)
+	if err != nil {
+		t.Fatalf("PresignGet: %v", err)
+	}
+	getKey, getQuery := signedRequest(t, getURL)
+	if params, err := store.Verify("GET", getKey, getQuery); err != nil || params.FileName != "report.txt" {
+		t.Errorf("Verify GET = %+v, %v", params, err)
+	}
+
+	tampered := func(name, value string) url.Values {
+		q := url.Values{}
+		for k, v := range query {
+			q[k] = v
+		}
+		q.Set(name, value)
+		return q
+	}
+	tests := []struct {
+		name   string
+		method string
+		key    string
+		query  url.Values
+	}{
+		{"method", "GET", key, query},
+		{"key", "PUT", "orgs/1/other.txt", query},
+		{"max size", "PUT", key, tampered("max_size", "999999999")},
+		{"expiry", "PUT", key, tampered("expires", "99999999999")},
+		{"signature", "PUT", key, tampered("signature", strings.Repeat("0", 64))},
+		{"malformed signature", "PUT", key, tampered("signature", "not-hex")},
+		{"missing signature", "PUT", key, tampered("signature", "")},
+	}
+	for _, tt := range tests {
+		t.Run("tampered "+tt.name, func(t *testing.T) {
+			if _, err := store.Verify(tt.method, tt.key, tt.query); !errors.Is(err, ErrInvalidURL) {
+				t.Errorf("Verify error = %v, want ErrInvalidURL", err)
+			}
+		})
+	}
+
+	other, err := NewLocalStorage(t.TempDir(), "http://localhost:8080/files", []byte("other-secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := other.Verify("PUT", key, query); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("Verify with another secret error = %v, want ErrInvalidURL", err)
+	}
+}
+
+func TestLocalSignedURLExpiry(t *testing.T) {
+	store, _ := newTestLocal(t)
+	ctx := context.Background()
+
+	expired, err := store.PresignGet(ctx, "orgs/1/a.txt", "", -time.Second)
+	if err != nil {
+		t.Fatalf("PresignGet: %v", err)
+	}
+	key, query := signedRequest(t, expired)
+	if _, err := store.Verify("GET", key, query); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("Verify of expired URL error = %v, want ErrInvalidURL", err)
+	}
+
+	valid, err := store.PresignGet(ctx, "orgs/1/a.txt", "", 2*time.Second)
+	if err != nil {
+		t.Fatalf("PresignGet: %v", err)
+	}
+	key, query = signedRequest(t, valid)
+	if _, err := store.Verify("GET", key, query); err != nil {
+		t.Errorf("Verify of unexpired URL: %v", err)
+	}
+}
@@ -0,0 +1,266 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	amzDateFormat   = "20060102T150405Z"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+)
+
+// S3Config configures an S3-compatible object store
+type S3Config struct {
+	Endpoint        string // such as https://s3.eu-west-1.amazonaws.com or http://localhost:9000
+	Region          string
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	// PathStyle addresses objects as endpoint/bucket/key instead of
+	// bucket.endpoint/key, as local S3 stand-ins usually require
+	PathStyle bool
+}
+
+// S3Storage stores objects in an S3-compatible bucket, signing requests with
+// AWS Signature Version 4
+type S3Storage struct {
+	config   S3Config
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3Storage creates an S3Storage
+func NewS3Storage(config S3Config) (*S3Storage, error) {
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3 endpoint %q", config.Endpoint)
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("S3 bucket is required")
+	}
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+	return &S3Storage{
+		config:   config,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+func (s *S3Storage) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	req, err := s.request(ctx, http.MethodPut, key, io.LimitReader(r, size))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (s *S3Storage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+func (s *S3Storage) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if err == ErrNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// PresignPut returns a presigned PUT URL. S3 cannot enforce a maximum size on
+// presigned PUTs, so callers must check the size of the stored object.
+func (s *S3Storage) PresignPut(ctx context.Context, key, contentType string, maxSize int64, expires time.Duration) (string, error) {
+	return s.presign(http.MethodPut, key, nil, expires)
+}
+
+func (s *S3Storage) PresignGet(ctx context.Context, key, fileName string, expires time.Duration) (string, error) {
+	query := url.Values{}
+	if fileName != "" {
+		query.Set("response-content-disposition", contentDisposition(fileName))
+	}
+	return s.presign(http.MethodGet, key, query, expires)
+}
+
+// objectURL returns the URL of an object
+func (s *S3Storage) objectURL(key string) (*url.URL, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	u := *s.endpoint
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = escapeRFC3986(segment)
+	}
+	escaped := strings.Join(segments, "/")
+
+	base := strings.TrimSuffix(u.Path, "/")
+	if s.config.PathStyle {
+		u.Path = base + "/" + s.config.Bucket + "/" + key
+		u.RawPath = base + "/" + escapeRFC3986(s.config.Bucket) + "/" + escaped
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+		u.Path = base + "/" + key
+		u.RawPath = base + "/" + escaped
+	}
+	return &u, nil
+}
+
+func (s *S3Storage) request(ctx context.Context, method, key string, body io.Reader) (*http.Request, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	req.Header.Set("X-Amz-Date", now.Format(amzDateFormat))
+	req.Header.Set("X-Amz-Content-Sha256", unsignedPayload)
+
+	signedHeaders := []string{"host", "x-amz-content-sha256", "x-amz-date"}
+	headers := map[string]string{
+		"host":                 u.Host,
+		"x-amz-content-sha256": unsignedPayload,
+		"x-amz-date":           now.Format(amzDateFormat),
+	}
+	canonical := canonicalRequest(method, u.EscapedPath(), "", headers, signedHeaders, unsignedPayload)
+	signature := s.signature(now, canonical)
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.config.AccessKeyID, s.scope(now), strings.Join(signedHeaders, ";"), signature,
+	))
+	return req, nil
+}
+
+// do sends a signed request and converts error responses
+func (s *S3Storage) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrNotFound
+	}
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		return nil, fmt.Errorf("s3 %s %s: %s: %s", req.Method, req.URL.Path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return resp, nil
+}
+
+func (s *S3Storage) presign(method, key string, query url.Values, expires time.Duration) (string, error) {
+	u, err := s.objectURL(key)
+	if err != nil {
+		return "", err
+	}
+	if query == nil {
+		query = url.Values{}
+	}
+
+	now := time.Now().UTC()
+	query.Set("X-Amz-Algorithm", "AWS4-HMAC-SHA256")
+	query.Set("X-Amz-Credential", s.config.AccessKeyID+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.Format(amzDateFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", "host")
+
+	canonicalQuery := canonicalQueryString(query)
+	canonical := canonicalRequest(method, u.EscapedPath(), canonicalQuery, map[string]string{"host": u.Host}, []string{"host"}, unsignedPayload)
+
+	u.RawQuery = canonicalQuery + "&X-Amz-Signature=" + s.signature(now, canonical)
+	return u.String(), nil
+}
+
+func (s *S3Storage) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + s.config.Region + "/s3/aws4_request"
+}
+
+// signature signs a canonical request with the secret key
+func (s *S3Storage) signature(t time.Time, canonical string) string {
+	hash := sha256.Sum256([]byte(canonical))
+	stringToSign := "AWS4-HMAC-SHA256\n" + t.Format(amzDateFormat) + "\n" + s.scope(t) + "\n" + hex.EncodeToString(hash[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), t.Format("20060102"))
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func canonicalRequest(method, path, query string, headers map[string]string, signedHeaders []string, payload string) string {
+	var sb strings.Builder
+	sb.WriteString(method + "\n" + path + "\n" + query + "\n")
+	for _, name := range signedHeaders {
+		sb.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	sb.WriteString("\n" + strings.Join(signedHeaders, ";") + "\n" + payload)
+	return sb.String()
+}
+
+// canonicalQueryString encodes query parameters sorted by name as SigV4 requires
+func canonicalQueryString(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for name := range query {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, name := range names {
+		for _, value := range query[name] {
+			parts = append(parts, escapeRFC3986(name)+"="+escapeRFC3986(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
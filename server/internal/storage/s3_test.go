@@ -0,0 +1,398 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	testAccessKey = "AKIDEXAMPLE"
+	testSecretKey = "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY"
+	testRegion    = "eu-west-1"
+	testBucket    = "attachments"
+)
+
+// fakeS3 is an in-memory stand-in for a path-style S3 bucket. It checks
+// Signature Version 4 on every request, both in the Authorization header and
+// in presigned query strings, independently of the code under test.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]fakeObject
+}
+
+type fakeObject struct {
+	data        []byte
+	contentType string
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	fake := &fakeS3{objects: map[string]fakeObject{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return fake, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if err := verifySigV4(r, time.Now().UTC()); err != nil {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	key, ok := strings.CutPrefix(r.URL.Path, "/"+testBucket+"/")
+	if !ok {
+		http.Error(w, "NoSuchBucket", http.StatusNotFound)
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = fakeObject{data: data, contentType: r.Header.Get("Content-Type")}
+	case http.MethodGet:
+		object, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		if disposition := r.URL.Query().Get("response-content-disposition"); disposition != "" {
+			w.Header().Set("Content-Disposition", disposition)
+		}
+		w.Header().Set("Content-Type", object.contentType)
+		w.Write(object.data)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) object(key string) (fakeObject, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	object, ok := f.objects[key]
+	return object, ok
+}
+
+// verifySigV4 checks the signature of a request signed in its headers or its query
+func verifySigV4(r *http.Request, now time.Time) error {
+	query := r.URL.Query()
+	var (
+		amzDate, credential, signature, payload string
+		signedHeaders                           []string
+	)
+	if query.Has("X-Amz-Signature") {
+		if query.Get("X-Amz-Algorithm") != "AWS4-HMAC-SHA256" {
+			return errors.New("unsupported algorithm")
+		}
+		amzDate = query.Get("X-Amz-Date")
+		credential = query.Get("X-Amz-Credential")
+		signature = query.Get("X-Amz-Signature")
+		signedHeaders = strings.Split(query.Get("X-Amz-SignedHeaders"), ";")
+		payload = "UNSIGNED-PAYLOAD"
+
+		signedAt, err := time.Parse("20060102T150405Z", amzDate)
+		if err != nil {
+			return errors.New("invalid X-Amz-Date")
+		}
+		expires, err := strconv.Atoi(query.Get("X-Amz-Expires"))
+		if err != nil || expires < 1 || expires > 604800 {
+			return errors.New("invalid X-Amz-Expires")
+		}
+		if now.After(signedAt.Add(time.Duration(expires) * time.Second)) {
+			return errors.New("request has expired")
+		}
+		query.Del("X-Amz-Signature")
+	} else {
+		auth, ok := strings.CutPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ")
+		if !ok {
+			return errors.New("missing authorization")
+		}
+		for _, part := range strings.Split(auth, ", ") {
+			name, value, _ := strings.Cut(part, "=")
+			switch name {
+			case "Credential":
+				credential = value
+			case "SignedHeaders":
+				signedHeaders = strings.Split(value, ";")
+			case "Signature":
+				signature = value
+			}
+		}
+		amzDate = r.Header.Get("X-Amz-Date")
+		payload = r.Header.Get("X-Amz-Content-Sha256")
+	}
+
+	day := strings.SplitN(amzDate, "T", 2)[0]
+	scope := day + "/" + testRegion + "/s3/aws4_request"
+	if credential != testAccessKey+"/"+scope {
+		return fmt.Errorf("unexpected credential %q", credential)
+	}
+
+	var canonical strings.Builder
+	canonical.WriteString(r.Method + "\n" + r.URL.EscapedPath() + "\n" + encodeQuery(query) + "\n")
+	for _, name := range signedHeaders {
+		value := r.Header.Get(name)
+		if name == "host" {
+			value = r.Host
+		}
+		canonical.WriteString(name + ":" + strings.TrimSpace(value) + "\n")
+	}
+	canonical.WriteString("\n" + strings.Join(signedHeaders, ";") + "\n" + payload)
+
+	hash := sha256.Sum256([]byte(canonical.String()))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hash[:])
+	key := []byte("AWS4" + testSecretKey)
+	for _, part := range []string{day, testRegion, "s3", "aws4_request", stringToSign} {
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(part))
+		key = mac.Sum(nil)
+	}
+	if !hmac.Equal([]byte(hex.EncodeToString(key)), []byte(signature)) {
+		return errors.New("SignatureDoesNotMatch")
+	}
+	return nil
+}
+
+// encodeQuery encodes a query sorted by name with spaces as %20, as SigV4 requires
+func encodeQuery(query url.Values) string {
+	var parts []string
+	for name, values := range query {
+		for _, value := range values {
+			parts = append(parts, escapeQuery(name)+"="+escapeQuery(value))
+		}
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "&")
+}
+
+func escapeQuery(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
+
+func newTestS3(t *testing.T, endpoint string) *S3Storage {
+	t.Helper()
+	store, err := NewS3Storage(S3Config{
+		Endpoint:        endpoint,
+		Region:          testRegion,
+		Bucket:          testBucket,
+		AccessKeyID:     testAccessKey,
+		SecretAccessKey: testSecretKey,
+		PathStyle:       true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	return store
+}
+
+func TestS3PutGetDelete(t *testing.T) {
+	fake, server := newFakeS3(t)
+	store := newTestS3(t, server.URL)
+	ctx := context.Background()
+	key := "orgs/1/attachments/0a1b/Quarterly report (final) ü.pdf"
+	content := []byte("%PDF-1.7 quarterly numbers")
+
+	if err := store.Put(ctx, key, bytes.NewReader(content), int64(len(content)), "application/pdf"); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	object, ok := fake.object(key)
+	if !ok || !bytes.Equal(object.data, content) || object.contentType != "application/pdf" {
+		t.Fatalf("stored object = %q (%s), want %q (application/pdf)", object.data, object.contentType, content)
+	}
+
+	r, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	got, err := io.ReadAll(r)
+	r.Close()
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("Get = %q, %v, want %q", got, err, content)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Get after Delete error = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatalf("Delete of missing object: %v", err)
+	}
+}
+
+func TestS3PutStoresOnlyDeclaredSize(t *testing.T) {
+	fake, server := newFakeS3(t)
+	store := newTestS3(t, server.URL)
+
+	if err := store.Put(context.Background(), "a/b.txt", strings.NewReader("hello, world"), 5, ""); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if object, _ := fake.object("a/b.txt"); string(object.data) != "hello" {
+		t.Errorf("stored %q, want %q", object.data, "hello")
+	}
+}
+
+func TestS3RejectsWrongCredentials(t *testing.T) {
+	_, server := newFakeS3(t)
+	store := newTestS3(t, server.URL)
+	store.config.SecretAccessKey = "not-the-secret"
+
+	err := store.Put(context.Background(), "a/b.txt", strings.NewReader("x"), 1, "")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("Put with wrong secret error = %v, want 403", err)
+	}
+}
+
+func TestS3RejectsInvalidKeys(t *testing.T) {
+	store := newTestS3(t, "http://127.0.0.1:1")
+	ctx := context.Background()
+	for _, key := range []string{"../etc/passwd", "/abs", "a//b", "a/./b"} {
+		if err := store.Put(ctx, key, strings.NewReader("x"), 1, ""); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+		if _, err := store.PresignGet(ctx, key, "", time.Minute); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("PresignGet(%q) error = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+func TestS3PresignedPutAndGet(t *testing.T) {
+	fake, server := newFakeS3(t)
+	store := newTestS3(t, server.URL)
+	ctx := context.Background()
+	key := "orgs/2/attachments/ff00/notes & plans.txt"
+
+	putURL, err := store.PresignPut(ctx, key, "text/plain", 1024, 15*time.Minute)
+	if err != nil {
+		t.Fatalf("PresignPut: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodPut, putURL, strings.NewReader("uploaded directly"))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("presigned PUT: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("presigned PUT status = %d", resp.StatusCode)
+	}
+	if object, _ := fake.object(key); string(object.data) != "uploaded directly" {
+		t.Fatalf("stored %q after presigned PUT", object.data)
+	}
+
+	getURL, err := store.PresignGet(ctx, key, "Notes für Q3.txt", 5*time.Minute)
+	if err != nil {
+		t.Fatalf("PresignGet: %v", err)
+	}
+	resp, err = http.Get(getURL)
+	if err != nil {
+		t.Fatalf("presigned GET: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "uploaded directly" {
+		t.Fatalf("presigned GET = %d %q", resp.StatusCode, body)
+	}
+	wantDisposition := `attachment; filename="Notes f_r Q3.txt"; filename*=UTF-8''Notes%20f%C3%BCr%20Q3.txt`
+	if got := resp.Header.Get("Content-Disposition"); got != wantDisposition {
+		t.Errorf("Content-Disposition = %s, want %s", got, wantDisposition)
+	}
+}
+
+func TestS3PresignedURLSignatureIsVerified(t *testing.T) {
+	_, server := newFakeS3(t)
+	store := newTestS3(t, server.URL)
+	ctx := context.Background()
+
+	getURL, err := store.PresignGet(ctx, "a/secret.txt", "", time.Minute)
+	if err != nil {
+		t.Fatalf("PresignGet: %v", err)
+	}
+	putURL, err := store.PresignPut(ctx, "a/secret.txt", "", 10, time.Minute)
+	if err != nil {
+		t.Fatalf("PresignPut: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		method string
+		url    string
+	}{
+		{"other key", http.MethodGet, strings.Replace(getURL, "/a/secret.txt", "/a/other.txt", 1)},
+		{"extended expiry", http.MethodGet, strings.Replace(getURL, "X-Amz-Expires=60", "X-Amz-Expires=604800", 1)},
+		{"added parameter", http.MethodGet, getURL + "&response-content-type=text%2Fhtml"},
+		{"get url used to put", http.MethodPut, getURL},
+		{"put url used to delete", http.MethodDelete, putURL},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(tt.method, tt.url, strings.NewReader("x"))
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != http.StatusForbidden {
+				t.Errorf("status = %d, want %d", resp.StatusCode, http.StatusForbidden)
+			}
+		})
+	}
+}
+
+func TestS3PresignedURLExpires(t *testing.T) {
+	store := newTestS3(t, "http://localhost:9000")
+
+	getURL, err := store.PresignGet(context.Background(), "a/b.txt", "", time.Minute)
+	if err != nil {
+		t.Fatalf("PresignGet: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodGet, getURL, nil)
+
+	if err := verifySigV4(req, time.Now().UTC()); err != nil {
+		t.Fatalf("fresh URL rejected: %v", err)
+	}
+	if err := verifySigV4(req, time.Now().UTC().Add(2*time.Minute)); err == nil {
+		t.Fatal("expired URL accepted")
+	}
+}
+
+func TestS3VirtualHostedURL(t *testing.T) {
+	store, err := NewS3Storage(S3Config{
+		Endpoint: "https://s3.eu-west-1.amazonaws.com",
+		Bucket:   testBucket,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage: %v", err)
+	}
+	u, err := store.objectURL("orgs/1/a b+c.txt")
+	if err != nil {
+		t.Fatalf("objectURL: %v", err)
+	}
+	if want := "https://attachments.s3.eu-west-1.amazonaws.com/orgs/1/a%20b%2Bc.txt"; u.String() != want {
+		t.Errorf("objectURL = %s, want %s", u, want)
+	}
+	if store.config.Region != "us-east-1" {
+		t.Errorf("default region = %s, want us-east-1", store.config.Region)
+	}
+}
@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+// ScanResult is the verdict of a virus scan
+type ScanResult struct {
+	Clean     bool
+	Signature string // name of the detected threat when not clean
+}
+
+// Scanner checks uploaded content for malware before it is made available
+type Scanner interface {
+	Scan(ctx context.Context, r io.Reader) (ScanResult, error)
+}
+
+// NoopScanner accepts every file. It is used when no scanner is configured.
+type NoopScanner struct{}
+
+func (NoopScanner) Scan(ctx context.Context, r io.Reader) (ScanResult, error) {
+	return ScanResult{Clean: true}, nil
+}
+
+// ClamdScanner scans files with a ClamAV daemon using its INSTREAM command
+type ClamdScanner struct {
+	Addr    string // such as localhost:3310
+	Timeout time.Duration
+}
+
+const clamdChunkSize = 64 * 1024
+
+func (s ClamdScanner) Scan(ctx context.Context, r io.Reader) (ScanResult, error) {
+	timeout := s.Timeout
+	if timeout == 0 {
+		timeout = 2 * time.Minute
+	}
+	dialer := net.Dialer{Timeout: 10 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return ScanResult{}, fmt.Errorf("connecting to clamd: %w", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(timeout))
+
+	if _, err := conn.Write([]byte("zINSTREAM\x00")); err != nil {
+		return ScanResult{}, err
+	}
+
+	// Stream the content as length-prefixed chunks ending with an empty chunk
+	buf := make([]byte, clamdChunkSize)
+	size := make([]byte, 4)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			binary.BigEndian.PutUint32(size, uint32(n))
+			if _, err := conn.Write(size); err != nil {
+				return ScanResult{}, err
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				return ScanResult{}, err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return ScanResult{}, err
+		}
+	}
+	if _, err := conn.Write([]byte{0, 0, 0, 0}); err != nil {
+		return ScanResult{}, err
+	}
+
+	reply, err := bufio.NewReader(conn).ReadString('\x00')
+	if err != nil && err != io.EOF {
+		return ScanResult{}, err
+	}
+	reply = strings.TrimRight(reply, "\x00\n")
+
+	// Replies look like "stream: OK" or "stream: Eicar-Signature FOUND"
+	switch {
+	case strings.HasSuffix(reply, " OK"):
+		return ScanResult{Clean: true}, nil
+	case strings.HasSuffix(reply, " FOUND"):
+		signature := strings.TrimSuffix(strings.TrimPrefix(reply, "stream: "), " FOUND")
+		return ScanResult{Signature: signature}, nil
+	}
+	return ScanResult{}, fmt.Errorf("clamd: %s", reply)
+}
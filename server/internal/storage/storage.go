@@ -0,0 +1,74 @@
+// Package storage stores uploaded files in a pluggable backend: the local
+// filesystem or an S3-compatible object store.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+	ErrInvalidURL = errors.New("invalid or expired signed URL")
+	ErrTooLarge   = errors.New("object is larger than allowed")
+)
+
+// Storage is a backend that stores objects under keys
+type Storage interface {
+	// Put stores an object of the given size
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	// Get opens a stored object. The caller must close it.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes an object. Deleting a missing object is not an error.
+	Delete(ctx context.Context, key string) error
+	// PresignPut returns a URL a client can PUT an object of up to maxSize bytes to
+	PresignPut(ctx context.Context, key, contentType string, maxSize int64, expires time.Duration) (string, error)
+	// PresignGet returns a URL a client can download an object from, saved under fileName
+	PresignGet(ctx context.Context, key, fileName string, expires time.Duration) (string, error)
+}
+
+// CleanKey validates an object key and returns it in canonical form. Keys are
+// slash-separated relative paths without empty, "." or ".." segments.
+func CleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return "", ErrInvalidKey
+		}
+	}
+	return path.Clean(key), nil
+}
+
+// contentDisposition builds an attachment Content-Disposition header for a file name
+func contentDisposition(fileName string) string {
+	ascii := strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return '_'
+		}
+		return r
+	}, fileName)
+	return `attachment; filename="` + ascii + `"; filename*=UTF-8''` + escapeRFC3986(fileName)
+}
+
+// escapeRFC3986 percent-encodes everything except unreserved characters
+func escapeRFC3986(s string) string {
+	var sb strings.Builder
+	for _, b := range []byte(s) {
+		if ('A' <= b && b <= 'Z') || ('a' <= b && b <= 'z') || ('0' <= b && b <= '9') ||
+			b == '-' || b == '_' || b == '.' || b == '~' {
+			sb.WriteByte(b)
+			continue
+		}
+		sb.WriteByte('%')
+		sb.WriteByte("0123456789ABCDEF"[b>>4])
+		sb.WriteByte("0123456789ABCDEF"[b&15])
+	}
+	return sb.String()
+}